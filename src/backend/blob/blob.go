@@ -3,8 +3,12 @@ package blob
 import (
 	"collections/logger"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -18,8 +22,11 @@ import (
 	"github.com/samber/mo"
 	"gocloud.dev/blob"
 	"gocloud.dev/blob/fileblob"
+	"gocloud.dev/blob/gcsblob"
+	"gocloud.dev/blob/memblob"
 	"gocloud.dev/blob/s3blob"
 	"gocloud.dev/gcerrors"
+	"gocloud.dev/gcp"
 )
 
 type Bucket struct {
@@ -75,6 +82,17 @@ func NewBucket(
 	}, nil
 }
 
+// NewMemBucket returns an empty in-memory bucket, for tests that need a
+// Bucket without touching the filesystem.
+func NewMemBucket(ctx context.Context, log *logger.Logger) *Bucket {
+	b, err := NewBucket(ctx, log, "mem://")
+	if err != nil {
+		// Opening a mem bucket without options cannot fail
+		panic(fmt.Sprintf("failed to open mem bucket: %v", err))
+	}
+	return b
+}
+
 type BucketOption interface {
 	bucketOption()
 }
@@ -90,6 +108,10 @@ type bucket struct {
 	Scheme string
 }
 
+// openGCSBucket opens gs:// buckets; tests replace it to run the gs://
+// path without a GCS emulator
+var openGCSBucket = gcsblob.OpenBucket
+
 func newBucket(
 	ctx context.Context,
 	bucketUrl string,
@@ -105,6 +127,9 @@ func newBucket(
 		if err != nil {
 			return nil, fmt.Errorf("failed to open fileblob bucket %s: %w", dir, err)
 		}
+	case strings.HasPrefix(bucketUrl, "mem://"):
+		// Each mem:// bucket is independent and lives until closed
+		bucket = memblob.OpenBucket(nil)
 	case strings.HasPrefix(bucketUrl, "s3://"):
 		bucketName := strings.TrimRight(strings.TrimPrefix(bucketUrl, "s3://"), "/")
 		cfg, err := config.LoadDefaultConfig(ctx)
@@ -116,9 +141,30 @@ func newBucket(
 		if err != nil {
 			return nil, fmt.Errorf("failed to open s3 bucket %s: %w", bucketName, err)
 		}
+	case strings.HasPrefix(bucketUrl, "gs://"):
+		bucketName := strings.TrimRight(strings.TrimPrefix(bucketUrl, "gs://"), "/")
+		var client *gcp.HTTPClient
+		if os.Getenv("STORAGE_EMULATOR_HOST") != "" {
+			// gcsblob talks to the emulator unauthenticated, but still
+			// requires a non-nil client
+			client = gcp.NewAnonymousHTTPClient(gcp.DefaultTransport())
+		} else {
+			creds, err := gcp.DefaultCredentials(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load gcp credentials: %w", err)
+			}
+			client, err = gcp.NewHTTPClient(gcp.DefaultTransport(), gcp.CredentialsTokenSource(creds))
+			if err != nil {
+				return nil, fmt.Errorf("failed to create gcp http client: %w", err)
+			}
+		}
+		bucket, err = openGCSBucket(ctx, client, bucketName, &gcsblob.Options{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gcs bucket %s: %w", bucketName, err)
+		}
 	default:
 		return nil, fmt.Errorf(
-			"unsupported bucket-url %s, supported schemes are file, s3",
+			"unsupported bucket-url %s, supported schemes are file, mem, s3, gs",
 			bucketUrl,
 		)
 	}
@@ -184,6 +230,17 @@ func (b *Bucket) Exists(ctx context.Context, key string) (ok bool, err error) {
 	return b.bucket.Exists(ctx, key)
 }
 
+// MetadataSHA256 is the metadata key Write stores the content hash under.
+// For file buckets it lands in the .attrs sidecar as user.metadata.sha256.
+const MetadataSHA256 = "sha256"
+
+// ContentHash is the hex SHA-256 of uncompressed data, as stored by Write.
+// It doesn't depend on how the blob was compressed.
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 func (b *Bucket) Write(ctx context.Context, key string, data []byte) error {
 	// Add timeout to blob write operations (30 seconds)
 	writeCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
@@ -206,7 +263,9 @@ func (b *Bucket) Write(ctx context.Context, key string, data []byte) error {
 			time.Sleep(backoff)
 		}
 
-		var opts *blob.WriterOptions
+		opts := &blob.WriterOptions{
+			Metadata: map[string]string{MetadataSHA256: ContentHash(data)},
+		}
 		w, err := b.bucket.NewWriter(writeCtx, key, opts)
 		if err != nil {
 			lastErr = fmt.Errorf("failed to create bucket writer: %w", err)
@@ -261,14 +320,37 @@ func (b *Bucket) Write(ctx context.Context, key string, data []byte) error {
 	return nil
 }
 
+// ErrNotFound is returned by every backend when a key does not exist.
+// Err holds the backend's own error (e.g. an S3 NoSuchKey) when there is one.
 type ErrNotFound struct {
 	Key string
+	Err error
 }
 
 func (e *ErrNotFound) Error() string {
 	return fmt.Sprintf("key not found: %s", e.Key)
 }
 
+func (e *ErrNotFound) Unwrap() error {
+	return e.Err
+}
+
+// IsNotFound reports whether err means the key does not exist.
+func IsNotFound(err error) bool {
+	errNotFound := &ErrNotFound{}
+	return errors.As(err, &errNotFound)
+}
+
+// notFound converts a backend not-found error into *ErrNotFound, returning
+// nil if err is anything else. gocloud maps S3 404/NoSuchKey, GCS
+// ErrObjectNotExist and missing files all to gcerrors.NotFound.
+func notFound(key string, err error) error {
+	if err == nil || gcerrors.Code(err) != gcerrors.NotFound {
+		return nil
+	}
+	return &ErrNotFound{Key: key, Err: err}
+}
+
 func (b *Bucket) Read(ctx context.Context, key string) (data []byte, err error) {
 	// Add timeout to blob read operations (30 seconds)
 	readCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
@@ -314,8 +396,8 @@ func (b *Bucket) Read(ctx context.Context, key string) (data []byte, err error)
 	var opts *blob.ReaderOptions
 	r, err := b.bucket.NewReader(readCtx, key, opts)
 	if err != nil {
-		if gcerrors.Code(err) == gcerrors.NotFound {
-			return nil, &ErrNotFound{key}
+		if errNotFound := notFound(key, err); errNotFound != nil {
+			return nil, errNotFound
 		}
 		return nil, fmt.Errorf("failed to create bucket reader: %w", err)
 	}
@@ -324,6 +406,10 @@ func (b *Bucket) Read(ctx context.Context, key string) (data []byte, err error)
 	if err != nil {
 		_ = zr.Close()
 		_ = r.Close()
+		// Some backends only report a missing object on first read
+		if errNotFound := notFound(key, err); errNotFound != nil {
+			return nil, errNotFound
+		}
 		return nil, err
 	}
 	if err := zr.Close(); err != nil {
@@ -348,15 +434,196 @@ func (b *Bucket) Read(ctx context.Context, key string) (data []byte, err error)
 	return data, nil
 }
 
+// Attributes describes a stored blob without reading its contents.
+type Attributes struct {
+	Key     string
+	Size    int64 // Compressed size in bytes
+	ModTime time.Time
+	MD5     []byte // Of the compressed contents; nil when the backend has none (S3 multipart uploads)
+	// SHA256 is the hex content hash stored by Write, empty for blobs
+	// written without one. Listings never include it.
+	SHA256 string
+}
+
+// Stat returns the attributes of the blob at key.
+func (b *Bucket) Stat(ctx context.Context, key string) (*Attributes, error) {
+	attrs, err := b.bucket.Attributes(ctx, key+".zst")
+	if err != nil {
+		if errNotFound := notFound(key+".zst", err); errNotFound != nil {
+			return nil, errNotFound
+		}
+		return nil, fmt.Errorf("failed to stat %s: %w", key, err)
+	}
+	return &Attributes{
+		Key:     key,
+		Size:    attrs.Size,
+		ModTime: attrs.ModTime,
+		MD5:     attrs.MD5,
+		SHA256:  attrs.Metadata[MetadataSHA256],
+	}, nil
+}
+
+// ErrNoContentHash is returned by Verify for a blob written without a
+// content hash.
+var ErrNoContentHash = errors.New("blob has no content hash")
+
+// ErrContentHashMismatch is returned by Verify when a blob's contents no
+// longer match the hash stored when it was written.
+type ErrContentHashMismatch struct {
+	Key  string
+	Want string
+	Got  string
+}
+
+func (e *ErrContentHashMismatch) Error() string {
+	return fmt.Sprintf("content hash mismatch for %s: stored %s, read %s", e.Key, e.Want, e.Got)
+}
+
+// Verify re-reads the blob at key from the backend, bypassing the cache,
+// and checks it against the content hash stored by Write. A blob that no
+// longer decompresses fails as well.
+func (b *Bucket) Verify(ctx context.Context, key string) error {
+	attrs, err := b.Stat(ctx, key)
+	if err != nil {
+		return err
+	}
+	if attrs.SHA256 == "" {
+		return fmt.Errorf("%s: %w", key, ErrNoContentHash)
+	}
+	r, err := b.bucket.NewReader(ctx, key+".zst", nil)
+	if err != nil {
+		if errNotFound := notFound(key+".zst", err); errNotFound != nil {
+			return errNotFound
+		}
+		return fmt.Errorf("failed to create bucket reader: %w", err)
+	}
+	defer r.Close()
+	zr := zstd.NewReader(r)
+	defer zr.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, zr); err != nil {
+		return fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != attrs.SHA256 {
+		return &ErrContentHashMismatch{Key: key, Want: attrs.SHA256, Got: got}
+	}
+	return nil
+}
+
+// CopyTo copies the blob at key to the same key in dst, which may be in
+// another bucket or backend. The stored compressed bytes are streamed
+// as they are, so nothing is recompressed, along with the stored content
+// hash. A copy cached in dst is dropped.
+func (b *Bucket) CopyTo(ctx context.Context, dst *Bucket, key string) error {
+	key += ".zst"
+	attrs, err := b.bucket.Attributes(ctx, key)
+	if err != nil {
+		if errNotFound := notFound(key, err); errNotFound != nil {
+			return errNotFound
+		}
+		return fmt.Errorf("failed to stat %s: %w", key, err)
+	}
+	r, err := b.bucket.NewReader(ctx, key, nil)
+	if err != nil {
+		if errNotFound := notFound(key, err); errNotFound != nil {
+			return errNotFound
+		}
+		return fmt.Errorf("failed to create bucket reader: %w", err)
+	}
+	defer r.Close()
+	w, err := dst.bucket.NewWriter(ctx, key, &blob.WriterOptions{Metadata: attrs.Metadata})
+	if err != nil {
+		return fmt.Errorf("failed to create bucket writer: %w", err)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		_ = w.Close()
+		return fmt.Errorf("failed to copy %s: %w", key, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to close bucket writer: %w", err)
+	}
+	dst.dropCached(ctx, key)
+	return nil
+}
+
+// Copy copies the blob at srcKey to dstKey within the bucket. A cached
+// copy of dstKey is dropped.
+func (b *Bucket) Copy(ctx context.Context, dstKey, srcKey string) error {
+	if err := b.bucket.Copy(ctx, dstKey+".zst", srcKey+".zst", nil); err != nil {
+		if errNotFound := notFound(srcKey+".zst", err); errNotFound != nil {
+			return errNotFound
+		}
+		return fmt.Errorf("failed to copy %s to %s: %w", srcKey, dstKey, err)
+	}
+	b.dropCached(ctx, dstKey+".zst")
+	return nil
+}
+
+// Delete deletes the blob at key, returning *ErrNotFound if it does not
+// exist. A cached copy is dropped as well.
+func (b *Bucket) Delete(ctx context.Context, key string) error {
+	key += ".zst"
+	if err := b.bucket.Delete(ctx, key); err != nil {
+		if errNotFound := notFound(key, err); errNotFound != nil {
+			return errNotFound
+		}
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	b.dropCached(ctx, key)
+	return nil
+}
+
+// dropCached deletes the cache entry for the stored key (with .zst), if
+// the bucket has a cache. Failures are only logged.
+func (b *Bucket) dropCached(ctx context.Context, key string) {
+	if b.cache == nil {
+		return
+	}
+	err := b.cache.Update(func(txn *badger.Txn) error {
+		return txn.Delete(b.cacheKey(key))
+	})
+	if err != nil {
+		b.log.Errorf(ctx, "failed to delete cache entry: %v", err)
+	}
+}
+
+// DeletePrefix deletes every blob under prefix and returns how many were
+// deleted. Cached copies are dropped as well.
+func (b *Bucket) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	it := b.bucket.List(&blob.ListOptions{Prefix: prefix})
+	deleted := 0
+	for {
+		obj, err := it.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return deleted, fmt.Errorf("failed to list prefix %s: %w", prefix, err)
+		}
+		if obj.IsDir {
+			continue
+		}
+		if err := b.bucket.Delete(ctx, obj.Key); err != nil {
+			return deleted, fmt.Errorf("failed to delete %s: %w", obj.Key, err)
+		}
+		b.dropCached(ctx, obj.Key)
+		deleted++
+	}
+	return deleted, nil
+}
+
 func (b *Bucket) List(
 	ctx context.Context,
 	options ...ListOption,
 ) *ListIterator {
 	prefix := mo.None[string]()
+	maxRetries := mo.None[int]()
 	for _, opt := range options {
 		switch opt := opt.(type) {
 		case *OptListPrefix:
 			prefix = mo.Some(opt.Prefix)
+		case *OptListMaxRetries:
+			maxRetries = mo.Some(opt.MaxRetries)
 		default:
 			panic(fmt.Sprintf("invalid option type %T", opt))
 		}
@@ -365,11 +632,13 @@ func (b *Bucket) List(
 		Prefix: prefix.OrElse(""),
 	})
 	return &ListIterator{
-		b:    b,
-		it:   it,
-		obj:  nil,
-		err:  nil,
-		done: false,
+		b:          b,
+		it:         it,
+		obj:        nil,
+		err:        nil,
+		done:       false,
+		maxRetries: maxRetries.OrElse(defaultListMaxRetries),
+		backoff:    listBackoff,
 	}
 }
 
@@ -381,27 +650,78 @@ type OptListPrefix struct {
 	Prefix string
 }
 
-func (o *OptListPrefix) listOption() {}
+// OptListMaxRetries bounds how many times a failed page fetch is retried
+// before the iterator gives up. Zero disables retries.
+type OptListMaxRetries struct {
+	MaxRetries int
+}
+
+func (o *OptListPrefix) listOption()     {}
+func (o *OptListMaxRetries) listOption() {}
+
+const defaultListMaxRetries = 5
+
+// listBackoff is the wait before retry attempt n (0-based): 200ms, 400ms, ...
+// capped at 30s.
+func listBackoff(attempt int) time.Duration {
+	d := time.Duration(200*(1<<uint(attempt))) * time.Millisecond
+	if d > 30*time.Second || d <= 0 {
+		d = 30 * time.Second
+	}
+	return d
+}
+
+// listPager is the subset of *blob.ListIterator used by ListIterator
+type listPager interface {
+	Next(ctx context.Context) (*blob.ListObject, error)
+}
 
 type ListIterator struct {
 	b    *Bucket
-	it   *blob.ListIterator
+	it   listPager
 	obj  *blob.ListObject
 	err  error
 	done bool
+
+	maxRetries int
+	backoff    func(attempt int) time.Duration
 }
 
+// Next advances to the next key. A failed page fetch is retried with
+// backoff; the underlying iterator re-requests the same page token, so no
+// keys are skipped or repeated. Once retries are exhausted Next returns
+// false and Err reports the failure.
 func (it *ListIterator) Next(ctx context.Context) bool {
 	if it.done {
 		return false
 	}
-	obj, err := it.it.Next(ctx)
-	if err == io.EOF {
-		it.done = true
-		return false
+	for attempt := 0; ; attempt++ {
+		obj, err := it.it.Next(ctx)
+		if err == nil {
+			it.obj = obj
+			return true
+		}
+		if err == io.EOF {
+			it.done = true
+			return false
+		}
+		if attempt >= it.maxRetries || ctx.Err() != nil {
+			it.err = fmt.Errorf("failed to list after %d attempts: %w", attempt+1, err)
+			it.done = true
+			return false
+		}
+		wait := it.backoff(attempt)
+		it.b.log.Fieldf("attempt", "%d", attempt+1).
+			Fieldf("wait", "%v", wait).
+			Warnf(ctx, "list page failed, retrying: %v", err)
+		select {
+		case <-ctx.Done():
+			it.err = ctx.Err()
+			it.done = true
+			return false
+		case <-time.After(wait):
+		}
 	}
-	it.obj = obj
-	return true
 }
 
 func (it *ListIterator) Err() error {
@@ -412,6 +732,17 @@ func (it *ListIterator) Key() string {
 	return strings.TrimSuffix(it.obj.Key, ".zst")
 }
 
+// Attributes returns the current blob's attributes from the listing,
+// without a request per key. MD5 is nil for backends that don't list it.
+func (it *ListIterator) Attributes() *Attributes {
+	return &Attributes{
+		Key:     it.Key(),
+		Size:    it.obj.Size,
+		ModTime: it.obj.ModTime,
+		MD5:     it.obj.MD5,
+	}
+}
+
 func (it *ListIterator) Value(ctx context.Context) ([]byte, error) {
 	return it.b.Read(ctx, it.Key())
 }
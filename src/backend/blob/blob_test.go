@@ -0,0 +1,523 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/DataDog/zstd"
+	gcblob "gocloud.dev/blob"
+	"gocloud.dev/blob/gcsblob"
+	"gocloud.dev/blob/memblob"
+	"gocloud.dev/gcerrors"
+	"gocloud.dev/gcp"
+)
+
+func newFileBucket(t *testing.T) *Bucket {
+	t.Helper()
+	ctx := context.Background()
+	b, err := NewBucket(ctx, nil, "file://"+t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create bucket: %v", err)
+	}
+	t.Cleanup(func() { b.Close(ctx) })
+	return b
+}
+
+// testBucketRoundTrip exercises the Bucket surface shared by every backend
+func testBucketRoundTrip(t *testing.T, b *Bucket) {
+	t.Helper()
+	ctx := context.Background()
+
+	data := []byte(`{"id":"deck-1"}`)
+	if err := b.Write(ctx, "decks/a/1.json", data); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := b.Write(ctx, "decks/a/2.json", data); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := b.Write(ctx, "decks/b/1.json", data); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	got, err := b.Read(ctx, "decks/a/1.json")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("Read() = %q, want %q", got, data)
+	}
+
+	ok, err := b.Exists(ctx, "decks/a/1.json")
+	if err != nil || !ok {
+		t.Errorf("Exists() = %v, %v; want true, nil", ok, err)
+	}
+
+	var keys []string
+	it := b.List(ctx, &OptListPrefix{Prefix: "decks/a/"})
+	for it.Next(ctx) {
+		keys = append(keys, it.Key())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	sort.Strings(keys)
+	want := []string{"decks/a/1.json", "decks/a/2.json"}
+	if fmt.Sprint(keys) != fmt.Sprint(want) {
+		t.Errorf("List() keys = %v, want %v", keys, want)
+	}
+
+	attrs, err := b.Stat(ctx, "decks/a/1.json")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if attrs.Size <= 0 || attrs.ModTime.IsZero() {
+		t.Errorf("Stat() = %+v, want size and mod time", attrs)
+	}
+
+	if err := b.Copy(ctx, "decks/c/1.json", "decks/a/1.json"); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	got, err = b.Read(ctx, "decks/c/1.json")
+	if err != nil || string(got) != string(data) {
+		t.Errorf("Read() after Copy() = %q, %v", got, err)
+	}
+
+	n, err := b.DeletePrefix(ctx, "decks/a/")
+	if err != nil {
+		t.Fatalf("DeletePrefix() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("DeletePrefix() deleted %d, want 2", n)
+	}
+	ok, err = b.Exists(ctx, "decks/a/2.json")
+	if err != nil || ok {
+		t.Errorf("Exists() after DeletePrefix() = %v, %v; want false, nil", ok, err)
+	}
+	ok, err = b.Exists(ctx, "decks/b/1.json")
+	if err != nil || !ok {
+		t.Errorf("DeletePrefix() removed key outside prefix")
+	}
+
+	if err := b.Delete(ctx, "decks/b/1.json"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	ok, err = b.Exists(ctx, "decks/b/1.json")
+	if err != nil || ok {
+		t.Errorf("Exists() after Delete() = %v, %v; want false, nil", ok, err)
+	}
+	if err := b.Delete(ctx, "decks/b/1.json"); !IsNotFound(err) {
+		t.Errorf("Delete() missing key error = %v, want ErrNotFound", err)
+	}
+
+	errNotFound := &ErrNotFound{}
+	if _, err := b.Stat(ctx, "missing"); !errors.As(err, &errNotFound) {
+		t.Errorf("Stat() missing key error = %v, want ErrNotFound", err)
+	}
+}
+
+// localBackends returns constructors for the backends that need no
+// external services, so contract tests can run against each of them
+func localBackends() map[string]func(t *testing.T) *Bucket {
+	return map[string]func(t *testing.T) *Bucket{
+		"file": newFileBucket,
+		"mem": func(t *testing.T) *Bucket {
+			ctx := context.Background()
+			b := NewMemBucket(ctx, nil)
+			t.Cleanup(func() { b.Close(ctx) })
+			return b
+		},
+	}
+}
+
+func TestBucketRoundTrip(t *testing.T) {
+	for name, newBucket := range localBackends() {
+		t.Run(name, func(t *testing.T) {
+			testBucketRoundTrip(t, newBucket(t))
+		})
+	}
+}
+
+func TestBucketCopyTo(t *testing.T) {
+	ctx := context.Background()
+	for srcName, newSrc := range localBackends() {
+		for dstName, newDst := range localBackends() {
+			t.Run(srcName+"->"+dstName, func(t *testing.T) {
+				src, dst := newSrc(t), newDst(t)
+				data := []byte(`{"id":"deck-1"}`)
+				if err := src.Write(ctx, "decks/1.json", data); err != nil {
+					t.Fatal(err)
+				}
+				if err := src.CopyTo(ctx, dst, "decks/1.json"); err != nil {
+					t.Fatalf("CopyTo() error = %v", err)
+				}
+				got, err := dst.Read(ctx, "decks/1.json")
+				if err != nil || string(got) != string(data) {
+					t.Errorf("Read() after CopyTo() = %q, %v", got, err)
+				}
+
+				// The stored bytes are copied as they are
+				srcAttrs, err := src.Stat(ctx, "decks/1.json")
+				if err != nil {
+					t.Fatal(err)
+				}
+				dstAttrs, err := dst.Stat(ctx, "decks/1.json")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if dstAttrs.Size != srcAttrs.Size || len(srcAttrs.MD5) == 0 || string(dstAttrs.MD5) != string(srcAttrs.MD5) {
+					t.Errorf("copied attrs = %+v, want size and MD5 of %+v", dstAttrs, srcAttrs)
+				}
+				if dstAttrs.SHA256 != ContentHash(data) {
+					t.Errorf("copied SHA256 = %q, want %q", dstAttrs.SHA256, ContentHash(data))
+				}
+				it := dst.List(ctx)
+				if !it.Next(ctx) {
+					t.Fatalf("List() found nothing: %v", it.Err())
+				}
+				// fileblob lists no MD5, only Stat reads it from the .attrs sidecar
+				if listed := it.Attributes(); listed.Key != "decks/1.json" || listed.Size != dstAttrs.Size ||
+					(listed.MD5 != nil && string(listed.MD5) != string(dstAttrs.MD5)) {
+					t.Errorf("listed attrs = %+v, want %+v", listed, dstAttrs)
+				}
+
+				if err := src.CopyTo(ctx, dst, "missing"); !IsNotFound(err) {
+					t.Errorf("CopyTo() missing key error = %v, want ErrNotFound", err)
+				}
+			})
+		}
+	}
+}
+
+func TestBucketCopyDropsCachedDestination(t *testing.T) {
+	ctx := context.Background()
+	b, err := NewBucket(ctx, nil, "file://"+t.TempDir(), &OptBucketCache{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close(ctx)
+	for key, data := range map[string]string{"src.json": "new", "dst.json": "old"} {
+		if err := b.Write(ctx, key, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := b.Read(ctx, "dst.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Copy(ctx, "dst.json", "src.json"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := b.Read(ctx, "dst.json"); err != nil || string(got) != "new" {
+		t.Errorf("Read() after Copy() = %q, %v, want %q", got, err, "new")
+	}
+}
+
+func TestBucketVerify(t *testing.T) {
+	ctx := context.Background()
+	for name, newBucket := range localBackends() {
+		t.Run(name, func(t *testing.T) {
+			b := newBucket(t)
+			if err := b.Write(ctx, "decks/1.json", []byte(`{"id":"deck-1"}`)); err != nil {
+				t.Fatal(err)
+			}
+			if err := b.Verify(ctx, "decks/1.json"); err != nil {
+				t.Fatalf("Verify() of a fresh blob = %v", err)
+			}
+
+			// Overwrite the stored bytes but keep the stored hash, as a
+			// corrupted file under an intact .attrs sidecar would
+			attrs, err := b.bucket.Attributes(ctx, "decks/1.json.zst")
+			if err != nil {
+				t.Fatal(err)
+			}
+			rewrite := func(raw []byte) {
+				t.Helper()
+				err := b.bucket.WriteAll(ctx, "decks/1.json.zst", raw, &gcblob.WriterOptions{Metadata: attrs.Metadata})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			other, err := zstd.Compress(nil, []byte(`{"id":"deck-2"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rewrite(other)
+			errMismatch := &ErrContentHashMismatch{}
+			if err := b.Verify(ctx, "decks/1.json"); !errors.As(err, &errMismatch) {
+				t.Errorf("Verify() of changed contents = %v, want ErrContentHashMismatch", err)
+			}
+			rewrite([]byte("not zstd"))
+			if err := b.Verify(ctx, "decks/1.json"); err == nil {
+				t.Error("Verify() of undecodable contents = nil, want an error")
+			}
+
+			if err := b.bucket.WriteAll(ctx, "decks/2.json.zst", other, nil); err != nil {
+				t.Fatal(err)
+			}
+			if err := b.Verify(ctx, "decks/2.json"); !errors.Is(err, ErrNoContentHash) {
+				t.Errorf("Verify() without a hash = %v, want ErrNoContentHash", err)
+			}
+			if err := b.Verify(ctx, "missing"); !IsNotFound(err) {
+				t.Errorf("Verify() of a missing key = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestBucketListOrdering(t *testing.T) {
+	ctx := context.Background()
+	for name, newBucket := range localBackends() {
+		t.Run(name, func(t *testing.T) {
+			b := newBucket(t)
+			// Written out of order; List must return lexicographic order
+			for _, key := range []string{"c.json", "a.json", "b/2.json", "b/1.json"} {
+				if err := b.Write(ctx, key, []byte("{}")); err != nil {
+					t.Fatalf("Write(%s) error = %v", key, err)
+				}
+			}
+			var keys []string
+			it := b.List(ctx)
+			for it.Next(ctx) {
+				keys = append(keys, it.Key())
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			want := []string{"a.json", "b/1.json", "b/2.json", "c.json"}
+			if fmt.Sprint(keys) != fmt.Sprint(want) {
+				t.Errorf("List() keys = %v, want %v", keys, want)
+			}
+		})
+	}
+}
+
+// testBucketNotFound asserts that a missing key surfaces as *ErrNotFound
+// wrapping the backend's own not-found error
+func testBucketNotFound(t *testing.T, b *Bucket) {
+	t.Helper()
+	ctx := context.Background()
+
+	_, err := b.Read(ctx, "missing.json")
+	errNotFound := &ErrNotFound{}
+	if !errors.As(err, &errNotFound) {
+		t.Fatalf("Read() missing key error = %v, want ErrNotFound", err)
+	}
+	if errNotFound.Key != "missing.json.zst" {
+		t.Errorf("ErrNotFound.Key = %q, want %q", errNotFound.Key, "missing.json.zst")
+	}
+	if gcerrors.Code(errors.Unwrap(err)) != gcerrors.NotFound {
+		t.Errorf("ErrNotFound does not wrap the backend error: %v", errors.Unwrap(err))
+	}
+	if !IsNotFound(fmt.Errorf("wrapped: %w", err)) {
+		t.Error("IsNotFound() = false for wrapped ErrNotFound")
+	}
+
+	if _, err := b.Stat(ctx, "missing.json"); !IsNotFound(err) {
+		t.Errorf("Stat() missing key error = %v, want ErrNotFound", err)
+	}
+	if err := b.Copy(ctx, "dst.json", "missing.json"); !IsNotFound(err) {
+		t.Errorf("Copy() missing key error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestBucketNotFound(t *testing.T) {
+	for name, newBucket := range localBackends() {
+		t.Run(name, func(t *testing.T) {
+			testBucketNotFound(t, newBucket(t))
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	if IsNotFound(nil) {
+		t.Error("IsNotFound(nil) = true")
+	}
+	if IsNotFound(errors.New("boom")) {
+		t.Error("IsNotFound(other) = true")
+	}
+}
+
+func TestS3Bucket(t *testing.T) {
+	// Requires AWS credentials (or AWS_ENDPOINT_URL pointing at MinIO/localstack):
+	//   S3_TEST_BUCKET=test go test ./blob
+	bucketName, ok := os.LookupEnv("S3_TEST_BUCKET")
+	if !ok {
+		t.Skip("S3_TEST_BUCKET not set")
+	}
+
+	ctx := context.Background()
+	b, err := NewBucket(ctx, nil, "s3://"+bucketName)
+	if err != nil {
+		t.Fatalf("failed to create s3 bucket: %v", err)
+	}
+	defer b.Close(ctx)
+
+	b = b.WithPrefix(t.Name())
+	testBucketNotFound(t, b)
+	testBucketRoundTrip(t, b)
+}
+
+func TestMemBucketsAreIndependent(t *testing.T) {
+	ctx := context.Background()
+	a := NewMemBucket(ctx, nil)
+	defer a.Close(ctx)
+	b := NewMemBucket(ctx, nil)
+	defer b.Close(ctx)
+
+	if err := a.Write(ctx, "key", []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := b.Exists(ctx, "key"); ok {
+		t.Error("write to one mem bucket is visible in another")
+	}
+}
+
+func TestGCSBucket(t *testing.T) {
+	// Requires a GCS emulator, e.g. fake-gcs-server, with the bucket created:
+	//   STORAGE_EMULATOR_HOST=localhost:4443 GCS_TEST_BUCKET=test go test ./blob
+	if os.Getenv("STORAGE_EMULATOR_HOST") == "" {
+		t.Skip("STORAGE_EMULATOR_HOST not set")
+	}
+	bucketName, ok := os.LookupEnv("GCS_TEST_BUCKET")
+	if !ok {
+		t.Skip("GCS_TEST_BUCKET not set")
+	}
+
+	ctx := context.Background()
+	b, err := NewBucket(ctx, nil, "gs://"+bucketName)
+	if err != nil {
+		t.Fatalf("failed to create gcs bucket: %v", err)
+	}
+	defer b.Close(ctx)
+
+	// Isolate from other runs against the same emulator bucket
+	b = b.WithPrefix(t.Name())
+	testBucketNotFound(t, b)
+	testBucketRoundTrip(t, b)
+}
+
+func TestGCSBucketWithoutEmulator(t *testing.T) {
+	// Opens gs:// as NewBucket does, down to the anonymous client, but
+	// backs it with a mem bucket so it runs everywhere
+	t.Setenv("STORAGE_EMULATOR_HOST", "localhost:0")
+	var opened []string
+	defer func(open func(context.Context, *gcp.HTTPClient, string, *gcsblob.Options) (*gcblob.Bucket, error)) {
+		openGCSBucket = open
+	}(openGCSBucket)
+	openGCSBucket = func(_ context.Context, client *gcp.HTTPClient, bucketName string, _ *gcsblob.Options) (*gcblob.Bucket, error) {
+		if client == nil {
+			t.Error("gcs bucket opened without a client")
+		}
+		opened = append(opened, bucketName)
+		return memblob.OpenBucket(nil), nil
+	}
+
+	ctx := context.Background()
+	b, err := NewBucket(ctx, nil, "gs://decks/")
+	if err != nil {
+		t.Fatalf("failed to create gcs bucket: %v", err)
+	}
+	defer b.Close(ctx)
+	if fmt.Sprint(opened) != "[decks]" {
+		t.Errorf("opened gcs buckets %v, want [decks]", opened)
+	}
+
+	testBucketNotFound(t, b)
+	testBucketRoundTrip(t, b)
+}
+
+// flakyPager lists keys in pages of pageSize, like gocloud's iterator:
+// items come from the current page and a page is only fetched once the
+// previous one is used up. Fetching page failAt fails the first fails
+// times, the way a transient S3 error would, and a failed fetch leaves the
+// page token where it was.
+type flakyPager struct {
+	keys     []string
+	pageSize int
+	failAt   int // Index of the page whose fetch fails
+	fails    int
+
+	page    []string
+	next    int // Index into page
+	token   int // Next page to fetch
+	fetches int
+}
+
+func (p *flakyPager) Next(ctx context.Context) (*gcblob.ListObject, error) {
+	if p.next >= len(p.page) {
+		if p.token*p.pageSize >= len(p.keys) {
+			return nil, io.EOF
+		}
+		p.fetches++
+		if p.token == p.failAt && p.fails > 0 {
+			p.fails--
+			return nil, errors.New("connection reset by peer")
+		}
+		end := min((p.token+1)*p.pageSize, len(p.keys))
+		p.page, p.next = p.keys[p.token*p.pageSize:end], 0
+		p.token++
+	}
+	key := p.page[p.next]
+	p.next++
+	return &gcblob.ListObject{Key: key + ".zst"}, nil
+}
+
+func TestListIteratorRetries(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemBucket(ctx, nil)
+	defer b.Close(ctx)
+
+	var want []string
+	for i := 0; i < 10; i++ {
+		want = append(want, fmt.Sprintf("items/%02d.json", i))
+	}
+
+	// Pages of 3 keys; the third page (keys 6-8) fails
+	newIter := func(fails, maxRetries int) (*ListIterator, *flakyPager) {
+		it := b.List(ctx, &OptListMaxRetries{MaxRetries: maxRetries})
+		pager := &flakyPager{keys: want, pageSize: 3, failAt: 2, fails: fails}
+		it.it = pager
+		it.backoff = func(int) time.Duration { return time.Millisecond }
+		return it, pager
+	}
+
+	t.Run("recovers", func(t *testing.T) {
+		it, pager := newIter(1, 3)
+		var keys []string
+		for it.Next(ctx) {
+			keys = append(keys, it.Key())
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("Err() = %v", err)
+		}
+		if fmt.Sprint(keys) != fmt.Sprint(want) {
+			t.Errorf("keys = %v, want %v", keys, want)
+		}
+		// 4 pages plus the retried one
+		if pager.fetches != 5 {
+			t.Errorf("fetched %d pages, want 5", pager.fetches)
+		}
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		it, pager := newIter(3, 2)
+		n := 0
+		for it.Next(ctx) {
+			n++
+		}
+		if it.Err() == nil {
+			t.Fatal("Err() = nil, want error after retries exhausted")
+		}
+		if n != 6 {
+			t.Errorf("iterated %d keys before failing, want the 6 of the first two pages", n)
+		}
+		if pager.fetches != 5 {
+			t.Errorf("fetched %d pages, want 2 and 3 attempts at the third", pager.fetches)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+// Package cio holds file helpers shared by the cmd and tools binaries.
+package cio
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultCollectionExtensions matches compressed collections (including
+// .json.zst and gzip archives) and plain JSON collections.
+var DefaultCollectionExtensions = []string{".zst", ".json", ".json.gz"}
+
+// FindOpts filters the files returned by FindCollectionFiles
+type FindOpts struct {
+	// Extensions are path suffixes to match, e.g. ".json.zst".
+	// Empty means DefaultCollectionExtensions.
+	Extensions []string
+
+	// Prefix restricts results to files whose slash-separated path
+	// relative to dir starts with Prefix, e.g. "magic/mtgtop8/".
+	Prefix string
+
+	// SkipErrors ignores unreadable entries (and a missing dir) instead of
+	// returning the first error.
+	SkipErrors bool
+}
+
+// FindCollectionFiles walks dir and returns the matching files in lexical
+// order.
+func FindCollectionFiles(dir string, opts FindOpts) ([]string, error) {
+	var files []string
+	err := WalkCollectionFiles(dir, opts, func(path string) error {
+		files = append(files, path)
+		return nil
+	})
+	return files, err
+}
+
+// WalkCollectionFiles calls fn for each matching file under dir in lexical
+// order, without collecting the paths first. An error from fn stops the
+// walk and is returned.
+func WalkCollectionFiles(dir string, opts FindOpts, fn func(path string) error) error {
+	exts := opts.Extensions
+	if len(exts) == 0 {
+		exts = DefaultCollectionExtensions
+	}
+
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if opts.SkipErrors {
+				return nil
+			}
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			// Prune directories that cannot contain a match
+			if opts.Prefix != "" && rel != "." &&
+				!strings.HasPrefix(rel+"/", opts.Prefix) &&
+				!strings.HasPrefix(opts.Prefix, rel+"/") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if opts.Prefix != "" && !strings.HasPrefix(rel, opts.Prefix) {
+			return nil
+		}
+		for _, ext := range exts {
+			if strings.HasSuffix(path, ext) {
+				return fn(path)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,141 @@
+package cio
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFixtureTree(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, rel := range []string{
+		"magic/mtgtop8/1.json.zst",
+		"magic/mtgtop8/2.json",
+		"magic/scryfall/cards/a.zst",
+		"magic/notes.txt",
+		"pokemon/limitless/1.json.zst",
+		"pokemon/limitless/.DS_Store",
+		"yugioh/ygoprodeck/cards.json",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func relPaths(t *testing.T, dir string, files []string) []string {
+	t.Helper()
+	rels := make([]string, len(files))
+	for i, f := range files {
+		rel, err := filepath.Rel(dir, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rels[i] = filepath.ToSlash(rel)
+	}
+	return rels
+}
+
+func TestFindCollectionFiles(t *testing.T) {
+	dir := writeFixtureTree(t)
+
+	tests := []struct {
+		name string
+		opts FindOpts
+		want []string
+	}{
+		{
+			name: "defaults",
+			want: []string{
+				"magic/mtgtop8/1.json.zst",
+				"magic/mtgtop8/2.json",
+				"magic/scryfall/cards/a.zst",
+				"pokemon/limitless/1.json.zst",
+				"yugioh/ygoprodeck/cards.json",
+			},
+		},
+		{
+			name: "json.zst only",
+			opts: FindOpts{Extensions: []string{".json.zst"}},
+			want: []string{
+				"magic/mtgtop8/1.json.zst",
+				"pokemon/limitless/1.json.zst",
+			},
+		},
+		{
+			name: "prefix",
+			opts: FindOpts{Prefix: "magic/"},
+			want: []string{
+				"magic/mtgtop8/1.json.zst",
+				"magic/mtgtop8/2.json",
+				"magic/scryfall/cards/a.zst",
+			},
+		},
+		{
+			name: "prefix within directory name",
+			opts: FindOpts{Prefix: "magic/mtg"},
+			want: []string{
+				"magic/mtgtop8/1.json.zst",
+				"magic/mtgtop8/2.json",
+			},
+		},
+		{
+			name: "prefix and extension",
+			opts: FindOpts{Prefix: "magic/", Extensions: []string{".zst"}},
+			want: []string{
+				"magic/mtgtop8/1.json.zst",
+				"magic/scryfall/cards/a.zst",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := FindCollectionFiles(dir, tt.opts)
+			if err != nil {
+				t.Fatalf("FindCollectionFiles() error = %v", err)
+			}
+			got := relPaths(t, dir, files)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("FindCollectionFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindCollectionFilesMissingDir(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	if _, err := FindCollectionFiles(missing, FindOpts{}); err == nil {
+		t.Error("FindCollectionFiles() error = nil for missing dir")
+	}
+	files, err := FindCollectionFiles(missing, FindOpts{SkipErrors: true})
+	if err != nil || len(files) != 0 {
+		t.Errorf("FindCollectionFiles() with SkipErrors = %v, %v; want none, nil", files, err)
+	}
+}
+
+func TestWalkCollectionFilesStopsOnError(t *testing.T) {
+	dir := writeFixtureTree(t)
+	stop := errors.New("stop")
+	var seen []string
+	err := WalkCollectionFiles(dir, FindOpts{}, func(path string) error {
+		seen = append(seen, path)
+		if len(seen) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("WalkCollectionFiles() error = %v, want %v", err, stop)
+	}
+	if got, want := relPaths(t, dir, seen), []string{"magic/mtgtop8/1.json.zst", "magic/mtgtop8/2.json"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("WalkCollectionFiles() visited %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,18 @@
+package cio
+
+import (
+	"bufio"
+	"io"
+)
+
+// MaxJSONLLine bounds a single JSONL record; exported decks are far
+// smaller, but bufio.Scanner's 64KB default is not
+const MaxJSONLLine = 64 << 20
+
+// NewJSONLScanner returns a scanner over the lines of r that accepts
+// records up to MaxJSONLLine bytes
+func NewJSONLScanner(r io.Reader) *bufio.Scanner {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), MaxJSONLLine)
+	return sc
+}
@@ -0,0 +1,21 @@
+package cio
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewJSONLScanner(t *testing.T) {
+	long := `{"name":"` + strings.Repeat("x", 1<<20) + `"}`
+	sc := NewJSONLScanner(strings.NewReader(`{"id":1}` + "\n" + long + "\n"))
+	var lines []string
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	if len(lines) != 2 || lines[1] != long {
+		t.Errorf("scanned %d lines, want 2 with the 1MB record intact", len(lines))
+	}
+}
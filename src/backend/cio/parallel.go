@@ -0,0 +1,112 @@
+package cio
+
+import (
+	"errors"
+	"sync"
+)
+
+// errStopped ends the walk after merge fails
+var errStopped = errors.New("stopped")
+
+// ProcessOrdered runs work on each path walk emits, on up to workers
+// goroutines, and passes the results to merge one at a time in the order
+// the paths were emitted. Because merge sees the same sequence regardless
+// of workers, output built from it stays deterministic. walk is typically
+// a WalkCollectionFiles call; an error from merge stops the walk and is
+// returned. At most a few results per worker are held waiting for merge.
+func ProcessOrdered[T any](
+	workers int,
+	walk func(emit func(path string) error) error,
+	work func(path string) T,
+	merge func(path string, v T) error,
+) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	type job struct {
+		i    int
+		path string
+	}
+	type result struct {
+		job
+		v T
+	}
+
+	jobs := make(chan job)
+	results := make(chan result, workers)
+	stop := make(chan struct{})
+	// Bounds paths emitted but not yet merged, so one slow file cannot
+	// let the reorder buffer grow without limit
+	inflight := make(chan struct{}, 4*workers)
+
+	var walkErr error
+	go func() {
+		defer close(jobs)
+		n := 0
+		walkErr = walk(func(path string) error {
+			select {
+			case inflight <- struct{}{}:
+			case <-stop:
+				return errStopped
+			}
+			select {
+			case jobs <- job{i: n, path: path}:
+			case <-stop:
+				return errStopped
+			}
+			n++
+			return nil
+		})
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				results <- result{job: j, v: work(j.path)}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var mergeErr error
+	pending := make(map[int]result)
+	next := 0
+	for r := range results {
+		// After a failed merge the walk is stopped and no longer waits
+		// on inflight, so the remaining results are just drained
+		if mergeErr != nil {
+			continue
+		}
+		pending[r.i] = r
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			err := merge(r.path, r.v)
+			// Only a merged path frees a slot, so results waiting in
+			// pending behind a slow one count against the bound
+			<-inflight
+			if err != nil {
+				mergeErr = err
+				close(stop)
+				break
+			}
+		}
+	}
+
+	// results closes only after the walk finished and jobs drained
+	if mergeErr != nil {
+		return mergeErr
+	}
+	return walkErr
+}
@@ -0,0 +1,127 @@
+package cio
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func emitN(n int) func(func(string) error) error {
+	return func(emit func(string) error) error {
+		for i := 0; i < n; i++ {
+			if err := emit(fmt.Sprintf("f%04d", i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestProcessOrderedPreservesOrder(t *testing.T) {
+	for _, workers := range []int{0, 1, 8} {
+		var got []string
+		err := ProcessOrdered(workers, emitN(200),
+			func(path string) string {
+				// Finish out of order
+				time.Sleep(time.Duration(rand.Intn(200)) * time.Microsecond)
+				return path + "!"
+			},
+			func(path, v string) error {
+				if v != path+"!" {
+					t.Errorf("merge(%s) got result %q", path, v)
+				}
+				got = append(got, path)
+				return nil
+			})
+		if err != nil {
+			t.Fatalf("workers=%d: ProcessOrdered() error = %v", workers, err)
+		}
+		if len(got) != 200 {
+			t.Fatalf("workers=%d: merged %d paths, want 200", workers, len(got))
+		}
+		for i, path := range got {
+			if want := fmt.Sprintf("f%04d", i); path != want {
+				t.Fatalf("workers=%d: merge %d = %s, want %s", workers, i, path, want)
+			}
+		}
+	}
+}
+
+func TestProcessOrderedBoundsPending(t *testing.T) {
+	const workers = 2
+	var emitted, merged, maxAhead atomic.Int64
+	walk := func(emit func(string) error) error {
+		for i := 0; i < 200; i++ {
+			if err := emit(fmt.Sprintf("f%04d", i)); err != nil {
+				return err
+			}
+			if ahead := emitted.Add(1) - merged.Load(); ahead > maxAhead.Load() {
+				maxAhead.Store(ahead)
+			}
+		}
+		return nil
+	}
+	err := ProcessOrdered(workers, walk,
+		func(path string) string {
+			// The first path is slow, so every later result waits for it
+			if path == "f0000" {
+				time.Sleep(50 * time.Millisecond)
+			}
+			return path
+		},
+		func(string, string) error {
+			merged.Add(1)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := maxAhead.Load(); got > 4*workers {
+		t.Errorf("walk ran %d paths ahead of merge, want at most %d", got, 4*workers)
+	}
+}
+
+func TestProcessOrderedStopsOnError(t *testing.T) {
+	errBoom := errors.New("boom")
+	emitted := 0
+	walk := func(emit func(string) error) error {
+		for i := 0; i < 10000; i++ {
+			if err := emit(fmt.Sprintf("f%04d", i)); err != nil {
+				return err
+			}
+			emitted++
+		}
+		return nil
+	}
+	merged := 0
+	err := ProcessOrdered(4, walk,
+		func(path string) string { return path },
+		func(path, _ string) error {
+			merged++
+			if merged == 10 {
+				return errBoom
+			}
+			return nil
+		})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("ProcessOrdered() error = %v, want %v", err, errBoom)
+	}
+	if merged != 10 {
+		t.Errorf("merged %d paths after error, want 10", merged)
+	}
+	if emitted == 10000 {
+		t.Error("walk ran to completion after merge failed")
+	}
+
+	errWalk := errors.New("walk failed")
+	err = ProcessOrdered(4, func(emit func(string) error) error {
+		emit("a")
+		return errWalk
+	}, func(path string) string { return path }, func(string, string) error { return nil })
+	if !errors.Is(err, errWalk) {
+		t.Errorf("ProcessOrdered() error = %v, want %v", err, errWalk)
+	}
+}
@@ -0,0 +1,61 @@
+package cio
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/DataDog/zstd"
+)
+
+var (
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	gzipMagic = []byte{0x1f, 0x8b}
+)
+
+// IsCompressed reports whether path names a zstd-compressed collection
+func IsCompressed(path string) bool {
+	return strings.HasSuffix(path, ".zst")
+}
+
+// Decompress returns data decompressed according to its leading magic
+// bytes: zstd, gzip (older archived dumps), or otherwise unchanged as raw
+// JSON
+func Decompress(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, zstdMagic):
+		return zstd.Decompress(nil, data)
+	case bytes.HasPrefix(data, gzipMagic):
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	default:
+		return data, nil
+	}
+}
+
+// DecodeCollectionData returns the JSON for a collection file's raw bytes,
+// decompressing zstd or gzip content and passing plain JSON through. A
+// .zst file must actually be compressed.
+func DecodeCollectionData(path string, data []byte) ([]byte, error) {
+	if IsCompressed(path) && !bytes.HasPrefix(data, zstdMagic) && !bytes.HasPrefix(data, gzipMagic) {
+		return nil, fmt.Errorf("%s is not compressed", path)
+	}
+	return Decompress(data)
+}
+
+// ReadCollectionFile reads a .json, .json.zst or .json.gz collection file
+// and returns its JSON
+func ReadCollectionFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeCollectionData(path, data)
+}
@@ -0,0 +1,54 @@
+package cio
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/zstd"
+)
+
+func TestReadCollectionFile(t *testing.T) {
+	dir := t.TempDir()
+	want := `{"id":"deck-1"}`
+
+	compressed, err := zstd.Compress(nil, []byte(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(want))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"deck.json":     []byte(want),
+		"deck.json.zst": compressed,
+		"deck.json.gz":  gz.Bytes(),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadCollectionFile(path)
+		if err != nil {
+			t.Fatalf("ReadCollectionFile(%s) error = %v", name, err)
+		}
+		if string(got) != want {
+			t.Errorf("ReadCollectionFile(%s) = %q, want %q", name, got, want)
+		}
+	}
+
+	// Plain JSON misnamed as .zst is an error, not silently passed through
+	bad := filepath.Join(dir, "bad.json.zst")
+	if err := os.WriteFile(bad, []byte(want), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadCollectionFile(bad); err == nil {
+		t.Error("ReadCollectionFile() error = nil for uncompressed .zst")
+	}
+}
@@ -1,15 +1,12 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 	"sort"
 
+	"collections/cio"
 	"collections/games/magic/game"
-
-	"github.com/DataDog/zstd"
 )
 
 func main() {
@@ -21,13 +18,7 @@ func main() {
 	dataDir := os.Args[1]
 
 	// Scan all collections
-	var files []string
-	filepath.Walk(dataDir, func(path string, info os.FileInfo, err error) error {
-		if err == nil && !info.IsDir() && filepath.Ext(path) == ".zst" {
-			files = append(files, path)
-		}
-		return nil
-	})
+	files, _ := cio.FindCollectionFiles(dataDir, cio.FindOpts{SkipErrors: true})
 
 	fmt.Printf("📊 Analyzing %d collections...\n\n", len(files))
 
@@ -54,7 +45,7 @@ func main() {
 	}{}
 
 	for _, file := range files {
-		col, err := loadCollection(file)
+		col, err := game.LoadCollectionFile(file)
 		if err != nil {
 			continue
 		}
@@ -154,24 +145,3 @@ func main() {
 
 	fmt.Println("\n═══════════════════════════════════════════════════════════")
 }
-
-func loadCollection(path string) (*game.Collection, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-
-	if filepath.Ext(path) == ".zst" {
-		data, err = zstd.Decompress(nil, data)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	var col game.Collection
-	if err := json.Unmarshal(data, &col); err != nil {
-		return nil, err
-	}
-
-	return &col, nil
-}
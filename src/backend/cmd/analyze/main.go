@@ -0,0 +1,166 @@
+package main
+
+// Per-game deck analysis, dispatched to the games.Analyzer registered for
+// --game (Magic: mana curve, land ratio and colors; Pokemon: supertype
+// ratios and energy curves; Yu-Gi-Oh!: main/extra/side ratios).
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"collections/cio"
+	"collections/games"
+	magic "collections/games/magic/game" // Also registers the Magic analyzer
+	_ "collections/games/pokemon/game"
+	_ "collections/games/yugioh/game"
+)
+
+var (
+	gameName   = flag.String("game", "pokemon", "Game to analyze (one of: "+strings.Join(games.AnalyzerGames(), ", ")+")")
+	cardsDir   = flag.String("cards", "", "Directory of card data, for games that need it (default: the game's directory under <data-dir>)")
+	jsonOutput = flag.Bool("json", false, "Print the report as JSON")
+)
+
+// maxListed bounds warning lines in the text report
+const maxListed = 10
+
+func main() {
+	flag.Parse()
+	args := flag.Args()
+	if len(args) < 1 {
+		fmt.Printf("Usage: analyze [--game %s] [--cards DIR] [--json] <data-dir>\n", strings.Join(games.AnalyzerGames(), "|"))
+		os.Exit(1)
+	}
+	dataDir := args[0]
+
+	analyzer, ok := games.LookupAnalyzer(*gameName)
+	if !ok {
+		fmt.Printf("Error: no analyzer for game %q (supported: %s)\n", *gameName, strings.Join(games.AnalyzerGames(), ", "))
+		os.Exit(1)
+	}
+
+	var skipDir string
+	if loader, ok := analyzer.(games.CardDataLoader); ok {
+		skipDir = *cardsDir
+		if skipDir == "" {
+			skipDir = filepath.Join(dataDir, loader.CardDataDir())
+		}
+		n, err := loader.LoadCardData(skipDir)
+		if err != nil {
+			fmt.Printf("Error: failed to load card data from %s: %v\n", skipDir, err)
+			os.Exit(1)
+		}
+		if !*jsonOutput {
+			fmt.Printf("🃏 Loaded %d %s cards from %s\n", n, *gameName, skipDir)
+		}
+	}
+
+	decks, err := loadDecks(dataDir, analyzer.DeckType(), skipDir)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	report := analyzer.Analyze(decks)
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	printReport(report)
+}
+
+// loadDecks reads the collections of deckType under dataDir, skipping
+// files under skipDir (card data)
+func loadDecks(dataDir, deckType, skipDir string) ([]*games.Collection, error) {
+	files, err := cio.FindCollectionFiles(dataDir, cio.FindOpts{SkipErrors: true})
+	if err != nil {
+		return nil, err
+	}
+	var decks []*games.Collection
+	for _, file := range files {
+		if skipDir != "" && strings.HasPrefix(file, skipDir+string(filepath.Separator)) {
+			continue
+		}
+		col, err := loadCollection(file)
+		if err != nil || col == nil || col.Type.Type != deckType {
+			continue
+		}
+		decks = append(decks, col)
+	}
+	return decks, nil
+}
+
+// loadCollection reads a collection file of any game, returning nil for
+// files that are not collections
+func loadCollection(path string) (*games.Collection, error) {
+	data, err := cio.ReadCollectionFile(path)
+	if err != nil {
+		return nil, err
+	}
+	item, err := magic.DeserializeAsAnyCollection(path, data)
+	if err != nil {
+		return nil, err
+	}
+	col := item.(*games.CollectionItem).Collection
+	if col.Type.Inner == nil {
+		return nil, nil
+	}
+	return col, nil
+}
+
+func printReport(report games.AnalysisReport) {
+	fmt.Printf("\n📊 %s decks analyzed: %d\n", report.Game, report.Decks)
+	if report.Decks == 0 {
+		return
+	}
+
+	if len(report.Metrics) > 0 {
+		fmt.Printf("\n   Metrics (mean per deck):\n")
+		for _, name := range sortedKeys(report.Metrics) {
+			fmt.Printf("     - %s: %.3f\n", name, report.Metrics[name])
+		}
+	}
+
+	for _, name := range sortedKeys(report.Histograms) {
+		hist := report.Histograms[name]
+		buckets := make([]int, 0, len(hist))
+		for b := range hist {
+			buckets = append(buckets, b)
+		}
+		sort.Ints(buckets)
+		fmt.Printf("\n   %s:\n", name)
+		for _, b := range buckets {
+			fmt.Printf("     %d: %d\n", b, hist[b])
+		}
+	}
+
+	if len(report.Warnings) > 0 {
+		fmt.Printf("\n⚠️  %d warnings:\n", len(report.Warnings))
+		for i, w := range report.Warnings {
+			if i >= maxListed {
+				fmt.Printf("   ... and %d more\n", len(report.Warnings)-maxListed)
+				break
+			}
+			fmt.Printf("   %s\n", w)
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
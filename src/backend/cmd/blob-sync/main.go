@@ -0,0 +1,203 @@
+package main
+
+// Sync blobs from one bucket to another (S3, local or both)
+//
+// Copies every blob under --prefix that is missing from the destination or
+// differs from it. Blobs are compared by MD5 where both listings have one,
+// then by the SHA-256 content hash stored when they were written or the
+// MD5 kept in file:// .attrs sidecars, and by size otherwise. Blobs are
+// copied as stored, compressed, with their sidecars, which `aws s3 sync`
+// does not know about.
+//
+// Nothing is ever deleted from the destination.
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"collections/blob"
+	"collections/logger"
+)
+
+var (
+	prefix  = flag.String("prefix", "", "Only sync keys under this prefix (e.g. games/magic/)")
+	workers = flag.Int("workers", runtime.NumCPU()*4, "Number of blobs to compare and copy concurrently")
+	dryRun  = flag.Bool("dry-run", false, "List the blobs that would be copied without copying them")
+)
+
+func main() {
+	flag.Parse()
+	args := flag.Args()
+	if len(args) != 2 {
+		fmt.Println("Usage: blob-sync [flags] <src-bucket-url> <dst-bucket-url>")
+		fmt.Println("Example: blob-sync --prefix games/magic/ s3://games-collections file://./data-full")
+		fmt.Println("Example: blob-sync --dry-run file://./data-full s3://games-collections")
+		fmt.Println()
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("INFO")
+
+	src, err := blob.NewBucket(ctx, log, args[0])
+	if err != nil {
+		fmt.Printf("Error: failed to open %s: %v\n", args[0], err)
+		os.Exit(1)
+	}
+	defer src.Close(ctx)
+	dst, err := blob.NewBucket(ctx, log, args[1])
+	if err != nil {
+		fmt.Printf("Error: failed to open %s: %v\n", args[1], err)
+		os.Exit(1)
+	}
+	defer dst.Close(ctx)
+
+	log.Infof(ctx, "Syncing %s%s to %s...", args[0], *prefix, args[1])
+	result, err := runSync(ctx, log, src, dst, syncOptions{
+		Prefix:  *prefix,
+		Workers: *workers,
+		DryRun:  *dryRun,
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	verb := "Copied"
+	if *dryRun {
+		verb = "Would copy"
+	}
+	log.Infof(ctx, "✅ %s %d blobs, %d unchanged", verb, result.Copied, result.Unchanged)
+	if result.Errors > 0 {
+		log.Warnf(ctx, "⚠️  %d blobs failed to sync", result.Errors)
+		os.Exit(1)
+	}
+}
+
+type syncOptions struct {
+	Prefix  string
+	Workers int
+	DryRun  bool // Count what would be copied without copying
+}
+
+type syncResult struct {
+	Copied    int // Blobs copied, or that would be with DryRun
+	Unchanged int // Blobs already in the destination
+	Errors    int // Blobs that failed to compare or copy
+}
+
+// runSync copies every blob under opts.Prefix in src that is missing from
+// or differs in dst. Failures on single blobs are logged and counted, and
+// only a failed listing is returned as an error.
+func runSync(
+	ctx context.Context,
+	log *logger.Logger,
+	src, dst *blob.Bucket,
+	opts syncOptions,
+) (syncResult, error) {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+
+	// The destination listing is held in memory so each source key is
+	// compared without a request
+	existing := make(map[string]*blob.Attributes)
+	it := dst.List(ctx, &blob.OptListPrefix{Prefix: opts.Prefix})
+	for it.Next(ctx) {
+		existing[it.Key()] = it.Attributes()
+	}
+	if err := it.Err(); err != nil {
+		return syncResult{}, fmt.Errorf("failed to list destination: %w", err)
+	}
+
+	var copied, unchanged, errs atomic.Int64
+	keys := make(chan *blob.Attributes, opts.Workers*2)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for attrs := range keys {
+				changed, err := blobChanged(ctx, src, dst, attrs, existing[attrs.Key])
+				if err != nil {
+					log.Errorf(ctx, "Failed to compare %s: %v", attrs.Key, err)
+					errs.Add(1)
+					continue
+				}
+				if !changed {
+					unchanged.Add(1)
+					continue
+				}
+				if opts.DryRun {
+					log.Infof(ctx, "Would copy %s (%d bytes)", attrs.Key, attrs.Size)
+					copied.Add(1)
+					continue
+				}
+				if err := src.CopyTo(ctx, dst, attrs.Key); err != nil {
+					log.Errorf(ctx, "Failed to copy %s: %v", attrs.Key, err)
+					errs.Add(1)
+					continue
+				}
+				if n := copied.Add(1); n%1000 == 0 {
+					log.Infof(ctx, "Copied %d blobs...", n)
+				}
+			}
+		}()
+	}
+
+	it = src.List(ctx, &blob.OptListPrefix{Prefix: opts.Prefix})
+	for it.Next(ctx) {
+		keys <- it.Attributes()
+	}
+	close(keys)
+	wg.Wait()
+
+	result := syncResult{
+		Copied:    int(copied.Load()),
+		Unchanged: int(unchanged.Load()),
+		Errors:    int(errs.Load()),
+	}
+	if err := it.Err(); err != nil {
+		return result, fmt.Errorf("failed to list source: %w", err)
+	}
+	return result, nil
+}
+
+// blobChanged reports whether the source blob needs copying over have,
+// the destination's listed attributes (nil if missing). Listed MD5s are
+// compared when both sides have one; otherwise both sides are read with
+// Stat and compared by the content hash Write stores, then by MD5. If
+// neither side has both, the sizes decide.
+func blobChanged(ctx context.Context, src, dst *blob.Bucket, want, have *blob.Attributes) (bool, error) {
+	if have == nil {
+		return true, nil
+	}
+	if want.Size != have.Size {
+		return true, nil
+	}
+	if want.MD5 != nil && have.MD5 != nil {
+		return !bytes.Equal(want.MD5, have.MD5), nil
+	}
+	want, err := src.Stat(ctx, want.Key)
+	if err != nil {
+		return false, err
+	}
+	if have, err = dst.Stat(ctx, have.Key); err != nil {
+		return false, err
+	}
+	switch {
+	case want.SHA256 != "" && have.SHA256 != "":
+		return want.SHA256 != have.SHA256, nil
+	case want.MD5 != nil && have.MD5 != nil:
+		return !bytes.Equal(want.MD5, have.MD5), nil
+	}
+	return false, nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"collections/blob"
+	"collections/logger"
+)
+
+func TestRunSync(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	src, err := blob.NewBucket(ctx, log, "file://"+t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close(ctx)
+	dst := blob.NewMemBucket(ctx, log)
+	defer dst.Close(ctx)
+
+	write := func(b *blob.Bucket, key, data string) {
+		t.Helper()
+		if err := b.Write(ctx, key, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(src, "games/magic/a.json", `{"id":"a"}`)
+	write(src, "games/magic/b.json", `{"id":"b"}`)
+	write(src, "games/magic/c.json", `{"id":"c"}`)
+	write(src, "games/pokemon/d.json", `{"id":"d"}`)
+	write(dst, "games/magic/a.json", `{"id":"a"}`)
+	// Same size, different contents
+	write(dst, "games/magic/b.json", `{"id":"x"}`)
+
+	opts := syncOptions{Prefix: "games/magic/", Workers: 2, DryRun: true}
+	result, err := runSync(ctx, log, src, dst, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (syncResult{Copied: 2, Unchanged: 1}); result != want {
+		t.Errorf("dry run = %+v, want %+v", result, want)
+	}
+	if ok, _ := dst.Exists(ctx, "games/magic/c.json"); ok {
+		t.Error("dry run copied games/magic/c.json")
+	}
+
+	opts.DryRun = false
+	if result, err = runSync(ctx, log, src, dst, opts); err != nil {
+		t.Fatal(err)
+	}
+	if want := (syncResult{Copied: 2, Unchanged: 1}); result != want {
+		t.Errorf("sync = %+v, want %+v", result, want)
+	}
+	for key, want := range map[string]string{
+		"games/magic/b.json": `{"id":"b"}`,
+		"games/magic/c.json": `{"id":"c"}`,
+	} {
+		got, err := dst.Read(ctx, key)
+		if err != nil || string(got) != want {
+			t.Errorf("dst %s = %q, %v, want %q", key, got, err, want)
+		}
+	}
+	if ok, _ := dst.Exists(ctx, "games/pokemon/d.json"); ok {
+		t.Error("synced games/pokemon/d.json outside the prefix")
+	}
+
+	// Syncing back into the file bucket compares against .attrs sidecars
+	result, err = runSync(ctx, log, dst, src, syncOptions{Prefix: "games/", Workers: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (syncResult{Unchanged: 3}); result != want {
+		t.Errorf("sync back = %+v, want %+v", result, want)
+	}
+}
@@ -6,9 +6,8 @@ import (
 	"os"
 	"path/filepath"
 
+	"collections/cio"
 	"collections/games"
-
-	"github.com/DataDog/zstd"
 )
 
 func main() {
@@ -25,7 +24,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	decompressed, err := zstd.Decompress(nil, data)
+	decompressed, err := cio.Decompress(data)
 	if err != nil {
 		fmt.Printf("Error decompressing: %v\n", err)
 		os.Exit(1)
@@ -27,7 +27,11 @@ func (a *mtgDatasetAdapter) Extract(
 	sc *scraper.Scraper,
 	options ...games.UpdateOption,
 ) error {
-	// Convert games.UpdateOption to magicdataset.UpdateOption
+	return a.inner.Extract(ctx, sc, magicUpdateOptions(options...)...)
+}
+
+// magicUpdateOptions converts games.UpdateOption to magicdataset.UpdateOption
+func magicUpdateOptions(options ...games.UpdateOption) []magicdataset.UpdateOption {
 	magicOpts := make([]magicdataset.UpdateOption, 0, len(options))
 	for _, opt := range options {
 		switch opt := opt.(type) {
@@ -55,7 +59,7 @@ func (a *mtgDatasetAdapter) Extract(
 			magicOpts = append(magicOpts, &magicdataset.OptExtractItemCat{})
 		}
 	}
-	return a.inner.Extract(ctx, sc, magicOpts...)
+	return magicOpts
 }
 
 func (a *mtgDatasetAdapter) IterItems(
@@ -10,17 +10,21 @@ import (
 	"github.com/spf13/pflag"
 
 	"collections/games"
+	"collections/games/digimon/dataset/digimonmeta"
+	digimonlimitless "collections/games/digimon/dataset/limitless"
+	digimonlimitlessweb "collections/games/digimon/dataset/limitless-web"
+	"collections/games/magic/dataset/archidekt"
 	"collections/games/magic/dataset/deckbox"
 	"collections/games/magic/dataset/goldfish"
+	"collections/games/magic/dataset/moxfield"
 	"collections/games/magic/dataset/mtgtop8"
 	"collections/games/magic/dataset/scryfall"
-	digimonlimitless "collections/games/digimon/dataset/limitless"
-	digimonlimitlessweb "collections/games/digimon/dataset/limitless-web"
 	onepiecelimitless "collections/games/onepiece/dataset/limitless"
 	onepiecelimitlessweb "collections/games/onepiece/dataset/limitless-web"
-	riftboundriftmana "collections/games/riftbound/dataset/riftmana"
-	riftboundriftcodex "collections/games/riftbound/dataset/riftcodex"
+	"collections/games/onepiece/dataset/onepiecetopdecks"
 	riftboundriftboundgg "collections/games/riftbound/dataset/riftboundgg"
+	riftboundriftcodex "collections/games/riftbound/dataset/riftcodex"
+	riftboundriftmana "collections/games/riftbound/dataset/riftmana"
 	"collections/logger"
 	"collections/scraper"
 )
@@ -43,6 +47,7 @@ func init() {
 	flags.StringArrayP("only", "o", nil, "update only the given urls, if provided")
 	flags.StringP("section", "S", "", "which section to parse")
 	flags.Bool("cat", false, "whether to print out json lines of extracted items")
+	flags.Bool("repair-metadata", false, "after extracting, fill in deck metadata from event pages (mtgtop8 event names, players, placements)")
 }
 
 func runExtract(cmd *cobra.Command, args []string) error {
@@ -63,6 +68,7 @@ func runExtract(cmd *cobra.Command, args []string) error {
 	scraper := scraper.NewScraper(config.Log, scraperBlob)
 
 	var d games.Dataset
+	var top8 *mtgtop8.Dataset // Set for mtgtop8, which supports --repair-metadata
 	datasetName := strings.ToLower(args[0])
 	switch datasetName {
 	case "deckbox":
@@ -71,16 +77,25 @@ func runExtract(cmd *cobra.Command, args []string) error {
 		d = wrapMTGDataset(scryfall.NewDataset(config.Log, gamesBlob))
 	case "goldfish":
 		d = wrapMTGDataset(goldfish.NewDataset(config.Log, gamesBlob))
+	case "moxfield":
+		d = wrapMTGDataset(moxfield.NewDataset(config.Log, gamesBlob))
+	case "archidekt":
+		d = wrapMTGDataset(archidekt.NewDataset(config.Log, gamesBlob))
 	case "mtgtop8":
-		d = wrapMTGDataset(mtgtop8.NewDataset(config.Log, gamesBlob))
+		top8 = mtgtop8.NewDataset(config.Log, gamesBlob)
+		d = wrapMTGDataset(top8)
 	case "digimon-limitless", "digimonlimitless":
 		d = digimonlimitless.NewDataset(config.Log, gamesBlob)
 	case "digimon-limitless-web", "digimonlimitlessweb":
 		d = digimonlimitlessweb.NewDataset(config.Log, gamesBlob)
+	case "digimon-digimonmeta", "digimonmeta":
+		d = digimonmeta.NewDataset(config.Log, gamesBlob)
 	case "onepiece-limitless", "onepiecelimitless":
 		d = onepiecelimitless.NewDataset(config.Log, gamesBlob)
 	case "onepiece-limitless-web", "onepiecelimitlessweb":
 		d = onepiecelimitlessweb.NewDataset(config.Log, gamesBlob)
+	case "onepiece-onepiecetopdecks", "onepiecetopdecks":
+		d = onepiecetopdecks.NewDataset(config.Log, gamesBlob)
 	case "riftbound-riftmana", "riftboundriftmana":
 		dataset, err := riftboundriftmana.NewDataset(config.Log, gamesBlob)
 		if err != nil {
@@ -89,21 +104,29 @@ func runExtract(cmd *cobra.Command, args []string) error {
 		d = dataset
 	case "riftbound-riftcodex", "riftboundriftcodex":
 		d = riftboundriftcodex.NewDataset(config.Log, gamesBlob)
-		case "riftbound-riftboundgg", "riftboundriftboundgg", "riftbound-gg":
-			dataset, err := riftboundriftboundgg.NewDataset(config.Log, gamesBlob)
-			if err != nil {
-				return fmt.Errorf("failed to create riftbound.gg dataset: %w", err)
-			}
-			d = dataset
+	case "riftbound-riftboundgg", "riftboundriftboundgg", "riftbound-gg":
+		dataset, err := riftboundriftboundgg.NewDataset(config.Log, gamesBlob)
+		if err != nil {
+			return fmt.Errorf("failed to create riftbound.gg dataset: %w", err)
+		}
+		d = dataset
 	default:
 		return fmt.Errorf(
 			"unsupported dataset %q, allowed (%+v)",
 			datasetName,
-			[]string{"deckbox", "scryfall", "goldfish", "mtgtop8", "digimon-limitless", "digimon-limitless-web", "onepiece-limitless", "onepiece-limitless-web", "riftbound-riftmana", "riftbound-riftcodex", "riftbound-riftboundgg"},
+			[]string{"deckbox", "scryfall", "goldfish", "mtgtop8", "moxfield", "archidekt", "digimon-limitless", "digimon-limitless-web", "digimon-digimonmeta", "onepiece-limitless", "onepiece-limitless-web", "onepiece-onepiecetopdecks", "riftbound-riftmana", "riftbound-riftcodex", "riftbound-riftboundgg"},
 		)
 	}
 	opts := parseOptions(config.Ctx, config.Log, cmd.Flags())
 
+	repairMetadata, err := cmd.Flags().GetBool("repair-metadata")
+	if err != nil {
+		return err
+	}
+	if repairMetadata && top8 == nil {
+		return fmt.Errorf("--repair-metadata is not supported for dataset %q", datasetName)
+	}
+
 	// Create stats tracker and progress reporter for extraction metrics
 	stats := games.NewExtractStats(config.Log)
 	progress := games.NewProgressReporter(config.Log, d.Description().Name, 30*time.Second)
@@ -113,7 +136,8 @@ func runExtract(cmd *cobra.Command, args []string) error {
 
 	config.Log.Infof(ctxWithStats, "🚀 Starting extraction for dataset: %s", d.Description().Name)
 
-	if err := d.Extract(ctxWithStats, scraper, opts...); err != nil {
+	result, err := games.ExtractWithResult(ctxWithStats, d, scraper, opts...)
+	if err != nil {
 		stats.RecordError(config.Ctx, "", d.Description().Name, err)
 		progress.IncrementFailed()
 		config.Log.Errorf(config.Ctx, "Extraction failed: %v", err)
@@ -127,6 +151,7 @@ func runExtract(cmd *cobra.Command, args []string) error {
 
 	// Display extraction summary with quality metrics
 	config.Log.Infof(config.Ctx, "✅ Extraction complete: %s", stats.Summary())
+	config.Log.Infof(config.Ctx, "📦 %d new, %d skipped, %d errors", result.New, result.Skipped, result.Errors)
 
 	// Show quality metrics
 	if stats.NormalizedCount > 0 {
@@ -144,6 +169,14 @@ func runExtract(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if repairMetadata {
+		n, err := top8.EnrichEvents(config.Ctx, scraper, magicUpdateOptions(opts...)...)
+		if err != nil {
+			return fmt.Errorf("failed to repair metadata: %w", err)
+		}
+		config.Log.Infof(config.Ctx, "🔧 Updated event metadata on %d decks", n)
+	}
+
 	// Show recent errors if any
 	errors := stats.GetErrors()
 	if len(errors) > 0 {
@@ -6,17 +6,21 @@ import (
 	"github.com/spf13/cobra"
 
 	"collections/games"
+	"collections/games/digimon/dataset/digimonmeta"
+	digimonlimitless "collections/games/digimon/dataset/limitless"
+	digimonlimitlessweb "collections/games/digimon/dataset/limitless-web"
+	"collections/games/magic/dataset/archidekt"
 	"collections/games/magic/dataset/deckbox"
 	"collections/games/magic/dataset/goldfish"
+	"collections/games/magic/dataset/moxfield"
 	"collections/games/magic/dataset/mtgtop8"
 	"collections/games/magic/dataset/scryfall"
-	digimonlimitless "collections/games/digimon/dataset/limitless"
-	digimonlimitlessweb "collections/games/digimon/dataset/limitless-web"
 	onepiecelimitless "collections/games/onepiece/dataset/limitless"
 	onepiecelimitlessweb "collections/games/onepiece/dataset/limitless-web"
-	riftboundriftmana "collections/games/riftbound/dataset/riftmana"
-	riftboundriftcodex "collections/games/riftbound/dataset/riftcodex"
+	"collections/games/onepiece/dataset/onepiecetopdecks"
 	riftboundriftboundgg "collections/games/riftbound/dataset/riftboundgg"
+	riftboundriftcodex "collections/games/riftbound/dataset/riftcodex"
+	riftboundriftmana "collections/games/riftbound/dataset/riftmana"
 	"collections/scraper"
 )
 
@@ -68,16 +72,24 @@ func runHealth(cmd *cobra.Command, args []string) error {
 			d = wrapMTGDataset(scryfall.NewDataset(config.Log, gamesBlob))
 		case "goldfish":
 			d = wrapMTGDataset(goldfish.NewDataset(config.Log, gamesBlob))
+		case "moxfield":
+			d = wrapMTGDataset(moxfield.NewDataset(config.Log, gamesBlob))
+		case "archidekt":
+			d = wrapMTGDataset(archidekt.NewDataset(config.Log, gamesBlob))
 		case "mtgtop8":
 			d = wrapMTGDataset(mtgtop8.NewDataset(config.Log, gamesBlob))
 		case "digimon-limitless", "digimonlimitless":
 			d = digimonlimitless.NewDataset(config.Log, gamesBlob)
 		case "digimon-limitless-web", "digimonlimitlessweb":
 			d = digimonlimitlessweb.NewDataset(config.Log, gamesBlob)
+		case "digimon-digimonmeta", "digimonmeta":
+			d = digimonmeta.NewDataset(config.Log, gamesBlob)
 		case "onepiece-limitless", "onepiecelimitless":
 			d = onepiecelimitless.NewDataset(config.Log, gamesBlob)
 		case "onepiece-limitless-web", "onepiecelimitlessweb":
 			d = onepiecelimitlessweb.NewDataset(config.Log, gamesBlob)
+		case "onepiece-onepiecetopdecks", "onepiecetopdecks":
+			d = onepiecetopdecks.NewDataset(config.Log, gamesBlob)
 		case "riftbound-riftmana", "riftboundriftmana":
 			dataset, err := riftboundriftmana.NewDataset(config.Log, gamesBlob)
 			if err != nil {
@@ -0,0 +1,196 @@
+package main
+
+// Find tournament decks scraped from more than one source (the same Pro
+// Tour list on goldfish and mtgtop8) and keep one copy, so they aren't
+// counted twice in the co-occurrence graph.
+//
+// Decks are grouped by games.DeckFingerprint; in each group the copy with
+// the highest games.MetadataScore is kept. Without --delete the groups are
+// only reported.
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"collections/blob"
+	"collections/games"
+	_ "collections/games/digimon/game" // Register collection types
+	mtg "collections/games/magic/game"
+	_ "collections/games/onepiece/game"  // Register collection types
+	_ "collections/games/pokemon/game"   // Register collection types
+	_ "collections/games/riftbound/game" // Register collection types
+	_ "collections/games/yugioh/game"    // Register collection types
+	"collections/logger"
+)
+
+var (
+	bucketURL  = flag.String("bucket", "s3://games-collections", "Bucket holding the games/ prefix")
+	cacheDir   = flag.String("cache", "", "Dir of the local blob cache, if any")
+	deleteDups = flag.Bool("delete", false, "Delete the duplicate copies; without it they are only reported")
+	showGroups = flag.Int("show", 20, "Duplicate groups to list")
+)
+
+// deckCopy is one stored copy of a deck
+type deckCopy struct {
+	Key    string  `json:"key"`
+	Source string  `json:"source"`
+	Score  float64 `json:"score"` // games.MetadataScore
+}
+
+// richer reports whether a should be kept over b: the higher metadata
+// score wins, then the smaller key so reruns keep the same copy
+func richer(a, b deckCopy) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return a.Key < b.Key
+}
+
+// loadCollection decodes a stored collection of any game, returning nil
+// for collections that aren't decks
+func loadCollection(key string, data []byte) (*games.Collection, error) {
+	item, err := mtg.DeserializeAsAnyCollection(key, data)
+	if err != nil {
+		return nil, err
+	}
+	col := item.(*games.CollectionItem).Collection
+	if _, ok := col.Type.DeckMetadata(); !ok {
+		return nil, nil
+	}
+	return col, nil
+}
+
+// dupGroup is a deck stored more than once
+type dupGroup struct {
+	Fingerprint string     `json:"fingerprint"`
+	Keep        deckCopy   `json:"keep"`
+	Drop        []deckCopy `json:"drop"`
+}
+
+type dedupOptions struct {
+	Delete bool
+}
+
+type dedupResult struct {
+	Decks      int        // Deck collections read
+	Skipped    int        // Other collection types
+	Failed     int        // Unreadable collections
+	Duplicates int        // Copies dropped (or that would be, without Delete)
+	Deleted    int        // Copies deleted
+	Groups     []dupGroup // Sorted by fingerprint
+}
+
+// dedup groups the deck collections under prefixes of b (the games/
+// prefix) by fingerprint and, with opts.Delete, deletes all but the
+// richest copy of each
+func dedup(ctx context.Context, log *logger.Logger, b *blob.Bucket, prefixes []string, opts dedupOptions) (dedupResult, error) {
+	var result dedupResult
+	copies := make(map[string][]deckCopy)
+	for _, prefix := range prefixes {
+		it := b.List(ctx, &blob.OptListPrefix{Prefix: prefix})
+		for it.Next(ctx) {
+			key := it.Key()
+			data, err := b.Read(ctx, key)
+			if err != nil {
+				return result, fmt.Errorf("failed to read %s: %w", key, err)
+			}
+			col, err := loadCollection(key, data)
+			if err != nil {
+				log.Warnf(ctx, "failed to unmarshal collection %s: %v", key, err)
+				result.Failed++
+				continue
+			}
+			if col == nil {
+				result.Skipped++
+				continue
+			}
+			result.Decks++
+			fp := games.DeckFingerprint(col)
+			copies[fp] = append(copies[fp], deckCopy{Key: key, Source: string(col.Source), Score: games.MetadataScore(col)})
+		}
+		if err := it.Err(); err != nil {
+			return result, err
+		}
+	}
+
+	for fp, cs := range copies {
+		if len(cs) < 2 {
+			continue
+		}
+		sort.Slice(cs, func(i, j int) bool { return richer(cs[i], cs[j]) })
+		result.Groups = append(result.Groups, dupGroup{Fingerprint: fp, Keep: cs[0], Drop: cs[1:]})
+		result.Duplicates += len(cs) - 1
+	}
+	sort.Slice(result.Groups, func(i, j int) bool { return result.Groups[i].Fingerprint < result.Groups[j].Fingerprint })
+
+	if !opts.Delete {
+		return result, nil
+	}
+	for _, g := range result.Groups {
+		for _, c := range g.Drop {
+			if err := b.Delete(ctx, c.Key); err != nil {
+				return result, fmt.Errorf("failed to delete %s: %w", c.Key, err)
+			}
+			result.Deleted++
+		}
+	}
+	return result, nil
+}
+
+func main() {
+	flag.Parse()
+	prefixes := flag.Args()
+	if len(prefixes) == 0 {
+		fmt.Println("Usage: dedup-decks [--bucket URL] [--cache DIR] [--delete] [--show N] <prefix>...")
+		fmt.Println("Example: dedup-decks --bucket file://./data-full magic/")
+		fmt.Println("Example: dedup-decks --delete magic/mtgtop8/ magic/goldfish/")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("INFO")
+
+	var bucketOpts []blob.BucketOption
+	if *cacheDir != "" {
+		bucketOpts = append(bucketOpts, &blob.OptBucketCache{Dir: *cacheDir})
+	}
+	bucket, err := blob.NewBucket(ctx, log, *bucketURL, bucketOpts...)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer bucket.Close(ctx)
+
+	if !*deleteDups {
+		fmt.Println("🔍 REPORT ONLY - rerun with --delete to remove duplicates")
+	}
+	result, err := dedup(ctx, log, bucket.WithPrefix("games/"), prefixes, dedupOptions{Delete: *deleteDups})
+	fmt.Printf("\n📊 Deduplicated decks under %v:\n", prefixes)
+	fmt.Printf("   Decks:      %d\n", result.Decks)
+	fmt.Printf("   Skipped:    %d (not decks)\n", result.Skipped)
+	if result.Failed > 0 {
+		fmt.Printf("   Failed:     %d\n", result.Failed)
+	}
+	fmt.Printf("   Duplicated: %d decks, %d extra copies\n", len(result.Groups), result.Duplicates)
+	if *deleteDups {
+		fmt.Printf("   Deleted:    %d\n", result.Deleted)
+	}
+	for i, g := range result.Groups {
+		if i == *showGroups {
+			fmt.Printf("   ... and %d more\n", len(result.Groups)-i)
+			break
+		}
+		fmt.Printf("   ✅ keep %s (%s, %.2f)\n", g.Keep.Key, g.Keep.Source, g.Keep.Score)
+		for _, c := range g.Drop {
+			fmt.Printf("      ❌ %s (%s, %.2f)\n", c.Key, c.Source, c.Score)
+		}
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"collections/blob"
+	"collections/games"
+	mtg "collections/games/magic/game"
+	ygo "collections/games/yugioh/game"
+	"collections/logger"
+)
+
+func TestDedup(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+
+	write := func(key string, v any) {
+		t.Helper()
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := bucket.Write(ctx, "games/"+key, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	main := games.Partition{Name: "Main Deck", Cards: []games.CardDesc{{Name: "Ash Blossom & Joyous Spring", Count: 3}, {Name: "Maxx \"C\"", Count: 3}}}
+	extra := games.Partition{Name: "Extra Deck", Cards: []games.CardDesc{{Name: "Accesscode Talker", Count: 1}}}
+	ygoDeck := func(source games.Source, inner *ygo.CollectionTypeDeck, partitions ...games.Partition) games.Collection {
+		return games.Collection{
+			ID:         string(source),
+			Type:       games.CollectionTypeWrapper{Type: "YGODeck", Inner: inner},
+			Partitions: partitions,
+			Source:     source,
+		}
+	}
+	placement := 1
+	write("yugioh/ygoprodeck/a.json", ygoDeck(games.SourceYGOPRODeck,
+		&ygo.CollectionTypeDeck{Name: "Tenpai", Player: "Jessy Grospe", Event: "YCS Lille"},
+		main, extra))
+	// The same list with the partitions the other way round and more metadata
+	write("yugioh/ygoprodeck/b.json", ygoDeck(games.SourceYGOPRODeck,
+		&ygo.CollectionTypeDeck{Name: "Tenpai", Format: "TCG", Player: "Jessy Grospe", Event: "YCS Lille", Placement: &placement},
+		extra, main))
+	write("yugioh/ygoprodeck/c.json", ygoDeck(games.SourceYGOPRODeck,
+		&ygo.CollectionTypeDeck{Name: "Tenpai", Player: "Someone Else", Event: "YCS Lille"},
+		main, extra))
+
+	magic := func(format string) mtg.Collection {
+		return mtg.Collection{
+			ID:         "burn",
+			URL:        "https://www.mtgtop8.com/event?e=1&d=1",
+			Type:       mtg.CollectionTypeWrapper{Type: mtg.CollectionTypeDeck{}.Type(), Inner: &mtg.CollectionTypeDeck{Name: "Burn", Format: format, Player: "Kai Budde"}},
+			Partitions: []mtg.Partition{{Name: "Main", Cards: []mtg.CardDesc{{Name: "Lightning Bolt", Count: 4}}}},
+		}
+	}
+	write("magic/mtgtop8/burn.json", magic(""))
+	write("magic/goldfish/burn.json", magic("Modern"))
+	write("magic/scryfall/lea.json", mtg.Collection{
+		ID:   "lea",
+		Type: mtg.CollectionTypeWrapper{Type: mtg.CollectionTypeSet{}.Type(), Inner: &mtg.CollectionTypeSet{Name: "Alpha", Code: "LEA"}},
+	})
+
+	b := bucket.WithPrefix("games/")
+	result, err := dedup(ctx, log, b, []string{"yugioh/", "magic/"}, dedupOptions{})
+	if err != nil {
+		t.Fatalf("dedup() error = %v", err)
+	}
+	if result.Decks != 5 || result.Skipped != 1 || result.Failed != 0 || result.Duplicates != 2 || result.Deleted != 0 {
+		t.Fatalf("dedup() = %+v", result)
+	}
+
+	result, err = dedup(ctx, log, b, []string{"yugioh/", "magic/"}, dedupOptions{Delete: true})
+	if err != nil {
+		t.Fatalf("dedup(Delete) error = %v", err)
+	}
+	if result.Deleted != 2 {
+		t.Errorf("dedup(Delete) deleted %d, want 2", result.Deleted)
+	}
+	for key, want := range map[string]bool{
+		"yugioh/ygoprodeck/a.json": false,
+		"yugioh/ygoprodeck/b.json": true,
+		"yugioh/ygoprodeck/c.json": true,
+		"magic/mtgtop8/burn.json":  false,
+		"magic/goldfish/burn.json": true,
+		"magic/scryfall/lea.json":  true,
+	} {
+		got, err := b.Exists(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%s exists = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package main
+
+// Deduplicate a JSONL file (e.g. concatenated incremental exports) by a
+// record key, keeping the first or last record per key. The seen-set
+// spills to an on-disk index once it outgrows --max-mem-keys.
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dgraph-io/badger/v3"
+
+	"collections/cio"
+)
+
+var (
+	keyField   = flag.String("key", "deck_id", "Record field to deduplicate on")
+	keep       = flag.String("keep", keepLast, "Which record to keep per key: first or last")
+	maxMemKeys = flag.Int("max-mem-keys", 1_000_000, "Keys held in memory before the seen-set spills to disk")
+)
+
+const (
+	keepFirst = "first"
+	keepLast  = "last"
+)
+
+// keyIndex maps record keys to the line that wins for them. It starts as
+// a map and moves to a temporary badger database past maxMem keys.
+type keyIndex struct {
+	mem    map[string]int64
+	maxMem int
+	dir    string
+	db     *badger.DB
+}
+
+func newKeyIndex(maxMem int) *keyIndex {
+	return &keyIndex{mem: make(map[string]int64), maxMem: maxMem}
+}
+
+func (x *keyIndex) get(key string) (int64, bool, error) {
+	if x.db == nil {
+		line, ok := x.mem[key]
+		return line, ok, nil
+	}
+	var line int64
+	err := x.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+		return item.Value(func(v []byte) error {
+			line = int64(binary.BigEndian.Uint64(v))
+			return nil
+		})
+	})
+	if err == badger.ErrKeyNotFound {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return line, true, nil
+}
+
+func (x *keyIndex) put(key string, line int64) error {
+	if x.db == nil {
+		x.mem[key] = line
+		if len(x.mem) <= x.maxMem {
+			return nil
+		}
+		return x.spill()
+	}
+	return x.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(key), encodeLine(line))
+	})
+}
+
+// spill moves the in-memory keys to a new on-disk index
+func (x *keyIndex) spill() error {
+	dir, err := os.MkdirTemp("", "dedupe-jsonl")
+	if err != nil {
+		return err
+	}
+	opts := badger.DefaultOptions(dir)
+	opts.Logger = nil
+	db, err := badger.Open(opts)
+	if err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+	wb := db.NewWriteBatch()
+	for key, line := range x.mem {
+		if err := wb.Set([]byte(key), encodeLine(line)); err != nil {
+			wb.Cancel()
+			db.Close()
+			os.RemoveAll(dir)
+			return err
+		}
+	}
+	if err := wb.Flush(); err != nil {
+		db.Close()
+		os.RemoveAll(dir)
+		return err
+	}
+	x.dir, x.db, x.mem = dir, db, nil
+	return nil
+}
+
+func (x *keyIndex) spilled() bool {
+	return x.db != nil
+}
+
+func (x *keyIndex) close() error {
+	if x.db == nil {
+		return nil
+	}
+	err := x.db.Close()
+	if rmErr := os.RemoveAll(x.dir); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+func encodeLine(line int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(line))
+	return b
+}
+
+// recordKey returns the raw JSON of the key field, or "" when the line is
+// malformed or has no such field
+func recordKey(data []byte, field string) string {
+	var rec map[string]json.RawMessage
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return ""
+	}
+	v, ok := rec[field]
+	if !ok || bytes.Equal(v, []byte("null")) {
+		return ""
+	}
+	return string(v)
+}
+
+type stats struct {
+	records    int
+	written    int
+	duplicates int
+	unkeyed    int // malformed or missing the key; passed through unchanged
+}
+
+// forEachLine calls fn with every non-blank line of the file at path and
+// its 0-based line number
+func forEachLine(path string, fn func(line int64, data []byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := cio.NewJSONLScanner(f)
+	var line int64
+	for ; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		if err := fn(line, sc.Bytes()); err != nil {
+			return fmt.Errorf("line %d: %w", line+1, err)
+		}
+	}
+	return sc.Err()
+}
+
+// dedupe writes one record per key from inPath to w, keeping the first or
+// last occurrence. Kept records stay in input order; keeping the last
+// occurrence takes a second pass over the input.
+func dedupe(inPath string, w io.Writer, field, policy string, index *keyIndex) (*stats, error) {
+	st := &stats{}
+	bw := bufio.NewWriter(w)
+	write := func(data []byte) error {
+		st.written++
+		if _, err := bw.Write(data); err != nil {
+			return err
+		}
+		return bw.WriteByte('\n')
+	}
+
+	switch policy {
+	case keepFirst:
+		err := forEachLine(inPath, func(line int64, data []byte) error {
+			st.records++
+			key := recordKey(data, field)
+			if key == "" {
+				st.unkeyed++
+				return write(data)
+			}
+			_, seen, err := index.get(key)
+			if err != nil {
+				return err
+			}
+			if seen {
+				st.duplicates++
+				return nil
+			}
+			if err := index.put(key, line); err != nil {
+				return err
+			}
+			return write(data)
+		})
+		if err != nil {
+			return st, err
+		}
+	case keepLast:
+		err := forEachLine(inPath, func(line int64, data []byte) error {
+			if key := recordKey(data, field); key != "" {
+				return index.put(key, line)
+			}
+			return nil
+		})
+		if err != nil {
+			return st, err
+		}
+		err = forEachLine(inPath, func(line int64, data []byte) error {
+			st.records++
+			key := recordKey(data, field)
+			if key == "" {
+				st.unkeyed++
+				return write(data)
+			}
+			last, _, err := index.get(key)
+			if err != nil {
+				return err
+			}
+			if last != line {
+				st.duplicates++
+				return nil
+			}
+			return write(data)
+		})
+		if err != nil {
+			return st, err
+		}
+	default:
+		return st, fmt.Errorf("unknown keep policy %q (supported: first, last)", policy)
+	}
+	return st, bw.Flush()
+}
+
+func main() {
+	flag.Parse()
+	args := flag.Args()
+	if len(args) < 2 {
+		fmt.Println("Usage: dedupe-jsonl [--key deck_id] [--keep first|last] [--max-mem-keys N] <in.jsonl> <out.jsonl>")
+		os.Exit(1)
+	}
+	inPath, outPath := args[0], args[1]
+	if inPath == outPath {
+		fmt.Println("Error: input and output must be different files")
+		os.Exit(1)
+	}
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	index := newKeyIndex(*maxMemKeys)
+	st, err := dedupe(inPath, out, *keyField, *keep, index)
+	if closeErr := index.close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✓ Deduplicated %s on %q (keeping %s)\n", inPath, *keyField, *keep)
+	fmt.Printf("   Records read: %d\n", st.records)
+	fmt.Printf("   Duplicates dropped: %d\n", st.duplicates)
+	if st.unkeyed > 0 {
+		fmt.Printf("   ⚠️  Records without %q kept as-is: %d\n", *keyField, st.unkeyed)
+	}
+	if index.spilled() {
+		fmt.Println("   (Seen-set spilled to disk)")
+	}
+	fmt.Printf("✅ Wrote %d records to %s\n", st.written, outPath)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDedupe(t *testing.T) {
+	lines := []string{
+		`{"deck_id":"a","version":1}`,
+		`{"deck_id":"b","version":1}`,
+		`{"deck_id":"a","version":2}`,
+		`{"version":1}`,
+		`{"deck_id":"c","version":1}`,
+		`{"deck_id":"b","version":2}`,
+	}
+	in := filepath.Join(t.TempDir(), "in.jsonl")
+	if err := os.WriteFile(in, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		keep   string
+		maxMem int
+		want   []string
+	}{
+		{keepFirst, 100, []string{lines[0], lines[1], lines[3], lines[4]}},
+		{keepLast, 100, []string{lines[2], lines[3], lines[4], lines[5]}},
+		// Spill the seen-set to disk after the first key
+		{keepFirst, 1, []string{lines[0], lines[1], lines[3], lines[4]}},
+		{keepLast, 1, []string{lines[2], lines[3], lines[4], lines[5]}},
+	}
+	for _, tt := range tests {
+		index := newKeyIndex(tt.maxMem)
+		var out bytes.Buffer
+		st, err := dedupe(in, &out, "deck_id", tt.keep, index)
+		if err != nil {
+			t.Fatalf("dedupe(%s, max %d) error = %v", tt.keep, tt.maxMem, err)
+		}
+		if spilled := index.spilled(); spilled != (tt.maxMem == 1) {
+			t.Errorf("dedupe(%s, max %d) spilled = %v", tt.keep, tt.maxMem, spilled)
+		}
+		if err := index.close(); err != nil {
+			t.Fatal(err)
+		}
+
+		got := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("dedupe(%s, max %d) =\n%s\nwant\n%s", tt.keep, tt.maxMem, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+		if st.records != 6 || st.duplicates != 2 || st.unkeyed != 1 || st.written != 4 {
+			t.Errorf("dedupe(%s, max %d) stats = %+v", tt.keep, tt.maxMem, *st)
+		}
+	}
+}
+
+func TestDedupeUnknownPolicy(t *testing.T) {
+	in := filepath.Join(t.TempDir(), "in.jsonl")
+	if err := os.WriteFile(in, []byte(`{"deck_id":"a"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dedupe(in, &bytes.Buffer{}, "deck_id", "middle", newKeyIndex(10)); err == nil {
+		t.Error("dedupe(middle) error = nil, want error")
+	}
+}
@@ -9,7 +9,7 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/DataDog/zstd"
+	"collections/cio"
 )
 
 func getKeys(m map[string]interface{}) []string {
@@ -30,15 +30,9 @@ func main() {
 	fmt.Println("Diagnosing metadata in:", dataDir)
 	fmt.Println()
 
-	var files []string
-	filepath.Walk(dataDir, func(path string, info os.FileInfo, err error) error {
-		if err == nil && !info.IsDir() && filepath.Ext(path) == ".zst" {
-			files = append(files, path)
-		}
-		return nil
-	})
+	files, _ := cio.FindCollectionFiles(dataDir, cio.FindOpts{SkipErrors: true})
 
-	fmt.Printf("Found %d .zst files\n", len(files))
+	fmt.Printf("Found %d collection files\n", len(files))
 	fmt.Println("Checking first 5 files...")
 
 	stats := struct {
@@ -67,7 +61,7 @@ func main() {
 		}
 
 		// Decompress
-		decompressed, err := zstd.Decompress(nil, data)
+		decompressed, err := cio.DecodeCollectionData(file, data)
 		if err != nil {
 			fmt.Printf("  ❌ Decompress error: %v\n\n", err)
 			stats.decompressErrors++
@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 
 	"collections/blob"
+	"collections/games"
 	"collections/games/magic/dataset"
 	"collections/games/magic/dataset/mtgtop8"
 	"collections/games/magic/dataset/scryfall"
@@ -13,17 +15,29 @@ import (
 	"collections/transform/cardco"
 )
 
+var (
+	sourceWeights = make(cardco.SourceWeights)
+	weightSum     = flag.Bool("weight-sum", false, "Add a WEIGHT_SUM column: the summed weight of the collections each pair occurs in")
+	formats       games.FormatFilter
+)
+
+func init() {
+	flag.Var(sourceWeights, "source-weight", "Scale a source's (or collection type's) counts: source=factor, repeatable; sources accept their aliases (e.g. mtggoldfish). Set and Cube default to 0")
+	flag.Var(&formats, "format", "Only include decks of this format (repeatable; case-insensitive, abbreviations like \"mod\" allowed); sets, cubes and decks without a format are skipped")
+}
+
 func main() {
 	ctx := context.Background()
 	log := logger.NewLogger(ctx)
 	log.SetLevel("INFO")
 
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run main.go <output.csv>")
+	flag.Parse()
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: go run main.go [--source-weight source=factor]... [--weight-sum] [--format FORMAT]... <output.csv>")
 		os.Exit(1)
 	}
 
-	_ = os.Args[1] // outputFile - TODO: implement CSV export
+	outputFile := flag.Arg(0)
 
 	// Create blob bucket
 	bucket, err := blob.NewBucket(ctx, log, "file://./data-full")
@@ -55,19 +69,24 @@ func main() {
 		log.Errorf(ctx, "Failed to create transform: %v", err)
 		os.Exit(1)
 	}
-	// Note: Transform doesn't have Close() or ExportCSV() - this command needs updating
-	// defer tr.close() // unexported method
+	defer tr.Close()
 
 	// Run transform
 	log.Infof(ctx, "Processing collections...")
-	_, err = tr.Transform(ctx, datasets)
+	options := append(sourceWeights.Options(), cardco.FormatOptions(formats)...)
+	_, err = tr.Transform(ctx, datasets, options...)
 	if err != nil {
 		log.Errorf(ctx, "Transform failed: %v", err)
 		os.Exit(1)
 	}
 
-	// TODO: Transform.ExportCSV() doesn't exist - need to implement or use different export method
-	// For now, this command is incomplete
-	log.Warnf(ctx, "Export functionality not yet implemented in transform")
-	fmt.Printf("\n⚠️  Transform completed but CSV export not implemented\n")
+	var exportOpts []cardco.ExportOption
+	if *weightSum {
+		exportOpts = append(exportOpts, &cardco.OptExportWeightSum{})
+	}
+	if err := tr.ExportCSV(ctx, outputFile, exportOpts...); err != nil {
+		log.Errorf(ctx, "Export failed: %v", err)
+		os.Exit(1)
+	}
+	fmt.Printf("\n✅ Exported pairs to %s\n", outputFile)
 }
@@ -0,0 +1,203 @@
+package main
+
+// Export an archetype-level graph: nodes are archetypes within a format,
+// edges are weighted by how many distinct cards two archetypes share.
+//
+// Output columns: FORMAT, ARCHETYPE_1, ARCHETYPE_2, SHARED_CARDS, JACCARD
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"collections/cio"
+	"collections/games"
+	"collections/games/magic/game"
+)
+
+var (
+	minShared   = flag.Int("min-shared", 1, "Only emit archetype pairs sharing at least this many cards")
+	aliasesFile = flag.String("archetype-aliases", "", "JSON file of format -> {alias: canonical archetype} applied before grouping")
+)
+
+// archetypeIndex maps format -> archetype -> distinct card names seen in
+// any deck of that archetype
+type archetypeIndex map[string]map[string]map[string]bool
+
+// addDeck records a deck's cards under its format and archetype, with the
+// archetype normalized through aliases. Returns false if the collection is
+// not a deck with an archetype.
+func (idx archetypeIndex) addDeck(col *game.Collection, aliases games.ArchetypeAliases) bool {
+	deck, ok := col.Type.Inner.(*game.CollectionTypeDeck)
+	if !ok {
+		return false
+	}
+	archetype := strings.TrimSpace(deck.Archetype)
+	if archetype == "" {
+		return false
+	}
+	archetype = games.NormalizeArchetype(deck.Format, archetype, aliases)
+	format := games.NormalizeFormatName(deck.Format)
+	if format == "" {
+		format = "unknown"
+	}
+
+	if idx[format] == nil {
+		idx[format] = make(map[string]map[string]bool)
+	}
+	cards := idx[format][archetype]
+	if cards == nil {
+		cards = make(map[string]bool)
+		idx[format][archetype] = cards
+	}
+	for _, partition := range col.Partitions {
+		for _, card := range partition.Cards {
+			if name := games.NormalizeCardName(card.Name); name != "" {
+				cards[name] = true
+			}
+		}
+	}
+	return true
+}
+
+type archetypeEdge struct {
+	Format     string
+	Archetype1 string
+	Archetype2 string
+	Shared     int     // Distinct cards played by both archetypes
+	Jaccard    float64 // Shared / distinct cards played by either
+}
+
+// edges returns every archetype pair within a format sharing at least
+// minShared cards, sorted by format then archetype names
+func (idx archetypeIndex) edges(minShared int) []archetypeEdge {
+	var edges []archetypeEdge
+	formats := make([]string, 0, len(idx))
+	for format := range idx {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+
+	for _, format := range formats {
+		archetypes := make([]string, 0, len(idx[format]))
+		for archetype := range idx[format] {
+			archetypes = append(archetypes, archetype)
+		}
+		sort.Strings(archetypes)
+
+		for i, a := range archetypes {
+			for _, b := range archetypes[i+1:] {
+				cardsA, cardsB := idx[format][a], idx[format][b]
+				shared := overlap(cardsA, cardsB)
+				if shared < minShared || shared == 0 {
+					continue
+				}
+				edges = append(edges, archetypeEdge{
+					Format:     format,
+					Archetype1: a,
+					Archetype2: b,
+					Shared:     shared,
+					Jaccard:    float64(shared) / float64(len(cardsA)+len(cardsB)-shared),
+				})
+			}
+		}
+	}
+	return edges
+}
+
+func overlap(a, b map[string]bool) int {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	n := 0
+	for card := range a {
+		if b[card] {
+			n++
+		}
+	}
+	return n
+}
+
+func main() {
+	flag.Parse()
+	args := flag.Args()
+	if len(args) < 2 {
+		fmt.Println("Usage: export-archetype-graph [--min-shared N] [--archetype-aliases aliases.json] <data-dir> <output.csv>")
+		os.Exit(1)
+	}
+
+	dataDir := args[0]
+	outputFile := args[1]
+
+	var aliases games.ArchetypeAliases
+	if *aliasesFile != "" {
+		var err error
+		aliases, err = games.LoadArchetypeAliases(*aliasesFile)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Println("🧭 Building ARCHETYPE co-occurrence graph...")
+	fmt.Println()
+
+	files, _ := cio.FindCollectionFiles(dataDir, cio.FindOpts{SkipErrors: true})
+	fmt.Printf("Found %d collection files\n", len(files))
+
+	idx := make(archetypeIndex)
+	decks := 0
+	skipped := 0
+	for _, file := range files {
+		col, err := game.LoadCollectionFile(file)
+		if err != nil {
+			fmt.Printf("⚠️  Failed to load %s: %v\n", filepath.Base(file), err)
+			continue
+		}
+		if idx.addDeck(col, aliases) {
+			decks++
+		} else {
+			skipped++
+		}
+	}
+
+	edges := idx.edges(*minShared)
+
+	archetypes := 0
+	for _, byArchetype := range idx {
+		archetypes += len(byArchetype)
+	}
+	fmt.Printf("\n📊 Summary:\n")
+	fmt.Printf("   Decks with archetype: %d\n", decks)
+	fmt.Printf("   Skipped (no archetype, sets, cubes): %d\n", skipped)
+	fmt.Printf("   Formats: %d\n", len(idx))
+	fmt.Printf("   Archetypes: %d\n", archetypes)
+	fmt.Printf("   Edges: %d\n", len(edges))
+
+	f, err := os.Create(outputFile)
+	if err != nil {
+		fmt.Printf("Error creating output: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	w.Write([]string{"FORMAT", "ARCHETYPE_1", "ARCHETYPE_2", "SHARED_CARDS", "JACCARD"})
+	for _, e := range edges {
+		w.Write([]string{
+			e.Format,
+			e.Archetype1,
+			e.Archetype2,
+			fmt.Sprintf("%d", e.Shared),
+			fmt.Sprintf("%.4f", e.Jaccard),
+		})
+	}
+
+	fmt.Printf("\n✅ Archetype graph exported to %s\n", outputFile)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"testing"
+
+	"collections/games"
+	"collections/games/magic/game"
+)
+
+func testDeck(format, archetype string, cards ...string) *game.Collection {
+	descs := make([]game.CardDesc, len(cards))
+	for i, name := range cards {
+		descs[i] = game.CardDesc{Name: name, Count: 4}
+	}
+	return &game.Collection{
+		Type: game.CollectionTypeWrapper{
+			Type:  "Deck",
+			Inner: &game.CollectionTypeDeck{Format: format, Archetype: archetype},
+		},
+		Partitions: []game.Partition{{Name: "Main", Cards: descs}},
+	}
+}
+
+func TestArchetypeEdgeWeightIsOverlap(t *testing.T) {
+	idx := make(archetypeIndex)
+	decks := []*game.Collection{
+		testDeck("Modern", "Burn", "Lightning Bolt", "Lava Spike", "Goblin Guide", "Mountain"),
+		// A second Burn list adds a card to the archetype's pool
+		testDeck("Modern", "Burn", "Lightning Bolt", "Skewer the Critics"),
+		testDeck("Modern", "Prowess", "Lightning Bolt", "Monastery Swiftspear", "Mountain", "Skewer the Critics"),
+		testDeck("Modern", "Tron", "Karn Liberated", "Expedition Map"),
+		// Same archetype name in another format is a separate node
+		testDeck("Legacy", "Burn", "Lightning Bolt", "Mountain"),
+	}
+	for _, d := range decks {
+		if !idx.addDeck(d, nil) {
+			t.Fatalf("addDeck() = false for %v", d.Type.Inner)
+		}
+	}
+
+	edges := idx.edges(1)
+	if len(edges) != 1 {
+		t.Fatalf("edges = %+v, want only Burn-Prowess", edges)
+	}
+	e := edges[0]
+	if e.Format != "Modern" || e.Archetype1 != "Burn" || e.Archetype2 != "Prowess" {
+		t.Errorf("edge = %+v, want Modern Burn-Prowess", e)
+	}
+	// Lightning Bolt, Mountain, Skewer the Critics
+	if e.Shared != 3 {
+		t.Errorf("Shared = %d, want 3", e.Shared)
+	}
+	// 5 Burn cards + 4 Prowess cards - 3 shared = 6 distinct
+	if want := 3.0 / 6.0; e.Jaccard != want {
+		t.Errorf("Jaccard = %v, want %v", e.Jaccard, want)
+	}
+
+	if edges := idx.edges(4); len(edges) != 0 {
+		t.Errorf("edges(4) = %+v, want none", edges)
+	}
+}
+
+func TestAddDeckSkipsWithoutArchetype(t *testing.T) {
+	idx := make(archetypeIndex)
+	if idx.addDeck(testDeck("Modern", "  ", "Island"), nil) {
+		t.Error("addDeck() = true for blank archetype")
+	}
+	set := &game.Collection{Type: game.CollectionTypeWrapper{Type: "Set", Inner: &game.CollectionTypeSet{}}}
+	if idx.addDeck(set, nil) {
+		t.Error("addDeck() = true for a set")
+	}
+}
+
+func TestAddDeckNormalizesArchetype(t *testing.T) {
+	aliases := games.NewArchetypeAliases(map[string]map[string]string{
+		"modern": {"RDW": "Mono-Red Aggro"},
+	})
+	idx := make(archetypeIndex)
+	for _, d := range []*game.Collection{
+		testDeck("Modern", "RDW", "Lightning Bolt"),
+		testDeck("Modern", "Mono Red Aggro", "Goblin Guide"),
+		testDeck("Modern", "Mono-Red Aggro", "Monastery Swiftspear"),
+	} {
+		idx.addDeck(d, aliases)
+	}
+	if len(idx["Modern"]) != 1 {
+		t.Fatalf("archetypes = %v, want one", idx["Modern"])
+	}
+	if cards := idx["Modern"]["Mono-Red Aggro"]; len(cards) != 3 {
+		t.Errorf("Mono-Red Aggro cards = %v, want 3", cards)
+	}
+}
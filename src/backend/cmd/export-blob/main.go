@@ -1,43 +1,78 @@
 package main
 
 // Export collections from blob storage (S3 or local) to JSONL
-// Uses IterItemsBlobPrefix pattern - works with any blob storage
+// Works with any blob storage (file, s3, gs)
 //
 // DATA LINEAGE: Order 1 (depends on Order 0: Primary Source Data)
 // - Input: s3://games-collections/games/{game}/{dataset}/ (Order 0)
 // - Output: data/processed/decks_{game}_{dataset}.jsonl (Order 1)
 // - Converts Collection objects to flattened JSONL format
+// - With --provenance, each record names the collection it came from
+//
+// CHECKPOINTING: keys are exported in listing order and the last exported
+// key is periodically saved under exports/{game}/{dataset}/, keyed by the
+// output's absolute path. A rerun with the same output resumes after that
+// key unless --no-resume, first retrying keys that failed to read. An
+// output that was deleted or is shorter than the checkpoint is exported
+// again from the beginning.
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"collections/blob"
 	"collections/games"
 	_ "collections/games/digimon/game"   // Register collection types
-	_ "collections/games/magic/game"      // Register collection types
-	_ "collections/games/onepiece/game"   // Register collection types
-	_ "collections/games/pokemon/game"    // Register collection types
-	_ "collections/games/riftbound/game"  // Register collection types
-	_ "collections/games/yugioh/game"     // Register collection types
+	_ "collections/games/magic/game"     // Register collection types
+	_ "collections/games/onepiece/game"  // Register collection types
+	_ "collections/games/pokemon/game"   // Register collection types
+	_ "collections/games/riftbound/game" // Register collection types
+	_ "collections/games/yugioh/game"    // Register collection types
 	"collections/logger"
 )
 
+var (
+	noResume        = flag.Bool("no-resume", false, "Ignore any saved checkpoint and export from the beginning")
+	checkpointEvery = flag.Int("checkpoint-every", 1000, "Save a checkpoint after this many exported decks")
+	parallel        = flag.Int("parallel", 64, "Number of collections to read concurrently")
+	perSource       = flag.Int("limit-per-source", 0, "Export at most this many decks per source (0 = no limit)")
+	provenance      = flag.Bool("provenance", false, "Add a provenance object (source, dataset, key, scraped_at, scraper_version) to each record")
+	since           = flag.String("since", "", "Only export collections released on or after this time (RFC3339 or YYYY-MM-DD); collections without a release date are skipped")
+	until           = flag.String("until", "", "Only export collections released on or before this time (RFC3339 or YYYY-MM-DD, inclusive of the day); collections without a release date are skipped")
+)
+
 func main() {
-	if len(os.Args) < 4 {
-		fmt.Println("Usage: export-blob <bucket-url> <game> <dataset> <output.jsonl>")
+	flag.Parse()
+	args := flag.Args()
+	if len(args) < 4 {
+		fmt.Println("Usage: export-blob [flags] <bucket-url> <game> <dataset> <output.jsonl>")
 		fmt.Println("Example: export-blob s3://games-collections pokemon limitless-web output.jsonl")
 		fmt.Println("Example: export-blob file://./data-full magic mtgtop8 output.jsonl")
+		fmt.Println("Example: export-blob gs://games-collections yugioh ygoprodeck output.jsonl")
+		fmt.Println()
+		flag.PrintDefaults()
 		os.Exit(1)
 	}
 
-	bucketURL := os.Args[1]
-	game := os.Args[2]
-	dataset := os.Args[3]
-	outputFile := os.Args[4]
+	bucketURL := args[0]
+	game := args[1]
+	dataset := args[2]
+	outputFile := args[3]
+
+	dates, err := games.ParseDateRange(*since, *until)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	ctx := context.Background()
 	log := logger.NewLogger(ctx)
@@ -55,114 +90,324 @@ func main() {
 		bucket.Close(ctx) // Close doesn't return error
 	}()
 
-	// Use games/ prefix
-	gamesBucket := bucket.WithPrefix("games/")
+	result, err := runExport(ctx, log, bucket, exportOptions{
+		Game:            game,
+		Dataset:         dataset,
+		OutputFile:      outputFile,
+		Resume:          !*noResume,
+		CheckpointEvery: *checkpointEvery,
+		Parallel:        *parallel,
+		PerSource:       *perSource,
+		Provenance:      *provenance,
+		Dates:           dates,
+	})
+	if err != nil {
+		log.Errorf(ctx, "Export failed after %d decks: %v", result.Exported, err)
+		log.Errorf(ctx, "Rerun the same command to resume from the last checkpoint")
+		os.Exit(1)
+	}
 
-	// Prefix for this game/dataset
-	prefix := filepath.Join(game, dataset) + "/"
+	log.Infof(ctx, "✅ Exported %d decks to %s", result.Exported, outputFile)
+	if result.Capped > 0 {
+		log.Infof(ctx, "Left out %d decks over the limit of %d per source", result.Capped, *perSource)
+	}
+	if result.OutOfRange > 0 {
+		log.Infof(ctx, "Left out %d collections released outside %s", result.OutOfRange, dates)
+	}
+	if result.Errors > 0 {
+		log.Warnf(ctx, "⚠️  Encountered %d errors", result.Errors)
+	}
+}
 
-	log.Infof(ctx, "Iterating collections from prefix: %s", prefix)
+type exportOptions struct {
+	Game            string
+	Dataset         string
+	OutputFile      string
+	Resume          bool
+	CheckpointEvery int
+	Parallel        int
+	PerSource       int  // Max decks exported per source; 0 is no limit
+	Provenance      bool // Add a games.Provenance to each record
+	// Dates keeps collections whose ReleaseDate is in range; with either
+	// bound set, collections without one are left out
+	Dates games.DateRange
+
+	// afterWrite is called after each record is written; returning an error
+	// aborts the export without saving a checkpoint. Used by tests to
+	// simulate an interrupted run.
+	afterWrite func(exported int) error
+}
 
-	// Open output file
-	out, err := os.Create(outputFile)
+type exportResult struct {
+	Exported int // Records written, including those from resumed runs
+	Errors   int // Collections that failed to read or decode
+	Capped   int // Decks left out by PerSource in this run
+	// OutOfRange counts collections left out by Dates in this run
+	OutOfRange int
+}
+
+// exportCheckpoint is the persisted progress of an export. Offset is the
+// output size once every record up to LastKey was flushed, so a resumed run
+// can drop anything written after the checkpoint. PerSource holds the
+// per-source counts so a resumed run keeps to --limit-per-source.
+type exportCheckpoint struct {
+	LastKey   string         `json:"last_key"`
+	Exported  int            `json:"exported"`
+	Offset    int64          `json:"offset"`
+	Complete  bool           `json:"complete"`
+	PerSource map[string]int `json:"per_source,omitempty"`
+	// Failed are keys up to LastKey that failed to read or decode; a
+	// resumed run retries them first
+	Failed    []string  `json:"failed,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// checkpoints stores export checkpoints under exports/{game}/{dataset}/
+func checkpoints(b *blob.Bucket, opts exportOptions) *games.BlobKV {
+	return games.NewBlobKV(b.WithPrefix("exports/"), path.Join(opts.Game, opts.Dataset))
+}
+
+// checkpointKey is the output's checkpoint key in checkpoints: its
+// absolute path, so outputs with the same name in different directories
+// keep separate checkpoints
+func checkpointKey(opts exportOptions) (string, error) {
+	abs, err := filepath.Abs(opts.OutputFile)
 	if err != nil {
-		log.Errorf(ctx, "Failed to create output file: %v", err)
-		os.Exit(1)
+		return "", fmt.Errorf("failed to resolve output path: %w", err)
+	}
+	return abs + ".checkpoint", nil
+}
+
+// countingWriter tracks the number of bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// runExport writes every collection under games/{game}/{dataset}/ to
+// opts.OutputFile as JSONL. Collections are read in parallel batches but
+// written in key order, so a checkpoint's LastKey covers every earlier key
+// but those in Failed. A resumed run writes those retried keys first.
+func runExport(
+	ctx context.Context,
+	log *logger.Logger,
+	bucket *blob.Bucket,
+	opts exportOptions,
+) (exportResult, error) {
+	var result exportResult
+	if opts.Parallel < 1 {
+		opts.Parallel = 1
+	}
+	if opts.CheckpointEvery < 1 {
+		opts.CheckpointEvery = 1000
+	}
+
+	gamesBucket := bucket.WithPrefix("games/")
+	cpStore := checkpoints(bucket, opts)
+	cpKey, err := checkpointKey(opts)
+	if err != nil {
+		return result, err
+	}
+
+	var cp exportCheckpoint
+	if opts.Resume {
+		var saved exportCheckpoint
+		found, err := cpStore.Get(ctx, cpKey, &saved)
+		if err != nil {
+			return result, fmt.Errorf("failed to load checkpoint: %w", err)
+		}
+		if found && !saved.Complete {
+			cp = saved
+		}
+	}
+	// The output must still hold everything the checkpoint covers; one
+	// deleted or cut short (a crash before the OS flushed it) starts over
+	if cp.LastKey != "" {
+		info, err := os.Stat(opts.OutputFile)
+		if err != nil || info.Size() < cp.Offset {
+			log.Warnf(ctx, "Output %s no longer holds the %d checkpointed decks, exporting from the beginning", opts.OutputFile, cp.Exported)
+			cp = exportCheckpoint{}
+		} else {
+			log.Infof(ctx, "Resuming after %s (%d decks already exported)", cp.LastKey, cp.Exported)
+		}
+	}
+
+	// Open output, discarding anything written after the checkpoint
+	flags := os.O_CREATE | os.O_WRONLY
+	if cp.LastKey == "" {
+		flags |= os.O_TRUNC
+	}
+	out, err := os.OpenFile(opts.OutputFile, flags, 0644)
+	if err != nil {
+		return result, fmt.Errorf("failed to open output file: %w", err)
 	}
 	defer out.Close()
+	if cp.LastKey != "" {
+		if err := out.Truncate(cp.Offset); err != nil {
+			return result, fmt.Errorf("failed to truncate output to checkpoint: %w", err)
+		}
+		if _, err := out.Seek(cp.Offset, io.SeekStart); err != nil {
+			return result, fmt.Errorf("failed to seek output: %w", err)
+		}
+	}
 
-	encoder := json.NewEncoder(out)
-	exported := 0
-	errors := 0
-
-	// Iterate through collections using IterItemsBlobPrefix
-	err = games.IterItemsBlobPrefix(
-		ctx,
-		gamesBucket,
-		prefix,
-		func(key string, data []byte) (games.Item, error) {
-			var collection games.Collection
-			if err := json.Unmarshal(data, &collection); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal collection: %w", err)
-			}
-			return &games.CollectionItem{
-				Collection: &collection,
-			}, nil
-		},
-		func(item games.Item) error {
-			colItem, ok := item.(*games.CollectionItem)
-			if !ok {
-				return fmt.Errorf("unexpected item type")
-			}
+	retry := cp.Failed
+	cp.Failed = nil
 
-			collection := colItem.Collection
+	counter := &countingWriter{w: out, n: cp.Offset}
+	bw := bufio.NewWriter(counter)
+	encoder := json.NewEncoder(bw)
+	result.Exported = cp.Exported
+	sourceCap := &games.SourceCap{Limit: opts.PerSource, Counts: cp.PerSource}
+	sinceCheckpoint := 0
 
-			// Convert to export format (similar to export-hetero)
-			deckMap := map[string]interface{}{
-				"deck_id":    collection.ID,
-				"url":        collection.URL,
-				"source":     collection.Source,
-				"scraped_at": collection.ReleaseDate.Format("2006-01-02T15:04:05Z07:00"),
-				"timestamp":  collection.ReleaseDate.Format("2006-01-02T15:04:05Z07:00"),
-				"created_at": collection.ReleaseDate.Format("2006-01-02T15:04:05Z07:00"),
-			}
+	checkpoint := func(complete bool) error {
+		if err := bw.Flush(); err != nil {
+			return fmt.Errorf("failed to flush output: %w", err)
+		}
+		cp.Exported = result.Exported
+		cp.Offset = counter.n
+		cp.Complete = complete
+		cp.PerSource = sourceCap.Counts
+		cp.UpdatedAt = time.Now().UTC()
+		sinceCheckpoint = 0
+		if err := cpStore.Set(ctx, cpKey, cp); err != nil {
+			return fmt.Errorf("failed to save checkpoint: %w", err)
+		}
+		return nil
+	}
 
-			// Extract type info - use reflection or type switch
-			// Try common methods first
-			if inner := collection.Type.Inner; inner != nil {
-				// Use type assertion with interface{} and extract via reflection-like approach
-				// For now, extract what we can from the type
-				deckMap["archetype"] = ""
-				deckMap["format"] = ""
-				deckMap["player"] = ""
-				deckMap["event"] = ""
-				deckMap["placement"] = 0
-				deckMap["event_date"] = ""
-
-				// Try to get values using type assertions for known types
-				// This is a simplified approach - in production, you'd use proper type switches
-			}
+	// Prefix for this game/dataset
+	prefix := filepath.Join(opts.Game, opts.Dataset) + "/"
+	log.Infof(ctx, "Iterating collections from prefix: %s", prefix)
+
+	type readResult struct {
+		record     map[string]interface{}
+		outOfRange bool
+		err        error
+	}
+
+	it := gamesBucket.List(ctx, &blob.OptListPrefix{Prefix: prefix})
+	batch := make([]string, 0, opts.Parallel)
+	// advance moves LastKey up to key; retried keys are behind it already
+	advance := func(key string) {
+		if key > cp.LastKey {
+			cp.LastKey = key
+		}
+	}
 
-			// Extract cards from partitions
-			var cards []map[string]interface{}
-			for _, partition := range collection.Partitions {
-				for _, card := range partition.Cards {
-					cards = append(cards, map[string]interface{}{
-						"name":      card.Name,
-						"count":     card.Count,
-						"partition": partition.Name,
-					})
+	flushBatch := func() error {
+		results := make([]readResult, len(batch))
+		wg := new(sync.WaitGroup)
+		for i, key := range batch {
+			wg.Add(1)
+			go func(i int, key string) {
+				defer wg.Done()
+				data, err := gamesBucket.Read(ctx, key)
+				if err != nil {
+					results[i].err = fmt.Errorf("failed to read %s: %w", key, err)
+					return
 				}
-			}
+				var collection games.Collection
+				if err := json.Unmarshal(data, &collection); err != nil {
+					results[i].err = fmt.Errorf("failed to unmarshal collection %s: %w", key, err)
+					return
+				}
+				if !opts.Dates.Contains(collection.ReleaseDate) {
+					results[i].outOfRange = true
+					return
+				}
+				results[i].record = games.ExportRecord(&collection)
+				if opts.Provenance && results[i].record != nil {
+					results[i].record["provenance"] = games.NewProvenance(&collection, opts.Dataset, key)
+				}
+			}(i, key)
+		}
+		wg.Wait()
 
-			if len(cards) == 0 {
-				return nil // Skip decks with no cards
+		for i, r := range results {
+			if r.err != nil {
+				log.Warnf(ctx, "%v", r.err)
+				result.Errors++
+				cp.Failed = append(cp.Failed, batch[i])
+			} else if r.outOfRange {
+				result.OutOfRange++
+			} else if r.record != nil {
+				source, _ := r.record["source"].(string)
+				if !sourceCap.Allow(source) {
+					result.Capped++
+					advance(batch[i])
+					continue
+				}
+				if err := encoder.Encode(r.record); err != nil {
+					return fmt.Errorf("failed to encode deck: %w", err)
+				}
+				result.Exported++
+				sinceCheckpoint++
+				if result.Exported%1000 == 0 {
+					log.Infof(ctx, "Exported %d decks...", result.Exported)
+				}
+				if opts.afterWrite != nil {
+					if err := opts.afterWrite(result.Exported); err != nil {
+						return err
+					}
+				}
 			}
+			advance(batch[i])
+		}
+		batch = batch[:0]
 
-			deckMap["cards"] = cards
+		if sinceCheckpoint >= opts.CheckpointEvery {
+			return checkpoint(false)
+		}
+		return nil
+	}
 
-			if err := encoder.Encode(deckMap); err != nil {
-				return fmt.Errorf("failed to encode deck: %w", err)
+	if len(retry) > 0 {
+		log.Infof(ctx, "Retrying %d collections that failed in the previous run", len(retry))
+	}
+	for _, key := range retry {
+		batch = append(batch, key)
+		if len(batch) == opts.Parallel {
+			if err := flushBatch(); err != nil {
+				return result, err
 			}
-
-			exported++
-			if exported%1000 == 0 {
-				log.Infof(ctx, "Exported %d decks...", exported)
+		}
+	}
+	for it.Next(ctx) {
+		key := it.Key()
+		if cp.LastKey != "" && key <= cp.LastKey {
+			continue // Exported by a previous run
+		}
+		batch = append(batch, key)
+		if len(batch) == opts.Parallel {
+			if err := flushBatch(); err != nil {
+				return result, err
 			}
-
-			return nil
-		},
-	)
-
-	if err != nil {
-		log.Errorf(ctx, "Iteration failed: %v", err)
-		errors++
+		}
 	}
-
-	log.Infof(ctx, "✅ Exported %d decks to %s", exported, outputFile)
-	if errors > 0 {
-		log.Warnf(ctx, "⚠️  Encountered %d errors", errors)
+	if err := it.Err(); err != nil {
+		// Save what we have so a rerun picks up from here
+		if cpErr := checkpoint(false); cpErr != nil {
+			log.Errorf(ctx, "failed to save checkpoint: %v", cpErr)
+		}
+		return result, fmt.Errorf("failed to list collections: %w", err)
+	}
+	if len(batch) > 0 {
+		if err := flushBatch(); err != nil {
+			return result, err
+		}
+	}
+	if err := checkpoint(true); err != nil {
+		return result, err
 	}
+	return result, nil
 }
 
 func getString(m map[string]interface{}, key string) string {
@@ -0,0 +1,566 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"collections/blob"
+	"collections/games"
+	pokemon "collections/games/pokemon/game"
+	ygo "collections/games/yugioh/game"
+	"collections/logger"
+)
+
+func writeTestDecks(t *testing.T, bucket *blob.Bucket, n int) {
+	t.Helper()
+	ctx := context.Background()
+	for i := 0; i < n; i++ {
+		c := games.Collection{
+			ID:          fmt.Sprintf("deck-%03d", i),
+			URL:         fmt.Sprintf("https://example.com/deck/%d", i),
+			Type:        games.CollectionTypeWrapper{Type: "YGODeck", Inner: &ygo.CollectionTypeDeck{Name: "Test"}},
+			ReleaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Partitions: []games.Partition{{
+				Name:  "Main Deck",
+				Cards: []games.CardDesc{{Name: "Ash Blossom & Joyous Spring", Count: 3}},
+			}},
+			Source: "ygoprodeck",
+		}
+		data, err := json.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key := fmt.Sprintf("games/yugioh/ygoprodeck/deck-%03d.json", i)
+		if err := bucket.Write(ctx, key, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readDeckIDs(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var ids []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var rec struct {
+			DeckID string `json:"deck_id"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatalf("invalid line %q: %v", sc.Text(), err)
+		}
+		ids = append(ids, rec.DeckID)
+	}
+	return ids
+}
+
+func TestRunExportResume(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+	writeTestDecks(t, bucket, 25)
+
+	dir := t.TempDir()
+	opts := func(out string) exportOptions {
+		return exportOptions{
+			Game:            "yugioh",
+			Dataset:         "ygoprodeck",
+			OutputFile:      filepath.Join(dir, out),
+			Resume:          true,
+			CheckpointEvery: 4,
+			Parallel:        3,
+		}
+	}
+
+	// Reference: one uninterrupted run
+	full := opts("full.jsonl")
+	if _, err := runExport(ctx, log, bucket, full); err != nil {
+		t.Fatalf("runExport() error = %v", err)
+	}
+	want := readDeckIDs(t, full.OutputFile)
+	if len(want) != 25 {
+		t.Fatalf("full export has %d decks, want 25", len(want))
+	}
+
+	// Interrupted run: crash part way through a checkpoint interval, leaving
+	// records in the output that the checkpoint does not cover
+	errCrash := errors.New("crash")
+	interrupted := opts("resumed.jsonl")
+	interrupted.afterWrite = func(n int) error {
+		if n == 14 {
+			return errCrash
+		}
+		return nil
+	}
+	if _, err := runExport(ctx, log, bucket, interrupted); !errors.Is(err, errCrash) {
+		t.Fatalf("runExport() error = %v, want crash", err)
+	}
+
+	resumed := opts("resumed.jsonl")
+	res, err := runExport(ctx, log, bucket, resumed)
+	if err != nil {
+		t.Fatalf("resumed runExport() error = %v", err)
+	}
+	if res.Exported != 25 {
+		t.Errorf("Exported = %d, want 25", res.Exported)
+	}
+
+	got := readDeckIDs(t, resumed.OutputFile)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("resumed export = %v, want %v", got, want)
+	}
+
+	// A completed checkpoint does not skip anything on the next run
+	again, err := runExport(ctx, log, bucket, resumed)
+	if err != nil {
+		t.Fatalf("rerun runExport() error = %v", err)
+	}
+	if again.Exported != 25 {
+		t.Errorf("rerun Exported = %d, want 25", again.Exported)
+	}
+	if got := readDeckIDs(t, resumed.OutputFile); len(got) != 25 {
+		t.Errorf("rerun export has %d decks, want 25", len(got))
+	}
+}
+
+func TestRunExportResumeRecovers(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+	writeTestDecks(t, bucket, 12)
+	// runExport's WithPrefix calls leave bucket itself closed, so decks
+	// are changed between runs through their own handle
+	decks := bucket.WithPrefix("games/yugioh/ygoprodeck/")
+
+	errCrash := errors.New("crash")
+	opts := func(out string) exportOptions {
+		return exportOptions{
+			Game:            "yugioh",
+			Dataset:         "ygoprodeck",
+			OutputFile:      out,
+			Resume:          true,
+			CheckpointEvery: 2,
+			Parallel:        1,
+		}
+	}
+	// interrupt crashes an export after its sixth record, past a checkpoint
+	interrupt := func(t *testing.T, out string) {
+		t.Helper()
+		o := opts(out)
+		o.afterWrite = func(n int) error {
+			if n == 6 {
+				return errCrash
+			}
+			return nil
+		}
+		if _, err := runExport(ctx, log, bucket, o); !errors.Is(err, errCrash) {
+			t.Fatalf("runExport() error = %v, want crash", err)
+		}
+	}
+	// resume finishes the export of out and checks it holds every deck once
+	resume := func(t *testing.T, out string) {
+		t.Helper()
+		res, err := runExport(ctx, log, bucket, opts(out))
+		if err != nil {
+			t.Fatalf("resumed runExport() error = %v", err)
+		}
+		ids := readDeckIDs(t, out)
+		sort.Strings(ids)
+		var want []string
+		for i := 0; i < 12; i++ {
+			want = append(want, fmt.Sprintf("deck-%03d", i))
+		}
+		if res.Exported != 12 || fmt.Sprint(ids) != fmt.Sprint(want) {
+			t.Errorf("%s: Exported = %d, decks = %v, want all 12 once", out, res.Exported, ids)
+		}
+	}
+
+	t.Run("output deleted", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "out.jsonl")
+		interrupt(t, out)
+		if err := os.Remove(out); err != nil {
+			t.Fatal(err)
+		}
+		resume(t, out)
+	})
+
+	t.Run("output shorter than checkpoint", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "out.jsonl")
+		interrupt(t, out)
+		if err := os.Truncate(out, 10); err != nil {
+			t.Fatal(err)
+		}
+		resume(t, out)
+	})
+
+	t.Run("same name in another directory", func(t *testing.T) {
+		outA := filepath.Join(t.TempDir(), "out.jsonl")
+		outB := filepath.Join(t.TempDir(), "out.jsonl")
+		interrupt(t, outA)
+		resume(t, outB)
+		// A still resumes from its own checkpoint after the 4 it covers
+		o := opts(outA)
+		written := 0
+		o.afterWrite = func(int) error { written++; return nil }
+		if _, err := runExport(ctx, log, bucket, o); err != nil {
+			t.Fatal(err)
+		}
+		if written != 8 {
+			t.Errorf("resumed export wrote %d decks, want 8", written)
+		}
+		if ids := readDeckIDs(t, outA); len(ids) != 12 {
+			t.Errorf("resumed export has %d decks, want 12", len(ids))
+		}
+	})
+
+	t.Run("failed keys retried", func(t *testing.T) {
+		key := "deck-002.json"
+		good, err := decks.Read(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := decks.Write(ctx, key, []byte("not json")); err != nil {
+			t.Fatal(err)
+		}
+		out := filepath.Join(t.TempDir(), "out.jsonl")
+		interrupt(t, out)
+		if err := decks.Write(ctx, key, good); err != nil {
+			t.Fatal(err)
+		}
+		resume(t, out)
+	})
+}
+
+func TestRunExportLimitPerSource(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+	writeTestDecks(t, bucket, 10)
+	// Every third deck comes from another source
+	for i := 0; i < 10; i += 3 {
+		key := fmt.Sprintf("games/yugioh/ygoprodeck/deck-%03d.json", i)
+		data, err := bucket.Read(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var c games.Collection
+		if err := json.Unmarshal(data, &c); err != nil {
+			t.Fatal(err)
+		}
+		c.Source = "yugiohmeta"
+		if data, err = json.Marshal(c); err != nil {
+			t.Fatal(err)
+		}
+		if err := bucket.Write(ctx, key, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := exportOptions{
+		Game:            "yugioh",
+		Dataset:         "ygoprodeck",
+		OutputFile:      filepath.Join(t.TempDir(), "capped.jsonl"),
+		Resume:          true,
+		CheckpointEvery: 1,
+		Parallel:        2,
+		PerSource:       3,
+	}
+	// Crash part way, so the resumed run must carry the counts over
+	errCrash := errors.New("crash")
+	opts.afterWrite = func(n int) error {
+		if n == 3 {
+			return errCrash
+		}
+		return nil
+	}
+	if _, err := runExport(ctx, log, bucket, opts); !errors.Is(err, errCrash) {
+		t.Fatalf("runExport() error = %v, want crash", err)
+	}
+	opts.afterWrite = nil
+	res, err := runExport(ctx, log, bucket, opts)
+	if err != nil {
+		t.Fatalf("resumed runExport() error = %v", err)
+	}
+
+	// deck-000, -003 and -006 from yugiohmeta; -001, -002 and -004 from
+	// ygoprodeck
+	want := []string{"deck-000", "deck-001", "deck-002", "deck-003", "deck-004", "deck-006"}
+	if got := readDeckIDs(t, opts.OutputFile); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("capped export = %v, want %v", got, want)
+	}
+	if res.Exported != 6 {
+		t.Errorf("Exported = %d, want 6", res.Exported)
+	}
+}
+
+func TestRunExportNoResume(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+	writeTestDecks(t, bucket, 10)
+
+	o := exportOptions{
+		Game:            "yugioh",
+		Dataset:         "ygoprodeck",
+		OutputFile:      filepath.Join(t.TempDir(), "out.jsonl"),
+		Resume:          true,
+		CheckpointEvery: 2,
+		Parallel:        2,
+		afterWrite: func(n int) error {
+			if n == 5 {
+				return errors.New("crash")
+			}
+			return nil
+		},
+	}
+	if _, err := runExport(ctx, log, bucket, o); err == nil {
+		t.Fatal("runExport() error = nil, want crash")
+	}
+
+	o.afterWrite = nil
+	o.Resume = false
+	if _, err := runExport(ctx, log, bucket, o); err != nil {
+		t.Fatalf("runExport() error = %v", err)
+	}
+	if got := readDeckIDs(t, o.OutputFile); len(got) != 10 {
+		t.Errorf("export has %d decks, want 10", len(got))
+	}
+}
+
+func TestRunExportProvenance(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+	writeTestDecks(t, bucket, 2)
+	// The first deck knows when it was scraped
+	key := "games/yugioh/ygoprodeck/deck-000.json"
+	data, err := bucket.Read(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c games.Collection
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatal(err)
+	}
+	c.ScrapedAt = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	if data, err = json.Marshal(c); err != nil {
+		t.Fatal(err)
+	}
+	if err := bucket.Write(ctx, key, data); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := exportOptions{
+		Game:            "yugioh",
+		Dataset:         "ygoprodeck",
+		OutputFile:      filepath.Join(t.TempDir(), "out.jsonl"),
+		CheckpointEvery: 10,
+		Parallel:        2,
+		Provenance:      true,
+	}
+	if _, err := runExport(ctx, log, bucket, opts); err != nil {
+		t.Fatalf("runExport() error = %v", err)
+	}
+	f, err := os.Open(opts.OutputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []games.Provenance
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var rec struct {
+			Provenance *games.Provenance `json:"provenance"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Provenance == nil {
+			t.Fatalf("record %s has no provenance", sc.Text())
+		}
+		got = append(got, *rec.Provenance)
+	}
+	want := []games.Provenance{
+		{Source: "ygoprodeck", Dataset: "ygoprodeck", Key: "yugioh/ygoprodeck/deck-000.json", ScrapedAt: "2024-05-06T07:08:09Z", ScraperVersion: games.ScraperVersion()},
+		{Source: "ygoprodeck", Dataset: "ygoprodeck", Key: "yugioh/ygoprodeck/deck-001.json", ScraperVersion: games.ScraperVersion()},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("provenance = %+v, want %+v", got, want)
+	}
+
+	// Off by default
+	opts.Provenance = false
+	if _, err := runExport(ctx, log, bucket, opts); err != nil {
+		t.Fatalf("runExport() error = %v", err)
+	}
+	out, err := os.ReadFile(opts.OutputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "provenance") {
+		t.Errorf("export without Provenance has provenance: %s", out)
+	}
+}
+
+func TestCollectionRecordOmitsUnknownMetadata(t *testing.T) {
+	c := &games.Collection{
+		ID:   "deck-1",
+		URL:  "https://example.com/deck/1",
+		Type: games.CollectionTypeWrapper{Type: "YGODeck", Inner: &ygo.CollectionTypeDeck{Name: "Test", Format: "TCG"}},
+		Partitions: []games.Partition{{
+			Name:  "Main Deck",
+			Cards: []games.CardDesc{{Name: "Ash Blossom & Joyous Spring", Count: 3}},
+		}},
+	}
+	data, err := json.Marshal(games.ExportRecord(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec map[string]interface{}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"placement", "event", "player", "event_date"} {
+		if v, ok := rec[key]; ok {
+			t.Errorf("record has unknown %s = %v, want omitted", key, v)
+		}
+	}
+	if rec["format"] != "TCG" {
+		t.Errorf("format = %v, want TCG", rec["format"])
+	}
+
+	// Known metadata is kept
+	top8 := 8
+	c.Type.Inner = &ygo.CollectionTypeDeck{Name: "Test", Placement: &top8}
+	if got := games.ExportRecord(c)["placement"]; got != 8 {
+		t.Errorf("placement = %v, want 8", got)
+	}
+}
+
+func TestCollectionRecordDeckMetadata(t *testing.T) {
+	placement := 2
+	c := &games.Collection{
+		ID:  "deck-1",
+		URL: "https://example.com/deck/1",
+		Type: games.CollectionTypeWrapper{Type: "PokemonDeck", Inner: &pokemon.CollectionTypeDeck{
+			Name:      "Charizard ex",
+			Format:    "Standard",
+			Archetype: "Charizard ex",
+			Player:    "Alice",
+			Event:     "Regionals",
+			Placement: &placement,
+			EventDate: "2024-03-01",
+		}},
+		Partitions: []games.Partition{{
+			Name:  "Deck",
+			Cards: []games.CardDesc{{Name: "Charizard ex", Count: 3}},
+		}},
+	}
+	rec := games.ExportRecord(c)
+	want := map[string]any{
+		"archetype":  "Charizard ex",
+		"format":     "Standard",
+		"player":     "Alice",
+		"event":      "Regionals",
+		"placement":  2,
+		"event_date": "2024-03-01",
+	}
+	for key, v := range want {
+		if rec[key] != v {
+			t.Errorf("%s = %v, want %v", key, rec[key], v)
+		}
+	}
+
+	// Non-deck types keep the record shape with empty metadata
+	c.Type = games.CollectionTypeWrapper{Type: "PokemonSet", Inner: &pokemon.CollectionTypeSet{Name: "Obsidian Flames"}}
+	rec = games.ExportRecord(c)
+	if rec["archetype"] != "" || rec["format"] != "" {
+		t.Errorf("set record archetype, format = %q, %q, want empty", rec["archetype"], rec["format"])
+	}
+	if _, ok := rec["player"]; ok {
+		t.Errorf("set record has player = %v, want omitted", rec["player"])
+	}
+}
+
+func TestRunExportDateRange(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+	writeTestDecks(t, bucket, 6)
+	// deck-00N is released on January N+1; deck-005 has no release date
+	for i := 0; i < 6; i++ {
+		key := fmt.Sprintf("games/yugioh/ygoprodeck/deck-%03d.json", i)
+		data, err := bucket.Read(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var c games.Collection
+		if err := json.Unmarshal(data, &c); err != nil {
+			t.Fatal(err)
+		}
+		c.ReleaseDate = time.Date(2024, 1, i+1, 12, 0, 0, 0, time.UTC)
+		if i == 5 {
+			c.ReleaseDate = time.Time{}
+		}
+		if data, err = json.Marshal(c); err != nil {
+			t.Fatal(err)
+		}
+		if err := bucket.Write(ctx, key, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dates, err := games.ParseDateRange("2024-01-02", "2024-01-04")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := exportOptions{
+		Game:       "yugioh",
+		Dataset:    "ygoprodeck",
+		OutputFile: filepath.Join(t.TempDir(), "dated.jsonl"),
+		Parallel:   2,
+		Dates:      dates,
+	}
+	res, err := runExport(ctx, log, bucket, opts)
+	if err != nil {
+		t.Fatalf("runExport() error = %v", err)
+	}
+	want := []string{"deck-001", "deck-002", "deck-003"}
+	if got := readDeckIDs(t, opts.OutputFile); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("dated export = %v, want %v", got, want)
+	}
+	if res.Exported != 3 || res.OutOfRange != 3 {
+		t.Errorf("Exported, OutOfRange = %d, %d, want 3, 3", res.Exported, res.OutOfRange)
+	}
+}
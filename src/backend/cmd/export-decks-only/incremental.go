@@ -0,0 +1,233 @@
+package main
+
+// Incremental exports (--incremental) re-read only decks whose files
+// changed since the last run and reuse a saved pair snapshot for the rest.
+//
+// State lives in a file bucket rooted at the data dir's parent, under
+// --tracker-prefix. The default prefix is
+// .export-decks-only/<data-dir name>/<output name>, so each data dir and
+// output pair keeps its own state and exporting the same data to two
+// outputs does not share a tracker. The prefix holds the ExportTracker
+// (which decks were exported, by file mod time) and a "pairs" snapshot:
+// the pair counts of the last run plus each deck's cards, so a changed or
+// deleted deck's old contribution can be subtracted before its new one is
+// added.
+//
+// A snapshot built with different --normalize-counts, --half-life, --as-of,
+// --since, --until, --english-only, --localized-names or --format settings
+// is discarded and the run starts fresh, which gives the same output as a
+// run without --incremental.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"collections/blob"
+	"collections/games"
+	"collections/games/magic/game"
+	"collections/graphio"
+	"collections/logger"
+)
+
+// snapshotKey is the pair snapshot's BlobKV key under the tracker prefix
+const snapshotKey = "pairs"
+
+// pairSnapshot is the state an incremental run resumes from
+type pairSnapshot struct {
+	Options string                  `json:"options"` // see snapshotOptions
+	Now     time.Time               `json:"now"`     // decay reference the weights were computed at
+	Pairs   []graphio.Edge          `json:"pairs"`
+	Decks   map[string]snapshotDeck `json:"decks"` // data-dir relative path -> deck
+}
+
+// snapshotDeck is what one deck contributed to Pairs. Sets, cubes and
+// decks skipped by date or locale have no partitions.
+type snapshotDeck struct {
+	Partitions []game.Partition `json:"partitions,omitempty"`
+	Decay      float64          `json:"decay,omitempty"`
+	Date       time.Time        `json:"date,omitempty"`
+}
+
+// snapshotOptions identifies the settings that change pair counts; a
+// snapshot is only reused under the same options
+func snapshotOptions(binary bool, halfLifeDays float64, asOf string, released games.DateRange, englishOnly bool, namesFile string, formats games.FormatFilter) string {
+	return fmt.Sprintf("normalize-counts=%t half-life=%g as-of=%s released=%s english-only=%t localized-names=%s formats=%s", binary, halfLifeDays, asOf, released, englishOnly, namesFile, formats)
+}
+
+// defaultTrackerPrefix is the state prefix for exporting dataDir to
+// outputFile when --tracker-prefix is not set
+func defaultTrackerPrefix(dataDir, outputFile string) string {
+	return filepath.ToSlash(filepath.Join(".export-decks-only", filepath.Base(filepath.Clean(dataDir)), filepath.Base(outputFile)))
+}
+
+// incrementalState is the tracker and snapshot store for one prefix
+type incrementalState struct {
+	bucket  *blob.Bucket
+	tracker *games.ExportTracker
+	kv      *games.BlobKV
+	options string
+}
+
+// openIncrementalState opens the state under prefix in a file bucket at
+// root and loads the tracker
+func openIncrementalState(ctx context.Context, log *logger.Logger, root, prefix, options string) (*incrementalState, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := blob.NewBucket(ctx, log, "file://"+absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tracker bucket: %w", err)
+	}
+	s := &incrementalState{
+		bucket:  bucket,
+		tracker: games.NewExportTracker(log, bucket, prefix),
+		kv:      games.NewBlobKV(bucket, prefix),
+		options: options,
+	}
+	if err := s.tracker.Load(ctx); err != nil {
+		bucket.Close(ctx)
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *incrementalState) close(ctx context.Context) {
+	s.bucket.Close(ctx)
+}
+
+// loadSnapshot returns the saved snapshot, or an empty one if there is
+// none or it was built with other options
+func (s *incrementalState) loadSnapshot(ctx context.Context) (*pairSnapshot, error) {
+	var snap pairSnapshot
+	exists, err := s.kv.Get(ctx, snapshotKey, &snap)
+	if err != nil {
+		return nil, err
+	}
+	if !exists || snap.Options != s.options {
+		return &pairSnapshot{Options: s.options, Decks: make(map[string]snapshotDeck)}, nil
+	}
+	if snap.Decks == nil {
+		snap.Decks = make(map[string]snapshotDeck)
+	}
+	return &snap, nil
+}
+
+// buildIncremental is buildDeckPairs for an --incremental run: decks the
+// tracker has seen unmodified keep their snapshot contribution, the rest
+// are re-read. It saves the new snapshot and tracker and returns the pair
+// counts, stats for the re-read decks, and how many decks were unchanged.
+func buildIncremental(ctx context.Context, out io.Writer, dataDir string, files []string, workers int, binary bool, dates games.DatePolicy, locales localePolicy, formats games.FormatFilter, state *incrementalState) (map[graphio.Pair]*graphio.PairCounts, deckStats, int, error) {
+	snap, err := state.loadSnapshot(ctx)
+	if err != nil {
+		return nil, deckStats{}, 0, err
+	}
+
+	pairCounts := make(map[graphio.Pair]*graphio.PairCounts, len(snap.Pairs))
+	for _, e := range snap.Pairs {
+		pairCounts[graphio.Pair{Card1: e.Card1, Card2: e.Card2}] = &graphio.PairCounts{
+			Set:      int(e.CountSet),
+			Multiset: int(e.CountMultiset),
+			Weight:   e.Weight,
+		}
+	}
+
+	// Without --as-of, decay is relative to now, so weights computed last
+	// run are aged by the time since. Decks dated after the last run were
+	// clamped to weight 1 then and are recounted instead.
+	rescale := dates.HalfLife > 0 && dates.AsOf.IsZero() && !snap.Now.IsZero()
+	if rescale {
+		factor := games.DecayWeight(snap.Now, dates.Now, dates.HalfLife)
+		for _, c := range pairCounts {
+			c.Weight *= factor
+		}
+		for rel, d := range snap.Decks {
+			d.Decay *= factor
+			snap.Decks[rel] = d
+		}
+	}
+
+	rels := make(map[string]string, len(files)) // file -> relative path
+	var changed []string
+	seen := make(map[string]bool, len(files))
+	for _, file := range files {
+		rel, err := filepath.Rel(dataDir, file)
+		if err != nil {
+			return nil, deckStats{}, 0, err
+		}
+		rel = filepath.ToSlash(rel)
+		rels[file] = rel
+		seen[rel] = true
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, deckStats{}, 0, err
+		}
+		d, known := snap.Decks[rel]
+		if known && !state.tracker.ShouldExport(ctx, rel, info.ModTime(), time.Time{}, 0) &&
+			!(rescale && d.Date.After(snap.Now)) {
+			continue
+		}
+		if known {
+			subtractDeck(pairCounts, d, binary)
+			delete(snap.Decks, rel)
+		}
+		changed = append(changed, file)
+	}
+	for rel, d := range snap.Decks {
+		if !seen[rel] {
+			subtractDeck(pairCounts, d, binary)
+			delete(snap.Decks, rel)
+		}
+	}
+	unchanged := len(files) - len(changed)
+
+	onDeck := func(file string, dp deckPairs) {
+		if dp.err != nil {
+			return // Retry next run
+		}
+		rel := rels[file]
+		snap.Decks[rel] = snapshotDeck{Partitions: dp.partitions, Decay: dp.decay, Date: dp.date}
+		state.tracker.MarkExported(rel)
+	}
+	stats, err := buildDeckPairs(out, changed, workers, binary, dates, locales, formats, pairCounts, onDeck)
+	if err != nil {
+		return nil, stats, unchanged, err
+	}
+
+	snap.Now = dates.Now
+	snap.Pairs = sortedEdges(pairCounts, true)
+	if err := state.kv.Set(ctx, snapshotKey, snap); err != nil {
+		return nil, stats, unchanged, err
+	}
+	if err := state.tracker.Save(ctx); err != nil {
+		return nil, stats, unchanged, err
+	}
+	return pairCounts, stats, unchanged, nil
+}
+
+// subtractDeck removes a snapshot deck's contribution from pairCounts,
+// dropping pairs no remaining deck contributes to
+func subtractDeck(pairCounts map[graphio.Pair]*graphio.PairCounts, d snapshotDeck, binary bool) {
+	if len(d.Partitions) == 0 {
+		return
+	}
+	contrib := make(map[graphio.Pair]*graphio.PairCounts)
+	graphio.AddCollectionPairs(contrib, &game.Collection{Partitions: d.Partitions}, binary, d.Decay)
+	for p, c := range contrib {
+		dst := pairCounts[p]
+		if dst == nil {
+			continue
+		}
+		dst.Set -= c.Set
+		dst.Multiset -= c.Multiset
+		dst.Weight -= c.Weight
+		if dst.Set <= 0 && dst.Multiset <= 0 {
+			delete(pairCounts, p)
+		}
+	}
+}
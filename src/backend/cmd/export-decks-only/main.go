@@ -1,188 +1,335 @@
 package main
 
 import (
-	"encoding/csv"
-	"encoding/json"
+	"context"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"sort"
+	"runtime"
+	"time"
 
+	"collections/cio"
+	"collections/games"
 	"collections/games/magic/game"
-
-	"github.com/DataDog/zstd"
+	"collections/graphio"
+	"collections/logger"
 )
 
-type pair struct {
-	card1 string
-	card2 string
-}
+var (
+	outputFormat  = flag.String("output-format", "", "Output format: csv, jsonl, parquet or gexf (default: from output extension)")
+	normalize     = flag.Bool("normalize-counts", false, "Count each pair once per deck (set presence) instead of multiplying copy counts, so singleton formats (Commander) and 4-of formats combine fairly")
+	halfLifeDays  = flag.Float64("half-life", 0, "Weight each deck's pairs by exponential age decay with this half-life in days; decks with estimated dates are skipped (0 disables)")
+	asOfDate      = flag.String("as-of", "", "Only include decks dated on or before this date (YYYY-MM-DD), reconstructing the graph at that point; decks with estimated dates are skipped")
+	workers       = flag.Int("workers", runtime.NumCPU(), "Collections to load and count in parallel")
+	minCount      = flag.Int64("min-count", 0, "Only write pairs appearing together in at least this many collections (COUNT_SET)")
+	topN          = flag.Int("top-n", 0, "Only write the N pairs with the highest COUNT_SET, after --min-count (0 writes all)")
+	incremental   = flag.Bool("incremental", false, "Only read decks changed since the last --incremental run with the same output, reusing a saved pair snapshot for the rest")
+	trackerPrefix = flag.String("tracker-prefix", "", "Where --incremental keeps its state, under the data dir's parent (default: .export-decks-only/<data-dir name>/<output name>)")
+	englishOnly   = flag.Bool("english-only", false, "Skip decks tagged with a non-English locale, unless --localized-names translates them")
+	namesFile     = flag.String("localized-names", "", "JSON file mapping localized card names to English; non-English decks are translated with it")
+	since         = flag.String("since", "", "Only include decks released on or after this time (RFC3339 or YYYY-MM-DD); decks without a release date are skipped")
+	until         = flag.String("until", "", "Only include decks released on or before this time (RFC3339 or YYYY-MM-DD, inclusive of the day); decks without a release date are skipped")
+	formats       games.FormatFilter
+)
 
-type counts struct {
-	set      int
-	multiset int
+func init() {
+	flag.Var(&formats, "format", "Only include decks of this format (repeatable; case-insensitive, abbreviations like \"mod\" allowed); decks without a format are skipped")
 }
 
 func main() {
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: go run main.go <data-dir> <output.csv>")
+	flag.Parse()
+	args := flag.Args()
+	if len(args) < 2 {
+		fmt.Println("Usage: go run main.go [--output-format csv|jsonl|parquet|gexf] [--normalize-counts] [--half-life DAYS] [--as-of YYYY-MM-DD] [--since TIME] [--until TIME] [--english-only] [--localized-names FILE] [--format FORMAT]... [--workers N] [--incremental [--tracker-prefix PREFIX]] [--min-count N] [--top-n N] <data-dir> <output.csv>")
 		os.Exit(1)
 	}
 
-	dataDir := os.Args[1]
-	outputFile := os.Args[2]
+	dataDir := args[0]
+	outputFile := args[1]
+
+	format, err := graphio.ResolveFormat(*outputFormat, outputFile)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	released, err := games.ParseDateRange(*since, *until)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	fmt.Println("🎯 Building DECK-ONLY co-occurrence graph...")
 	fmt.Println("   (Excluding sets and cubes to avoid contamination)")
+	if *normalize {
+		fmt.Println("   (Normalized counts: multiset counts use set presence)")
+	}
+	if *halfLifeDays > 0 {
+		fmt.Printf("   (Age decay: half-life %g days)\n", *halfLifeDays)
+	}
+	if *asOfDate != "" {
+		fmt.Printf("   (As of %s)\n", *asOfDate)
+	}
+	if !released.IsZero() {
+		fmt.Printf("   (Released %s)\n", released)
+	}
+	if *englishOnly {
+		fmt.Println("   (English decks only)")
+	}
+	if formats != nil {
+		fmt.Printf("   (Formats: %s)\n", formats)
+	}
 	fmt.Println()
 
 	// Find all collection files
-	var files []string
-	filepath.Walk(dataDir, func(path string, info os.FileInfo, err error) error {
-		if err == nil && !info.IsDir() && filepath.Ext(path) == ".zst" {
-			files = append(files, path)
+	files, _ := cio.FindCollectionFiles(dataDir, cio.FindOpts{SkipErrors: true})
+
+	dates, err := games.NewDatePolicy(*asOfDate, *halfLifeDays, time.Now())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	dates.Released = released
+	locales := localePolicy{englishOnly: *englishOnly}
+	if *namesFile != "" {
+		if locales.names, err = games.LoadLocalizedNames(*namesFile); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
 		}
-		return nil
-	})
+	}
 
 	// Build co-occurrence map
-	pairCounts := make(map[pair]*counts)
-
-	totalDecks := 0
-	skippedSets := 0
-	skippedCubes := 0
-	totalCards := 0
-	totalEdges := 0
-
-	for i, file := range files {
-		col, err := loadCollection(file)
+	pairCounts := make(map[graphio.Pair]*graphio.PairCounts)
+	var stats deckStats
+	unchanged := 0
+	if *incremental {
+		prefix := *trackerPrefix
+		if prefix == "" {
+			prefix = defaultTrackerPrefix(dataDir, outputFile)
+		}
+		ctx := context.Background()
+		log := logger.NewLogger(ctx)
+		state, err := openIncrementalState(ctx, log, filepath.Dir(dataDir), prefix, snapshotOptions(*normalize, *halfLifeDays, *asOfDate, released, *englishOnly, *namesFile, formats))
 		if err != nil {
-			fmt.Printf("⚠️  Failed to load %s: %v\n", filepath.Base(file), err)
-			continue
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
 		}
-
-		// CRITICAL: Skip sets and cubes
-		if col.Type.Type == "Set" {
-			skippedSets++
-			continue
-		}
-		if col.Type.Type == "Cube" {
-			skippedCubes++
-			continue
-		}
-
-		// Only process decks
-		collectionCards := 0
-		collectionEdges := 0
-
-		for _, partition := range col.Partitions {
-			cards := partition.Cards
-			n := len(cards)
-			collectionCards += n
-
-			for i := 0; i < n; i++ {
-				c := cards[i]
-
-				if c.Count > 1 {
-					p := makePair(c.Name, c.Name)
-					if pairCounts[p] == nil {
-						pairCounts[p] = &counts{}
-					}
-					pairCounts[p].multiset += c.Count - 1
-					collectionEdges++
-				}
-
-				for j := i + 1; j < n; j++ {
-					d := cards[j]
-					p := makePair(c.Name, d.Name)
-					if pairCounts[p] == nil {
-						pairCounts[p] = &counts{}
-					}
-					pairCounts[p].set += 1
-					pairCounts[p].multiset += c.Count * d.Count
-					collectionEdges++
-				}
-			}
+		defer state.close(ctx)
+		pairCounts, stats, unchanged, err = buildIncremental(ctx, os.Stdout, dataDir, files, *workers, *normalize, dates, locales, formats, state)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		stats, err = buildDeckPairs(os.Stdout, files, *workers, *normalize, dates, locales, formats, pairCounts, nil)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
 		}
-
-		totalDecks++
-		totalCards += collectionCards
-		totalEdges += collectionEdges
-
-		pct := float64(i+1) / float64(len(files)) * 100
-		fmt.Printf("✓ [%d/%d %.1f%%] Deck: %d cards, %d edges → %d unique pairs\n",
-			i+1, len(files), pct, collectionCards, collectionEdges, len(pairCounts))
 	}
 
 	fmt.Printf("\n📊 Summary:\n")
-	fmt.Printf("   Decks processed: %d\n", totalDecks)
-	fmt.Printf("   Sets skipped: %d\n", skippedSets)
-	fmt.Printf("   Cubes skipped: %d\n", skippedCubes)
-	fmt.Printf("   Total cards: %d\n", totalCards)
-	fmt.Printf("   Total edges: %d\n", totalEdges)
+	fmt.Printf("   Decks processed: %d\n", stats.totalDecks)
+	if *incremental {
+		fmt.Printf("   Unchanged since last run: %d\n", unchanged)
+	}
+	fmt.Printf("   Sets skipped: %d\n", stats.skippedSets)
+	fmt.Printf("   Cubes skipped: %d\n", stats.skippedCubes)
+	for _, reason := range []string{games.SkipEstimated, games.SkipAfterAsOf, games.SkipReleased, skipNonEnglish, skipFormat} {
+		if n := stats.skipped[reason]; n > 0 {
+			fmt.Printf("   Decks skipped (%s): %d\n", reason, n)
+		}
+	}
+	fmt.Printf("   Total cards: %d\n", stats.totalCards)
+	fmt.Printf("   Total edges: %d\n", stats.totalEdges)
 	fmt.Printf("   Unique pairs: %d\n", len(pairCounts))
 
-	// Write CSV
-	f, err := os.Create(outputFile)
+	// Sort pairs for deterministic output
+	edges := sortedEdges(pairCounts, *halfLifeDays > 0)
+
+	// Write in the requested format
+	var writerOpts []graphio.WriterOption
+	if *halfLifeDays > 0 {
+		writerOpts = append(writerOpts, &graphio.OptWriterWeight{})
+	}
+	w, err := graphio.Create(outputFile, format, writerOpts...)
 	if err != nil {
 		fmt.Printf("Error creating output: %v\n", err)
 		os.Exit(1)
 	}
-	defer f.Close()
-
-	w := csv.NewWriter(f)
-	defer w.Flush()
-
-	w.Write([]string{"NAME_1", "NAME_2", "COUNT_SET", "COUNT_MULTISET"})
-
-	var sortedPairs []pair
-	for p := range pairCounts {
-		sortedPairs = append(sortedPairs, p)
+	if *minCount > 0 || *topN > 0 {
+		w = graphio.NewFilterWriter(w, graphio.EdgeFilter{MinCount: *minCount, TopN: *topN})
 	}
-	sort.Slice(sortedPairs, func(i, j int) bool {
-		if sortedPairs[i].card1 != sortedPairs[j].card1 {
-			return sortedPairs[i].card1 < sortedPairs[j].card1
+	for _, e := range edges {
+		if err := w.Write(e); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
 		}
-		return sortedPairs[i].card2 < sortedPairs[j].card2
-	})
-
-	for _, p := range sortedPairs {
-		c := pairCounts[p]
-		w.Write([]string{
-			p.card1,
-			p.card2,
-			fmt.Sprintf("%d", c.set),
-			fmt.Sprintf("%d", c.multiset),
-		})
+	}
+	if err := w.Close(); err != nil {
+		fmt.Printf("Error writing output: %v\n", err)
+		os.Exit(1)
 	}
 
 	fmt.Printf("\n✅ Deck-only graph exported to %s\n", outputFile)
 }
 
-func loadCollection(path string) (*game.Collection, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
+// deckPairs is one deck's contribution to the graph, counted by a worker
+// and merged in file order
+type deckPairs struct {
+	err   error
+	typ   string // collection type; sets and cubes are not counted
+	skip  string // date, locale or format skip reason
+	pairs map[graphio.Pair]*graphio.PairCounts
+	cards int
+	edges int
+
+	// What produced pairs, kept for incremental snapshots
+	partitions []game.Partition
+	decay      float64
+	date       time.Time
+}
+
+// deckStats summarizes a buildDeckPairs run
+type deckStats struct {
+	totalDecks   int
+	skippedSets  int
+	skippedCubes int
+	totalCards   int
+	totalEdges   int
+	skipped      map[string]int // decks skipped by date, locale or format, by reason
+}
 
-	if filepath.Ext(path) == ".zst" {
-		data, err = zstd.Decompress(nil, data)
+// buildDeckPairs counts the pairs of every deck in files into pairCounts,
+// skipping sets, cubes, decks the date and locale policies drop and decks
+// formats doesn't match. Files are loaded and counted on up to workers
+// goroutines but merged in order, so the counts are the same for any
+// number of workers. onDeck, if set, sees each file's result after it is
+// merged. Progress lines go to out.
+func buildDeckPairs(out io.Writer, files []string, workers int, binary bool, dates games.DatePolicy, locales localePolicy, formats games.FormatFilter, pairCounts map[graphio.Pair]*graphio.PairCounts, onDeck func(file string, dp deckPairs)) (deckStats, error) {
+	stats := deckStats{skipped: make(map[string]int)}
+
+	count := func(file string) deckPairs {
+		col, err := game.LoadCollectionFile(file)
 		if err != nil {
-			return nil, err
+			return deckPairs{err: err}
+		}
+		// CRITICAL: Skip sets and cubes
+		if col.Type.Type == "Set" || col.Type.Type == "Cube" {
+			return deckPairs{typ: col.Type.Type}
+		}
+		if !formats.Match(deckFormat(col)) {
+			return deckPairs{skip: skipFormat}
+		}
+		if skip := locales.apply(col); skip != "" {
+			return deckPairs{skip: skip}
+		}
+		decay, skip := dates.Weigh(col.EventDate(), col.ReleaseDate, col.ScrapedAt)
+		if skip != "" {
+			return deckPairs{skip: skip}
+		}
+		dp := deckPairs{typ: col.Type.Type, pairs: make(map[graphio.Pair]*graphio.PairCounts), partitions: col.Partitions, decay: decay}
+		dp.date, _ = col.EffectiveDate()
+		dp.cards, dp.edges = graphio.AddCollectionPairs(dp.pairs, col, binary, decay)
+		return dp
+	}
+
+	i := 0
+	merge := func(file string, dp deckPairs) error {
+		i++
+		if onDeck != nil {
+			defer onDeck(file, dp)
+		}
+		switch {
+		case dp.err != nil:
+			fmt.Fprintf(out, "⚠️  Failed to load %s: %v\n", filepath.Base(file), dp.err)
+			return nil
+		case dp.typ == "Set":
+			stats.skippedSets++
+			return nil
+		case dp.typ == "Cube":
+			stats.skippedCubes++
+			return nil
+		case dp.skip != "":
+			stats.skipped[dp.skip]++
+			return nil
 		}
+
+		graphio.MergePairs(pairCounts, dp.pairs)
+		stats.totalDecks++
+		stats.totalCards += dp.cards
+		stats.totalEdges += dp.edges
+
+		pct := float64(i) / float64(len(files)) * 100
+		fmt.Fprintf(out, "✓ [%d/%d %.1f%%] Deck: %d cards, %d edges → %d unique pairs\n",
+			i, len(files), pct, dp.cards, dp.edges, len(pairCounts))
+		return nil
 	}
 
-	var col game.Collection
-	if err := json.Unmarshal(data, &col); err != nil {
-		return nil, err
+	walk := func(emit func(string) error) error {
+		for _, file := range files {
+			if err := emit(file); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
+	err := cio.ProcessOrdered(workers, walk, count, merge)
+	return stats, err
+}
 
-	return &col, nil
+// sortedEdges returns pairCounts as edges in graphio.SortEdges order,
+// with Weight set only when weighted
+func sortedEdges(pairCounts map[graphio.Pair]*graphio.PairCounts, weighted bool) []graphio.Edge {
+	edges := make([]graphio.Edge, 0, len(pairCounts))
+	for p, c := range pairCounts {
+		e := graphio.Edge{
+			Card1:         p.Card1,
+			Card2:         p.Card2,
+			CountSet:      int64(c.Set),
+			CountMultiset: int64(c.Multiset),
+		}
+		if weighted {
+			e.Weight = c.Weight
+		}
+		edges = append(edges, e)
+	}
+	graphio.SortEdges(edges)
+	return edges
 }
 
-func makePair(a, b string) pair {
-	if a > b {
-		a, b = b, a
+const skipNonEnglish = "non-English"
+
+// localePolicy drops or translates decks tagged with a non-English
+// locale. The zero value keeps every deck as it is.
+type localePolicy struct {
+	englishOnly bool
+	names       games.LocalizedNames // translates non-English decks when set
+}
+
+// apply translates a non-English col's card names in place, or returns
+// the reason col should be skipped
+func (p localePolicy) apply(col *game.Collection) string {
+	if games.IsEnglishLocale(col.Locale) {
+		return ""
+	}
+	if p.names != nil {
+		col.TranslateCardNames(p.names)
+		return ""
+	}
+	if p.englishOnly {
+		return skipNonEnglish
+	}
+	return ""
+}
+
+const skipFormat = "format"
+
+// deckFormat is col's deck format, or "" when it has none
+func deckFormat(col *game.Collection) string {
+	if deck, ok := col.Type.Inner.(*game.CollectionTypeDeck); ok {
+		return deck.Format
 	}
-	return pair{card1: a, card2: b}
+	return ""
 }
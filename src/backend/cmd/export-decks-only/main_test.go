@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"collections/games"
+	"collections/games/magic/game"
+	"collections/graphio"
+	"collections/graphio/graphiotest"
+	"collections/logger"
+)
+
+func TestBuildDeckPairsLocale(t *testing.T) {
+	dir := t.TempDir()
+	english := graphiotest.Deck("Modern", 1, "Lightning Bolt", "Mountain")
+	japanese := graphiotest.Deck("Modern", 1, "稲妻", "山")
+	japanese.Locale = "ja"
+	var files []string
+	for i, col := range []*game.Collection{english, japanese} {
+		data, err := json.Marshal(col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, filepath.Join(dir, fmt.Sprintf("%d.json", i)))
+		if err := os.WriteFile(files[i], data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The Japanese deck is tagged on disk
+	if col, err := game.LoadCollectionFile(files[1]); err != nil || col.Locale != "ja" {
+		t.Fatalf("LoadCollectionFile() locale = %+v, %v, want ja", col, err)
+	}
+
+	names := make(games.LocalizedNames)
+	names.Add("稲妻", "Lightning Bolt")
+	names.Add("山", "Mountain")
+	bolt := graphio.MakePair("Lightning Bolt", "Mountain")
+	tests := []struct {
+		name    string
+		locales localePolicy
+		decks   int
+		set     int
+		pairs   int
+	}{
+		{"all decks", localePolicy{}, 2, 1, 2},
+		{"english only", localePolicy{englishOnly: true}, 1, 1, 1},
+		{"translated", localePolicy{englishOnly: true, names: names}, 2, 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairCounts := make(map[graphio.Pair]*graphio.PairCounts)
+			stats, err := buildDeckPairs(io.Discard, files, 1, false, games.DatePolicy{}, tt.locales, nil, pairCounts, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.totalDecks != tt.decks {
+				t.Errorf("decks = %d, want %d (skipped %v)", stats.totalDecks, tt.decks, stats.skipped)
+			}
+			if got := pairCounts[bolt]; got == nil || got.Set != tt.set {
+				t.Errorf("Lightning Bolt/Mountain = %+v, want COUNT_SET %d", got, tt.set)
+			}
+			if len(pairCounts) != tt.pairs {
+				t.Errorf("unique pairs = %d, want %d", len(pairCounts), tt.pairs)
+			}
+		})
+	}
+}
+
+func TestBuildDeckPairsFormat(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for i, col := range []*game.Collection{
+		graphiotest.Deck("Modern", 4, "Lightning Bolt", "Mountain"),
+		graphiotest.Deck("mod", 4, "Lightning Bolt", "Ragavan, Nimble Pilferer"),
+		graphiotest.Deck("Commander", 1, "Sol Ring", "Command Tower"),
+		graphiotest.Deck("", 4, "Lightning Bolt", "Chain Lightning"),
+	} {
+		data, err := json.Marshal(col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, filepath.Join(dir, fmt.Sprintf("%d.json", i)))
+		if err := os.WriteFile(files[i], data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var formats games.FormatFilter
+	if err := formats.Set("Modern"); err != nil {
+		t.Fatal(err)
+	}
+	pairCounts := make(map[graphio.Pair]*graphio.PairCounts)
+	stats, err := buildDeckPairs(io.Discard, files, 1, false, games.DatePolicy{}, localePolicy{}, formats, pairCounts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.totalDecks != 2 || stats.skipped[skipFormat] != 2 {
+		t.Errorf("decks = %d, skipped %v, want 2 and 2 for format", stats.totalDecks, stats.skipped)
+	}
+	for _, p := range []graphio.Pair{graphio.MakePair("Sol Ring", "Command Tower"), graphio.MakePair("Lightning Bolt", "Chain Lightning")} {
+		if pairCounts[p] != nil {
+			t.Errorf("%s/%s = %+v, want no pair from a non-Modern deck", p.Card1, p.Card2, *pairCounts[p])
+		}
+	}
+	for _, p := range []graphio.Pair{graphio.MakePair("Lightning Bolt", "Mountain"), graphio.MakePair("Lightning Bolt", "Ragavan, Nimble Pilferer")} {
+		if pairCounts[p] == nil {
+			t.Errorf("missing %s/%s from a Modern deck", p.Card1, p.Card2)
+		}
+	}
+}
+
+func buildWeighted(tb testing.TB, files []string, workers int) (map[graphio.Pair]*graphio.PairCounts, deckStats) {
+	tb.Helper()
+	dates, err := games.NewDatePolicy("", 90, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	pairCounts := make(map[graphio.Pair]*graphio.PairCounts)
+	stats, err := buildDeckPairs(io.Discard, files, workers, false, dates, localePolicy{}, nil, pairCounts, nil)
+	if err != nil {
+		tb.Fatalf("buildDeckPairs() error = %v", err)
+	}
+	return pairCounts, stats
+}
+
+func BenchmarkBuildDeckPairs(b *testing.B) {
+	files := graphiotest.WriteCollectionTree(b, 3000)
+	for _, workers := range []int{1, 4, runtime.NumCPU()} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				buildWeighted(b, files, workers)
+			}
+		})
+	}
+}
+
+func TestBuildIncrementalMatchesFullRebuild(t *testing.T) {
+	files := graphiotest.WriteCollectionTree(t, 50)
+	dataDir := filepath.Dir(files[0])
+	// The tracker stores export times to the second; date the files
+	// safely before the first run
+	past := time.Now().Add(-time.Hour)
+	for _, file := range files {
+		if err := os.Chtimes(file, past, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stateDir := t.TempDir()
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	dates, err := games.NewDatePolicy("", 90, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	incremental := func(files []string) (map[graphio.Pair]*graphio.PairCounts, deckStats, int) {
+		t.Helper()
+		state, err := openIncrementalState(ctx, log, stateDir, defaultTrackerPrefix(dataDir, "out.csv"), snapshotOptions(false, 90, "", games.DateRange{}, false, "", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer state.close(ctx)
+		pairCounts, stats, unchanged, err := buildIncremental(ctx, io.Discard, dataDir, files, 4, false, dates, localePolicy{}, nil, state)
+		if err != nil {
+			t.Fatalf("buildIncremental() error = %v", err)
+		}
+		return pairCounts, stats, unchanged
+	}
+	assertSame := func(got, want map[graphio.Pair]*graphio.PairCounts, exact bool) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("found %d pairs, want %d", len(got), len(want))
+		}
+		for p, w := range want {
+			g := got[p]
+			if g == nil || g.Set != w.Set || g.Multiset != w.Multiset {
+				t.Fatalf("counts(%v) = %+v, want %+v", p, g, *w)
+			}
+			if diff := math.Abs(g.Weight - w.Weight); diff > 1e-9 || exact && diff != 0 {
+				t.Fatalf("weight(%v) = %v, want %v", p, g.Weight, w.Weight)
+			}
+		}
+	}
+
+	// A fresh run is the non-incremental build
+	full, _ := buildWeighted(t, files, 1)
+	got, stats, unchanged := incremental(files)
+	assertSame(got, full, true)
+	if unchanged != 0 || stats.totalDecks != 45 {
+		t.Errorf("fresh run: %d unchanged, %d decks, want 0 and 45", unchanged, stats.totalDecks)
+	}
+
+	// Nothing changed
+	got, stats, unchanged = incremental(files)
+	assertSame(got, full, true)
+	if unchanged != 50 || stats.totalDecks != 0 {
+		t.Errorf("rerun: %d unchanged, %d decks, want 50 and 0", unchanged, stats.totalDecks)
+	}
+
+	// Rewrite one deck with other cards, add one and delete one
+	extra := graphiotest.WriteCollectionTree(t, 3)
+	data, err := os.ReadFile(extra[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(files[1], data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(files[1], future, future); err != nil {
+		t.Fatal(err)
+	}
+	added := filepath.Join(dataDir, "added.json.zst")
+	if data, err = os.ReadFile(extra[2]); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(added, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(files[2]); err != nil {
+		t.Fatal(err)
+	}
+	files = append(files[:2:2], files[3:]...)
+	files = append(files, added)
+
+	full, _ = buildWeighted(t, files, 1)
+	got, stats, unchanged = incremental(files)
+	assertSame(got, full, false)
+	if unchanged != 48 || stats.totalDecks != 2 {
+		t.Errorf("after edits: %d unchanged, %d decks, want 48 and 2", unchanged, stats.totalDecks)
+	}
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 
 	"collections/blob"
+	"collections/games"
 	"collections/games/magic/dataset"
 	"collections/games/magic/dataset/mtgtop8"
 	"collections/games/magic/dataset/scryfall"
@@ -13,17 +15,29 @@ import (
 	"collections/transform/cardco"
 )
 
+var (
+	sourceWeights = make(cardco.SourceWeights)
+	weightSum     = flag.Bool("weight-sum", false, "Add a WEIGHT_SUM column: the summed weight of the collections each pair occurs in")
+	formats       games.FormatFilter
+)
+
+func init() {
+	flag.Var(sourceWeights, "source-weight", "Scale a source's (or collection type's) counts: source=factor, repeatable. Set and Cube default to 0")
+	flag.Var(&formats, "format", "Only include decks of this format (repeatable; case-insensitive, abbreviations like \"mod\" allowed); sets, cubes and decks without a format are skipped")
+}
+
 func main() {
 	ctx := context.Background()
 	log := logger.NewLogger(ctx)
 	log.SetLevel("INFO")
 
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run main.go <pairs.csv>")
+	flag.Parse()
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: go run main.go [--source-weight source=factor]... [--weight-sum] [--format FORMAT]... <pairs.csv>")
 		os.Exit(1)
 	}
 
-	pairsFile := os.Args[1]
+	pairsFile := flag.Arg(0)
 
 	// Create blob bucket
 	bucket, err := blob.NewBucket(ctx, log, "file://./data-full")
@@ -49,7 +63,8 @@ func main() {
 
 	// Run transform
 	log.Infof(ctx, "Processing collections...")
-	_, err = tr.Transform(ctx, datasets)
+	options := append(sourceWeights.Options(), cardco.FormatOptions(formats)...)
+	_, err = tr.Transform(ctx, datasets, options...)
 	if err != nil {
 		log.Errorf(ctx, "Transform failed: %v", err)
 		os.Exit(1)
@@ -57,7 +72,11 @@ func main() {
 
 	// Export pairs CSV
 	log.Infof(ctx, "Exporting pairs to %s...", pairsFile)
-	err = tr.ExportCSV(ctx, pairsFile)
+	var exportOpts []cardco.ExportOption
+	if *weightSum {
+		exportOpts = append(exportOpts, &cardco.OptExportWeightSum{})
+	}
+	err = tr.ExportCSV(ctx, pairsFile, exportOpts...)
 	if err != nil {
 		log.Errorf(ctx, "Export failed: %v", err)
 		os.Exit(1)
@@ -2,239 +2,547 @@ package main
 
 // Export heterogeneous graph preserving deck context
 // Output: JSONL with deck structure intact
+//
+// Files are decoded on --workers goroutines and written as they finish,
+// so the output order varies between runs; --sort orders it by deck_id,
+// holding every record until the end.
+//
+// With --incremental, only decks that are new or changed since the last
+// incremental run are exported and appended to the output. Changes are
+// detected by mod time (or the collection's updated_at/version), or by
+// content hash with --by-hash.
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"time"
 
-	"github.com/DataDog/zstd"
+	"collections/blob"
+	"collections/cio"
+	"collections/games"
+	_ "collections/games/digimon/game" // Register collection types
+	mtg "collections/games/magic/game"
+	_ "collections/games/onepiece/game"  // Register collection types
+	_ "collections/games/pokemon/game"   // Register collection types
+	_ "collections/games/riftbound/game" // Register collection types
+	_ "collections/games/yugioh/game"    // Register collection types
+	"collections/logger"
 )
 
 type DeckRecord struct {
-	DeckID     string       `json:"deck_id"`
-	Archetype  string       `json:"archetype"`
-	Format     string       `json:"format"`
-	URL        string       `json:"url"`
-	Source     string       `json:"source,omitempty"`
-	Player     string       `json:"player,omitempty"`
-	Event      string       `json:"event,omitempty"`
-	Placement  int          `json:"placement,omitempty"`
-	EventDate  string       `json:"event_date,omitempty"`
-	ScrapedAt  string       `json:"scraped_at,omitempty"`
-	Cards      []CardInDeck `json:"cards"`
+	DeckID    string       `json:"deck_id"`
+	Archetype string       `json:"archetype"`
+	Format    string       `json:"format"`
+	URL       string       `json:"url"`
+	Source    string       `json:"source,omitempty"`
+	Player    string       `json:"player,omitempty"`
+	Event     string       `json:"event,omitempty"`
+	Placement *int         `json:"placement,omitempty"`
+	EventDate string       `json:"event_date,omitempty"`
+	ScrapedAt string       `json:"scraped_at,omitempty"`
+	UpdatedAt string       `json:"updated_at,omitempty"`
+	Version   int          `json:"version,omitempty"`
+	Cards     []CardInDeck `json:"cards"`
 }
 
 type CardInDeck struct {
 	Name      string `json:"name"`
 	Count     int    `json:"count"`
 	Partition string `json:"partition"`
+	ImageURL  string `json:"image_url,omitempty"` // With --with-images; empty when the card index has none
 }
 
+var (
+	withImages    = flag.Bool("with-images", false, "Annotate each card with an image URL from the stored card corpus (scryfall, pokemontcg-data)")
+	cardsDir      = flag.String("cards", "", "Directory searched for stored cards with --with-images (default: data-dir)")
+	incremental   = flag.Bool("incremental", false, "Only export decks that are new or changed since the last --incremental run, appending to the output")
+	trackerPrefix = flag.String("tracker-prefix", "", "Where --incremental keeps its tracker, under the data dir's parent (default: data-dir)")
+	byHash        = flag.Bool("by-hash", false, "With --incremental, detect changes by collection content hash instead of mod time, so rewritten but unchanged files (recompression, backfills) are not re-exported")
+	perSource     = flag.Int("limit-per-source", 0, "Export at most this many decks per source (0 = no limit)")
+	since         = flag.String("since", "", "Only export decks released on or after this time (RFC3339 or YYYY-MM-DD); decks without a release date are skipped")
+	until         = flag.String("until", "", "Only export decks released on or before this time (RFC3339 or YYYY-MM-DD, inclusive of the day); decks without a release date are skipped")
+	workers       = flag.Int("workers", runtime.NumCPU(), "Collections to read and decode in parallel")
+	sortOutput    = flag.Bool("sort", false, "Sort the output by deck_id for reproducible diffs; every record is held in memory until the end")
+)
+
 func main() {
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: export-hetero <data-dir> <output.jsonl>")
+	flag.Parse()
+	args := flag.Args()
+	if len(args) < 2 {
+		fmt.Println("Usage: export-hetero [--with-images] [--cards DIR] [--incremental [--tracker-prefix PREFIX] [--by-hash]] [--limit-per-source N] [--since TIME] [--until TIME] [--workers N] [--sort] <data-dir> <output.jsonl>")
 		os.Exit(1)
 	}
 
-	dataDir := os.Args[1]
-	outputFile := os.Args[2]
+	dates, err := games.ParseDateRange(*since, *until)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 
-	fmt.Println("Exporting heterogeneous graph structure...")
+	opts := exportOptions{
+		DataDir:       args[0],
+		OutputFile:    args[1],
+		Incremental:   *incremental,
+		TrackerPrefix: *trackerPrefix,
+		ByHash:        *byHash,
+		PerSource:     *perSource,
+		Dates:         dates,
+		Workers:       *workers,
+		Sort:          *sortOutput,
+	}
+	if opts.TrackerPrefix == "" {
+		opts.TrackerPrefix = opts.DataDir
+	}
 
-	var files []string
-	filepath.Walk(dataDir, func(path string, info os.FileInfo, err error) error {
-		if err == nil && !info.IsDir() && filepath.Ext(path) == ".zst" {
-			files = append(files, path)
+	if *withImages {
+		dir := *cardsDir
+		if dir == "" {
+			dir = opts.DataDir
 		}
-		return nil
-	})
+		var err error
+		opts.Images, err = loadImageIndex(dir)
+		if err != nil {
+			fmt.Printf("Error: Failed to load card images from %s: %v\n", dir, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Loaded image URLs for %d cards\n", len(opts.Images))
+	}
 
-	out, _ := os.Create(outputFile)
-	defer out.Close()
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
 
-	encoder := json.NewEncoder(out)
-	exported := 0
+	if err := runExport(ctx, log, opts); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
 
-	errorCount := 0
-	maxErrorsToLog := 10
+type exportOptions struct {
+	DataDir    string
+	OutputFile string
+	// Incremental exports only new or changed decks, tracked under
+	// TrackerPrefix in the bucket at DataDir's parent, and appends to
+	// OutputFile. A full export overwrites it and leaves the tracker alone.
+	Incremental   bool
+	TrackerPrefix string
+	ByHash        bool
+	Images        map[imageKey]string // Card -> image URL; nil disables
+	// PerSource caps the decks exported per source; 0 is no cap. Capped
+	// decks are not marked exported, so a later incremental run can pick
+	// them up.
+	PerSource int
+	// Dates keeps decks whose release date is in range; with either bound
+	// set, decks without one are left out. Left out decks are not marked
+	// exported.
+	Dates games.DateRange
+	// Workers is how many files are read and decoded at once; Sort writes
+	// the records ordered by deck_id instead of as they finish
+	Workers int
+	Sort    bool
+}
 
-	for _, file := range files {
-		data, err := os.ReadFile(file)
-		if err != nil {
-			errorCount++
-			if errorCount <= maxErrorsToLog {
-				fmt.Printf("⚠️  Failed to read %s: %v\n", filepath.Base(file), err)
-			}
-			continue
-		}
+// errorLog counts per-file errors, printing only the first few
+type errorLog struct {
+	count  int
+	maxLog int
+}
+
+func (l *errorLog) add(format string, args ...any) {
+	l.count++
+	if l.count <= l.maxLog {
+		fmt.Printf("⚠️  "+format+"\n", args...)
+	}
+}
+
+func (l *errorLog) summary() {
+	if l.count == 0 {
+		return
+	}
+	if l.count > l.maxLog {
+		fmt.Printf("⚠️  %d additional errors occurred (showing first %d)\n", l.count-l.maxLog, l.maxLog)
+	}
+	fmt.Printf("⚠️  Total errors: %d\n", l.count)
+}
+
+// loadedDeck is a deck read by a worker, ready to write
+type loadedDeck struct {
+	key         string // Data-dir relative path, the tracker key
+	contentHash string // With ByHash
+	record      DeckRecord
+}
+
+// loadOutcome is what a worker made of a file
+type loadOutcome int
+
+const (
+	outcomeWrite      loadOutcome = iota // Write the deck
+	outcomeUnchanged                     // Already exported by an incremental run
+	outcomeOutOfRange                    // Released outside Dates
+	outcomeEmpty                         // No cards
+)
+
+func runExport(ctx context.Context, log *logger.Logger, opts exportOptions) error {
+	dataDir := opts.DataDir
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
 
-		decompressed, err := zstd.Decompress(nil, data)
+	var tracker *games.ExportTracker
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if opts.Incremental {
+		// Create blob bucket for tracking (using file:// for local storage)
+		trackerBlob, err := blob.NewBucket(ctx, log, "file://"+filepath.Dir(dataDir))
 		if err != nil {
-			errorCount++
-			if errorCount <= maxErrorsToLog {
-				fmt.Printf("⚠️  Failed to decompress %s: %v\n", filepath.Base(file), err)
-			}
-			continue
+			return fmt.Errorf("failed to create blob bucket: %w", err)
 		}
+		defer trackerBlob.Close(ctx)
 
-		var obj map[string]interface{}
-		if err := json.Unmarshal(decompressed, &obj); err != nil {
-			errorCount++
-			if errorCount <= maxErrorsToLog {
-				fmt.Printf("⚠️  Failed to parse JSON in %s: %v\n", filepath.Base(file), err)
-			}
-			continue
+		// Load export tracker
+		tracker = games.NewExportTracker(log, trackerBlob, opts.TrackerPrefix)
+		if err := tracker.Load(ctx); err != nil {
+			fmt.Printf("Warning: Failed to load export tracker: %v (starting fresh)\n", err)
 		}
 
-		// FIXED: Data is at root level, not under "collection"
-		scrapedAt := time.Now().UTC().Format(time.RFC3339)
-		deck := DeckRecord{
-			DeckID:    filepath.Base(file),
-			URL:       getString(obj, "url"),
-			Source:    getString(obj, "source"),
-			ScrapedAt: scrapedAt,
-		}
+		fmt.Println("Exporting new/changed decks incrementally...")
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	} else {
+		fmt.Println("Exporting heterogeneous graph structure...")
+	}
 
-		// Backfill source from URL or file path if missing
-		if deck.Source == "" {
-			deck.Source = inferSourceFromPath(deck.URL, file)
-		}
+	out, err := os.OpenFile(opts.OutputFile, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open output file: %w", err)
+	}
+	defer out.Close()
 
-		// Get type info - FIXED structure
-		if typeObj, ok := obj["type"].(map[string]interface{}); ok {
-			if inner, ok := typeObj["inner"].(map[string]interface{}); ok {
-				deck.Archetype = getString(inner, "archetype")
-				deck.Format = getString(inner, "format")
-				deck.Player = getString(inner, "player")
-				deck.Event = getString(inner, "event")
-				deck.Placement = getInt(inner, "placement")
-				deck.EventDate = getString(inner, "event_date")
-			}
+	bw := bufio.NewWriter(out)
+	encoder := json.NewEncoder(bw)
+	exported := 0
+	skipped := 0
+	capped := 0
+	outOfRange := 0
+	sourceCap := &games.SourceCap{Limit: opts.PerSource}
+	errs := &errorLog{maxLog: 10}
+
+	// write encodes a deck and marks it exported; it runs on one goroutine
+	// at a time, from merge or after the walk
+	write := func(d loadedDeck) error {
+		if !sourceCap.Allow(d.record.Source) {
+			capped++
+			return nil
+		}
+		if err := encoder.Encode(deckRecordMap(d.record)); err != nil {
+			return fmt.Errorf("failed to write deck: %w", err)
 		}
+		exported++
+		if tracker == nil {
+			return nil
+		}
+		if opts.ByHash {
+			tracker.MarkExportedHash(d.key, d.contentHash)
+		} else {
+			tracker.MarkExported(d.key)
+		}
+		tracker.SetSource(d.key, d.record.Source)
+		return nil
+	}
 
-		// Get cards
-		if parts, ok := obj["partitions"].([]interface{}); ok {
-			for _, p := range parts {
-				part := p.(map[string]interface{})
-				partName := getString(part, "name")
-
-				if cards, ok := part["cards"].([]interface{}); ok {
-					for _, c := range cards {
-						card := c.(map[string]interface{})
-						deck.Cards = append(deck.Cards, CardInDeck{
-							Name:      getString(card, "name"),
-							Count:     getInt(card, "count"),
-							Partition: partName,
-						})
-					}
-				}
-			}
+	// Files are read and decoded on worker goroutines and merged in walk
+	// order, so output order and the decks kept under the per-source cap
+	// don't depend on which worker finishes first
+	type loaded struct {
+		deck    loadedDeck
+		outcome loadOutcome
+		err     error
+	}
+	var held []loadedDeck
+	walk := func(emit func(string) error) error {
+		return cio.WalkCollectionFiles(dataDir, cio.FindOpts{}, emit)
+	}
+	load := func(file string) loaded {
+		d, outcome, err := loadDeck(ctx, dataDir, file, tracker, opts)
+		return loaded{d, outcome, err}
+	}
+	merge := func(file string, l loaded) error {
+		switch {
+		case l.err != nil:
+			errs.add("%v", l.err)
+		case l.outcome == outcomeUnchanged:
+			skipped++
+		case l.outcome == outcomeOutOfRange:
+			outOfRange++
+		case l.outcome == outcomeEmpty:
+		case opts.Sort:
+			held = append(held, l.deck)
+		default:
+			return write(l.deck)
 		}
+		return nil
+	}
+	if err := cio.ProcessOrdered(workers, walk, load, merge); err != nil {
+		return fmt.Errorf("failed to export %s: %w", dataDir, err)
+	}
 
-		if len(deck.Cards) > 0 {
-			// Create map with timestamp aliases for backward compatibility
-			deckMap := map[string]interface{}{
-				"deck_id":       deck.DeckID,
-				"archetype":     deck.Archetype,
-				"format":        deck.Format,
-				"url":           deck.URL,
-				"source":        deck.Source,
-				"player":        deck.Player,
-				"event":         deck.Event,
-				"placement":     deck.Placement,
-				"event_date":    deck.EventDate,
-				"scraped_at":    deck.ScrapedAt,
-				"timestamp":     deck.ScrapedAt, // Alias for backward compatibility
-				"created_at":    deck.ScrapedAt, // Alias for backward compatibility
-				"export_version": "1.0",         // Schema version for validation
-				"cards":         deck.Cards,
+	if opts.Sort {
+		sort.Slice(held, func(i, j int) bool {
+			if held[i].record.DeckID != held[j].record.DeckID {
+				return held[i].record.DeckID < held[j].record.DeckID
+			}
+			return held[i].key < held[j].key
+		})
+		for _, d := range held {
+			if err := write(d); err != nil {
+				return err
 			}
-			encoder.Encode(deckMap)
-			exported++
 		}
 	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
 
-	fmt.Printf("✓ Exported %d decks with full context\n", exported)
-	if errorCount > 0 {
-		if errorCount > maxErrorsToLog {
-			fmt.Printf("⚠️  %d additional errors occurred (showing first %d)\n", errorCount-maxErrorsToLog, maxErrorsToLog)
+	if tracker == nil {
+		fmt.Printf("✓ Exported %d decks with full context\n", exported)
+	} else {
+		// Save tracker
+		if err := tracker.Save(ctx); err != nil {
+			fmt.Printf("Warning: Failed to save export tracker: %v\n", err)
 		}
-		fmt.Printf("⚠️  Total errors: %d\n", errorCount)
-	}
-}
 
-func getString(m map[string]interface{}, key string) string {
-	if v, ok := m[key].(string); ok {
-		return v
+		total, recent := tracker.GetStats()
+		fmt.Printf("✓ Exported %d new/changed decks (skipped %d unchanged)\n", exported, skipped)
+		fmt.Printf("  Total tracked: %d, Recent (24h): %d\n", total, recent)
+		bySource := tracker.GetStatsBySource()
+		sources := make([]string, 0, len(bySource))
+		for source := range bySource {
+			sources = append(sources, source)
+		}
+		sort.Strings(sources)
+		for _, source := range sources {
+			s := bySource[source]
+			fmt.Printf("    %-24s tracked %d, recent (24h) %d\n", source, s.Total, s.Recent)
+		}
 	}
-	return ""
-}
-
-func getInt(m map[string]interface{}, key string) int {
-	if v, ok := m[key].(float64); ok {
-		return int(v)
+	if capped > 0 {
+		fmt.Printf("  Left out %d decks over the limit of %d per source\n", capped, opts.PerSource)
+	}
+	if outOfRange > 0 {
+		fmt.Printf("  Left out %d decks released outside %s\n", outOfRange, opts.Dates)
 	}
-	return 0 // Fixed: Default to 0, not 1 (0 = unknown/missing)
+	errs.summary()
+	return nil
 }
 
-func inferSourceFromPath(url string, filePath string) string {
-	// Try URL first
-	urlLower := strings.ToLower(url)
-	if strings.Contains(urlLower, "mtgtop8.com") || strings.Contains(urlLower, "mtgtop8") {
-		return "mtgtop8"
+// loadDeck reads and decodes one collection file and decides whether it
+// is exported. It only reads the tracker, so it is safe to run
+// concurrently.
+func loadDeck(ctx context.Context, dataDir, file string, tracker *games.ExportTracker, opts exportOptions) (loadedDeck, loadOutcome, error) {
+	// Relative blob key for tracking
+	key, _ := filepath.Rel(dataDir, file)
+	d := loadedDeck{key: key}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return d, 0, fmt.Errorf("failed to read %s: %w", filepath.Base(file), err)
 	}
-	if strings.Contains(urlLower, "mtggoldfish.com") || strings.Contains(urlLower, "goldfish") {
-		return "goldfish"
+	decompressed, err := cio.DecodeCollectionData(file, data)
+	if err != nil {
+		return d, 0, fmt.Errorf("failed to decompress %s: %w", filepath.Base(file), err)
 	}
-	if strings.Contains(urlLower, "deckbox.org") || strings.Contains(urlLower, "deckbox") {
-		return "deckbox"
+	item, err := mtg.DeserializeAsAnyCollection(file, decompressed)
+	if err != nil {
+		return d, 0, fmt.Errorf("failed to parse %s: %w", filepath.Base(file), err)
 	}
-	if strings.Contains(urlLower, "limitlesstcg.com") || strings.Contains(urlLower, "limitless") {
-		return "limitless-web"
+	col := item.(*games.CollectionItem).Collection
+	if !opts.Dates.Contains(col.ReleaseDate) {
+		return d, outcomeOutOfRange, nil
 	}
-	if strings.Contains(urlLower, "ygoprodeck.com") || strings.Contains(urlLower, "ygoprodeck") {
-		return "ygoprodeck-tournament"
+
+	if tracker != nil {
+		var export bool
+		if opts.ByHash {
+			d.contentHash, err = games.ContentHashOf(decompressed)
+			if err != nil {
+				return d, 0, fmt.Errorf("failed to hash %s: %w", filepath.Base(file), err)
+			}
+			export = tracker.ShouldExportHash(key, d.contentHash)
+		} else {
+			info, err := os.Stat(file)
+			if err != nil {
+				return d, 0, fmt.Errorf("failed to stat %s: %w", filepath.Base(file), err)
+			}
+			updatedAt, version := collectionChangeInfo(col)
+			export = tracker.ShouldExport(ctx, key, info.ModTime(), updatedAt, version)
+		}
+		if !export {
+			return d, outcomeUnchanged, nil
+		}
+	}
+
+	d.record = buildDeckRecord(file, col)
+	if opts.Images != nil {
+		attachImages(d.record.Cards, gameOf(key), opts.Images)
 	}
-	if strings.Contains(urlLower, "scryfall.com") || strings.Contains(urlLower, "scryfall") {
-		return "scryfall"
+	if len(d.record.Cards) == 0 {
+		return d, outcomeEmpty, nil
 	}
+	return d, outcomeWrite, nil
+}
 
-	// Fallback to file path
-	pathLower := strings.ToLower(filePath)
-	if strings.Contains(pathLower, "mtgtop8") {
-		return "mtgtop8"
+// collectionChangeInfo is the collection's own change metadata for
+// ExportTracker.ShouldExport: updated_at (or scraped_at) and version
+func collectionChangeInfo(col *games.Collection) (time.Time, int) {
+	if !col.UpdatedAt.IsZero() {
+		return col.UpdatedAt, col.Version
 	}
-	if strings.Contains(pathLower, "goldfish") {
-		return "goldfish"
+	return col.ScrapedAt, col.Version
+}
+
+// buildDeckRecord flattens a decoded collection file into a DeckRecord
+func buildDeckRecord(file string, col *games.Collection) DeckRecord {
+	scrapedAt := col.ScrapedAt
+	if scrapedAt.IsZero() {
+		scrapedAt = time.Now().UTC()
 	}
-	if strings.Contains(pathLower, "deckbox") {
-		return "deckbox"
+	deck := DeckRecord{
+		DeckID:    filepath.Base(file),
+		URL:       col.URL,
+		Source:    string(col.Source),
+		ScrapedAt: scrapedAt.Format(time.RFC3339),
+		Version:   col.Version,
 	}
-	if strings.Contains(pathLower, "limitless") {
-		return "limitless-web"
+	if !col.UpdatedAt.IsZero() {
+		deck.UpdatedAt = col.UpdatedAt.Format(time.RFC3339)
 	}
-	if strings.Contains(pathLower, "ygoprodeck") {
-		return "ygoprodeck-tournament"
+
+	// Backfill source from URL or file path if missing
+	if deck.Source == "" {
+		deck.Source = string(games.InferSource(deck.URL, file))
 	}
-	if strings.Contains(pathLower, "scryfall") {
-		return "scryfall"
+
+	if meta, ok := col.Type.DeckMetadata(); ok {
+		deck.Archetype = meta.Archetype
+		deck.Format = meta.Format
+		deck.Player = meta.Player
+		deck.Event = meta.Event
+		deck.Placement = meta.Placement
+		deck.EventDate = meta.EventDate
 	}
-	if strings.Contains(pathLower, "pokemon") {
-		return "limitless-web" // Default for Pokemon
+
+	for _, part := range col.Partitions {
+		for _, card := range part.Cards {
+			deck.Cards = append(deck.Cards, CardInDeck{
+				Name:      card.Name,
+				Count:     card.Count,
+				Partition: part.Name,
+			})
+		}
 	}
-	if strings.Contains(pathLower, "yugioh") || strings.Contains(pathLower, "ygo") {
-		return "ygoprodeck-tournament" // Default for Yu-Gi-Oh
+	return deck
+}
+
+// deckRecordMap is the JSONL record for deck, with timestamp aliases for
+// backward compatibility and unknown metadata left out
+func deckRecordMap(deck DeckRecord) map[string]interface{} {
+	deckMap := map[string]interface{}{
+		"deck_id":        deck.DeckID,
+		"archetype":      deck.Archetype,
+		"format":         deck.Format,
+		"url":            deck.URL,
+		"source":         deck.Source,
+		"player":         deck.Player,
+		"event":          deck.Event,
+		"placement":      deck.Placement,
+		"event_date":     deck.EventDate,
+		"scraped_at":     deck.ScrapedAt,
+		"updated_at":     deck.UpdatedAt,
+		"version":        deck.Version,
+		"timestamp":      deck.ScrapedAt, // Alias for backward compatibility
+		"created_at":     deck.ScrapedAt, // Alias for backward compatibility
+		"export_version": "1.0",          // Schema version for validation
+		"cards":          deck.Cards,
 	}
+	games.OmitUnknown(deckMap, games.ExportMetadataKeys...)
+	return deckMap
+}
+
+// storedCard is the part of a stored card shared by the card datasets:
+// scryfall writes images under "image", pokemontcg-data under "images"
+type storedCard struct {
+	Name  string `json:"name"`
+	Image []struct {
+		URL string `json:"url"`
+	} `json:"image"`
+	Images []struct {
+		URL string `json:"url"`
+	} `json:"images"`
+}
+
+// imageKey names a card within its game, since the same name can be a
+// card in more than one game
+type imageKey struct {
+	Game string
+	Name string
+}
 
-	// Final fallback: use directory name
-	dir := filepath.Base(filepath.Dir(filePath))
-	if dir != "" && dir != "." {
-		return dir
+// gameOf is the game a file belongs to: the first segment of its path
+// relative to the data or cards directory
+func gameOf(rel string) string {
+	game, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	return game
+}
+
+// loadImageIndex maps each card, by game and name, to the first image URL
+// of the stored cards (files under a "cards" directory) below dir
+func loadImageIndex(dir string) (map[imageKey]string, error) {
+	files, err := cio.FindCollectionFiles(dir, cio.FindOpts{SkipErrors: true})
+	if err != nil {
+		return nil, err
 	}
+	sep := string(filepath.Separator)
+	index := make(map[imageKey]string)
+	for _, file := range files {
+		if !strings.Contains(sep+file, sep+"cards"+sep) {
+			continue
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			continue
+		}
+		data, err := cio.ReadCollectionFile(file)
+		if err != nil {
+			continue
+		}
+		var card storedCard
+		if err := json.Unmarshal(data, &card); err != nil || card.Name == "" {
+			continue
+		}
+		key := imageKey{Game: gameOf(rel), Name: card.Name}
+		if _, ok := index[key]; ok {
+			continue
+		}
+		for _, img := range append(card.Image, card.Images...) {
+			if img.URL != "" {
+				index[key] = img.URL
+				break
+			}
+		}
+	}
+	return index, nil
+}
 
-	return "unknown"
+// attachImages sets each card's ImageURL from game's cards in index,
+// leaving cards missing from it empty
+func attachImages(cards []CardInDeck, game string, index map[imageKey]string) {
+	for i := range cards {
+		cards[i].ImageURL = index[imageKey{Game: game, Name: cards[i].Name}]
+	}
 }
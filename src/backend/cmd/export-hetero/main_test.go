@@ -0,0 +1,471 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"collections/games"
+	mtg "collections/games/magic/game"
+	"collections/logger"
+
+	"github.com/DataDog/zstd"
+)
+
+func TestAttachImages(t *testing.T) {
+	dir := t.TempDir()
+	for path, data := range map[string]string{
+		"magic/scryfall/cards/Lightning Bolt.json":  `{"name": "Lightning Bolt", "image": [{"url": "https://cards.scryfall.io/png/bolt.png"}]}`,
+		"pokemon/pokemontcg-data/cards/sv1-25.json": `{"name": "Pikachu", "images": [{"url": "https://images.pokemontcg.io/sv1/25_hires.png", "small": "https://images.pokemontcg.io/sv1/25.png"}]}`,
+		"pokemon/pokemontcg-data/cards/sv2-1.json":  `{"name": "Nest Ball", "images": []}`,
+		"magic/mtgtop8/collections/deck.json":       `{"name": "Counterspell", "image": [{"url": "https://example.com/not-a-card.png"}]}`,
+		// The same name in another game must not replace Magic's image
+		"yugioh/ygoprodeck/cards/Lightning Bolt.json": `{"name": "Lightning Bolt", "images": [{"url": "https://images.ygoprodeck.com/bolt.jpg"}]}`,
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	index, err := loadImageIndex(dir)
+	if err != nil {
+		t.Fatalf("loadImageIndex() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		game string
+		want map[string]string
+	}{
+		{"magic", map[string]string{
+			"Lightning Bolt": "https://cards.scryfall.io/png/bolt.png",
+			"Pikachu":        "",
+			"Counterspell":   "",
+		}},
+		{"pokemon", map[string]string{
+			"Pikachu":   "https://images.pokemontcg.io/sv1/25_hires.png",
+			"Nest Ball": "",
+		}},
+		{"yugioh", map[string]string{
+			"Lightning Bolt": "https://images.ygoprodeck.com/bolt.jpg",
+		}},
+	} {
+		var cards []CardInDeck
+		for name := range tt.want {
+			cards = append(cards, CardInDeck{Name: name, Count: 1, Partition: "Main"})
+		}
+		attachImages(cards, tt.game, index)
+		for _, c := range cards {
+			if c.ImageURL != tt.want[c.Name] {
+				t.Errorf("%s %s ImageURL = %q, want %q", tt.game, c.Name, c.ImageURL, tt.want[c.Name])
+			}
+		}
+	}
+}
+
+func writeDecks(t *testing.T, dataDir string, n int) {
+	t.Helper()
+	dir := filepath.Join(dataDir, "magic", "mtgtop8")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		deck := `{"id":"` + string(rune('a'+i)) + `","url":"https://www.mtgtop8.com/event?d=1","source":"mtgtop8",` +
+			`"type":{"type":"Deck","inner":{"format":"Modern"}},"partitions":[{"name":"Main","cards":[{"name":"Lightning Bolt","count":4}]}]}`
+		if err := os.WriteFile(filepath.Join(dir, string(rune('a'+i))+".json"), []byte(deck), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	n := 0
+	for sc := bufio.NewScanner(f); sc.Scan(); {
+		n++
+	}
+	return n
+}
+
+func TestRunExportFullLeavesTrackerUntouched(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	root := t.TempDir()
+	dataDir := filepath.Join(root, "games")
+	writeDecks(t, dataDir, 3)
+	trackerFile := filepath.Join(root, "state", ".export_tracker.json.zst")
+
+	// A tracked run records every deck, so a second one exports nothing
+	opts := exportOptions{DataDir: dataDir, OutputFile: filepath.Join(root, "first.jsonl"), Incremental: true, TrackerPrefix: "state"}
+	if err := runExport(ctx, log, opts); err != nil {
+		t.Fatal(err)
+	}
+	if got := countLines(t, opts.OutputFile); got != 3 {
+		t.Fatalf("tracked export wrote %d decks, want 3", got)
+	}
+	before, err := os.ReadFile(trackerFile)
+	if err != nil {
+		t.Fatalf("tracker not saved: %v", err)
+	}
+	info, err := os.Stat(trackerFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A full export writes everything and neither reads nor writes state
+	opts.OutputFile = filepath.Join(root, "full.jsonl")
+	opts.Incremental = false
+	writeDecks(t, dataDir, 4)
+	if err := runExport(ctx, log, opts); err != nil {
+		t.Fatal(err)
+	}
+	if got := countLines(t, opts.OutputFile); got != 4 {
+		t.Errorf("full export wrote %d decks, want all 4", got)
+	}
+	after, err := os.ReadFile(trackerFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	afterInfo, err := os.Stat(trackerFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) || !afterInfo.ModTime().Equal(info.ModTime()) {
+		t.Error("full export modified the tracker blob")
+	}
+
+	// With no tracker state at all, none is created
+	opts.TrackerPrefix = "other"
+	if err := runExport(ctx, log, opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "other")); !os.IsNotExist(err) {
+		t.Errorf("full export created tracker state: %v", err)
+	}
+}
+
+func readRecords(t *testing.T, path string) map[string]map[string]any {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records := make(map[string]map[string]any)
+	for sc := bufio.NewScanner(f); sc.Scan(); {
+		var rec map[string]any
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatal(err)
+		}
+		records[rec["deck_id"].(string)] = rec
+	}
+	return records
+}
+
+// readDeckIDs returns the deck_id of each record in the export, in order
+func readDeckIDs(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var ids []string
+	for sc := bufio.NewScanner(f); sc.Scan(); {
+		var rec map[string]any
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, rec["deck_id"].(string))
+	}
+	return ids
+}
+
+func TestRunExportIncrementalMatchesFull(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	root := t.TempDir()
+	dataDir := filepath.Join(root, "games")
+	writeDecks(t, dataDir, 3)
+
+	full := exportOptions{DataDir: dataDir, OutputFile: filepath.Join(root, "full.jsonl")}
+	if err := runExport(ctx, log, full); err != nil {
+		t.Fatal(err)
+	}
+	incr := exportOptions{DataDir: dataDir, OutputFile: filepath.Join(root, "incr.jsonl"), Incremental: true, TrackerPrefix: "state"}
+	if err := runExport(ctx, log, incr); err != nil {
+		t.Fatal(err)
+	}
+
+	want := readRecords(t, full.OutputFile)
+	got := readRecords(t, incr.OutputFile)
+	if len(want) != 3 {
+		t.Fatalf("full export wrote %d decks, want 3", len(want))
+	}
+	for id, rec := range want {
+		// scraped_at and its aliases default to the export time
+		for _, key := range []string{"scraped_at", "timestamp", "created_at"} {
+			delete(rec, key)
+			delete(got[id], key)
+		}
+		if !reflect.DeepEqual(got[id], rec) {
+			t.Errorf("incremental record %s = %v, want %v", id, got[id], rec)
+		}
+	}
+
+	// A second full export overwrites rather than appends
+	if err := runExport(ctx, log, full); err != nil {
+		t.Fatal(err)
+	}
+	if got := countLines(t, full.OutputFile); got != 3 {
+		t.Errorf("second full export left %d lines, want 3", got)
+	}
+}
+
+func TestRunExportLimitPerSource(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	root := t.TempDir()
+	dataDir := filepath.Join(root, "games")
+	writeDecks(t, dataDir, 5) // mtgtop8
+	decks := map[string]string{
+		"magic/goldfish/g1.json":        `{"id":"g1","url":"https://www.mtggoldfish.com/deck/1","source":"goldfish"`,
+		"magic/goldfish/g2.json":        `{"id":"g2","url":"https://www.mtggoldfish.com/deck/2","source":"goldfish"`,
+		"magic/goldfish/g3.json":        `{"id":"g3","url":"https://www.mtggoldfish.com/deck/3"`, // Source inferred from the URL
+		"pokemon/limitless-web/p1.json": `{"id":"p1","url":"https://limitlesstcg.com/decks/list/1","source":"limitless-web"`,
+	}
+	for path, deck := range decks {
+		path = filepath.Join(dataDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		deck += `,"type":{"type":"Deck","inner":{"format":"Standard"}},"partitions":[{"name":"Main","cards":[{"name":"Card","count":4}]}]}`
+		if err := os.WriteFile(path, []byte(deck), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := exportOptions{DataDir: dataDir, OutputFile: filepath.Join(root, "capped.jsonl"), PerSource: 2}
+	if err := runExport(ctx, log, opts); err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]int)
+	for _, rec := range readRecords(t, opts.OutputFile) {
+		counts[rec["source"].(string)]++
+	}
+	want := map[string]int{"mtgtop8": 2, "goldfish": 2, "limitless-web": 1}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("decks per source = %v, want %v", counts, want)
+	}
+
+	// The cap keeps the first decks in walk order, whichever worker
+	// finishes first
+	var kept []string
+	for _, workers := range []int{1, 8} {
+		opts := exportOptions{DataDir: dataDir, OutputFile: filepath.Join(root, fmt.Sprintf("capped-%d.jsonl", workers)), PerSource: 2, Workers: workers}
+		if err := runExport(ctx, log, opts); err != nil {
+			t.Fatal(err)
+		}
+		kept = append(kept, strings.Join(readDeckIDs(t, opts.OutputFile), ","))
+	}
+	if want := "g1.json,g2.json,a.json,b.json,p1.json"; kept[0] != want || kept[1] != want {
+		t.Errorf("capped deck_ids = %q with 1 worker and %q with 8, want %q", kept[0], kept[1], want)
+	}
+
+	// Capped decks aren't tracked, so raising the cap exports them next time
+	opts = exportOptions{DataDir: dataDir, OutputFile: filepath.Join(root, "incr.jsonl"), Incremental: true, TrackerPrefix: "state", ByHash: true, PerSource: 2}
+	if err := runExport(ctx, log, opts); err != nil {
+		t.Fatal(err)
+	}
+	opts.PerSource = 0
+	if err := runExport(ctx, log, opts); err != nil {
+		t.Fatal(err)
+	}
+	if got := countLines(t, opts.OutputFile); got != 9 {
+		t.Errorf("incremental exports wrote %d decks in total, want all 9", got)
+	}
+}
+
+func TestRunExportWalkError(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	root := t.TempDir()
+	opts := exportOptions{DataDir: filepath.Join(root, "missing"), OutputFile: filepath.Join(root, "out.jsonl")}
+	if err := runExport(ctx, log, opts); err == nil {
+		t.Error("runExport() of a missing data dir succeeded, want error")
+	}
+}
+
+func TestRunExportDateRange(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	root := t.TempDir()
+	dataDir := filepath.Join(root, "games")
+	dir := filepath.Join(dataDir, "magic", "mtgtop8")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	decks := map[string]string{
+		"jan": `"release_date":"2024-01-15T00:00:00Z",`,
+		"feb": `"release_date":"2024-02-29T18:30:00Z",`,
+		"mar": `"release_date":"2024-03-01T00:00:00Z",`,
+		"old": `"release_date":"0001-01-01T00:00:00Z",`, // Zero time as encoded by encoding/json
+		"nil": ``,
+	}
+	for id, date := range decks {
+		deck := `{"id":"` + id + `","url":"https://www.mtgtop8.com/event?d=1","source":"mtgtop8",` + date +
+			`"type":{"type":"Deck","inner":{"format":"Modern"}},"partitions":[{"name":"Main","cards":[{"name":"Lightning Bolt","count":4}]}]}`
+		if err := os.WriteFile(filepath.Join(dir, id+".json"), []byte(deck), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dates, err := games.ParseDateRange("2024-01-01", "2024-02-29")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := exportOptions{DataDir: dataDir, OutputFile: filepath.Join(root, "dated.jsonl"), Dates: dates}
+	if err := runExport(ctx, log, opts); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, rec := range readRecords(t, opts.OutputFile) {
+		got = append(got, rec["deck_id"].(string))
+	}
+	sort.Strings(got)
+	if want := []string{"feb.json", "jan.json"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("dated export = %v, want %v", got, want)
+	}
+}
+
+// decodeCollection decodes a stored collection as runExport does
+func decodeCollection(t *testing.T, file, data string) *games.Collection {
+	t.Helper()
+	item, err := mtg.DeserializeAsAnyCollection(file, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return item.(*games.CollectionItem).Collection
+}
+
+func TestBuildDeckRecordPlacement(t *testing.T) {
+	for placement, want := range map[string]int{`3`: 3, `"Top 8"`: 8, `"Winner"`: 1, `"Participant"`: 0} {
+		col := decodeCollection(t, "data/magic/mtgtop8/1.json", `{"type":{"type":"Deck","inner":{"placement":`+placement+`}}}`)
+		got := buildDeckRecord("data/magic/mtgtop8/1.json", col).Placement
+		if (want == 0) != (got == nil) || (got != nil && *got != want) {
+			t.Errorf("placement %v: Placement = %v, want %d", placement, got, want)
+		}
+	}
+}
+
+func TestBuildDeckRecordInfersMissingSource(t *testing.T) {
+	const deck = `"url":"https://www.mtggoldfish.com/deck/123","type":{"type":"Deck","inner":{"format":"Modern"}}`
+	col := decodeCollection(t, "data/magic/unsorted/123.json", `{`+deck+`}`)
+	if got := buildDeckRecord("data/magic/unsorted/123.json", col).Source; got != "goldfish" {
+		t.Errorf("Source = %q, want goldfish from the URL", got)
+	}
+
+	col = decodeCollection(t, "data/magic/unsorted/123.json", `{"source":"mtgtop8",`+deck+`}`)
+	if got := buildDeckRecord("data/magic/unsorted/123.json", col).Source; got != "mtgtop8" {
+		t.Errorf("Source = %q, want the stored mtgtop8", got)
+	}
+}
+
+func TestRunExportSort(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	root := t.TempDir()
+	dataDir := filepath.Join(root, "games")
+	writeDecks(t, dataDir, 8)
+
+	var outputs []string
+	for _, workers := range []int{1, 4} {
+		opts := exportOptions{DataDir: dataDir, OutputFile: filepath.Join(root, fmt.Sprintf("out-%d.jsonl", workers)), Workers: workers, Sort: true}
+		if err := runExport(ctx, log, opts); err != nil {
+			t.Fatal(err)
+		}
+		ids := readDeckIDs(t, opts.OutputFile)
+		if !sort.StringsAreSorted(ids) || len(ids) != 8 {
+			t.Errorf("%d workers: deck_ids = %v, want all 8 sorted", workers, ids)
+		}
+		outputs = append(outputs, strings.Join(ids, ","))
+	}
+	if outputs[0] != outputs[1] {
+		t.Errorf("sorted output differs by workers: %s vs %s", outputs[0], outputs[1])
+	}
+}
+
+// writeBenchDecks writes n compressed 60-card decks under dataDir
+func writeBenchDecks(b *testing.B, dataDir string, n int) {
+	b.Helper()
+	dir := filepath.Join(dataDir, "magic", "mtgtop8")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		var cards []string
+		for j := 0; j < 60; j++ {
+			cards = append(cards, fmt.Sprintf(`{"name":"Card %d","count":%d}`, (i+j*7)%500, 1+j%4))
+		}
+		deck := fmt.Sprintf(`{"id":"%d","url":"https://www.mtgtop8.com/event?e=1&d=%d","source":"mtgtop8","release_date":"2024-01-01T00:00:00Z",`+
+			`"type":{"type":"Deck","inner":{"name":"Burn","format":"Modern","archetype":"Burn","player":"Alice","placement":1}},`+
+			`"partitions":[{"name":"Main","cards":[%s]}]}`, i, i, strings.Join(cards, ","))
+		data, err := zstd.Compress(nil, []byte(deck))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%05d.json.zst", i)), data, 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRunExport(b *testing.B) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	root := b.TempDir()
+	dataDir := filepath.Join(root, "games")
+	writeBenchDecks(b, dataDir, 3000)
+
+	for _, workers := range []int{1, 4, runtime.NumCPU()} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				opts := exportOptions{DataDir: dataDir, OutputFile: filepath.Join(root, "out.jsonl"), Workers: workers}
+				if err := runExport(ctx, log, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -3,16 +3,21 @@ package main
 import (
 	"context"
 	"encoding/csv"
-	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 
+	"collections/cio"
+	"collections/games"
+	_ "collections/games/digimon/game" // Register collection types
+	mtg "collections/games/magic/game"
+	_ "collections/games/onepiece/game"  // Register collection types
+	_ "collections/games/pokemon/game"   // Register collection types
+	_ "collections/games/riftbound/game" // Register collection types
+	_ "collections/games/yugioh/game"    // Register collection types
 	"collections/logger"
-
-	"github.com/DataDog/zstd"
 )
 
 // MultiGamePair represents a card pair with game context
@@ -21,19 +26,45 @@ type MultiGamePair struct {
 	Card2  string
 	Game1  string // MTG, YGO, PKM
 	Game2  string
-	Count  int
+	Count  int // Decks containing the pair
+	Weight int // Copy-level pairings summed over decks (multiset count)
 	DeckID string
 	Source string
 }
 
+// pairKey identifies a card pair within a game. A struct key, unlike a
+// delimiter-joined string, cannot collide when card names contain "|".
+type pairKey struct {
+	card1 string
+	card2 string
+	game1 string
+	game2 string
+}
+
+func makePairKey(card1, card2, game1, game2 string) pairKey {
+	if card1 > card2 {
+		card1, card2 = card2, card1
+	}
+	return pairKey{card1: card1, card2: card2, game1: game1, game2: game2}
+}
+
+var (
+	includeSelfPairs = flag.Bool("include-self-pairs", false, "Emit a card paired with itself when a deck has more than one copy")
+	seenPairs        = flag.Int("seen-pairs", 0, "Max distinct pairs held in memory before spilling sorted runs to disk (0 = unlimited)")
+	frontFace        = flag.Bool("front-face", false, "Collapse Magic split and double-faced cards to their front face, for sources that list only the front")
+)
+
 func main() {
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: export-multi-game-graph <data-dir> <output.csv>")
+	flag.Parse()
+	args := flag.Args()
+	if len(args) < 2 {
+		fmt.Println("Usage: export-multi-game-graph [flags] <data-dir> <output.csv>")
+		flag.PrintDefaults()
 		os.Exit(1)
 	}
 
-	dataDir := os.Args[1]
-	outputFile := os.Args[2]
+	dataDir := args[0]
+	outputFile := args[1]
 
 	ctx := context.Background()
 	log := logger.NewLogger(ctx)
@@ -43,16 +74,17 @@ func main() {
 	fmt.Println()
 
 	// Find all collection files
-	var files []string
-	filepath.Walk(dataDir, func(path string, info os.FileInfo, err error) error {
-		if err == nil && !info.IsDir() && filepath.Ext(path) == ".zst" {
-			files = append(files, path)
-		}
-		return nil
-	})
+	files, _ := cio.FindCollectionFiles(dataDir, cio.FindOpts{SkipErrors: true})
 
 	// Build co-occurrence map with game context
-	pairCounts := make(map[string]*MultiGamePair) // key: "card1|card2|game1|game2"
+	spillDir, err := os.MkdirTemp("", "multi-game-pairs-")
+	if err != nil {
+		log.Errorf(ctx, "Failed to create spill directory: %v", err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(spillDir)
+	store := newPairStore(*seenPairs, spillDir)
+	defer store.close()
 
 	totalDecks := 0
 	totalCards := 0
@@ -61,7 +93,7 @@ func main() {
 
 	fmt.Printf("Found %d collection files\n", len(files))
 	if len(files) == 0 {
-		fmt.Println("⚠️  No .zst files found in data directory")
+		fmt.Println("⚠️  No collection files found in data directory")
 		return
 	}
 
@@ -97,107 +129,29 @@ func main() {
 		gameStats[game]++
 
 		// Extract all cards from all partitions
-		var allCards []string
-		for _, part := range col.Partitions {
-			for _, card := range part.Cards {
-				for i := 0; i < card.Count; i++ {
-					allCards = append(allCards, card.Name)
-				}
-			}
+		cards := deckCards(col, game, *frontFace)
+		copies := 0
+		for _, c := range cards {
+			copies += c.count
 		}
 
-		if len(allCards) < 2 {
+		if copies < 2 {
 			continue
 		}
 
 		totalDecks++
-		totalCards += len(allCards)
+		totalCards += copies
 
 		// Create pairs within this deck
-		seenPairs := make(map[string]bool)
-		for i := 0; i < len(allCards); i++ {
-			for j := i + 1; j < len(allCards); j++ {
-				card1, card2 := allCards[i], allCards[j]
-				if card1 == card2 {
-					continue
-				}
-
-				// Normalize pair (alphabetical)
-				if card1 > card2 {
-					card1, card2 = card2, card1
-				}
-
-				// Key includes game context
-				key := fmt.Sprintf("%s|%s|%s|%s", card1, card2, game, game)
-				if seenPairs[key] {
-					continue
-				}
-				seenPairs[key] = true
-
-				// Update count
-				if pair, exists := pairCounts[key]; exists {
-					pair.Count++
-				} else {
-					// Get source from collection, fallback to URL or file path
-					source := col.Source
-					if source == "" {
-						// Try URL first
-						urlLower := strings.ToLower(col.URL)
-						if strings.Contains(urlLower, "deckbox") {
-							source = "deckbox"
-						} else if strings.Contains(urlLower, "scryfall") {
-							source = "scryfall"
-						} else if strings.Contains(urlLower, "mtgtop8") {
-							source = "mtgtop8"
-						} else if strings.Contains(urlLower, "goldfish") {
-							source = "goldfish"
-						} else {
-							// Fallback to file path
-							if strings.Contains(file, "deckbox") {
-								source = "deckbox"
-							} else if strings.Contains(file, "scryfall") {
-								source = "scryfall"
-							} else if strings.Contains(file, "mtgtop8") {
-								source = "mtgtop8"
-							} else {
-								source = filepath.Base(filepath.Dir(file))
-							}
-						}
-					}
-
-					pairCounts[key] = &MultiGamePair{
-						Card1:  card1,
-						Card2:  card2,
-						Game1:  game,
-						Game2:  game,
-						Count:  1,
-						DeckID: filepath.Base(file),
-						Source: source,
-					}
-					totalEdges++
-				}
+		source := deckSource(col, file)
+		for _, dp := range deckPairs(cards, game, *includeSelfPairs) {
+			if err := store.add(dp, filepath.Base(file), source); err != nil {
+				log.Errorf(ctx, "Failed to record pairs: %v", err)
+				os.Exit(1)
 			}
 		}
 	}
 
-	fmt.Printf("\n📊 Statistics:\n")
-	fmt.Printf("   Files found: %d\n", len(files))
-	fmt.Printf("   Files processed: %d\n", processed)
-	fmt.Printf("   Files skipped: %d\n", skipped)
-	if errorCount > maxErrorsToLog {
-		fmt.Printf("   Errors (showing first %d): %d total\n", maxErrorsToLog, errorCount)
-	} else if errorCount > 0 {
-		fmt.Printf("   Errors: %d\n", errorCount)
-	}
-	fmt.Printf("   Total decks: %d\n", totalDecks)
-	fmt.Printf("   Total cards: %d\n", totalCards)
-	fmt.Printf("   Total edges: %d\n", totalEdges)
-	fmt.Printf("\n   Game distribution:\n")
-	for game, count := range gameStats {
-		fmt.Printf("     %s: %d decks\n", game, count)
-	}
-	fmt.Println()
-
 	// Write CSV
 	out, err := os.Create(outputFile)
 	if err != nil {
@@ -210,26 +164,12 @@ func main() {
 	defer w.Flush()
 
 	// Header
-	w.Write([]string{"NAME_1", "NAME_2", "GAME_1", "GAME_2", "COUNT", "DECK_ID", "SOURCE"})
+	w.Write([]string{"NAME_1", "NAME_2", "GAME_1", "GAME_2", "COUNT", "DECK_ID", "SOURCE", "COUNT_MULTISET"})
 
-	// Sort pairs for deterministic output
-	var sortedPairs []*MultiGamePair
-	for _, pair := range pairCounts {
-		sortedPairs = append(sortedPairs, pair)
-	}
-	sort.Slice(sortedPairs, func(i, j int) bool {
-		if sortedPairs[i].Card1 != sortedPairs[j].Card1 {
-			return sortedPairs[i].Card1 < sortedPairs[j].Card1
-		}
-		if sortedPairs[i].Card2 != sortedPairs[j].Card2 {
-			return sortedPairs[i].Card2 < sortedPairs[j].Card2
-		}
-		return sortedPairs[i].Game1 < sortedPairs[j].Game1
-	})
-
-	// Write data
-	for _, pair := range sortedPairs {
-		w.Write([]string{
+	// Write data in sorted order for deterministic output
+	err = store.each(func(pair *MultiGamePair) error {
+		totalEdges++
+		return w.Write([]string{
 			pair.Card1,
 			pair.Card2,
 			pair.Game1,
@@ -237,59 +177,126 @@ func main() {
 			fmt.Sprintf("%d", pair.Count),
 			pair.DeckID,
 			pair.Source,
+			fmt.Sprintf("%d", pair.Weight),
 		})
+	})
+	if err != nil {
+		log.Errorf(ctx, "Failed to write pairs: %v", err)
+		os.Exit(1)
+	}
+	w.Flush()
+
+	fmt.Printf("\n📊 Statistics:\n")
+	fmt.Printf("   Files found: %d\n", len(files))
+	fmt.Printf("   Files processed: %d\n", processed)
+	fmt.Printf("   Files skipped: %d\n", skipped)
+	if errorCount > maxErrorsToLog {
+		fmt.Printf("   Errors (showing first %d): %d total\n", maxErrorsToLog, errorCount)
+	} else if errorCount > 0 {
+		fmt.Printf("   Errors: %d\n", errorCount)
+	}
+	fmt.Printf("   Total decks: %d\n", totalDecks)
+	fmt.Printf("   Total cards: %d\n", totalCards)
+	fmt.Printf("   Total edges: %d\n", totalEdges)
+	fmt.Printf("\n   Game distribution:\n")
+	for game, count := range gameStats {
+		fmt.Printf("     %s: %d decks\n", game, count)
 	}
+	fmt.Println()
 
 	fmt.Printf("✅ Successfully exported multi-game graph to %s\n", outputFile)
 }
 
-// SimpleCollection is a minimal collection structure for export
-type SimpleCollection struct {
-	ID         string      `json:"id"`
-	URL        string      `json:"url"`
-	Type       TypeInfo    `json:"type"`
-	Partitions []Partition `json:"partitions"`
-	Source     string      `json:"source,omitempty"`
+// deckCard is a distinct card in a deck with its total copy count
+type deckCard struct {
+	name  string
+	count int
 }
 
-type TypeInfo struct {
-	Type  string          `json:"type"`
-	Inner json.RawMessage `json:"inner"`
+// deckCards merges a collection's partitions into distinct cards with
+// counts. Names are normalized so spellings from different sources
+// reconcile to one node; Magic names also get their face separator
+// canonicalized, and with frontFace only their first face kept.
+func deckCards(col *games.Collection, game string, frontFace bool) []deckCard {
+	normalize := games.NormalizeCardName
+	if game == "MTG" {
+		normalize = func(name string) string {
+			return games.NormalizeCardNameFull(name, games.CardNameOptions{FrontFace: frontFace})
+		}
+	}
+	var cards []deckCard
+	index := make(map[string]int)
+	for _, part := range col.Partitions {
+		for _, card := range part.Cards {
+			name := normalize(card.Name)
+			if name == "" || card.Count <= 0 {
+				continue
+			}
+			if i, ok := index[name]; ok {
+				cards[i].count += card.Count
+				continue
+			}
+			index[name] = len(cards)
+			cards = append(cards, deckCard{name: name, count: card.Count})
+		}
+	}
+	return cards
 }
 
-type Partition struct {
-	Name  string     `json:"name"`
-	Cards []CardDesc `json:"cards"`
+// deckPair is a pair occurring in one deck. Weight is the number of
+// copy-level pairings: count_i * count_j for distinct cards, and
+// count*(count-1)/2 for a self-pair.
+type deckPair struct {
+	key    pairKey
+	weight int
 }
 
-type CardDesc struct {
-	Name  string `json:"name"`
-	Count int    `json:"count"`
+// deckPairs returns each distinct pair of different cards in a deck once.
+// With includeSelf, a card with more than one copy also pairs with itself.
+// Pairs are within a single game, so game1 and game2 are both game.
+// Work is O(unique²) regardless of copy counts.
+func deckPairs(cards []deckCard, game string, includeSelf bool) []deckPair {
+	var pairs []deckPair
+	for i, a := range cards {
+		if includeSelf && a.count > 1 {
+			pairs = append(pairs, deckPair{
+				key:    makePairKey(a.name, a.name, game, game),
+				weight: a.count * (a.count - 1) / 2,
+			})
+		}
+		for _, b := range cards[i+1:] {
+			pairs = append(pairs, deckPair{
+				key:    makePairKey(a.name, b.name, game, game),
+				weight: a.count * b.count,
+			})
+		}
+	}
+	return pairs
 }
 
-func loadCollection(path string) (*SimpleCollection, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
+// deckSource returns the collection's source, falling back to hints in
+// its URL or file path
+func deckSource(col *games.Collection, file string) string {
+	if col.Source != "" {
+		return string(col.Source)
 	}
+	return string(games.InferSource(col.URL, file))
+}
 
-	// Decompress
-	if filepath.Ext(path) == ".zst" {
-		data, err = zstd.Decompress(nil, data)
-		if err != nil {
-			return nil, err
-		}
+// loadCollection reads a collection file of any game
+func loadCollection(path string) (*games.Collection, error) {
+	data, err := cio.ReadCollectionFile(path)
+	if err != nil {
+		return nil, err
 	}
-
-	var col SimpleCollection
-	if err := json.Unmarshal(data, &col); err != nil {
+	item, err := mtg.DeserializeAsAnyCollection(path, data)
+	if err != nil {
 		return nil, err
 	}
-
-	return &col, nil
+	return item.(*games.CollectionItem).Collection, nil
 }
 
-func inferGameFromCollection(col *SimpleCollection, filePath string) string {
+func inferGameFromCollection(col *games.Collection, filePath string) string {
 	// Infer from file path first (most reliable)
 	pathLower := strings.ToLower(filePath)
 	if strings.Contains(pathLower, "/yugioh/") || strings.Contains(pathLower, "/ygo/") {
@@ -315,25 +322,12 @@ func inferGameFromCollection(col *SimpleCollection, filePath string) string {
 	typeStr := col.Type.Type
 
 	// Infer from source/URL (check both Source field and URL if available)
-	source := strings.ToLower(col.Source)
+	source := strings.ToLower(string(col.Source))
 	urlLower := strings.ToLower(col.URL)
 
 	// If source is empty, try to infer from URL
 	if source == "" {
-		if strings.Contains(urlLower, "deckbox") {
-			source = "deckbox"
-		} else if strings.Contains(urlLower, "scryfall") {
-			source = "scryfall"
-		} else if strings.Contains(urlLower, "mtgtop8") {
-			source = "mtgtop8"
-		} else if strings.Contains(urlLower, "goldfish") {
-			source = "goldfish"
-		} else if strings.Contains(urlLower, "limitlesstcg") || strings.Contains(urlLower, "limitless") {
-			// Limitless supports multiple games - need to check game parameter or collection type
-			source = "limitless"
-		} else if strings.Contains(urlLower, "riftdecks") || strings.Contains(urlLower, "riftbound") {
-			source = "riftdecks"
-		}
+		source = string(games.InferSource(col.URL, ""))
 	}
 
 	// Digimon types
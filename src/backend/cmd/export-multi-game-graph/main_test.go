@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"collections/games"
+)
+
+func cardsOf(names ...string) []deckCard {
+	cards := make([]deckCard, len(names))
+	for i, name := range names {
+		cards[i] = deckCard{name: name, count: 1}
+	}
+	return cards
+}
+
+func TestDeckPairsSeparatorInCardName(t *testing.T) {
+	// Joined with "|", both decks would produce "A|B|C|MTG|MTG"
+	deck1 := deckPairs(cardsOf("A|B", "C"), "MTG", false)
+	deck2 := deckPairs(cardsOf("A", "B|C"), "MTG", false)
+
+	counts := make(map[pairKey]int)
+	for _, dp := range append(deck1, deck2...) {
+		counts[dp.key]++
+	}
+	if len(counts) != 2 {
+		t.Fatalf("got %d distinct pairs, want 2: %v", len(counts), counts)
+	}
+	for key, n := range counts {
+		if n != 1 {
+			t.Errorf("pair %+v counted %d times, want 1", key, n)
+		}
+	}
+}
+
+func TestDeckPairs(t *testing.T) {
+	col := &games.Collection{Partitions: []games.Partition{
+		{Name: "Main", Cards: []games.CardDesc{{Name: "Sol Ring", Count: 1}, {Name: "Island", Count: 2}}},
+		{Name: "Sideboard", Cards: []games.CardDesc{{Name: "Sol Ring", Count: 1}}},
+	}}
+	cards := deckCards(col, "MTG", false)
+
+	pairs := deckPairs(cards, "MTG", false)
+	if len(pairs) != 1 {
+		t.Fatalf("deckPairs() = %v, want 1 pair", pairs)
+	}
+	want := pairKey{card1: "Island", card2: "Sol Ring", game1: "MTG", game2: "MTG"}
+	if pairs[0].key != want {
+		t.Errorf("deckPairs() = %+v, want %+v", pairs[0].key, want)
+	}
+	if pairs[0].weight != 4 {
+		t.Errorf("weight = %d, want 4", pairs[0].weight)
+	}
+}
+
+func TestDeckPairsIncludeSelf(t *testing.T) {
+	cards := []deckCard{{name: "Island", count: 4}, {name: "Sol Ring", count: 1}}
+
+	got := make(map[pairKey]bool)
+	for _, dp := range deckPairs(cards, "MTG", true) {
+		got[dp.key] = true
+	}
+	want := []pairKey{
+		{card1: "Island", card2: "Island", game1: "MTG", game2: "MTG"},
+		{card1: "Island", card2: "Sol Ring", game1: "MTG", game2: "MTG"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("deckPairs() = %v, want %v", got, want)
+	}
+	for _, key := range want {
+		if !got[key] {
+			t.Errorf("deckPairs() missing %+v", key)
+		}
+	}
+
+	// Single-copy cards never self-pair
+	for _, dp := range deckPairs(cardsOf("Sol Ring"), "MTG", true) {
+		t.Errorf("unexpected pair %+v for single copy", dp.key)
+	}
+}
+
+func TestDeckCardsNormalizesNames(t *testing.T) {
+	deck1 := &games.Collection{Partitions: []games.Partition{{
+		Name:  "Main",
+		Cards: []games.CardDesc{{Name: "Fire &amp; Ice", Count: 1}, {Name: "Island", Count: 1}},
+	}}}
+	deck2 := &games.Collection{Partitions: []games.Partition{{
+		Name:  "Main",
+		Cards: []games.CardDesc{{Name: "  Fire  & Ice ", Count: 1}, {Name: "Island", Count: 1}},
+	}}}
+
+	counts := make(map[pairKey]int)
+	for _, col := range []*games.Collection{deck1, deck2} {
+		for _, dp := range deckPairs(deckCards(col, "MTG", false), "MTG", false) {
+			counts[dp.key]++
+		}
+	}
+	want := pairKey{card1: "Fire & Ice", card2: "Island", game1: "MTG", game2: "MTG"}
+	if len(counts) != 1 || counts[want] != 2 {
+		t.Errorf("pairs = %v, want %+v counted twice", counts, want)
+	}
+}
+
+func TestDeckCardsFaces(t *testing.T) {
+	col := &games.Collection{Partitions: []games.Partition{{
+		Name: "Main",
+		Cards: []games.CardDesc{
+			{Name: "Delver of Secrets // Insectile Aberration", Count: 2},
+			{Name: "Delver of Secrets", Count: 2},
+			{Name: "Fire / Ice", Count: 1},
+			{Name: "Fire//Ice", Count: 1},
+		},
+	}}}
+	tests := []struct {
+		game      string
+		frontFace bool
+		want      []deckCard
+	}{
+		{"MTG", false, []deckCard{{"Delver of Secrets // Insectile Aberration", 2}, {"Delver of Secrets", 2}, {"Fire // Ice", 2}}},
+		{"MTG", true, []deckCard{{"Delver of Secrets", 4}, {"Fire", 2}}},
+		// Lone slashes are only face separators in Magic
+		{"YGO", true, []deckCard{{"Delver of Secrets // Insectile Aberration", 2}, {"Delver of Secrets", 2}, {"Fire / Ice", 1}, {"Fire // Ice", 1}}},
+	}
+	for _, tt := range tests {
+		if got := deckCards(col, tt.game, tt.frontFace); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("deckCards(%s, frontFace=%v) = %v, want %v", tt.game, tt.frontFace, got, tt.want)
+		}
+	}
+}
+
+// expandedDeckPairs is the previous approach: one slice entry per copy and
+// a nested loop over every pair of entries, O((Σcount)²)
+func expandedDeckPairs(cards []deckCard, game string, includeSelf bool) map[pairKey]int {
+	var all []string
+	for _, c := range cards {
+		for i := 0; i < c.count; i++ {
+			all = append(all, c.name)
+		}
+	}
+	weights := make(map[pairKey]int)
+	for i := 0; i < len(all); i++ {
+		for j := i + 1; j < len(all); j++ {
+			if all[i] == all[j] && !includeSelf {
+				continue
+			}
+			weights[makePairKey(all[i], all[j], game, game)]++
+		}
+	}
+	return weights
+}
+
+func TestDeckPairsMatchExpanded(t *testing.T) {
+	cards := []deckCard{
+		{name: "Island", count: 17},
+		{name: "Counterspell", count: 4},
+		{name: "Brainstorm", count: 3},
+		{name: "Sol Ring", count: 1},
+	}
+	for _, includeSelf := range []bool{false, true} {
+		want := expandedDeckPairs(cards, "MTG", includeSelf)
+		got := make(map[pairKey]int)
+		for _, dp := range deckPairs(cards, "MTG", includeSelf) {
+			got[dp.key] += dp.weight
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("includeSelf=%v: weights = %v, want %v", includeSelf, got, want)
+		}
+	}
+}
+
+// benchDeck is a 100-card Commander-sized deck with basic lands, the case
+// where expanding copies hurts most
+func benchDeck() []deckCard {
+	cards := []deckCard{{name: "Island", count: 20}, {name: "Swamp", count: 19}}
+	for i := 0; i < 61; i++ {
+		cards = append(cards, deckCard{name: fmt.Sprintf("Card %02d", i), count: 1})
+	}
+	return cards
+}
+
+func BenchmarkDeckPairs(b *testing.B) {
+	cards := benchDeck()
+	b.Run("unique", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			deckPairs(cards, "MTG", false)
+		}
+	})
+	b.Run("expanded", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			expandedDeckPairs(cards, "MTG", false)
+		}
+	})
+}
+
+func TestLoadCollection(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"magic": `{"id":"m","type":{"type":"Deck","inner":{"format":"Modern"}},"source":"mtgtop8",` +
+			`"partitions":[{"name":"Main","cards":[{"name":"Island","count":4}]}]}`,
+		"yugioh": `{"id":"y","type":{"type":"YGODeck","inner":{"format":"TCG"}},` +
+			`"partitions":[{"name":"Main Deck","cards":[{"name":"Ash Blossom & Joyous Spring","count":3}]}]}`,
+	}
+	for name, data := range files {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, "deck.json"), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		dir, game, card string
+	}{
+		{"magic", "MTG", "Island"},
+		{"yugioh", "YGO", "Ash Blossom & Joyous Spring"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			file := filepath.Join(dir, tt.dir, "deck.json")
+			col, err := loadCollection(file)
+			if err != nil {
+				t.Fatalf("loadCollection() error = %v", err)
+			}
+			if got := inferGameFromCollection(col, file); got != tt.game {
+				t.Errorf("game = %q, want %q", got, tt.game)
+			}
+			if cards := deckCards(col, tt.game, false); len(cards) != 1 || cards[0].name != tt.card {
+				t.Errorf("deckCards() = %v, want %s", cards, tt.card)
+			}
+		})
+	}
+	if _, err := loadCollection(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("loadCollection() of a missing file error = nil, want error")
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// pairStore accumulates pair counts with a bound on how many distinct pairs
+// are held in memory. When maxPairs is exceeded, the in-memory pairs are
+// written to disk as a sorted run and cleared; each merges the runs back
+// together in output order. maxPairs <= 0 keeps everything in memory.
+type pairStore struct {
+	maxPairs int
+	dir      string
+	pairs    map[pairKey]*MultiGamePair
+	spills   []string
+}
+
+func newPairStore(maxPairs int, dir string) *pairStore {
+	return &pairStore{
+		maxPairs: maxPairs,
+		dir:      dir,
+		pairs:    make(map[pairKey]*MultiGamePair),
+	}
+}
+
+// add records one deck's occurrence of a pair. deckID and source are kept
+// from the first deck the pair is seen in.
+func (s *pairStore) add(dp deckPair, deckID, source string) error {
+	if pair, exists := s.pairs[dp.key]; exists {
+		pair.Count++
+		pair.Weight += dp.weight
+		return nil
+	}
+	s.pairs[dp.key] = &MultiGamePair{
+		Card1:  dp.key.card1,
+		Card2:  dp.key.card2,
+		Game1:  dp.key.game1,
+		Game2:  dp.key.game2,
+		Count:  1,
+		Weight: dp.weight,
+		DeckID: deckID,
+		Source: source,
+	}
+	if s.maxPairs > 0 && len(s.pairs) >= s.maxPairs {
+		return s.spill()
+	}
+	return nil
+}
+
+func pairLess(a, b *MultiGamePair) bool {
+	if a.Card1 != b.Card1 {
+		return a.Card1 < b.Card1
+	}
+	if a.Card2 != b.Card2 {
+		return a.Card2 < b.Card2
+	}
+	if a.Game1 != b.Game1 {
+		return a.Game1 < b.Game1
+	}
+	return a.Game2 < b.Game2
+}
+
+func (s *pairStore) sorted() []*MultiGamePair {
+	sortedPairs := make([]*MultiGamePair, 0, len(s.pairs))
+	for _, pair := range s.pairs {
+		sortedPairs = append(sortedPairs, pair)
+	}
+	sort.Slice(sortedPairs, func(i, j int) bool {
+		return pairLess(sortedPairs[i], sortedPairs[j])
+	})
+	return sortedPairs
+}
+
+// spill writes the in-memory pairs to a sorted run file and clears them
+func (s *pairStore) spill() error {
+	path := filepath.Join(s.dir, fmt.Sprintf("pairs-%05d.gob", len(s.spills)))
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create spill file: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := gob.NewEncoder(w)
+	for _, pair := range s.sorted() {
+		if err := enc.Encode(pair); err != nil {
+			return fmt.Errorf("failed to write spill file: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write spill file: %w", err)
+	}
+
+	s.spills = append(s.spills, path)
+	s.pairs = make(map[pairKey]*MultiGamePair)
+	return nil
+}
+
+// pairRun is a sorted source of pairs: a spill file or the in-memory pairs
+type pairRun struct {
+	next func() (*MultiGamePair, error)
+	head *MultiGamePair
+	seq  int // Spill order; earlier runs hold earlier decks
+}
+
+type runHeap []*pairRun
+
+func (h runHeap) Len() int { return len(h) }
+func (h runHeap) Less(i, j int) bool {
+	if pairLess(h[i].head, h[j].head) {
+		return true
+	}
+	if pairLess(h[j].head, h[i].head) {
+		return false
+	}
+	return h[i].seq < h[j].seq
+}
+func (h runHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x any)   { *h = append(*h, x.(*pairRun)) }
+func (h *runHeap) Pop() any {
+	old := *h
+	run := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return run
+}
+
+// each calls fn for every distinct pair in sorted order, merging counts for
+// pairs that were spilled more than once
+func (s *pairStore) each(fn func(*MultiGamePair) error) error {
+	h := &runHeap{}
+
+	for i, path := range s.spills {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open spill file: %w", err)
+		}
+		defer f.Close()
+		dec := gob.NewDecoder(bufio.NewReader(f))
+		run := &pairRun{seq: i, next: func() (*MultiGamePair, error) {
+			pair := new(MultiGamePair)
+			if err := dec.Decode(pair); err != nil {
+				return nil, err
+			}
+			return pair, nil
+		}}
+		if err := advance(h, run); err != nil {
+			return err
+		}
+	}
+
+	mem := s.sorted()
+	memRun := &pairRun{seq: len(s.spills), next: func() (*MultiGamePair, error) {
+		if len(mem) == 0 {
+			return nil, io.EOF
+		}
+		pair := mem[0]
+		mem = mem[1:]
+		return pair, nil
+	}}
+	if err := advance(h, memRun); err != nil {
+		return err
+	}
+
+	var current *MultiGamePair
+	for h.Len() > 0 {
+		run := heap.Pop(h).(*pairRun)
+		pair := run.head
+		if current != nil && !pairLess(current, pair) {
+			current.Count += pair.Count
+			current.Weight += pair.Weight
+		} else {
+			if current != nil {
+				if err := fn(current); err != nil {
+					return err
+				}
+			}
+			current = pair
+		}
+		if err := advance(h, run); err != nil {
+			return err
+		}
+	}
+	if current != nil {
+		return fn(current)
+	}
+	return nil
+}
+
+// advance loads the run's next pair and pushes it back on the heap, or
+// drops the run once it is exhausted
+func advance(h *runHeap, run *pairRun) error {
+	pair, err := run.next()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read spill file: %w", err)
+	}
+	run.head = pair
+	heap.Push(h, run)
+	return nil
+}
+
+// close removes spill files
+func (s *pairStore) close() error {
+	var firstErr error
+	for _, path := range s.spills {
+		if err := os.Remove(path); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	s.spills = nil
+	return firstErr
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func collectPairs(t *testing.T, s *pairStore) []MultiGamePair {
+	t.Helper()
+	var pairs []MultiGamePair
+	if err := s.each(func(p *MultiGamePair) error {
+		pairs = append(pairs, *p)
+		return nil
+	}); err != nil {
+		t.Fatalf("each() error = %v", err)
+	}
+	return pairs
+}
+
+func TestPairStoreSpill(t *testing.T) {
+	decks := [][]deckCard{
+		{{name: "Island", count: 4}, {name: "Counterspell", count: 4}, {name: "Brainstorm", count: 2}},
+		{{name: "Island", count: 10}, {name: "Counterspell", count: 2}, {name: "Ponder", count: 4}},
+		{{name: "Mountain", count: 20}, {name: "Lightning Bolt", count: 4}},
+		{{name: "Counterspell", count: 1}, {name: "Brainstorm", count: 4}, {name: "Ponder", count: 1}},
+		{{name: "Island", count: 1}, {name: "Lightning Bolt", count: 1}},
+	}
+	fill := func(s *pairStore) {
+		for i, cards := range decks {
+			for _, dp := range deckPairs(cards, "MTG", true) {
+				if err := s.add(dp, fmt.Sprintf("deck-%d", i), "test"); err != nil {
+					t.Fatalf("add() error = %v", err)
+				}
+			}
+		}
+	}
+
+	unbounded := newPairStore(0, t.TempDir())
+	fill(unbounded)
+	want := collectPairs(t, unbounded)
+
+	dir := t.TempDir()
+	bounded := newPairStore(2, dir)
+	fill(bounded)
+	if len(bounded.spills) < 2 {
+		t.Fatalf("spilled %d runs, want several with a budget of 2 pairs", len(bounded.spills))
+	}
+	if len(bounded.pairs) >= 2 {
+		t.Errorf("holding %d pairs in memory, budget is 2", len(bounded.pairs))
+	}
+	got := collectPairs(t, bounded)
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("spilled output differs:\n got %v\nwant %v", got, want)
+	}
+	for i := 1; i < len(got); i++ {
+		if !pairLess(&got[i-1], &got[i]) {
+			t.Errorf("output not strictly sorted at %d: %v, %v", i, got[i-1], got[i])
+		}
+	}
+
+	if err := bounded.close(); err != nil {
+		t.Fatalf("close() error = %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("close() left %d spill files", len(entries))
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+// Export-parquet: the deck-only co-occurrence graph as Parquet, with typed
+// columns (name_1, name_2 strings; count_set, count_multiset int64) for
+// loading into a warehouse without parsing CSV.
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/parquet-go/parquet-go"
+
+	"collections/cio"
+	"collections/games/magic/game"
+	"collections/graphio"
+)
+
+// parquetEdge is a row of the output: two string and two int64 columns
+type parquetEdge struct {
+	Card1         string `parquet:"name_1"`
+	Card2         string `parquet:"name_2"`
+	CountSet      int64  `parquet:"count_set"`
+	CountMultiset int64  `parquet:"count_multiset"`
+}
+
+var rowGroupSize = flag.Int64("row-group-size", 100000, "Maximum edges per Parquet row group")
+
+func main() {
+	flag.Parse()
+	args := flag.Args()
+	if len(args) < 2 {
+		fmt.Println("Usage: export-parquet [--row-group-size N] <data-dir> <output.parquet>")
+		os.Exit(1)
+	}
+	if *rowGroupSize <= 0 {
+		fmt.Printf("Error: --row-group-size must be positive, got %d\n", *rowGroupSize)
+		os.Exit(1)
+	}
+
+	dataDir := args[0]
+	outputFile := args[1]
+
+	fmt.Println("🎯 Building DECK-ONLY co-occurrence graph for Parquet export...")
+	fmt.Println("   (Excluding sets and cubes to avoid contamination)")
+	fmt.Println()
+
+	files, _ := cio.FindCollectionFiles(dataDir, cio.FindOpts{SkipErrors: true})
+
+	pairCounts := make(map[graphio.Pair]*graphio.PairCounts)
+	totalDecks := 0
+	skippedSets := 0
+	skippedCubes := 0
+
+	for _, file := range files {
+		col, err := game.LoadCollectionFile(file)
+		if err != nil {
+			fmt.Printf("⚠️  Failed to load %s: %v\n", filepath.Base(file), err)
+			continue
+		}
+
+		// Skip sets and cubes, as export-decks-only does
+		if col.Type.Type == "Set" {
+			skippedSets++
+			continue
+		}
+		if col.Type.Type == "Cube" {
+			skippedCubes++
+			continue
+		}
+
+		graphio.AddCollectionPairs(pairCounts, col, false, 1)
+		totalDecks++
+	}
+
+	fmt.Printf("📊 Summary:\n")
+	fmt.Printf("   Decks processed: %d\n", totalDecks)
+	fmt.Printf("   Sets skipped: %d\n", skippedSets)
+	fmt.Printf("   Cubes skipped: %d\n", skippedCubes)
+	fmt.Printf("   Unique pairs: %d\n", len(pairCounts))
+
+	groups, err := writeParquet(outputFile, pairCounts, *rowGroupSize)
+	if err != nil {
+		fmt.Printf("Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\n✅ Deck-only graph exported to %s (%d row groups of up to %d edges)\n", outputFile, groups, *rowGroupSize)
+}
+
+// writeParquet writes pairCounts sorted by card names and returns the
+// number of row groups the writer flushed. Only the sorted keys are held
+// besides the counts; rows are encoded a row group at a time rather than
+// built into a second copy of the graph.
+func writeParquet(path string, pairCounts map[graphio.Pair]*graphio.PairCounts, rowGroupSize int64) (int, error) {
+	pairs := make([]graphio.Pair, 0, len(pairCounts))
+	for p := range pairCounts {
+		pairs = append(pairs, p)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Card1 != pairs[j].Card1 {
+			return pairs[i].Card1 < pairs[j].Card1
+		}
+		return pairs[i].Card2 < pairs[j].Card2
+	})
+
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	w := parquet.NewGenericWriter[parquetEdge](f, parquet.MaxRowsPerRowGroup(rowGroupSize))
+	for _, p := range pairs {
+		c := pairCounts[p]
+		row := parquetEdge{
+			Card1:         p.Card1,
+			Card2:         p.Card2,
+			CountSet:      int64(c.Set),
+			CountMultiset: int64(c.Multiset),
+		}
+		if _, err := w.Write([]parquetEdge{row}); err != nil {
+			return 0, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+	return len(w.File().Metadata().RowGroups), nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+
+	"collections/games/magic/game"
+	"collections/graphio"
+)
+
+func deck(cards ...game.CardDesc) *game.Collection {
+	return &game.Collection{
+		Type:       game.CollectionTypeWrapper{Type: "Deck", Inner: &game.CollectionTypeDeck{Format: "Modern"}},
+		Partitions: []game.Partition{{Name: "Main", Cards: cards}},
+	}
+}
+
+func TestWriteParquetRoundTrip(t *testing.T) {
+	pairCounts := make(map[graphio.Pair]*graphio.PairCounts)
+	graphio.AddCollectionPairs(pairCounts, deck(
+		game.CardDesc{Name: "Lightning Bolt", Count: 4},
+		game.CardDesc{Name: "Mountain", Count: 20},
+	), false, 1)
+	graphio.AddCollectionPairs(pairCounts, deck(
+		game.CardDesc{Name: "Lightning Bolt", Count: 1},
+		game.CardDesc{Name: "Fire // Ice", Count: 2},
+	), false, 1)
+
+	path := filepath.Join(t.TempDir(), "graph.parquet")
+	groups, err := writeParquet(path, pairCounts, 2)
+	if err != nil {
+		t.Fatalf("writeParquet() error = %v", err)
+	}
+
+	want := []parquetEdge{
+		{Card1: "Fire // Ice", Card2: "Fire // Ice", CountSet: 0, CountMultiset: 1},
+		{Card1: "Fire // Ice", Card2: "Lightning Bolt", CountSet: 1, CountMultiset: 2},
+		{Card1: "Lightning Bolt", Card2: "Lightning Bolt", CountSet: 0, CountMultiset: 3},
+		{Card1: "Lightning Bolt", Card2: "Mountain", CountSet: 1, CountMultiset: 80},
+		{Card1: "Mountain", Card2: "Mountain", CountSet: 0, CountMultiset: 19},
+	}
+	got, err := parquet.ReadFile[parquetEdge](path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("read back %v, want %v", got, want)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf, err := parquet.OpenFile(f, info.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(pf.RowGroups()); got != 3 || groups != 3 {
+		t.Errorf("row groups = %d (reported %d), want 3", got, groups)
+	}
+	if fields := pf.Schema().Fields(); len(fields) != 4 {
+		t.Errorf("schema has %d columns, want 4", len(fields))
+	}
+	for column, want := range map[string]parquet.Kind{
+		"name_1":         parquet.ByteArray,
+		"name_2":         parquet.ByteArray,
+		"count_set":      parquet.Int64,
+		"count_multiset": parquet.Int64,
+	} {
+		col, ok := pf.Schema().Lookup(column)
+		if !ok {
+			t.Errorf("schema has no column %s", column)
+			continue
+		}
+		if got := col.Node.Type().Kind(); got != want {
+			t.Errorf("column %s kind = %v, want %v", column, got, want)
+		}
+	}
+}
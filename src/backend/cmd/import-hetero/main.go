@@ -0,0 +1,322 @@
+package main
+
+// Import decks from the JSONL format export-hetero writes back into blob
+// storage as canonical collections, so decklists generated by external
+// tooling can join scraped ones
+//
+// A record may carry the collection type directly ("type": {"type",
+// "inner"}) and partitions ("partitions": [{"name", "cards"}]), or the flat
+// export-hetero fields (archetype, format, player, ...) and a "cards" list
+// tagged with their partition. Each is canonicalized and written to
+// <prefix>/<id>.json.zst.
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"collections/blob"
+	"collections/cio"
+	"collections/games"
+	_ "collections/games/digimon/game"   // Register collection types
+	magic "collections/games/magic/game" // Magic keeps its own Collection type
+	_ "collections/games/onepiece/game"  // Register collection types
+	_ "collections/games/pokemon/game"   // Register collection types
+	_ "collections/games/riftbound/game" // Register collection types
+	_ "collections/games/yugioh/game"    // Register collection types
+	"collections/logger"
+)
+
+// deckTypes is each game's deck collection type, used for flat records
+var deckTypes = map[string]string{
+	"magic":     "Deck",
+	"pokemon":   "PokemonDeck",
+	"yugioh":    "YGODeck",
+	"onepiece":  "OnePieceDeck",
+	"riftbound": "RiftboundDeck",
+	"digimon":   "DigimonDeck",
+}
+
+var gameName = flag.String("game", "magic", "Game the records belong to: magic, pokemon, yugioh, onepiece, riftbound or digimon")
+
+// record is one JSONL line. Type and Partitions take precedence over the
+// flat fields when present.
+type record struct {
+	ID          string            `json:"id"`
+	DeckID      string            `json:"deck_id"`
+	URL         string            `json:"url"`
+	Source      string            `json:"source"`
+	ReleaseDate time.Time         `json:"release_date"`
+	Type        json.RawMessage   `json:"type"`
+	Partitions  []games.Partition `json:"partitions"`
+
+	Archetype string `json:"archetype"`
+	Format    string `json:"format"`
+	Player    string `json:"player"`
+	Event     string `json:"event"`
+	Placement *int   `json:"placement"`
+	EventDate string `json:"event_date"`
+	ScrapedAt string `json:"scraped_at"`
+	Cards     []struct {
+		Name      string `json:"name"`
+		Count     int    `json:"count"`
+		Partition string `json:"partition"`
+	} `json:"cards"`
+}
+
+// collection is the part of magic.Collection and games.Collection the
+// importer needs
+type collection interface {
+	Canonicalize() error
+}
+
+// rejection is a record dropped by parsing or canonicalization,
+// identified by 1-based line
+type rejection struct {
+	Line   int
+	ID     string
+	Reason string
+}
+
+type importResult struct {
+	Records  int
+	Imported int
+	Rejected []rejection
+}
+
+// reQuoted matches quoted card and partition names in Canonicalize
+// errors, so rejections group by reason rather than by card
+var reQuoted = regexp.MustCompile(`"[^"]*"`)
+
+type reasonCount struct {
+	Reason string
+	Count  int
+}
+
+// reasons counts rejections by reason with quoted names elided, most
+// frequent first
+func (r *importResult) reasons() []reasonCount {
+	counts := make(map[string]int)
+	for _, rej := range r.Rejected {
+		counts[reQuoted.ReplaceAllString(rej.Reason, `"…"`)]++
+	}
+	out := make([]reasonCount, 0, len(counts))
+	for reason, n := range counts {
+		out = append(out, reasonCount{reason, n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Reason < out[j].Reason
+	})
+	return out
+}
+
+// importRecords reads JSONL from r and writes each record that
+// canonicalizes to bucket under prefix. Blank lines are ignored.
+func importRecords(ctx context.Context, bucket *blob.Bucket, r io.Reader, game, prefix string) (*importResult, error) {
+	deckType, ok := deckTypes[game]
+	if !ok {
+		return nil, fmt.Errorf("unknown game %q", game)
+	}
+
+	res := &importResult{}
+	sc := cio.NewJSONLScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		data := sc.Bytes()
+		if len(strings.TrimSpace(string(data))) == 0 {
+			continue
+		}
+		res.Records++
+
+		var rec record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			res.Rejected = append(res.Rejected, rejection{Line: line, Reason: fmt.Sprintf("malformed JSON: %v", err)})
+			continue
+		}
+		id, err := recordID(rec)
+		if err != nil {
+			res.Rejected = append(res.Rejected, rejection{Line: line, ID: id, Reason: err.Error()})
+			continue
+		}
+		col, err := buildCollection(rec, id, game, deckType)
+		if err == nil {
+			err = col.Canonicalize()
+		}
+		if err != nil {
+			res.Rejected = append(res.Rejected, rejection{Line: line, ID: id, Reason: err.Error()})
+			continue
+		}
+
+		b, err := json.Marshal(col)
+		if err != nil {
+			return res, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := bucket.Write(ctx, path.Join(prefix, id+".json"), b); err != nil {
+			return res, fmt.Errorf("line %d: %w", line, err)
+		}
+		res.Imported++
+	}
+	if err := sc.Err(); err != nil {
+		return res, fmt.Errorf("line %d: %w", line+1, err)
+	}
+	return res, nil
+}
+
+// recordID is the record's id, or its export-hetero deck_id (the file
+// name the deck was exported from) without extensions. The id becomes a
+// key under --prefix, so ids that are empty or could name another path
+// are rejected.
+func recordID(rec record) (string, error) {
+	id := rec.ID
+	if id == "" {
+		id = strings.TrimSuffix(rec.DeckID, ".zst")
+		id = strings.TrimSuffix(id, ".json")
+	}
+	if strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return id, fmt.Errorf("invalid id %q: must not contain /, \\ or ..", id)
+	}
+	if cleaned := strings.TrimSpace(id); cleaned == "" || cleaned == "." {
+		return id, fmt.Errorf("missing id")
+	}
+	return id, nil
+}
+
+// buildCollection reconstructs the collection rec describes, as the
+// game's Collection type
+func buildCollection(rec record, id, game, deckType string) (collection, error) {
+	typ := rec.Type
+	if len(typ) == 0 {
+		inner := map[string]any{
+			"name":      rec.Archetype,
+			"format":    rec.Format,
+			"archetype": rec.Archetype,
+			"player":    rec.Player,
+			"event":     rec.Event,
+			"eventDate": rec.EventDate,
+		}
+		if rec.Placement != nil {
+			inner["placement"] = *rec.Placement
+		}
+		var err error
+		typ, err = json.Marshal(map[string]any{"type": deckType, "inner": inner})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	partitions := rec.Partitions
+	if len(partitions) == 0 {
+		// Group flat cards by partition in order of first appearance
+		index := make(map[string]int)
+		for _, c := range rec.Cards {
+			i, ok := index[c.Partition]
+			if !ok {
+				i = len(partitions)
+				index[c.Partition] = i
+				partitions = append(partitions, games.Partition{Name: c.Partition})
+			}
+			partitions[i].Cards = append(partitions[i].Cards, games.CardDesc{Name: c.Name, Count: c.Count})
+		}
+	}
+
+	releaseDate := rec.ReleaseDate
+	if releaseDate.IsZero() {
+		releaseDate = games.ParseDateWithFallback(rec.EventDate, time.Time{})
+	}
+	if releaseDate.IsZero() {
+		releaseDate = games.ParseDateWithFallback(rec.ScrapedAt, time.Time{})
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"id":           id,
+		"url":          rec.URL,
+		"type":         typ,
+		"release_date": releaseDate,
+		"partitions":   partitions,
+		"source":       rec.Source,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var col collection
+	if game == "magic" {
+		col = new(magic.Collection)
+	} else {
+		col = new(games.Collection)
+	}
+	if err := json.Unmarshal(data, col); err != nil {
+		return nil, fmt.Errorf("invalid collection type: %w", err)
+	}
+	return col, nil
+}
+
+func main() {
+	flag.Parse()
+	args := flag.Args()
+	if len(args) < 3 {
+		fmt.Println("Usage: import-hetero [--game GAME] <input.jsonl> <bucket-url> <prefix>")
+		fmt.Println("Example: import-hetero --game pokemon decks.jsonl file://./data-full pokemon/imported")
+		os.Exit(1)
+	}
+
+	inputFile := args[0]
+	bucketURL := args[1]
+	prefix := args[2]
+
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+
+	f, err := os.Open(inputFile)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	bucket, err := blob.NewBucket(ctx, log, bucketURL)
+	if err != nil {
+		fmt.Printf("Error: Failed to create blob bucket: %v\n", err)
+		os.Exit(1)
+	}
+	defer bucket.Close(ctx)
+
+	res, err := importRecords(ctx, bucket, f, *gameName, prefix)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("📊 %s\n", inputFile)
+	fmt.Printf("   Records: %d\n", res.Records)
+	fmt.Printf("   Imported: %d\n", res.Imported)
+	fmt.Printf("   Rejected: %d\n", len(res.Rejected))
+	for _, r := range res.reasons() {
+		fmt.Printf("     %d × %s\n", r.Count, r.Reason)
+	}
+	maxToLog := 10
+	for i, rej := range res.Rejected {
+		if i == maxToLog {
+			fmt.Printf("⚠️  %d more rejected records (showing first %d)\n", len(res.Rejected)-maxToLog, maxToLog)
+			break
+		}
+		if rej.ID != "" {
+			fmt.Printf("⚠️  line %d (%s): %s\n", rej.Line, rej.ID, rej.Reason)
+		} else {
+			fmt.Printf("⚠️  line %d: %s\n", rej.Line, rej.Reason)
+		}
+	}
+	fmt.Printf("✅ Imported %d decks to %s/%s\n", res.Imported, bucketURL, prefix)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"collections/blob"
+	"collections/games"
+	magic "collections/games/magic/game"
+	"collections/logger"
+)
+
+func TestImportRecords(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+
+	lines := []string{
+		// Flat export-hetero record
+		`{"deck_id":"123.json.zst","archetype":"Burn","format":"Modern","url":"https://www.mtgtop8.com/event?d=123","source":"mtgtop8","placement":1,"event_date":"2024-05-01","cards":[{"name":"Mountain","count":18,"partition":"Main"},{"name":"Lightning Bolt","count":4,"partition":"Main"},{"name":"Smash to Smithereens","count":3,"partition":"Sideboard"}]}`,
+		`{"deck_id":"124.json","url":"https://www.mtgtop8.com/event?d=124","event_date":"2024-05-01","cards":[{"name":"Lightning Bolt","count":0,"partition":"Main"}]}`,
+		`{"deck_id":"125.json","url":"https://www.mtgtop8.com/event?d=125","event_date":"2024-05-01","cards":[{"name":"Counterspell","count":0,"partition":"Main"}]}`,
+		``,
+		`{"deck_id":"126.json","url":"https://www.mtgtop8.com/event?d=126","cards":[{"name":"Island","count":20,"partition":"Main"}]}`,
+		`{"deck_id":"127.json",`,
+		// Full type and partitions
+		`{"id":"128","url":"https://www.mtgtop8.com/event?d=128","release_date":"2024-05-02T00:00:00Z","type":{"type":"Cube","inner":{"name":"Vintage"}},"partitions":[{"name":"Main","cards":[{"name":"Black Lotus","count":1}]}]}`,
+	}
+	res, err := importRecords(ctx, bucket, strings.NewReader(strings.Join(lines, "\n")), "magic", "magic/imported")
+	if err != nil {
+		t.Fatalf("importRecords() error = %v", err)
+	}
+	if res.Records != 6 || res.Imported != 2 || len(res.Rejected) != 4 {
+		t.Fatalf("result = %+v, want 6 records, 2 imported, 4 rejected", *res)
+	}
+
+	reasons := res.reasons()
+	if len(reasons) != 3 || reasons[0].Count != 2 || reasons[0].Reason != `card "…" has count 0 in partition "…"` {
+		t.Errorf("reasons() = %+v, want the two count-0 rejections grouped first", reasons)
+	}
+	if rej := res.Rejected[2]; rej.Line != 5 || rej.Reason != "release date is zero time" {
+		t.Errorf("rejection = %+v, want line 5 without a date", rej)
+	}
+
+	data, err := bucket.Read(ctx, "magic/imported/123.json")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	var col magic.Collection
+	if err := json.Unmarshal(data, &col); err != nil {
+		t.Fatal(err)
+	}
+	deck, ok := col.Type.Inner.(*magic.CollectionTypeDeck)
+	if !ok || deck.Archetype != "Burn" || deck.Placement == nil || *deck.Placement != 1 || deck.EventDate != "2024-05-01" {
+		t.Errorf("type = %+v, want a Burn deck placing 1", col.Type.Inner)
+	}
+	// Canonicalized: partitions and cards sorted by name
+	if len(col.Partitions) != 2 || col.Partitions[0].Cards[0].Name != "Lightning Bolt" || col.Partitions[1].Name != "Sideboard" {
+		t.Errorf("partitions = %+v, want canonical Main and Sideboard", col.Partitions)
+	}
+
+	if ok, err := bucket.Exists(ctx, "magic/imported/128.json"); err != nil || !ok {
+		t.Errorf("Exists(128) = %v, %v; want the cube imported", ok, err)
+	}
+}
+
+func TestImportRecordsRegisteredGame(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+
+	line := `{"deck_id":"pk1.json","archetype":"Charizard ex","format":"Standard","url":"https://limitlesstcg.com/decks/list/1","source":"limitless-web","placement":3,"event_date":"2024-03-01","cards":[{"name":"Charizard ex","count":3,"partition":"Deck"}]}`
+	res, err := importRecords(ctx, bucket, strings.NewReader(line), "pokemon", "pokemon/imported")
+	if err != nil {
+		t.Fatalf("importRecords() error = %v", err)
+	}
+	if res.Imported != 1 {
+		t.Fatalf("result = %+v, want 1 imported", *res)
+	}
+
+	data, err := bucket.Read(ctx, "pokemon/imported/pk1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var col games.Collection
+	if err := json.Unmarshal(data, &col); err != nil {
+		t.Fatal(err)
+	}
+	if col.Type.Type != "PokemonDeck" || col.Source != "limitless-web" {
+		t.Errorf("collection type %q source %q, want PokemonDeck from limitless-web", col.Type.Type, col.Source)
+	}
+
+	if _, err := importRecords(ctx, bucket, strings.NewReader(line), "chess", "x"); err == nil {
+		t.Error("importRecords(chess) error = nil, want unknown game")
+	}
+}
+
+func TestImportRecordsRejectsUnsafeIDs(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+
+	const rest = `"url":"https://www.mtgtop8.com/event?d=1","event_date":"2024-05-01","cards":[{"name":"Island","count":20,"partition":"Main"}]}`
+	var lines []string
+	for _, id := range []string{"../../pokemon/imported/pk1", "a/b", `a\\b`, "..", " ", ""} {
+		lines = append(lines, `{"id":"`+id+`",`+rest)
+	}
+	lines = append(lines, `{"deck_id":"../x.json",`+rest)
+	res, err := importRecords(ctx, bucket, strings.NewReader(strings.Join(lines, "\n")), "magic", "magic/imported")
+	if err != nil {
+		t.Fatalf("importRecords() error = %v", err)
+	}
+	if res.Imported != 0 || len(res.Rejected) != len(lines) {
+		t.Errorf("result = %+v, want all %d records rejected", *res, len(lines))
+	}
+	it := bucket.List(ctx)
+	for it.Next(ctx) {
+		t.Errorf("wrote %s", it.Key())
+	}
+}
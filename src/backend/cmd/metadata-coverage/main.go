@@ -0,0 +1,98 @@
+package main
+
+// Report tournament metadata completeness per source, worst first, to show
+// which scrapers/parsers most need work
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"collections/cio"
+	"collections/games"
+)
+
+// rawType carries a collection type's JSON through unchanged, so decks from
+// every game (including MTG, whose types are not in games.TypeRegistry)
+// can be scored without registering their types.
+type rawType struct {
+	name  string
+	inner json.RawMessage
+}
+
+func (t *rawType) Type() string                 { return t.name }
+func (t *rawType) IsCollectionType()            {}
+func (t *rawType) MarshalJSON() ([]byte, error) { return t.inner, nil }
+
+// loadDeck reads a collection file, returning nil for non-deck collections
+func loadDeck(path string) (*games.Collection, error) {
+	data, err := cio.ReadCollectionFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw struct {
+		Source string `json:"source"`
+		Type   struct {
+			Type  string          `json:"type"`
+			Inner json.RawMessage `json:"inner"`
+		} `json:"type"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(raw.Type.Type, "Deck") || len(raw.Type.Inner) == 0 {
+		return nil, nil
+	}
+	return &games.Collection{
+		Source: games.Source(raw.Source),
+		Type: games.CollectionTypeWrapper{
+			Type:  raw.Type.Type,
+			Inner: &rawType{name: raw.Type.Type, inner: raw.Type.Inner},
+		},
+	}, nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println("Usage: metadata-coverage <data-dir>")
+		os.Exit(1)
+	}
+
+	dataDir := os.Args[1]
+	files, err := cio.FindCollectionFiles(dataDir, cio.FindOpts{SkipErrors: true})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	report := games.NewMetadataReport()
+	decks := 0
+	for _, file := range files {
+		col, err := loadDeck(file)
+		if err != nil {
+			fmt.Printf("⚠️  Failed to load %s: %v\n", filepath.Base(file), err)
+			continue
+		}
+		if col == nil {
+			continue
+		}
+		report.Add(col)
+		decks++
+	}
+
+	fmt.Printf("📊 Metadata coverage for %d decks\n\n", decks)
+	fmt.Printf("%-24s %8s %7s", "SOURCE", "DECKS", "SCORE")
+	for _, f := range games.MetadataFields {
+		fmt.Printf(" %10s", f.Name)
+	}
+	fmt.Println()
+	for _, s := range report.Sources() {
+		fmt.Printf("%-24s %8d %7.2f", s.Source, s.Collections, s.MeanScore())
+		for _, f := range games.MetadataFields {
+			fmt.Printf(" %9.0f%%", s.Coverage(f.Name)*100)
+		}
+		fmt.Println()
+	}
+}
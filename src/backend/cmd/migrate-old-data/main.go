@@ -1,19 +1,23 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"collections/blob"
+	"collections/cio"
+	"collections/games"
 	"collections/games/magic/dataset/deckbox"
 	"collections/games/magic/dataset/goldfish"
 	"collections/games/magic/dataset/scryfall"
+	"collections/games/magic/game"
 	"collections/logger"
 
 	"github.com/DataDog/zstd"
@@ -130,6 +134,14 @@ type migrationStats struct {
 	failed  int
 }
 
+// errNotCollection marks a dumped page that holds no collection, such as a
+// listing page, so it is skipped rather than counted as a failure
+var errNotCollection = errors.New("not a collection page")
+
+// parseFunc parses one dumped response into a collection and the blob key
+// it is written under
+type parseFunc func(resp *oldFormatResponse, body []byte) (string, *game.Collection, error)
+
 func migrateGoldfish(ctx context.Context, log *logger.Logger, b *blob.Bucket, sourceDir string, stats *migrationStats) error {
 	files, err := findJSONFiles(sourceDir)
 	if err != nil {
@@ -138,38 +150,34 @@ func migrateGoldfish(ctx context.Context, log *logger.Logger, b *blob.Bucket, so
 
 	log.Infof(ctx, "Found %d goldfish files", len(files))
 
-	// Use goldfish parser to process
-	for i, file := range files {
-		if limitFlag > 0 && i >= limitFlag {
-			break
-		}
-
-		stats.total++
-
-		// Read and decompress
-		html, err := readOldFormat(file)
+	// Decklists come from the separate plain-text download responses in the
+	// dump, so index those by URL before parsing the deck pages
+	downloads := make(map[string][]byte)
+	var pages []string
+	for _, file := range files {
+		resp, body, err := readOldFormat(file)
 		if err != nil {
-			log.Errorf(ctx, "Failed to read %s: %v", filepath.Base(file), err)
-			stats.failed++
+			// Reported when the deck pages are read below
+			pages = append(pages, file)
 			continue
 		}
-
-		if len(html) == 0 {
-			stats.skipped++
+		if strings.Contains(resp.URL, "/deck/download/") {
+			downloads[resp.URL] = body
 			continue
 		}
-
-		// Parse with goldfish parser
-		// For now, skip actual parsing - we'll use the scraper to re-extract
-		// This is just to count and validate files
-		stats.skipped++
-
-		if i > 0 && i%100 == 0 {
-			log.Infof(ctx, "Processed %d/%d goldfish files", i, len(files))
-		}
+		pages = append(pages, file)
 	}
+	log.Infof(ctx, "Found %d goldfish deck pages and %d downloads", len(pages), len(downloads))
 
-	return nil
+	return migrateFiles(ctx, log, b, "goldfish", pages, stats, func(resp *oldFormatResponse, body []byte) (string, *game.Collection, error) {
+		// Without a dumped download, ParseCollection falls back to the
+		// decklist embedded in the page
+		col, err := goldfish.ParseCollection(resp.URL, body, downloads[goldfish.DownloadURL(resp.URL)])
+		if err != nil {
+			return "", nil, err
+		}
+		return goldfish.CollectionKey(col.ID), col, nil
+	})
 }
 
 func migrateDeckbox(ctx context.Context, log *logger.Logger, b *blob.Bucket, sourceDir string, stats *migrationStats) error {
@@ -180,35 +188,19 @@ func migrateDeckbox(ctx context.Context, log *logger.Logger, b *blob.Bucket, sou
 
 	log.Infof(ctx, "Found %d deckbox files", len(files))
 
-	for i, file := range files {
-		if limitFlag > 0 && i >= limitFlag {
-			break
+	return migrateFiles(ctx, log, b, "deckbox", files, stats, func(resp *oldFormatResponse, body []byte) (string, *game.Collection, error) {
+		if !strings.Contains(resp.URL, "/sets/") {
+			return "", nil, errNotCollection
 		}
-
-		stats.total++
-
-		// Read and decompress
-		html, err := readOldFormat(file)
+		// Set pages carry no date; like an extract of explicit URLs, fall
+		// back to when the page was seen
+		releaseDate := games.ParseDateWithFallback(resp.ScrapedAt, time.Now())
+		col, err := deckbox.ParseCollection(resp.URL, body, releaseDate)
 		if err != nil {
-			log.Errorf(ctx, "Failed to read %s: %v", filepath.Base(file), err)
-			stats.failed++
-			continue
-		}
-
-		if len(html) == 0 {
-			stats.skipped++
-			continue
-		}
-
-		// Skip for now - will re-extract
-		stats.skipped++
-
-		if i > 0 && i%100 == 0 {
-			log.Infof(ctx, "Processed %d/%d deckbox files", i, len(files))
+			return "", nil, err
 		}
-	}
-
-	return nil
+		return deckbox.CollectionKey(col.ID), col, nil
+	})
 }
 
 func migrateScryfall(ctx context.Context, log *logger.Logger, b *blob.Bucket, sourceDir string, stats *migrationStats) error {
@@ -219,6 +211,26 @@ func migrateScryfall(ctx context.Context, log *logger.Logger, b *blob.Bucket, so
 
 	log.Infof(ctx, "Found %d scryfall files", len(files))
 
+	return migrateFiles(ctx, log, b, "scryfall", files, stats, func(resp *oldFormatResponse, body []byte) (string, *game.Collection, error) {
+		col, err := scryfall.ParseCollection(ctx, log, resp.URL, body)
+		if err != nil {
+			return "", nil, err
+		}
+		return scryfall.CollectionKey(col.ID), col, nil
+	})
+}
+
+// migrateFiles parses each old-format file with parse and writes the
+// resulting collections to b
+func migrateFiles(
+	ctx context.Context,
+	log *logger.Logger,
+	b *blob.Bucket,
+	source string,
+	files []string,
+	stats *migrationStats,
+	parse parseFunc,
+) error {
 	for i, file := range files {
 		if limitFlag > 0 && i >= limitFlag {
 			break
@@ -227,75 +239,83 @@ func migrateScryfall(ctx context.Context, log *logger.Logger, b *blob.Bucket, so
 		stats.total++
 
 		// Read and decompress
-		html, err := readOldFormat(file)
+		resp, body, err := readOldFormat(file)
 		if err != nil {
 			log.Errorf(ctx, "Failed to read %s: %v", filepath.Base(file), err)
 			stats.failed++
 			continue
 		}
 
-		if len(html) == 0 {
+		if len(body) == 0 {
+			stats.skipped++
+			continue
+		}
+
+		key, col, err := parse(resp, body)
+		if errors.Is(err, errNotCollection) {
 			stats.skipped++
 			continue
 		}
+		if err != nil {
+			log.Errorf(ctx, "Failed to parse %s (%s): %v", filepath.Base(file), resp.URL, err)
+			stats.failed++
+			continue
+		}
 
-		// Skip for now - will re-extract
-		stats.skipped++
+		if err := writeCollection(ctx, b, key, col); err != nil {
+			log.Errorf(ctx, "Failed to write %s: %v", key, err)
+			stats.failed++
+			continue
+		}
+		stats.success++
 
 		if i > 0 && i%100 == 0 {
-			log.Infof(ctx, "Processed %d/%d scryfall files", i, len(files))
+			log.Infof(ctx, "Processed %d/%d %s files", i, len(files), source)
 		}
 	}
 
 	return nil
 }
 
-func findJSONFiles(dir string) ([]string, error) {
-	var files []string
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() && strings.HasSuffix(path, ".json.zst") {
-			files = append(files, path)
-		}
+func writeCollection(ctx context.Context, b *blob.Bucket, key string, col *game.Collection) error {
+	data, err := json.Marshal(col)
+	if err != nil {
+		return err
+	}
+	if dryRun {
 		return nil
-	})
-	return files, err
+	}
+	return b.Write(ctx, key, data)
 }
 
-func readOldFormat(filePath string) ([]byte, error) {
+func findJSONFiles(dir string) ([]string, error) {
+	return cio.FindCollectionFiles(dir, cio.FindOpts{Extensions: []string{".json.zst"}})
+}
+
+func readOldFormat(filePath string) (*oldFormatResponse, []byte, error) {
 	// Read compressed file
 	compressed, err := os.ReadFile(filePath)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Decompress
 	decompressed, err := zstd.Decompress(nil, compressed)
 	if err != nil {
-		return nil, fmt.Errorf("decompress failed: %w", err)
+		return nil, nil, fmt.Errorf("decompress failed: %w", err)
 	}
 
 	// Parse old format JSON
 	var oldResp oldFormatResponse
 	if err := json.Unmarshal(decompressed, &oldResp); err != nil {
-		return nil, fmt.Errorf("json unmarshal failed: %w", err)
+		return nil, nil, fmt.Errorf("json unmarshal failed: %w", err)
 	}
 
 	// Decode base64 HTML
 	html, err := base64.StdEncoding.DecodeString(oldResp.Bytes)
 	if err != nil {
-		return nil, fmt.Errorf("base64 decode failed: %w", err)
+		return nil, nil, fmt.Errorf("base64 decode failed: %w", err)
 	}
 
-	return html, nil
+	return &oldResp, html, nil
 }
-
-// Ensure imports are used
-var (
-	_ = bytes.Buffer{}
-	_ = goldfish.Dataset{}
-	_ = deckbox.Dataset{}
-	_ = scryfall.Dataset{}
-)
@@ -1,187 +1,247 @@
 package main
 
 import (
-	"encoding/csv"
-	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"sort"
+	"runtime"
+	"strings"
+	"time"
 
+	"collections/cio"
+	"collections/games"
 	"collections/games/magic/game"
-	"github.com/DataDog/zstd"
+	"collections/graphio"
 )
 
-type pair struct {
-	card1 string
-	card2 string
-}
+var (
+	outputFormat = flag.String("output-format", "", "Output format: csv, jsonl, parquet or gexf (default: from output extension)")
+	weighting    = flag.String("weighting", string(weightMultiset), "Pair weighting policy: multiset, binary, or by-type (binary for Set/Cube/singleton formats, multiset otherwise)")
+	halfLifeDays = flag.Float64("half-life", 0, "Weight each collection's pairs by exponential age decay with this half-life in days; collections with estimated dates are skipped (0 disables)")
+	asOfDate     = flag.String("as-of", "", "Only include collections dated on or before this date (YYYY-MM-DD), reconstructing the graph at that point; collections with estimated dates are skipped")
+	spillPairs   = flag.Int("spill-threshold", 0, "Unique pairs held in memory before they spill to a temporary on-disk store (0 never spills)")
+	workers      = flag.Int("workers", runtime.NumCPU(), "Collections to load and count in parallel")
+	minCount     = flag.Int64("min-count", 0, "Only write pairs appearing together in at least this many collections (COUNT_SET)")
+	topN         = flag.Int("top-n", 0, "Only write the N pairs with the highest COUNT_SET, after --min-count (0 writes all)")
+	formats      games.FormatFilter
+)
 
-type counts struct {
-	set      int
-	multiset int
+func init() {
+	flag.Var(&formats, "format", "Only include decks of this format (repeatable; case-insensitive, abbreviations like \"mod\" allowed); sets, cubes and decks without a format are skipped")
 }
 
-func main() {
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: go run main.go <data-dir> <output.csv>")
-		os.Exit(1)
-	}
+// weightingPolicy decides, per collection, whether pairs count copies
+// (count_i * count_j) or only presence
+type weightingPolicy string
 
-	dataDir := os.Args[1]
-	outputFile := os.Args[2]
+const (
+	weightMultiset weightingPolicy = "multiset"
+	weightBinary   weightingPolicy = "binary"
+	weightByType   weightingPolicy = "by-type"
+)
 
-	fmt.Println("Scanning for collections...")
+// singletonFormats allow one copy of each nonbasic card
+var singletonFormats = map[string]bool{
+	"commander":           true,
+	"cedh":                true,
+	"duel commander":      true,
+	"highlander":          true,
+	"canadian highlander": true,
+	"brawl":               true,
+}
 
-	// Find all collection files
-	var files []string
-	err := filepath.Walk(dataDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() && filepath.Ext(path) == ".zst" {
-			files = append(files, path)
-		}
-		return nil
-	})
-	if err != nil {
-		fmt.Printf("Error scanning directory: %v\n", err)
-		os.Exit(1)
+func parseWeightingPolicy(s string) (weightingPolicy, error) {
+	switch p := weightingPolicy(s); p {
+	case weightMultiset, weightBinary, weightByType:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown weighting policy %q (supported: multiset, binary, by-type)", s)
 	}
+}
 
-	fmt.Printf("Found %d collection files\n", len(files))
-
-	// Build co-occurrence map
-	pairCounts := make(map[pair]*counts)
-	total := 0
-	totalCards := 0
-	totalEdges := 0
-
-	for i, file := range files {
-		col, err := loadCollection(file)
-		if err != nil {
-			fmt.Printf("⚠️  [%d/%d] Failed to load %s: %v\n", i+1, len(files), filepath.Base(file), err)
-			continue
+// binary reports whether col should be weighted by presence
+func (p weightingPolicy) binary(col *game.Collection) bool {
+	switch p {
+	case weightBinary:
+		return true
+	case weightByType:
+		switch inner := col.Type.Inner.(type) {
+		case *game.CollectionTypeSet, *game.CollectionTypeCube:
+			return true
+		case *game.CollectionTypeDeck:
+			return singletonFormats[strings.ToLower(games.NormalizeFormatName(inner.Format))]
 		}
+	}
+	return false
+}
 
-		collectionCards := 0
-		collectionEdges := 0
-
-		// Process each partition
-		for _, partition := range col.Partitions {
-			cards := partition.Cards
-			n := len(cards)
-			collectionCards += n
-
-			for i := 0; i < n; i++ {
-				c := cards[i]
-
-				// Self-pairs (if count > 1)
-				if c.Count > 1 {
-					p := makePair(c.Name, c.Name)
-					if pairCounts[p] == nil {
-						pairCounts[p] = &counts{}
-					}
-					pairCounts[p].multiset += c.Count - 1
-					collectionEdges++
-				}
-
-				// Other pairs
-				for j := i + 1; j < n; j++ {
-					d := cards[j]
-					p := makePair(c.Name, d.Name)
-					if pairCounts[p] == nil {
-						pairCounts[p] = &counts{}
-					}
-					pairCounts[p].set += 1
-					pairCounts[p].multiset += c.Count * d.Count
-					collectionEdges++
-				}
-			}
-		}
+func main() {
+	flag.Parse()
+	args := flag.Args()
+	if len(args) < 2 {
+		fmt.Println("Usage: go run main.go [--output-format csv|jsonl|parquet|gexf] [--weighting multiset|binary|by-type] [--half-life DAYS] [--as-of YYYY-MM-DD] [--format FORMAT]... [--spill-threshold PAIRS] [--workers N] [--min-count N] [--top-n N] <data-dir> <output.csv>")
+		os.Exit(1)
+	}
 
-		total++
-		totalCards += collectionCards
-		totalEdges += collectionEdges
+	dataDir := args[0]
+	outputFile := args[1]
 
-		// Progress with details
-		pct := float64(total) / float64(len(files)) * 100
-		fmt.Printf("✓ [%d/%d %.1f%%] %s: %d cards, %d edges → %d unique pairs total\n",
-			total, len(files), pct, filepath.Base(file), collectionCards, collectionEdges, len(pairCounts))
+	format, err := graphio.ResolveFormat(*outputFormat, outputFile)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	policy, err := parseWeightingPolicy(*weighting)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
 	}
 
-	fmt.Printf("\n📊 Summary:\n")
-	fmt.Printf("   Collections processed: %d\n", total)
-	fmt.Printf("   Total unique cards: %d\n", totalCards)
-	fmt.Printf("   Total edges created: %d\n", totalEdges)
-	fmt.Printf("   Unique card pairs: %d\n", len(pairCounts))
-	fmt.Printf("   Compression ratio: %.1fx\n", float64(totalEdges)/float64(len(pairCounts)))
-
-	// Write to CSV
-	f, err := os.Create(outputFile)
+	dates, err := games.NewDatePolicy(*asOfDate, *halfLifeDays, time.Now())
 	if err != nil {
-		fmt.Printf("Error creating output file: %v\n", err)
+		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
-	defer f.Close()
 
-	w := csv.NewWriter(f)
-	defer w.Flush()
+	fmt.Println("Scanning for collections...")
 
-	// Header
-	w.Write([]string{"NAME_1", "NAME_2", "COUNT_SET", "COUNT_MULTISET"})
+	// Build co-occurrence map as the walk finds collections, so only the
+	// pair counts grow with the input
+	pairs := newPairStore(*spillPairs)
+	defer pairs.close()
+	stats, err := buildPairs(os.Stdout, dataDir, *workers, pairs, policy, dates, formats)
+	if err != nil {
+		fmt.Printf("Error scanning directory: %v\n", err)
+		os.Exit(1)
+	}
 
-	// Sort pairs for deterministic output
-	var sortedPairs []pair
-	for p := range pairCounts {
-		sortedPairs = append(sortedPairs, p)
+	// Write in the requested format, sorted for deterministic output
+	var writerOpts []graphio.WriterOption
+	if *halfLifeDays > 0 {
+		writerOpts = append(writerOpts, &graphio.OptWriterWeight{})
+	}
+	w, err := graphio.Create(outputFile, format, writerOpts...)
+	if err != nil {
+		fmt.Printf("Error creating output: %v\n", err)
+		os.Exit(1)
+	}
+	if *minCount > 0 || *topN > 0 {
+		w = graphio.NewFilterWriter(w, graphio.EdgeFilter{MinCount: *minCount, TopN: *topN})
+	}
+	if err := pairs.writeEdges(w, *halfLifeDays > 0); err != nil {
+		fmt.Printf("Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+	if err := w.Close(); err != nil {
+		fmt.Printf("Error writing output: %v\n", err)
+		os.Exit(1)
 	}
-	sort.Slice(sortedPairs, func(i, j int) bool {
-		if sortedPairs[i].card1 != sortedPairs[j].card1 {
-			return sortedPairs[i].card1 < sortedPairs[j].card1
-		}
-		return sortedPairs[i].card2 < sortedPairs[j].card2
-	})
 
-	// Write data
-	for _, p := range sortedPairs {
-		c := pairCounts[p]
-		w.Write([]string{
-			p.card1,
-			p.card2,
-			fmt.Sprintf("%d", c.set),
-			fmt.Sprintf("%d", c.multiset),
-		})
+	fmt.Printf("\n📊 Summary:\n")
+	fmt.Printf("   Collection files found: %d\n", stats.seen)
+	fmt.Printf("   Collections processed: %d\n", stats.total)
+	for _, reason := range []string{games.SkipEstimated, games.SkipAfterAsOf, skipFormat} {
+		if n := stats.skipped[reason]; n > 0 {
+			fmt.Printf("   Collections skipped (%s): %d\n", reason, n)
+		}
+	}
+	fmt.Printf("   Total unique cards: %d\n", stats.totalCards)
+	fmt.Printf("   Total edges created: %d\n", stats.totalEdges)
+	fmt.Printf("   Unique card pairs: %d\n", pairs.len())
+	if pairs.spilled() {
+		fmt.Printf("   (Pair counts spilled to disk past %d pairs)\n", *spillPairs)
 	}
+	fmt.Printf("   Compression ratio: %.1fx\n", float64(stats.totalEdges)/float64(pairs.len()))
 
 	fmt.Printf("✅ Successfully exported to %s\n", outputFile)
 }
 
-func loadCollection(path string) (*game.Collection, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
+// collectionPairs is one collection's contribution to the graph, counted
+// by a worker and merged into the store in file order
+type collectionPairs struct {
+	err   error
+	skip  string // date or format skip reason
+	pairs map[graphio.Pair]*graphio.PairCounts
+	cards int
+	edges int
+}
+
+// graphStats summarizes a buildPairs run
+type graphStats struct {
+	seen       int
+	total      int
+	totalCards int
+	totalEdges int
+	skipped    map[string]int // collections skipped by date or format, by reason
+}
+
+// buildPairs counts the pairs of every collection under dataDir into
+// pairs, skipping collections the date policy drops and those formats
+// doesn't match. Collections are loaded and counted on up to workers goroutines
+// but merged in walk order, so the store ends up the same for any number
+// of workers. Progress lines go to out.
+func buildPairs(out io.Writer, dataDir string, workers int, pairs *pairStore, policy weightingPolicy, dates games.DatePolicy, formats games.FormatFilter) (graphStats, error) {
+	stats := graphStats{skipped: make(map[string]int)}
 
-	// Decompress if .zst
-	if filepath.Ext(path) == ".zst" {
-		data, err = zstd.Decompress(nil, data)
+	count := func(file string) collectionPairs {
+		col, err := game.LoadCollectionFile(file)
 		if err != nil {
-			return nil, err
+			return collectionPairs{err: err}
 		}
+		if !formats.Match(deckFormat(col)) {
+			return collectionPairs{skip: skipFormat}
+		}
+		decay, skip := dates.Weigh(col.EventDate(), col.ReleaseDate, col.ScrapedAt)
+		if skip != "" {
+			return collectionPairs{skip: skip}
+		}
+		cp := collectionPairs{pairs: make(map[graphio.Pair]*graphio.PairCounts)}
+		cp.cards, cp.edges = graphio.AddCollectionPairs(cp.pairs, col, policy.binary(col), decay)
+		return cp
 	}
 
-	var col game.Collection
-	if err := json.Unmarshal(data, &col); err != nil {
-		return nil, err
+	merge := func(file string, cp collectionPairs) error {
+		stats.seen++
+		if cp.err != nil {
+			fmt.Fprintf(out, "⚠️  [%d] Failed to load %s: %v\n", stats.seen, filepath.Base(file), cp.err)
+			return nil
+		}
+		if cp.skip != "" {
+			stats.skipped[cp.skip]++
+			return nil
+		}
+
+		graphio.MergePairs(pairs.mem, cp.pairs)
+		if err := pairs.checkpoint(); err != nil {
+			return fmt.Errorf("spilling pair counts: %w", err)
+		}
+
+		stats.total++
+		stats.totalCards += cp.cards
+		stats.totalEdges += cp.edges
+
+		// Progress with details
+		fmt.Fprintf(out, "✓ [%d] %s: %d cards, %d edges → %d unique pairs total\n",
+			stats.seen, filepath.Base(file), cp.cards, cp.edges, pairs.len())
+		return nil
 	}
 
-	return &col, nil
+	walk := func(emit func(string) error) error {
+		return cio.WalkCollectionFiles(dataDir, cio.FindOpts{}, emit)
+	}
+	err := cio.ProcessOrdered(workers, walk, count, merge)
+	return stats, err
 }
 
-func makePair(a, b string) pair {
-	if a > b {
-		a, b = b, a
+const skipFormat = "format"
+
+// deckFormat is col's deck format, or "" for sets, cubes and decks
+// without one
+func deckFormat(col *game.Collection) string {
+	if deck, ok := col.Type.Inner.(*game.CollectionTypeDeck); ok {
+		return deck.Format
 	}
-	return pair{card1: a, card2: b}
+	return ""
 }
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"collections/games"
+	"collections/games/magic/game"
+	"collections/graphio"
+	"collections/graphio/graphiotest"
+)
+
+func TestWeightingPolicyByType(t *testing.T) {
+	tests := []struct {
+		name string
+		col  *game.Collection
+		want bool
+	}{
+		{"cube", graphiotest.Collection(&game.CollectionTypeCube{}, 1, "A"), true},
+		{"set", graphiotest.Collection(&game.CollectionTypeSet{}, 1, "A"), true},
+		{"commander", graphiotest.Collection(&game.CollectionTypeDeck{Format: "EDH"}, 1, "A"), true},
+		{"duel commander", graphiotest.Collection(&game.CollectionTypeDeck{Format: "Duel Commander"}, 1, "A"), true},
+		{"modern", graphiotest.Collection(&game.CollectionTypeDeck{Format: "Modern"}, 4, "A"), false},
+	}
+	for _, tt := range tests {
+		if got := weightByType.binary(tt.col); got != tt.want {
+			t.Errorf("by-type binary(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+		if weightMultiset.binary(tt.col) {
+			t.Errorf("multiset binary(%s) = true", tt.name)
+		}
+		if !weightBinary.binary(tt.col) {
+			t.Errorf("binary binary(%s) = false", tt.name)
+		}
+	}
+}
+
+func TestParseWeightingPolicy(t *testing.T) {
+	if _, err := parseWeightingPolicy("by-type"); err != nil {
+		t.Errorf("parseWeightingPolicy(by-type) error = %v", err)
+	}
+	if _, err := parseWeightingPolicy("linear"); err == nil {
+		t.Error("parseWeightingPolicy(linear) error = nil")
+	}
+}
+
+func TestPairStoreSpillOutputIdentical(t *testing.T) {
+	cols := []*game.Collection{
+		graphiotest.Collection(&game.CollectionTypeDeck{Format: "Modern"}, 4, "Lightning Bolt", "Mountain", "Lava Spike"),
+		graphiotest.Collection(&game.CollectionTypeDeck{Format: "Modern"}, 2, "Lightning Bolt", "Fire // Ice", "A"),
+		graphiotest.Collection(&game.CollectionTypeCube{}, 1, "AB", "Lightning Bolt", "Mountain"),
+		graphiotest.Collection(&game.CollectionTypeDeck{Format: "Legacy"}, 3, "Brainstorm", "Ponder", "A"),
+	}
+
+	write := func(threshold int, format graphio.Format) []byte {
+		t.Helper()
+		pairs := newPairStore(threshold)
+		defer pairs.close()
+		for i, col := range cols {
+			graphio.AddCollectionPairs(pairs.mem, col, weightByType.binary(col), 1/float64(i+1))
+			if err := pairs.checkpoint(); err != nil {
+				t.Fatalf("checkpoint() error = %v", err)
+			}
+		}
+		if threshold > 0 && !pairs.spilled() {
+			t.Fatal("store did not spill")
+		}
+		var buf bytes.Buffer
+		w, err := graphio.NewEdgeWriter(&buf, format, &graphio.OptWriterWeight{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := pairs.writeEdges(w, true); err != nil {
+			t.Fatalf("writeEdges() error = %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if want := 18; pairs.len() != want {
+			t.Errorf("len() = %d, want %d", pairs.len(), want)
+		}
+		return buf.Bytes()
+	}
+
+	for _, format := range []graphio.Format{graphio.FormatCSV, graphio.FormatJSONL} {
+		mem := write(0, format)
+		spilled := write(2, format)
+		if !bytes.Equal(mem, spilled) {
+			t.Errorf("%s output differs after spilling:\n%s\nwant:\n%s", format, spilled, mem)
+		}
+	}
+}
+
+// buildCSV runs buildPairs over dir and returns the weighted CSV
+func buildCSV(tb testing.TB, dir string, workers int) []byte {
+	tb.Helper()
+	dates, err := games.NewDatePolicy("", 90, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	pairs := newPairStore(0)
+	defer pairs.close()
+	stats, err := buildPairs(io.Discard, dir, workers, pairs, weightMultiset, dates, nil)
+	if err != nil {
+		tb.Fatalf("buildPairs() error = %v", err)
+	}
+	// The undated cubes are skipped by the decay policy
+	if stats.total+stats.skipped[games.SkipEstimated] != stats.seen {
+		tb.Fatalf("processed %d of %d collections, skipped %v", stats.total, stats.seen, stats.skipped)
+	}
+	var buf bytes.Buffer
+	w, err := graphio.NewEdgeWriter(&buf, graphio.FormatCSV, &graphio.OptWriterWeight{})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := pairs.writeEdges(w, true); err != nil {
+		tb.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		tb.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestBuildPairsWorkersDeterministic(t *testing.T) {
+	dir := filepath.Dir(graphiotest.WriteCollectionTree(t, 2000)[0])
+
+	start := time.Now()
+	sequential := buildCSV(t, dir, 1)
+	elapsed := time.Since(start)
+
+	workers := max(8, runtime.NumCPU())
+	start = time.Now()
+	parallel := buildCSV(t, dir, workers)
+	t.Logf("2000 files: 1 worker %v, %d workers %v", elapsed, workers, time.Since(start))
+
+	// Byte-identical, weights included: merges happen in file order
+	if !bytes.Equal(sequential, parallel) {
+		t.Errorf("CSV differs between 1 and %d workers", workers)
+	}
+}
+
+func TestBuildPairsFormat(t *testing.T) {
+	dir := t.TempDir()
+	for i, col := range []*game.Collection{
+		graphiotest.Collection(&game.CollectionTypeDeck{Format: "Modern"}, 4, "Lightning Bolt", "Mountain"),
+		graphiotest.Collection(&game.CollectionTypeDeck{Format: "Commander"}, 1, "Sol Ring", "Command Tower"),
+		graphiotest.Collection(&game.CollectionTypeCube{}, 1, "Sol Ring", "Lightning Bolt"),
+	} {
+		data, err := json.Marshal(col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.json", i)), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pairs := newPairStore(0)
+	defer pairs.close()
+	stats, err := buildPairs(io.Discard, dir, 1, pairs, weightMultiset, games.DatePolicy{}, games.NewFormatFilter([]string{"modern"}))
+	if err != nil {
+		t.Fatalf("buildPairs() error = %v", err)
+	}
+	if stats.total != 1 || stats.skipped[skipFormat] != 2 {
+		t.Errorf("processed %d, skipped %v, want 1 and 2 for format", stats.total, stats.skipped)
+	}
+	var buf bytes.Buffer
+	w, err := graphio.NewEdgeWriter(&buf, graphio.FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pairs.writeEdges(w, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "NAME_1,NAME_2,COUNT_SET,COUNT_MULTISET\nLightning Bolt,Lightning Bolt,0,3\nLightning Bolt,Mountain,1,16\nMountain,Mountain,0,3\n"; buf.String() != want {
+		t.Errorf("pairs =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func BenchmarkBuildPairs(b *testing.B) {
+	dir := filepath.Dir(graphiotest.WriteCollectionTree(b, 3000)[0])
+	for _, workers := range []int{1, 4, runtime.NumCPU()} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				buildCSV(b, dir, workers)
+			}
+		})
+	}
+}
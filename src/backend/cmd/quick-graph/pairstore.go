@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"strings"
+
+	"collections/graphio"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+// pairStore accumulates pair counts in memory. With a spill threshold,
+// once the in-memory map holds more pairs it is merged into a temporary
+// badger database and cleared, so memory stays bounded on large inputs.
+type pairStore struct {
+	mem       map[graphio.Pair]*graphio.PairCounts
+	threshold int // pairs held in memory before spilling; 0 never spills
+	stored    int // distinct pairs in db
+	dir       string
+	db        *badger.DB
+}
+
+func newPairStore(threshold int) *pairStore {
+	return &pairStore{mem: make(map[graphio.Pair]*graphio.PairCounts), threshold: threshold}
+}
+
+// len returns the number of distinct pairs. It is exact until the store
+// spills; after that it may count a pair both in memory and on disk until
+// the next spill.
+func (s *pairStore) len() int {
+	return s.stored + len(s.mem)
+}
+
+func (s *pairStore) spilled() bool {
+	return s.db != nil
+}
+
+// checkpoint spills the in-memory pairs if they exceed the threshold.
+// Call it between collections.
+func (s *pairStore) checkpoint() error {
+	if s.threshold <= 0 || len(s.mem) <= s.threshold {
+		return nil
+	}
+	return s.spill()
+}
+
+// spill merges the in-memory pairs into the on-disk store and clears them
+func (s *pairStore) spill() error {
+	if s.db == nil {
+		dir, err := os.MkdirTemp("", "quick-graph")
+		if err != nil {
+			return err
+		}
+		opts := badger.DefaultOptions(dir)
+		opts.Logger = nil
+		db, err := badger.Open(opts)
+		if err != nil {
+			os.RemoveAll(dir)
+			return err
+		}
+		s.dir, s.db = dir, db
+	}
+
+	txn := s.db.NewTransaction(true)
+	defer func() { txn.Discard() }()
+	for p, c := range s.mem {
+		key := pairKey(p)
+		merged := *c
+		item, err := txn.Get(key)
+		switch err {
+		case nil:
+			if err := item.Value(func(v []byte) error {
+				merged.Add(decodeCounts(v))
+				return nil
+			}); err != nil {
+				return err
+			}
+		case badger.ErrKeyNotFound:
+			s.stored++
+		default:
+			return err
+		}
+		value := encodeCounts(merged)
+		if err := txn.Set(key, value); err == badger.ErrTxnTooBig {
+			if err := txn.Commit(); err != nil {
+				return err
+			}
+			txn = s.db.NewTransaction(true)
+			if err := txn.Set(key, value); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+	}
+	if err := txn.Commit(); err != nil {
+		return err
+	}
+	s.mem = make(map[graphio.Pair]*graphio.PairCounts)
+	return nil
+}
+
+// writeEdges writes every pair to w sorted by card names, the order of
+// graphio.SortEdges. Weight is set only when weighted.
+func (s *pairStore) writeEdges(w graphio.EdgeWriter, weighted bool) error {
+	edge := func(p graphio.Pair, c graphio.PairCounts) graphio.Edge {
+		e := graphio.Edge{
+			Card1:         p.Card1,
+			Card2:         p.Card2,
+			CountSet:      int64(c.Set),
+			CountMultiset: int64(c.Multiset),
+		}
+		if weighted {
+			e.Weight = c.Weight
+		}
+		return e
+	}
+
+	if !s.spilled() {
+		edges := make([]graphio.Edge, 0, len(s.mem))
+		for p, c := range s.mem {
+			edges = append(edges, edge(p, *c))
+		}
+		graphio.SortEdges(edges)
+		for _, e := range edges {
+			if err := w.Write(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Keys sort by card1 then card2, so iterating the db streams edges in
+	// order without holding them all
+	if err := s.spill(); err != nil {
+		return err
+	}
+	return s.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			p := parsePairKey(item.Key())
+			var c graphio.PairCounts
+			if err := item.Value(func(v []byte) error {
+				c = decodeCounts(v)
+				return nil
+			}); err != nil {
+				return err
+			}
+			if err := w.Write(edge(p, c)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *pairStore) close() error {
+	if s.db == nil {
+		return nil
+	}
+	err := s.db.Close()
+	if rmErr := os.RemoveAll(s.dir); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// pairKey joins the card names with a NUL, which sorts below any byte in a
+// card name (Canonicalize rejects control characters), so keys order like
+// (card1, card2)
+func pairKey(p graphio.Pair) []byte {
+	return []byte(p.Card1 + "\x00" + p.Card2)
+}
+
+func parsePairKey(key []byte) graphio.Pair {
+	card1, card2, _ := strings.Cut(string(key), "\x00")
+	return graphio.Pair{Card1: card1, Card2: card2}
+}
+
+func encodeCounts(c graphio.PairCounts) []byte {
+	b := make([]byte, 24)
+	binary.BigEndian.PutUint64(b[0:], uint64(c.Set))
+	binary.BigEndian.PutUint64(b[8:], uint64(c.Multiset))
+	binary.BigEndian.PutUint64(b[16:], math.Float64bits(c.Weight))
+	return b
+}
+
+func decodeCounts(b []byte) graphio.PairCounts {
+	return graphio.PairCounts{
+		Set:      int(binary.BigEndian.Uint64(b[0:])),
+		Multiset: int(binary.BigEndian.Uint64(b[8:])),
+		Weight:   math.Float64frombits(binary.BigEndian.Uint64(b[16:])),
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+// Re-parse collections from pages already in the scraper cache, so a parser
+// fix can be applied without re-scraping.
+//
+// Every cached page of the source's host whose URL is a collection page is
+// run through the dataset's ParsePage and the collection blob is rewritten.
+// Pages that fail to parse leave their existing collection untouched.
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"collections/blob"
+	"collections/games/magic/dataset/deckbox"
+	"collections/games/magic/dataset/goldfish"
+	"collections/games/magic/dataset/mtgtop8"
+	"collections/games/magic/dataset/scryfall"
+	"collections/games/magic/game"
+	"collections/logger"
+	"collections/scraper"
+)
+
+var (
+	bucketURL  = flag.String("bucket", "s3://games-collections", "Bucket holding the games/ and scraper/ prefixes")
+	cacheDir   = flag.String("cache", "", "Dir of the local blob cache, if any")
+	sourceFlag = flag.String("source", "", "Dataset to re-parse: "+strings.Join(sourceNames(), ", "))
+	dryRun     = flag.Bool("dry-run", false, "Parse pages and report without writing collections")
+	limit      = flag.Int("limit", 0, "Stop after this many collection pages (0 = all)")
+)
+
+// pageParser is implemented by datasets that can parse a fetched page
+type pageParser interface {
+	ParsePage(ctx context.Context, u string, body []byte) (*game.Collection, error)
+}
+
+// source describes where a dataset's pages are cached and how they are
+// parsed
+type source struct {
+	host  string
+	pages *regexp.Regexp // URLs of collection pages
+	key   func(id string) string
+	new   func(log *logger.Logger, b *blob.Bucket) pageParser
+
+	// keepDate keeps an existing collection's release date, for sources
+	// whose pages don't carry one
+	keepDate bool
+}
+
+var sources = map[string]source{
+	"mtgtop8": {
+		host:  "mtgtop8.com",
+		pages: regexp.MustCompile(`^https://mtgtop8\.com/event\?e=\d+&d=\d+`),
+		key:   mtgtop8.CollectionKey,
+		new: func(log *logger.Logger, b *blob.Bucket) pageParser {
+			return mtgtop8.NewDataset(log, b)
+		},
+	},
+	"goldfish": {
+		host:  "www.mtggoldfish.com",
+		pages: regexp.MustCompile(`^https://www\.mtggoldfish\.com/deck/\d+$`),
+		key:   goldfish.CollectionKey,
+		new: func(log *logger.Logger, b *blob.Bucket) pageParser {
+			return goldfish.NewDataset(log, b).(pageParser)
+		},
+	},
+	"deckbox": {
+		host:  "deckbox.org",
+		pages: regexp.MustCompile(`^https://deckbox\.org/sets/\d+$`),
+		key:   deckbox.CollectionKey,
+		new: func(log *logger.Logger, b *blob.Bucket) pageParser {
+			return deckbox.NewDataset(log, b).(pageParser)
+		},
+		keepDate: true,
+	},
+	"scryfall": {
+		host:  "scryfall.com",
+		pages: regexp.MustCompile(`^https://scryfall\.com/sets/[a-z0-9]+$`),
+		key:   scryfall.CollectionKey,
+		new: func(log *logger.Logger, b *blob.Bucket) pageParser {
+			return scryfall.NewDataset(log, b).(pageParser)
+		},
+	},
+}
+
+func sourceNames() []string {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func main() {
+	flag.Parse()
+	src, ok := sources[*sourceFlag]
+	if !ok {
+		fmt.Printf("Usage: reparse-cache --source %s [--bucket URL] [--cache DIR] [--dry-run] [--limit N]\n", strings.Join(sourceNames(), "|"))
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("INFO")
+
+	var bucketOpts []blob.BucketOption
+	if *cacheDir != "" {
+		bucketOpts = append(bucketOpts, &blob.OptBucketCache{Dir: *cacheDir})
+	}
+	bucket, err := blob.NewBucket(ctx, log, *bucketURL, bucketOpts...)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer bucket.Close(ctx)
+
+	if *dryRun {
+		fmt.Println("🔍 DRY RUN MODE - no collections will be written")
+	}
+	result, err := reparse(ctx, log, bucket, src, reparseOptions{DryRun: *dryRun, Limit: *limit})
+	fmt.Printf("\n📊 Re-parsed %s pages from %s:\n", *sourceFlag, src.host)
+	fmt.Printf("   Parsed:  %d\n", result.Parsed)
+	fmt.Printf("   Failed:  %d\n", result.Failed)
+	fmt.Printf("   Skipped: %d (not collection pages or no cached body)\n", result.Skipped)
+	for _, f := range result.Failures {
+		fmt.Printf("   ❌ %s: %v\n", f.URL, f.Err)
+	}
+	if n := result.Failed - len(result.Failures); n > 0 {
+		fmt.Printf("   ... and %d more failures\n", n)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+type reparseOptions struct {
+	DryRun bool
+	Limit  int
+}
+
+// maxFailures is how many failures a reparseResult lists
+const maxFailures = 20
+
+type reparseResult struct {
+	Parsed   int
+	Failed   int
+	Skipped  int
+	Failures []reparseFailure // The first maxFailures failures
+}
+
+type reparseFailure struct {
+	URL string
+	Err error
+}
+
+// reparse parses every cached collection page of src in bucket's scraper/
+// prefix and writes the collections under its games/ prefix
+func reparse(ctx context.Context, log *logger.Logger, bucket *blob.Bucket, src source, opts reparseOptions) (reparseResult, error) {
+	gamesBlob := bucket.WithPrefix("games/")
+	scraperBlob := bucket.WithPrefix("scraper/")
+	sc := scraper.NewScraper(log, scraperBlob)
+	parser := src.new(log, gamesBlob)
+
+	var result reparseResult
+	fail := func(u string, err error) {
+		result.Failed++
+		if len(result.Failures) < maxFailures {
+			result.Failures = append(result.Failures, reparseFailure{URL: u, Err: err})
+		}
+	}
+
+	it := scraperBlob.List(ctx, &blob.OptListPrefix{Prefix: src.host + "/"})
+	for it.Next(ctx) {
+		if opts.Limit > 0 && result.Parsed+result.Failed >= opts.Limit {
+			break
+		}
+		key := it.Key()
+		if path.Ext(key) != ".json" {
+			continue
+		}
+		page, err := sc.ReadPage(ctx, key)
+		if err != nil {
+			fail(key, err)
+			continue
+		}
+		if page == nil {
+			result.Skipped++
+			continue
+		}
+		// Datasets may fetch with a fragment, as goldfish does with #paper
+		u, _, _ := strings.Cut(page.Request.URL, "#")
+		if !src.pages.MatchString(u) {
+			result.Skipped++
+			continue
+		}
+
+		col, err := parser.ParsePage(ctx, u, page.Response.Body)
+		if err != nil {
+			fail(u, err)
+			continue
+		}
+		bkey := src.key(col.ID)
+		if src.keepDate {
+			if err := keepReleaseDate(ctx, gamesBlob, bkey, col); err != nil {
+				fail(u, err)
+				continue
+			}
+		}
+		if !opts.DryRun {
+			data, err := json.Marshal(col)
+			if err != nil {
+				fail(u, err)
+				continue
+			}
+			if err := gamesBlob.Write(ctx, bkey, data); err != nil {
+				return result, fmt.Errorf("failed to write %s: %w", bkey, err)
+			}
+		}
+		result.Parsed++
+	}
+	return result, it.Err()
+}
+
+// keepReleaseDate gives col the release date of the collection already
+// stored under bkey, if any
+func keepReleaseDate(ctx context.Context, b *blob.Bucket, bkey string, col *game.Collection) error {
+	data, err := b.Read(ctx, bkey)
+	if blob.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	old, err := game.LoadCollectionBytes(data)
+	if err != nil {
+		return fmt.Errorf("failed to read existing collection: %w", err)
+	}
+	col.ReleaseDate = old.ReleaseDate
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"collections/blob"
+	"collections/games/magic/dataset/mtgtop8"
+	"collections/games/magic/game"
+	"collections/scraper"
+)
+
+const deckPage = `<html><head><title>Boros Energy - MTGTop8</title></head><body>
+<div class="S14"><div class="meta_arch">Modern</div></div>
+<div style="display:flex"><div align=left>
+<div class="O14">LANDS</div>
+<div class="deck_line hover_tr">8 <span class="L14">Mountain</span></div>
+</div></div>
+</body></html>`
+
+func writePage(t *testing.T, b *blob.Bucket, key, u, body string) {
+	t.Helper()
+	page := scraper.Page{
+		ScrapedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Request:   scraper.PageRequest{URL: u, Method: "GET"},
+		Response:  scraper.PageResponse{StatusCode: 200, Body: []byte(body)},
+	}
+	data, err := json.Marshal(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Write(context.Background(), key, data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReparse(t *testing.T) {
+	ctx := context.Background()
+	bucket := blob.NewMemBucket(ctx, nil)
+	scraperBlob := bucket.WithPrefix("scraper/")
+	writePage(t, scraperBlob, "mtgtop8.com/deck.json", "https://mtgtop8.com/event?e=1&d=2&f=MO", deckPage)
+	writePage(t, scraperBlob, "mtgtop8.com/broken.json", "https://mtgtop8.com/event?e=1&d=3&f=MO", `<html><body>no cards</body></html>`)
+	writePage(t, scraperBlob, "mtgtop8.com/listing.json", "https://mtgtop8.com/format?f=MO", `<html></html>`)
+	writePage(t, scraperBlob, "deckbox.org/other.json", "https://deckbox.org/sets/1", `<html></html>`)
+
+	src := sources["mtgtop8"]
+	gamesBlob := bucket.WithPrefix("games/")
+	key := mtgtop8.CollectionKey("1.2")
+
+	result, err := reparse(ctx, nil, bucket, src, reparseOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("reparse(dry run) error = %v", err)
+	}
+	if result.Parsed != 1 || result.Failed != 1 || result.Skipped != 1 {
+		t.Errorf("reparse(dry run) = %+v, want 1 parsed, 1 failed, 1 skipped", result)
+	}
+	if len(result.Failures) != 1 || result.Failures[0].URL != "https://mtgtop8.com/event?e=1&d=3&f=MO" {
+		t.Errorf("failures = %+v, want the broken deck", result.Failures)
+	}
+	if ok, _ := gamesBlob.Exists(ctx, key); ok {
+		t.Error("dry run wrote a collection")
+	}
+
+	if _, err := reparse(ctx, nil, bucket, src, reparseOptions{}); err != nil {
+		t.Fatalf("reparse() error = %v", err)
+	}
+	data, err := gamesBlob.Read(ctx, key)
+	if err != nil {
+		t.Fatalf("collection not written: %v", err)
+	}
+	col, err := game.LoadCollectionBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if col.URL != "https://mtgtop8.com/event?e=1&d=2&f=MO" || len(col.Partitions) != 1 || col.Partitions[0].Cards[0].Name != "Mountain" {
+		t.Errorf("collection = %+v", col)
+	}
+}
@@ -0,0 +1,427 @@
+package main
+
+// Draw a stratified random sample from exported JSONL files, so a training
+// set isn't dominated by the most-scraped formats, archetypes or sources.
+//
+// Records are grouped into strata by the --by fields, each stratum gets a
+// share of --size and that many of its records are picked at random. The
+// sample is the same for the same inputs and --seed, and keeps input order.
+//
+// With --bucket the inputs are collection prefixes under games/ (such as
+// magic/mtgtop8/), read in a single pass and written as export-blob
+// records, by stratum and URL. Each stratum keeps a reservoir of at most --size records, so a
+// corpus too large to materialize can be sampled.
+//
+// Exports carry no game field, so an input can be labelled with its game
+// as game=path.jsonl; a record's own "game" field takes precedence.
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"collections/blob"
+	"collections/cio"
+	"collections/games"
+	_ "collections/games/digimon/game"   // Register collection types
+	_ "collections/games/magic/game"     // Register collection types
+	_ "collections/games/onepiece/game"  // Register collection types
+	_ "collections/games/pokemon/game"   // Register collection types
+	_ "collections/games/riftbound/game" // Register collection types
+	_ "collections/games/yugioh/game"    // Register collection types
+	"collections/logger"
+)
+
+var (
+	size       = flag.Int("size", 10000, "Records to sample")
+	by         = flag.String("by", "format,archetype", "Comma-separated record fields to stratify on, e.g. format,archetype or game,source")
+	seed       = flag.Int64("seed", 1, "Random seed; the same seed and inputs give the same sample")
+	allocation = flag.String("allocation", allocProportional, "How --size is split across strata: proportional or equal")
+	bucketURL  = flag.String("bucket", "", "Sample collections from this bucket instead of JSONL files")
+	parallel   = flag.Int("parallel", 64, "Collections read concurrently with --bucket")
+)
+
+const (
+	// allocProportional gives each stratum its share of the input
+	allocProportional = "proportional"
+	// allocEqual gives every stratum the same number of records, handing
+	// what small strata can't fill to the larger ones
+	allocEqual = "equal"
+)
+
+// input is a JSONL file to sample from, with the game its records belong to
+type input struct {
+	path string
+	game string // Empty when not given
+}
+
+// parseInput parses an input argument of the form [game=]path
+func parseInput(arg string) input {
+	if game, path, ok := strings.Cut(arg, "="); ok && game != "" && !strings.ContainsAny(game, `/\`) {
+		return input{path: path, game: game}
+	}
+	return input{path: arg}
+}
+
+// recordRef locates a record by input and 0-based line
+type recordRef struct {
+	input int
+	line  int64
+}
+
+// stratum is the records sharing one value of the --by fields
+type stratum struct {
+	key   string
+	seen  int
+	quota int
+}
+
+type stats struct {
+	records   int
+	malformed int // Skipped
+	empty     int // Collections without cards, skipped
+	written   int
+	strata    []*stratum // Sorted by key
+}
+
+// forEachLine calls fn with every non-blank line of the file at path and
+// its 0-based line number
+func forEachLine(path string, fn func(line int64, data []byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := cio.NewJSONLScanner(f)
+	var line int64
+	for ; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		if err := fn(line, sc.Bytes()); err != nil {
+			return fmt.Errorf("line %d: %w", line+1, err)
+		}
+	}
+	return sc.Err()
+}
+
+// stratumKey returns the values of fields, as looked up by value, joined
+// with " / ". Missing fields count as empty; "game" falls back to game.
+func stratumKey(fields []string, game string, value func(field string) (string, bool)) string {
+	values := make([]string, len(fields))
+	for i, field := range fields {
+		if v, ok := value(field); ok {
+			values[i] = v
+		} else if field == "game" {
+			values[i] = game
+		}
+	}
+	return strings.Join(values, " / ")
+}
+
+// jsonValue looks up fields of a JSONL record; strings are unquoted and
+// null counts as missing
+func jsonValue(rec map[string]json.RawMessage) func(string) (string, bool) {
+	return func(field string) (string, bool) {
+		raw, ok := rec[field]
+		if !ok || bytes.Equal(raw, []byte("null")) {
+			return "", false
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return s, true
+		}
+		return string(raw), true
+	}
+}
+
+// recordValue looks up fields of a record built by games.ExportRecord
+func recordValue(rec map[string]any) func(string) (string, bool) {
+	return func(field string) (string, bool) {
+		v, ok := rec[field]
+		if !ok || v == nil {
+			return "", false
+		}
+		return fmt.Sprint(v), true
+	}
+}
+
+// strataOf returns the strata of sampler, sorted by key, with quotas
+// allocated from size
+func strataOf[T any](sampler *games.ReservoirSampler[T], size int, policy string) ([]*stratum, error) {
+	var strata []*stratum
+	for _, key := range sampler.Strata() {
+		strata = append(strata, &stratum{key: key, seen: sampler.Seen(key)})
+	}
+	return strata, allocate(strata, size, policy)
+}
+
+// allocate sets the quota of each stratum so they sum to size, or to every
+// record when there are fewer than size
+func allocate(strata []*stratum, size int, policy string) error {
+	total := 0
+	for _, s := range strata {
+		total += s.seen
+	}
+	if size >= total {
+		for _, s := range strata {
+			s.quota = s.seen
+		}
+		return nil
+	}
+
+	switch policy {
+	case allocProportional:
+		// Largest remainder: floor every share, then hand the records left
+		// over to the strata with the largest fractional parts
+		type share struct {
+			s   *stratum
+			rem int
+		}
+		shares := make([]share, len(strata))
+		left := size
+		for i, s := range strata {
+			n := s.seen * size
+			s.quota = n / total
+			left -= s.quota
+			shares[i] = share{s, n % total}
+		}
+		sort.SliceStable(shares, func(i, j int) bool { return shares[i].rem > shares[j].rem })
+		for i := 0; i < left; i++ {
+			shares[i].s.quota++
+		}
+	case allocEqual:
+		// Fill every open stratum evenly until size is reached, in key order
+		// so the extra records go to the same strata every time
+		for _, s := range strata {
+			s.quota = 0
+		}
+		left := size
+		for left > 0 {
+			var open []*stratum
+			for _, s := range strata {
+				if s.quota < s.seen {
+					open = append(open, s)
+				}
+			}
+			each := left / len(open)
+			if each == 0 {
+				each = 1
+			}
+			for _, s := range open {
+				n := min(each, s.seen-s.quota, left)
+				s.quota += n
+				left -= n
+				if left == 0 {
+					break
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("unknown allocation %q (supported: proportional, equal)", policy)
+	}
+	return nil
+}
+
+// sample writes a stratified sample of size records from inputs to w.
+// Strata are the distinct values of fields; malformed lines are skipped.
+// Only the sampled line numbers are held in memory; the sampled lines are
+// copied in a second pass, keeping input order.
+func sample(inputs []input, w io.Writer, fields []string, size int, policy string, seed int64) (*stats, error) {
+	st := &stats{}
+	sampler := games.NewReservoirSampler[recordRef](size, seed)
+	for i, in := range inputs {
+		err := forEachLine(in.path, func(line int64, data []byte) error {
+			var rec map[string]json.RawMessage
+			if err := json.Unmarshal(data, &rec); err != nil {
+				st.malformed++
+				return nil
+			}
+			st.records++
+			ref := recordRef{input: i, line: line}
+			sampler.Add(stratumKey(fields, in.game, jsonValue(rec)), fmt.Sprintf("%d:%d", i, line), ref)
+			return nil
+		})
+		if err != nil {
+			return st, fmt.Errorf("%s: %w", in.path, err)
+		}
+	}
+	strata, err := strataOf(sampler, size, policy)
+	st.strata = strata
+	if err != nil {
+		return st, err
+	}
+
+	picked := make(map[recordRef]bool)
+	for _, s := range st.strata {
+		for _, ref := range sampler.Sample(s.key, s.quota) {
+			picked[ref] = true
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for i, in := range inputs {
+		err := forEachLine(in.path, func(line int64, data []byte) error {
+			if !picked[recordRef{input: i, line: line}] {
+				return nil
+			}
+			st.written++
+			if _, err := bw.Write(data); err != nil {
+				return err
+			}
+			return bw.WriteByte('\n')
+		})
+		if err != nil {
+			return st, fmt.Errorf("%s: %w", in.path, err)
+		}
+	}
+	return st, bw.Flush()
+}
+
+// sampleBucket writes a stratified sample of size collections under the
+// prefixes of b to w, as export-blob records, in one pass over each prefix.
+// Memory is bounded by the sample, not the corpus. The game of a prefix
+// defaults to its first path segment.
+func sampleBucket(ctx context.Context, b *blob.Bucket, prefixes []input, w io.Writer, fields []string, size int, policy string, seed int64, parallel int) (*stats, error) {
+	st := &stats{}
+	sampler := games.NewReservoirSampler[map[string]any](size, seed)
+	var empty atomic.Int64
+	for _, in := range prefixes {
+		game := in.game
+		if game == "" {
+			game, _, _ = strings.Cut(in.path, "/")
+		}
+		err := games.IterItemsBlobPrefix(ctx, b, in.path, games.DeserializeAsCollection, func(item games.Item) error {
+			ci, ok := item.(*games.CollectionItem)
+			if !ok {
+				return nil
+			}
+			rec := games.ExportRecord(ci.Collection)
+			if rec == nil {
+				empty.Add(1)
+				return nil
+			}
+			sampler.Add(stratumKey(fields, game, recordValue(rec)), ci.Collection.URL, rec)
+			return nil
+		}, &games.OptIterItemsParallel{Parallel: parallel})
+		if err != nil {
+			return st, fmt.Errorf("%s: %w", in.path, err)
+		}
+	}
+	st.empty = int(empty.Load())
+	strata, err := strataOf(sampler, size, policy)
+	st.strata = strata
+	if err != nil {
+		return st, err
+	}
+	for _, s := range st.strata {
+		st.records += s.seen
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, s := range st.strata {
+		recs := sampler.Sample(s.key, s.quota)
+		sort.Slice(recs, func(i, j int) bool { return fmt.Sprint(recs[i]["url"]) < fmt.Sprint(recs[j]["url"]) })
+		for _, rec := range recs {
+			if err := enc.Encode(rec); err != nil {
+				return st, err
+			}
+			st.written++
+		}
+	}
+	return st, bw.Flush()
+}
+
+func main() {
+	flag.Parse()
+	args := flag.Args()
+	if len(args) < 2 {
+		fmt.Println("Usage: sample-export [flags] <out.jsonl> <[game=]in.jsonl>...")
+		fmt.Println("       sample-export [flags] --bucket <bucket-url> <out.jsonl> <[game=]prefix>...")
+		fmt.Println("Example: sample-export --size 5000 --by game,source sample.jsonl magic=magic.jsonl pokemon=pokemon.jsonl")
+		fmt.Println("Example: sample-export --bucket s3://games-collections sample.jsonl magic/mtgtop8/ magic/goldfish/")
+		fmt.Println()
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	outPath := args[0]
+	var inputs []input
+	for _, arg := range args[1:] {
+		in := parseInput(arg)
+		if *bucketURL == "" && in.path == outPath {
+			fmt.Println("Error: input and output must be different files")
+			os.Exit(1)
+		}
+		inputs = append(inputs, in)
+	}
+	var fields []string
+	for _, f := range strings.Split(*by, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 || *size <= 0 {
+		fmt.Println("Error: --by needs at least one field and --size must be positive")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	var bucket *blob.Bucket
+	if *bucketURL != "" {
+		log := logger.NewLogger(ctx)
+		log.SetLevel("INFO")
+		var err error
+		bucket, err = blob.NewBucket(ctx, log, *bucketURL)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer bucket.Close(ctx)
+	}
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	var st *stats
+	if bucket != nil {
+		st, err = sampleBucket(ctx, bucket.WithPrefix("games/"), inputs, out, fields, *size, *allocation, *seed, *parallel)
+	} else {
+		st, err = sample(inputs, out, fields, *size, *allocation, *seed)
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✓ Sampled %d inputs by %s (%s, seed %d)\n", len(inputs), strings.Join(fields, ", "), *allocation, *seed)
+	fmt.Printf("   Records read: %d\n", st.records)
+	if st.malformed > 0 {
+		fmt.Printf("   ⚠️  Malformed lines skipped: %d\n", st.malformed)
+	}
+	if st.empty > 0 {
+		fmt.Printf("   Collections without cards skipped: %d\n", st.empty)
+	}
+	fmt.Printf("   Strata: %d\n", len(st.strata))
+	for _, s := range st.strata {
+		key := s.key
+		if strings.Trim(key, " /") == "" {
+			key = "(none)"
+		}
+		fmt.Printf("     %-40s %6d / %d\n", key, s.quota, s.seen)
+	}
+	fmt.Printf("✅ Wrote %d records to %s\n", st.written, outPath)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"collections/blob"
+	"collections/games"
+	ygo "collections/games/yugioh/game"
+	"collections/logger"
+)
+
+// writeExport writes n records of each format/archetype in counts to a
+// JSONL file and returns its path
+func writeExport(t *testing.T, name string, counts map[string]int) string {
+	t.Helper()
+	var b strings.Builder
+	id := 0
+	for key, n := range counts {
+		format, archetype, _ := strings.Cut(key, "/")
+		for i := 0; i < n; i++ {
+			id++
+			fmt.Fprintf(&b, `{"deck_id":"%s-%d","format":%q,"archetype":%q,"source":"mtgtop8"}`+"\n", name, id, format, archetype)
+		}
+	}
+	path := filepath.Join(t.TempDir(), name+".jsonl")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// countBy counts the sampled records per field value
+func countBy(t *testing.T, out []byte, field string) map[string]int {
+	t.Helper()
+	counts := make(map[string]int)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("sampled line %q: %v", line, err)
+		}
+		counts[fmt.Sprint(rec[field])]++
+	}
+	return counts
+}
+
+func TestSampleProportional(t *testing.T) {
+	in := writeExport(t, "magic", map[string]int{
+		"Modern/Burn":     600,
+		"Modern/Tron":     300,
+		"Legacy/Delver":   90,
+		"Pauper/Affinity": 10,
+	})
+	fields := []string{"format", "archetype"}
+
+	var out bytes.Buffer
+	st, err := sample([]input{{path: in}}, &out, fields, 100, allocProportional, 7)
+	if err != nil {
+		t.Fatalf("sample() error = %v", err)
+	}
+	if st.records != 1000 || st.written != 100 || len(st.strata) != 4 {
+		t.Fatalf("sample() stats = records %d, written %d, strata %d", st.records, st.written, len(st.strata))
+	}
+	want := map[string]int{"Burn": 60, "Tron": 30, "Delver": 9, "Affinity": 1}
+	if got := countBy(t, out.Bytes(), "archetype"); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("sampled archetypes = %v, want %v", got, want)
+	}
+
+	var again bytes.Buffer
+	if _, err := sample([]input{{path: in}}, &again, fields, 100, allocProportional, 7); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Bytes(), out.Bytes()) {
+		t.Error("sample() with the same seed gave a different sample")
+	}
+	var other bytes.Buffer
+	if _, err := sample([]input{{path: in}}, &other, fields, 100, allocProportional, 8); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(other.Bytes(), out.Bytes()) {
+		t.Error("sample() with another seed gave the same sample")
+	}
+}
+
+func TestSampleEqualByGame(t *testing.T) {
+	magic := writeExport(t, "magic", map[string]int{"Modern/Burn": 50})
+	pokemon := writeExport(t, "pokemon", map[string]int{"Standard/Lugia": 3})
+
+	var out bytes.Buffer
+	inputs := []input{parseInput("magic=" + magic), parseInput("pokemon=" + pokemon)}
+	st, err := sample(inputs, &out, []string{"game", "source"}, 20, allocEqual, 1)
+	if err != nil {
+		t.Fatalf("sample() error = %v", err)
+	}
+	// Pokemon only has 3 records, so Magic fills the rest
+	want := map[string]int{"Burn": 17, "Lugia": 3}
+	if got := countBy(t, out.Bytes(), "archetype"); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("sampled archetypes = %v, want %v", got, want)
+	}
+	if st.strata[0].key != "magic / mtgtop8" {
+		t.Errorf("first stratum = %q, want %q", st.strata[0].key, "magic / mtgtop8")
+	}
+}
+
+func TestSampleSizeAboveInput(t *testing.T) {
+	in := writeExport(t, "magic", map[string]int{"Modern/Burn": 4, "Legacy/Delver": 2})
+	var out bytes.Buffer
+	st, err := sample([]input{{path: in}}, &out, []string{"format"}, 100, allocProportional, 1)
+	if err != nil {
+		t.Fatalf("sample() error = %v", err)
+	}
+	if st.written != 6 {
+		t.Errorf("sample() wrote %d records, want all 6", st.written)
+	}
+	if _, err := sample([]input{{path: in}}, &bytes.Buffer{}, []string{"format"}, 1, "random", 1); err == nil {
+		t.Error("sample(random) error = nil, want error")
+	}
+}
+
+func TestSampleBucket(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+
+	formats := map[string]int{"TCG": 30, "OCG": 10}
+	for format, n := range formats {
+		for i := 0; i < n; i++ {
+			c := games.Collection{
+				ID:          fmt.Sprintf("%s-%d", format, i),
+				URL:         fmt.Sprintf("https://ygoprodeck.com/deck/%s-%d", format, i),
+				Type:        games.CollectionTypeWrapper{Type: "YGODeck", Inner: &ygo.CollectionTypeDeck{Name: "Test", Format: format}},
+				ReleaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				Partitions:  []games.Partition{{Name: "Main Deck", Cards: []games.CardDesc{{Name: "Ash Blossom & Joyous Spring", Count: 3}}}},
+				Source:      games.SourceYGOPRODeck,
+			}
+			data, err := json.Marshal(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := bucket.Write(ctx, fmt.Sprintf("games/yugioh/ygoprodeck/%s.json", c.ID), data); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	run := func(parallel int) []byte {
+		var out bytes.Buffer
+		st, err := sampleBucket(ctx, bucket.WithPrefix("games/"), []input{parseInput("yugioh/ygoprodeck/")}, &out, []string{"game", "format"}, 8, allocProportional, 3, parallel)
+		if err != nil {
+			t.Fatalf("sampleBucket() error = %v", err)
+		}
+		if st.records != 40 || st.written != 8 {
+			t.Fatalf("sampleBucket() read %d, wrote %d, want 40, 8", st.records, st.written)
+		}
+		if st.strata[0].key != "yugioh / OCG" {
+			t.Errorf("first stratum = %q, want %q", st.strata[0].key, "yugioh / OCG")
+		}
+		return out.Bytes()
+	}
+	out := run(8)
+	if got, want := countBy(t, out, "format"), map[string]int{"TCG": 6, "OCG": 2}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("sampled formats = %v, want %v", got, want)
+	}
+	// Parallel reads arrive in any order but sample the same records
+	if again := run(1); !bytes.Equal(again, out) {
+		t.Errorf("sampleBucket() differs between parallel runs:\n%s\n%s", out, again)
+	}
+}
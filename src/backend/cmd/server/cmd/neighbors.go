@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"collections/graphio"
+)
+
+const (
+	defaultNeighbors = 20
+	maxNeighbors     = 1000
+)
+
+// neighborMetrics scores an edge for /neighbors
+var neighborMetrics = map[string]func(g *cardGraph, e graphio.Edge) float64{
+	"count":    func(_ *cardGraph, e graphio.Edge) float64 { return float64(e.CountSet) },
+	"multiset": func(_ *cardGraph, e graphio.Edge) float64 { return float64(e.CountMultiset) },
+	"weight":   func(_ *cardGraph, e graphio.Edge) float64 { return e.Weight },
+	"pmi":      (*cardGraph).pmi,
+}
+
+// cardGraph indexes co-occurrence edges by card
+type cardGraph struct {
+	edges map[string][]graphio.Edge // Card -> its edges
+	names map[string]string         // Lowercased name -> card
+	// marginal is each card's summed COUNT_SET over its edges and total
+	// the sum over both ends of every edge, for PMI
+	marginal map[string]float64
+	total    float64
+}
+
+func newCardGraph(edges []graphio.Edge) *cardGraph {
+	g := &cardGraph{
+		edges:    make(map[string][]graphio.Edge),
+		names:    make(map[string]string),
+		marginal: make(map[string]float64),
+	}
+	for _, e := range edges {
+		if e.Card1 == e.Card2 {
+			continue
+		}
+		for _, card := range []string{e.Card1, e.Card2} {
+			g.edges[card] = append(g.edges[card], e)
+			g.names[strings.ToLower(card)] = card
+			g.marginal[card] += float64(e.CountSet)
+		}
+		g.total += 2 * float64(e.CountSet)
+	}
+	return g
+}
+
+// lookup resolves a queried card name, ignoring case
+func (g *cardGraph) lookup(name string) (string, bool) {
+	if _, ok := g.edges[name]; ok {
+		return name, true
+	}
+	card, ok := g.names[strings.ToLower(strings.TrimSpace(name))]
+	return card, ok
+}
+
+// pmi is log(P(a,b) / P(a)P(b)) with probabilities taken from the edge
+// counts themselves, so cards that are everywhere (basic lands) don't
+// dominate every list
+func (g *cardGraph) pmi(e graphio.Edge) float64 {
+	if e.CountSet <= 0 {
+		return math.Inf(-1)
+	}
+	return math.Log(float64(e.CountSet) * g.total / (g.marginal[e.Card1] * g.marginal[e.Card2]))
+}
+
+// neighbor is one related card in a /neighbors response
+type neighbor struct {
+	Card          string  `json:"card"`
+	Score         float64 `json:"score"`
+	CountSet      int64   `json:"count_set"`
+	CountMultiset int64   `json:"count_multiset"`
+}
+
+type neighborsResponse struct {
+	Card      string     `json:"card"`
+	Metric    string     `json:"metric"`
+	Neighbors []neighbor `json:"neighbors"`
+}
+
+// neighbors returns card's k best neighbors by metric, best first, ties
+// by name. card itself is never among them.
+func (g *cardGraph) neighbors(card string, k int, metric func(*cardGraph, graphio.Edge) float64) []neighbor {
+	var ns []neighbor
+	for _, e := range g.edges[card] {
+		other := e.Card2
+		if other == card {
+			other = e.Card1
+		}
+		ns = append(ns, neighbor{
+			Card:          other,
+			Score:         metric(g, e),
+			CountSet:      e.CountSet,
+			CountMultiset: e.CountMultiset,
+		})
+	}
+	sort.Slice(ns, func(i, j int) bool {
+		if ns[i].Score != ns[j].Score {
+			return ns[i].Score > ns[j].Score
+		}
+		return ns[i].Card < ns[j].Card
+	})
+	if len(ns) > k {
+		ns = ns[:k]
+	}
+	return ns
+}
+
+// neighborsHandler serves GET /neighbors?card=NAME&k=N&metric=M from a
+// graph loaded at startup. metric is count (COUNT_SET, the default),
+// multiset, weight or pmi.
+type neighborsHandler struct {
+	graph *cardGraph
+}
+
+func (h *neighborsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.graph == nil {
+		http.Error(w, "no graph loaded: start the server with --graph", http.StatusServiceUnavailable)
+		return
+	}
+	q := r.URL.Query()
+	card, ok := h.graph.lookup(q.Get("card"))
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown card %q", q.Get("card")), http.StatusNotFound)
+		return
+	}
+	k := defaultNeighbors
+	if s := q.Get("k"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxNeighbors {
+			http.Error(w, fmt.Sprintf("invalid k %q (1-%d)", s, maxNeighbors), http.StatusBadRequest)
+			return
+		}
+		k = n
+	}
+	name := q.Get("metric")
+	if name == "" {
+		name = "count"
+	}
+	metric, ok := neighborMetrics[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown metric %q (count, multiset, weight, pmi)", name), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(neighborsResponse{
+		Card:      card,
+		Metric:    name,
+		Neighbors: h.graph.neighbors(card, k, metric),
+	})
+}
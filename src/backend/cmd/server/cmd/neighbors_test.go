@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"collections/graphio"
+)
+
+func TestNeighborsHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pairs.csv")
+	const csv = `NAME_1,NAME_2,COUNT_SET,COUNT_MULTISET
+Lightning Bolt,Mountain,40,3200
+Chain Lightning,Lightning Bolt,10,160
+Lightning Bolt,Rift Bolt,10,160
+Lightning Bolt,Lightning Bolt,50,200
+Island,Mountain,100,4000
+Chain Lightning,Rift Bolt,2,16
+`
+	if err := os.WriteFile(path, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	edges, err := graphio.ReadFile(path, graphio.FormatFromPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &neighborsHandler{graph: newCardGraph(edges)}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// Ties are broken by name
+		{"card=Lightning+Bolt", []string{"Mountain", "Chain Lightning", "Rift Bolt"}},
+		{"card=lightning+bolt&k=2", []string{"Mountain", "Chain Lightning"}},
+		// Mountain is everywhere, so PMI ranks it last
+		{"card=Lightning+Bolt&metric=pmi", []string{"Chain Lightning", "Rift Bolt", "Mountain"}},
+		{"card=Island", []string{"Mountain"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/neighbors?"+tt.query, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("GET /neighbors?%s = %d: %s", tt.query, rec.Code, rec.Body)
+			}
+			var resp neighborsResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("payload %s: %v", rec.Body, err)
+			}
+			var got []string
+			for _, n := range resp.Neighbors {
+				if n.Card == resp.Card {
+					t.Errorf("query card %s is among its neighbors", n.Card)
+				}
+				got = append(got, n.Card)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("neighbors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for query, code := range map[string]int{
+		"card=Black+Lotus":                  http.StatusNotFound,
+		"card=Lightning+Bolt&k=0":           http.StatusBadRequest,
+		"card=Lightning+Bolt&metric=cosine": http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/neighbors?"+query, nil))
+		if rec.Code != code {
+			t.Errorf("GET /neighbors?%s = %d, want %d", query, rec.Code, code)
+		}
+	}
+	rec := httptest.NewRecorder()
+	(&neighborsHandler{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/neighbors?card=Island", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET /neighbors without a graph = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
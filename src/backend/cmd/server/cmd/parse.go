@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"collections/blob"
+	"collections/games/magic/dataset/goldfish"
+	"collections/games/magic/dataset/mtgtop8"
+	"collections/games/magic/game"
+	"collections/logger"
+	"collections/scraper"
+)
+
+// maxParseBody is the largest decklist /parse accepts
+const maxParseBody = 1 << 20
+
+// pageParser is implemented by datasets that can parse a fetched page
+type pageParser interface {
+	ParsePage(ctx context.Context, u string, body []byte) (*game.Collection, error)
+}
+
+// deckPage is a supported deck URL and the dataset that parses it
+type deckPage struct {
+	pages  *regexp.Regexp
+	parser pageParser
+}
+
+// parseHandler serves POST /parse. The body is a plain-text decklist
+// ("4 Lightning Bolt" lines, a blank or "Sideboard" line before the
+// sideboard) or the URL of a supported deck page, and the response is the
+// parsed collection. Decklists take their name and format from the
+// name and format query parameters.
+type parseHandler struct {
+	log   *logger.Logger
+	pages []deckPage
+	fetch func(ctx context.Context, u string) ([]byte, error)
+	now   func() time.Time
+}
+
+// newParseHandler returns a parseHandler that fetches deck pages through a
+// scraper caching under b's scraper/ prefix, so pages already scraped are
+// not fetched again
+func newParseHandler(log *logger.Logger, b *blob.Bucket) *parseHandler {
+	sc := scraper.NewScraper(log, b.WithPrefix("scraper/"))
+	gamesBlob := b.WithPrefix("games/")
+	return &parseHandler{
+		log: log,
+		pages: []deckPage{
+			{
+				pages:  regexp.MustCompile(`^https://(www\.)?mtgtop8\.com/event\?e=\d+&d=\d+`),
+				parser: mtgtop8.NewDataset(log, gamesBlob),
+			},
+			{
+				pages:  regexp.MustCompile(`^https://www\.mtggoldfish\.com/deck/\d+$`),
+				parser: goldfish.NewDataset(log, gamesBlob).(pageParser),
+			},
+		},
+		fetch: func(ctx context.Context, u string) ([]byte, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+			if err != nil {
+				return nil, err
+			}
+			page, err := sc.Do(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			return page.Response.Body, nil
+		},
+		now: time.Now,
+	}
+}
+
+func (h *parseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxParseBody))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read body: %v", err), http.StatusRequestEntityTooLarge)
+		return
+	}
+	text := strings.TrimSpace(string(body))
+	if text == "" {
+		http.Error(w, "empty body: post a decklist or a deck URL", http.StatusBadRequest)
+		return
+	}
+
+	var col *game.Collection
+	if strings.HasPrefix(text, "http://") || strings.HasPrefix(text, "https://") {
+		col, err = h.parseURL(r.Context(), text)
+	} else {
+		q := r.URL.Query()
+		col, err = h.parseDecklist(text, q.Get("name"), q.Get("format"))
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(col); err != nil {
+		h.log.Errorf(r.Context(), "failed to write parsed collection: %v", err)
+	}
+}
+
+// parseURL fetches and parses the deck page at u
+func (h *parseHandler) parseURL(ctx context.Context, u string) (*game.Collection, error) {
+	for _, p := range h.pages {
+		if !p.pages.MatchString(u) {
+			continue
+		}
+		body, err := h.fetch(ctx, u)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", u, err)
+		}
+		col, err := p.parser.ParsePage(ctx, u, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", u, err)
+		}
+		return col, nil
+	}
+	return nil, fmt.Errorf("unsupported deck URL %s", u)
+}
+
+// parseDecklist parses a plain-text decklist into a canonicalized deck.
+// The ID is derived from the text, so pasting the same list twice gives
+// the same collection.
+func (h *parseHandler) parseDecklist(text, name, format string) (*game.Collection, error) {
+	mainCards, sideboardCards := goldfish.ParseDeckText(text)
+	if len(mainCards) == 0 {
+		return nil, fmt.Errorf("no cards found in decklist")
+	}
+	partitions := []game.Partition{{Name: "Main", Cards: mainCards}}
+	if len(sideboardCards) > 0 {
+		partitions = append(partitions, game.Partition{Name: "Sideboard", Cards: sideboardCards})
+	}
+
+	sum := sha256.Sum256([]byte(text))
+	id := hex.EncodeToString(sum[:8])
+	t := &game.CollectionTypeDeck{Name: name, Format: format}
+	col := &game.Collection{
+		ID:          id,
+		URL:         "paste:" + id,
+		Type:        game.CollectionTypeWrapper{Type: t.Type(), Inner: t},
+		ReleaseDate: h.now().UTC(),
+		Partitions:  partitions,
+	}
+	if err := col.Canonicalize(); err != nil {
+		return nil, fmt.Errorf("decklist is invalid: %w", err)
+	}
+	return col, nil
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"collections/blob"
+	"collections/games/magic/game"
+	"collections/logger"
+)
+
+func TestParseHandler(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+	h := newParseHandler(log, bucket)
+
+	const decklist = `
+Deck
+4 Lightning Bolt
+18 Mountain
+2 Lightning Bolt
+
+Sideboard
+3 Smash to Smithereens
+`
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/parse?name=Mono+Red&format=Modern", strings.NewReader(decklist)))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("POST /parse = %d %q: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body)
+	}
+	var col game.Collection
+	if err := json.Unmarshal(rec.Body.Bytes(), &col); err != nil {
+		t.Fatalf("payload %s: %v", rec.Body, err)
+	}
+	counts := make(map[string]map[string]int)
+	for _, p := range col.Partitions {
+		counts[p.Name] = make(map[string]int)
+		for _, c := range p.Cards {
+			counts[p.Name][c.Name] = c.Count
+		}
+	}
+	want := map[string]map[string]int{
+		"Main":      {"Lightning Bolt": 6, "Mountain": 18},
+		"Sideboard": {"Smash to Smithereens": 3},
+	}
+	if len(counts) != len(want) {
+		t.Errorf("partitions = %v, want %v", counts, want)
+	}
+	for name, cards := range want {
+		for card, n := range cards {
+			if counts[name][card] != n {
+				t.Errorf("%s %s = %d, want %d", name, card, counts[name][card], n)
+			}
+		}
+	}
+	deck, ok := col.Type.Inner.(*game.CollectionTypeDeck)
+	if !ok || deck.Name != "Mono Red" || deck.Format != "Modern" {
+		t.Errorf("deck = %+v, want Mono Red in Modern", col.Type.Inner)
+	}
+	if col.ID == "" || col.ReleaseDate.IsZero() || col.ParserVersion == "" {
+		t.Errorf("ID, ReleaseDate, ParserVersion = %q, %v, %q, want all set", col.ID, col.ReleaseDate, col.ParserVersion)
+	}
+
+	for _, tt := range []struct {
+		method, body string
+		code         int
+	}{
+		{http.MethodGet, "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "  \n", http.StatusBadRequest},
+		{http.MethodPost, "Lightning Bolt\nMountain", http.StatusUnprocessableEntity},
+		{http.MethodPost, "https://example.com/deck/1", http.StatusUnprocessableEntity},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tt.method, "/parse", strings.NewReader(tt.body)))
+		if rec.Code != tt.code {
+			t.Errorf("%s /parse %q = %d, want %d", tt.method, tt.body, rec.Code, tt.code)
+		}
+	}
+}
@@ -5,6 +5,10 @@ import (
 	"net/http"
 	"os"
 
+	"collections/blob"
+	"collections/graphio"
+	"collections/logger"
+
 	"github.com/spf13/cobra"
 )
 
@@ -26,14 +30,60 @@ func init() {
 	// rootCmd.AddCommand(migrateCmd)
 
 	flags := rootCmd.PersistentFlags()
-	_ = flags
+	flags.String("addr", ":6000", "address to listen on")
+	flags.String("bucket", "s3://games-collections", "bucket url whose scraper cache deck pages are read through")
+	flags.StringP("cache", "c", "", "dir to use for local blob cache")
+	flags.String("graph", "", "co-occurrence graph (csv, jsonl or parquet) served on /neighbors")
 }
 
 func runRoot(cmd *cobra.Command, args []string) error {
-	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	ctx := cmd.Context()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("INFO")
+
+	flags := cmd.Flags()
+	addr, err := flags.GetString("addr")
+	if err != nil {
+		return err
+	}
+	bucketURL, err := flags.GetString("bucket")
+	if err != nil {
+		return err
+	}
+	cacheDir, err := flags.GetString("cache")
+	if err != nil {
+		return err
+	}
+	var bucketOpts []blob.BucketOption
+	if cacheDir != "" {
+		bucketOpts = append(bucketOpts, &blob.OptBucketCache{Dir: cacheDir})
+	}
+	bucket, err := blob.NewBucket(ctx, log, bucketURL, bucketOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to open bucket: %w", err)
+	}
+	defer bucket.Close(ctx)
+
+	graphPath, err := flags.GetString("graph")
+	if err != nil {
+		return err
+	}
+	neighbors := &neighborsHandler{}
+	if graphPath != "" {
+		edges, err := graphio.ReadFile(graphPath, graphio.FormatFromPath(graphPath))
+		if err != nil {
+			return fmt.Errorf("failed to load graph %s: %w", graphPath, err)
+		}
+		neighbors.graph = newCardGraph(edges)
+		log.Infof(ctx, "loaded %d edges between %d cards from %s", len(edges), len(neighbors.graph.edges), graphPath)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/parse", newParseHandler(log, bucket))
+	mux.Handle("/neighbors", neighbors)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("hello"))
 	})
-	http.ListenAndServe(":6000", handler)
-	fmt.Println("HELLO")
-	return nil
+	log.Infof(ctx, "serving on %s", addr)
+	return http.ListenAndServe(addr, mux)
 }
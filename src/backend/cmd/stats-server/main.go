@@ -0,0 +1,217 @@
+package main
+
+// Serve live corpus stats for a dashboard: collection and deck counts by
+// game, format and source, and metadata coverage per source, recomputed
+// from the bucket every --refresh instead of rerunning the CLI analyzers.
+//
+// GET /stats.json returns the latest snapshot (503 until the first one is
+// ready).
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"collections/blob"
+	"collections/games"
+	_ "collections/games/digimon/game" // Register collection types
+	mtg "collections/games/magic/game"
+	_ "collections/games/onepiece/game"  // Register collection types
+	_ "collections/games/pokemon/game"   // Register collection types
+	_ "collections/games/riftbound/game" // Register collection types
+	_ "collections/games/yugioh/game"    // Register collection types
+	"collections/logger"
+)
+
+var (
+	bucketURL = flag.String("bucket", "s3://games-collections", "Bucket holding the games/ prefix")
+	cacheDir  = flag.String("cache", "", "Dir of the local blob cache, if any")
+	addr      = flag.String("addr", ":8080", "Address to listen on")
+	refresh   = flag.Duration("refresh", 15*time.Minute, "How often to recompute the stats")
+	parallel  = flag.Int("parallel", 64, "Collections read concurrently")
+)
+
+// gameStats counts one game's collections
+type gameStats struct {
+	Collections int            `json:"collections"`
+	Decks       int            `json:"decks"`
+	ByFormat    map[string]int `json:"by_format"` // Decks per format
+	BySource    map[string]int `json:"by_source"` // Collections per source
+	ByType      map[string]int `json:"by_type"`   // Collections per collection type
+}
+
+// sourceCoverage is the metadata coverage of one source's decks
+type sourceCoverage struct {
+	Source    string             `json:"source"`
+	Decks     int                `json:"decks"`
+	MeanScore float64            `json:"mean_score"`
+	Coverage  map[string]float64 `json:"coverage"` // Field -> fraction of decks with it
+}
+
+// corpusStats is a snapshot served on /stats.json
+type corpusStats struct {
+	GeneratedAt time.Time             `json:"generated_at"`
+	DurationSec float64               `json:"duration_sec"`
+	Prefixes    []string              `json:"prefixes"`
+	Collections int                   `json:"collections"`
+	Decks       int                   `json:"decks"`
+	Games       map[string]*gameStats `json:"games"`
+	Metadata    []sourceCoverage      `json:"metadata"` // Worst mean score first
+}
+
+// computeStats reads every collection under prefixes of b (the games/
+// prefix). The game of a prefix is its first path segment.
+func computeStats(ctx context.Context, b *blob.Bucket, prefixes []string, parallel int) (*corpusStats, error) {
+	start := time.Now()
+	st := &corpusStats{
+		Prefixes: prefixes,
+		Games:    make(map[string]*gameStats),
+	}
+	report := games.NewMetadataReport()
+	mu := new(sync.Mutex)
+	for _, prefix := range prefixes {
+		game, _, _ := strings.Cut(prefix, "/")
+		gs, ok := st.Games[game]
+		if !ok {
+			gs = &gameStats{
+				ByFormat: make(map[string]int),
+				BySource: make(map[string]int),
+				ByType:   make(map[string]int),
+			}
+			st.Games[game] = gs
+		}
+		err := games.IterItemsBlobPrefix(ctx, b, prefix, mtg.DeserializeAsAnyCollection, func(item games.Item) error {
+			ci, ok := item.(*games.CollectionItem)
+			if !ok {
+				return nil
+			}
+			col := ci.Collection
+			source := string(col.Source)
+			if source == "" {
+				source = string(games.SourceUnknown)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			st.Collections++
+			gs.Collections++
+			gs.BySource[source]++
+			gs.ByType[col.Type.Type]++
+			if meta, ok := col.Type.DeckMetadata(); ok {
+				st.Decks++
+				gs.Decks++
+				format := meta.Format
+				if format == "" {
+					format = "unknown"
+				}
+				gs.ByFormat[format]++
+				report.Add(col)
+			}
+			return nil
+		}, &games.OptIterItemsParallel{Parallel: parallel})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", prefix, err)
+		}
+	}
+
+	for _, s := range report.Sources() {
+		cov := sourceCoverage{
+			Source:    s.Source,
+			Decks:     s.Collections,
+			MeanScore: s.MeanScore(),
+			Coverage:  make(map[string]float64, len(games.MetadataFields)),
+		}
+		for _, f := range games.MetadataFields {
+			cov.Coverage[f.Name] = s.Coverage(f.Name)
+		}
+		st.Metadata = append(st.Metadata, cov)
+	}
+	st.GeneratedAt = time.Now().UTC()
+	st.DurationSec = time.Since(start).Seconds()
+	return st, nil
+}
+
+// statsServer serves the latest snapshot while refreshing it
+type statsServer struct {
+	latest atomic.Pointer[corpusStats]
+}
+
+func (s *statsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	st := s.latest.Load()
+	if st == nil {
+		http.Error(w, "stats not computed yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(st); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// run recomputes the stats every interval until ctx is done. A failed
+// refresh keeps serving the previous snapshot.
+func (s *statsServer) run(ctx context.Context, log *logger.Logger, compute func(context.Context) (*corpusStats, error), interval time.Duration) {
+	for {
+		st, err := compute(ctx)
+		if err != nil {
+			log.Errorf(ctx, "failed to refresh stats: %v", err)
+		} else {
+			s.latest.Store(st)
+			log.Infof(ctx, "refreshed stats: %d collections, %d decks in %.1fs", st.Collections, st.Decks, st.DurationSec)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func main() {
+	flag.Parse()
+	prefixes := flag.Args()
+	if len(prefixes) == 0 {
+		fmt.Println("Usage: stats-server [--bucket URL] [--cache DIR] [--addr ADDR] [--refresh DURATION] <prefix>...")
+		fmt.Println("Example: stats-server --bucket file://./data-full magic/ pokemon/ yugioh/")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("INFO")
+
+	var bucketOpts []blob.BucketOption
+	if *cacheDir != "" {
+		bucketOpts = append(bucketOpts, &blob.OptBucketCache{Dir: *cacheDir})
+	}
+	bucket, err := blob.NewBucket(ctx, log, *bucketURL, bucketOpts...)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer bucket.Close(ctx)
+	b := bucket.WithPrefix("games/")
+
+	server := &statsServer{}
+	go server.run(ctx, log, func(ctx context.Context) (*corpusStats, error) {
+		return computeStats(ctx, b, prefixes, *parallel)
+	}, *refresh)
+
+	mux := http.NewServeMux()
+	mux.Handle("/stats.json", server)
+	log.Infof(ctx, "serving stats for %v on %s", prefixes, *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"collections/blob"
+	"collections/games"
+	mtg "collections/games/magic/game"
+	ygo "collections/games/yugioh/game"
+	"collections/logger"
+)
+
+func TestStatsHandler(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+
+	write := func(key string, v any) {
+		t.Helper()
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := bucket.Write(ctx, "games/"+key, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cards := []games.CardDesc{{Name: "Ash Blossom & Joyous Spring", Count: 3}}
+	for i, format := range []string{"TCG", "TCG", "OCG"} {
+		write(fmt.Sprintf("yugioh/ygoprodeck/%d.json", i), games.Collection{
+			ID:         fmt.Sprint(i),
+			URL:        fmt.Sprintf("https://ygoprodeck.com/deck/%d", i),
+			Type:       games.CollectionTypeWrapper{Type: "YGODeck", Inner: &ygo.CollectionTypeDeck{Name: "Tenpai", Format: format, Player: "Jessy Grospe"}},
+			Partitions: []games.Partition{{Name: "Main Deck", Cards: cards}},
+			Source:     games.SourceYGOPRODeck,
+		})
+	}
+	write("magic/mtgtop8/1.json", mtg.Collection{
+		ID:         "1",
+		URL:        "https://www.mtgtop8.com/event?e=1&d=1",
+		Type:       mtg.CollectionTypeWrapper{Type: "Deck", Inner: &mtg.CollectionTypeDeck{Name: "Burn", Format: "Modern", Archetype: "Burn"}},
+		Partitions: []mtg.Partition{{Name: "Main", Cards: []mtg.CardDesc{{Name: "Lightning Bolt", Count: 4}}}},
+	})
+	write("magic/scryfall/lea.json", mtg.Collection{
+		ID:   "lea",
+		URL:  "https://scryfall.com/sets/lea",
+		Type: mtg.CollectionTypeWrapper{Type: "Set", Inner: &mtg.CollectionTypeSet{Name: "Alpha", Code: "LEA"}},
+	})
+
+	server := &statsServer{}
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats.json", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status before the first refresh = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel() // One refresh, then stop
+	server.run(ctx, log, func(ctx context.Context) (*corpusStats, error) {
+		return computeStats(context.Background(), bucket.WithPrefix("games/"), []string{"yugioh/", "magic/"}, 4)
+	}, time.Hour)
+
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats.json", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("GET /stats.json = %d %q: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body)
+	}
+	var got corpusStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("payload %s: %v", rec.Body, err)
+	}
+
+	if got.Collections != 5 || got.Decks != 4 {
+		t.Errorf("collections, decks = %d, %d, want 5, 4", got.Collections, got.Decks)
+	}
+	for game, want := range map[string]string{
+		"yugioh": `{3 3 map[OCG:1 TCG:2] map[ygoprodeck:3] map[YGODeck:3]}`,
+		"magic":  `{2 1 map[Modern:1] map[mtgtop8:1 scryfall:1] map[Deck:1 Set:1]}`,
+	} {
+		gs, ok := got.Games[game]
+		if !ok {
+			t.Errorf("no stats for %s", game)
+			continue
+		}
+		if fmt.Sprint(*gs) != want {
+			t.Errorf("%s stats = %v, want %v", game, *gs, want)
+		}
+	}
+
+	if len(got.Metadata) != 2 {
+		t.Fatalf("metadata = %+v, want 2 sources", got.Metadata)
+	}
+	// Worst first: mtgtop8 has format and archetype, ygoprodeck format and player
+	if m := got.Metadata[0]; m.Source != "ygoprodeck" || m.Decks != 3 || m.Coverage["player"] != 1 || m.Coverage["event"] != 0 {
+		t.Errorf("metadata[0] = %+v", m)
+	}
+	if m := got.Metadata[1]; m.Source != "mtgtop8" || m.Decks != 1 || m.Coverage["archetype"] != 1 {
+		t.Errorf("metadata[1] = %+v", m)
+	}
+
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/stats.json", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -6,21 +6,29 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"collections/blob"
+	"collections/cio"
+	"collections/games"
 	"collections/games/magic/game"
+	ygo "collections/games/yugioh/game"
 	"collections/logger"
 
-	"github.com/DataDog/zstd"
 	"github.com/spf13/cobra"
 )
 
 var (
 	bucketURL string
 	verbose   bool
+	jobs      int
 )
 
+// maxStoredErrors bounds the error messages kept for the summary; invalid
+// still counts every failure
+const maxStoredErrors = 1000
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "validate-data",
@@ -35,6 +43,7 @@ func main() {
 
 	validateCmd.Flags().StringVar(&bucketURL, "bucket", "file://./data-full", "Bucket URL to validate")
 	validateCmd.Flags().BoolVar(&verbose, "verbose", false, "Show details for each collection")
+	validateCmd.Flags().IntVar(&jobs, "jobs", runtime.NumCPU(), "Collections to validate in parallel")
 
 	rootCmd.AddCommand(validateCmd)
 
@@ -48,12 +57,26 @@ type validationStats struct {
 	total      int
 	valid      int
 	invalid    int
-	errors     []string
+	errors     []string // first maxStoredErrors, in file order
 	byType     map[string]int
 	byFormat   map[string]int
 	totalCards int
 }
 
+// fileStats is what one valid collection adds to validationStats
+type fileStats struct {
+	typ    string
+	format string // empty when unknown or not a deck
+	cards  int
+}
+
+// fileResult is one file's validation, computed by a worker and merged
+// into validationStats in file order
+type fileResult struct {
+	stats fileStats
+	err   error
+}
+
 func runValidate(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 	log := logger.NewLogger(ctx)
@@ -66,30 +89,7 @@ func runValidate(cmd *cobra.Command, args []string) error {
 	// Extract local path from file:// URL
 	localPath := strings.TrimPrefix(bucketURL, "file://")
 
-	stats := &validationStats{
-		byType:   make(map[string]int),
-		byFormat: make(map[string]int),
-	}
-
-	// Find all .json.zst files
-	err := filepath.Walk(localPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if !info.IsDir() && strings.HasSuffix(path, ".json.zst") {
-			if err := validateCollection(ctx, log, path, stats); err != nil {
-				stats.invalid++
-				stats.errors = append(stats.errors, fmt.Sprintf("%s: %v", filepath.Base(path), err))
-			} else {
-				stats.valid++
-			}
-			stats.total++
-		}
-
-		return nil
-	})
-
+	stats, err := validateDir(ctx, log, localPath, jobs)
 	if err != nil {
 		return err
 	}
@@ -116,11 +116,11 @@ func runValidate(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(stats.errors) > 0 {
-		fmt.Printf("\n=== Errors (%d) ===\n", len(stats.errors))
+		fmt.Printf("\n=== Errors (%d) ===\n", stats.invalid)
 		for i, err := range stats.errors {
 			fmt.Printf("%d. %s\n", i+1, err)
 			if i >= 9 { // Show first 10 errors
-				fmt.Printf("... and %d more errors\n", len(stats.errors)-10)
+				fmt.Printf("... and %d more errors\n", stats.invalid-10)
 				break
 			}
 		}
@@ -135,39 +135,130 @@ func runValidate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func validateCollection(ctx context.Context, log *logger.Logger, path string, stats *validationStats) error {
-	// Read and decompress
-	compressed, err := os.ReadFile(path)
+// validateDir validates every .json and .json.zst collection under dir on
+// up to jobs goroutines. Results are merged in file order, so the stats
+// and error list are the same for any number of jobs.
+func validateDir(ctx context.Context, log *logger.Logger, dir string, jobs int) (*validationStats, error) {
+	stats := &validationStats{
+		byType:   make(map[string]int),
+		byFormat: make(map[string]int),
+	}
+
+	files, err := cio.FindCollectionFiles(dir, cio.FindOpts{})
 	if err != nil {
-		return fmt.Errorf("read failed: %w", err)
+		return nil, err
 	}
 
-	decompressed, err := zstd.Decompress(nil, compressed)
+	walk := func(emit func(string) error) error {
+		for _, path := range files {
+			if err := emit(path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	work := func(path string) fileResult {
+		fs, err := validateCollection(ctx, log, path)
+		return fileResult{stats: fs, err: err}
+	}
+	merge := func(path string, r fileResult) error {
+		stats.total++
+		if r.err != nil {
+			stats.invalid++
+			if len(stats.errors) < maxStoredErrors {
+				stats.errors = append(stats.errors, fmt.Sprintf("%s: %v", filepath.Base(path), r.err))
+			}
+			return nil
+		}
+		stats.valid++
+		stats.byType[r.stats.typ]++
+		if r.stats.format != "" {
+			stats.byFormat[r.stats.format]++
+		}
+		stats.totalCards += r.stats.cards
+		return nil
+	}
+	if err := cio.ProcessOrdered(jobs, walk, work, merge); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func validateCollection(ctx context.Context, log *logger.Logger, path string) (fileStats, error) {
+	// Read and decompress (.json files are read as-is)
+	decompressed, err := cio.ReadCollectionFile(path)
 	if err != nil {
-		return fmt.Errorf("decompress failed: %w", err)
+		return fileStats{}, fmt.Errorf("read failed: %w", err)
+	}
+
+	if typ := collectionType(decompressed); games.TypeRegistry[typ] != nil {
+		return validateGameCollection(ctx, log, path, decompressed)
 	}
 
 	// Parse as collection
 	var collection game.Collection
 	if err := json.Unmarshal(decompressed, &collection); err != nil {
-		return fmt.Errorf("json unmarshal failed: %w", err)
+		return fileStats{}, fmt.Errorf("json unmarshal failed: %w", err)
 	}
 
 	// Validate using built-in canonicalization
 	if err := collection.Canonicalize(); err != nil {
-		return fmt.Errorf("validation failed: %w", err)
+		return fileStats{}, fmt.Errorf("validation failed: %w", err)
 	}
 
 	// Collect stats
-	stats.byType[string(collection.Type.Type)]++
-	if deck, ok := collection.Type.Inner.(*game.CollectionTypeDeck); ok && deck.Format != "" {
-		stats.byFormat[deck.Format]++
+	fs := fileStats{typ: string(collection.Type.Type), cards: countCards(&collection)}
+	if deck, ok := collection.Type.Inner.(*game.CollectionTypeDeck); ok {
+		fs.format = deck.Format
+	}
+
+	if verbose {
+		log.Infof(ctx, "✓ %s: %s (%d partitions, %d cards)",
+			filepath.Base(path),
+			collection.Type.Type,
+			len(collection.Partitions),
+			fs.cards)
+	}
+
+	return fs, nil
+}
+
+// collectionType returns the type name of a collection's JSON, or "" if
+// it has none
+func collectionType(data []byte) string {
+	var header struct {
+		Type struct {
+			Type string `json:"type"`
+		} `json:"type"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return ""
+	}
+	return header.Type.Type
+}
+
+// validateGameCollection validates a collection of a type registered with
+// games (non-MTG games), adding game-specific legality checks: Yu-Gi-Oh!
+// decks must be within the zone size limits.
+func validateGameCollection(ctx context.Context, log *logger.Logger, path string, data []byte) (fileStats, error) {
+	var collection games.Collection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return fileStats{}, fmt.Errorf("json unmarshal failed: %w", err)
+	}
+	if err := collection.Canonicalize(); err != nil {
+		return fileStats{}, fmt.Errorf("validation failed: %w", err)
+	}
+	fs := fileStats{typ: collection.Type.Type}
+	if deck, ok := collection.Type.Inner.(*ygo.CollectionTypeDeck); ok {
+		if v := ygo.ZoneViolations(&collection); len(v) > 0 {
+			return fileStats{}, fmt.Errorf("zone sizes: %s", strings.Join(v, ", "))
+		}
+		fs.format = deck.Format
 	}
 
-	// Count cards
 	for _, partition := range collection.Partitions {
 		for _, card := range partition.Cards {
-			stats.totalCards += card.Count
+			fs.cards += card.Count
 		}
 	}
 
@@ -176,10 +267,10 @@ func validateCollection(ctx context.Context, log *logger.Logger, path string, st
 			filepath.Base(path),
 			collection.Type.Type,
 			len(collection.Partitions),
-			countCards(&collection))
+			fs.cards)
 	}
 
-	return nil
+	return fs, nil
 }
 
 func countCards(c *game.Collection) int {
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"collections/games"
+	"collections/games/magic/game"
+	ygo "collections/games/yugioh/game"
+	"collections/logger"
+
+	"github.com/DataDog/zstd"
+)
+
+func writeCollection(t *testing.T, path string, c game.Collection, compress bool) {
+	t.Helper()
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compress {
+		if data, err = zstd.Compress(nil, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testDeck(id string) game.Collection {
+	return game.Collection{
+		ID:          id,
+		URL:         "https://www.mtgtop8.com/event?d=" + id,
+		Type:        game.CollectionTypeWrapper{Type: "Deck", Inner: &game.CollectionTypeDeck{Name: "Burn", Format: "Modern"}},
+		ReleaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Partitions: []game.Partition{{
+			Name:  "Main",
+			Cards: []game.CardDesc{{Name: "Lightning Bolt", Count: 4}},
+		}},
+	}
+}
+
+func TestValidateDirMixedCompression(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	dir := t.TempDir()
+	writeCollection(t, filepath.Join(dir, "magic/mtgtop8/1.json.zst"), testDeck("1"), true)
+	writeCollection(t, filepath.Join(dir, "magic/mtgtop8/2.json"), testDeck("2"), false)
+
+	stats, err := validateDir(ctx, log, dir, 1)
+	if err != nil {
+		t.Fatalf("validateDir() error = %v", err)
+	}
+	if stats.total != 2 || stats.valid != 2 {
+		t.Errorf("validateDir() total=%d valid=%d, want 2 and 2 (errors: %v)", stats.total, stats.valid, stats.errors)
+	}
+	if stats.totalCards != 8 {
+		t.Errorf("totalCards = %d, want 8", stats.totalCards)
+	}
+}
+
+func writeJSON(t *testing.T, path string, v any) {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func ygoDeck(id string, main, extra int) games.Collection {
+	return games.Collection{
+		ID:          id,
+		URL:         "https://ygoprodeck.com/deck/" + id,
+		Type:        games.CollectionTypeWrapper{Type: "YGODeck", Inner: &ygo.CollectionTypeDeck{Name: "Snake-Eye", Format: "TCG"}},
+		ReleaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Partitions: []games.Partition{
+			{Name: ygo.PartitionMain, Cards: []games.CardDesc{{Name: "Snake-Eye Ash", Count: main}}},
+			{Name: ygo.PartitionExtra, Cards: []games.CardDesc{{Name: "I:P Masquerena", Count: extra}}},
+		},
+	}
+}
+
+func TestValidateDirYGOZones(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	dir := t.TempDir()
+	writeJSON(t, filepath.Join(dir, "yugioh/ygoprodeck/legal.json"), ygoDeck("legal", 40, 15))
+	writeJSON(t, filepath.Join(dir, "yugioh/ygoprodeck/extra.json"), ygoDeck("extra", 40, 16))
+
+	stats, err := validateDir(ctx, log, dir, 1)
+	if err != nil {
+		t.Fatalf("validateDir() error = %v", err)
+	}
+	if stats.total != 2 || stats.valid != 1 || stats.invalid != 1 {
+		t.Errorf("validateDir() total=%d valid=%d invalid=%d, want 2, 1 and 1 (errors: %v)", stats.total, stats.valid, stats.invalid, stats.errors)
+	}
+	want := "extra.json: zone sizes: 16 extra deck cards, want at most 15"
+	if len(stats.errors) != 1 || stats.errors[0] != want {
+		t.Errorf("errors = %q, want [%q]", stats.errors, want)
+	}
+	if stats.byType["YGODeck"] != 1 || stats.byFormat["TCG"] != 1 {
+		t.Errorf("byType = %v, byFormat = %v, want the legal deck counted once", stats.byType, stats.byFormat)
+	}
+}
+
+func TestValidateDirJobsMatchSequential(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	dir := t.TempDir()
+	for i := 0; i < 60; i++ {
+		id := fmt.Sprintf("%03d", i)
+		switch i % 4 {
+		case 0:
+			writeCollection(t, filepath.Join(dir, "magic/mtgtop8", id+".json.zst"), testDeck(id), true)
+		case 1:
+			writeJSON(t, filepath.Join(dir, "yugioh/ygoprodeck", id+".json"), ygoDeck(id, 40, 15))
+		case 2:
+			writeJSON(t, filepath.Join(dir, "yugioh/ygoprodeck", id+".json"), ygoDeck(id, 40, 16)) // Invalid
+		case 3:
+			deck := testDeck(id)
+			deck.URL = "" // Invalid
+			writeCollection(t, filepath.Join(dir, "magic/goldfish", id+".json"), deck, false)
+		}
+	}
+
+	want, err := validateDir(ctx, log, dir, 1)
+	if err != nil {
+		t.Fatalf("validateDir() error = %v", err)
+	}
+	if want.total != 60 || want.invalid != 30 {
+		t.Fatalf("sequential total=%d invalid=%d, want 60 and 30", want.total, want.invalid)
+	}
+	got, err := validateDir(ctx, log, dir, 8)
+	if err != nil {
+		t.Fatalf("validateDir() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("8 jobs stats = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+// Validate an exported JSONL file before downstream ingestion: every line
+// must parse, and every record needs a deck_id, non-empty cards and a
+// known source
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"collections/cio"
+	"collections/games"
+)
+
+var jsonOutput = flag.Bool("json", false, "Print the summary as JSON")
+
+// issue is one problem found in the file, identified by 1-based line
+type issue struct {
+	Line    int    `json:"line"`
+	DeckID  string `json:"deck_id,omitempty"`
+	Problem string `json:"problem"`
+}
+
+type summary struct {
+	Records       int     `json:"records"`
+	Valid         int     `json:"valid"`
+	Malformed     int     `json:"malformed"`
+	MissingFields int     `json:"missing_fields"`
+	Issues        []issue `json:"issues,omitempty"`
+}
+
+func (s *summary) ok() bool {
+	return s.Malformed == 0 && s.MissingFields == 0
+}
+
+type record struct {
+	DeckID string            `json:"deck_id"`
+	Source string            `json:"source"`
+	Cards  []json.RawMessage `json:"cards"`
+}
+
+// validate streams r line by line. Blank lines are ignored.
+func validate(r io.Reader) (*summary, error) {
+	s := &summary{}
+	sc := cio.NewJSONLScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		data := sc.Bytes()
+		if len(strings.TrimSpace(string(data))) == 0 {
+			continue
+		}
+		s.Records++
+
+		var rec record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			s.Malformed++
+			s.Issues = append(s.Issues, issue{Line: line, Problem: fmt.Sprintf("malformed JSON: %v", err)})
+			continue
+		}
+
+		var problems []string
+		if rec.DeckID == "" {
+			problems = append(problems, "missing deck_id")
+		}
+		if len(rec.Cards) == 0 {
+			problems = append(problems, "missing or empty cards")
+		}
+		if rec.Source == "" {
+			problems = append(problems, "missing source")
+		} else if !games.Source(rec.Source).IsKnown() {
+			problems = append(problems, fmt.Sprintf("unknown source %q", rec.Source))
+		}
+		if len(problems) > 0 {
+			s.MissingFields++
+			s.Issues = append(s.Issues, issue{Line: line, DeckID: rec.DeckID, Problem: strings.Join(problems, ", ")})
+			continue
+		}
+		s.Valid++
+	}
+	if err := sc.Err(); err != nil {
+		return s, fmt.Errorf("line %d: %w", line+1, err)
+	}
+	return s, nil
+}
+
+func main() {
+	flag.Parse()
+	args := flag.Args()
+	if len(args) < 1 {
+		fmt.Println("Usage: validate-export [--json] <export.jsonl>")
+		os.Exit(1)
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	s, err := validate(f)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(s); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		fmt.Printf("📊 %s\n", args[0])
+		fmt.Printf("   Records: %d\n", s.Records)
+		fmt.Printf("   Valid: %d\n", s.Valid)
+		fmt.Printf("   Malformed lines: %d\n", s.Malformed)
+		fmt.Printf("   Records missing fields: %d\n", s.MissingFields)
+		for _, is := range s.Issues {
+			if is.DeckID != "" {
+				fmt.Printf("⚠️  line %d (%s): %s\n", is.Line, is.DeckID, is.Problem)
+			} else {
+				fmt.Printf("⚠️  line %d: %s\n", is.Line, is.Problem)
+			}
+		}
+	}
+
+	if !s.ok() {
+		os.Exit(1)
+	}
+	if !*jsonOutput {
+		fmt.Println("✅ Export is valid")
+	}
+}
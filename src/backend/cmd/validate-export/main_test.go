@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	lines := []string{
+		`{"deck_id":"1.json","source":"mtgtop8","cards":[{"name":"Lightning Bolt","count":4}]}`,
+		`{"deck_id":"2.json","source":"mtgtop8","cards":[`,
+		`{"deck_id":"3.json","source":"goldfish","cards":[]}`,
+		``,
+		`{"deck_id":"4.json","source":"limitless-web","cards":[{"name":"Pikachu","count":1}]}`,
+	}
+	path := filepath.Join(t.TempDir(), "export.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	s, err := validate(f)
+	if err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+	if s.Records != 4 || s.Valid != 2 || s.Malformed != 1 || s.MissingFields != 1 {
+		t.Errorf("summary = %+v, want 4 records, 2 valid, 1 malformed, 1 missing fields", *s)
+	}
+	if s.ok() {
+		t.Error("ok() = true, want false")
+	}
+	if len(s.Issues) != 2 {
+		t.Fatalf("issues = %+v, want 2", s.Issues)
+	}
+	if got := s.Issues[0]; got.Line != 2 || !strings.Contains(got.Problem, "malformed") {
+		t.Errorf("issues[0] = %+v, want malformed line 2", got)
+	}
+	if got := s.Issues[1]; got.Line != 3 || got.DeckID != "3.json" || !strings.Contains(got.Problem, "cards") {
+		t.Errorf("issues[1] = %+v, want empty cards on line 3", got)
+	}
+}
+
+func TestValidateUnknownSource(t *testing.T) {
+	s, err := validate(strings.NewReader(`{"deck_id":"1.json","source":"unknown","cards":[{"name":"Island","count":1}]}` + "\n"))
+	if err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+	if s.MissingFields != 1 || !strings.Contains(s.Issues[0].Problem, `unknown source "unknown"`) {
+		t.Errorf("summary = %+v, want unknown source reported", *s)
+	}
+}
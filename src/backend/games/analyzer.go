@@ -0,0 +1,78 @@
+package games
+
+import (
+	"fmt"
+	"sort"
+)
+
+// AnalysisReport is a game's summary of a set of decks. Metrics and
+// histograms are keyed by names the game chooses, e.g. "energy_ratio" or
+// "energy_curve".
+type AnalysisReport struct {
+	Game       string                 `json:"game"`
+	Decks      int                    `json:"decks"`
+	Metrics    map[string]float64     `json:"metrics,omitempty"`
+	Histograms map[string]map[int]int `json:"histograms,omitempty"`
+	// Warnings flag decks that look wrong, e.g. incomplete parses
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Analyzer computes game-specific deck statistics. Each game implements
+// one and registers it on init, so analysis tools dispatch by game name
+// instead of hardcoding per-game logic.
+type Analyzer interface {
+	// Game is the name the analyzer is registered under, e.g. "pokemon"
+	Game() string
+	// DeckType is the collection type the analyzer accepts, e.g. "PokemonDeck"
+	DeckType() string
+	Analyze(decks []*Collection) AnalysisReport
+}
+
+// CardDataLoader is implemented by analyzers that need card data, such as
+// types or costs, beyond what a deck lists.
+type CardDataLoader interface {
+	// CardDataDir is the default card data directory, relative to the data dir
+	CardDataDir() string
+	// LoadCardData loads card data from dir and returns the number of cards
+	LoadCardData(dir string) (int, error)
+}
+
+var analyzers = make(map[string]Analyzer)
+
+// RegisterAnalyzer registers a game's analyzer.
+// Call from game package init() functions.
+// Panics if the game already has one (prevents silent overwrites).
+func RegisterAnalyzer(a Analyzer) {
+	if _, exists := analyzers[a.Game()]; exists {
+		panic(fmt.Sprintf("analyzer for %q already registered", a.Game()))
+	}
+	analyzers[a.Game()] = a
+}
+
+// LookupAnalyzer returns the analyzer registered for game
+func LookupAnalyzer(game string) (Analyzer, bool) {
+	a, ok := analyzers[game]
+	return a, ok
+}
+
+// AnalyzerGames returns the games with a registered analyzer, sorted
+func AnalyzerGames() []string {
+	names := make([]string, 0, len(analyzers))
+	for name := range analyzers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AnalyzeCollections runs a on the collections of its DeckType, ignoring
+// the rest
+func AnalyzeCollections(a Analyzer, cols []*Collection) AnalysisReport {
+	var decks []*Collection
+	for _, col := range cols {
+		if col != nil && col.Type.Type == a.DeckType() {
+			decks = append(decks, col)
+		}
+	}
+	return a.Analyze(decks)
+}
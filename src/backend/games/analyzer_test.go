@@ -0,0 +1,79 @@
+package games
+
+import (
+	"testing"
+)
+
+type fakeDeckType struct{}
+
+func (*fakeDeckType) Type() string      { return "FakeAnalyzerDeck" }
+func (*fakeDeckType) IsCollectionType() {}
+
+type fakeAnalyzer struct {
+	calls int
+	seen  []string
+}
+
+func (*fakeAnalyzer) Game() string     { return "fake-analyzer-game" }
+func (*fakeAnalyzer) DeckType() string { return "FakeAnalyzerDeck" }
+
+func (a *fakeAnalyzer) Analyze(decks []*Collection) AnalysisReport {
+	a.calls++
+	for _, d := range decks {
+		a.seen = append(a.seen, d.ID)
+	}
+	return AnalysisReport{
+		Game:    a.Game(),
+		Decks:   len(decks),
+		Metrics: map[string]float64{"decks_seen": float64(len(decks))},
+	}
+}
+
+func TestAnalyzerRegistry(t *testing.T) {
+	fake := &fakeAnalyzer{}
+	RegisterAnalyzer(fake)
+	t.Cleanup(func() { delete(analyzers, fake.Game()) })
+
+	a, ok := LookupAnalyzer("fake-analyzer-game")
+	if !ok {
+		t.Fatal("LookupAnalyzer() found no analyzer for registered game")
+	}
+	if _, ok := LookupAnalyzer("no-such-game"); ok {
+		t.Error("LookupAnalyzer() found an analyzer for an unregistered game")
+	}
+	found := false
+	for _, g := range AnalyzerGames() {
+		found = found || g == fake.Game()
+	}
+	if !found {
+		t.Errorf("AnalyzerGames() = %v, missing %q", AnalyzerGames(), fake.Game())
+	}
+
+	cols := []*Collection{
+		{ID: "deck-1", Type: CollectionTypeWrapper{Type: "FakeAnalyzerDeck", Inner: &fakeDeckType{}}},
+		{ID: "other", Type: CollectionTypeWrapper{Type: "OtherDeck"}},
+		nil,
+		{ID: "deck-2", Type: CollectionTypeWrapper{Type: "FakeAnalyzerDeck", Inner: &fakeDeckType{}}},
+	}
+	report := AnalyzeCollections(a, cols)
+	if fake.calls != 1 {
+		t.Errorf("Analyze() called %d times, want 1", fake.calls)
+	}
+	if len(fake.seen) != 2 || fake.seen[0] != "deck-1" || fake.seen[1] != "deck-2" {
+		t.Errorf("Analyze() saw %v, want [deck-1 deck-2]", fake.seen)
+	}
+	if report.Game != "fake-analyzer-game" || report.Decks != 2 || report.Metrics["decks_seen"] != 2 {
+		t.Errorf("report = %+v, want 2 decks for fake-analyzer-game", report)
+	}
+}
+
+func TestRegisterAnalyzerDuplicatePanics(t *testing.T) {
+	RegisterAnalyzer(&fakeAnalyzer{})
+	t.Cleanup(func() { delete(analyzers, "fake-analyzer-game") })
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterAnalyzer() did not panic on duplicate game")
+		}
+	}()
+	RegisterAnalyzer(&fakeAnalyzer{})
+}
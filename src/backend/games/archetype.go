@@ -0,0 +1,83 @@
+package games
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// AnyFormat is the ArchetypeAliases key for aliases that apply in every
+// format.
+const AnyFormat = "*"
+
+// ArchetypeAliases maps a format to alias -> canonical archetype name.
+// Aliases under AnyFormat apply to every format. Build with
+// NewArchetypeAliases so keys are normalized for lookup.
+type ArchetypeAliases map[string]map[string]string
+
+// NewArchetypeAliases normalizes an alias table. Format keys go through
+// NormalizeFormatName and alias keys through archetypeKey, so "Mono-Red
+// Aggro" and "mono red  aggro" are the same alias. Each canonical name is
+// also added as an alias of itself, so its spelling variants collapse too.
+func NewArchetypeAliases(raw map[string]map[string]string) ArchetypeAliases {
+	aliases := make(ArchetypeAliases, len(raw))
+	for format, table := range raw {
+		f := formatKey(format)
+		if aliases[f] == nil {
+			aliases[f] = make(map[string]string, len(table))
+		}
+		for alias, canonical := range table {
+			aliases[f][archetypeKey(alias)] = canonical
+			aliases[f][archetypeKey(canonical)] = canonical
+		}
+	}
+	return aliases
+}
+
+// LoadArchetypeAliases reads an alias file: a JSON object of format ->
+// {alias: canonical}, e.g.
+//
+//	{"modern": {"RDW": "Mono-Red Aggro"}, "*": {"UW Control": "Azorius Control"}}
+func LoadArchetypeAliases(path string) (ArchetypeAliases, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse archetype aliases %s: %w", path, err)
+	}
+	return NewArchetypeAliases(raw), nil
+}
+
+// NormalizeArchetype returns the canonical name for an archetype in format,
+// checking the format's aliases before AnyFormat. Archetypes with no alias
+// are returned unchanged.
+func NormalizeArchetype(format, raw string, aliases ArchetypeAliases) string {
+	key := archetypeKey(raw)
+	if key == "" {
+		return raw
+	}
+	if canonical, ok := aliases[formatKey(format)][key]; ok {
+		return canonical
+	}
+	if canonical, ok := aliases[AnyFormat][key]; ok {
+		return canonical
+	}
+	return raw
+}
+
+func formatKey(format string) string {
+	if strings.TrimSpace(format) == AnyFormat {
+		return AnyFormat
+	}
+	return strings.ToLower(NormalizeFormatName(format))
+}
+
+// archetypeKey folds case, hyphens, underscores and repeated whitespace
+func archetypeKey(archetype string) string {
+	archetype = strings.ToLower(NormalizeCardName(archetype))
+	archetype = strings.NewReplacer("-", " ", "_", " ").Replace(archetype)
+	return strings.Join(strings.Fields(archetype), " ")
+}
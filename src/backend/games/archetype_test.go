@@ -0,0 +1,48 @@
+package games
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeArchetype(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aliases.json")
+	data := `{
+		"modern": {"RDW": "Mono-Red Aggro", "Red Deck Wins": "Mono-Red Aggro"},
+		"*": {"UW Control": "Azorius Control"}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	aliases, err := LoadArchetypeAliases(path)
+	if err != nil {
+		t.Fatalf("LoadArchetypeAliases() error = %v", err)
+	}
+
+	tests := []struct {
+		format, raw, want string
+	}{
+		{"Modern", "RDW", "Mono-Red Aggro"},
+		{"mod", "red deck wins", "Mono-Red Aggro"},
+		{"Modern", "Mono Red Aggro", "Mono-Red Aggro"},
+		{"Modern", "mono-red  aggro", "Mono-Red Aggro"},
+		{"Legacy", "uw control", "Azorius Control"},
+		{"Modern", "UW-Control", "Azorius Control"},
+		// Format-specific aliases don't leak into other formats
+		{"Legacy", "RDW", "RDW"},
+		// Unknown archetypes pass through unchanged
+		{"Modern", "Boros Energy", "Boros Energy"},
+		{"Modern", "", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeArchetype(tt.format, tt.raw, aliases); got != tt.want {
+			t.Errorf("NormalizeArchetype(%q, %q) = %q, want %q", tt.format, tt.raw, got, tt.want)
+		}
+	}
+
+	// No aliases: everything passes through
+	if got := NormalizeArchetype("Modern", "RDW", nil); got != "RDW" {
+		t.Errorf("NormalizeArchetype() with nil aliases = %q, want RDW", got)
+	}
+}
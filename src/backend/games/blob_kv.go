@@ -0,0 +1,67 @@
+package games
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+
+	"collections/blob"
+)
+
+// BlobKV stores JSON values in a blob bucket under a namespace, so
+// checkpoints, trackers and other per-run state share one load/save path.
+// Keys are escaped, so a key containing "/" cannot reach into another
+// namespace; namespaces themselves should not nest.
+type BlobKV struct {
+	blob      *blob.Bucket
+	namespace string
+}
+
+// NewBlobKV returns a BlobKV storing values under namespace, e.g.
+// "magic/mtgtop8"
+func NewBlobKV(bucket *blob.Bucket, namespace string) *BlobKV {
+	return &BlobKV{blob: bucket, namespace: namespace}
+}
+
+// blobKey is namespace/<escaped key>.json
+func (kv *BlobKV) blobKey(key string) string {
+	return path.Join(kv.namespace, url.PathEscape(key)+".json")
+}
+
+// Get decodes the value at key into v and reports whether it exists
+func (kv *BlobKV) Get(ctx context.Context, key string, v any) (bool, error) {
+	data, err := kv.blob.Read(ctx, kv.blobKey(key))
+	if blob.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// Set stores v as JSON at key, replacing any existing value
+func (kv *BlobKV) Set(ctx context.Context, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", key, err)
+	}
+	if err := kv.blob.Write(ctx, kv.blobKey(key), data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes key; deleting a missing key is not an error
+func (kv *BlobKV) Delete(ctx context.Context, key string) error {
+	err := kv.blob.Delete(ctx, kv.blobKey(key))
+	if err != nil && !blob.IsNotFound(err) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
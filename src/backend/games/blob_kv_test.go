@@ -0,0 +1,127 @@
+package games
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"collections/blob"
+	"collections/logger"
+)
+
+func TestBlobKVRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+
+	type checkpoint struct {
+		Page    int       `json:"page"`
+		Updated time.Time `json:"updated"`
+	}
+	kv := NewBlobKV(bucket, "magic/mtgtop8")
+
+	want := checkpoint{Page: 42, Updated: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+	if err := kv.Set(ctx, "checkpoint", want); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := kv.Set(ctx, "dead_urls", []string{"https://a", "https://b"}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := kv.Set(ctx, "scroll/page", map[string]int{"offset": 3}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	var got checkpoint
+	if ok, err := kv.Get(ctx, "checkpoint", &got); err != nil || !ok || got != want {
+		t.Errorf("Get(checkpoint) = %+v, %v, %v; want %+v, true, nil", got, ok, err, want)
+	}
+	var urls []string
+	if ok, err := kv.Get(ctx, "dead_urls", &urls); err != nil || !ok || len(urls) != 2 {
+		t.Errorf("Get(dead_urls) = %v, %v, %v; want 2 URLs", urls, ok, err)
+	}
+	var scroll map[string]int
+	if ok, err := kv.Get(ctx, "scroll/page", &scroll); err != nil || !ok || scroll["offset"] != 3 {
+		t.Errorf("Get(scroll/page) = %v, %v, %v; want offset 3", scroll, ok, err)
+	}
+
+	// Overwrite, delete, and missing keys
+	if err := kv.Set(ctx, "checkpoint", checkpoint{Page: 43}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, err := kv.Get(ctx, "checkpoint", &got); err != nil || got.Page != 43 {
+		t.Errorf("Get() after overwrite page = %d, %v; want 43", got.Page, err)
+	}
+	if err := kv.Delete(ctx, "dead_urls"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if ok, err := kv.Get(ctx, "dead_urls", &urls); err != nil || ok {
+		t.Errorf("Get() after Delete() = %v, %v; want false, nil", ok, err)
+	}
+	if err := kv.Delete(ctx, "dead_urls"); err != nil {
+		t.Errorf("Delete() of missing key error = %v, want nil", err)
+	}
+}
+
+func TestBlobKVNamespaceIsolation(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+
+	a := NewBlobKV(bucket, "magic")
+	b := NewBlobKV(bucket, "pokemon")
+	nested := NewBlobKV(bucket, "magic/mtgtop8")
+
+	if err := a.Set(ctx, "state", "magic"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Set(ctx, "state", "pokemon"); err != nil {
+		t.Fatal(err)
+	}
+	// A key with a slash must not land in another namespace
+	if err := a.Set(ctx, "mtgtop8/state", "escaped"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		kv   *BlobKV
+		want string
+	}{{a, "magic"}, {b, "pokemon"}} {
+		var got string
+		if ok, err := tt.kv.Get(ctx, "state", &got); err != nil || !ok || got != tt.want {
+			t.Errorf("%s Get(state) = %q, %v, %v; want %q", tt.kv.namespace, got, ok, err, tt.want)
+		}
+	}
+	var got string
+	if ok, err := nested.Get(ctx, "state", &got); err != nil || ok {
+		t.Errorf("nested Get(state) = %q, %v, %v; want missing", got, ok, err)
+	}
+
+	if err := a.Delete(ctx, "state"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := b.Get(ctx, "state", &got); !ok || got != "pokemon" {
+		t.Errorf("Delete() in one namespace removed another's key")
+	}
+}
+
+func TestExportTrackerKeyUnchanged(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+
+	tracker := NewExportTracker(log, bucket, "data-full/games")
+	tracker.MarkExported("magic/mtgtop8/1.json")
+	if err := tracker.Save(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Existing tracker files must keep loading after the move to BlobKV
+	if ok, err := bucket.Exists(ctx, "data-full/games/.export_tracker.json"); err != nil || !ok {
+		t.Errorf("Exists(.export_tracker.json) = %v, %v; want true", ok, err)
+	}
+}
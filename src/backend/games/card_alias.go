@@ -0,0 +1,111 @@
+package games
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// CardAliases maps alias -> canonical card name, for spellings sources
+// disagree on ("Lim-Dul's Vault" for "Lim-Dûl's Vault"). Build with
+// NewCardAliases so keys are normalized for lookup.
+type CardAliases map[string]string
+
+//go:embed assets/card_aliases.json
+var defaultCardAliasesJSON []byte
+
+// DefaultCardAliases is the alias table embedded from
+// assets/card_aliases.json, which Canonicalize applies
+var DefaultCardAliases = mustParseCardAliases(defaultCardAliasesJSON)
+
+// NewCardAliases normalizes an alias table. Keys go through nameKey,
+// so diacritics, ligatures, case and apostrophe or dash variants don't
+// matter. Each canonical name is also added as an alias of itself, so any
+// spelling that folds to it resolves too.
+func NewCardAliases(raw map[string]string) CardAliases {
+	aliases := make(CardAliases, 2*len(raw))
+	for alias, canonical := range raw {
+		aliases[nameKey(alias)] = canonical
+		aliases[nameKey(canonical)] = canonical
+	}
+	return aliases
+}
+
+// LoadCardAliases reads an alias file: a JSON object of alias -> canonical
+// name, e.g.
+//
+//	{"Juzam Djinn": "Juzám Djinn", "Æther Vial": "Aether Vial"}
+func LoadCardAliases(path string) (CardAliases, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := parseCardAliases(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse card aliases %s: %w", path, err)
+	}
+	return aliases, nil
+}
+
+func parseCardAliases(data []byte) (CardAliases, error) {
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return NewCardAliases(raw), nil
+}
+
+func mustParseCardAliases(data []byte) CardAliases {
+	aliases, err := parseCardAliases(data)
+	if err != nil {
+		panic(fmt.Sprintf("embedded card aliases: %v", err))
+	}
+	return aliases
+}
+
+// Resolve returns the canonical spelling of a card name, or name unchanged
+// when it has no alias
+func (a CardAliases) Resolve(name string) string {
+	if canonical, ok := a[nameKey(name)]; ok {
+		return canonical
+	}
+	return name
+}
+
+// ResolveCardAlias returns the canonical spelling of a card name from
+// DefaultCardAliases
+func ResolveCardAlias(name string) string {
+	return DefaultCardAliases.Resolve(name)
+}
+
+// nameFolds spells out ligatures and unifies punctuation variants
+var nameFolds = strings.NewReplacer(
+	"æ", "ae",
+	"œ", "oe",
+	"’", "'",
+	"‘", "'",
+	"‐", "-",
+	"‑", "-",
+	"–", "-",
+	"—", "-",
+)
+
+// nameKey folds case, diacritics, ligatures, apostrophe and dash
+// variants and repeated whitespace, for matching card and player names
+// spelled differently by different sources
+func nameKey(name string) string {
+	name = strings.ToLower(NormalizeCardName(name))
+	name = nameFolds.Replace(name)
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package games
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResolveCardAlias(t *testing.T) {
+	tests := map[string]string{
+		"Lim-Dul's Vault":  "Lim-Dûl's Vault",
+		"lim-dul’s vault":  "Lim-Dûl's Vault",
+		"Lim-Dûl's Vault":  "Lim-Dûl's Vault",
+		"Juzam Djinn":      "Juzám Djinn",
+		"  JUZAM   DJINN ": "Juzám Djinn",
+		"Æther Vial":       "Aether Vial",
+		"Dandan":           "Dandân",
+		"Lightning Bolt":   "Lightning Bolt",
+		"Fire // Ice":      "Fire // Ice",
+	}
+	for name, want := range tests {
+		if got := ResolveCardAlias(name); got != want {
+			t.Errorf("ResolveCardAlias(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestLoadCardAliases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aliases.json")
+	if err := os.WriteFile(path, []byte(`{"Bolt": "Lightning Bolt"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	aliases, err := LoadCardAliases(path)
+	if err != nil {
+		t.Fatalf("LoadCardAliases() error = %v", err)
+	}
+	for name, want := range map[string]string{"bolt": "Lightning Bolt", "LIGHTNING BOLT": "Lightning Bolt", "Juzam Djinn": "Juzam Djinn"} {
+		if got := aliases.Resolve(name); got != want {
+			t.Errorf("Resolve(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(`["Bolt"]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCardAliases(path); err == nil {
+		t.Error("LoadCardAliases() of a JSON array succeeded, want error")
+	}
+}
+
+func TestCanonicalizeCardAliases(t *testing.T) {
+	collection := func(cards ...CardDesc) Collection {
+		return Collection{
+			ID:          "test-123",
+			URL:         "https://example.com/test",
+			Type:        CollectionTypeWrapper{Type: "TestType", Inner: &testCollectionType{}},
+			ReleaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Partitions:  []Partition{{Name: "Main", Cards: cards}},
+		}
+	}
+	a := collection(CardDesc{Name: "Lim-Dûl's Vault", Count: 1}, CardDesc{Name: "Juzám Djinn", Count: 4})
+	b := collection(CardDesc{Name: "Juzam Djinn", Count: 4}, CardDesc{Name: "Lim-Dul's Vault", Count: 1})
+	for _, c := range []*Collection{&a, &b} {
+		if err := c.Canonicalize(); err != nil {
+			t.Fatalf("Canonicalize() error = %v", err)
+		}
+		c.ComputeContentHash()
+	}
+	for i, card := range a.Partitions[0].Cards {
+		if b.Partitions[0].Cards[i] != card {
+			t.Errorf("card %d = %+v, want %+v", i, b.Partitions[0].Cards[i], card)
+		}
+	}
+	if a.ContentHash != b.ContentHash {
+		t.Error("collections differing only by card aliases have different content hashes")
+	}
+}
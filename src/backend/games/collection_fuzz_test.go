@@ -0,0 +1,102 @@
+package games_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"collections/games"
+	_ "collections/games/digimon/game"
+	_ "collections/games/onepiece/game"
+	_ "collections/games/pokemon/game"
+	_ "collections/games/riftbound/game"
+	_ "collections/games/yugioh/game"
+)
+
+// FuzzCollectionRoundTrip checks that any collection which parses and
+// canonicalizes survives marshal/unmarshal unchanged: canonicalizing is
+// idempotent, the round-tripped JSON is identical, and the content hash is
+// stable. Inputs that are not valid collections are skipped.
+//
+// Seeds are the collections in testdata/seed_collections, each stored in
+// the indented form json.MarshalIndent gives it after Canonicalize; run with
+// go test ./games -run '^$' -fuzz FuzzCollectionRoundTrip
+func FuzzCollectionRoundTrip(f *testing.F) {
+	seeds, err := filepath.Glob(filepath.Join("testdata", "seed_collections", "*.json"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	if len(seeds) == 0 {
+		f.Skip("no seed collections in testdata/seed_collections")
+	}
+	for _, path := range seeds {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		var c games.Collection
+		if err := json.Unmarshal(data, &c); err != nil {
+			f.Fatalf("seed %s: %v", path, err)
+		}
+		if err := c.Canonicalize(); err != nil {
+			f.Fatalf("seed %s: %v", path, err)
+		}
+		canonical, err := json.MarshalIndent(&c, "", "  ")
+		if err != nil {
+			f.Fatalf("seed %s: %v", path, err)
+		}
+		if !bytes.Equal(bytes.TrimSpace(data), canonical) {
+			f.Fatalf("seed %s is not a canonicalized collection:\n%s", path, canonical)
+		}
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var c games.Collection
+		if err := json.Unmarshal(data, &c); err != nil {
+			return
+		}
+		if err := c.Canonicalize(); err != nil {
+			return
+		}
+		first, err := json.Marshal(&c)
+		if err != nil {
+			return // e.g. a year json cannot encode
+		}
+
+		if err := c.Canonicalize(); err != nil {
+			t.Fatalf("second Canonicalize() error = %v", err)
+		}
+		again, err := json.Marshal(&c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(first, again) {
+			t.Fatalf("Canonicalize() not idempotent:\n%s\n%s", first, again)
+		}
+
+		var rt games.Collection
+		if err := json.Unmarshal(first, &rt); err != nil {
+			t.Fatalf("Unmarshal(Marshal(c)) error = %v\n%s", err, first)
+		}
+		if err := rt.Canonicalize(); err != nil {
+			t.Fatalf("Canonicalize() after round trip error = %v\n%s", err, first)
+		}
+		second, err := json.Marshal(&rt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(first, second) {
+			t.Fatalf("round trip changed collection:\n%s\n%s", first, second)
+		}
+
+		c.ContentHash, rt.ContentHash = "", ""
+		c.ComputeContentHash()
+		rt.ComputeContentHash()
+		if c.ContentHash != rt.ContentHash {
+			t.Fatalf("ContentHash = %s after round trip, want %s", rt.ContentHash, c.ContentHash)
+		}
+	})
+}
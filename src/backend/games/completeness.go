@@ -0,0 +1,89 @@
+package games
+
+import "strings"
+
+// Completeness grades how fully a deck was parsed, so downstream filters
+// can drop decks a parser only partly extracted
+type Completeness string
+
+const (
+	// CompletenessFull is a deck with at least its format's card count
+	// and a known format
+	CompletenessFull Completeness = "full"
+	// CompletenessPartial is a deck short of its format's card count, or
+	// missing its format
+	CompletenessPartial Completeness = "partial"
+	// CompletenessSuspect is a deck with under half its format's card
+	// count, most likely a failed parse
+	CompletenessSuspect Completeness = "suspect"
+)
+
+// deckSizes is the minimum main deck size of each deck collection type
+var deckSizes = map[string]int{
+	"Deck":          60, // MTG constructed; see formatDeckSizes
+	"PokemonDeck":   60,
+	"YGODeck":       40,
+	"OnePieceDeck":  50,
+	"DigimonDeck":   50,
+	"RiftboundDeck": 40,
+}
+
+// formatDeckSizes overrides deckSizes for formats with other minimums,
+// keyed by lowercased format
+var formatDeckSizes = map[string]int{
+	"commander":           100,
+	"edh":                 100,
+	"duel commander":      100,
+	"historic brawl":      100,
+	"brawl":               60,
+	"limited":             40,
+	"draft":               40,
+	"sealed":              40,
+	"oathbreaker":         60,
+	"canadian highlander": 100,
+}
+
+// ExpectedDeckSize is the minimum main deck size for a deck type and
+// format, or 0 when unknown
+func ExpectedDeckSize(deckType, format string) int {
+	if n, ok := formatDeckSizes[strings.ToLower(strings.TrimSpace(format))]; ok {
+		return n
+	}
+	return deckSizes[deckType]
+}
+
+// isMainPartition reports whether a partition counts toward the main deck
+// size: sideboards, extra decks and maybeboards do not
+func isMainPartition(name string) bool {
+	name = strings.ToLower(name)
+	for _, side := range []string{"side", "extra", "maybe", "considering", "prize"} {
+		if strings.Contains(name, side) {
+			return false
+		}
+	}
+	return true
+}
+
+// DeckCompleteness grades a deck from its main deck card count against
+// ExpectedDeckSize and whether its format is known. Types with no known
+// size are graded on metadata alone.
+func DeckCompleteness(deckType string, meta DeckMetadata, partitions []Partition) Completeness {
+	main := 0
+	for _, p := range partitions {
+		if !isMainPartition(p.Name) {
+			continue
+		}
+		for _, c := range p.Cards {
+			main += c.Count
+		}
+	}
+
+	expected := ExpectedDeckSize(deckType, meta.Format)
+	switch {
+	case expected > 0 && main*2 < expected:
+		return CompletenessSuspect
+	case expected > 0 && main < expected, meta.Format == "":
+		return CompletenessPartial
+	}
+	return CompletenessFull
+}
@@ -0,0 +1,83 @@
+package games
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// sizedDeckType is a deck type with a known size (60), for completeness
+// tests
+type sizedDeckType struct {
+	format string
+}
+
+func (t *sizedDeckType) Type() string      { return "PokemonDeck" }
+func (t *sizedDeckType) IsCollectionType() {}
+func (t *sizedDeckType) DeckMetadata() DeckMetadata {
+	return DeckMetadata{Format: t.format}
+}
+
+// cardsTotaling returns distinct cards with counts summing to n
+func cardsTotaling(n int) []CardDesc {
+	var cards []CardDesc
+	for i := 0; n > 0; i++ {
+		count := min(n, 4)
+		cards = append(cards, CardDesc{Name: fmt.Sprintf("Card %d", i), Count: count})
+		n -= count
+	}
+	return cards
+}
+
+func TestCanonicalizeCompleteness(t *testing.T) {
+	tests := []struct {
+		name       string
+		inner      CollectionType
+		partitions []Partition
+		want       Completeness
+	}{
+		{"full deck", &sizedDeckType{format: "Standard"}, []Partition{{Name: "Deck", Cards: cardsTotaling(60)}}, CompletenessFull},
+		{"missing format", &sizedDeckType{}, []Partition{{Name: "Deck", Cards: cardsTotaling(60)}}, CompletenessPartial},
+		{"short deck", &sizedDeckType{format: "Standard"}, []Partition{{Name: "Deck", Cards: cardsTotaling(45)}}, CompletenessPartial},
+		{"sideboard only", &sizedDeckType{format: "Standard"}, []Partition{
+			{Name: "Deck", Cards: cardsTotaling(12)},
+			{Name: "Sideboard", Cards: cardsTotaling(40)},
+		}, CompletenessSuspect},
+		{"not a deck", &testCollectionType{}, []Partition{{Name: "Cards", Cards: cardsTotaling(5)}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Collection{
+				ID:          "test",
+				URL:         "https://example.com/test",
+				Type:        CollectionTypeWrapper{Type: tt.inner.Type(), Inner: tt.inner},
+				ReleaseDate: time.Now(),
+				Partitions:  tt.partitions,
+			}
+			if err := c.Canonicalize(); err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			if c.Completeness != tt.want {
+				t.Errorf("Completeness = %q, want %q", c.Completeness, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpectedDeckSize(t *testing.T) {
+	tests := []struct {
+		deckType, format string
+		want             int
+	}{
+		{"Deck", "Modern", 60},
+		{"Deck", " Commander ", 100},
+		{"Deck", "Draft", 40},
+		{"YGODeck", "TCG", 40},
+		{"UnknownDeck", "", 0},
+	}
+	for _, tt := range tests {
+		if got := ExpectedDeckSize(tt.deckType, tt.format); got != tt.want {
+			t.Errorf("ExpectedDeckSize(%q, %q) = %d, want %d", tt.deckType, tt.format, got, tt.want)
+		}
+	}
+}
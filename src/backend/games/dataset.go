@@ -62,6 +62,10 @@ type OptExtractItemLimit struct{ Limit int }
 type OptExtractItemOnlyURL struct{ URL string }
 type OptExtractItemCat struct{}
 
+// OptExtractRepoRef pins repo-backed datasets (pokemontcg-data) to a
+// commit, tag or branch instead of the latest HEAD
+type OptExtractRepoRef struct{ Ref string }
+
 func (o *OptExtractReparse) updateOption()            {}
 func (o *OptExtractScraperReplaceAll) updateOption()  {}
 func (o *OptExtractScraperSkipMissing) updateOption() {}
@@ -73,6 +77,7 @@ func (o *OptExtractScrollStart) updateOption()        {}
 func (o *OptExtractItemLimit) updateOption()          {}
 func (o *OptExtractItemOnlyURL) updateOption()        {}
 func (o *OptExtractItemCat) updateOption()            {}
+func (o *OptExtractRepoRef) updateOption()            {}
 
 // ResolvedUpdateOptions are the normalized extraction options
 type ResolvedUpdateOptions struct {
@@ -85,6 +90,7 @@ type ResolvedUpdateOptions struct {
 	ItemLimit       mo.Option[int]
 	ItemOnlyURLs    []string
 	Cat             bool
+	RepoRef         string
 	// Cached compiled regexes for Section() to avoid recompilation
 	sectionRegexCache map[string]*regexp.Regexp
 	sectionRegexMu    sync.RWMutex
@@ -136,6 +142,7 @@ func ResolveUpdateOptions(options ...UpdateOption) (ResolvedUpdateOptions, error
 	var collectionLimit mo.Option[int]
 	var onlyCollectionURLs []string
 	var cat mo.Option[bool]
+	var repoRef string
 
 	for _, opt := range options {
 		switch opt := opt.(type) {
@@ -164,6 +171,8 @@ func ResolveUpdateOptions(options ...UpdateOption) (ResolvedUpdateOptions, error
 			onlyCollectionURLs = append(onlyCollectionURLs, opt.URL)
 		case *OptExtractItemCat:
 			cat = mo.Some(true)
+		case *OptExtractRepoRef:
+			repoRef = opt.Ref
 		default:
 			panic(fmt.Sprintf("invalid option: %T", opt))
 		}
@@ -179,6 +188,7 @@ func ResolveUpdateOptions(options ...UpdateOption) (ResolvedUpdateOptions, error
 		ItemLimit:       collectionLimit,
 		ItemOnlyURLs:    onlyCollectionURLs,
 		Cat:             cat.OrElse(false),
+		RepoRef:         repoRef,
 	}, nil
 }
 
@@ -233,8 +243,15 @@ type OptIterItemsParallel struct {
 	Parallel int
 }
 
-func (o *OptIterItemsFilterType) iterItemsOption() {}
-func (o *OptIterItemsParallel) iterItemsOption()   {}
+// OptIterItemsListRetries sets how many times a failed blob listing page is
+// retried before iteration aborts (see blob.OptListMaxRetries)
+type OptIterItemsListRetries struct {
+	MaxRetries int
+}
+
+func (o *OptIterItemsFilterType) iterItemsOption()  {}
+func (o *OptIterItemsParallel) iterItemsOption()    {}
+func (o *OptIterItemsListRetries) iterItemsOption() {}
 
 // --- Helper Functions ---
 
@@ -264,6 +281,9 @@ func IterItemsBlobPrefix(
 	options ...IterItemsOption,
 ) error {
 	parallel := 64 // Lowered from 512 for safety
+	listOpts := []blob.ListOption{&blob.OptListPrefix{
+		Prefix: prefix,
+	}}
 	for _, opt := range options {
 		switch opt := opt.(type) {
 		case *OptIterItemsParallel:
@@ -271,12 +291,15 @@ func IterItemsBlobPrefix(
 				return fmt.Errorf("parallel must be 1-1024, got %d", opt.Parallel)
 			}
 			parallel = opt.Parallel
+		case *OptIterItemsListRetries:
+			if opt.MaxRetries < 0 {
+				return fmt.Errorf("list retries must be non-negative, got %d", opt.MaxRetries)
+			}
+			listOpts = append(listOpts, &blob.OptListMaxRetries{MaxRetries: opt.MaxRetries})
 		}
 	}
 
-	it := b.List(ctx, &blob.OptListPrefix{
-		Prefix: prefix,
-	})
+	it := b.List(ctx, listOpts...)
 
 	// Buffered error channel
 	errChan := make(chan error, parallel)
@@ -51,3 +51,81 @@ func ParseDateWithFallback(dateStr string, fallback time.Time) time.Time {
 	}
 	return fallback
 }
+
+// DateRange selects collections by ReleaseDate. A zero Since or Until
+// leaves that side open; the zero DateRange matches everything.
+type DateRange struct {
+	Since time.Time // Inclusive
+	Until time.Time // Inclusive
+}
+
+// ParseDateRange parses --since and --until values, each RFC3339 or
+// 2006-01-02; an empty value leaves that side open. A date-only until
+// covers the whole day.
+func ParseDateRange(since, until string) (DateRange, error) {
+	var r DateRange
+	var err error
+	if since != "" {
+		if r.Since, _, err = parseRangeBound(since); err != nil {
+			return DateRange{}, fmt.Errorf("invalid --since %q: %w", since, err)
+		}
+	}
+	if until != "" {
+		var dateOnly bool
+		if r.Until, dateOnly, err = parseRangeBound(until); err != nil {
+			return DateRange{}, fmt.Errorf("invalid --until %q: %w", until, err)
+		}
+		if dateOnly {
+			r.Until = r.Until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+	}
+	if !r.Since.IsZero() && !r.Until.IsZero() && r.Since.After(r.Until) {
+		return DateRange{}, fmt.Errorf("--since %s is after --until %s", since, until)
+	}
+	return r, nil
+}
+
+// parseRangeBound parses an RFC3339 time or a 2006-01-02 date, reporting
+// which it was
+func parseRangeBound(s string) (time.Time, bool, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("expected RFC3339 or YYYY-MM-DD")
+	}
+	return t, true, nil
+}
+
+// IsZero reports whether r has neither bound
+func (r DateRange) IsZero() bool {
+	return r.Since.IsZero() && r.Until.IsZero()
+}
+
+// Contains reports whether t is within r. A zero t, an unknown release
+// date, is outside any range with a bound.
+func (r DateRange) Contains(t time.Time) bool {
+	if r.IsZero() {
+		return true
+	}
+	if t.IsZero() {
+		return false
+	}
+	if !r.Since.IsZero() && t.Before(r.Since) {
+		return false
+	}
+	return r.Until.IsZero() || !t.After(r.Until)
+}
+
+// String formats r as since..until in RFC3339, for logs and usage lines
+func (r DateRange) String() string {
+	format := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	return format(r.Since) + ".." + format(r.Until)
+}
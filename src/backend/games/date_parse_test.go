@@ -108,3 +108,44 @@ func TestParseDateWithFallback(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDateRange(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	r, err := ParseDateRange("2024-03-01", "2024-03-31")
+	if err != nil {
+		t.Fatalf("ParseDateRange() error = %v", err)
+	}
+	tests := []struct {
+		date time.Time
+		want bool
+	}{
+		{day(2024, 2, 29), false},
+		{day(2024, 3, 1), true},
+		{day(2024, 3, 31).Add(23 * time.Hour), true}, // A date-only until covers the day
+		{day(2024, 4, 1), false},
+		{time.Time{}, false},
+	}
+	for _, tt := range tests {
+		if got := r.Contains(tt.date); got != tt.want {
+			t.Errorf("Contains(%v) = %v, want %v", tt.date, got, tt.want)
+		}
+	}
+
+	r, err = ParseDateRange("2024-03-01T12:00:00Z", "")
+	if err != nil {
+		t.Fatalf("ParseDateRange() error = %v", err)
+	}
+	if r.Contains(day(2024, 3, 1)) || !r.Contains(day(2030, 1, 1)) || r.Contains(time.Time{}) {
+		t.Errorf("open-ended range %v matched wrongly", r)
+	}
+	if r, _ := ParseDateRange("", ""); !r.IsZero() || !r.Contains(time.Time{}) {
+		t.Errorf("empty range %v should match everything", r)
+	}
+
+	for _, tt := range [][2]string{{"2024-04-01", "2024-03-01"}, {"yesterday", ""}, {"", "03/01/2024"}} {
+		if _, err := ParseDateRange(tt[0], tt[1]); err == nil {
+			t.Errorf("ParseDateRange(%q, %q) error = nil, want error", tt[0], tt[1])
+		}
+	}
+}
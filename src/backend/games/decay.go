@@ -0,0 +1,98 @@
+package games
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// EffectiveDate picks the date a deck was played: the event date when it
+// parses, else the release date. The release date is estimated when it is
+// missing or within a day of scrapedAt, since scrapers fall back to the
+// scrape time when a page has no date.
+func EffectiveDate(eventDate string, releaseDate, scrapedAt time.Time) (time.Time, bool) {
+	if t, err := ParseDateWithValidation(eventDate); err == nil {
+		return t, false
+	}
+	if releaseDate.IsZero() {
+		return time.Time{}, true
+	}
+	if !scrapedAt.IsZero() {
+		if d := scrapedAt.Sub(releaseDate); d > -24*time.Hour && d < 24*time.Hour {
+			return releaseDate, true
+		}
+	}
+	return releaseDate, false
+}
+
+// DecayWeight returns 0.5^(age/halfLife) for something dated date, so it
+// halves every halfLife. Dates after now weigh 1.
+func DecayWeight(date, now time.Time, halfLife time.Duration) float64 {
+	age := now.Sub(date)
+	if age <= 0 || halfLife <= 0 {
+		return 1
+	}
+	return math.Exp2(-float64(age) / float64(halfLife))
+}
+
+// Reasons DatePolicy.Weigh skips a deck
+const (
+	SkipEstimated = "estimated date"
+	SkipAfterAsOf = "after as-of date"
+	SkipReleased  = "released out of range"
+)
+
+// DatePolicy filters and weights decks by EffectiveDate, and filters them
+// by release date. The zero value includes everything at weight 1.
+type DatePolicy struct {
+	AsOf     time.Time     // exclusive end of the as-of day; zero disables
+	HalfLife time.Duration // zero disables decay
+	Now      time.Time     // decay reference: the as-of cutoff when set
+	Released DateRange     // zero disables
+}
+
+// NewDatePolicy builds a DatePolicy from an as-of day (YYYY-MM-DD, empty
+// for none) and a decay half-life in days, decaying relative to now
+// unless the as-of day is set.
+func NewDatePolicy(asOf string, halfLifeDays float64, now time.Time) (DatePolicy, error) {
+	p := DatePolicy{
+		HalfLife: time.Duration(halfLifeDays * float64(24*time.Hour)),
+		Now:      now,
+	}
+	if asOf != "" {
+		t, err := time.Parse("2006-01-02", asOf)
+		if err != nil {
+			return DatePolicy{}, fmt.Errorf("invalid as-of date %q: %w", asOf, err)
+		}
+		p.AsOf = t.AddDate(0, 0, 1)
+		p.Now = p.AsOf
+	}
+	return p, nil
+}
+
+// Enabled reports whether the policy filters or weights by effective date
+func (p DatePolicy) Enabled() bool {
+	return p.HalfLife > 0 || !p.AsOf.IsZero()
+}
+
+// Weigh returns the decay weight of a deck with the given dates (see
+// EffectiveDate), or the reason it should be skipped
+func (p DatePolicy) Weigh(eventDate string, releaseDate, scrapedAt time.Time) (float64, string) {
+	if !p.Released.Contains(releaseDate) {
+		return 0, SkipReleased
+	}
+	if !p.Enabled() {
+		return 1, ""
+	}
+	date, estimated := EffectiveDate(eventDate, releaseDate, scrapedAt)
+	if estimated {
+		return 0, SkipEstimated
+	}
+	if !p.AsOf.IsZero() && !date.Before(p.AsOf) {
+		return 0, SkipAfterAsOf
+	}
+	if p.HalfLife <= 0 {
+		return 1, ""
+	}
+	return DecayWeight(date, p.Now, p.HalfLife), ""
+}
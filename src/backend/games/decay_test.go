@@ -0,0 +1,98 @@
+package games
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEffectiveDate(t *testing.T) {
+	release := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	scraped := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		eventDate     string
+		release       time.Time
+		scraped       time.Time
+		want          time.Time
+		wantEstimated bool
+	}{
+		{"event date", "2024-02-10", release, scraped, time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC), false},
+		{"release date", "", release, scraped, release, false},
+		{"unparseable event date", "last week", release, time.Time{}, release, false},
+		{"release is scrape time", "", scraped.Add(-time.Hour), scraped, scraped.Add(-time.Hour), true},
+		{"no dates", "", time.Time{}, scraped, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, estimated := EffectiveDate(tt.eventDate, tt.release, tt.scraped)
+		if !got.Equal(tt.want) || estimated != tt.wantEstimated {
+			t.Errorf("%s: EffectiveDate() = %v, %v, want %v, %v", tt.name, got, estimated, tt.want, tt.wantEstimated)
+		}
+	}
+}
+
+func TestDecayWeight(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	halfLife := 30 * 24 * time.Hour
+	tests := []struct {
+		age  time.Duration
+		want float64
+	}{
+		{0, 1},
+		{-halfLife, 1},
+		{halfLife, 0.5},
+		{2 * halfLife, 0.25},
+	}
+	for _, tt := range tests {
+		if got := DecayWeight(now.Add(-tt.age), now, halfLife); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("DecayWeight(age %v) = %v, want %v", tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestDatePolicy(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	asOf, err := NewDatePolicy("2024-05-01", 0, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decay, err := NewDatePolicy("", 30, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	released, err := ParseDateRange("2024-01-01", "2024-01-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		policy     DatePolicy
+		eventDate  string
+		release    time.Time
+		scraped    time.Time
+		wantWeight float64
+		wantSkip   string
+	}{
+		{"zero policy", DatePolicy{}, "", time.Time{}, time.Time{}, 1, ""},
+		{"before as-of", asOf, "2024-05-01", release, time.Time{}, 1, ""},
+		{"after as-of", asOf, "2024-05-02", release, time.Time{}, 0, SkipAfterAsOf},
+		{"undated", asOf, "", time.Time{}, time.Time{}, 0, SkipEstimated},
+		{"scrape-time release", decay, "", now, now, 0, SkipEstimated},
+		{"one half-life", decay, "2024-05-02", release, time.Time{}, 0.5, ""},
+		{"two half-lives", decay, "2024-04-02", release, time.Time{}, 0.25, ""},
+		{"released in range", DatePolicy{Released: released}, "", release, time.Time{}, 1, ""},
+		{"released out of range", DatePolicy{Released: released}, "", now, time.Time{}, 0, SkipReleased},
+		{"unknown release with range", DatePolicy{Released: released}, "2024-01-15", time.Time{}, time.Time{}, 0, SkipReleased},
+	}
+	for _, tt := range tests {
+		weight, skip := tt.policy.Weigh(tt.eventDate, tt.release, tt.scraped)
+		if math.Abs(weight-tt.wantWeight) > 1e-9 || skip != tt.wantSkip {
+			t.Errorf("%s: Weigh() = %v, %q, want %v, %q", tt.name, weight, skip, tt.wantWeight, tt.wantSkip)
+		}
+	}
+
+	if _, err := NewDatePolicy("June 2024", 0, now); err == nil {
+		t.Error("NewDatePolicy(\"June 2024\") error = nil, want error")
+	}
+}
@@ -0,0 +1,36 @@
+package games
+
+// DeckMetadata is the tournament metadata every game's deck collection
+// type carries, under game-specific field types
+type DeckMetadata struct {
+	Name      string
+	Archetype string
+	Format    string
+	Player    string
+	Event     string
+	EventDate string
+	// Placement is the finishing position (1 = 1st place), nil when
+	// unknown
+	Placement *int
+}
+
+// DeckMetadataProvider is implemented by deck collection types
+type DeckMetadataProvider interface {
+	DeckMetadata() DeckMetadata
+}
+
+// DeckMetadataOf returns the deck metadata of a collection type's inner
+// value, or false for sets, cubes and other non-deck types. inner is any
+// so MTG's collection types, which are not CollectionTypes, work too.
+func DeckMetadataOf(inner any) (DeckMetadata, bool) {
+	if p, ok := inner.(DeckMetadataProvider); ok {
+		return p.DeckMetadata(), true
+	}
+	return DeckMetadata{}, false
+}
+
+// DeckMetadata returns the wrapped type's deck metadata, or false if it is
+// not a deck
+func (w CollectionTypeWrapper) DeckMetadata() (DeckMetadata, bool) {
+	return DeckMetadataOf(w.Inner)
+}
@@ -0,0 +1,304 @@
+// Package decksite scrapes deck sites that link their decks from paginated
+// listing pages and show one deck per page. Datasets describe a site with
+// its selectors and a parser for its deck pages; listing, pagination,
+// skipping stored decks, the worker pool and stats are shared here.
+package decksite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"collections/blob"
+	"collections/games"
+	"collections/logger"
+	"collections/scraper"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// defaultMaxPages bounds the listing pages followed without a ScrollLimit
+const defaultMaxPages = 10
+
+// Site describes a deck site
+type Site struct {
+	// Name is the dataset name errors are recorded under
+	Name string
+	// Listing is the first listing page
+	Listing *url.URL
+	// DeckLinks selects the deck links on a listing page
+	DeckLinks string
+	// DeckPath matches the path of a deck page; its first group is the
+	// deck ID
+	DeckPath *regexp.Regexp
+	// NextPage selects a listing page's link to the next one
+	NextPage string
+	// Key returns the blob key a deck is stored under
+	Key func(deckID string) string
+	// Parse builds the collection stored for a deck page, dating it now
+	// when the page has no date
+	Parse func(doc *goquery.Document, deckID, deckURL string, now time.Time) (any, error)
+}
+
+// DeckID returns the ID of the deck at deckURL, or false if it isn't a
+// deck page on the site
+func (s *Site) DeckID(deckURL string) (string, bool) {
+	u, err := url.Parse(deckURL)
+	if err != nil || u.Host != s.Listing.Host {
+		return "", false
+	}
+	m := s.DeckPath.FindStringSubmatch(u.Path)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// ParseListingPage returns the deck URLs linked from the listing page at
+// pageURL that are not in seen, adding them to it, and the next listing
+// page's URL, empty on the last page
+func (s *Site) ParseListingPage(doc *goquery.Document, pageURL *url.URL, seen map[string]bool) ([]string, string) {
+	urls := []string{}
+	doc.Find(s.DeckLinks).Each(func(i int, sel *goquery.Selection) {
+		href, ok := sel.Attr("href")
+		if !ok {
+			return
+		}
+		u, err := pageURL.Parse(strings.TrimSpace(href))
+		if err != nil {
+			return
+		}
+		u.RawQuery = ""
+		u.Fragment = ""
+		deckURL := u.String()
+		if _, ok := s.DeckID(deckURL); !ok || seen[deckURL] {
+			return
+		}
+		seen[deckURL] = true
+		urls = append(urls, deckURL)
+	})
+
+	next := ""
+	if href, ok := doc.Find(s.NextPage).First().Attr("href"); ok {
+		if u, err := pageURL.Parse(strings.TrimSpace(href)); err == nil && u.String() != pageURL.String() {
+			next = u.String()
+		}
+	}
+	return urls, next
+}
+
+// Extract scrapes the site's decks into bucket: the decks linked from up
+// to ScrollLimit listing pages (10 by default), or ItemOnlyURLs, at most
+// ItemLimit of them. Decks already stored are skipped unless Reparse or
+// FetchReplaceAll is set. Failed decks are logged and recorded in the
+// context's stats; the number of decks written is returned.
+func (s *Site) Extract(
+	ctx context.Context,
+	log *logger.Logger,
+	bucket *blob.Bucket,
+	sc *scraper.Scraper,
+	opts *games.ResolvedUpdateOptions,
+) (int, error) {
+	deckURLs := opts.ItemOnlyURLs
+	if len(deckURLs) == 0 {
+		var err error
+		deckURLs, err = s.scrapeListingPages(ctx, log, sc, opts)
+		if err != nil {
+			return 0, fmt.Errorf("failed to scrape deck listings: %w", err)
+		}
+	}
+
+	log.Infof(ctx, "Found %d deck URLs to process", len(deckURLs))
+
+	// Process deck URLs in parallel using worker pool
+	tasks := make(chan string, len(deckURLs))
+	wg := new(sync.WaitGroup)
+	var totalDecks atomic.Int64
+
+	for i := 0; i < max(opts.Parallel, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case deckURL, ok := <-tasks:
+					if !ok {
+						return
+					}
+					if limit, ok := opts.ItemLimit.Get(); ok && int(totalDecks.Load()) >= limit {
+						return
+					}
+					if err := s.parseDeck(ctx, log, bucket, sc, deckURL, opts); err != nil {
+						log.Field("url", deckURL).Errorf(ctx, "Failed to parse deck: %v", err)
+						if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+							stats.RecordCategorizedError(ctx, deckURL, s.Name, err)
+						}
+						continue
+					}
+					if n := totalDecks.Add(1); n%10 == 0 {
+						log.Infof(ctx, "Processed %d/%d decks...", n, len(deckURLs))
+					}
+				}
+			}
+		}()
+	}
+
+	// Send all URLs to workers
+	for _, deckURL := range deckURLs {
+		if limit, ok := opts.ItemLimit.Get(); ok && int(totalDecks.Load()) >= limit {
+			break
+		}
+		tasks <- deckURL
+	}
+	close(tasks)
+	wg.Wait()
+
+	return int(totalDecks.Load()), nil
+}
+
+func (s *Site) scrapeListingPages(
+	ctx context.Context,
+	log *logger.Logger,
+	sc *scraper.Scraper,
+	opts *games.ResolvedUpdateOptions,
+) ([]string, error) {
+	maxPages := defaultMaxPages
+	if limit, ok := opts.ScrollLimit.Get(); ok {
+		maxPages = limit
+	}
+
+	allURLs := []string{}
+	seenURLs := make(map[string]bool)
+	pageURL := s.Listing.String()
+	for page := 1; page <= maxPages && pageURL != ""; page++ {
+		u, err := url.Parse(pageURL)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := fetchDocument(ctx, sc, opts, pageURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch listing page %d: %w", page, err)
+		}
+
+		var pageURLs []string
+		pageURLs, pageURL = s.ParseListingPage(doc, u, seenURLs)
+		if len(pageURLs) == 0 {
+			log.Infof(ctx, "No more decks found on page %d, stopping", page)
+			break
+		}
+
+		log.Infof(ctx, "Found %d deck URLs on page %d", len(pageURLs), page)
+		allURLs = append(allURLs, pageURLs...)
+	}
+
+	return allURLs, nil
+}
+
+func (s *Site) parseDeck(
+	ctx context.Context,
+	log *logger.Logger,
+	bucket *blob.Bucket,
+	sc *scraper.Scraper,
+	deckURL string,
+	opts *games.ResolvedUpdateOptions,
+) error {
+	deckID, ok := s.DeckID(deckURL)
+	if !ok {
+		return fmt.Errorf("failed to extract deck ID from URL")
+	}
+	bkey := s.Key(deckID)
+
+	if !opts.Reparse && !opts.FetchReplaceAll {
+		exists, err := bucket.Exists(ctx, bkey)
+		if err != nil {
+			return fmt.Errorf("failed to check if deck exists: %w", err)
+		}
+		if exists {
+			log.Field("deck_id", deckID).Debugf(ctx, "Deck already exists")
+			if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+				stats.RecordSkipped()
+			}
+			return nil
+		}
+	}
+
+	doc, err := fetchDocument(ctx, sc, opts, deckURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch deck page: %w", err)
+	}
+
+	collection, err := s.Parse(doc, deckID, deckURL, time.Now())
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(collection)
+	if err != nil {
+		return err
+	}
+
+	if err := bucket.Write(ctx, bkey, b); err != nil {
+		return err
+	}
+
+	if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+		stats.RecordSuccess()
+	}
+
+	return nil
+}
+
+func fetchDocument(
+	ctx context.Context,
+	sc *scraper.Scraper,
+	opts *games.ResolvedUpdateOptions,
+	pageURL string,
+) (*goquery.Document, error) {
+	req, err := http.NewRequest("GET", pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := games.Do(ctx, sc, opts, req)
+	if err != nil {
+		return nil, err
+	}
+	return goquery.NewDocumentFromReader(bytes.NewReader(resp.Response.Body))
+}
+
+// Meta is the event metadata deck pages list next to their cards
+type Meta struct {
+	Player    string
+	Event     string
+	Placement int // 0 when unknown
+	EventDate string
+	Format    string
+}
+
+// Set records value under a metadata label such as "Player" or
+// "Tournament", ignoring labels it doesn't know. Placements are parsed
+// with games.ParsePlacement.
+func (m *Meta) Set(label, value string) {
+	value = strings.Join(strings.Fields(value), " ")
+	switch strings.ToLower(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(label), ":"))) {
+	case "player", "pilot":
+		m.Player = value
+	case "event", "tournament":
+		m.Event = value
+	case "placement", "place", "rank":
+		m.Placement, _ = games.ParsePlacement(value)
+	case "date":
+		m.EventDate = value
+	case "format":
+		m.Format = value
+	}
+}
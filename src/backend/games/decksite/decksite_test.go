@@ -0,0 +1,190 @@
+package decksite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"collections/blob"
+	"collections/games"
+	"collections/logger"
+	"collections/scraper"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// testSite serves testdata/site, a two-page listing of the decks alpha,
+// beta and gamma, and counts the requests for each path
+func testSite(t *testing.T) (*Site, map[string]int) {
+	t.Helper()
+	var mu sync.Mutex
+	fetches := make(map[string]int)
+	files := http.FileServer(http.Dir("testdata/site"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetches[r.URL.Path]++
+		mu.Unlock()
+		files.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	listing, err := url.Parse(server.URL + "/deck-list/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Site{
+		Name:      "test",
+		Listing:   listing,
+		DeckLinks: "article.deck .entry-title a",
+		DeckPath:  regexp.MustCompile(`^/deck/([a-z]+)/$`),
+		NextPage:  ".pagination a.next",
+		Key:       func(deckID string) string { return "decks/" + deckID + ".json" },
+		Parse: func(doc *goquery.Document, deckID, deckURL string, now time.Time) (any, error) {
+			return map[string]string{"id": deckID, "title": doc.Find("h1.entry-title").Text()}, nil
+		},
+	}, fetches
+}
+
+func TestParseListingPage(t *testing.T) {
+	s, _ := testSite(t)
+	pageURL := s.Listing
+	doc := loadDocument(t, "testdata/site/deck-list/index.html")
+
+	seen := map[string]bool{}
+	urls, next := s.ParseListingPage(doc, pageURL, seen)
+	want := []string{pageURL.JoinPath("../deck/alpha/").String(), pageURL.JoinPath("../deck/beta/").String()}
+	if strings.Join(urls, " ") != strings.Join(want, " ") {
+		t.Errorf("ParseListingPage() urls = %v, want %v", urls, want)
+	}
+	if want := pageURL.JoinPath("page/2/").String(); next != want {
+		t.Errorf("ParseListingPage() next = %q, want %q", next, want)
+	}
+	if again, _ := s.ParseListingPage(doc, pageURL, seen); len(again) != 0 {
+		t.Errorf("ParseListingPage() with all seen = %v, want none", again)
+	}
+
+	last := loadDocument(t, "testdata/site/deck-list/page/2/index.html")
+	if _, next := s.ParseListingPage(last, pageURL.JoinPath("page/2/"), seen); next != "" {
+		t.Errorf("ParseListingPage() of the last page next = %q, want none", next)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	s, fetches := testSite(t)
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+	scraperBlob := blob.NewMemBucket(ctx, log)
+	defer scraperBlob.Close(ctx)
+	sc := scraper.NewScraper(log, scraperBlob)
+
+	extract := func(t *testing.T, want int, options ...games.UpdateOption) {
+		t.Helper()
+		// One worker, so ItemLimit stops at exactly the limit
+		opts, err := games.ResolveUpdateOptions(append(options, &games.OptExtractParallel{Parallel: 1})...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := s.Extract(ctx, log, bucket, sc, &opts)
+		if err != nil {
+			t.Fatalf("Extract() error = %v", err)
+		}
+		if n != want {
+			t.Errorf("Extract() = %d decks, want %d", n, want)
+		}
+	}
+
+	t.Run("scroll limit", func(t *testing.T) {
+		extract(t, 2, &games.OptExtractScrollLimit{Limit: 1})
+		if fetches["/deck-list/page/2/"] != 0 {
+			t.Error("fetched listing page 2 past the scroll limit")
+		}
+		for _, id := range []string{"alpha", "beta"} {
+			data, err := bucket.Read(ctx, "decks/"+id+".json")
+			if err != nil {
+				t.Fatalf("deck %s not stored: %v", id, err)
+			}
+			var got map[string]string
+			if err := json.Unmarshal(data, &got); err != nil || got["id"] != id || got["title"] != id {
+				t.Errorf("deck %s = %s, %v", id, data, err)
+			}
+		}
+	})
+
+	// A stored deck is left alone unless reparsing
+	if err := bucket.Write(ctx, "decks/alpha.json", []byte(`{"id":"stored"}`)); err != nil {
+		t.Fatal(err)
+	}
+	alpha := func(t *testing.T) string {
+		t.Helper()
+		data, err := bucket.Read(ctx, "decks/alpha.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	t.Run("next pages", func(t *testing.T) {
+		extract(t, 3)
+		if ok, _ := bucket.Exists(ctx, "decks/gamma.json"); !ok {
+			t.Error("deck gamma from listing page 2 not stored")
+		}
+		if got := alpha(t); got != `{"id":"stored"}` {
+			t.Errorf("stored deck alpha rewritten to %s without reparse", got)
+		}
+	})
+
+	t.Run("item only reparse", func(t *testing.T) {
+		extract(t, 1, &games.OptExtractItemOnlyURL{URL: s.Listing.JoinPath("../deck/alpha/").String()}, &games.OptExtractReparse{})
+		if got := alpha(t); got == `{"id":"stored"}` {
+			t.Error("deck alpha not rewritten on reparse")
+		}
+	})
+
+	t.Run("item limit", func(t *testing.T) {
+		extract(t, 1, &games.OptExtractItemLimit{Limit: 1}, &games.OptExtractReparse{})
+	})
+}
+
+func TestMetaSet(t *testing.T) {
+	var m Meta
+	for _, row := range [][2]string{
+		{"Player", " Alice "},
+		{"Tournament:", "Regional   Championship"},
+		{"Placement", "Top 4"},
+		{"Date", "2024-05-12"},
+		{"Format", "BT17"},
+		{"Country", "Japan"},
+	} {
+		m.Set(row[0], row[1])
+	}
+	want := Meta{Player: "Alice", Event: "Regional Championship", Placement: 4, EventDate: "2024-05-12", Format: "BT17"}
+	if m != want {
+		t.Errorf("Meta = %+v, want %+v", m, want)
+	}
+}
+
+func loadDocument(t *testing.T, path string) *goquery.Document {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
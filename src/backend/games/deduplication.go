@@ -4,9 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
-	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
@@ -45,11 +43,45 @@ func ComputeDeckSignature(c *Collection) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// DeckFingerprint identifies a tournament deck across sources: a hash of
+// the cards and counts of its main partition (the first in
+// PartitionOrder) with its player and event. Card, player and event names
+// are folded with nameKey and card aliases resolved, so the same list
+// scraped from two sites fingerprints the same regardless of partition
+// order or spelling. Sideboards are left out, since not every source
+// lists them.
+func DeckFingerprint(c *Collection) string {
+	var main *Partition
+	for i := range c.Partitions {
+		if main == nil || PartitionLess(c.Partitions[i].Name, main.Name) {
+			main = &c.Partitions[i]
+		}
+	}
+	counts := make(map[string]int)
+	if main != nil {
+		for _, card := range main.Cards {
+			counts[nameKey(ResolveCardAlias(card.Name))] += card.Count
+		}
+	}
+	cards := make([]string, 0, len(counts))
+	for name, count := range counts {
+		cards = append(cards, fmt.Sprintf("%s:%d", name, count))
+	}
+	sort.Strings(cards)
+
+	meta, _ := c.Type.DeckMetadata()
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s", nameKey(meta.Player), nameKey(meta.Event), strings.Join(cards, "\n"))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// deduplicationKey is the tracker's BlobKV key under its prefix
+const deduplicationKey = ".deduplication"
+
 // DeduplicationTracker tracks deck signatures to identify duplicates across sources
 type DeduplicationTracker struct {
-	blob       *blob.Bucket
+	kv         *BlobKV
 	log        *logger.Logger
-	prefix     string
 	signatures map[string]*DeckSignature // card signature -> deck signature
 	mu         sync.RWMutex               // Protects signatures map for concurrent access
 }
@@ -57,35 +89,24 @@ type DeduplicationTracker struct {
 // NewDeduplicationTracker creates a new deduplication tracker
 func NewDeduplicationTracker(log *logger.Logger, blob *blob.Bucket, prefix string) *DeduplicationTracker {
 	return &DeduplicationTracker{
-		blob:       blob,
+		kv:         NewBlobKV(blob, prefix),
 		log:        log,
-		prefix:     prefix,
 		signatures: make(map[string]*DeckSignature),
 	}
 }
 
 // Load loads deduplication data from blob storage
 func (dt *DeduplicationTracker) Load(ctx context.Context) error {
-	key := dt.trackingKey()
-	exists, err := dt.blob.Exists(ctx, key)
+	var signatures map[string]*DeckSignature
+	exists, err := dt.kv.Get(ctx, deduplicationKey, &signatures)
 	if err != nil {
-		return fmt.Errorf("failed to check deduplication data: %w", err)
+		return fmt.Errorf("failed to load deduplication data: %w", err)
 	}
 	if !exists {
 		dt.log.Debugf(ctx, "No existing deduplication data found, starting fresh")
 		return nil
 	}
 
-	data, err := dt.blob.Read(ctx, key)
-	if err != nil {
-		return fmt.Errorf("failed to read deduplication data: %w", err)
-	}
-
-	var signatures map[string]*DeckSignature
-	if err := json.Unmarshal(data, &signatures); err != nil {
-		return fmt.Errorf("failed to parse deduplication data: %w", err)
-	}
-
 	dt.mu.Lock()
 	dt.signatures = signatures
 	dt.mu.Unlock()
@@ -111,14 +132,8 @@ func (dt *DeduplicationTracker) Save(ctx context.Context) error {
 	}
 	dt.mu.RUnlock()
 
-	data, err := json.Marshal(signatures)
-	if err != nil {
-		return fmt.Errorf("failed to marshal deduplication data: %w", err)
-	}
-
-	key := dt.trackingKey()
-	if err := dt.blob.Write(ctx, key, data); err != nil {
-		return fmt.Errorf("failed to write deduplication data: %w", err)
+	if err := dt.kv.Set(ctx, deduplicationKey, signatures); err != nil {
+		return fmt.Errorf("failed to save deduplication data: %w", err)
 	}
 
 	return nil
@@ -137,7 +152,7 @@ func (dt *DeduplicationTracker) FindDuplicate(c *Collection) (isDuplicate bool,
 		// Check if this is a different source
 		isNewSource := true
 		for _, src := range existing.Sources {
-			if src == c.Source {
+			if src == string(c.Source) {
 				isNewSource = false
 				break
 			}
@@ -152,13 +167,13 @@ func (dt *DeduplicationTracker) FindDuplicate(c *Collection) (isDuplicate bool,
 				// Check again if source was added by another goroutine
 				isNewSource = true
 				for _, src := range existing.Sources {
-					if src == c.Source {
+					if src == string(c.Source) {
 						isNewSource = false
 						break
 					}
 				}
 				if isNewSource {
-					existing.Sources = append(existing.Sources, c.Source)
+					existing.Sources = append(existing.Sources, string(c.Source))
 				}
 			}
 			dt.mu.Unlock()
@@ -176,7 +191,7 @@ func (dt *DeduplicationTracker) FindDuplicate(c *Collection) (isDuplicate bool,
 	}
 	dt.signatures[sig] = &DeckSignature{
 		CardSignature: sig,
-		Sources:       []string{c.Source},
+		Sources:       []string{string(c.Source)},
 		CanonicalID:   c.ID,
 		CanonicalURL:  c.URL,
 	}
@@ -220,7 +235,3 @@ func (dt *DeduplicationTracker) GetCanonicalSource(sig string) string {
 	return bestSource
 }
 
-func (dt *DeduplicationTracker) trackingKey() string {
-	return filepath.Join(dt.prefix, ".deduplication.json")
-}
-
@@ -14,7 +14,7 @@ func ConvertToUniversalCollection(adapter CollectionAdapter) *Collection {
 	return &Collection{
 		ID:          adapter.GetID(),
 		URL:         adapter.GetURL(),
-		Source:      adapter.GetSource(),
+		Source:      Source(adapter.GetSource()),
 		Partitions:  adapter.GetPartitions(),
 	}
 }
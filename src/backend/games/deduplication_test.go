@@ -57,6 +57,56 @@ func TestComputeDeckSignature(t *testing.T) {
 	}
 }
 
+// eventDeckType is a deck type carrying a player and event
+type eventDeckType struct {
+	player, event string
+}
+
+func (t *eventDeckType) Type() string      { return "TestDeck" }
+func (t *eventDeckType) IsCollectionType() {}
+func (t *eventDeckType) DeckMetadata() DeckMetadata {
+	return DeckMetadata{Player: t.player, Event: t.event}
+}
+
+func TestDeckFingerprint(t *testing.T) {
+	deck := func(player string, partitions ...Partition) *Collection {
+		return &Collection{
+			Type:       CollectionTypeWrapper{Type: "TestDeck", Inner: &eventDeckType{player: player, event: "Worlds 2024"}},
+			Partitions: partitions,
+		}
+	}
+	main := Partition{Name: "Main", Cards: []CardDesc{{Name: "Juzám Djinn", Count: 4}, {Name: "Mountain", Count: 20}}}
+	side := Partition{Name: "Sideboard", Cards: []CardDesc{{Name: "Pyroblast", Count: 3}}}
+	want := DeckFingerprint(deck("Jon Finkel", main, side))
+
+	same := map[string]*Collection{
+		"reordered partitions": deck("Jon Finkel", side, main),
+		"reordered cards": deck("Jon Finkel", Partition{Name: "Main", Cards: []CardDesc{
+			{Name: "Mountain", Count: 20}, {Name: "Juzám Djinn", Count: 4},
+		}}),
+		"aliases and case": deck("  jon  FINKEL", Partition{Name: "Main", Cards: []CardDesc{
+			{Name: "juzam djinn", Count: 2}, {Name: "Juzam Djinn", Count: 2}, {Name: "MOUNTAIN", Count: 20},
+		}}),
+	}
+	for name, c := range same {
+		if got := DeckFingerprint(c); got != want {
+			t.Errorf("%s: DeckFingerprint() = %s, want %s", name, got, want)
+		}
+	}
+
+	different := map[string]*Collection{
+		"other player": deck("Kai Budde", main, side),
+		"other count": deck("Jon Finkel", Partition{Name: "Main", Cards: []CardDesc{
+			{Name: "Juzám Djinn", Count: 3}, {Name: "Mountain", Count: 20},
+		}}),
+	}
+	for name, c := range different {
+		if DeckFingerprint(c) == want {
+			t.Errorf("%s: DeckFingerprint() matches the original deck", name)
+		}
+	}
+}
+
 func TestDeduplicationTracker(t *testing.T) {
 	ctx := context.Background()
 	log := logger.NewLogger(ctx)
@@ -197,4 +247,3 @@ func TestGetCanonicalSource(t *testing.T) {
 		t.Errorf("Canonical source = %s, want scryfall", canonSource)
 	}
 }
-
@@ -0,0 +1,197 @@
+package digimonmeta
+
+import (
+	"collections/blob"
+	"collections/games"
+	"collections/games/decksite"
+	"collections/games/digimon/game"
+	"collections/logger"
+	"collections/scraper"
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Dataset scrapes Digimon tournament decks from DigimonMeta
+// No API key required - scrapes https://digimonmeta.com/deck-list/
+type Dataset struct {
+	log  *logger.Logger
+	blob *blob.Bucket
+}
+
+var base *url.URL
+
+func init() {
+	u, err := url.Parse("https://digimonmeta.com/")
+	if err != nil {
+		panic(err)
+	}
+	base = u
+}
+
+func NewDataset(log *logger.Logger, blob *blob.Bucket) *Dataset {
+	return &Dataset{
+		log:  log,
+		blob: blob,
+	}
+}
+
+func (d *Dataset) Description() games.Description {
+	return games.Description{
+		Game: "digimon",
+		Name: "digimonmeta",
+	}
+}
+
+// reDeckPath matches a deck page's path; the slug is the deck ID
+var reDeckPath = regexp.MustCompile(`^/deck-list/([a-z0-9-]+)/?$`)
+
+// site lists decks as WordPress posts under /deck-list/, paginated with
+// /deck-list/page/N/
+func site() *decksite.Site {
+	return &decksite.Site{
+		Name:      "digimonmeta",
+		Listing:   base.JoinPath("deck-list/"),
+		DeckLinks: "article .entry-title a",
+		DeckPath:  reDeckPath,
+		NextPage:  ".pagination a.next",
+		Key:       collectionKey,
+		Parse: func(doc *goquery.Document, deckID, deckURL string, now time.Time) (any, error) {
+			return buildCollection(parseDeckPage(doc), deckID, deckURL, now)
+		},
+	}
+}
+
+func (d *Dataset) Extract(
+	ctx context.Context,
+	sc *scraper.Scraper,
+	options ...games.UpdateOption,
+) error {
+	opts, err := games.ResolveUpdateOptions(options...)
+	if err != nil {
+		return err
+	}
+
+	d.log.Infof(ctx, "Extracting Digimon tournament decks from DigimonMeta...")
+
+	n, err := site().Extract(ctx, d.log, d.blob, sc, &opts)
+	if err != nil {
+		return err
+	}
+
+	d.log.Infof(ctx, "✅ Extracted %d Digimon tournament decks from DigimonMeta", n)
+	return nil
+}
+
+// deckPage is what a DigimonMeta deck page lists
+type deckPage struct {
+	Name string
+	decksite.Meta
+	Partitions []game.Partition
+}
+
+// partitionDigiEgg holds the separate Digi-Egg deck; the main deck is
+// game.PartitionDeck
+const partitionDigiEgg = "Digi-Egg"
+
+// parseDeckPage reads a deck page's details table and card sections.
+// Cards under a heading mentioning Digi-Egg go to the Digi-Egg partition,
+// the rest to the main deck.
+func parseDeckPage(doc *goquery.Document) deckPage {
+	page := deckPage{
+		Name: strings.TrimSpace(doc.Find("h1.entry-title").First().Text()),
+	}
+
+	doc.Find(".entry-content .deck-details tr").Each(func(i int, s *goquery.Selection) {
+		page.Set(s.Find("th").First().Text(), s.Find("td").First().Text())
+	})
+
+	cards := map[string][]game.CardDesc{}
+	doc.Find(".deck-list .deck-section").Each(func(i int, section *goquery.Selection) {
+		partition := game.PartitionDeck
+		if strings.Contains(strings.ToLower(section.Find("h2, h3, h4").First().Text()), "egg") {
+			partition = partitionDigiEgg
+		}
+		section.Find(".card").Each(func(i int, s *goquery.Selection) {
+			count, err := strconv.Atoi(strings.TrimSpace(s.Find(".card-count").Text()))
+			if err != nil {
+				return
+			}
+			// Normalize card name for consistency
+			name := games.NormalizeCardName(s.Find(".card-name").Text())
+			if name == "" {
+				return // Skip empty card names
+			}
+			cards[partition] = append(cards[partition], game.CardDesc{Name: name, Count: count})
+		})
+	})
+	for _, name := range []string{game.PartitionDeck, partitionDigiEgg} {
+		if len(cards[name]) > 0 {
+			page.Partitions = append(page.Partitions, game.Partition{Name: name, Cards: cards[name]})
+		}
+	}
+	return page
+}
+
+// buildCollection turns a parsed deck page into a canonical collection,
+// dated now when the page has no event date
+func buildCollection(page deckPage, deckID, deckURL string, now time.Time) (*game.Collection, error) {
+	if len(page.Partitions) == 0 {
+		return nil, fmt.Errorf("no cards found in deck")
+	}
+
+	format := page.Format
+	if format == "" {
+		format = "Standard"
+	}
+
+	deckType := &game.CollectionTypeDeck{
+		Name:      games.CleanDeckName(page.Name),
+		Format:    format,
+		Archetype: page.Name,
+		Player:    page.Player,
+		Event:     page.Event,
+		Placement: games.KnownPlacement(page.Placement),
+		EventDate: page.EventDate,
+	}
+
+	tw := game.CollectionTypeWrapper{
+		Type:  deckType.Type(),
+		Inner: deckType,
+	}
+
+	collection := &game.Collection{
+		Type:        tw,
+		ID:          deckID,
+		URL:         deckURL,
+		ReleaseDate: games.ParseDateWithFallback(page.EventDate, now),
+		Partitions:  page.Partitions,
+		Source:      "digimonmeta",
+	}
+
+	if err := collection.Canonicalize(); err != nil {
+		return nil, fmt.Errorf("collection is invalid: %w", err)
+	}
+	return collection, nil
+}
+
+var prefix = filepath.Join("digimon", "digimonmeta")
+
+func collectionKey(collectionID string) string {
+	return filepath.Join(prefix, collectionID+".json")
+}
+
+func (d *Dataset) IterItems(
+	ctx context.Context,
+	fn func(item games.Item) error,
+	options ...games.IterItemsOption,
+) error {
+	return games.IterItemsBlobPrefix(ctx, d.blob, prefix, games.DeserializeAsCollection, fn, options...)
+}
@@ -0,0 +1,98 @@
+package digimonmeta
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"collections/games/digimon/game"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func loadFixture(t *testing.T, name string) *goquery.Document {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestParseListingPage(t *testing.T) {
+	pageURL := base.JoinPath("deck-list/page/2/")
+	urls, next := site().ParseListingPage(loadFixture(t, "listing.html"), pageURL, map[string]bool{})
+
+	// The sidebar's recent decks are not part of this page's listing
+	want := []string{
+		"https://digimonmeta.com/deck-list/imperialdramon-bt17-regional-1st/",
+		"https://digimonmeta.com/deck-list/blue-flare-bt17-regional-top4/",
+	}
+	if len(urls) != len(want) {
+		t.Fatalf("ParseListingPage() = %v, want %v", urls, want)
+	}
+	for i := range want {
+		if urls[i] != want[i] {
+			t.Errorf("ParseListingPage()[%d] = %q, want %q", i, urls[i], want[i])
+		}
+	}
+	if want := "https://digimonmeta.com/deck-list/page/3/"; next != want {
+		t.Errorf("ParseListingPage() next = %q, want %q", next, want)
+	}
+}
+
+func TestParseDeckPage(t *testing.T) {
+	page := parseDeckPage(loadFixture(t, "deck.html"))
+
+	if page.Name != "Imperialdramon" || page.Player != "Alice" || page.Event != "Regional Championship" ||
+		page.Placement != 1 || page.EventDate != "2024-05-12" || page.Format != "BT17" {
+		t.Errorf("parseDeckPage() metadata = %+v", page)
+	}
+	if len(page.Partitions) != 2 {
+		t.Fatalf("parseDeckPage() partitions = %+v, want Deck and Digi-Egg", page.Partitions)
+	}
+
+	deck, eggs := page.Partitions[0], page.Partitions[1]
+	if deck.Name != game.PartitionDeck || deck.Cards[0] != (game.CardDesc{Name: "Veemon", Count: 4}) {
+		t.Errorf("main deck = %+v", deck)
+	}
+	if eggs.Name != partitionDigiEgg || len(eggs.Cards) != 2 || eggs.Cards[0] != (game.CardDesc{Name: "Chicomon", Count: 4}) {
+		t.Errorf("Digi-Egg deck = %+v", eggs)
+	}
+	total := 0
+	for _, c := range deck.Cards {
+		total += c.Count
+	}
+	if len(deck.Cards) != 14 || total != 50 {
+		t.Errorf("main deck has %d cards totalling %d, want 14 totalling 50", len(deck.Cards), total)
+	}
+}
+
+func TestBuildCollection(t *testing.T) {
+	page := parseDeckPage(loadFixture(t, "deck.html"))
+	url := "https://digimonmeta.com/deck-list/imperialdramon-bt17-regional-1st/"
+	col, err := buildCollection(page, "imperialdramon-bt17-regional-1st", url, time.Now())
+	if err != nil {
+		t.Fatalf("buildCollection() error = %v", err)
+	}
+
+	deck, ok := col.Type.Inner.(*game.CollectionTypeDeck)
+	if !ok {
+		t.Fatalf("collection type = %T, want *CollectionTypeDeck", col.Type.Inner)
+	}
+	if deck.Player != "Alice" || deck.Format != "BT17" || deck.Placement == nil || *deck.Placement != 1 {
+		t.Errorf("deck type = %+v", deck)
+	}
+	if want := time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC); !col.ReleaseDate.Equal(want) {
+		t.Errorf("ReleaseDate = %v, want %v", col.ReleaseDate, want)
+	}
+
+	if _, err := buildCollection(deckPage{}, "empty", url, time.Now()); err == nil {
+		t.Error("buildCollection() of a page without cards succeeded, want error")
+	}
+}
@@ -81,7 +81,7 @@ type CollectionTypeDeck struct {
 	Player    string `json:"player,omitempty"`
 	// Tournament metadata (from Limitless TCG API)
 	Event     string `json:"event,omitempty"`
-	Placement int    `json:"placement,omitempty"`
+	Placement *int   `json:"placement,omitempty"` // nil when unknown
 	EventDate string `json:"eventDate,omitempty"`
 }
 
@@ -100,6 +100,18 @@ func (ct *CollectionTypeSet) Type() string    { return "DigimonSet" }
 func (ct *CollectionTypeDeck) IsCollectionType()   {}
 func (ct *CollectionTypeSet) IsCollectionType()    {}
 
+func (ct *CollectionTypeDeck) DeckMetadata() games.DeckMetadata {
+	return games.DeckMetadata{
+		Name:      ct.Name,
+		Archetype: ct.Archetype,
+		Format:    ct.Format,
+		Player:    ct.Player,
+		Event:     ct.Event,
+		EventDate: ct.EventDate,
+		Placement: ct.Placement,
+	}
+}
+
 // Standard partition names for Digimon
 const (
 	PartitionDeck = "Deck"
@@ -0,0 +1,60 @@
+package games
+
+import (
+	"net/url"
+	"strings"
+)
+
+// EventKey identifies the tournament a deck was played in, for grouping
+// decks by event. Decks whose URL names no event get a key unique to the
+// deck, so they never cluster with unrelated decks.
+type EventKey struct {
+	Source string // e.g. "mtgtop8", or the URL host for unknown sites
+	ID     string // the site's event ID, or the normalized deck URL
+}
+
+// EventKeyFromURL extracts the event from a deck or event URL: the e=
+// parameter on mtgtop8, /tournament/<id> on goldfish and
+// /tournament(s)/<id> on limitless. Other URLs fall back to their host
+// and normalized URL (lowercased host, no fragment, sorted query), which
+// is stable across runs.
+func EventKeyFromURL(rawURL string) EventKey {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return EventKey{Source: unknownSource, ID: rawURL}
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	switch {
+	case strings.HasSuffix(host, "mtgtop8.com"):
+		if e := u.Query().Get("e"); e != "" {
+			return EventKey{Source: "mtgtop8", ID: e}
+		}
+	case strings.HasSuffix(host, "mtggoldfish.com"):
+		if id := segmentAfter(segments, "tournament"); id != "" {
+			return EventKey{Source: "goldfish", ID: id}
+		}
+	case strings.HasSuffix(host, "limitlesstcg.com"):
+		if id := segmentAfter(segments, "tournament", "tournaments"); id != "" {
+			return EventKey{Source: "limitless", ID: id}
+		}
+	}
+
+	u.Host = host
+	u.Fragment = ""
+	u.RawQuery = u.Query().Encode() // Encode sorts by key
+	return EventKey{Source: host, ID: u.String()}
+}
+
+// segmentAfter returns the path segment following the first of names
+func segmentAfter(segments []string, names ...string) string {
+	for i := 0; i+1 < len(segments); i++ {
+		for _, name := range names {
+			if segments[i] == name && segments[i+1] != "" {
+				return segments[i+1]
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package games
+
+import "testing"
+
+func TestEventKeyFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want EventKey
+	}{
+		{"https://mtgtop8.com/event?e=74272&d=763488", EventKey{"mtgtop8", "74272"}},
+		{"https://www.mtgtop8.com/event?d=763489&e=74272&f=MO", EventKey{"mtgtop8", "74272"}},
+		{"https://www.mtggoldfish.com/tournament/54321#paper", EventKey{"goldfish", "54321"}},
+		{"https://limitlesstcg.com/tournaments/412/decks", EventKey{"limitless", "412"}},
+		{"https://play.limitlesstcg.com/tournament/abc123/standings", EventKey{"limitless", "abc123"}},
+		// No event: each deck is its own key
+		{"https://www.mtgtop8.com/event?f=MO&d=763488", EventKey{"mtgtop8.com", "https://mtgtop8.com/event?d=763488&f=MO"}},
+	}
+	for _, tt := range tests {
+		if got := EventKeyFromURL(tt.url); got != tt.want {
+			t.Errorf("EventKeyFromURL(%q) = %+v, want %+v", tt.url, got, tt.want)
+		}
+	}
+
+	// The fallback is stable and distinguishes decks
+	a := EventKeyFromURL("https://www.mtggoldfish.com/deck/1")
+	if a != EventKeyFromURL("https://www.mtggoldfish.com/deck/1") {
+		t.Error("fallback key is not stable")
+	}
+	if a == EventKeyFromURL("https://www.mtggoldfish.com/deck/2") {
+		t.Error("decks without an event share a key")
+	}
+}
@@ -0,0 +1,151 @@
+package games
+
+import "time"
+
+// ExportMetadataKeys are the optional deck metadata fields in flattened
+// export records. They are unknown for many sources and must be left out
+// rather than written as "" or 0, which consumers read as real values.
+// archetype and format are always present and not listed here.
+var ExportMetadataKeys = []string{
+	"source",
+	"player",
+	"event",
+	"placement",
+	"event_date",
+	"updated_at",
+	"version",
+}
+
+// OmitUnknown deletes the given keys from record when their value is nil,
+// an empty string or a zero number, so "unknown" is distinguishable from
+// "zero" in the exported JSON. Optional numbers held as *int are only
+// deleted when nil; a pointer to 0 is a known value.
+func OmitUnknown(record map[string]any, keys ...string) {
+	for _, key := range keys {
+		v, ok := record[key]
+		if !ok {
+			continue
+		}
+		switch v := v.(type) {
+		case nil:
+			delete(record, key)
+		case *int:
+			if v == nil {
+				delete(record, key)
+			}
+		case string:
+			if v == "" {
+				delete(record, key)
+			}
+		case int:
+			if v == 0 {
+				delete(record, key)
+			}
+		case int64:
+			if v == 0 {
+				delete(record, key)
+			}
+		case float64:
+			if v == 0 {
+				delete(record, key)
+			}
+		}
+	}
+}
+
+// SourceCap caps how many records each source contributes to an export, so
+// one prolific source doesn't dominate a training set. Records with no
+// source share one cap. The zero value, or a Limit of 0, caps nothing.
+type SourceCap struct {
+	Limit  int
+	Counts map[string]int // Records allowed so far, by source
+}
+
+// Allow reports whether another record from source fits under the cap,
+// counting it when it does
+func (c *SourceCap) Allow(source string) bool {
+	if c.Limit <= 0 {
+		return true
+	}
+	if c.Counts == nil {
+		c.Counts = make(map[string]int)
+	}
+	if c.Counts[source] >= c.Limit {
+		return false
+	}
+	c.Counts[source]++
+	return true
+}
+
+// ExportRecord converts a collection to the flattened export record that
+// export-blob writes (similar to export-hetero). Returns nil for collections
+// with no cards.
+func ExportRecord(collection *Collection) map[string]any {
+	deckMap := map[string]any{
+		"deck_id":    collection.ID,
+		"url":        collection.URL,
+		"source":     string(collection.Source),
+		"scraped_at": collection.ReleaseDate.Format("2006-01-02T15:04:05Z07:00"),
+		"timestamp":  collection.ReleaseDate.Format("2006-01-02T15:04:05Z07:00"),
+		"created_at": collection.ReleaseDate.Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	// Same metadata fields as export-hetero; sets and other non-deck types
+	// get empty archetype and format
+	meta, _ := collection.Type.DeckMetadata()
+	deckMap["archetype"] = meta.Archetype
+	deckMap["format"] = meta.Format
+	deckMap["player"] = meta.Player
+	deckMap["event"] = meta.Event
+	if meta.Placement != nil {
+		deckMap["placement"] = *meta.Placement
+	}
+	deckMap["event_date"] = meta.EventDate
+	OmitUnknown(deckMap, ExportMetadataKeys...)
+
+	// Extract cards from partitions
+	var cards []map[string]any
+	for _, partition := range collection.Partitions {
+		for _, card := range partition.Cards {
+			cards = append(cards, map[string]any{
+				"name":      card.Name,
+				"count":     card.Count,
+				"partition": partition.Name,
+			})
+		}
+	}
+
+	if len(cards) == 0 {
+		return nil // Skip decks with no cards
+	}
+
+	deckMap["cards"] = cards
+	return deckMap
+}
+
+// Provenance traces an exported record back to the collection it came
+// from. ScraperVersion is the VCS revision of the exporting binary, which
+// is built from the same tree as the scrapers; it is empty for builds
+// without VCS info, such as go run and tests.
+type Provenance struct {
+	Source         string `json:"source,omitempty"`
+	Dataset        string `json:"dataset,omitempty"`
+	Key            string `json:"key,omitempty"` // Blob key of the collection under games/
+	ScrapedAt      string `json:"scraped_at,omitempty"`
+	ScraperVersion string `json:"scraper_version,omitempty"`
+}
+
+// NewProvenance returns the provenance of collection c, read from the
+// dataset's blob key. ScrapedAt is when c was first scraped, if known.
+func NewProvenance(c *Collection, dataset, key string) Provenance {
+	p := Provenance{
+		Source:         string(c.Source),
+		Dataset:        dataset,
+		Key:            key,
+		ScraperVersion: ScraperVersion(),
+	}
+	if !c.ScrapedAt.IsZero() {
+		p.ScrapedAt = c.ScrapedAt.UTC().Format(time.RFC3339)
+	}
+	return p
+}
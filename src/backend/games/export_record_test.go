@@ -0,0 +1,63 @@
+package games
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestOmitUnknown(t *testing.T) {
+	record := map[string]any{
+		"deck_id":    "d1",
+		"event":      "",
+		"placement":  0,
+		"version":    int64(0),
+		"player":     "Alice",
+		"event_date": nil,
+		"cards":      []string{},
+	}
+	OmitUnknown(record, ExportMetadataKeys...)
+
+	b, err := json.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"event", "placement", "version", "event_date"} {
+		if strings.Contains(string(b), `"`+key+`"`) {
+			t.Errorf("record %s contains unknown %q", b, key)
+		}
+	}
+	for _, key := range []string{"deck_id", "player", "cards"} {
+		if _, ok := record[key]; !ok {
+			t.Errorf("record is missing %q", key)
+		}
+	}
+
+	// A known placement survives
+	record = map[string]any{"placement": 1}
+	OmitUnknown(record, ExportMetadataKeys...)
+	if record["placement"] != 1 {
+		t.Errorf("placement = %v, want 1", record["placement"])
+	}
+}
+
+func TestSourceCap(t *testing.T) {
+	c := &SourceCap{Limit: 2}
+	var got []bool
+	for _, source := range []string{"a", "a", "b", "a", "", "", ""} {
+		got = append(got, c.Allow(source))
+	}
+	want := []bool{true, true, true, false, true, true, false}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Allow() results = %v, want %v", got, want)
+		}
+	}
+
+	var none SourceCap
+	for i := 0; i < 5; i++ {
+		if !none.Allow("a") {
+			t.Fatal("zero SourceCap refused a record")
+		}
+	}
+}
@@ -2,9 +2,7 @@ package games
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"path/filepath"
 	"sync"
 	"time"
 
@@ -12,49 +10,58 @@ import (
 	"collections/logger"
 )
 
-// ExportTracker tracks what has been exported to enable incremental exports
+// exportTrackerKey is the tracker's BlobKV key under its prefix
+const exportTrackerKey = ".export_tracker"
+
+// ExportTracker tracks what has been exported to enable incremental exports.
+// Its methods are safe for concurrent use, so parallel exporters can share
+// one tracker.
 type ExportTracker struct {
-	blob     *blob.Bucket
+	kv       *BlobKV
 	log      *logger.Logger
-	prefix   string
 	exported map[string]time.Time // blob key -> last export time
-	mu       sync.RWMutex          // Protects exported map for concurrent access
+	hashes   map[string]string    // blob key -> content hash at last export
+	sources  map[string]string    // blob key -> collection source
+	mu       sync.RWMutex          // Protects the maps for concurrent access
+}
+
+// TrackerStat counts a source's tracked blobs and those exported in the
+// last 24 hours
+type TrackerStat struct {
+	Total  int
+	Recent int
 }
 
+// unknownSource groups blobs exported without a SetSource call
+const unknownSource = "unknown"
+
 // NewExportTracker creates a new export tracker
 func NewExportTracker(log *logger.Logger, blob *blob.Bucket, prefix string) *ExportTracker {
 	return &ExportTracker{
-		blob:     blob,
+		kv:       NewBlobKV(blob, prefix),
 		log:      log,
-		prefix:   prefix,
 		exported: make(map[string]time.Time),
+		hashes:   make(map[string]string),
+		sources:  make(map[string]string),
 	}
 }
 
 // Load loads the tracking data from blob storage
 func (et *ExportTracker) Load(ctx context.Context) error {
-	key := et.trackingKey()
-	exists, err := et.blob.Exists(ctx, key)
+	var trackingData struct {
+		Exported map[string]string `json:"exported"` // blob key -> ISO timestamp
+		Hashes   map[string]string `json:"hashes"`   // blob key -> content hash
+		Sources  map[string]string `json:"sources"`  // blob key -> source
+	}
+	exists, err := et.kv.Get(ctx, exportTrackerKey, &trackingData)
 	if err != nil {
-		return fmt.Errorf("failed to check tracking data: %w", err)
+		return fmt.Errorf("failed to load tracking data: %w", err)
 	}
 	if !exists {
 		et.log.Debugf(ctx, "No existing export tracking data found, starting fresh")
 		return nil
 	}
 
-	data, err := et.blob.Read(ctx, key)
-	if err != nil {
-		return fmt.Errorf("failed to read tracking data: %w", err)
-	}
-
-	var trackingData struct {
-		Exported map[string]string `json:"exported"` // blob key -> ISO timestamp
-	}
-	if err := json.Unmarshal(data, &trackingData); err != nil {
-		return fmt.Errorf("failed to parse tracking data: %w", err)
-	}
-
 	// Parse timestamps
 	exported := make(map[string]time.Time, len(trackingData.Exported))
 	for blobKey, tsStr := range trackingData.Exported {
@@ -63,8 +70,19 @@ func (et *ExportTracker) Load(ctx context.Context) error {
 		}
 	}
 
+	hashes := trackingData.Hashes
+	if hashes == nil {
+		hashes = make(map[string]string)
+	}
+	sources := trackingData.Sources
+	if sources == nil {
+		sources = make(map[string]string)
+	}
+
 	et.mu.Lock()
 	et.exported = exported
+	et.hashes = hashes
+	et.sources = sources
 	et.mu.Unlock()
 
 	et.log.Infof(ctx, "Loaded export tracking data: %d items already exported", len(exported))
@@ -78,12 +96,24 @@ func (et *ExportTracker) Save(ctx context.Context) error {
 	for k, v := range et.exported {
 		exported[k] = v
 	}
+	hashes := make(map[string]string, len(et.hashes))
+	for k, v := range et.hashes {
+		hashes[k] = v
+	}
+	sources := make(map[string]string, len(et.sources))
+	for k, v := range et.sources {
+		sources[k] = v
+	}
 	et.mu.RUnlock()
 
 	trackingData := struct {
 		Exported map[string]string `json:"exported"`
+		Hashes   map[string]string `json:"hashes,omitempty"`
+		Sources  map[string]string `json:"sources,omitempty"`
 	}{
 		Exported: make(map[string]string, len(exported)),
+		Hashes:   hashes,
+		Sources:  sources,
 	}
 
 	// Convert timestamps to ISO strings
@@ -91,14 +121,8 @@ func (et *ExportTracker) Save(ctx context.Context) error {
 		trackingData.Exported[blobKey] = ts.Format(time.RFC3339)
 	}
 
-	data, err := json.Marshal(trackingData)
-	if err != nil {
-		return fmt.Errorf("failed to marshal tracking data: %w", err)
-	}
-
-	key := et.trackingKey()
-	if err := et.blob.Write(ctx, key, data); err != nil {
-		return fmt.Errorf("failed to write tracking data: %w", err)
+	if err := et.kv.Set(ctx, exportTrackerKey, trackingData); err != nil {
+		return fmt.Errorf("failed to save tracking data: %w", err)
 	}
 
 	return nil
@@ -125,6 +149,19 @@ func (et *ExportTracker) ShouldExport(ctx context.Context, blobKey string, blobM
 	return blobModifiedTime.After(lastExported)
 }
 
+// ShouldExportHash is the content-hash alternative to ShouldExport: a blob
+// is exported only if it was never exported with a recorded hash or its
+// Collection.ContentHash changed since. Rewriting a file without changing
+// its cards (recompression, metadata backfills) does not trigger an export.
+// Thread-safe: uses read lock for concurrent access
+func (et *ExportTracker) ShouldExportHash(blobKey, contentHash string) bool {
+	et.mu.RLock()
+	lastHash, exists := et.hashes[blobKey]
+	et.mu.RUnlock()
+
+	return !exists || lastHash != contentHash
+}
+
 // MarkExported marks a blob as exported
 // Thread-safe: uses write lock for concurrent access
 func (et *ExportTracker) MarkExported(blobKey string) {
@@ -133,6 +170,52 @@ func (et *ExportTracker) MarkExported(blobKey string) {
 	et.mu.Unlock()
 }
 
+// MarkExportedHash marks a blob as exported and records its content hash
+// for ShouldExportHash
+// Thread-safe: uses write lock for concurrent access
+func (et *ExportTracker) MarkExportedHash(blobKey, contentHash string) {
+	et.mu.Lock()
+	et.exported[blobKey] = time.Now()
+	et.hashes[blobKey] = contentHash
+	et.mu.Unlock()
+}
+
+// SetSource records the source a blob's collection came from, for
+// GetStatsBySource
+// Thread-safe: uses write lock for concurrent access
+func (et *ExportTracker) SetSource(blobKey, source string) {
+	if source == "" {
+		return
+	}
+	et.mu.Lock()
+	et.sources[blobKey] = source
+	et.mu.Unlock()
+}
+
+// GetStatsBySource is GetStats broken down by the source recorded with
+// SetSource; blobs without one count under "unknown"
+// Thread-safe: uses read lock for concurrent access
+func (et *ExportTracker) GetStatsBySource() map[string]TrackerStat {
+	cutoff := time.Now().Add(-24 * time.Hour)
+	stats := make(map[string]TrackerStat)
+
+	et.mu.RLock()
+	defer et.mu.RUnlock()
+	for blobKey, ts := range et.exported {
+		source := et.sources[blobKey]
+		if source == "" {
+			source = unknownSource
+		}
+		s := stats[source]
+		s.Total++
+		if ts.After(cutoff) {
+			s.Recent++
+		}
+		stats[source] = s
+	}
+	return stats
+}
+
 // GetStats returns statistics about exported items
 // Thread-safe: uses read lock for concurrent access
 func (et *ExportTracker) GetStats() (total, recent int) {
@@ -153,7 +236,3 @@ func (et *ExportTracker) GetStats() (total, recent int) {
 	return total, recent
 }
 
-func (et *ExportTracker) trackingKey() string {
-	return filepath.Join(et.prefix, ".export_tracker.json")
-}
-
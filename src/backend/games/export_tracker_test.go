@@ -2,11 +2,18 @@ package games
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"collections/blob"
+	"collections/cio"
 	"collections/logger"
+
+	"github.com/DataDog/zstd"
 )
 
 func TestExportTracker(t *testing.T) {
@@ -103,3 +110,166 @@ func TestExportTrackerStats(t *testing.T) {
 	}
 }
 
+
+func TestExportTrackerStatsBySource(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+
+	tracker := NewExportTracker(log, bucket, "test")
+	for key, source := range map[string]string{
+		"magic/mtgtop8/1.json":         "mtgtop8",
+		"magic/mtgtop8/2.json":         "mtgtop8",
+		"magic/goldfish/1.json":        "goldfish",
+		"pokemon/limitless-web/1.json": "limitless-web",
+	} {
+		tracker.MarkExported(key)
+		tracker.SetSource(key, source)
+	}
+	tracker.MarkExported("legacy/1.json")
+	if err := tracker.Save(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Sources survive a reload; an old export ages out of Recent
+	reloaded := NewExportTracker(log, bucket, "test")
+	if err := reloaded.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	reloaded.mu.Lock()
+	reloaded.exported["magic/mtgtop8/1.json"] = time.Now().Add(-48 * time.Hour)
+	reloaded.mu.Unlock()
+
+	got := reloaded.GetStatsBySource()
+	want := map[string]TrackerStat{
+		"mtgtop8":       {Total: 2, Recent: 1},
+		"goldfish":      {Total: 1, Recent: 1},
+		"limitless-web": {Total: 1, Recent: 1},
+		"unknown":       {Total: 1, Recent: 1},
+	}
+	if len(got) != len(want) {
+		t.Errorf("GetStatsBySource() = %v, want %v", got, want)
+	}
+	for source, w := range want {
+		if got[source] != w {
+			t.Errorf("GetStatsBySource()[%s] = %+v, want %+v", source, got[source], w)
+		}
+	}
+}
+
+func TestExportTrackerHashIgnoresRecompression(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+
+	path := filepath.Join(t.TempDir(), "deck.json.zst")
+	deck := []byte(`{"id":"1","partitions":[{"name":"Main","cards":[{"name":"Lightning Bolt","count":4}]}]}`)
+	write := func(level int, modTime time.Time) {
+		t.Helper()
+		data, err := zstd.CompressLevel(nil, deck, level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash := func() string {
+		t.Helper()
+		data, err := cio.ReadCollectionFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, err := ContentHashOf(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	write(1, time.Now().Add(-time.Hour))
+	tracker := NewExportTracker(log, bucket, "test")
+	if !tracker.ShouldExportHash("deck.json.zst", hash()) {
+		t.Fatal("ShouldExportHash() = false for a never-exported blob")
+	}
+	tracker.MarkExportedHash("deck.json.zst", hash())
+	if err := tracker.Save(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Recompress at another level: new bytes and mod time, same content
+	later := time.Now().Add(time.Hour)
+	write(19, later)
+	reloaded := NewExportTracker(log, bucket, "test")
+	if err := reloaded.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.ShouldExportHash("deck.json.zst", hash()) {
+		t.Error("ShouldExportHash() = true after recompression with unchanged content")
+	}
+	if !reloaded.ShouldExport(ctx, "deck.json.zst", later, time.Time{}, 0) {
+		t.Error("ShouldExport() = false, want mod-time mode to re-export the touched file")
+	}
+
+	// A real content change is exported
+	deck = []byte(`{"id":"1","partitions":[{"name":"Main","cards":[{"name":"Lightning Bolt","count":3}]}]}`)
+	write(1, later)
+	if !reloaded.ShouldExportHash("deck.json.zst", hash()) {
+		t.Error("ShouldExportHash() = false after the deck's cards changed")
+	}
+}
+
+func TestExportTrackerConcurrentMarks(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+
+	tracker := NewExportTracker(log, bucket, "test")
+	const goroutines, perGoroutine = 32, 200
+
+	// Run with -race: marks, checks, stats and saves all interleave
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				key := fmt.Sprintf("src%d/%d.json", g%4, i*goroutines+g)
+				tracker.ShouldExport(ctx, key, time.Now(), time.Time{}, 0)
+				tracker.ShouldExportHash(key, "h")
+				if i%2 == 0 {
+					tracker.MarkExported(key)
+				} else {
+					tracker.MarkExportedHash(key, "h")
+				}
+				tracker.SetSource(key, fmt.Sprintf("src%d", g%4))
+				if i%50 == 0 {
+					tracker.GetStats()
+					tracker.GetStatsBySource()
+					if err := tracker.Save(ctx); err != nil {
+						t.Error(err)
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if total, _ := tracker.GetStats(); total != goroutines*perGoroutine {
+		t.Errorf("GetStats() total = %d, want %d", total, goroutines*perGoroutine)
+	}
+	for source, s := range tracker.GetStatsBySource() {
+		if s.Total != goroutines*perGoroutine/4 {
+			t.Errorf("GetStatsBySource()[%s] = %+v, want %d", source, s, goroutines*perGoroutine/4)
+		}
+	}
+}
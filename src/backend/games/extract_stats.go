@@ -31,6 +31,7 @@ type ExtractStats struct {
 	Total      int
 	Successful int
 	Failed     int
+	Skipped    int // Items already present and not re-parsed
 	Errors     []ExtractError
 
 	// Quality metrics
@@ -70,6 +71,13 @@ func (s *ExtractStats) RecordSuccess() {
 	s.Successful++
 }
 
+// RecordSkipped records an item that was skipped because it already exists
+func (s *ExtractStats) RecordSkipped() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Skipped++
+}
+
 // RecordError records an extraction error
 func (s *ExtractStats) RecordError(ctx context.Context, url, dataset string, err error) {
 	s.mu.Lock()
@@ -164,6 +172,7 @@ func (s *ExtractStats) ExportJSON() ([]byte, error) {
 		Total              int            `json:"total"`
 		Successful         int            `json:"successful"`
 		Failed             int            `json:"failed"`
+		Skipped            int            `json:"skipped"`
 		SuccessRate        float64        `json:"success_rate"`
 		NormalizedCount    int            `json:"normalized_count"`
 		ValidationFailures map[string]int `json:"validation_failures"`
@@ -179,17 +188,23 @@ func (s *ExtractStats) ExportJSON() ([]byte, error) {
 	if s.Total > 0 {
 		successRate = float64(s.Successful) / float64(s.Total) * 100
 	}
+	// GetCacheHitRate takes the lock itself, so compute the rate inline
+	cacheHitRate := 0.0
+	if total := s.CacheHits + s.CacheMisses; total > 0 {
+		cacheHitRate = float64(s.CacheHits) / float64(total)
+	}
 
 	export := Export{
 		Total:              s.Total,
 		Successful:         s.Successful,
 		Failed:             s.Failed,
+		Skipped:            s.Skipped,
 		SuccessRate:        successRate,
 		NormalizedCount:    s.NormalizedCount,
 		ValidationFailures: make(map[string]int),
 		CacheHits:          s.CacheHits,
 		CacheMisses:        s.CacheMisses,
-		CacheHitRate:       s.GetCacheHitRate() * 100,
+		CacheHitRate:       cacheHitRate * 100,
 		Duration:           duration.Round(time.Second).String(),
 		Errors:             make([]ExtractError, len(s.Errors)),
 	}
@@ -46,9 +46,9 @@ type Collection struct {
 	ReleaseDate time.Time             `json:"release_date"`
 	Partitions  []Partition           `json:"partitions"`
 
-	// Source tracking: which scraper/dataset extracted this
-	// Examples: "mtgtop8", "goldfish", "deckbox", "scryfall", "limitless", "ygoprodeck"
-	Source string `json:"source,omitempty"`
+	// Source tracking: which scraper/dataset extracted this. Canonicalize
+	// rejects values other than the Source constants.
+	Source Source `json:"source,omitempty"`
 
 	// Change tracking and versioning
 	ScrapedAt   time.Time `json:"scraped_at,omitempty"`   // When this was first scraped
@@ -56,6 +56,18 @@ type Collection struct {
 	Version     int       `json:"version,omitempty"`      // Increments on content change
 	ContentHash string    `json:"content_hash,omitempty"` // SHA256 hash of canonicalized content
 	ETag        string    `json:"etag,omitempty"`         // HTTP ETag from source
+
+	// ParserVersion is the ParserVersion of the build that parsed this;
+	// set by Canonicalize when empty
+	ParserVersion string `json:"parser_version,omitempty"`
+
+	// Completeness grades how fully a deck was parsed; set by Canonicalize
+	// for deck types, empty otherwise
+	Completeness Completeness `json:"completeness,omitempty"`
+
+	// Locale is the language of the collection's card names, as a
+	// NormalizeLocale code; empty when the source doesn't say
+	Locale string `json:"locale,omitempty"`
 }
 
 // CollectionTypeWrapper wraps game-specific collection types.
@@ -117,10 +129,54 @@ func (w *CollectionTypeWrapper) UnmarshalJSON(b []byte) error {
 
 var reBadCardName = regexp.MustCompile(`(^\s*$)|(\p{Cc})`)
 
+// PartitionOrder is the canonical order of partition names across games:
+// the main deck first, then the other zones. Canonicalize sorts partitions
+// in this order, with names not listed after them in name order.
+var PartitionOrder = []string{
+	"Main",
+	"Main Deck",
+	"Deck",
+	"Cards",
+	"Leader",
+	"Sideboard",
+	"Commander",
+	"Extra Deck",
+	"Side Deck",
+	"Prizes",
+	"Scratchpad",
+}
+
+var partitionRank = func() map[string]int {
+	rank := make(map[string]int, len(PartitionOrder))
+	for i, name := range PartitionOrder {
+		rank[name] = i
+	}
+	return rank
+}()
+
+// PartitionLess orders partition names by PartitionOrder, then by name.
+// Game packages with their own Collection type sort with it too.
+func PartitionLess(a, b string) bool {
+	ra, aok := partitionRank[a]
+	rb, bok := partitionRank[b]
+	switch {
+	case aok && bok:
+		return ra < rb
+	case aok != bok:
+		return aok
+	default:
+		return a < b
+	}
+}
+
 // Canonicalize validates and normalizes a collection.
 // Universal validation logic across all games.
 //
-// MUTATES: Sorts partitions and cards by name in place.
+// MUTATES: Sorts partitions by PartitionOrder and cards by name then count
+// in place, and rewrites card names to their DefaultCardAliases spelling.
+// Canonicalize is idempotent: a second call leaves the collection (and its
+// JSON) byte-identical, and duplicate card entries always land in the same
+// order regardless of input order.
 func (c *Collection) Canonicalize() error {
 	if c.ID == "" {
 		return errors.New("empty id")
@@ -131,6 +187,9 @@ func (c *Collection) Canonicalize() error {
 	if _, err := url.Parse(c.URL); err != nil {
 		return fmt.Errorf("failed to parse url: %w", err)
 	}
+	if c.Type.Inner == nil {
+		return errors.New("collection type is missing")
+	}
 	if c.Type.Type != c.Type.Inner.Type() {
 		return fmt.Errorf(
 			"mismatched types: %s != %s",
@@ -144,14 +203,24 @@ func (c *Collection) Canonicalize() error {
 	if len(c.Partitions) == 0 {
 		return errors.New("collection has no partitions")
 	}
+	source, err := validateSource(c.Source)
+	if err != nil {
+		return err
+	}
+	c.Source = source
 
-	// Sort partitions by name
+	// Sort partitions into the canonical order, so parsers that emit them
+	// in different orders serialize the same collection identically
 	sort.SliceStable(c.Partitions, func(i, j int) bool {
-		return c.Partitions[i].Name < c.Partitions[j].Name
+		return PartitionLess(c.Partitions[i].Name, c.Partitions[j].Name)
 	})
 
-	// Validate each partition
+	// Validate each partition, spelling cards the same whichever source
+	// they came from
 	for i, p := range c.Partitions {
+		for j := range p.Cards {
+			p.Cards[j].Name = ResolveCardAlias(p.Cards[j].Name)
+		}
 		if p.Name == "" {
 			return fmt.Errorf("partition %d has empty name", i)
 		}
@@ -171,17 +240,33 @@ func (c *Collection) Canonicalize() error {
 				return fmt.Errorf("bad card name %q in partition %q", card.Name, p.Name)
 			}
 		}
-		// Sort cards by name
+		// Sort cards by name, breaking ties between duplicate entries by
+		// count so the order does not depend on the input
 		sort.SliceStable(p.Cards, func(i, j int) bool {
-			return p.Cards[i].Name < p.Cards[j].Name
+			a, b := p.Cards[i], p.Cards[j]
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.Count < b.Count
 		})
 	}
+
+	c.Locale = NormalizeLocale(c.Locale)
+	c.Completeness = ""
+	if meta, ok := c.Type.DeckMetadata(); ok {
+		c.Completeness = DeckCompleteness(c.Type.Type, meta, c.Partitions)
+	}
+	if c.ParserVersion == "" {
+		c.ParserVersion = ParserVersion()
+	}
 	return nil
 }
 
 // ComputeContentHash calculates a SHA256 hash of the canonicalized card content.
 // This hash is used to detect content changes (not just metadata changes).
-// The hash is computed from the sorted partitions and cards, making it order-independent.
+// The hash is computed from the partitions sorted by name and their sorted
+// cards, so it doesn't depend on the partition order (and matches hashes
+// from before PartitionOrder, when Canonicalize sorted partitions by name).
 func (c *Collection) ComputeContentHash() {
 	if c.ContentHash != "" {
 		return // Already computed
@@ -209,7 +294,9 @@ func (c *Collection) ComputeContentHash() {
 		}, len(c.Partitions)),
 	}
 
-	for i, p := range c.Partitions {
+	partitions := append([]Partition(nil), c.Partitions...)
+	sort.SliceStable(partitions, func(i, j int) bool { return partitions[i].Name < partitions[j].Name })
+	for i, p := range partitions {
 		hc.Partitions[i].Name = p.Name
 		hc.Partitions[i].Cards = make([]struct {
 			Name  string
@@ -233,3 +320,30 @@ func (c *Collection) ComputeContentHash() {
 	hash := sha256.Sum256(data)
 	c.ContentHash = hex.EncodeToString(hash[:])
 }
+
+// ContentHashOf returns the content hash of a collection's JSON: its
+// stored content_hash, or one computed from its partitions. The collection
+// type is not decoded, so any game's collections work without registering
+// their types.
+func ContentHashOf(data []byte) (string, error) {
+	var raw struct {
+		Partitions  []Partition `json:"partitions"`
+		ContentHash string      `json:"content_hash"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return "", err
+	}
+	c := Collection{Partitions: raw.Partitions, ContentHash: raw.ContentHash}
+	c.ComputeContentHash()
+	return c.ContentHash, nil
+}
+
+// KnownPlacement returns a pointer to placement, or nil when it is not a
+// real finishing position. Scrapers parse placement into an int starting at
+// 0, so 0 means it was not found on the page.
+func KnownPlacement(placement int) *int {
+	if placement <= 0 {
+		return nil
+	}
+	return &placement
+}
@@ -1,6 +1,9 @@
 package games
 
 import (
+	"encoding/json"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 )
@@ -96,6 +99,104 @@ func TestCanonicalizeValidCollection(t *testing.T) {
 	}
 }
 
+func TestCanonicalizeIdempotent(t *testing.T) {
+	collection := func(main, side []CardDesc) Collection {
+		return Collection{
+			ID:          "test-123",
+			URL:         "https://example.com/test",
+			Type:        CollectionTypeWrapper{Type: "TestType", Inner: &testCollectionType{}},
+			ReleaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Partitions: []Partition{
+				{Name: "Sideboard", Cards: side},
+				{Name: "Main", Cards: main},
+			},
+		}
+	}
+	canonicalJSON := func(c *Collection) []byte {
+		t.Helper()
+		if err := c.Canonicalize(); err != nil {
+			t.Fatalf("Canonicalize() error = %v", err)
+		}
+		data, err := json.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	// Duplicate entries for Card A and Card C
+	c := collection(
+		[]CardDesc{{Name: "Card B", Count: 2}, {Name: "Card A", Count: 3}, {Name: "Card A", Count: 1}},
+		[]CardDesc{{Name: "Card C", Count: 2}, {Name: "Card C", Count: 1}, {Name: "Card D", Count: 4}},
+	)
+	first := canonicalJSON(&c)
+	if second := canonicalJSON(&c); string(second) != string(first) {
+		t.Errorf("second Canonicalize() changed JSON:\n%s\n%s", first, second)
+	}
+
+	// Same content in another order canonicalizes to the same bytes
+	reordered := collection(
+		[]CardDesc{{Name: "Card A", Count: 1}, {Name: "Card B", Count: 2}, {Name: "Card A", Count: 3}},
+		[]CardDesc{{Name: "Card D", Count: 4}, {Name: "Card C", Count: 1}, {Name: "Card C", Count: 2}},
+	)
+	if got := canonicalJSON(&reordered); string(got) != string(first) {
+		t.Errorf("reordered input canonicalized to\n%s\nwant\n%s", got, first)
+	}
+
+	if got := c.Partitions[0]; got.Name != "Main" || got.Cards[0] != (CardDesc{Name: "Card A", Count: 1}) {
+		t.Errorf("Partitions[0] = %+v, want Main starting with 1 Card A", got)
+	}
+}
+
+func TestCanonicalizePartitionOrder(t *testing.T) {
+	names := func(ps []Partition) []string {
+		var out []string
+		for _, p := range ps {
+			out = append(out, p.Name)
+		}
+		return out
+	}
+	partition := func(name string) Partition {
+		return Partition{Name: name, Cards: []CardDesc{{Name: "Card A", Count: 1}}}
+	}
+	want := []string{"Main", "Sideboard", "Commander", "Scratchpad", "Maybeboard", "Tokens"}
+	inputs := [][]string{
+		{"Tokens", "Scratchpad", "Commander", "Sideboard", "Maybeboard", "Main"},
+		{"Sideboard", "Main", "Tokens", "Commander", "Maybeboard", "Scratchpad"},
+		want,
+	}
+	var hash string
+	for _, in := range inputs {
+		c := Collection{
+			ID:          "test-123",
+			URL:         "https://example.com/test",
+			Type:        CollectionTypeWrapper{Type: "TestType", Inner: &testCollectionType{}},
+			ReleaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		for _, name := range in {
+			c.Partitions = append(c.Partitions, partition(name))
+		}
+		if err := c.Canonicalize(); err != nil {
+			t.Fatalf("Canonicalize() error = %v", err)
+		}
+		if got := names(c.Partitions); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("Canonicalize(%v) partitions = %v, want %v", in, got, want)
+		}
+		c.ComputeContentHash()
+		if hash != "" && c.ContentHash != hash {
+			t.Errorf("content hash of %v = %s, want %s", in, c.ContentHash, hash)
+		}
+		hash = c.ContentHash
+	}
+
+	// Yu-Gi-Oh! zones follow the same order
+	ygo := []Partition{partition("Side Deck"), partition("Extra Deck"), partition("Main Deck")}
+	sort.SliceStable(ygo, func(i, j int) bool { return PartitionLess(ygo[i].Name, ygo[j].Name) })
+	if got := names(ygo); strings.Join(got, ",") != "Main Deck,Extra Deck,Side Deck" {
+		t.Errorf("Yu-Gi-Oh! partitions sorted to %v", got)
+	}
+}
+
 func TestCanonicalizeInvalidCollection(t *testing.T) {
 	ct := &testCollectionType{}
 
@@ -132,6 +233,18 @@ func TestCanonicalizeInvalidCollection(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "unknown source",
+			collection: Collection{
+				ID:          "test",
+				URL:         "https://example.com",
+				Type:        CollectionTypeWrapper{Type: "TestType", Inner: ct},
+				ReleaseDate: time.Now(),
+				Partitions:  []Partition{{Name: "Main", Cards: []CardDesc{{Name: "Card A", Count: 1}}}},
+				Source:      "example.com",
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tt := range tests {
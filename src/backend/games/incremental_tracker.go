@@ -2,7 +2,6 @@ package games
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -10,48 +9,39 @@ import (
 	"collections/logger"
 )
 
+// incrementalTrackerKey is the tracker's BlobKV key under its prefix
+const incrementalTrackerKey = ".incremental_tracker"
+
 // IncrementalTracker tracks what has already been extracted to enable incremental updates
 type IncrementalTracker struct {
-	blob    *blob.Bucket
-	log     *logger.Logger
-	seen    map[string]time.Time // URL -> last extracted time
-	prefix  string
+	kv   *BlobKV
+	log  *logger.Logger
+	seen map[string]time.Time // URL -> last extracted time
 }
 
 // NewIncrementalTracker creates a new incremental tracker
 func NewIncrementalTracker(log *logger.Logger, blob *blob.Bucket, prefix string) *IncrementalTracker {
 	return &IncrementalTracker{
-		blob:   blob,
-		log:    log,
-		seen:   make(map[string]time.Time),
-		prefix: prefix,
+		kv:   NewBlobKV(blob, prefix),
+		log:  log,
+		seen: make(map[string]time.Time),
 	}
 }
 
 // Load loads the tracking data from blob storage
 func (it *IncrementalTracker) Load(ctx context.Context) error {
-	key := it.trackingKey()
-	exists, err := it.blob.Exists(ctx, key)
+	var trackingData struct {
+		Seen map[string]string `json:"seen"` // URL -> ISO timestamp
+	}
+	exists, err := it.kv.Get(ctx, incrementalTrackerKey, &trackingData)
 	if err != nil {
-		return fmt.Errorf("failed to check tracking data: %w", err)
+		return fmt.Errorf("failed to load tracking data: %w", err)
 	}
 	if !exists {
 		it.log.Debugf(ctx, "No existing tracking data found, starting fresh")
 		return nil
 	}
 
-	data, err := it.blob.Read(ctx, key)
-	if err != nil {
-		return fmt.Errorf("failed to read tracking data: %w", err)
-	}
-
-	var trackingData struct {
-		Seen map[string]string `json:"seen"` // URL -> ISO timestamp
-	}
-	if err := json.Unmarshal(data, &trackingData); err != nil {
-		return fmt.Errorf("failed to parse tracking data: %w", err)
-	}
-
 	// Parse timestamps
 	for url, tsStr := range trackingData.Seen {
 		if ts, err := time.Parse(time.RFC3339, tsStr); err == nil {
@@ -76,14 +66,8 @@ func (it *IncrementalTracker) Save(ctx context.Context) error {
 		trackingData.Seen[url] = ts.Format(time.RFC3339)
 	}
 
-	data, err := json.Marshal(trackingData)
-	if err != nil {
-		return fmt.Errorf("failed to marshal tracking data: %w", err)
-	}
-
-	key := it.trackingKey()
-	if err := it.blob.Write(ctx, key, data); err != nil {
-		return fmt.Errorf("failed to write tracking data: %w", err)
+	if err := it.kv.Set(ctx, incrementalTrackerKey, trackingData); err != nil {
+		return fmt.Errorf("failed to save tracking data: %w", err)
 	}
 
 	return nil
@@ -119,7 +103,3 @@ func (it *IncrementalTracker) GetStats() (total, recent int) {
 	}
 	return total, recent
 }
-
-func (it *IncrementalTracker) trackingKey() string {
-	return fmt.Sprintf("%s/.incremental_tracker.json", it.prefix)
-}
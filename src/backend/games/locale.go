@@ -0,0 +1,92 @@
+package games
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LocaleEnglish is the locale of English decks and card names
+const LocaleEnglish = "en"
+
+// localeAliases maps language names and region-qualified tags that sources
+// use to the codes NormalizeLocale returns. Chinese keeps Scryfall's split
+// into simplified (zhs) and traditional (zht), since their card names differ.
+var localeAliases = map[string]string{
+	"english":             "en",
+	"japanese":            "ja",
+	"german":              "de",
+	"french":              "fr",
+	"italian":             "it",
+	"spanish":             "es",
+	"portuguese":          "pt",
+	"russian":             "ru",
+	"korean":              "ko",
+	"chinese":             "zhs",
+	"simplified chinese":  "zhs",
+	"traditional chinese": "zht",
+	"zh":                  "zhs",
+	"zh-cn":               "zhs",
+	"zh-hans":             "zhs",
+	"zh-tw":               "zht",
+	"zh-hk":               "zht",
+	"zh-hant":             "zht",
+}
+
+// NormalizeLocale reduces a language tag or name ("en-US", "pt_BR",
+// "Japanese") to its lowercase language code ("en", "pt", "ja"). An empty
+// or blank locale stays empty.
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	locale = strings.ReplaceAll(locale, "_", "-")
+	if alias, ok := localeAliases[locale]; ok {
+		return alias
+	}
+	lang, _, _ := strings.Cut(locale, "-")
+	return lang
+}
+
+// IsEnglishLocale reports whether locale is English. An unknown (empty)
+// locale counts as English, since most sources only publish English lists.
+func IsEnglishLocale(locale string) bool {
+	locale = NormalizeLocale(locale)
+	return locale == "" || locale == LocaleEnglish
+}
+
+// LocalizedNames maps localized card names to their English names.
+// Lookups ignore case and whitespace differences.
+type LocalizedNames map[string]string
+
+func localizedKey(name string) string {
+	return strings.ToLower(NormalizeCardName(name))
+}
+
+// Add records english as the English name of localized
+func (n LocalizedNames) Add(localized, english string) {
+	n[localizedKey(localized)] = english
+}
+
+// English returns the English name of a localized card name
+func (n LocalizedNames) English(name string) (string, bool) {
+	english, ok := n[localizedKey(name)]
+	return english, ok
+}
+
+// LoadLocalizedNames reads a JSON object mapping localized card names to
+// English names
+func LoadLocalizedNames(path string) (LocalizedNames, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse localized names %s: %w", path, err)
+	}
+	names := make(LocalizedNames, len(raw))
+	for localized, english := range raw {
+		names.Add(localized, english)
+	}
+	return names, nil
+}
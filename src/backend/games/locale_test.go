@@ -0,0 +1,53 @@
+package games
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := map[string]string{
+		"":         "",
+		"  ":       "",
+		"en":       "en",
+		"en-US":    "en",
+		"pt_BR":    "pt",
+		"Japanese": "ja",
+		"ZH-Hant":  "zht",
+		"zh-CN":    "zhs",
+		"zhs":      "zhs",
+	}
+	for in, want := range tests {
+		if got := NormalizeLocale(in); got != want {
+			t.Errorf("NormalizeLocale(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestIsEnglishLocale(t *testing.T) {
+	for locale, want := range map[string]bool{"": true, "en": true, "en-GB": true, "English": true, "ja": false, "de-DE": false} {
+		if got := IsEnglishLocale(locale); got != want {
+			t.Errorf("IsEnglishLocale(%q) = %v, want %v", locale, got, want)
+		}
+	}
+}
+
+func TestLoadLocalizedNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "names.json")
+	if err := os.WriteFile(path, []byte(`{"稲妻": "Lightning Bolt", "Blitzschlag": "Lightning Bolt"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	names, err := LoadLocalizedNames(path)
+	if err != nil {
+		t.Fatalf("LoadLocalizedNames() error = %v", err)
+	}
+	for _, name := range []string{"稲妻", "blitzschlag", "  Blitzschlag "} {
+		if got, ok := names.English(name); !ok || got != "Lightning Bolt" {
+			t.Errorf("English(%q) = %q, %v, want Lightning Bolt", name, got, ok)
+		}
+	}
+	if got, ok := names.English("Counterspell"); ok {
+		t.Errorf("English(Counterspell) = %q, want no translation", got)
+	}
+}
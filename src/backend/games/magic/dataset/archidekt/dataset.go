@@ -0,0 +1,442 @@
+package archidekt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"collections/blob"
+	"collections/games"
+	"collections/games/magic/dataset"
+	"collections/games/magic/game"
+	"collections/logger"
+	"collections/scraper"
+
+	"go.uber.org/ratelimit"
+)
+
+// Dataset scrapes Commander and constructed decks from Archidekt's public
+// deck API
+type Dataset struct {
+	log  *logger.Logger
+	blob *blob.Bucket
+}
+
+func NewDataset(log *logger.Logger, blob *blob.Bucket) dataset.Dataset {
+	return &Dataset{log: log, blob: blob}
+}
+
+func (d *Dataset) Description() dataset.Description {
+	return dataset.Description{Name: "archidekt"}
+}
+
+const apiBase = "https://archidekt.com/api/"
+
+// reDeckURL matches a public deck page; the ID is numeric
+var reDeckURL = regexp.MustCompile(`^https://(?:www\.)?archidekt\.com/decks/(\d+)(?:[/?#].*)?$`)
+
+func (d *Dataset) Extract(
+	ctx context.Context,
+	sc *scraper.Scraper,
+	options ...dataset.UpdateOption,
+) error {
+	opts, err := dataset.ResolveUpdateOptions(options...)
+	if err != nil {
+		return err
+	}
+	for _, u := range opts.ItemOnlyURLs {
+		if !reDeckURL.MatchString(u) {
+			return fmt.Errorf("invalid only url: %s", u)
+		}
+	}
+
+	ids := make(chan string)
+	wg := new(sync.WaitGroup)
+	var total atomic.Int64
+	for i := 0; i < opts.Parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id, ok := <-ids:
+					if !ok {
+						return
+					}
+					if err := d.parseDeck(ctx, sc, id, opts); err != nil {
+						u := deckURL(id)
+						d.log.Field("url", u).Errorf(ctx, "failed to parse deck: %v", err)
+						// Record error in statistics if available
+						if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+							stats.RecordCategorizedError(ctx, u, "archidekt", err)
+						}
+						continue
+					}
+					total.Add(1)
+				}
+			}
+		}()
+	}
+
+	if len(opts.ItemOnlyURLs) > 0 {
+		for _, u := range opts.ItemOnlyURLs {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ids <- reDeckURL.FindStringSubmatch(u)[1]:
+			}
+		}
+	} else if err := d.searchDecks(ctx, sc, ids, opts); err != nil {
+		close(ids)
+		wg.Wait()
+		return err
+	}
+	close(ids)
+	wg.Wait()
+
+	d.log.Infof(ctx, "Extracted %d Archidekt decks", total.Load())
+	return nil
+}
+
+// searchPage is a page of Archidekt's public deck search
+type searchPage struct {
+	Next    string `json:"next"`
+	Results []struct {
+		ID int `json:"id"`
+	} `json:"results"`
+}
+
+// searchDecks sends the IDs of recently created public decks to ids, a
+// page at a time up to ScrollLimit pages and ItemLimit decks
+func (d *Dataset) searchDecks(
+	ctx context.Context,
+	sc *scraper.Scraper,
+	ids chan<- string,
+	opts dataset.ResolvedUpdateOptions,
+) error {
+	page := opts.ScrollStart.OrElse(1)
+	if page < 1 {
+		page = 1
+	}
+	sent := 0
+	for pages := 0; ; pages++ {
+		if limit, ok := opts.ScrollLimit.Get(); ok && pages >= limit {
+			return nil
+		}
+		q := url.Values{}
+		q.Set("orderBy", "-createdAt")
+		q.Set("page", strconv.Itoa(page))
+		resp, err := d.fetch(ctx, sc, apiBase+"decks/v3/?"+q.Encode(), opts)
+		if err != nil {
+			return fmt.Errorf("failed to fetch search page %d: %w", page, err)
+		}
+		var result searchPage
+		if err := json.Unmarshal(resp.Response.Body, &result); err != nil {
+			return fmt.Errorf("failed to decode search page %d: %w", page, err)
+		}
+		for _, deck := range result.Results {
+			if limit, ok := opts.ItemLimit.Get(); ok && sent >= limit {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ids <- strconv.Itoa(deck.ID):
+			}
+			sent++
+		}
+		d.log.Fieldf("page", "%d", page).
+			Fieldf("decks", "%d", len(result.Results)).
+			Fieldf("total", "%d", sent).
+			Infof(ctx, "parsed search page")
+		if len(result.Results) == 0 || result.Next == "" {
+			return nil
+		}
+		page++
+	}
+}
+
+// apiDeck is the part of a /decks/{id}/ response the dataset uses
+type apiDeck struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	DeckFormat int    `json:"deckFormat"`
+	CreatedAt  string `json:"createdAt"`
+	UpdatedAt  string `json:"updatedAt"`
+	Owner      struct {
+		Username string `json:"username"`
+	} `json:"owner"`
+	Categories []apiCategory `json:"categories"`
+	Cards      []apiCard     `json:"cards"`
+}
+
+// apiCategory is a deck's card grouping. Archidekt decks group cards by
+// role (Ramp, Draw, ...) as well as by board; includedInDeck is false for
+// boards outside the deck such as the sideboard and maybeboard, and the
+// premier category holds the commander whatever it is named.
+type apiCategory struct {
+	Name           string `json:"name"`
+	IsPremier      bool   `json:"isPremier"`
+	IncludedInDeck bool   `json:"includedInDeck"`
+}
+
+type apiCard struct {
+	Quantity   int      `json:"quantity"`
+	Categories []string `json:"categories"`
+	Card       struct {
+		OracleCard struct {
+			Name string `json:"name"`
+		} `json:"oracleCard"`
+	} `json:"card"`
+}
+
+// formats maps Archidekt's numeric deck formats to the names other
+// sources use
+var formats = map[int]string{
+	1:  "Standard",
+	2:  "Modern",
+	3:  "Commander",
+	4:  "Legacy",
+	5:  "Vintage",
+	6:  "Pauper",
+	8:  "Frontier",
+	9:  "Future Standard",
+	10: "Penny Dreadful",
+	11: "Commander", // 1v1 Commander
+	12: "Duel Commander",
+	13: "Brawl",
+	14: "Oathbreaker",
+	15: "Pioneer",
+	16: "Historic",
+	17: "Pauper Commander",
+	18: "Alchemy",
+	19: "Explorer",
+	20: "Historic Brawl",
+	21: "Gladiator",
+	22: "Premodern",
+	23: "PreDH",
+	24: "Timeless",
+	25: "Canadian Highlander",
+}
+
+// partitionOf is the partition a card in categories belongs to, or "" for
+// cards outside the deck. Commander and sideboard categories win over role
+// categories; a maybeboard card, or one in only categories excluded from
+// the deck, is dropped.
+func partitionOf(categories []string, byName map[string]apiCategory) string {
+	if len(categories) == 0 {
+		return "Main"
+	}
+	included := false
+	for _, name := range categories {
+		switch strings.ToLower(name) {
+		case "commander":
+			return "Commander"
+		case "sideboard":
+			return "Sideboard"
+		case "maybeboard":
+			return ""
+		}
+		cat, ok := byName[name]
+		if cat.IsPremier {
+			return "Commander"
+		}
+		if !ok || cat.IncludedInDeck {
+			included = true
+		}
+	}
+	if !included {
+		return ""
+	}
+	return "Main"
+}
+
+// buildCollection turns an API deck into a canonical collection
+func buildCollection(deck apiDeck) (*game.Collection, error) {
+	if deck.ID == 0 {
+		return nil, fmt.Errorf("deck has no id")
+	}
+	id := strconv.Itoa(deck.ID)
+
+	byName := make(map[string]apiCategory, len(deck.Categories))
+	for _, c := range deck.Categories {
+		byName[c.Name] = c
+	}
+
+	cards := make(map[string][]game.CardDesc)
+	index := make(map[string]int) // partition + "\x00" + name -> index in cards
+	for _, c := range deck.Cards {
+		part := partitionOf(c.Categories, byName)
+		// Normalize card name for consistency
+		name := games.NormalizeCardNameFull(c.Card.OracleCard.Name, games.CardNameOptions{})
+		if part == "" || name == "" || c.Quantity <= 0 {
+			continue
+		}
+		key := part + "\x00" + name
+		if i, ok := index[key]; ok {
+			cards[part][i].Count += c.Quantity
+			continue
+		}
+		index[key] = len(cards[part])
+		cards[part] = append(cards[part], game.CardDesc{Name: name, Count: c.Quantity})
+	}
+
+	var partitions []game.Partition
+	for _, name := range []string{"Commander", "Main", "Sideboard"} {
+		if len(cards[name]) > 0 {
+			partitions = append(partitions, game.Partition{Name: name, Cards: cards[name]})
+		}
+	}
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("deck has no cards")
+	}
+
+	date := games.ParseDateWithFallback(deck.UpdatedAt, time.Time{})
+	if date.IsZero() {
+		date = games.ParseDateWithFallback(deck.CreatedAt, time.Time{})
+	}
+
+	t := &game.CollectionTypeDeck{
+		Name:   games.CleanDeckName(deck.Name),
+		Format: formats[deck.DeckFormat],
+		Player: deck.Owner.Username,
+	}
+	tw := game.CollectionTypeWrapper{
+		Type:  t.Type(),
+		Inner: t,
+	}
+	collection := &game.Collection{
+		Type:        tw,
+		ID:          id,
+		URL:         deckURL(id),
+		ReleaseDate: date,
+		Partitions:  partitions,
+	}
+	if err := collection.Canonicalize(); err != nil {
+		return nil, fmt.Errorf("collection is invalid: %w", err)
+	}
+	return collection, nil
+}
+
+func (d *Dataset) parseDeck(
+	ctx context.Context,
+	sc *scraper.Scraper,
+	id string,
+	opts dataset.ResolvedUpdateOptions,
+) error {
+	bkey := d.collectionKey(id)
+
+	if !opts.Reparse && !opts.FetchReplaceAll {
+		exists, err := d.blob.Exists(ctx, bkey)
+		if err != nil {
+			return fmt.Errorf("failed to check if already parsed collection exists: %w", err)
+		}
+		if exists {
+			d.log.Field("id", id).Debugf(ctx, "parsed collection already exists")
+			if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+				stats.RecordSkipped()
+			}
+			return nil
+		}
+	}
+
+	page, err := d.fetch(ctx, sc, apiBase+"decks/"+id+"/", opts)
+	if err != nil {
+		return err
+	}
+	var deck apiDeck
+	if err := json.Unmarshal(page.Response.Body, &deck); err != nil {
+		return fmt.Errorf("failed to decode deck: %w", err)
+	}
+
+	collection, err := buildCollection(deck)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(collection)
+	if err != nil {
+		return err
+	}
+	if err := d.blob.Write(ctx, bkey, b); err != nil {
+		return err
+	}
+
+	// Record success in statistics if available
+	if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+		stats.RecordSuccess()
+	}
+
+	return nil
+}
+
+// deckURL is the public page of the Archidekt deck with ID id
+func deckURL(id string) string {
+	return "https://archidekt.com/decks/" + id
+}
+
+var prefix = filepath.Join("magic", "archidekt")
+
+func (d *Dataset) collectionKey(collectionID string) string {
+	return filepath.Join(prefix, collectionID+".json")
+}
+
+var (
+	reSilentThrottle = regexp.MustCompile(`(?i)too many requests|request was throttled`)
+	limiter          = ratelimit.New(60, ratelimit.Per(time.Minute))
+	defaultFetchOpts = []scraper.DoOption{
+		&scraper.OptDoSilentThrottle{
+			PageBytesRegexp: reSilentThrottle,
+		},
+		&scraper.OptDoLimiter{
+			Limiter: limiter,
+		},
+	}
+)
+
+func (d *Dataset) fetch(
+	ctx context.Context,
+	sc *scraper.Scraper,
+	u string,
+	datasetOptions dataset.ResolvedUpdateOptions,
+) (*scraper.Page, error) {
+	opts := defaultFetchOpts
+	if datasetOptions.FetchReplaceAll {
+		opts = append(opts, &scraper.OptDoReplace{})
+	}
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	page, err := sc.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (d *Dataset) IterItems(
+	ctx context.Context,
+	fn func(dataset.Item) error,
+	options ...dataset.IterItemsOption,
+) error {
+	return dataset.IterItemsBlobPrefix(
+		ctx,
+		d.blob,
+		prefix,
+		dataset.DeserializeAsCollection,
+		fn,
+	)
+}
@@ -0,0 +1,108 @@
+package archidekt
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"collections/games"
+	"collections/games/magic/game"
+)
+
+func TestBuildCollection(t *testing.T) {
+	data, err := os.ReadFile("testdata/deck.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var deck apiDeck
+	if err := json.Unmarshal(data, &deck); err != nil {
+		t.Fatal(err)
+	}
+
+	col, err := buildCollection(deck)
+	if err != nil {
+		t.Fatalf("buildCollection() error = %v", err)
+	}
+
+	if col.ID != "1234567" || col.URL != "https://archidekt.com/decks/1234567" {
+		t.Errorf("ID, URL = %q, %q", col.ID, col.URL)
+	}
+	if want := time.Date(2024, 2, 20, 9, 15, 30, 123456000, time.UTC); !col.ReleaseDate.Equal(want) {
+		t.Errorf("ReleaseDate = %v, want %v", col.ReleaseDate, want)
+	}
+	meta, ok := col.Type.DeckMetadata()
+	if !ok || meta.Name != "Meren Reanimator" || meta.Format != "Commander" || meta.Player != "bob" {
+		t.Errorf("deck metadata = %+v, %v", meta, ok)
+	}
+	// 14 main deck cards is well short of 100
+	if col.Completeness != games.CompletenessSuspect {
+		t.Errorf("Completeness = %q, want %q", col.Completeness, games.CompletenessSuspect)
+	}
+
+	// Role categories fold into Main, duplicate names merge, and the
+	// maybeboard, excluded categories and zero quantities are dropped
+	want := []game.Partition{
+		{Name: "Main", Cards: []game.CardDesc{
+			{Name: "Eternal Witness", Count: 1},
+			{Name: "Sakura-Tribe Elder", Count: 1},
+			{Name: "Sol Ring", Count: 1},
+			{Name: "Swamp", Count: 10},
+		}},
+		{Name: "Sideboard", Cards: []game.CardDesc{{Name: "Grafdigger's Cage", Count: 1}}},
+		{Name: "Commander", Cards: []game.CardDesc{{Name: "Meren of Clan Nel Toth", Count: 1}}},
+	}
+	if len(col.Partitions) != len(want) {
+		t.Fatalf("partitions = %+v, want %+v", col.Partitions, want)
+	}
+	for i, p := range want {
+		got := col.Partitions[i]
+		if got.Name != p.Name || len(got.Cards) != len(p.Cards) {
+			t.Errorf("partition %d = %+v, want %+v", i, got, p)
+			continue
+		}
+		for j := range p.Cards {
+			if got.Cards[j] != p.Cards[j] {
+				t.Errorf("partition %s card %d = %+v, want %+v", p.Name, j, got.Cards[j], p.Cards[j])
+			}
+		}
+	}
+
+	// Canonical output survives a round trip unchanged
+	b, err := json.Marshal(col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back game.Collection
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatal(err)
+	}
+	if err := back.Canonicalize(); err != nil {
+		t.Fatalf("Canonicalize() of round-tripped collection error = %v", err)
+	}
+	if b2, _ := json.Marshal(&back); string(b2) != string(b) {
+		t.Errorf("round trip changed collection:\n%s\n%s", b, b2)
+	}
+
+	if _, err := buildCollection(apiDeck{ID: 1, UpdatedAt: "2024-01-01"}); err == nil {
+		t.Error("buildCollection() of a deck without cards succeeded, want error")
+	}
+}
+
+func TestDeckURLPattern(t *testing.T) {
+	for u, want := range map[string]string{
+		"https://archidekt.com/decks/1234567":                  "1234567",
+		"https://www.archidekt.com/decks/1234567/meren_reanim": "1234567",
+		"https://archidekt.com/decks/1234567?view=table":       "1234567",
+		"https://archidekt.com/decks/abc":                      "",
+	} {
+		m := reDeckURL.FindStringSubmatch(u)
+		got := ""
+		if m != nil {
+			got = m[1]
+		}
+		if got != want {
+			t.Errorf("deck id of %q = %q, want %q", u, got, want)
+		}
+	}
+}
@@ -60,7 +60,7 @@ var reCollectionURL = regexp.MustCompile(`^https://deckbox.org/sets/\d+`)
 
 type task struct {
 	CollectionURL string
-	ReleaseDate   time.Time
+	ReleaseDate   time.Time // zero falls back to the scrape time
 }
 
 func (d *Dataset) Extract(
@@ -115,10 +115,7 @@ func (d *Dataset) Extract(
 				return ctx.Err()
 			default:
 			}
-			tasks <- task{
-				CollectionURL: u,
-				ReleaseDate:   time.Now(),
-			}
+			tasks <- task{CollectionURL: u}
 		}
 	} else {
 		if err := d.scrollPages(ctx, sc, tasks, opts); err != nil {
@@ -284,11 +281,10 @@ func (d *Dataset) parseCollection(
 	task task,
 	opts dataset.ResolvedUpdateOptions,
 ) error {
-	matches := reDeckID.FindStringSubmatch(task.CollectionURL)
-	if len(matches) != 2 {
-		return fmt.Errorf("failed to create deck id from url %s", task.CollectionURL)
+	id, err := deckID(task.CollectionURL)
+	if err != nil {
+		return err
 	}
-	id := matches[1]
 	bkey := d.collectionKey(id)
 
 	if !opts.Reparse {
@@ -298,6 +294,9 @@ func (d *Dataset) parseCollection(
 		}
 		if exists {
 			d.log.Field("url", task.CollectionURL).Debugf(ctx, "parsed collection already exists")
+			if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+				stats.RecordSkipped()
+			}
 			return nil
 		}
 	}
@@ -316,13 +315,67 @@ func (d *Dataset) parseCollection(
 		}
 	}
 
-	r := bytes.NewReader(page.Response.Body)
-	doc, err := goquery.NewDocumentFromReader(r)
+	releaseDate := task.ReleaseDate
+	if releaseDate.IsZero() {
+		releaseDate = page.ScrapedAt
+	}
+	collection, err := ParseCollection(task.CollectionURL, page.Response.Body, releaseDate)
 	if err != nil {
 		return err
 	}
+	collection.ScrapedAt = page.ScrapedAt
+
+	b, err := json.Marshal(collection)
+	if err != nil {
+		return err
+	}
+	if err := d.blob.Write(ctx, bkey, b); err != nil {
+		return err
+	}
+
+	// Record success in statistics if available
+	if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+		stats.RecordSuccess()
+	}
+
+	return nil
+}
+
+func deckID(u string) (string, error) {
+	matches := reDeckID.FindStringSubmatch(u)
+	if len(matches) != 2 {
+		return "", fmt.Errorf("failed to create deck id from url %s", u)
+	}
+	return matches[1], nil
+}
+
+// ParsePage parses the set page at u into a canonicalized collection,
+// without fetching anything. Like an extract of explicit URLs it dates the
+// collection now; use ParseCollection to supply the listing date.
+func (d *Dataset) ParsePage(ctx context.Context, u string, body []byte) (*game.Collection, error) {
+	now := time.Now()
+	collection, err := ParseCollection(u, body, now)
+	if err != nil {
+		return nil, err
+	}
+	collection.ScrapedAt = now
+	return collection, nil
+}
 
-	collectionName := strings.TrimSpace(doc.Find(".page_header .section_title span").Text())
+// ParseCollection parses the set page at u into a canonicalized
+// collection, without fetching anything. Set pages carry no date, so the
+// caller supplies the release date, normally from the listing page.
+func ParseCollection(u string, page []byte, releaseDate time.Time) (*game.Collection, error) {
+	id, err := deckID(u)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
+	if err != nil {
+		return nil, err
+	}
+
+	collectionName := games.CleanDeckName(doc.Find(".page_header .section_title span").Text())
 
 	var t game.CollectionType
 	// Try multiple selectors for format - page structure may have changed
@@ -348,20 +401,7 @@ func (d *Dataset) parseCollection(
 		}
 
 		// Map common abbreviations to full names
-		formatMap := map[string]string{
-			"com": "Commander",
-			"mod": "Modern",
-			"sta": "Standard",
-			"leg": "Legacy",
-			"vin": "Vintage",
-			"pio": "Pioneer",
-			"pau": "Pauper",
-		}
-		if mapped, ok := formatMap[strings.ToLower(format)]; ok {
-			format = mapped
-		}
-
-		format = strings.TrimSpace(format)
+		format = strings.TrimSpace(games.ExpandFormatAbbreviation(format))
 		if format == "" {
 			return true // Continue searching
 		}
@@ -408,11 +448,8 @@ func (d *Dataset) parseCollection(
 		})
 	}
 
-	if err != nil {
-		return err
-	}
 	if t == nil {
-		return fmt.Errorf("failed to find collection format")
+		return nil, fmt.Errorf("failed to find collection format")
 	}
 
 	var partitions []game.Partition
@@ -445,7 +482,7 @@ func (d *Dataset) parseCollection(
 					cardCount = 1
 				}
 				// Normalize card name for consistency
-				normalizedName := games.NormalizeCardName(cardName)
+				normalizedName := games.NormalizeCardNameFull(cardName, games.CardNameOptions{})
 				if normalizedName == "" {
 					return // Skip empty names
 				}
@@ -478,7 +515,7 @@ func (d *Dataset) parseCollection(
 		return true
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	tw := game.CollectionTypeWrapper{
@@ -487,34 +524,27 @@ func (d *Dataset) parseCollection(
 	}
 	collection := &game.Collection{
 		ID:          id,
-		URL:         task.CollectionURL,
+		URL:         u,
 		Type:        tw,
-		ReleaseDate: task.ReleaseDate,
+		ReleaseDate: releaseDate,
 		Partitions:  partitions,
+		Locale:      doc.Find("html").AttrOr("lang", ""),
 	}
 	if err := collection.Canonicalize(); err != nil {
-		return fmt.Errorf("collection is invalid: %w", err)
+		return nil, fmt.Errorf("collection is invalid: %w", err)
 	}
-
-	b, err := json.Marshal(collection)
-	if err != nil {
-		return err
-	}
-	if err := d.blob.Write(ctx, bkey, b); err != nil {
-		return err
-	}
-
-	// Record success in statistics if available
-	if stats := games.ExtractStatsFromContext(ctx); stats != nil {
-		stats.RecordSuccess()
-	}
-
-	return nil
+	return collection, nil
 }
 
 var prefix = filepath.Join("magic", "deckbox")
 
 func (d *Dataset) collectionKey(collectionID string) string {
+	return CollectionKey(collectionID)
+}
+
+// CollectionKey returns the blob key of the parsed collection with the
+// given id
+func CollectionKey(collectionID string) string {
 	return filepath.Join(prefix, collectionID+".json")
 }
 
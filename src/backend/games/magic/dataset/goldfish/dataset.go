@@ -282,11 +282,10 @@ func (d *Dataset) parseCollection(
 	u string,
 	opts dataset.ResolvedUpdateOptions,
 ) error {
-	idSubmatches := reDeckID.FindStringSubmatch(u)
-	if idSubmatches == nil {
-		return fmt.Errorf("failed to extract deck id")
+	id, err := deckID(u)
+	if err != nil {
+		return err
 	}
-	id := strings.ReplaceAll(idSubmatches[1], "/", ":")
 	bkey := d.collectionKey(id)
 
 	if !opts.Reparse && !opts.FetchReplaceAll {
@@ -296,6 +295,9 @@ func (d *Dataset) parseCollection(
 		}
 		if exists {
 			d.log.Field("url", u).Debugf(ctx, "parsed collection already exists")
+			if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+				stats.RecordSkipped()
+			}
 			return nil
 		}
 	}
@@ -309,25 +311,84 @@ func (d *Dataset) parseCollection(
 	if err != nil {
 		return err
 	}
-	r := bytes.NewReader(page.Response.Body)
-	doc, err := goquery.NewDocumentFromReader(r)
+
+	// Fetch deck in plain text format (much more reliable than HTML parsing)
+	downloadPage, err := d.fetch(ctx, sc, DownloadURL(u), opts)
+	if err != nil {
+		return fmt.Errorf("failed to fetch deck download: %w", err)
+	}
+
+	collection, err := ParseCollection(u, page.Response.Body, downloadPage.Response.Body)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(collection)
 	if err != nil {
 		return err
 	}
+	if err := d.blob.Write(ctx, bkey, b); err != nil {
+		return err
+	}
+
+	// Record success in statistics if available
+	if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+		stats.RecordSuccess()
+	}
+
+	return nil
+}
+
+func deckID(u string) (string, error) {
+	idSubmatches := reDeckID.FindStringSubmatch(u)
+	if idSubmatches == nil {
+		return "", fmt.Errorf("failed to extract deck id")
+	}
+	return strings.ReplaceAll(idSubmatches[1], "/", ":"), nil
+}
+
+// DownloadURL returns the plain-text download endpoint of the deck page at u
+func DownloadURL(u string) string {
+	deckID := strings.TrimPrefix(u, "https://www.mtggoldfish.com/deck/")
+	deckID = strings.TrimSuffix(deckID, "#paper")
+	deckID = strings.TrimSuffix(deckID, "#")
+	return fmt.Sprintf("https://www.mtggoldfish.com/deck/download/%s", deckID)
+}
+
+// ParsePage parses the deck page at u into a canonicalized collection,
+// without fetching anything. The decklist comes from the page's embedded
+// deck input; use ParseCollection to supply the plain-text download.
+func (d *Dataset) ParsePage(ctx context.Context, u string, body []byte) (*game.Collection, error) {
+	return ParseCollection(u, body, nil)
+}
+
+// ParseCollection parses the deck page at u and its plain-text download
+// into a canonicalized collection, without fetching anything. If download
+// is empty, the decklist embedded in the page's deck input is used
+// instead.
+func ParseCollection(u string, page, download []byte) (*game.Collection, error) {
+	id, err := deckID(u)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
+	if err != nil {
+		return nil, err
+	}
 
 	header := doc.Find(".header-container .title")
 	header.Find(".author").Remove()
-	deckName := strings.TrimSpace(header.Text())
+	deckName := games.CleanDeckName(header.Text())
 
 	infoStr := doc.Find(".deck-container-information").Text()
 	formatSubmatches := reFormat.FindStringSubmatch(infoStr)
 	if formatSubmatches == nil {
-		return fmt.Errorf("failed to extract deck format")
+		return nil, fmt.Errorf("failed to extract deck format")
 	}
 	format := formatSubmatches[1]
 	dateSubmatches := reDate.FindStringSubmatch(infoStr)
 	if dateSubmatches == nil {
-		return fmt.Errorf("failed to extract deck date")
+		return nil, fmt.Errorf("failed to extract deck date")
 	}
 	// Use centralized date parsing with validation
 	date, err := games.ParseDateWithValidation(dateSubmatches[1])
@@ -339,76 +400,20 @@ func (d *Dataset) parseCollection(
 			if year >= 1990 && year <= 2100 {
 				date = fallbackDate
 			} else {
-				return fmt.Errorf("fallback date %q has invalid year %d (expected 1990-2100)", dateSubmatches[1], year)
+				return nil, fmt.Errorf("fallback date %q has invalid year %d (expected 1990-2100)", dateSubmatches[1], year)
 			}
 		} else {
-			return fmt.Errorf("failed to parse deck date %q: %w (fallback also failed: %v)", dateSubmatches[1], err, fallbackErr)
+			return nil, fmt.Errorf("failed to parse deck date %q: %w (fallback also failed: %v)", dateSubmatches[1], err, fallbackErr)
 		}
 	}
 
-	// Extract deck ID from URL for download endpoint
-	deckID := strings.TrimPrefix(u, "https://www.mtggoldfish.com/deck/")
-	deckID = strings.TrimSuffix(deckID, "#paper")
-	deckID = strings.TrimSuffix(deckID, "#")
-
-	// Fetch deck in plain text format (much more reliable than HTML parsing)
-	downloadURL := fmt.Sprintf("https://www.mtggoldfish.com/deck/download/%s", deckID)
-	downloadPage, err := d.fetch(ctx, sc, downloadURL, opts)
-	if err != nil {
-		return fmt.Errorf("failed to fetch deck download: %w", err)
-	}
-
-	// Parse plain text deck format:
-	// "3 Card Name\n4 Another Card\n\n1 Sideboard Card\n..."
-	// Blank line separates main deck from sideboard
-	deckText := string(downloadPage.Response.Body)
-	lines := strings.Split(deckText, "\n")
-
-	var mainCards []game.CardDesc
-	var sideboardCards []game.CardDesc
-	inSideboard := false
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			inSideboard = true
-			continue
-		}
-
-		// Parse format: "3 Card Name" or "4 Card Name"
-		parts := strings.SplitN(line, " ", 2)
-		if len(parts) != 2 {
-			continue // Skip invalid lines
-		}
-
-		countStr := parts[0]
-		cardName := parts[1]
-
-		count, parseErr := strconv.ParseInt(countStr, 10, 0)
-		if parseErr != nil {
-			continue // Skip lines that don't start with a number
-		}
-
-		// Normalize card name for consistency
-		normalizedName := games.NormalizeCardName(cardName)
-		if normalizedName == "" || count <= 0 {
-			continue
-		}
-
-		card := game.CardDesc{
-			Name:  normalizedName,
-			Count: int(count),
-		}
-
-		if inSideboard {
-			sideboardCards = append(sideboardCards, card)
-		} else {
-			mainCards = append(mainCards, card)
-		}
+	deckText := string(download)
+	if strings.TrimSpace(deckText) == "" {
+		deckText = doc.Find("#deck_input_deck").AttrOr("value", "")
 	}
-
+	mainCards, sideboardCards := ParseDeckText(deckText)
 	if len(mainCards) == 0 {
-		return fmt.Errorf("failed to parse cards: no cards found in deck download")
+		return nil, fmt.Errorf("failed to parse cards: no cards found in deck download")
 	}
 
 	partitions := []game.Partition{{
@@ -436,31 +441,66 @@ func (d *Dataset) parseCollection(
 		Type:  t.Type(),
 		Inner: t,
 	}
-	collection := game.Collection{
+	collection := &game.Collection{
 		Type:        tw,
 		ID:          id,
 		URL:         u,
 		ReleaseDate: date,
 		Partitions:  partitions,
+		Locale:      doc.Find("html").AttrOr("lang", ""),
 	}
 	if err := collection.Canonicalize(); err != nil {
-		return fmt.Errorf("collection is invalid: %w", err)
+		return nil, fmt.Errorf("collection is invalid: %w", err)
 	}
+	return collection, nil
+}
 
-	b, err := json.Marshal(collection)
-	if err != nil {
-		return err
-	}
-	if err := d.blob.Write(ctx, bkey, b); err != nil {
-		return err
-	}
+// ParseDeckText parses the plain text deck format:
+// "3 Card Name\n4 Another Card\n\n1 Sideboard Card\n..."
+// A blank line, or a "sideboard" line as in the page's embedded deck
+// input, separates main deck from sideboard. Lines that don't start with
+// a count are skipped.
+func ParseDeckText(deckText string) (mainCards, sideboardCards []game.CardDesc) {
+	inSideboard := false
+	for _, line := range strings.Split(deckText, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.EqualFold(line, "sideboard") {
+			inSideboard = true
+			continue
+		}
 
-	// Record success in statistics if available
-	if stats := games.ExtractStatsFromContext(ctx); stats != nil {
-		stats.RecordSuccess()
-	}
+		// Parse format: "3 Card Name" or "4 Card Name"
+		parts := strings.SplitN(line, " ", 2)
+		if len(parts) != 2 {
+			continue // Skip invalid lines
+		}
 
-	return nil
+		countStr := parts[0]
+		cardName := parts[1]
+
+		count, parseErr := strconv.ParseInt(countStr, 10, 0)
+		if parseErr != nil {
+			continue // Skip lines that don't start with a number
+		}
+
+		// Normalize card name for consistency
+		normalizedName := games.NormalizeCardNameFull(cardName, games.CardNameOptions{})
+		if normalizedName == "" || count <= 0 {
+			continue
+		}
+
+		card := game.CardDesc{
+			Name:  normalizedName,
+			Count: int(count),
+		}
+
+		if inSideboard {
+			sideboardCards = append(sideboardCards, card)
+		} else {
+			mainCards = append(mainCards, card)
+		}
+	}
+	return mainCards, sideboardCards
 }
 
 var prefix = filepath.Join("magic", "goldfish")
@@ -553,6 +593,12 @@ func extractMTGLocation(eventName string) string {
 }
 
 func (d *Dataset) collectionKey(collectionID string) string {
+	return CollectionKey(collectionID)
+}
+
+// CollectionKey returns the blob key of the parsed collection with the
+// given id
+func CollectionKey(collectionID string) string {
 	return filepath.Join(prefix, collectionID+".json")
 }
 
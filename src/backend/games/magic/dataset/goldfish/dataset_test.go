@@ -0,0 +1,67 @@
+package goldfish
+
+import (
+	"strings"
+	"testing"
+)
+
+const deckPage = `<html lang="en-US"><body>
+<div class="header-container"><h1 class="title">Mono-Red Burn <span class="author">by someone</span></h1></div>
+<div class="deck-container-information">
+Format: Modern
+Deck Date: Mar 3, 2024
+</div>
+<input type="hidden" name="deck_input[deck]" id="deck_input_deck" value="4 Lightning Bolt
+20 Mountain
+sideboard
+2 Smash to Smithereens
+">
+</body></html>`
+
+func TestParseCollection(t *testing.T) {
+	const u = "https://www.mtggoldfish.com/deck/123456"
+	if got, want := DownloadURL(u+"#paper"), "https://www.mtggoldfish.com/deck/download/123456"; got != want {
+		t.Errorf("DownloadURL() = %q, want %q", got, want)
+	}
+
+	tests := map[string]string{
+		"download":       "4 Lightning Bolt\n20 Mountain\n\n2 Smash to Smithereens\n",
+		"embedded input": "",
+	}
+	for name, download := range tests {
+		t.Run(name, func(t *testing.T) {
+			col, err := ParseCollection(u, []byte(deckPage), []byte(download))
+			if err != nil {
+				t.Fatalf("ParseCollection() error = %v", err)
+			}
+			if col.ID != "deck:123456" || col.URL != u {
+				t.Errorf("ID, URL = %q, %q", col.ID, col.URL)
+			}
+			meta, ok := col.Type.DeckMetadata()
+			if !ok || meta.Name != "Mono-Red Burn" || meta.Format != "Modern" {
+				t.Errorf("deck metadata = %+v, %v", meta, ok)
+			}
+			if col.Locale != "en" {
+				t.Errorf("Locale = %q, want en", col.Locale)
+			}
+			if col.ReleaseDate.Year() != 2024 {
+				t.Errorf("ReleaseDate = %v", col.ReleaseDate)
+			}
+			if len(col.Partitions) != 2 ||
+				col.Partitions[0].Name != "Main" || len(col.Partitions[0].Cards) != 2 ||
+				col.Partitions[1].Name != "Sideboard" || col.Partitions[1].Cards[0].Count != 2 {
+				t.Errorf("partitions = %+v", col.Partitions)
+			}
+		})
+	}
+
+	// The page's language tags the deck
+	japanese := strings.Replace(deckPage, `lang="en-US"`, `lang="ja"`, 1)
+	if col, err := ParseCollection(u, []byte(japanese), nil); err != nil || col.Locale != "ja" {
+		t.Errorf("ParseCollection() of a Japanese page = %+v, %v, want locale ja", col, err)
+	}
+
+	if _, err := ParseCollection(u, []byte(`<html></html>`), nil); err == nil {
+		t.Error("ParseCollection() of a page without deck information succeeded, want error")
+	}
+}
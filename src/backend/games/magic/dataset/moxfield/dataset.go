@@ -2,15 +2,30 @@ package moxfield
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"collections/blob"
 	"collections/games"
 	"collections/games/magic/dataset"
 	"collections/games/magic/game"
 	"collections/logger"
 	"collections/scraper"
+
+	"go.uber.org/ratelimit"
 )
 
-// Dataset scrapes Commander decks from Moxfield
+// Dataset scrapes Commander and constructed decks from Moxfield's public
+// deck API
 type Dataset struct {
 	log  *logger.Logger
 	blob *blob.Bucket
@@ -24,15 +39,388 @@ func (d *Dataset) Description() dataset.Description {
 	return dataset.Description{Name: "moxfield"}
 }
 
+const apiBase = "https://api.moxfield.com/v2/"
+
+// reDeckURL matches a public deck page; the ID is the deck's public ID
+var reDeckURL = regexp.MustCompile(`^https://(?:www\.)?moxfield\.com/decks/([A-Za-z0-9_-]+)/?(?:[?#].*)?$`)
+
 func (d *Dataset) Extract(
 	ctx context.Context,
 	sc *scraper.Scraper,
 	options ...dataset.UpdateOption,
 ) error {
-	// TODO: Implement Moxfield scraper
-	// Moxfield has a public API: https://api.moxfield.com/v2/decks/all
-	// Rate limit: Unknown, be polite
-	// Format: Commander decks primarily
-	d.log.Infof(ctx, "Moxfield scraper not yet implemented")
+	opts, err := dataset.ResolveUpdateOptions(options...)
+	if err != nil {
+		return err
+	}
+	for _, u := range opts.ItemOnlyURLs {
+		if !reDeckURL.MatchString(u) {
+			return fmt.Errorf("invalid only url: %s", u)
+		}
+	}
+
+	ids := make(chan string)
+	wg := new(sync.WaitGroup)
+	var total atomic.Int64
+	for i := 0; i < opts.Parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id, ok := <-ids:
+					if !ok {
+						return
+					}
+					if err := d.parseDeck(ctx, sc, id, opts); err != nil {
+						u := deckURL(id)
+						d.log.Field("url", u).Errorf(ctx, "failed to parse deck: %v", err)
+						// Record error in statistics if available
+						if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+							stats.RecordCategorizedError(ctx, u, "moxfield", err)
+						}
+						continue
+					}
+					total.Add(1)
+				}
+			}
+		}()
+	}
+
+	if len(opts.ItemOnlyURLs) > 0 {
+		for _, u := range opts.ItemOnlyURLs {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ids <- reDeckURL.FindStringSubmatch(u)[1]:
+			}
+		}
+	} else if err := d.searchDecks(ctx, sc, ids, opts); err != nil {
+		close(ids)
+		wg.Wait()
+		return err
+	}
+	close(ids)
+	wg.Wait()
+
+	d.log.Infof(ctx, "Extracted %d Moxfield decks", total.Load())
 	return nil
 }
+
+// searchPage is a page of Moxfield's public deck search
+type searchPage struct {
+	PageNumber int `json:"pageNumber"`
+	TotalPages int `json:"totalPages"`
+	Data       []struct {
+		PublicID string `json:"publicId"`
+	} `json:"data"`
+}
+
+const searchPageSize = 64
+
+// searchDecks sends the IDs of recently updated public decks to ids, a
+// page at a time up to ScrollLimit pages and ItemLimit decks
+func (d *Dataset) searchDecks(
+	ctx context.Context,
+	sc *scraper.Scraper,
+	ids chan<- string,
+	opts dataset.ResolvedUpdateOptions,
+) error {
+	page := opts.ScrollStart.OrElse(1)
+	if page < 1 {
+		page = 1
+	}
+	sent := 0
+	for pages := 0; ; pages++ {
+		if limit, ok := opts.ScrollLimit.Get(); ok && pages >= limit {
+			return nil
+		}
+		q := url.Values{}
+		q.Set("pageNumber", strconv.Itoa(page))
+		q.Set("pageSize", strconv.Itoa(searchPageSize))
+		q.Set("sortType", "updated")
+		q.Set("sortDirection", "Descending")
+		resp, err := d.fetch(ctx, sc, apiBase+"decks/search?"+q.Encode(), opts)
+		if err != nil {
+			return fmt.Errorf("failed to fetch search page %d: %w", page, err)
+		}
+		var result searchPage
+		if err := json.Unmarshal(resp.Response.Body, &result); err != nil {
+			return fmt.Errorf("failed to decode search page %d: %w", page, err)
+		}
+		for _, deck := range result.Data {
+			if limit, ok := opts.ItemLimit.Get(); ok && sent >= limit {
+				return nil
+			}
+			if deck.PublicID == "" {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ids <- deck.PublicID:
+			}
+			sent++
+		}
+		d.log.Fieldf("page", "%d", page).
+			Fieldf("decks", "%d", len(result.Data)).
+			Fieldf("total", "%d", sent).
+			Infof(ctx, "parsed search page")
+		if len(result.Data) == 0 || page >= result.TotalPages {
+			return nil
+		}
+		page++
+	}
+}
+
+// apiDeck is the part of a /v2/decks/all/{id} response the dataset uses
+type apiDeck struct {
+	PublicID         string `json:"publicId"`
+	Name             string `json:"name"`
+	Format           string `json:"format"`
+	CreatedAtUTC     string `json:"createdAtUtc"`
+	LastUpdatedAtUTC string `json:"lastUpdatedAtUtc"`
+	CreatedByUser    struct {
+		UserName string `json:"userName"`
+	} `json:"createdByUser"`
+	Mainboard  map[string]apiCard `json:"mainboard"`
+	Sideboard  map[string]apiCard `json:"sideboard"`
+	Commanders map[string]apiCard `json:"commanders"`
+}
+
+type apiCard struct {
+	Quantity int `json:"quantity"`
+	Card     struct {
+		Name string `json:"name"`
+	} `json:"card"`
+}
+
+// formats maps Moxfield's format identifiers to the names other sources
+// use. Unlisted identifiers are title-cased.
+var formats = map[string]string{
+	"commander":       "Commander",
+	"duelcommander":   "Duel Commander",
+	"paupercommander": "Pauper Commander",
+	"predh":           "PreDH",
+	"cedh":            "cEDH",
+	"oathbreaker":     "Oathbreaker",
+	"brawl":           "Brawl",
+	"standardbrawl":   "Standard Brawl",
+	"historicbrawl":   "Historic Brawl",
+	"standard":        "Standard",
+	"pioneer":         "Pioneer",
+	"modern":          "Modern",
+	"legacy":          "Legacy",
+	"vintage":         "Vintage",
+	"pauper":          "Pauper",
+	"premodern":       "Premodern",
+	"oldschool":       "Old School",
+	"historic":        "Historic",
+	"explorer":        "Explorer",
+	"timeless":        "Timeless",
+	"alchemy":         "Alchemy",
+	"penny":           "Penny Dreadful",
+	"gladiator":       "Gladiator",
+	"highlander":      "Highlander",
+	"canlander":       "Canadian Highlander",
+	"none":            "",
+}
+
+// formatName maps a Moxfield format identifier to our format name
+func formatName(format string) string {
+	key := strings.ToLower(strings.TrimSpace(format))
+	if name, ok := formats[key]; ok {
+		return name
+	}
+	return games.NormalizeFormatName(format)
+}
+
+// partition turns a Moxfield board into a partition's cards, merging
+// entries whose names normalize the same
+func partition(board map[string]apiCard) []game.CardDesc {
+	index := make(map[string]int, len(board))
+	var cards []game.CardDesc
+	for key, c := range board {
+		name := c.Card.Name
+		if name == "" {
+			name = key
+		}
+		// Normalize card name for consistency
+		name = games.NormalizeCardNameFull(name, games.CardNameOptions{})
+		if name == "" || c.Quantity <= 0 {
+			continue
+		}
+		if i, ok := index[name]; ok {
+			cards[i].Count += c.Quantity
+			continue
+		}
+		index[name] = len(cards)
+		cards = append(cards, game.CardDesc{Name: name, Count: c.Quantity})
+	}
+	return cards
+}
+
+// buildCollection turns an API deck into a canonical collection
+func buildCollection(deck apiDeck) (*game.Collection, error) {
+	if deck.PublicID == "" {
+		return nil, fmt.Errorf("deck has no public id")
+	}
+
+	var partitions []game.Partition
+	for _, board := range []struct {
+		name  string
+		cards map[string]apiCard
+	}{
+		{"Commander", deck.Commanders},
+		{"Main", deck.Mainboard},
+		{"Sideboard", deck.Sideboard},
+	} {
+		if cards := partition(board.cards); len(cards) > 0 {
+			partitions = append(partitions, game.Partition{Name: board.name, Cards: cards})
+		}
+	}
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("deck has no cards")
+	}
+
+	date := games.ParseDateWithFallback(deck.LastUpdatedAtUTC, time.Time{})
+	if date.IsZero() {
+		date = games.ParseDateWithFallback(deck.CreatedAtUTC, time.Time{})
+	}
+
+	t := &game.CollectionTypeDeck{
+		Name:   games.CleanDeckName(deck.Name),
+		Format: formatName(deck.Format),
+		Player: deck.CreatedByUser.UserName,
+	}
+	tw := game.CollectionTypeWrapper{
+		Type:  t.Type(),
+		Inner: t,
+	}
+	collection := &game.Collection{
+		Type:        tw,
+		ID:          deck.PublicID,
+		URL:         deckURL(deck.PublicID),
+		ReleaseDate: date,
+		Partitions:  partitions,
+	}
+	if err := collection.Canonicalize(); err != nil {
+		return nil, fmt.Errorf("collection is invalid: %w", err)
+	}
+	return collection, nil
+}
+
+func (d *Dataset) parseDeck(
+	ctx context.Context,
+	sc *scraper.Scraper,
+	id string,
+	opts dataset.ResolvedUpdateOptions,
+) error {
+	bkey := d.collectionKey(id)
+
+	if !opts.Reparse && !opts.FetchReplaceAll {
+		exists, err := d.blob.Exists(ctx, bkey)
+		if err != nil {
+			return fmt.Errorf("failed to check if already parsed collection exists: %w", err)
+		}
+		if exists {
+			d.log.Field("id", id).Debugf(ctx, "parsed collection already exists")
+			if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+				stats.RecordSkipped()
+			}
+			return nil
+		}
+	}
+
+	page, err := d.fetch(ctx, sc, apiBase+"decks/all/"+url.PathEscape(id), opts)
+	if err != nil {
+		return err
+	}
+	var deck apiDeck
+	if err := json.Unmarshal(page.Response.Body, &deck); err != nil {
+		return fmt.Errorf("failed to decode deck: %w", err)
+	}
+
+	collection, err := buildCollection(deck)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(collection)
+	if err != nil {
+		return err
+	}
+	if err := d.blob.Write(ctx, bkey, b); err != nil {
+		return err
+	}
+
+	// Record success in statistics if available
+	if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+		stats.RecordSuccess()
+	}
+
+	return nil
+}
+
+// deckURL is the public page of the deck with Moxfield public ID id
+func deckURL(id string) string {
+	return "https://www.moxfield.com/decks/" + id
+}
+
+var prefix = filepath.Join("magic", "moxfield")
+
+func (d *Dataset) collectionKey(collectionID string) string {
+	return filepath.Join(prefix, collectionID+".json")
+}
+
+var (
+	reSilentThrottle = regexp.MustCompile(`(?i)too many requests|rate limit`)
+	// Moxfield publishes no rate limit, so be polite
+	limiter          = ratelimit.New(30, ratelimit.Per(time.Minute))
+	defaultFetchOpts = []scraper.DoOption{
+		&scraper.OptDoSilentThrottle{
+			PageBytesRegexp: reSilentThrottle,
+		},
+		&scraper.OptDoLimiter{
+			Limiter: limiter,
+		},
+	}
+)
+
+func (d *Dataset) fetch(
+	ctx context.Context,
+	sc *scraper.Scraper,
+	u string,
+	datasetOptions dataset.ResolvedUpdateOptions,
+) (*scraper.Page, error) {
+	opts := defaultFetchOpts
+	if datasetOptions.FetchReplaceAll {
+		opts = append(opts, &scraper.OptDoReplace{})
+	}
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	page, err := sc.Do(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (d *Dataset) IterItems(
+	ctx context.Context,
+	fn func(dataset.Item) error,
+	options ...dataset.IterItemsOption,
+) error {
+	return dataset.IterItemsBlobPrefix(
+		ctx,
+		d.blob,
+		prefix,
+		dataset.DeserializeAsCollection,
+		fn,
+	)
+}
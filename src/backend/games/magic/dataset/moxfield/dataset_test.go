@@ -0,0 +1,112 @@
+package moxfield
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"collections/games"
+	"collections/games/magic/game"
+)
+
+func loadDeck(t *testing.T) apiDeck {
+	t.Helper()
+	data, err := os.ReadFile("testdata/deck.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var deck apiDeck
+	if err := json.Unmarshal(data, &deck); err != nil {
+		t.Fatal(err)
+	}
+	return deck
+}
+
+func TestBuildCollection(t *testing.T) {
+	col, err := buildCollection(loadDeck(t))
+	if err != nil {
+		t.Fatalf("buildCollection() error = %v", err)
+	}
+
+	if col.ID != "AbC123xyz" || col.URL != "https://www.moxfield.com/decks/AbC123xyz" {
+		t.Errorf("ID, URL = %q, %q", col.ID, col.URL)
+	}
+	if want := time.Date(2024, 3, 15, 18, 30, 12, 345000000, time.UTC); !col.ReleaseDate.Equal(want) {
+		t.Errorf("ReleaseDate = %v, want %v", col.ReleaseDate, want)
+	}
+	deck, ok := col.Type.Inner.(*game.CollectionTypeDeck)
+	if !ok {
+		t.Fatalf("collection type = %T, want *CollectionTypeDeck", col.Type.Inner)
+	}
+	if deck.Name != "Atraxa Superfriends" || deck.Format != "Commander" || deck.Player != "alice" {
+		t.Errorf("deck type = %+v", deck)
+	}
+	if col.ParserVersion != games.ParserVersion() || col.ParserVersion == "" {
+		t.Errorf("ParserVersion = %q, want %q", col.ParserVersion, games.ParserVersion())
+	}
+
+	// Canonicalize sorts partitions into games.PartitionOrder; the empty
+	// sideboard and the maybeboard are dropped
+	want := []game.Partition{
+		{Name: "Main", Cards: []game.CardDesc{
+			{Name: "Doubling Season", Count: 1},
+			{Name: "Forest", Count: 12},
+			{Name: "Sol Ring", Count: 1},
+		}},
+		{Name: "Commander", Cards: []game.CardDesc{{Name: "Atraxa, Praetors' Voice", Count: 1}}},
+	}
+	if len(col.Partitions) != len(want) {
+		t.Fatalf("partitions = %+v, want %+v", col.Partitions, want)
+	}
+	for i, p := range want {
+		got := col.Partitions[i]
+		if got.Name != p.Name || len(got.Cards) != len(p.Cards) {
+			t.Errorf("partition %d = %+v, want %+v", i, got, p)
+			continue
+		}
+		for j := range p.Cards {
+			if got.Cards[j] != p.Cards[j] {
+				t.Errorf("partition %s card %d = %+v, want %+v", p.Name, j, got.Cards[j], p.Cards[j])
+			}
+		}
+	}
+
+	if _, err := buildCollection(apiDeck{PublicID: "empty", LastUpdatedAtUTC: "2024-01-01"}); err == nil {
+		t.Error("buildCollection() of a deck without cards succeeded, want error")
+	}
+}
+
+func TestFormatName(t *testing.T) {
+	tests := map[string]string{
+		"commander":     "Commander",
+		"duelCommander": "Duel Commander",
+		"historicBrawl": "Historic Brawl",
+		"Modern":        "Modern",
+		"none":          "",
+		"someNewFormat": "Somenewformat",
+	}
+	for in, want := range tests {
+		if got := formatName(in); got != want {
+			t.Errorf("formatName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDeckURLPattern(t *testing.T) {
+	for u, want := range map[string]string{
+		"https://www.moxfield.com/decks/AbC123xyz":         "AbC123xyz",
+		"https://moxfield.com/decks/a-b_c/":                "a-b_c",
+		"https://www.moxfield.com/decks/AbC123xyz?tab=raw": "AbC123xyz",
+		"https://www.moxfield.com/users/alice":             "",
+	} {
+		m := reDeckURL.FindStringSubmatch(u)
+		got := ""
+		if m != nil {
+			got = m[1]
+		}
+		if got != want {
+			t.Errorf("deck id of %q = %q, want %q", u, got, want)
+		}
+	}
+}
@@ -276,6 +276,9 @@ func (d *Dataset) parseItem(
 		}
 		if exists {
 			d.log.Field("url", itemURL).Debugf(ctx, "parsed collection already exists")
+			if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+				stats.RecordSkipped()
+			}
 			return nil
 		}
 	}
@@ -288,13 +291,50 @@ func (d *Dataset) parseItem(
 	if err != nil {
 		return err
 	}
-	r := bytes.NewReader(page.Response.Body)
-	doc, err := goquery.NewDocumentFromReader(r)
+	collection, err := d.ParsePage(ctx, itemURL, page.Response.Body)
+	if err != nil {
+		if opts.Cat && collection != nil {
+			b, _ := json.Marshal(collection)
+			fmt.Println(string(b))
+		}
+		return err
+	}
+	b, err := json.Marshal(collection)
 	if err != nil {
 		return err
 	}
+	if opts.Cat {
+		fmt.Println(string(b))
+	}
+	if err := d.blob.Write(ctx, bkey, b); err != nil {
+		return err
+	}
 
-	deckName := doc.Find("head title").Text()
+	// Record success in statistics if available
+	if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+		stats.RecordSuccess()
+	}
+
+	return nil
+}
+
+// ParsePage parses the deck page at u into a canonicalized collection,
+// without fetching anything. A collection that fails validation is
+// returned alongside the error so callers can show it.
+func (d *Dataset) ParsePage(ctx context.Context, u string, body []byte) (*game.Collection, error) {
+	idSubmatches := reDeckID.FindStringSubmatch(u)
+	if idSubmatches == nil {
+		return nil, fmt.Errorf("failed to extract deck id from url: %s", u)
+	}
+	eID, dID := idSubmatches[1], idSubmatches[2]
+	id := fmt.Sprintf("%s.%s", eID, dID)
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	deckName := games.CleanDeckName(doc.Find("head title").Text())
 
 	var archetype string
 	doc.Find("div.S14 a").EachWithBreak(func(i int, sel *goquery.Selection) bool {
@@ -309,66 +349,31 @@ func (d *Dataset) parseItem(
 		return true
 	})
 
-	format := doc.Find(".S14 .meta_arch").Text()
-	format = strings.TrimSpace(format)
-
-	// Extract tournament metadata: player, event, placement, record
-	var player, event, placement, record string
-	var wins, losses, ties int
-
-	// Try to extract from page structure - MTGTop8 shows this in various places
-	// Look for event name in page title or headers
-	titleText := doc.Find("head title").Text()
-	if strings.Contains(titleText, " - ") {
-		parts := strings.Split(titleText, " - ")
-		if len(parts) > 1 {
-			event = strings.TrimSpace(parts[0])
+	format := parseFormat(doc, u)
+	if format == "" {
+		d.log.Field("url", u).Warnf(ctx, "no format found on deck page")
+		if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+			stats.RecordValidationFailure("missing_format")
 		}
 	}
 
-	// Look for player name in various selectors
-	doc.Find(".S14, .meta_arch, div[class*='player'], span[class*='player']").Each(func(i int, sel *goquery.Selection) {
-		text := strings.TrimSpace(sel.Text())
-		if text != "" && !strings.Contains(text, "Format:") && !strings.Contains(text, "Archetype:") {
-			// Heuristic: if it looks like a name and we don't have one yet
-			if player == "" && len(text) > 2 && len(text) < 50 && !strings.Contains(text, "http") {
-				player = text
-			}
-		}
-	})
-
-	// Try to extract placement from result/rank indicators
-	doc.Find(".S14, .meta_arch, div[class*='result'], span[class*='result'], div[class*='rank'], span[class*='rank']").Each(func(i int, sel *goquery.Selection) {
-		text := strings.TrimSpace(sel.Text())
-		if strings.Contains(text, "st") || strings.Contains(text, "nd") || strings.Contains(text, "rd") || strings.Contains(text, "th") ||
-		   strings.Contains(text, "Top") || strings.Contains(text, "Winner") || strings.Contains(text, "Finalist") {
-			placement = text
-		}
-	})
+	// Event, player and placement come from the deck header; see
+	// parseDeckHeader
+	header := parseDeckHeader(doc)
+	event, player := header.Event, header.Player
+	placement, _ := games.ParsePlacement(header.Placement)
 
-	// Try to extract record (W-L-T format)
-	doc.Find(".S14, .meta_arch, div[class*='record'], span[class*='record']").Each(func(i int, sel *goquery.Selection) {
-		text := strings.TrimSpace(sel.Text())
-		// Look for patterns like "5-2-1" or "5-2" or "5W-2L"
-		if matched, _ := regexp.MatchString(`\d+[\s-]+\d+`, text); matched {
-			record = text
-			// Parse wins/losses/ties from record
-			reRecord := regexp.MustCompile(`(\d+)[\s-]+(\d+)(?:[\s-]+(\d+))?`)
-			if matches := reRecord.FindStringSubmatch(text); len(matches) >= 3 {
-				if w, err := strconv.Atoi(matches[1]); err == nil {
-					wins = w
-				}
-				if l, err := strconv.Atoi(matches[2]); err == nil {
-					losses = l
-				}
-				if len(matches) >= 4 && matches[3] != "" {
-					if t, err := strconv.Atoi(matches[3]); err == nil {
-						ties = t
-					}
-				}
-			}
+	// Deck pages rarely list a record; when they do it sits alone in a
+	// record element. EnrichEvents fills it in from the event page.
+	var record string
+	doc.Find("div[class*='record'], span[class*='record']").EachWithBreak(func(i int, sel *goquery.Selection) bool {
+		if m := reRecord.FindStringSubmatch(strings.TrimSpace(sel.Text())); m != nil {
+			record = m[1]
+			return false
 		}
+		return true
 	})
+	wins, losses, ties := parseRecord(record)
 
 	// Try to extract date from page, fallback to current time
 	date := time.Now()
@@ -409,7 +414,7 @@ func (d *Dataset) parseItem(
 				return false
 			}
 			// Normalize card name for consistency
-			normalizedName := games.NormalizeCardName(cardName)
+			normalizedName := games.NormalizeCardNameFull(cardName, games.CardNameOptions{})
 			if normalizedName == "" {
 				// Skip empty card names (after normalization)
 				return true
@@ -423,7 +428,7 @@ func (d *Dataset) parseItem(
 		return true
 	})
 	if err != nil {
-		return fmt.Errorf("failed to parse cards: %w", err)
+		return nil, fmt.Errorf("failed to parse cards: %w", err)
 	}
 
 	// Extract tournament type and location from event name
@@ -436,7 +441,7 @@ func (d *Dataset) parseItem(
 		Archetype:      archetype,
 		Player:         player,
 		Event:          event,
-		Placement:      placement,
+		Placement:      games.KnownPlacement(placement),
 		Record:         record,
 		Wins:           wins,
 		Losses:         losses,
@@ -461,37 +466,18 @@ func (d *Dataset) parseItem(
 			Cards: cards,
 		})
 	}
-	collection := game.Collection{
+	collection := &game.Collection{
 		Type:        tw,
 		ID:          id,
-		URL:         itemURL,
+		URL:         u,
 		ReleaseDate: date,
 		Partitions:  partitions,
+		Locale:      doc.Find("html").AttrOr("lang", ""),
 	}
 	if err := collection.Canonicalize(); err != nil {
-		if opts.Cat {
-			b, _ := json.Marshal(collection)
-			fmt.Println(string(b))
-		}
-		return fmt.Errorf("collection is invalid: %w", err)
+		return collection, fmt.Errorf("collection is invalid: %w", err)
 	}
-	b, err := json.Marshal(collection)
-	if err != nil {
-		return err
-	}
-	if opts.Cat {
-		fmt.Println(string(b))
-	}
-	if err := d.blob.Write(ctx, bkey, b); err != nil {
-		return err
-	}
-
-	// Record success in statistics if available
-	if stats := games.ExtractStatsFromContext(ctx); stats != nil {
-		stats.RecordSuccess()
-	}
-
-	return nil
+	return collection, nil
 }
 
 var basePrefix = filepath.Join("magic", "mtgtop8")
@@ -585,6 +571,12 @@ func extractMTGLocation(eventName string) string {
 }
 
 func (d *Dataset) collectionKey(collectionID string) string {
+	return CollectionKey(collectionID)
+}
+
+// CollectionKey returns the blob key of the parsed collection with the
+// given id
+func CollectionKey(collectionID string) string {
 	return filepath.Join(collectionsPrefix, collectionID+".json")
 }
 
@@ -2,10 +2,14 @@ package mtgtop8
 
 import (
 	"context"
+	"os"
+	"strings"
 	"testing"
 
 	"collections/blob"
+	"collections/games"
 	"collections/games/magic/dataset"
+	"collections/games/magic/game"
 	"collections/logger"
 	"collections/scraper"
 )
@@ -19,11 +23,11 @@ func TestErrorHandling(t *testing.T) {
 	// Create a test blob storage
 	tmpDir := t.TempDir()
 	bucketURL := "file://" + tmpDir
-	blob, err := blob.NewBucket(ctx, log, bucketURL)
+	bucket, err := blob.NewBucket(ctx, log, bucketURL)
 	if err != nil {
 		t.Fatalf("failed to create blob: %v", err)
 	}
-	defer blob.Close(ctx)
+	defer bucket.Close(ctx)
 
 	// Create scraper with test blob
 	scraperBlob, err := blob.NewBucket(ctx, log, bucketURL)
@@ -34,14 +38,14 @@ func TestErrorHandling(t *testing.T) {
 	sc := scraper.NewScraper(log, scraperBlob)
 
 	// Create dataset
-	d := NewDataset(log, blob)
+	d := NewDataset(log, bucket)
 
 	// Test with invalid URL that would trigger the bug
 	// This test verifies the error handling fix doesn't lose error context
-	opts, _ := dataset.ResolveUpdateOptions(
+	opts := []dataset.UpdateOption{
 		&dataset.OptExtractItemOnlyURL{URL: "https://mtgtop8.com/event?e=123&d=456"},
 		&dataset.OptExtractParallel{Parallel: 1},
-	)
+	}
 
 	// This should either succeed (if URL is valid) or fail with a clear error
 	// The key is that if url.Parse fails, we should see the error, not a generic one
@@ -68,13 +72,13 @@ func TestCardNameNormalization(t *testing.T) {
 
 	tmpDir := t.TempDir()
 	bucketURL := "file://" + tmpDir
-	blob, err := blob.NewBucket(ctx, log, bucketURL)
+	bucket, err := blob.NewBucket(ctx, log, bucketURL)
 	if err != nil {
 		t.Fatalf("failed to create blob: %v", err)
 	}
-	defer blob.Close(ctx)
+	defer bucket.Close(ctx)
 
-	d := NewDataset(log, blob)
+	d := NewDataset(log, bucket)
 
 	// Verify the dataset exists and can be created
 	if d == nil {
@@ -86,3 +90,105 @@ func TestCardNameNormalization(t *testing.T) {
 		t.Errorf("Description().Name = %q, want %q", desc.Name, "mtgtop8")
 	}
 }
+
+func TestParsePage(t *testing.T) {
+	body, err := os.ReadFile("testdata/deckpage.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const u = "https://mtgtop8.com/event?e=54321&d=600001&f=MO"
+	col, err := (&Dataset{}).ParsePage(context.Background(), u, body)
+	if err != nil {
+		t.Fatalf("ParsePage() error = %v", err)
+	}
+	if col.ID != "54321.600001" || col.URL != u {
+		t.Errorf("ID, URL = %q, %q", col.ID, col.URL)
+	}
+	deck, ok := col.Type.Inner.(*game.CollectionTypeDeck)
+	if !ok || deck.Format != "Modern" || deck.Archetype != "Boros Energy" ||
+		deck.Player != "Carol Jones" || deck.Placement == nil || *deck.Placement != 1 || deck.TournamentID != "54321" {
+		t.Errorf("deck = %+v", col.Type.Inner)
+	}
+	counts := make(map[string]int)
+	for _, p := range col.Partitions {
+		for _, c := range p.Cards {
+			counts[p.Name] += c.Count
+		}
+	}
+	if counts["Main"] != 16 || counts["Sideboard"] != 2 || len(counts) != 2 {
+		t.Errorf("partition card counts = %v, want Main 16 and Sideboard 2", counts)
+	}
+
+	if _, err := (&Dataset{}).ParsePage(context.Background(), "https://mtgtop8.com/format?f=MO", body); err == nil {
+		t.Error("ParsePage() of a non-deck url succeeded, want error")
+	}
+}
+
+func TestParsePageTopPlacement(t *testing.T) {
+	body, err := os.ReadFile("testdata/deck.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The fixture has a single card, so the collection fails validation but
+	// is still returned
+	col, _ := (&Dataset{}).ParsePage(context.Background(), "https://mtgtop8.com/event?e=54321&d=600003&f=MO", body)
+	if col == nil {
+		t.Fatal("ParsePage() returned no collection")
+	}
+	deck := col.Type.Inner.(*game.CollectionTypeDeck)
+	// A shared "3-4" finish is placed at its best position
+	if deck.Player != "Carol Jones" || deck.Placement == nil || *deck.Placement != 3 {
+		t.Errorf("Player, Placement = %q, %v, want Carol Jones, 3", deck.Player, deck.Placement)
+	}
+	// The "#3-4" rank in the header is not a record
+	if deck.Record != "" || deck.Wins != 0 || deck.Losses != 0 {
+		t.Errorf("Record = %q (%d-%d), want none", deck.Record, deck.Wins, deck.Losses)
+	}
+	meta, ok := col.Type.DeckMetadata()
+	if !ok || meta.Placement == nil || *meta.Placement != 3 {
+		t.Errorf("DeckMetadata().Placement = %v, want 3", meta.Placement)
+	}
+}
+
+func TestParsePageFormatFallback(t *testing.T) {
+	body, err := os.ReadFile("testdata/deck_noformat.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	d := &Dataset{log: log}
+
+	noEventCode := []byte(strings.ReplaceAll(string(body), "event?e=54321&f=LE", "event?e=54321"))
+	tests := []struct {
+		name string
+		u    string
+		body []byte
+		want string
+	}{
+		{"event link", "https://mtgtop8.com/event?e=54321&d=600001", body, "Legacy"},
+		{"deck url", "https://mtgtop8.com/event?e=54321&d=600001&f=PI", noEventCode, "Pioneer"},
+		{"none", "https://mtgtop8.com/event?e=54321&d=600001", noEventCode, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := games.NewExtractStats(log)
+			col, err := d.ParsePage(games.WithExtractStats(ctx, stats), tt.u, tt.body)
+			if err != nil {
+				t.Fatalf("ParsePage() error = %v", err)
+			}
+			deck := col.Type.Inner.(*game.CollectionTypeDeck)
+			if deck.Format != tt.want {
+				t.Errorf("Format = %q, want %q", deck.Format, tt.want)
+			}
+			wantFailures := 0
+			if tt.want == "" {
+				wantFailures = 1
+			}
+			if got := stats.ValidationFailures["missing_format"]; got != wantFailures {
+				t.Errorf("missing_format failures = %d, want %d", got, wantFailures)
+			}
+		})
+	}
+}
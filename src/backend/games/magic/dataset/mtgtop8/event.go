@@ -0,0 +1,186 @@
+package mtgtop8
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// eventParticipant is one deck's row on an event page
+type eventParticipant struct {
+	DeckID    string
+	Player    string
+	Placement string // "1", "2", "3-4", "5-8", ...
+	Record    string // "5-2-1" when the page shows it
+}
+
+// eventPage is what an event page says about the event and its decks
+type eventPage struct {
+	Name         string
+	Format       string
+	Participants map[string]eventParticipant // Deck ID -> participant
+}
+
+var (
+	rePlacement = regexp.MustCompile(`^\d+(?:-\d+)?$`)
+	reRank      = regexp.MustCompile(`^#\s*(\d+(?:-\d+)?)`)
+	reRecord    = regexp.MustCompile(`^\(?(\d+-\d+(?:-\d+)?)\)?$`)
+)
+
+// parseEventPage parses an event page. Each deck row links to the deck
+// (?e=EVENT&d=DECK) and carries the placement and player next to it, which
+// is more reliable than the deck page's own headers.
+func parseEventPage(doc *goquery.Document) *eventPage {
+	ev := &eventPage{
+		Name:         parseEventName(doc),
+		Format:       parseFormat(doc, ""),
+		Participants: make(map[string]eventParticipant),
+	}
+	doc.Find(".hover_tr, .chosen_tr").Each(func(_ int, row *goquery.Selection) {
+		var p eventParticipant
+		row.Find("a[href]").EachWithBreak(func(_ int, a *goquery.Selection) bool {
+			href, _ := a.Attr("href")
+			u, err := url.Parse(href)
+			if err != nil {
+				return true
+			}
+			if dID := u.Query().Get("d"); dID != "" {
+				p.DeckID = dID
+				return false
+			}
+			return true
+		})
+		if p.DeckID == "" {
+			return
+		}
+		if _, seen := ev.Participants[p.DeckID]; seen {
+			return
+		}
+
+		player := row.Find("a[href*='player=']").First()
+		if player.Length() == 0 {
+			player = row.Find(".G11").First()
+		}
+		p.Player = strings.TrimSpace(player.Text())
+
+		row.Find("div, span, td").EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+			if sel.Children().Length() > 0 {
+				return true
+			}
+			text := strings.TrimSpace(sel.Text())
+			switch {
+			case p.Placement == "" && rePlacement.MatchString(text):
+				p.Placement = text
+			case p.Record == "" && reRecord.MatchString(text):
+				p.Record = reRecord.FindStringSubmatch(text)[1]
+			}
+			return true
+		})
+
+		ev.Participants[p.DeckID] = p
+	})
+	return ev
+}
+
+// parseRecord splits a "W-L" or "W-L-T" record into its counts
+func parseRecord(record string) (wins, losses, ties int) {
+	parts := strings.Split(record, "-")
+	counts := make([]int, 3)
+	for i := 0; i < len(parts) && i < 3; i++ {
+		counts[i], _ = strconv.Atoi(parts[i])
+	}
+	return counts[0], counts[1], counts[2]
+}
+
+// deckHeader is the event metadata shown at the top of a deck page
+type deckHeader struct {
+	Event     string
+	Player    string
+	Placement string // Same form as the event page: "1", "3-4", "5-8", ...
+}
+
+// parseDeckHeader reads the deck page's .event_title lines. The first names
+// the event; the ranked one reads "#3-4 Deck Name - Player", with the player
+// linked as a player search.
+func parseDeckHeader(doc *goquery.Document) deckHeader {
+	var h deckHeader
+	doc.Find(".event_title").Each(func(_ int, sel *goquery.Selection) {
+		text := strings.TrimSpace(sel.Text())
+		m := reRank.FindStringSubmatch(text)
+		if m == nil {
+			if h.Event == "" {
+				h.Event = text
+			}
+			return
+		}
+		h.Placement = m[1]
+		player := sel.Find("a.player_big, a[href*='player=']").First()
+		if player.Length() > 0 {
+			h.Player = strings.TrimSpace(player.Text())
+		} else if i := strings.LastIndex(text, " - "); i >= 0 {
+			h.Player = strings.TrimSpace(text[i+3:])
+		}
+	})
+	if h.Event == "" {
+		h.Event = titleEventName(doc)
+	}
+	return h
+}
+
+// formatCodes maps mtgtop8's f= URL codes to format names
+var formatCodes = map[string]string{
+	"ST":   "Standard",
+	"PI":   "Pioneer",
+	"EXP":  "Explorer",
+	"HI":   "Historic",
+	"ALCH": "Alchemy",
+	"MO":   "Modern",
+	"PREM": "Premodern",
+	"LE":   "Legacy",
+	"VI":   "Vintage",
+	"PAU":  "Pauper",
+	"EDH":  "Duel Commander",
+	"cEDH": "cEDH",
+	"PEA":  "Peasant",
+	"HIGH": "Highlander",
+	"BL":   "Block",
+	"EX":   "Extended",
+	"LI":   "Limited",
+}
+
+// parseFormat reads the format of a deck or event page. The .meta_arch
+// line isn't always under .S14, and on some layouts it's empty, so this
+// falls back to the f= code of the event link and then of u itself.
+// Returns "" when none of them name a known format.
+func parseFormat(doc *goquery.Document, u string) string {
+	for _, sel := range []string{".S14 .meta_arch", ".meta_arch"} {
+		if format := strings.TrimSpace(doc.Find(sel).First().Text()); format != "" {
+			return format
+		}
+	}
+	var format string
+	doc.Find("a[href*='f=']").EachWithBreak(func(_ int, a *goquery.Selection) bool {
+		href, _ := a.Attr("href")
+		if !strings.HasPrefix(href, "event?") && !strings.HasPrefix(href, "?e=") {
+			return true
+		}
+		format = urlFormat(href)
+		return format == ""
+	})
+	if format != "" {
+		return format
+	}
+	return urlFormat(u)
+}
+
+// urlFormat returns the format named by a URL's f= code
+func urlFormat(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+	return formatCodes[parsed.Query().Get("f")]
+}
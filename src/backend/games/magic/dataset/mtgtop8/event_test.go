@@ -0,0 +1,96 @@
+package mtgtop8
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseEventPage(t *testing.T) {
+	f, err := os.Open("testdata/event.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ev := parseEventPage(doc)
+	if ev.Name != "Modern Challenge 64 @ mtgo.com" {
+		t.Errorf("Name = %q, want %q", ev.Name, "Modern Challenge 64 @ mtgo.com")
+	}
+	if ev.Format != "Modern" {
+		t.Errorf("Format = %q, want Modern", ev.Format)
+	}
+
+	want := []eventParticipant{
+		{DeckID: "600001", Player: "Alice", Placement: "1", Record: "7-1"},
+		{DeckID: "600002", Player: "Bob", Placement: "2"},
+		{DeckID: "600003", Player: "Carol", Placement: "3-4"},
+		{DeckID: "600004", Player: "Dave Smith", Placement: "5-8"},
+	}
+	if len(ev.Participants) != len(want) {
+		t.Fatalf("got %d participants, want %d: %+v", len(ev.Participants), len(want), ev.Participants)
+	}
+	for _, w := range want {
+		if got := ev.Participants[w.DeckID]; got != w {
+			t.Errorf("participant %s = %+v, want %+v", w.DeckID, got, w)
+		}
+	}
+}
+
+func TestParseRecord(t *testing.T) {
+	tests := []struct {
+		record             string
+		wins, losses, ties int
+	}{
+		{"7-1", 7, 1, 0},
+		{"5-2-1", 5, 2, 1},
+	}
+	for _, tt := range tests {
+		w, l, ti := parseRecord(tt.record)
+		if w != tt.wins || l != tt.losses || ti != tt.ties {
+			t.Errorf("parseRecord(%q) = %d, %d, %d, want %d, %d, %d", tt.record, w, l, ti, tt.wins, tt.losses, tt.ties)
+		}
+	}
+}
+
+func TestParseDeckHeader(t *testing.T) {
+	f, err := os.Open("testdata/deck.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := parseDeckHeader(doc)
+	want := deckHeader{
+		Event:     "Modern Challenge 64 @ mtgo.com",
+		Player:    "Carol Jones",
+		Placement: "3-4",
+	}
+	if got != want {
+		t.Errorf("parseDeckHeader() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseDeckHeaderUnlinkedPlayer(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(
+		`<html><head><title>Legacy League - MTGTop8</title></head><body>` +
+			`<div class="event_title">#1 Dimir Tempo - Dave</div></body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := parseDeckHeader(doc)
+	want := deckHeader{Event: "Legacy League", Player: "Dave", Placement: "1"}
+	if got != want {
+		t.Errorf("parseDeckHeader() = %+v, want %+v", got, want)
+	}
+}
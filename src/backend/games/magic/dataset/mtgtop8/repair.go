@@ -0,0 +1,239 @@
+package mtgtop8
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"collections/blob"
+	"collections/games"
+	"collections/games/magic/dataset"
+	"collections/games/magic/game"
+	"collections/scraper"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// RepairEvents backfills empty Event names on stored decks. Deck URLs encode
+// the event ID, so decks are grouped by event and each event page is
+// fetched once; its name is applied to every deck in the group. Returns the
+// number of decks updated.
+func (d *Dataset) RepairEvents(
+	ctx context.Context,
+	sc *scraper.Scraper,
+	options ...dataset.UpdateOption,
+) (int, error) {
+	return d.enrichEvents(ctx, sc, true, options...)
+}
+
+// EnrichEvents applies event-page metadata to every stored deck: the event
+// name and format where they are missing, and the player, placement and
+// record listed for the deck on its event page, which replace the deck page
+// heuristics. Each event page is fetched once. Returns the number of decks
+// updated.
+func (d *Dataset) EnrichEvents(
+	ctx context.Context,
+	sc *scraper.Scraper,
+	options ...dataset.UpdateOption,
+) (int, error) {
+	return d.enrichEvents(ctx, sc, false, options...)
+}
+
+func (d *Dataset) enrichEvents(
+	ctx context.Context,
+	sc *scraper.Scraper,
+	missingOnly bool,
+	options ...dataset.UpdateOption,
+) (int, error) {
+	opts, err := dataset.ResolveUpdateOptions(options...)
+	if err != nil {
+		return 0, err
+	}
+
+	// Event ID -> blob keys of decks to update
+	decks := make(map[string][]string)
+	it := d.blob.List(ctx, &blob.OptListPrefix{Prefix: collectionsPrefix})
+	for it.Next(ctx) {
+		b, err := it.Value(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read collection: %w", err)
+		}
+		var col game.Collection
+		if err := json.Unmarshal(b, &col); err != nil {
+			d.log.Field("key", it.Key()).Warnf(ctx, "skipping unparseable collection: %v", err)
+			continue
+		}
+		deck, ok := col.Type.Inner.(*game.CollectionTypeDeck)
+		if !ok || (missingOnly && deck.Event != "") {
+			continue
+		}
+		eID := eventID(&col, deck)
+		if eID == "" {
+			continue
+		}
+		decks[eID] = append(decks[eID], it.Key())
+	}
+	if err := it.Err(); err != nil {
+		return 0, fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	eIDs := make([]string, 0, len(decks))
+	for eID := range decks {
+		eIDs = append(eIDs, eID)
+	}
+	sort.Strings(eIDs)
+
+	updated := 0
+	for _, eID := range eIDs {
+		ev, err := d.fetchEvent(ctx, opts, sc, eID)
+		if err != nil {
+			d.log.Field("event", eID).Errorf(ctx, "failed to fetch event page: %v", err)
+			continue
+		}
+		if missingOnly {
+			ev.Participants = nil
+		}
+		if ev.Name == "" && ev.Format == "" && len(ev.Participants) == 0 {
+			d.log.Field("event", eID).Warnf(ctx, "no event metadata found")
+			continue
+		}
+		for _, key := range decks[eID] {
+			changed, err := d.applyEvent(ctx, key, ev)
+			if err != nil {
+				return updated, err
+			}
+			if changed {
+				updated++
+			}
+		}
+	}
+	return updated, nil
+}
+
+// eventID returns the deck's event ID, preferring the stored TournamentID
+// and falling back to the e= parameter of the deck URL
+func eventID(col *game.Collection, deck *game.CollectionTypeDeck) string {
+	if deck.TournamentID != "" {
+		return deck.TournamentID
+	}
+	if key := games.EventKeyFromURL(col.URL); key.Source == "mtgtop8" {
+		return key.ID
+	}
+	return ""
+}
+
+func (d *Dataset) fetchEvent(
+	ctx context.Context,
+	opts dataset.ResolvedUpdateOptions,
+	sc *scraper.Scraper,
+	eID string,
+) (*eventPage, error) {
+	u := base.ResolveReference(&url.URL{
+		Path:     "event",
+		RawQuery: url.Values{"e": {eID}}.Encode(),
+	})
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	page, err := dataset.Do(ctx, sc, opts, req)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page.Response.Body))
+	if err != nil {
+		return nil, err
+	}
+	return parseEventPage(doc), nil
+}
+
+// parseEventName reads the event name from an event page: the first
+// .event_title, or the page title before " - " as parseItem does
+func parseEventName(doc *goquery.Document) string {
+	if name := strings.TrimSpace(doc.Find(".event_title").First().Text()); name != "" {
+		return name
+	}
+	return titleEventName(doc)
+}
+
+// titleEventName reads the event name from the page title, "Event - ..."
+func titleEventName(doc *goquery.Document) string {
+	title := doc.Find("head title").Text()
+	if parts := strings.Split(title, " - "); len(parts) > 1 {
+		return strings.TrimSpace(parts[0])
+	}
+	return ""
+}
+
+// applyEvent rewrites the collection at key with metadata from its event
+// page, deriving tournament type and location from the name as parseItem
+// does. Reports whether anything changed.
+func (d *Dataset) applyEvent(ctx context.Context, key string, ev *eventPage) (bool, error) {
+	b, err := d.blob.Read(ctx, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read collection: %w", err)
+	}
+	var col game.Collection
+	if err := json.Unmarshal(b, &col); err != nil {
+		return false, fmt.Errorf("failed to parse collection: %w", err)
+	}
+	deck, ok := col.Type.Inner.(*game.CollectionTypeDeck)
+	if !ok {
+		return false, fmt.Errorf("collection %s is not a deck", key)
+	}
+	before := *deck
+
+	if deck.Event == "" && ev.Name != "" {
+		deck.Event = ev.Name
+		if deck.TournamentType == "" {
+			deck.TournamentType = extractMTGTournamentType(ev.Name)
+		}
+		if deck.Location == "" {
+			deck.Location = extractMTGLocation(ev.Name)
+		}
+	}
+	if deck.Format == "" && ev.Format != "" {
+		deck.Format = ev.Format
+	}
+	if m := reDeckID.FindStringSubmatch(col.URL); m != nil {
+		if p, ok := ev.Participants[m[2]]; ok {
+			if p.Player != "" {
+				deck.Player = p.Player
+			}
+			if n, ok := games.ParsePlacement(p.Placement); ok {
+				deck.Placement = &n
+			}
+			if p.Record != "" {
+				deck.Record = p.Record
+				deck.Wins, deck.Losses, deck.Ties = parseRecord(p.Record)
+			}
+		}
+	}
+	if deck.Event == before.Event && deck.Format == before.Format && deck.Player == before.Player &&
+		samePlacement(deck.Placement, before.Placement) && deck.Record == before.Record {
+		return false, nil
+	}
+
+	b, err = json.Marshal(col)
+	if err != nil {
+		return false, err
+	}
+	if err := d.blob.Write(ctx, key, b); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// samePlacement reports whether two placements are both unknown or the
+// same finishing position
+func samePlacement(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,197 @@
+package mtgtop8
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"collections/blob"
+	"collections/games/magic/game"
+	"collections/logger"
+	"collections/scraper"
+)
+
+func TestRepairEvents(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	fetches := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := r.URL.Query().Get("e")
+		fetches[e]++
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `<html><head><title>Event %s</title></head><body>`+
+			`<div class="event_title">Modern Challenge %s</div>`+
+			`<div class="event_title">#1 Burn - Alice</div></body></html>`, e, e)
+	}))
+	defer server.Close()
+
+	oldBase := base
+	u, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	base = u
+	defer func() { base = oldBase }()
+
+	gamesBlob := blob.NewMemBucket(ctx, log)
+	defer gamesBlob.Close(ctx)
+	scraperBlob := blob.NewMemBucket(ctx, log)
+	defer scraperBlob.Close(ctx)
+	sc := scraper.NewScraper(log, scraperBlob)
+	d := NewDataset(log, gamesBlob)
+
+	writeDeck := func(eID, dID, event string) string {
+		t.Helper()
+		deck := &game.CollectionTypeDeck{Name: "Burn", Format: "Modern", Event: event}
+		col := game.Collection{
+			ID:          eID + "." + dID,
+			URL:         fmt.Sprintf("https://mtgtop8.com/event?e=%s&d=%s", eID, dID),
+			Type:        game.CollectionTypeWrapper{Type: deck.Type(), Inner: deck},
+			ReleaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Partitions:  []game.Partition{{Name: "Main", Cards: []game.CardDesc{{Name: "Lightning Bolt", Count: 4}}}},
+		}
+		b, err := json.Marshal(col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key := d.collectionKey(col.ID)
+		if err := gamesBlob.Write(ctx, key, b); err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	shared := []string{
+		writeDeck("100", "1", ""),
+		writeDeck("100", "2", ""),
+		writeDeck("100", "3", ""),
+	}
+	named := writeDeck("200", "1", "Legacy League")
+
+	n, err := d.RepairEvents(ctx, sc)
+	if err != nil {
+		t.Fatalf("RepairEvents() error = %v", err)
+	}
+	if n != len(shared) {
+		t.Errorf("RepairEvents() = %d, want %d", n, len(shared))
+	}
+	if fetches["100"] != 1 {
+		t.Errorf("event 100 fetched %d times, want 1", fetches["100"])
+	}
+	if fetches["200"] != 0 {
+		t.Errorf("event 200 fetched %d times, want 0", fetches["200"])
+	}
+
+	readEvent := func(key string) string {
+		t.Helper()
+		b, err := gamesBlob.Read(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var col game.Collection
+		if err := json.Unmarshal(b, &col); err != nil {
+			t.Fatal(err)
+		}
+		return col.Type.Inner.(*game.CollectionTypeDeck).Event
+	}
+	for _, key := range shared {
+		if got := readEvent(key); got != "Modern Challenge 100" {
+			t.Errorf("%s event = %q, want %q", filepath.Base(key), got, "Modern Challenge 100")
+		}
+	}
+	if got := readEvent(named); got != "Legacy League" {
+		t.Errorf("named deck event = %q, want unchanged", got)
+	}
+}
+
+func TestEnrichEvents(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	fixture, err := os.ReadFile("testdata/event.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	oldBase := base
+	u, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	base = u
+	defer func() { base = oldBase }()
+
+	gamesBlob := blob.NewMemBucket(ctx, log)
+	defer gamesBlob.Close(ctx)
+	scraperBlob := blob.NewMemBucket(ctx, log)
+	defer scraperBlob.Close(ctx)
+	sc := scraper.NewScraper(log, scraperBlob)
+	d := NewDataset(log, gamesBlob)
+
+	// Deck page heuristics picked up junk for the player and missed the format
+	deck := &game.CollectionTypeDeck{Name: "Boros Energy", Player: "Boros Energy", TournamentID: "54321"}
+	col := game.Collection{
+		ID:          "54321.600001",
+		URL:         "https://mtgtop8.com/event?e=54321&d=600001&f=MO",
+		Type:        game.CollectionTypeWrapper{Type: deck.Type(), Inner: deck},
+		ReleaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Partitions:  []game.Partition{{Name: "Main", Cards: []game.CardDesc{{Name: "Guide of Souls", Count: 4}}}},
+	}
+	b, err := json.Marshal(col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := d.collectionKey(col.ID)
+	if err := gamesBlob.Write(ctx, key, b); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := d.EnrichEvents(ctx, sc)
+	if err != nil {
+		t.Fatalf("EnrichEvents() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("EnrichEvents() = %d, want 1", n)
+	}
+
+	b, err = gamesBlob.Read(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got game.Collection
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	gotDeck := got.Type.Inner.(*game.CollectionTypeDeck)
+	if gotDeck.Event != "Modern Challenge 64 @ mtgo.com" {
+		t.Errorf("Event = %q", gotDeck.Event)
+	}
+	if gotDeck.Format != "Modern" {
+		t.Errorf("Format = %q, want Modern", gotDeck.Format)
+	}
+	if gotDeck.Player != "Alice" || gotDeck.Placement == nil || *gotDeck.Placement != 1 {
+		t.Errorf("Player, Placement = %q, %v, want Alice, 1", gotDeck.Player, gotDeck.Placement)
+	}
+	if gotDeck.Record != "7-1" || gotDeck.Wins != 7 || gotDeck.Losses != 1 {
+		t.Errorf("Record = %q (%d-%d), want 7-1", gotDeck.Record, gotDeck.Wins, gotDeck.Losses)
+	}
+
+	// Nothing changes on a second pass
+	if n, err := d.EnrichEvents(ctx, sc); err != nil || n != 0 {
+		t.Errorf("second EnrichEvents() = %d, %v, want 0, nil", n, err)
+	}
+}
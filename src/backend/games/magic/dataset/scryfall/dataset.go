@@ -110,6 +110,8 @@ type card struct {
 	Set             string     `json:"set"`
 	CollectorNumber string     `json:"collector_number"`
 	Faces           []cardFace `json:"card_faces"`
+	Lang            string     `json:"lang"`
+	PrintedName     string     `json:"printed_name"`
 }
 
 type imageURIs struct {
@@ -227,6 +229,9 @@ func (d *Dataset) extractCards(
 			}
 			if exists {
 				d.log.Field("name", rawCard.Name).Debugf(ctx, "parsed card already exists")
+				if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+					stats.RecordSkipped()
+				}
 				continue
 			}
 		}
@@ -291,6 +296,10 @@ func (d *Dataset) parseCard(
 			{URL: ref.String()},
 		},
 	}
+	// Printings only available in another language carry their printed name
+	if lang := games.NormalizeLocale(rawCard.Lang); !games.IsEnglishLocale(lang) && rawCard.PrintedName != "" {
+		card.PrintedNames = map[string]string{lang: rawCard.PrintedName}
+	}
 
 	bkey := d.cardKey(card.Name)
 	b, err := json.Marshal(card)
@@ -434,6 +443,9 @@ func (d *Dataset) parseCollection(
 		}
 		if exists {
 			d.log.Field("url", u).Debugf(ctx, "parsed collection already exists")
+			if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+				stats.RecordSkipped()
+			}
 			return nil
 		}
 	}
@@ -446,16 +458,56 @@ func (d *Dataset) parseCollection(
 	if err != nil {
 		return err
 	}
-	r := bytes.NewReader(page.Response.Body)
-	doc, err := goquery.NewDocumentFromReader(r)
+
+	set, err := d.ParsePage(ctx, u, page.Response.Body)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(set)
 	if err != nil {
 		return err
 	}
 
+	if err := d.blob.Write(ctx, bkey, b); err != nil {
+		return err
+	}
+
+	// Record success in statistics if available
+	if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+		stats.RecordSuccess()
+	}
+
+	return nil
+}
+
+// ParsePage parses the set page at u into a canonicalized collection,
+// without fetching anything
+func (d *Dataset) ParsePage(ctx context.Context, u string, body []byte) (*game.Collection, error) {
+	return ParseCollection(ctx, d.log, u, body)
+}
+
+// ParseCollection parses the set page at u into a canonicalized
+// collection, without fetching anything. Partitions without a name or
+// cards are skipped with a warning on log.
+func ParseCollection(
+	ctx context.Context,
+	log *logger.Logger,
+	u string,
+	page []byte,
+) (*game.Collection, error) {
+	parts := strings.Split(u, "/")
+	setID := parts[len(parts)-1]
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
+	if err != nil {
+		return nil, err
+	}
+
 	setNameRaw := strings.TrimSpace(doc.FindMatcher(goquery.Single(".set-header-title-h1")).Text())
 	setNameSubmatches := reSetName.FindStringSubmatch(setNameRaw)
 	if setNameSubmatches == nil {
-		return fmt.Errorf("failed to extract set name: %q", setNameRaw)
+		return nil, fmt.Errorf("failed to extract set name: %q", setNameRaw)
 	}
 	setName := setNameSubmatches[1]
 	setCode := setNameSubmatches[2]
@@ -463,7 +515,7 @@ func (d *Dataset) parseCollection(
 	setReleasedRaw := strings.TrimSpace(doc.FindMatcher(goquery.Single(".set-header-title-words")).Text())
 	setReleasedSubmatches := reSetReleased.FindStringSubmatch(setReleasedRaw)
 	if setReleasedSubmatches == nil {
-		return fmt.Errorf("failed to extract set release date: %q", setReleasedRaw)
+		return nil, fmt.Errorf("failed to extract set release date: %q", setReleasedRaw)
 	}
 		// Use centralized date parsing with validation
 		setReleaseDate, err := games.ParseDateWithValidation(setReleasedSubmatches[1])
@@ -480,7 +532,7 @@ func (d *Dataset) parseCollection(
 			}
 		}
 	if err != nil {
-		return fmt.Errorf("failed to parse set release date %q: %w", setReleasedSubmatches[1], err)
+		return nil, fmt.Errorf("failed to parse set release date %q: %w", setReleasedSubmatches[1], err)
 	}
 
 	sel := doc.Find(".card-grid-header-content")
@@ -533,7 +585,7 @@ func (d *Dataset) parseCollection(
 		// If we still don't have a partition name, skip this partition with a warning
 		if partitionName == "" {
 			html, _ := headerSel.Html()
-			d.log.Field("html", html).Warnf(ctx, "skipping partition with no name")
+			log.Field("html", html).Warnf(ctx, "skipping partition with no name")
 			return true // Continue to next partition instead of failing
 		}
 
@@ -548,7 +600,7 @@ func (d *Dataset) parseCollection(
 					return
 				}
 				// Normalize card name first, then check for duplicates
-				normalizedName := games.NormalizeCardName(t)
+				normalizedName := games.NormalizeCardNameFull(t, games.CardNameOptions{})
 				if normalizedName == "" {
 					return // Skip empty card names
 				}
@@ -567,7 +619,7 @@ func (d *Dataset) parseCollection(
 
 		// Only add partition if it has cards (validation requires non-empty partitions)
 		if len(cards) == 0 {
-			d.log.Field("partition", partitionName).Warnf(ctx, "skipping partition with no cards")
+			log.Field("partition", partitionName).Warnf(ctx, "skipping partition with no cards")
 			return true // Continue to next partition
 		}
 
@@ -578,19 +630,19 @@ func (d *Dataset) parseCollection(
 		return true
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Check if we have any partitions with cards
 	if len(partitions) == 0 {
-		return fmt.Errorf("collection has no partitions with cards")
+		return nil, fmt.Errorf("collection has no partitions with cards")
 	}
 
 	ty := &game.CollectionTypeSet{
 		Name: setName,
 		Code: setCode,
 	}
-	set := game.Collection{
+	set := &game.Collection{
 		Type: game.CollectionTypeWrapper{
 			Type:  ty.Type(),
 			Inner: ty,
@@ -599,28 +651,14 @@ func (d *Dataset) parseCollection(
 		URL:         u,
 		ReleaseDate: setReleaseDate,
 		Partitions:  partitions,
+		Locale:      doc.Find("html").AttrOr("lang", ""),
 	}
 
 	// Validate and normalize the collection before writing
 	if err := set.Canonicalize(); err != nil {
-		return fmt.Errorf("collection is invalid: %w", err)
-	}
-
-	b, err := json.Marshal(set)
-	if err != nil {
-		return err
+		return nil, fmt.Errorf("collection is invalid: %w", err)
 	}
-
-	if err := d.blob.Write(ctx, bkey, b); err != nil {
-		return err
-	}
-
-	// Record success in statistics if available
-	if stats := games.ExtractStatsFromContext(ctx); stats != nil {
-		stats.RecordSuccess()
-	}
-
-	return nil
+	return set, nil
 }
 
 func (d *Dataset) resolveRef(ref string) (string, error) {
@@ -641,6 +679,12 @@ func (d *Dataset) cardKey(cardName string) string {
 }
 
 func (d *Dataset) collectionKey(collectionID string) string {
+	return CollectionKey(collectionID)
+}
+
+// CollectionKey returns the blob key of the parsed collection with the
+// given id
+func CollectionKey(collectionID string) string {
 	return filepath.Join(collectionsPrefix, collectionID+".json")
 }
 
@@ -0,0 +1,249 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"collections/cio"
+	"collections/games"
+)
+
+// Colors are the five colors of mana in WUBRG order
+var Colors = []string{"W", "U", "B", "R", "G"}
+
+// colorNames name Colors, and "C" for colorless, in report metrics
+var colorNames = map[string]string{"W": "white", "U": "blue", "B": "black", "R": "red", "G": "green", "C": "colorless"}
+
+// basicLands are counted as lands without card data, since every deck
+// lists them and card data often skips them
+var basicLands = map[string]bool{
+	"Plains": true, "Island": true, "Swamp": true, "Mountain": true, "Forest": true, "Wastes": true,
+	"Snow-Covered Plains": true, "Snow-Covered Island": true, "Snow-Covered Swamp": true,
+	"Snow-Covered Mountain": true, "Snow-Covered Forest": true,
+}
+
+// ManaValue returns the mana value of a cost like "{2}{U}{U}" and the
+// colors in it, in WUBRG order. X costs count 0, hybrid symbols count
+// their larger half ({2/W} is 2) and every other symbol counts 1.
+func ManaValue(cost string) (int, []string) {
+	value := 0
+	seen := make(map[string]bool)
+	for _, sym := range strings.Split(cost, "{") {
+		sym = strings.TrimSuffix(sym, "}")
+		if sym == "" {
+			continue
+		}
+		symValue := 0
+		for _, half := range strings.Split(sym, "/") {
+			n, err := strconv.Atoi(half)
+			switch {
+			case err == nil:
+				symValue = max(symValue, n)
+			case half == "X" || half == "Y" || half == "Z":
+			default:
+				symValue = max(symValue, 1)
+				seen[half] = true
+			}
+		}
+		value += symValue
+	}
+	var colors []string
+	for _, c := range Colors {
+		if seen[c] {
+			colors = append(colors, c)
+		}
+	}
+	return value, colors
+}
+
+// DeckStats is a deck's mana curve and colors, over the cards outside the
+// sideboard
+type DeckStats struct {
+	Cards int `json:"cards"`
+	Lands int `json:"lands"`
+	// ManaCurve maps the mana value of each nonland card's front face to
+	// its copies in the deck
+	ManaCurve map[int]int `json:"mana_curve"`
+	// Colors maps a color to the nonland copies with it in their cost;
+	// colorless spells count under "C"
+	Colors map[string]int `json:"colors"`
+	// Unknown lists card names with no card data, left out of the curve
+	Unknown []string `json:"unknown,omitempty"`
+}
+
+// AnalyzeDeck computes deck stats using cards, keyed by card name, for
+// mana costs and type lines
+func AnalyzeDeck(partitions []games.Partition, cards map[string]Card) DeckStats {
+	stats := DeckStats{
+		ManaCurve: make(map[int]int),
+		Colors:    make(map[string]int),
+	}
+	for _, p := range partitions {
+		if p.Name == "Sideboard" || p.Name == "Maybeboard" {
+			continue
+		}
+		for _, c := range p.Cards {
+			stats.Cards += c.Count
+			card, ok := cards[c.Name]
+			if !ok || len(card.Faces) == 0 {
+				if basicLands[c.Name] {
+					stats.Lands += c.Count
+				} else {
+					stats.Unknown = append(stats.Unknown, c.Name)
+				}
+				continue
+			}
+			face := card.Faces[0]
+			if strings.Contains(face.TypeLine, "Land") {
+				stats.Lands += c.Count
+				continue
+			}
+			value, colors := ManaValue(face.ManaCost)
+			stats.ManaCurve[value] += c.Count
+			if len(colors) == 0 {
+				stats.Colors["C"] += c.Count
+			}
+			for _, color := range colors {
+				stats.Colors[color] += c.Count
+			}
+		}
+	}
+	sort.Strings(stats.Unknown)
+	return stats
+}
+
+// nonlands is the number of cards in the mana curve
+func (s DeckStats) nonlands() int {
+	n := 0
+	for _, copies := range s.ManaCurve {
+		n += copies
+	}
+	return n
+}
+
+// MeanManaValue is the average mana value of the deck's nonland cards
+func (s DeckStats) MeanManaValue() float64 {
+	n, total := s.nonlands(), 0
+	if n == 0 {
+		return 0
+	}
+	for value, copies := range s.ManaCurve {
+		total += value * copies
+	}
+	return float64(total) / float64(n)
+}
+
+// maxUnknownListed bounds the card names quoted in the report's warning
+const maxUnknownListed = 5
+
+// DeckAnalyzer is the games.Analyzer for Magic decks: mana curve, land
+// ratio and color shares, using Cards for card data.
+type DeckAnalyzer struct {
+	Cards map[string]Card // keyed by card name and by each face's name
+}
+
+var (
+	_ games.Analyzer       = (*DeckAnalyzer)(nil)
+	_ games.CardDataLoader = (*DeckAnalyzer)(nil)
+)
+
+func (a *DeckAnalyzer) Game() string     { return "magic" }
+func (a *DeckAnalyzer) DeckType() string { return CollectionTypeDeck{}.Type() }
+
+// CardDataDir is where the scryfall dataset stores cards
+func (a *DeckAnalyzer) CardDataDir() string {
+	return filepath.Join("magic", "scryfall", "cards")
+}
+
+// LoadCardData indexes the card JSON files under dir by name, and by face
+// name for multi-faced cards, which decks often list by their front face
+func (a *DeckAnalyzer) LoadCardData(dir string) (int, error) {
+	files, err := cio.FindCollectionFiles(dir, cio.FindOpts{SkipErrors: true})
+	if err != nil {
+		return 0, err
+	}
+	sort.Strings(files)
+	cards := make(map[string]Card, len(files))
+	for _, file := range files {
+		data, err := cio.ReadCollectionFile(file)
+		if err != nil {
+			continue
+		}
+		var card Card
+		if err := json.Unmarshal(data, &card); err != nil || card.Name == "" {
+			continue
+		}
+		if _, ok := cards[card.Name]; !ok {
+			cards[card.Name] = card
+		}
+		for _, face := range card.Faces {
+			if _, ok := cards[face.Name]; !ok && len(card.Faces) > 1 {
+				cards[face.Name] = card
+			}
+		}
+	}
+	a.Cards = cards
+	return len(cards), nil
+}
+
+// Analyze reports the mean "land_ratio", "mean_mana_value" and
+// "<color>_ratio" (share of nonland copies with the color in their cost)
+// metrics, the combined "mana_curve" histogram, and warnings for decks
+// without lands and for cards with no card data
+func (a *DeckAnalyzer) Analyze(decks []*games.Collection) games.AnalysisReport {
+	report := games.AnalysisReport{
+		Game:       a.Game(),
+		Decks:      len(decks),
+		Metrics:    make(map[string]float64),
+		Histograms: map[string]map[int]int{"mana_curve": {}},
+	}
+	unknown := make(map[string]int)
+	for _, deck := range decks {
+		stats := AnalyzeDeck(deck.Partitions, a.Cards)
+		if stats.Cards > 0 {
+			report.Metrics["land_ratio"] += float64(stats.Lands) / float64(stats.Cards)
+		}
+		report.Metrics["mean_mana_value"] += stats.MeanManaValue()
+		if n := stats.nonlands(); n > 0 {
+			for color, name := range colorNames {
+				report.Metrics[name+"_ratio"] += float64(stats.Colors[color]) / float64(n)
+			}
+		}
+		for value, n := range stats.ManaCurve {
+			report.Histograms["mana_curve"][value] += n
+		}
+		for _, name := range stats.Unknown {
+			unknown[name]++
+		}
+		if stats.Lands == 0 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s: no lands", deck.ID))
+		}
+	}
+	if len(decks) > 0 {
+		for k := range report.Metrics {
+			report.Metrics[k] /= float64(len(decks))
+		}
+	}
+	if len(unknown) > 0 {
+		names := make([]string, 0, len(unknown))
+		for name := range unknown {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if unknown[names[i]] != unknown[names[j]] {
+				return unknown[names[i]] > unknown[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		listed := names
+		if len(listed) > maxUnknownListed {
+			listed = listed[:maxUnknownListed]
+		}
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d card names have no card data (most common: %s)", len(names), strings.Join(listed, ", ")))
+	}
+	return report
+}
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"collections/games"
+)
+
+func TestManaValue(t *testing.T) {
+	tests := []struct {
+		cost   string
+		value  int
+		colors []string
+	}{
+		{"", 0, nil},
+		{"{0}", 0, nil},
+		{"{2}{U}{U}", 4, []string{"U"}},
+		{"{X}{R}", 1, []string{"R"}},
+		{"{G}{W}{U}", 3, []string{"W", "U", "G"}},
+		{"{2/W}{2/W}", 4, []string{"W"}},
+		{"{B/G}", 1, []string{"B", "G"}},
+		{"{U/P}{C}", 2, []string{"U"}},
+		{"{10}", 10, nil},
+	}
+	for _, tt := range tests {
+		value, colors := ManaValue(tt.cost)
+		if value != tt.value || !reflect.DeepEqual(colors, tt.colors) {
+			t.Errorf("ManaValue(%q) = %d, %v, want %d, %v", tt.cost, value, colors, tt.value, tt.colors)
+		}
+	}
+}
+
+func TestDeckAnalyzer(t *testing.T) {
+	if _, ok := games.LookupAnalyzer("magic"); !ok {
+		t.Fatal("no analyzer registered for magic")
+	}
+	card := func(name, cost, typeLine string) Card {
+		return Card{Name: name, Faces: []CardFace{{Name: name, ManaCost: cost, TypeLine: typeLine}}}
+	}
+	a := &DeckAnalyzer{Cards: map[string]Card{
+		"Lightning Bolt":       card("Lightning Bolt", "{R}", "Instant"),
+		"Counterspell":         card("Counterspell", "{U}{U}", "Instant"),
+		"Sol Ring":             card("Sol Ring", "{1}", "Artifact"),
+		"Steam Vents":          card("Steam Vents", "", "Land — Island Mountain"),
+		"Expressive Iteration": card("Expressive Iteration", "{U}{R}", "Sorcery"),
+	}}
+	izzet := &games.Collection{ID: "izzet", Partitions: []games.Partition{
+		{Name: "Main", Cards: []games.CardDesc{
+			{Name: "Lightning Bolt", Count: 4},
+			{Name: "Counterspell", Count: 4},
+			{Name: "Expressive Iteration", Count: 4},
+			{Name: "Steam Vents", Count: 4},
+			{Name: "Island", Count: 4},
+		}},
+		{Name: "Sideboard", Cards: []games.CardDesc{{Name: "Sol Ring", Count: 15}}},
+	}}
+	artifacts := &games.Collection{ID: "artifacts", Partitions: []games.Partition{
+		{Name: "Main", Cards: []games.CardDesc{{Name: "Sol Ring", Count: 4}, {Name: "Mox Opal", Count: 4}}},
+	}}
+
+	report := a.Analyze([]*games.Collection{izzet, artifacts})
+	if report.Game != "magic" || report.Decks != 2 {
+		t.Errorf("report = %s/%d decks, want magic/2", report.Game, report.Decks)
+	}
+	for metric, want := range map[string]float64{
+		"land_ratio":      (8.0/20 + 0) / 2,
+		"mean_mana_value": (20.0/12 + 1) / 2,
+		"red_ratio":       (8.0/12 + 0) / 2,
+		"blue_ratio":      (8.0/12 + 0) / 2,
+		"colorless_ratio": (0 + 1.0) / 2,
+		"green_ratio":     0,
+	} {
+		if got := report.Metrics[metric]; math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", metric, got, want)
+		}
+	}
+	if h := report.Histograms["mana_curve"]; !reflect.DeepEqual(h, map[int]int{1: 8, 2: 8}) {
+		t.Errorf("mana_curve = %v, want 1: 8, 2: 8", h)
+	}
+	want := []string{"artifacts: no lands", "1 card names have no card data (most common: Mox Opal)"}
+	if !reflect.DeepEqual(report.Warnings, want) {
+		t.Errorf("Warnings = %q, want %q", report.Warnings, want)
+	}
+}
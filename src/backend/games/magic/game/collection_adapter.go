@@ -1,6 +1,12 @@
 package game
 
-import "collections/games"
+import (
+	"encoding/json"
+	"time"
+
+	"collections/cio"
+	"collections/games"
+)
 
 // Ensure Collection implements games.CollectionAdapter
 var _ games.CollectionAdapter = (*Collection)(nil)
@@ -37,3 +43,100 @@ func (c *Collection) GetPartitions() []games.Partition {
 	return result
 }
 
+func (ct *CollectionTypeDeck) DeckMetadata() games.DeckMetadata {
+	return games.DeckMetadata{
+		Name:      ct.Name,
+		Archetype: ct.Archetype,
+		Format:    ct.Format,
+		Player:    ct.Player,
+		Event:     ct.Event,
+		EventDate: ct.EventDate,
+		Placement: ct.Placement,
+	}
+}
+
+// DeckMetadata returns the wrapped type's deck metadata, or false for sets
+// and cubes
+func (w CollectionTypeWrapper) DeckMetadata() (games.DeckMetadata, bool) {
+	return games.DeckMetadataOf(w.Inner)
+}
+
+// gamesDeck adapts a deck to games.CollectionType; its fields marshal and
+// its DeckMetadata is promoted as they are on the deck
+type gamesDeck struct{ *CollectionTypeDeck }
+
+func (gamesDeck) Type() string      { return CollectionTypeDeck{}.Type() }
+func (gamesDeck) IsCollectionType() {}
+
+// gamesType adapts sets and cubes to games.CollectionType
+type gamesType struct{ inner CollectionType }
+
+func (t gamesType) Type() string                 { return t.inner.Type() }
+func (t gamesType) IsCollectionType()            {}
+func (t gamesType) MarshalJSON() ([]byte, error) { return json.Marshal(t.inner) }
+
+// GamesCollection converts c to a games.Collection, for tools that handle
+// every game's collections alike. MTG collections carry no source, so it
+// is inferred from the URL and the blob key.
+func (c *Collection) GamesCollection(key string) *games.Collection {
+	var inner games.CollectionType
+	switch t := c.Type.Inner.(type) {
+	case nil:
+	case *CollectionTypeDeck:
+		inner = gamesDeck{t}
+	default:
+		inner = gamesType{t}
+	}
+	return &games.Collection{
+		ID:            c.ID,
+		URL:           c.URL,
+		Type:          games.CollectionTypeWrapper{Type: c.Type.Type, Inner: inner},
+		ReleaseDate:   c.ReleaseDate,
+		Partitions:    c.GetPartitions(),
+		Source:        games.InferSource(c.URL, key),
+		Completeness:  c.Completeness,
+		Locale:        c.Locale,
+		ParserVersion: c.ParserVersion,
+		ScrapedAt:     c.ScrapedAt,
+	}
+}
+
+// DeserializeAsAnyCollection is a games.ItemDeserializer for collections
+// of any game. Types in games.TypeRegistry decode as games.Collection;
+// anything else is read as an MTG collection, whose types aren't
+// registered, and converted with GamesCollection. Change tracking fields
+// an MTG file does carry (source, scraped_at, updated_at, version) are
+// kept.
+func DeserializeAsAnyCollection(key string, data []byte) (games.Item, error) {
+	data, err := cio.Decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	var header struct {
+		Type struct {
+			Type string `json:"type"`
+		} `json:"type"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	if games.TypeRegistry[header.Type.Type] != nil {
+		return games.DeserializeAsCollection(key, data)
+	}
+	var col struct {
+		Collection
+		Source    games.Source `json:"source"`
+		UpdatedAt time.Time    `json:"updated_at"`
+		Version   int          `json:"version"`
+	}
+	if err := json.Unmarshal(data, &col); err != nil {
+		return nil, err
+	}
+	gc := col.GamesCollection(key)
+	if col.Source != "" {
+		gc.Source = col.Source
+	}
+	gc.UpdatedAt = col.UpdatedAt
+	gc.Version = col.Version
+	return &games.CollectionItem{Collection: gc}, nil
+}
@@ -10,15 +10,25 @@ import (
 	"strings"
 	"time"
 
+	"collections/games"
+
 	"github.com/samber/mo"
 )
 
+func init() {
+	games.RegisterAnalyzer(&DeckAnalyzer{})
+}
+
 type Card struct {
 	Name       string          `json:"name"`
 	Faces      []CardFace      `json:"faces"`
 	Images     []CardImage     `json:"image"`
 	References []CardReference `json:"references"`
 	Features   CardFeatures    `json:"features"`
+
+	// PrintedNames maps a locale to the card's name in non-English
+	// printings, where the source has them
+	PrintedNames map[string]string `json:"printed_names,omitempty"`
 }
 
 type CardImage struct {
@@ -116,6 +126,23 @@ type Collection struct {
 	Type        CollectionTypeWrapper `json:"type"`
 	ReleaseDate time.Time             `json:"release_date"`
 	Partitions  []Partition           `json:"partitions"`
+
+	// Completeness grades how fully a deck was parsed; set by Canonicalize
+	// for decks, empty for sets and cubes
+	Completeness games.Completeness `json:"completeness,omitempty"`
+
+	// Locale is the language of the card names, as a games.NormalizeLocale
+	// code; empty when the source doesn't say
+	Locale string `json:"locale,omitempty"`
+
+	// ParserVersion is the games.ParserVersion of the build that parsed
+	// this; set by Canonicalize when empty
+	ParserVersion string `json:"parser_version,omitempty"`
+
+	// ScrapedAt is when the page was scraped, zero when unknown. A release
+	// date close to it is likely a scrape-time fallback (see
+	// games.EffectiveDate).
+	ScrapedAt time.Time `json:"scraped_at,omitzero"`
 }
 
 var reBadCardName = regexp.MustCompile(`(^\s*$)|(\p{Cc})`)
@@ -144,8 +171,10 @@ func (c *Collection) Canonicalize() error {
 		return errors.New("collection has no partitions")
 	}
 	sort.SliceStable(c.Partitions, func(i, j int) bool {
-		return c.Partitions[i].Name < c.Partitions[j].Name
+		return games.PartitionLess(c.Partitions[i].Name, c.Partitions[j].Name)
 	})
+	// Spell cards the same whichever source they came from
+	c.renameCards(games.ResolveCardAlias)
 	for i, p := range c.Partitions {
 		if p.Name == "" {
 			return fmt.Errorf("partition %d has empty name", i)
@@ -185,9 +214,83 @@ func (c *Collection) Canonicalize() error {
 			return p.Cards[i].Name < p.Cards[j].Name
 		})
 	}
+
+	c.Locale = games.NormalizeLocale(c.Locale)
+	c.Completeness = ""
+	if meta, ok := c.Type.DeckMetadata(); ok {
+		c.Completeness = games.DeckCompleteness(c.Type.Type, meta, c.GetPartitions())
+	}
+	if c.ParserVersion == "" {
+		c.ParserVersion = games.ParserVersion()
+	}
 	return nil
 }
 
+// EffectiveDate is games.EffectiveDate for c: the deck's event date when
+// it has one, else its release date, which is estimated when it's the
+// scrape time
+func (c *Collection) EffectiveDate() (time.Time, bool) {
+	return games.EffectiveDate(c.EventDate(), c.ReleaseDate, c.ScrapedAt)
+}
+
+// EventDate is the deck's event date as scraped, or "" for sets, cubes
+// and decks without one
+func (c *Collection) EventDate() string {
+	if deck, ok := c.Type.Inner.(*CollectionTypeDeck); ok {
+		return deck.EventDate
+	}
+	return ""
+}
+
+// TranslateCardNames replaces localized card names with their English
+// names, merging cards that translate to the same name, and returns how
+// many cards were renamed. Locale keeps the language the source used.
+func (c *Collection) TranslateCardNames(names games.LocalizedNames) int {
+	return c.renameCards(func(name string) string {
+		if english, ok := names.English(name); ok {
+			return english
+		}
+		return name
+	})
+}
+
+// renameCards replaces each card name with rename(name), merging cards
+// that end up with the same name, and returns how many were renamed
+func (c *Collection) renameCards(rename func(string) string) int {
+	renamed := 0
+	for i, p := range c.Partitions {
+		cards := make([]CardDesc, 0, len(p.Cards))
+		index := make(map[string]int, len(p.Cards))
+		for _, card := range p.Cards {
+			if name := rename(card.Name); name != card.Name {
+				card.Name = name
+				renamed++
+			}
+			key := strings.ToLower(card.Name)
+			if j, ok := index[key]; ok {
+				cards[j].Count += card.Count
+				continue
+			}
+			index[key] = len(cards)
+			cards = append(cards, card)
+		}
+		c.Partitions[i].Cards = cards
+	}
+	return renamed
+}
+
+// LocalizedNamesOf maps the printed names of cards in a card corpus to
+// their English names
+func LocalizedNamesOf(cards []Card) games.LocalizedNames {
+	names := make(games.LocalizedNames)
+	for _, card := range cards {
+		for _, printed := range card.PrintedNames {
+			names.Add(printed, card.Name)
+		}
+	}
+	return names
+}
+
 type Partition struct {
 	Name  string     `json:"name"`
 	Cards []CardDesc `json:"cards"`
@@ -246,7 +349,7 @@ type CollectionTypeDeck struct {
 	// Tournament metadata
 	Player    string `json:"player,omitempty"`    // Player name
 	Event     string `json:"event,omitempty"`     // Tournament/event name
-	Placement string `json:"placement,omitempty"` // "1st", "Top 8", etc.
+	Placement *int   `json:"placement,omitempty"` // Finishing position (1 = 1st place), nil when unknown
 	EventDate string `json:"eventDate,omitempty"` // Tournament date
 	Wins      int    `json:"wins,omitempty"`      // Win count
 	Losses    int    `json:"losses,omitempty"`    // Loss count
@@ -271,6 +374,24 @@ type CollectionTypeDeck struct {
 	RoundResults []RoundResult `json:"roundResults,omitempty"`
 }
 
+// UnmarshalJSON decodes Placement with games.UnmarshalPlacement
+func (ct *CollectionTypeDeck) UnmarshalJSON(b []byte) error {
+	type plain CollectionTypeDeck
+	aux := struct {
+		*plain
+		Placement json.RawMessage `json:"placement,omitempty"`
+	}{plain: (*plain)(ct)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	placement, err := games.UnmarshalPlacement(aux.Placement)
+	if err != nil {
+		return err
+	}
+	ct.Placement = placement
+	return nil
+}
+
 // RoundResult represents a single round/match result
 type RoundResult struct {
 	RoundNumber  int    `json:"roundNumber"`
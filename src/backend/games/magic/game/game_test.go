@@ -0,0 +1,147 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"collections/games"
+)
+
+func TestCollectionTypeDeckMetadata(t *testing.T) {
+	tests := []struct {
+		json   string
+		ok     bool
+		format string
+		player string
+		place  int // 0 for unknown
+	}{
+		{`{"type":"Deck","inner":{"name":"Burn","format":"Modern","archetype":"Burn","player":"Alice","event":"GP Vegas","placement":3,"eventDate":"2024-03-01"}}`, true, "Modern", "Alice", 3},
+		// Placements stored as text before they were ints
+		{`{"type":"Deck","inner":{"name":"Burn","format":"Modern","archetype":"Burn","player":"Alice","event":"GP Vegas","placement":"Top 8","eventDate":"2024-03-01"}}`, true, "Modern", "Alice", 8},
+		{`{"type":"Deck","inner":{"name":"Burn","format":"Modern","archetype":"Burn","player":"Alice","event":"GP Vegas","placement":"3-4","eventDate":"2024-03-01"}}`, true, "Modern", "Alice", 3},
+		{`{"type":"Deck","inner":{"name":"Burn","format":"Modern","archetype":"Burn","player":"Alice","event":"GP Vegas","placement":"Participant","eventDate":"2024-03-01"}}`, true, "Modern", "Alice", 0},
+		{`{"type":"Set","inner":{"name":"Alpha","code":"LEA"}}`, false, "", "", 0},
+		{`{"type":"Cube","inner":{"name":"Vintage Cube"}}`, false, "", "", 0},
+	}
+	for _, tt := range tests {
+		var w CollectionTypeWrapper
+		if err := json.Unmarshal([]byte(tt.json), &w); err != nil {
+			t.Fatal(err)
+		}
+		meta, ok := w.DeckMetadata()
+		if ok != tt.ok {
+			t.Errorf("%s: DeckMetadata() ok = %v, want %v", w.Type, ok, tt.ok)
+		}
+		if ok && (meta.Archetype != "Burn" || meta.Event != "GP Vegas" || meta.EventDate != "2024-03-01") {
+			t.Errorf("%s: DeckMetadata() = %+v, want archetype, event and date from the deck", w.Type, meta)
+		}
+		place := 0
+		if meta.Placement != nil {
+			place = *meta.Placement
+		}
+		if meta.Format != tt.format || meta.Player != tt.player || place != tt.place {
+			t.Errorf("%s: DeckMetadata() = %+v, want format %q, player %q, placement %d", w.Type, meta, tt.format, tt.player, tt.place)
+		}
+	}
+}
+
+func TestCanonicalizeCompleteness(t *testing.T) {
+	singletons := func(n int) []CardDesc {
+		cards := make([]CardDesc, n)
+		for i := range cards {
+			cards[i] = CardDesc{Name: fmt.Sprintf("Card %03d", i), Count: 1}
+		}
+		return cards
+	}
+	tests := []struct {
+		name string
+		typ  CollectionTypeWrapper
+		main int
+		want games.Completeness
+	}{
+		{"commander", CollectionTypeWrapper{Type: "Deck", Inner: &CollectionTypeDeck{Format: "Commander"}}, 100, games.CompletenessFull},
+		{"commander short", CollectionTypeWrapper{Type: "Deck", Inner: &CollectionTypeDeck{Format: "Commander"}}, 60, games.CompletenessPartial},
+		{"modern few cards", CollectionTypeWrapper{Type: "Deck", Inner: &CollectionTypeDeck{Format: "Modern"}}, 20, games.CompletenessSuspect},
+		{"set", CollectionTypeWrapper{Type: "Set", Inner: &CollectionTypeSet{Name: "Alpha"}}, 20, ""},
+	}
+	for _, tt := range tests {
+		c := Collection{
+			ID:          "1",
+			URL:         "https://example.com/1",
+			Type:        tt.typ,
+			ReleaseDate: time.Now(),
+			Partitions:  []Partition{{Name: "Main", Cards: singletons(tt.main)}},
+		}
+		if err := c.Canonicalize(); err != nil {
+			t.Fatalf("%s: Canonicalize() error = %v", tt.name, err)
+		}
+		if c.Completeness != tt.want {
+			t.Errorf("%s: Completeness = %q, want %q", tt.name, c.Completeness, tt.want)
+		}
+	}
+}
+
+func TestTranslateCardNames(t *testing.T) {
+	col := Collection{
+		ID:          "1",
+		URL:         "https://example.com/deck/1",
+		Type:        CollectionTypeWrapper{Type: "Deck", Inner: &CollectionTypeDeck{Format: "Modern"}},
+		ReleaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Locale:      "ja-JP",
+		Partitions: []Partition{{Name: "Main", Cards: []CardDesc{
+			{Name: "稲妻", Count: 2},
+			{Name: "Lightning Bolt", Count: 2},
+			{Name: "山", Count: 20},
+		}}},
+	}
+	if err := col.Canonicalize(); err != nil {
+		t.Fatal(err)
+	}
+	if col.Locale != "ja" {
+		t.Errorf("Locale after Canonicalize = %q, want ja", col.Locale)
+	}
+
+	names := LocalizedNamesOf([]Card{
+		{Name: "Lightning Bolt", PrintedNames: map[string]string{"ja": "稲妻", "de": "Blitzschlag"}},
+		{Name: "Mountain", PrintedNames: map[string]string{"ja": "山"}},
+	})
+	if n := col.TranslateCardNames(names); n != 2 {
+		t.Errorf("TranslateCardNames() renamed %d cards, want 2", n)
+	}
+	if err := col.Canonicalize(); err != nil {
+		t.Fatalf("Canonicalize() after translation error = %v", err)
+	}
+	want := []CardDesc{{Name: "Lightning Bolt", Count: 4}, {Name: "Mountain", Count: 20}}
+	if got := col.Partitions[0].Cards; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("cards = %+v, want %+v", got, want)
+	}
+	if col.Locale != "ja" {
+		t.Errorf("Locale after translation = %q, want the source locale ja", col.Locale)
+	}
+}
+
+func TestCanonicalizeResolvesCardAliases(t *testing.T) {
+	deck := func(cards ...CardDesc) Collection {
+		return Collection{
+			ID:          "1",
+			URL:         "https://example.com/deck/1",
+			Type:        CollectionTypeWrapper{Type: "Deck", Inner: &CollectionTypeDeck{Format: "Vintage"}},
+			ReleaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Partitions:  []Partition{{Name: "Main", Cards: cards}},
+		}
+	}
+	// The same deck as two sources spell it; the second also lists one
+	// card under both spellings
+	a := deck(CardDesc{Name: "Lim-Dûl's Vault", Count: 1}, CardDesc{Name: "Juzám Djinn", Count: 4}, CardDesc{Name: "Aether Vial", Count: 4})
+	b := deck(CardDesc{Name: "Lim-Dul’s Vault", Count: 1}, CardDesc{Name: "Juzam Djinn", Count: 2}, CardDesc{Name: "Juzám Djinn", Count: 2}, CardDesc{Name: "Æther Vial", Count: 4})
+	for _, c := range []*Collection{&a, &b} {
+		if err := c.Canonicalize(); err != nil {
+			t.Fatalf("Canonicalize() error = %v", err)
+		}
+	}
+	if got, want := fmt.Sprint(b.Partitions), fmt.Sprint(a.Partitions); got != want {
+		t.Errorf("aliased deck canonicalized to %s, want %s", got, want)
+	}
+}
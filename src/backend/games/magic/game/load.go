@@ -0,0 +1,33 @@
+package game
+
+import (
+	"encoding/json"
+	"os"
+
+	"collections/cio"
+)
+
+// LoadCollectionFile reads a stored collection, compressed or not
+func LoadCollectionFile(path string) (*Collection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return LoadCollectionBytes(data)
+}
+
+// LoadCollectionBytes decodes a collection from JSON, decompressing it
+// first when it starts with the zstd or gzip magic number (see
+// cio.Decompress). The file extension is not consulted, so misnamed files
+// still load.
+func LoadCollectionBytes(data []byte) (*Collection, error) {
+	data, err := cio.Decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	var col Collection
+	if err := json.Unmarshal(data, &col); err != nil {
+		return nil, err
+	}
+	return &col, nil
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/DataDog/zstd"
+)
+
+func TestLoadCollectionSniffsCompression(t *testing.T) {
+	raw := []byte(`{"id":"1","url":"https://example.com/1","type":{"type":"Deck","inner":{"name":"Burn","format":"Modern"}},"partitions":[{"name":"Main","cards":[{"name":"Lightning Bolt","count":4}]}]}`)
+	compressed, err := zstd.Compress(nil, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	if _, err := zw.Write(raw); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want, err := LoadCollectionBytes(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	// Names deliberately disagree with content for two of the files
+	files := map[string][]byte{
+		"plain.json":          raw,
+		"compressed.json.zst": compressed,
+		"misnamed.json":       compressed,
+		"misnamed.json.zst":   raw,
+		"archive.json.gz":     gz.Bytes(),
+		"archive.json":        gz.Bytes(),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		col, err := LoadCollectionFile(path)
+		if err != nil {
+			t.Errorf("LoadCollectionFile(%s) error = %v", name, err)
+			continue
+		}
+		deck, ok := col.Type.Inner.(*CollectionTypeDeck)
+		if col.ID != "1" || !ok || deck.Name != "Burn" || col.Partitions[0].Cards[0].Count != 4 {
+			t.Errorf("LoadCollectionFile(%s) = %+v, want the Burn deck", name, col)
+		}
+		if !reflect.DeepEqual(col, want) {
+			t.Errorf("LoadCollectionFile(%s) = %+v, want %+v", name, col, want)
+		}
+	}
+
+	if _, err := LoadCollectionBytes(compressed[:8]); err == nil {
+		t.Error("LoadCollectionBytes(truncated zstd) error = nil")
+	}
+	if _, err := LoadCollectionBytes(gz.Bytes()[:8]); err == nil {
+		t.Error("LoadCollectionBytes(truncated gzip) error = nil")
+	}
+	if _, err := LoadCollectionFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("LoadCollectionFile(missing) error = nil")
+	}
+}
@@ -0,0 +1,146 @@
+package games
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// MetadataField is a piece of tournament metadata scored by MetadataScore.
+type MetadataField struct {
+	Name   string  // Display name
+	Key    string  // JSON key on the game-specific collection type
+	Weight float64 // Importance; weights sum to 1
+}
+
+// MetadataFields are the tournament metadata fields expected on a deck,
+// weighted by how much downstream analysis depends on them.
+var MetadataFields = []MetadataField{
+	{Name: "format", Key: "format", Weight: 0.25},
+	{Name: "archetype", Key: "archetype", Weight: 0.20},
+	{Name: "placement", Key: "placement", Weight: 0.20},
+	{Name: "event", Key: "event", Weight: 0.15},
+	{Name: "player", Key: "player", Weight: 0.10},
+	{Name: "date", Key: "eventDate", Weight: 0.10},
+}
+
+// MetadataPresent reports which MetadataFields are set on the collection.
+// Deck types are read through their DeckMetadata, so a placement counts as
+// present whenever it is non-nil. Other types are read generically through
+// their JSON form, where empty strings and missing or null fields count as
+// absent.
+func MetadataPresent(c *Collection) map[string]bool {
+	present := make(map[string]bool, len(MetadataFields))
+	if c == nil || c.Type.Inner == nil {
+		return present
+	}
+	if meta, ok := DeckMetadataOf(c.Type.Inner); ok {
+		present["format"] = meta.Format != ""
+		present["archetype"] = meta.Archetype != ""
+		present["placement"] = meta.Placement != nil
+		present["event"] = meta.Event != ""
+		present["player"] = meta.Player != ""
+		present["date"] = meta.EventDate != ""
+		return present
+	}
+	b, err := json.Marshal(c.Type.Inner)
+	if err != nil {
+		return present
+	}
+	var meta map[string]any
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return present
+	}
+	for _, f := range MetadataFields {
+		switch v := meta[f.Key].(type) {
+		case string:
+			present[f.Name] = v != ""
+		case float64:
+			// Numbers are only written when set, so 0 is a real value
+			present[f.Name] = true
+		}
+	}
+	return present
+}
+
+// MetadataScore returns the weighted fraction of MetadataFields present on
+// the collection, from 0 (bare) to 1 (fully populated).
+func MetadataScore(c *Collection) float64 {
+	present := MetadataPresent(c)
+	score := 0.0
+	for _, f := range MetadataFields {
+		if present[f.Name] {
+			score += f.Weight
+		}
+	}
+	return score
+}
+
+// MetadataReport aggregates metadata scores per source.
+type MetadataReport struct {
+	sources map[string]*SourceMetadata
+}
+
+// SourceMetadata is the metadata coverage of one source's collections.
+type SourceMetadata struct {
+	Source      string
+	Collections int
+	TotalScore  float64
+	FieldCounts map[string]int // Field name -> collections with it present
+}
+
+// MeanScore is the average MetadataScore over the source's collections.
+func (s *SourceMetadata) MeanScore() float64 {
+	if s.Collections == 0 {
+		return 0
+	}
+	return s.TotalScore / float64(s.Collections)
+}
+
+// Coverage is the fraction of the source's collections with field present.
+func (s *SourceMetadata) Coverage(field string) float64 {
+	if s.Collections == 0 {
+		return 0
+	}
+	return float64(s.FieldCounts[field]) / float64(s.Collections)
+}
+
+func NewMetadataReport() *MetadataReport {
+	return &MetadataReport{sources: make(map[string]*SourceMetadata)}
+}
+
+// Add scores a collection and counts it under its Source ("unknown" when
+// unset).
+func (r *MetadataReport) Add(c *Collection) {
+	source := string(c.Source)
+	if source == "" {
+		source = unknownSource
+	}
+	s, ok := r.sources[source]
+	if !ok {
+		s = &SourceMetadata{Source: source, FieldCounts: make(map[string]int)}
+		r.sources[source] = s
+	}
+	s.Collections++
+	s.TotalScore += MetadataScore(c)
+	for field, ok := range MetadataPresent(c) {
+		if ok {
+			s.FieldCounts[field]++
+		}
+	}
+}
+
+// Sources returns per-source coverage, worst mean score first, so the
+// sources most in need of parser work come first.
+func (r *MetadataReport) Sources() []*SourceMetadata {
+	sources := make([]*SourceMetadata, 0, len(r.sources))
+	for _, s := range r.sources {
+		sources = append(sources, s)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		if sources[i].MeanScore() != sources[j].MeanScore() {
+			return sources[i].MeanScore() < sources[j].MeanScore()
+		}
+		return sources[i].Source < sources[j].Source
+	})
+	return sources
+}
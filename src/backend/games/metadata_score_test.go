@@ -0,0 +1,87 @@
+package games
+
+import (
+	"math"
+	"testing"
+)
+
+type testDeckType struct {
+	Format    string `json:"format"`
+	Archetype string `json:"archetype,omitempty"`
+	Player    string `json:"player,omitempty"`
+	Event     string `json:"event,omitempty"`
+	Placement *int   `json:"placement,omitempty"`
+	EventDate string `json:"eventDate,omitempty"`
+}
+
+func (t *testDeckType) Type() string      { return "TestDeck" }
+func (t *testDeckType) IsCollectionType() {}
+
+// testProviderDeckType is read through DeckMetadata rather than its JSON
+type testProviderDeckType struct {
+	testDeckType
+}
+
+func (t *testProviderDeckType) DeckMetadata() DeckMetadata {
+	return DeckMetadata{Format: t.Format, Archetype: t.Archetype, Placement: t.Placement}
+}
+
+func TestMetadataPresentPlacement(t *testing.T) {
+	zero := 0
+	for name, newType := range map[string]func(placement *int) CollectionType{
+		"json": func(p *int) CollectionType { return &testDeckType{Placement: p} },
+		"provider": func(p *int) CollectionType {
+			return &testProviderDeckType{testDeckType{Placement: p}}
+		},
+	} {
+		c := &Collection{Type: CollectionTypeWrapper{Type: "TestDeck", Inner: newType(&zero)}}
+		if !MetadataPresent(c)["placement"] {
+			t.Errorf("%s: placement 0 is absent, want present", name)
+		}
+		c.Type.Inner = newType(nil)
+		if MetadataPresent(c)["placement"] {
+			t.Errorf("%s: nil placement is present, want absent", name)
+		}
+	}
+}
+
+func TestMetadataScore(t *testing.T) {
+	full := &Collection{Source: "full", Type: CollectionTypeWrapper{Type: "TestDeck", Inner: &testDeckType{
+		Format:    "Modern",
+		Archetype: "Burn",
+		Player:    "Alice",
+		Event:     "Regional Championship",
+		Placement: KnownPlacement(1),
+		EventDate: "2024-05-01",
+	}}}
+	bare := &Collection{Source: "bare", Type: CollectionTypeWrapper{Type: "TestDeck", Inner: &testDeckType{}}}
+	partial := &Collection{Source: "bare", Type: CollectionTypeWrapper{Type: "TestDeck", Inner: &testDeckType{
+		Format:    "Modern",
+		Archetype: "Burn",
+	}}}
+
+	if got := MetadataScore(full); math.Abs(got-1) > 1e-9 {
+		t.Errorf("MetadataScore(full) = %v, want 1", got)
+	}
+	if got := MetadataScore(bare); got != 0 {
+		t.Errorf("MetadataScore(bare) = %v, want 0", got)
+	}
+	if got := MetadataScore(partial); math.Abs(got-0.45) > 1e-9 {
+		t.Errorf("MetadataScore(partial) = %v, want 0.45", got)
+	}
+
+	report := NewMetadataReport()
+	for _, c := range []*Collection{full, bare, partial} {
+		report.Add(c)
+	}
+	sources := report.Sources()
+	if len(sources) != 2 || sources[0].Source != "bare" || sources[1].Source != "full" {
+		t.Fatalf("Sources() = %v, want [bare full]", sources)
+	}
+	if got := sources[0].MeanScore(); math.Abs(got-0.225) > 1e-9 {
+		t.Errorf("bare MeanScore() = %v, want 0.225", got)
+	}
+	if got := sources[0].Coverage("format"); got != 0.5 {
+		t.Errorf("bare Coverage(format) = %v, want 0.5", got)
+	}
+}
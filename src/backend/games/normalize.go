@@ -1,7 +1,9 @@
 package games
 
 import (
+	"fmt"
 	"html"
+	"sort"
 	"strings"
 	"unicode"
 
@@ -14,6 +16,8 @@ import (
 // - HTML entity decoding
 // - Unicode normalization (NFC)
 // - Multiple spaces collapsed to single space
+// - The "//" between the faces of split and double-faced cards spaced as
+// " // " ("Fire//Ice" -> "Fire // Ice")
 func NormalizeCardName(name string) string {
 	// Trim whitespace
 	name = strings.TrimSpace(name)
@@ -28,6 +32,98 @@ func NormalizeCardName(name string) string {
 	fields := strings.Fields(name)
 	name = strings.Join(fields, " ")
 
+	if strings.Contains(name, faceSeparator) {
+		name = joinFaces(strings.Split(name, faceSeparator))
+	}
+
+	return name
+}
+
+// faceSeparator separates the faces of split, adventure and double-faced
+// card names, which Scryfall renders as "Fire // Ice"
+const faceSeparator = "//"
+
+// joinFaces joins card faces with " // ", dropping empty faces
+func joinFaces(faces []string) string {
+	kept := faces[:0]
+	for _, face := range faces {
+		if face = strings.TrimSpace(face); face != "" {
+			kept = append(kept, face)
+		}
+	}
+	return strings.Join(kept, " "+faceSeparator+" ")
+}
+
+// CardNameOptions adjust NormalizeCardNameFull
+type CardNameOptions struct {
+	// FrontFace collapses split and double-faced cards to their first
+	// face, for sources that list only the front ("Delver of Secrets")
+	FrontFace bool
+}
+
+// NormalizeCardNameFull normalizes a Magic card name like
+// NormalizeCardName, and also reads a lone " / " as the face separator, as
+// deckbox and some mtgtop8 pages render split cards ("Fire / Ice"). No
+// Magic card name contains a spaced single slash, but other games' may, so
+// only Magic sources should use it.
+func NormalizeCardNameFull(name string, opts CardNameOptions) string {
+	name = NormalizeCardName(name)
+	name = joinFaces(strings.Split(strings.ReplaceAll(name, " / ", " "+faceSeparator+" "), faceSeparator))
+	if opts.FrontFace {
+		name, _, _ = strings.Cut(name, " "+faceSeparator+" ")
+	}
+	return name
+}
+
+// deckNameSeparators separate a page title from the site name appended
+// to it
+var deckNameSeparators = []string{" - ", " – ", " — ", " | ", " @ ", " :: "}
+
+// deckSiteNames are the site names, lowercased, that sources append to
+// titles and headings
+var deckSiteNames = map[string]bool{
+	"mtgtop8":             true,
+	"mtgtop8.com":         true,
+	"mtggoldfish":         true,
+	"mtggoldfish.com":     true,
+	"moxfield":            true,
+	"archidekt":           true,
+	"deckbox":             true,
+	"deckbox.org":         true,
+	"scryfall":            true,
+	"limitless":           true,
+	"limitless tcg":       true,
+	"limitlesstcg":        true,
+	"limitlesstcg.com":    true,
+	"ygoprodeck":          true,
+	"yugioh meta":         true,
+	"yu-gi-oh! meta":      true,
+	"master duel meta":    true,
+	"digimon meta":        true,
+	"one piece top decks": true,
+	"onepiecetopdecks":    true,
+	"riftbound.gg":        true,
+	"riftmana":            true,
+	"riftdecks":           true,
+	"pokestats":           true,
+	"pokemoncard.io":      true,
+}
+
+// CleanDeckName cleans a deck name scraped from a page title or heading:
+// it decodes HTML entities, collapses whitespace and strips site name
+// suffixes such as " - MTGTop8" or " | Limitless".
+func CleanDeckName(raw string) string {
+	name := NormalizeCardName(raw)
+	for stripped := true; stripped; {
+		stripped = false
+		for _, sep := range deckNameSeparators {
+			i := strings.LastIndex(name, sep)
+			if i > 0 && deckSiteNames[strings.ToLower(name[i+len(sep):])] {
+				name = strings.TrimSpace(name[:i])
+				stripped = true
+			}
+		}
+	}
 	return name
 }
 
@@ -69,6 +165,81 @@ func NormalizeFormatName(format string) string {
 	return strings.Join(words, " ")
 }
 
+// FormatAbbreviations maps the three-letter format abbreviations deck
+// sites such as deckbox show to format names
+var FormatAbbreviations = map[string]string{
+	"com": "Commander",
+	"mod": "Modern",
+	"sta": "Standard",
+	"leg": "Legacy",
+	"vin": "Vintage",
+	"pio": "Pioneer",
+	"pau": "Pauper",
+}
+
+// ExpandFormatAbbreviation returns the format a FormatAbbreviations entry
+// stands for (any case), or format unchanged
+func ExpandFormatAbbreviation(format string) string {
+	if name, ok := FormatAbbreviations[strings.ToLower(strings.TrimSpace(format))]; ok {
+		return name
+	}
+	return format
+}
+
+// FormatFilter selects decks by format. Formats match case-insensitively
+// after ExpandFormatAbbreviation, so "mod" selects "Modern" decks. A nil
+// filter matches every deck; otherwise decks without a format never match.
+type FormatFilter map[string]bool
+
+// NewFormatFilter returns a filter for formats, or nil when there are none
+func NewFormatFilter(formats []string) FormatFilter {
+	var f FormatFilter
+	for _, format := range formats {
+		if key := formatFilterKey(format); key != "" {
+			if f == nil {
+				f = make(FormatFilter)
+			}
+			f[key] = true
+		}
+	}
+	return f
+}
+
+// Match reports whether a deck of format passes the filter
+func (f FormatFilter) Match(format string) bool {
+	if f == nil {
+		return true
+	}
+	return f[formatFilterKey(format)]
+}
+
+// Set adds a format, so a *FormatFilter can collect a repeated --format
+// flag (flag.Value)
+func (f *FormatFilter) Set(format string) error {
+	key := formatFilterKey(format)
+	if key == "" {
+		return fmt.Errorf("empty format")
+	}
+	if *f == nil {
+		*f = make(FormatFilter)
+	}
+	(*f)[key] = true
+	return nil
+}
+
+func (f FormatFilter) String() string {
+	formats := make([]string, 0, len(f))
+	for format := range f {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return strings.Join(formats, ",")
+}
+
+func formatFilterKey(format string) string {
+	return strings.ToLower(strings.TrimSpace(ExpandFormatAbbreviation(format)))
+}
+
 // IsValidCardName checks if a card name is valid after normalization
 func IsValidCardName(name string) bool {
 	normalized := NormalizeCardName(name)
@@ -47,6 +47,64 @@ func TestNormalizeCardName(t *testing.T) {
 	}
 }
 
+func TestNormalizeCardNameFaces(t *testing.T) {
+	tests := []struct {
+		source string
+		input  string
+		want   string
+		front  string
+	}{
+		{"scryfall", "Fire // Ice", "Fire // Ice", "Fire"},
+		{"mtggoldfish", "Fire//Ice", "Fire // Ice", "Fire"},
+		{"mtgtop8", "Fire / Ice", "Fire // Ice", "Fire"},
+		{"deckbox", "Wear  /  Tear", "Wear // Tear", "Wear"},
+		{"moxfield", "Delver of Secrets // Insectile Aberration", "Delver of Secrets // Insectile Aberration", "Delver of Secrets"},
+		{"archidekt", "Delver of Secrets", "Delver of Secrets", "Delver of Secrets"},
+		{"mtgtop8", "Bonecrusher Giant //Stomp", "Bonecrusher Giant // Stomp", "Bonecrusher Giant"},
+		{"scryfall", "Who // What // When // Where // Why", "Who // What // When // Where // Why", "Who"},
+		{"html", "Fire &#47;&#47; Ice", "Fire // Ice", "Fire"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeCardNameFull(tt.input, CardNameOptions{}); got != tt.want {
+			t.Errorf("%s: NormalizeCardNameFull(%q) = %q, want %q", tt.source, tt.input, got, tt.want)
+		}
+		if got := NormalizeCardNameFull(tt.input, CardNameOptions{FrontFace: true}); got != tt.front {
+			t.Errorf("%s: NormalizeCardNameFull(%q, FrontFace) = %q, want %q", tt.source, tt.input, got, tt.front)
+		}
+	}
+
+	// NormalizeCardName spaces "//" but leaves lone slashes, which other
+	// games' names may contain
+	for input, want := range map[string]string{"Fire//Ice": "Fire // Ice", "Fire / Ice": "Fire / Ice", "D/D/D Wave King Caesar": "D/D/D Wave King Caesar"} {
+		if got := NormalizeCardName(input); got != want {
+			t.Errorf("NormalizeCardName(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestCleanDeckName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Boros Energy - MTGTop8", "Boros Energy"},
+		{"Boros Energy - Modern Challenge 64 @ mtgtop8.com", "Boros Energy - Modern Challenge 64"},
+		{"Gardevoir ex | Limitless", "Gardevoir ex"},
+		{"Charizard ex – Limitless TCG", "Charizard ex"},
+		{"Izzet Prowess by Dan | Moxfield", "Izzet Prowess by Dan"},
+		{"\n   Dimir   Murktide\n  ", "Dimir Murktide"},
+		{"Rakdos &amp; Friends - MTGGoldfish", "Rakdos & Friends"},
+		{"Snake-Eye Fire King | Yu-Gi-Oh! Meta", "Snake-Eye Fire King"},
+		{"Red Zoro - Alpha", "Red Zoro - Alpha"},
+		{"Limitless", "Limitless"},
+	}
+	for _, tt := range tests {
+		if got := CleanDeckName(tt.input); got != tt.expected {
+			t.Errorf("CleanDeckName(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
 func TestNormalizeFormatName(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -127,3 +185,41 @@ func TestIsValidCardName(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatFilter(t *testing.T) {
+	f := NewFormatFilter([]string{"Modern", " mod ", "PAU"})
+	tests := map[string]bool{
+		"Modern":    true,
+		"modern":    true,
+		"MOD":       true,
+		"Pauper":    true,
+		"Commander": false,
+		"":          false,
+	}
+	for format, want := range tests {
+		if got := f.Match(format); got != want {
+			t.Errorf("Match(%q) = %v, want %v", format, got, want)
+		}
+	}
+
+	var flagged FormatFilter
+	for _, format := range []string{"com", "Legacy"} {
+		if err := flagged.Set(format); err != nil {
+			t.Fatalf("Set(%q) error = %v", format, err)
+		}
+	}
+	if got := flagged.String(); got != "commander,legacy" {
+		t.Errorf("String() = %q, want commander,legacy", got)
+	}
+	if err := flagged.Set(""); err == nil {
+		t.Error("Set(\"\") error = nil, want error")
+	}
+
+	var none FormatFilter
+	if NewFormatFilter(nil) != nil || NewFormatFilter([]string{" "}) != nil {
+		t.Error("NewFormatFilter() without formats is not nil")
+	}
+	if !none.Match("") || !none.Match("Commander") {
+		t.Error("nil filter does not match every format")
+	}
+}
@@ -217,6 +217,9 @@ func (d *Dataset) parseDeck(
 		}
 		if exists {
 			d.log.Field("deck_id", deckID).Debugf(ctx, "Deck already exists")
+			if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+				stats.RecordSkipped()
+			}
 			return nil
 		}
 	}
@@ -238,7 +241,7 @@ func (d *Dataset) parseDeck(
 
 	// Extract deck metadata
 	deckName := doc.Find("h1").First().Text()
-	deckName = strings.TrimSpace(deckName)
+	deckName = games.CleanDeckName(deckName)
 
 	// Extract tournament info
 	tournamentName := ""
@@ -300,7 +303,7 @@ func (d *Dataset) parseDeck(
 		Archetype: archetype,
 		Player:    playerName,
 		Event:     tournamentName,
-		Placement: placement,
+		Placement: games.KnownPlacement(placement),
 		EventDate: eventDateStr,
 	}
 
@@ -57,7 +57,7 @@ type apiStanding struct {
 	Player   string         `json:"player"`
 	Name     string         `json:"name"`
 	Country  string         `json:"country"`
-	Placing  int            `json:"placing"`
+	Placing  *int           `json:"placing"`
 	Record   apiRecord      `json:"record"`
 	Decklist map[string]int `json:"decklist"` // card name -> count
 	Deck     *apiDeckType   `json:"deck"`     // optional
@@ -242,7 +242,7 @@ func (d *Dataset) storeDecklist(
 
 	// Build collection metadata
 	deckType := &game.CollectionTypeDeck{
-		Name:      fmt.Sprintf("%s - %s", tournament.Name, standing.Name),
+		Name:      games.CleanDeckName(fmt.Sprintf("%s - %s", tournament.Name, standing.Name)),
 		Format:    tournament.Format,
 		Archetype: archetype,
 		Player:    standing.Name,
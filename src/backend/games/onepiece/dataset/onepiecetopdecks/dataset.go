@@ -0,0 +1,210 @@
+package onepiecetopdecks
+
+import (
+	"collections/blob"
+	"collections/games"
+	"collections/games/decksite"
+	"collections/games/onepiece/game"
+	"collections/logger"
+	"collections/scraper"
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Dataset scrapes One Piece tournament decks from One Piece Top Decks
+// No API key required - scrapes https://onepiecetopdecks.com/deck-list/
+type Dataset struct {
+	log  *logger.Logger
+	blob *blob.Bucket
+}
+
+var base *url.URL
+
+func init() {
+	u, err := url.Parse("https://onepiecetopdecks.com/")
+	if err != nil {
+		panic(err)
+	}
+	base = u
+}
+
+func NewDataset(log *logger.Logger, blob *blob.Bucket) *Dataset {
+	return &Dataset{
+		log:  log,
+		blob: blob,
+	}
+}
+
+func (d *Dataset) Description() games.Description {
+	return games.Description{
+		Game: "onepiece",
+		Name: "onepiecetopdecks",
+	}
+}
+
+// reDeckPath matches a deck page's path; the slug is the deck ID
+var reDeckPath = regexp.MustCompile(`^/deck/([a-z0-9-]+)/?$`)
+
+// site lists decks as WordPress posts linking /deck/<slug>/ pages, with
+// the listing paginated under /deck-list/page/N/
+func site() *decksite.Site {
+	return &decksite.Site{
+		Name:      "onepiecetopdecks",
+		Listing:   base.JoinPath("deck-list/"),
+		DeckLinks: "article .entry-title a",
+		DeckPath:  reDeckPath,
+		NextPage:  ".pagination a.next",
+		Key:       collectionKey,
+		Parse: func(doc *goquery.Document, deckID, deckURL string, now time.Time) (any, error) {
+			return buildCollection(parseDeckPage(doc), deckID, deckURL, now)
+		},
+	}
+}
+
+func (d *Dataset) Extract(
+	ctx context.Context,
+	sc *scraper.Scraper,
+	options ...games.UpdateOption,
+) error {
+	opts, err := games.ResolveUpdateOptions(options...)
+	if err != nil {
+		return err
+	}
+
+	d.log.Infof(ctx, "Extracting One Piece tournament decks from One Piece Top Decks...")
+
+	n, err := site().Extract(ctx, d.log, d.blob, sc, &opts)
+	if err != nil {
+		return err
+	}
+
+	d.log.Infof(ctx, "✅ Extracted %d One Piece tournament decks from One Piece Top Decks", n)
+	return nil
+}
+
+// deckPage is what a One Piece Top Decks deck page lists
+type deckPage struct {
+	Name string
+	decksite.Meta
+	Leader     string
+	Partitions []game.Partition
+}
+
+// reCardLine matches a card entry such as "4x Nami" or "4 Nami"
+var reCardLine = regexp.MustCompile(`^(\d+)\s*x?\s+(.+)$`)
+
+// parseCards reads the card entries under sel, merging entries that
+// normalize to the same name
+func parseCards(sel *goquery.Selection) []game.CardDesc {
+	cards := []game.CardDesc{}
+	index := make(map[string]int)
+	sel.Each(func(i int, s *goquery.Selection) {
+		m := reCardLine.FindStringSubmatch(strings.Join(strings.Fields(s.Text()), " "))
+		if m == nil {
+			return
+		}
+		count, err := strconv.Atoi(m[1])
+		if err != nil || count < 1 {
+			return
+		}
+		// Normalize card name for consistency
+		name := games.NormalizeCardName(m[2])
+		if name == "" {
+			return // Skip empty card names
+		}
+		if j, ok := index[name]; ok {
+			cards[j].Count += count
+			return
+		}
+		index[name] = len(cards)
+		cards = append(cards, game.CardDesc{Name: name, Count: count})
+	})
+	return cards
+}
+
+// parseDeckPage reads a deck page's details table and decklist. The
+// Leader sits in its own section ahead of the 50-card main deck.
+func parseDeckPage(doc *goquery.Document) deckPage {
+	page := deckPage{
+		Name: strings.TrimSpace(doc.Find("h1.entry-title").First().Text()),
+	}
+
+	doc.Find(".entry-content .deck-details tr").Each(func(i int, s *goquery.Selection) {
+		page.Set(s.Find("th").First().Text(), s.Find("td").First().Text())
+	})
+
+	leader := parseCards(doc.Find(".decklist .leader .card-line"))
+	if len(leader) > 0 {
+		page.Leader = leader[0].Name
+		page.Partitions = append(page.Partitions, game.Partition{Name: game.PartitionLeader, Cards: leader})
+	}
+	if main := parseCards(doc.Find(".decklist .main .card-line")); len(main) > 0 {
+		page.Partitions = append(page.Partitions, game.Partition{Name: game.PartitionMain, Cards: main})
+	}
+	return page
+}
+
+// buildCollection turns a parsed deck page into a canonical collection,
+// dated now when the page has no event date
+func buildCollection(page deckPage, deckID, deckURL string, now time.Time) (*game.Collection, error) {
+	if len(page.Partitions) == 0 {
+		return nil, fmt.Errorf("no cards found in deck")
+	}
+
+	format := page.Format
+	if format == "" {
+		format = "Standard"
+	}
+
+	deckType := &game.CollectionTypeDeck{
+		Name:      games.CleanDeckName(page.Name),
+		Format:    format,
+		Archetype: page.Name,
+		Player:    page.Player,
+		Leader:    page.Leader,
+		Event:     page.Event,
+		Placement: games.KnownPlacement(page.Placement),
+		EventDate: page.EventDate,
+	}
+
+	tw := game.CollectionTypeWrapper{
+		Type:  deckType.Type(),
+		Inner: deckType,
+	}
+
+	collection := &game.Collection{
+		Type:        tw,
+		ID:          deckID,
+		URL:         deckURL,
+		ReleaseDate: games.ParseDateWithFallback(page.EventDate, now),
+		Partitions:  page.Partitions,
+		Source:      "onepiecetopdecks",
+	}
+
+	if err := collection.Canonicalize(); err != nil {
+		return nil, fmt.Errorf("collection is invalid: %w", err)
+	}
+	return collection, nil
+}
+
+var prefix = filepath.Join("onepiece", "onepiecetopdecks")
+
+func collectionKey(collectionID string) string {
+	return filepath.Join(prefix, collectionID+".json")
+}
+
+func (d *Dataset) IterItems(
+	ctx context.Context,
+	fn func(item games.Item) error,
+	options ...games.IterItemsOption,
+) error {
+	return games.IterItemsBlobPrefix(ctx, d.blob, prefix, games.DeserializeAsCollection, fn, options...)
+}
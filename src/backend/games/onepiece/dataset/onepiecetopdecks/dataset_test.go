@@ -0,0 +1,111 @@
+package onepiecetopdecks
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"collections/games"
+	"collections/games/onepiece/game"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func loadFixture(t *testing.T, name string) *goquery.Document {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestParseListingPage(t *testing.T) {
+	pageURL := base.JoinPath("deck-list/")
+	urls, next := site().ParseListingPage(loadFixture(t, "listing.html"), pageURL, map[string]bool{})
+
+	// The sidebar's recent decks are not part of this page's listing
+	want := []string{
+		"https://onepiecetopdecks.com/deck/op07-red-zoro-1042/",
+		"https://onepiecetopdecks.com/deck/op07-blue-doffy-1043/",
+	}
+	if len(urls) != len(want) {
+		t.Fatalf("ParseListingPage() = %v, want %v", urls, want)
+	}
+	for i := range want {
+		if urls[i] != want[i] {
+			t.Errorf("ParseListingPage()[%d] = %q, want %q", i, urls[i], want[i])
+		}
+	}
+	if want := "https://onepiecetopdecks.com/deck-list/page/2/"; next != want {
+		t.Errorf("ParseListingPage() next = %q, want %q", next, want)
+	}
+}
+
+func TestParseDeckPage(t *testing.T) {
+	page := parseDeckPage(loadFixture(t, "deck.html"))
+
+	if page.Name != "Red Zoro" || page.Player != "Alice" || page.Event != "Regional Championship" ||
+		page.Placement != 1 || page.EventDate != "2024-06-15" || page.Format != "OP07" || page.Leader != "Roronoa Zoro" {
+		t.Errorf("parseDeckPage() metadata = %+v", page)
+	}
+	if len(page.Partitions) != 2 {
+		t.Fatalf("parseDeckPage() partitions = %+v, want Leader and Main", page.Partitions)
+	}
+
+	leader, main := page.Partitions[0], page.Partitions[1]
+	if leader.Name != game.PartitionLeader || len(leader.Cards) != 1 || leader.Cards[0] != (game.CardDesc{Name: "Roronoa Zoro", Count: 1}) {
+		t.Errorf("leader = %+v", leader)
+	}
+	if main.Name != game.PartitionMain {
+		t.Errorf("main partition name = %q, want %q", main.Name, game.PartitionMain)
+	}
+	total := 0
+	for _, c := range main.Cards {
+		total += c.Count
+		if c.Name == "Nami" && c.Count != 6 {
+			t.Errorf("Nami count = %d, want duplicate entries merged to 6", c.Count)
+		}
+		if c.Name == "Monkey.D.Luffy" && c.Count != 4 {
+			t.Errorf("Monkey.D.Luffy count = %d, want 4", c.Count)
+		}
+	}
+	if len(main.Cards) != 12 || total != 50 {
+		t.Errorf("main deck has %d cards totalling %d, want 12 totalling 50", len(main.Cards), total)
+	}
+}
+
+func TestBuildCollection(t *testing.T) {
+	page := parseDeckPage(loadFixture(t, "deck.html"))
+	url := "https://onepiecetopdecks.com/deck/op07-red-zoro-1042/"
+	col, err := buildCollection(page, "op07-red-zoro-1042", url, time.Now())
+	if err != nil {
+		t.Fatalf("buildCollection() error = %v", err)
+	}
+
+	deck, ok := col.Type.Inner.(*game.CollectionTypeDeck)
+	if !ok {
+		t.Fatalf("collection type = %T, want *CollectionTypeDeck", col.Type.Inner)
+	}
+	if deck.Leader != "Roronoa Zoro" || deck.Format != "OP07" || deck.Placement == nil || *deck.Placement != 1 {
+		t.Errorf("deck type = %+v", deck)
+	}
+	if want := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC); !col.ReleaseDate.Equal(want) {
+		t.Errorf("ReleaseDate = %v, want %v", col.ReleaseDate, want)
+	}
+	if col.Completeness != games.CompletenessFull {
+		t.Errorf("Completeness = %q, want %q", col.Completeness, games.CompletenessFull)
+	}
+	if col.ParserVersion != games.ParserVersion() || col.ParserVersion == "" {
+		t.Errorf("ParserVersion = %q, want %q", col.ParserVersion, games.ParserVersion())
+	}
+
+	if _, err := buildCollection(deckPage{}, "empty", url, time.Now()); err == nil {
+		t.Error("buildCollection() of a page without cards succeeded, want error")
+	}
+}
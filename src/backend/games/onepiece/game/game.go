@@ -74,7 +74,7 @@ type CollectionTypeDeck struct {
 	Leader    string `json:"leader,omitempty"` // Leader card name
 	// Tournament metadata (from Limitless TCG API)
 	Event     string `json:"event,omitempty"`
-	Placement int    `json:"placement,omitempty"`
+	Placement *int   `json:"placement,omitempty"` // nil when unknown
 	EventDate string `json:"eventDate,omitempty"`
 }
 
@@ -93,7 +93,22 @@ func (ct *CollectionTypeSet) Type() string    { return "OnePieceSet" }
 func (ct *CollectionTypeDeck) IsCollectionType()   {}
 func (ct *CollectionTypeSet) IsCollectionType()    {}
 
-// Standard partition names for One Piece
+func (ct *CollectionTypeDeck) DeckMetadata() games.DeckMetadata {
+	return games.DeckMetadata{
+		Name:      ct.Name,
+		Archetype: ct.Archetype,
+		Format:    ct.Format,
+		Player:    ct.Player,
+		Event:     ct.Event,
+		EventDate: ct.EventDate,
+		Placement: ct.Placement,
+	}
+}
+
+// Standard partition names for One Piece. Sources that list the Leader
+// apart from the 50-card deck use Leader and Main; the rest use Deck.
 const (
-	PartitionDeck = "Deck"
+	PartitionDeck   = "Deck"
+	PartitionLeader = "Leader"
+	PartitionMain   = "Main"
 )
@@ -0,0 +1,79 @@
+package games
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// "1", "1st", "#1", "3-4", "5th-8th", "1st Place"
+	rePlacementRank = regexp.MustCompile(`^#?\s*(\d+)(?:st|nd|rd|th)?(?:\s*-\s*\d+(?:st|nd|rd|th)?)?(?:\s+place)?$`)
+	// "Top 8", "Top-16"
+	rePlacementTop = regexp.MustCompile(`^top\s*-?\s*(\d+)$`)
+)
+
+// placementTitles maps finishes that sources name rather than number to
+// their position
+var placementTitles = map[string]int{
+	"winner":           1,
+	"champion":         1,
+	"first place":      1,
+	"finalist":         2,
+	"runner-up":        2,
+	"runner up":        2,
+	"second place":     2,
+	"semifinalist":     4,
+	"semi-finalist":    4,
+	"quarterfinalist":  8,
+	"quarter-finalist": 8,
+}
+
+// ParsePlacement parses a placement as sources write it into a finishing
+// position: "1st" and "Winner" are 1, "Finalist" is 2, "Top 16" is 16 and
+// a shared finish such as "3-4" is its best position, 3. Reports false
+// when placement isn't one of these forms.
+func ParsePlacement(placement string) (int, bool) {
+	p := strings.ToLower(strings.Join(strings.Fields(placement), " "))
+	if n, ok := placementTitles[p]; ok {
+		return n, true
+	}
+	m := rePlacementRank.FindStringSubmatch(p)
+	if m == nil {
+		m = rePlacementTop.FindStringSubmatch(p)
+	}
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// UnmarshalPlacement decodes a stored placement: a number, or a string
+// from before placements were ints, parsed with ParsePlacement. Decks
+// stored before then hold text placements such as "Top 8" or "1st", so
+// the deck types' UnmarshalJSON methods decode Placement with this. Returns
+// nil when the placement is missing or unknown.
+func UnmarshalPlacement(data json.RawMessage) (*int, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		n, _ := ParsePlacement(s)
+		return KnownPlacement(n), nil
+	}
+	var n int
+	if err := json.Unmarshal(data, &n); err != nil {
+		return nil, fmt.Errorf("invalid placement %s: %w", data, err)
+	}
+	return KnownPlacement(n), nil
+}
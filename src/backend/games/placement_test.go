@@ -0,0 +1,69 @@
+package games
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParsePlacement(t *testing.T) {
+	tests := []struct {
+		placement string
+		want      int
+		ok        bool
+	}{
+		{"1", 1, true},
+		{"1st", 1, true},
+		{"2nd", 2, true},
+		{"3rd", 3, true},
+		{"9th", 9, true},
+		{"#5", 5, true},
+		{"1st Place", 1, true},
+		{"3-4", 3, true},
+		{"5th-8th", 5, true},
+		{"Top 8", 8, true},
+		{"top 16", 16, true},
+		{"Top-32", 32, true},
+		{"Winner", 1, true},
+		{"Champion", 1, true},
+		{"Finalist", 2, true},
+		{"Runner-up", 2, true},
+		{"Semifinalist", 4, true},
+		{"  Top   4 ", 4, true},
+		{"", 0, false},
+		{"0", 0, false},
+		{"Participant", 0, false},
+		{"5-2-1", 0, false},
+		{"Top", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParsePlacement(tt.placement)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParsePlacement(%q) = %d, %v, want %d, %v", tt.placement, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestUnmarshalPlacement(t *testing.T) {
+	tests := map[string]int{
+		`3`:         3,
+		`"Top 16"`:  16,
+		`"Winner"`:  1,
+		`"unknown"`: 0,
+		`0`:         0,
+		`null`:      0,
+		``:          0,
+	}
+	for data, want := range tests {
+		got, err := UnmarshalPlacement(json.RawMessage(data))
+		if err != nil {
+			t.Errorf("UnmarshalPlacement(%s) error = %v", data, err)
+			continue
+		}
+		if (want == 0) != (got == nil) || (got != nil && *got != want) {
+			t.Errorf("UnmarshalPlacement(%s) = %v, want %d", data, got, want)
+		}
+	}
+	if _, err := UnmarshalPlacement(json.RawMessage(`{}`)); err == nil {
+		t.Error("UnmarshalPlacement({}) succeeded, want error")
+	}
+}
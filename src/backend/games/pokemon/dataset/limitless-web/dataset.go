@@ -223,6 +223,9 @@ func (d *Dataset) parseDeck(
 		}
 		if exists {
 			d.log.Field("deck_id", deckID).Debugf(ctx, "Deck already exists")
+			if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+				stats.RecordSkipped()
+			}
 			return nil
 		}
 	}
@@ -238,19 +241,43 @@ func (d *Dataset) parseDeck(
 		return fmt.Errorf("failed to fetch deck page: %w", err)
 	}
 
-	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(resp.Response.Body))
+	collection, err := parseDeckPage(deckID, deckURL, resp.Response.Body)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(collection)
 	if err != nil {
 		return err
 	}
 
+	if err := d.blob.Write(ctx, bkey, b); err != nil {
+		return err
+	}
+
+	// Record success in statistics if available
+	if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+		stats.RecordSuccess()
+	}
+
+	return nil
+}
+
+// parseDeckPage parses a deck list page into a canonicalized collection
+func parseDeckPage(deckID, deckURL string, body []byte) (*game.Collection, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
 	// Extract deck name
-	deckName := strings.TrimSpace(doc.Find(".decklist-title").First().Clone().Children().Remove().End().Text())
+	deckName := games.CleanDeckName(doc.Find(".decklist-title").First().Clone().Children().Remove().End().Text())
 
 	// Extract player and tournament info from bottom section
 	playerName := ""
 	tournamentName := ""
 	placement := 0
-	eventDateStr := ""
+	var eventDateStr string
 
 	doc.Find(".decklist-results ul li").Each(func(i int, s *goquery.Selection) {
 		text := s.Text()
@@ -281,10 +308,13 @@ func (d *Dataset) parseDeck(
 		}
 	})
 
-	// Try to extract date from tournament link on listing page
-	// For now use current date as fallback
-	eventDateStr = time.Now().Format("2006-01-02")
-	// Note: Could be improved by extracting actual event date from page
+	// The event date is listed with the tournament; decks without one are
+	// dated today
+	eventDate, ok := parseEventDate(doc.Find(".decklist-results"))
+	if !ok {
+		eventDate = time.Now()
+	}
+	eventDateStr = eventDate.Format("2006-01-02")
 
 	// Parse card list from the data attributes
 	cards := []game.CardDesc{}
@@ -314,7 +344,7 @@ func (d *Dataset) parseDeck(
 	})
 
 	if len(cards) == 0 {
-		return fmt.Errorf("no cards found in deck")
+		return nil, fmt.Errorf("no cards found in deck")
 	}
 
 	// Determine archetype from deck name
@@ -327,7 +357,7 @@ func (d *Dataset) parseDeck(
 		Archetype: archetype,
 		Player:    playerName,
 		Event:     tournamentName,
-		Placement: placement,
+		Placement: games.KnownPlacement(placement),
 		EventDate: eventDateStr,
 	}
 
@@ -336,11 +366,11 @@ func (d *Dataset) parseDeck(
 		Inner: deckType,
 	}
 
-	collection := game.Collection{
+	collection := &game.Collection{
 		Type:        tw,
 		ID:          deckID,
 		URL:         deckURL,
-		ReleaseDate: games.ParseDateWithFallback(eventDateStr, time.Now()), // Use parsed date or fallback
+		ReleaseDate: eventDate,
 		Partitions: []game.Partition{{
 			Name:  "Deck",
 			Cards: cards,
@@ -349,24 +379,59 @@ func (d *Dataset) parseDeck(
 	}
 
 	if err := collection.Canonicalize(); err != nil {
-		return fmt.Errorf("collection is invalid: %w", err)
+		return nil, fmt.Errorf("collection is invalid: %w", err)
 	}
+	return collection, nil
 
-	b, err := json.Marshal(collection)
-	if err != nil {
-		return err
-	}
+}
 
-	if err := d.blob.Write(ctx, bkey, b); err != nil {
-		return err
+var reEventDate = regexp.MustCompile(`(?i)\b(?:(\d{1,2})(?:st|nd|rd|th)?\s+([a-z]{3,9})\.?,?\s+(\d{4})|([a-z]{3,9})\.?\s+(\d{1,2})(?:st|nd|rd|th)?,?\s+(\d{4})|(\d{4}-\d{2}-\d{2}))\b`)
+
+// parseEventDate finds the event date in a deck page's results section:
+// a datetime, data-date or title attribute (the tournament link's title
+// carries it), or a date in the text such as "2nd March 2024",
+// "March 2nd, 2024" or "2024-03-02"
+func parseEventDate(results *goquery.Selection) (time.Time, bool) {
+	var date time.Time
+	found := false
+	try := func(text string) bool {
+		for _, m := range reEventDate.FindAllStringSubmatch(text, -1) {
+			var candidate string
+			switch {
+			case m[7] != "":
+				candidate = m[7]
+			case m[1] != "":
+				candidate = fmt.Sprintf("%s %s, %s", shortMonth(m[2]), m[1], m[3])
+			default:
+				candidate = fmt.Sprintf("%s %s, %s", shortMonth(m[4]), m[5], m[6])
+			}
+			if t, err := games.ParseDateWithValidation(candidate); err == nil {
+				date, found = t, true
+				return true
+			}
+		}
+		return false
 	}
-
-	// Record success in statistics if available
-	if stats := games.ExtractStatsFromContext(ctx); stats != nil {
-		stats.RecordSuccess()
+	results.Find("*").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		for _, attr := range []string{"datetime", "data-date", "title"} {
+			if v, ok := s.Attr(attr); ok && try(v) {
+				return false
+			}
+		}
+		return true
+	})
+	if !found {
+		try(results.Text())
 	}
+	return date, found
+}
 
-	return nil
+// shortMonth returns the three-letter form of a month name, "Jan"
+func shortMonth(month string) string {
+	if len(month) < 3 {
+		return month
+	}
+	return strings.ToUpper(month[:1]) + strings.ToLower(month[1:3])
 }
 
 var (
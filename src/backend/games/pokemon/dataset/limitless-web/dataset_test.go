@@ -0,0 +1,53 @@
+package limitlessweb
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"collections/games/pokemon/game"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseDeckPageEventDate(t *testing.T) {
+	body, err := os.ReadFile("testdata/deck.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	col, err := parseDeckPage("12345", "https://limitlesstcg.com/decks/list/12345", body)
+	if err != nil {
+		t.Fatalf("parseDeckPage() error = %v", err)
+	}
+	want := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	if !col.ReleaseDate.Equal(want) {
+		t.Errorf("ReleaseDate = %v, want %v", col.ReleaseDate, want)
+	}
+	deck := col.Type.Inner.(*game.CollectionTypeDeck)
+	if deck.EventDate != "2024-03-02" {
+		t.Errorf("EventDate = %q, want 2024-03-02", deck.EventDate)
+	}
+	if deck.Player != "Liam Halliburton" || deck.Event != "Regional Pittsburgh, PA" {
+		t.Errorf("Player, Event = %q, %q", deck.Player, deck.Event)
+	}
+}
+
+func TestParseEventDate(t *testing.T) {
+	tests := map[string]string{
+		`<li><time datetime="2024-06-15">Sat</time></li>`:     "2024-06-15",
+		`<li>1st Place Regional (June 15th, 2024) - Ann</li>`: "2024-06-15",
+		`<li>15 Jun 2024 - 1st Place Cup - Ann</li>`:          "2024-06-15",
+		`<li>1st Place Regional Pittsburgh, PA - Ann</li>`:    "",
+	}
+	for html, want := range tests {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<div class="decklist-results"><ul>` + html + `</ul></div>`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		date, ok := parseEventDate(doc.Find(".decklist-results"))
+		if got := date.Format("2006-01-02"); ok != (want != "") || (ok && got != want) {
+			t.Errorf("parseEventDate(%s) = %s, %v, want %q", html, got, ok, want)
+		}
+	}
+}
@@ -231,7 +231,7 @@ func (d *Dataset) parseDeck(
 	}
 
 	// Name
-	name := strings.TrimSpace(doc.Find(".deck-metadata-container h1").First().Text())
+	name := games.CleanDeckName(doc.Find(".deck-metadata-container h1").First().Text())
 	if name == "" {
 		name = "PokemonCard.io Deck"
 	}
@@ -306,7 +306,8 @@ func (d *Dataset) parseDeck(
 		ID:          id,
 		URL:         deckURL,
 		Type:        tw,
-		ReleaseDate: time.Now(),
+		ReleaseDate: page.ScrapedAt, // no deck date on the page
+		ScrapedAt:   page.ScrapedAt,
 		Partitions:  []pgame.Partition{part},
 		Source:      "pokemoncard-io",
 	}
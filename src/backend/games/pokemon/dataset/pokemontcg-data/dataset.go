@@ -11,6 +11,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"collections/games/pokemon/game"
 )
@@ -70,6 +72,18 @@ type apiSet struct {
 	ReleaseDate  string `json:"releaseDate"`
 }
 
+// repoURL is a var so tests can point it at a local repo
+var repoURL = "https://github.com/PokemonTCG/pokemon-tcg-data.git"
+
+// stateKey records the last repo commit whose card files were processed,
+// so later runs only reprocess files changed since then. It is a BlobKV
+// key under the dataset's prefix.
+const stateKey = ".repo_state"
+
+type repoState struct {
+	Commit string `json:"commit"`
+}
+
 // Dataset fetches Pokemon card data from the pokemon-tcg-data GitHub repo
 // Source: https://github.com/PokemonTCG/pokemon-tcg-data
 type Dataset struct {
@@ -101,15 +115,15 @@ func (d *Dataset) Extract(
 		return err
 	}
 
-	repoURL := "https://github.com/PokemonTCG/pokemon-tcg-data.git"
-
 	// Allow overriding clone path via env for CI/local caching
 	cloneDir := os.Getenv("POKEMON_TCG_DATA_DIR")
 	if cloneDir == "" {
 		cloneDir = filepath.Join("..", "..", "..", "integration_test_tmp", "pokemon-tcg-data")
 	}
 
-	// 1. Clone or pull the repo.
+	// 1. Clone the repo if needed, then check out the requested ref
+	// (default: the remote HEAD) detached, so pinned and unpinned runs can
+	// share a clone.
 	if _, err := os.Stat(cloneDir); os.IsNotExist(err) {
 		d.log.Infof(ctx, "Cloning %s into %s...", repoURL, cloneDir)
 		cmd := exec.CommandContext(ctx, "git", "clone", "--depth=1", repoURL, cloneDir)
@@ -117,15 +131,50 @@ func (d *Dataset) Extract(
 			d.log.Errorf(ctx, "git clone output: %s", string(output))
 			return fmt.Errorf("failed to clone repo: %w", err)
 		}
-	} else {
-		d.log.Infof(ctx, "Pulling latest changes in %s...", cloneDir)
-		cmd := exec.CommandContext(ctx, "git", "-C", cloneDir, "pull")
-		if output, err := cmd.CombinedOutput(); err != nil {
-			d.log.Errorf(ctx, "git pull output: %s", string(output))
-			return fmt.Errorf("failed to pull repo: %w", err)
+	}
+	ref := opts.RepoRef
+	if ref == "" {
+		ref = "HEAD"
+	}
+	d.log.Infof(ctx, "Fetching %s in %s...", ref, cloneDir)
+	if _, err := git(ctx, cloneDir, "fetch", "--depth=1", "origin", ref); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", ref, err)
+	}
+	if _, err := git(ctx, cloneDir, "checkout", "-q", "--detach", "FETCH_HEAD"); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", ref, err)
+	}
+
+	head, err := git(ctx, cloneDir, "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to resolve repo HEAD: %w", err)
+	}
+	d.log.Infof(ctx, "Importing %s at commit %s", ref, head)
+
+	// Only reprocess card files changed since the last processed commit,
+	// unless forced. changed is nil for a full run.
+	var changed map[string]bool
+	if !opts.Reparse && !opts.FetchReplaceAll {
+		state, err := d.loadState(ctx)
+		if err != nil {
+			d.log.Warnf(ctx, "failed to load repo state, processing all card files: %v", err)
+		} else if state.Commit != "" {
+			changed, err = changedCardFiles(ctx, cloneDir, state.Commit, head)
+			if err != nil {
+				d.log.Warnf(ctx, "failed to diff %s..%s, processing all card files: %v", state.Commit, head, err)
+				changed = nil
+			} else {
+				d.log.Infof(ctx, "%d card files changed since %s", len(changed), state.Commit)
+			}
 		}
 	}
 
+	// Set metadata names and dates the set collections built from cards
+	sets := d.loadSets(ctx, cloneDir)
+	setsByID := make(map[string]apiSet, len(sets))
+	for _, set := range sets {
+		setsByID[set.ID] = set
+	}
+
 	// 2a. Find and parse the card JSON files.
 	setsDir := filepath.Join(cloneDir, "cards", "en")
 	files, err := os.ReadDir(setsDir)
@@ -134,6 +183,9 @@ func (d *Dataset) Extract(
 	}
 
 	totalCardsProcessed := 0
+	// Files and cards that failed to read or write; any failure keeps the
+	// state where it was so the next run retries them
+	failed := 0
 	// Optional global item/card limit across all sets
 	var globalLimit int
 	if limit, ok := opts.ItemLimit.Get(); ok {
@@ -143,33 +195,49 @@ func (d *Dataset) Extract(
 		if filepath.Ext(file.Name()) != ".json" {
 			continue
 		}
+		if changed != nil && !changed[file.Name()] {
+			continue
+		}
 
 		filePath := filepath.Join(setsDir, file.Name())
 		jsonData, err := os.ReadFile(filePath)
 		if err != nil {
 			d.log.Warnf(ctx, "Failed to read file %s: %v", filePath, err)
+			failed++
 			continue
 		}
 
 		var cards []apiCard
 		if err := json.Unmarshal(jsonData, &cards); err != nil {
 			d.log.Warnf(ctx, "Failed to unmarshal JSON from %s: %v", filePath, err)
+			failed++
 			continue
 		}
 
+		// Card files hold one set each, so the whole set is rebuilt from
+		// this file regardless of which cards are skipped below
+		for _, col := range setCollections(cards, setsByID, head, fileURL(head, file.Name())) {
+			if err := d.writeSetCollection(ctx, col); err != nil {
+				d.log.Warnf(ctx, "failed to write set collection %s: %v", col.ID, err)
+				failed++
+			}
+		}
+
 		for _, apiCard := range cards {
 			card := convertToCard(apiCard)
+			card.SourceCommit = head
 
 			// Store in blob: pokemon/pokemontcg-data/cards/{id}.json (relative to games/ prefix)
 			key := fmt.Sprintf("pokemon/pokemontcg-data/cards/%s.json", apiCard.ID)
 			data, err := json.Marshal(card)
 			if err != nil {
-				d.log.Warnf(ctx, "failed to marshal card %s: %w", card.Name, err)
+				d.log.Warnf(ctx, "failed to marshal card %s: %v", card.Name, err)
+				failed++
 				continue
 			}
 
-			// Skip existing unless forced
-			if !opts.Reparse && !opts.FetchReplaceAll {
+			// Skip existing unless forced or the card's file changed
+			if changed == nil && !opts.Reparse && !opts.FetchReplaceAll {
 				if exists, _ := d.blob.Exists(ctx, key); exists {
 					totalCardsProcessed++
 					if globalLimit > 0 && totalCardsProcessed >= globalLimit {
@@ -181,7 +249,8 @@ func (d *Dataset) Extract(
 			}
 
 			if err := d.blob.Write(ctx, key, data); err != nil {
-				d.log.Warnf(ctx, "failed to write card %s: %w", card.Name, err)
+				d.log.Warnf(ctx, "failed to write card %s: %v", card.Name, err)
+				failed++
 				continue
 			}
 			totalCardsProcessed++
@@ -194,76 +263,201 @@ func (d *Dataset) Extract(
 
 	d.log.Infof(ctx, "Successfully processed %d cards from %d set files.", totalCardsProcessed, len(files))
 
-	// 2b. Parse sets metadata if present
-	// Common layout in repo: sets/en.json (single file). Fallback: sets/en/*.json
+	// Only a complete pass with every write landed advances the state; the
+	// item limit returns early
+	if failed > 0 {
+		d.log.Warnf(ctx, "%d card files or writes failed, keeping repo state so they are retried", failed)
+	} else if err := d.saveState(ctx, repoState{Commit: head}); err != nil {
+		d.log.Warnf(ctx, "failed to save repo state: %v", err)
+	}
+
+	// 2b. Store set metadata
+	for _, set := range sets {
+		key := filepath.Join("pokemon", "pokemontcg-data", "sets", set.ID+".json")
+		data, err := json.Marshal(setType(set, head))
+		if err != nil {
+			d.log.Warnf(ctx, "failed to marshal set %s: %v", set.ID, err)
+			continue
+		}
+		if err := d.blob.Write(ctx, key, data); err != nil {
+			d.log.Warnf(ctx, "failed to write set %s: %v", set.ID, err)
+			continue
+		}
+	}
+	d.log.Infof(ctx, "Processed %d set metadata entries.", len(sets))
+
+	return nil
+}
+
+// loadSets reads set metadata from sets/en.json, falling back to a
+// directory of per-set files (sets/en/*.json)
+func (d *Dataset) loadSets(ctx context.Context, cloneDir string) []apiSet {
 	setsJSONPath := filepath.Join(cloneDir, "sets", "en.json")
 	if b, err := os.ReadFile(setsJSONPath); err == nil {
 		var sets []apiSet
-		if err := json.Unmarshal(b, &sets); err == nil {
-			for _, s := range sets {
-				setObj := game.CollectionTypeSet{
-					Name:         s.Name,
-					Code:         s.ID,
-					Series:       s.Series,
-					ReleaseDate:  s.ReleaseDate,
-					PrintedTotal: s.PrintedTotal,
-					Total:        s.Total,
-				}
-				key := filepath.Join("pokemon", "pokemontcg-data", "sets", s.ID+".json")
-				data, merr := json.Marshal(setObj)
-				if merr != nil {
-					d.log.Warnf(ctx, "failed to marshal set %s: %v", s.ID, merr)
-					continue
-				}
-				if err := d.blob.Write(ctx, key, data); err != nil {
-					d.log.Warnf(ctx, "failed to write set %s: %v", s.ID, err)
-					continue
-				}
-			}
-			d.log.Infof(ctx, "Processed %d set metadata entries.", len(sets))
-		} else {
+		if err := json.Unmarshal(b, &sets); err != nil {
 			d.log.Warnf(ctx, "failed to unmarshal sets from %s: %v", setsJSONPath, err)
+			return nil
 		}
-	} else {
-		// Fallback to directory of json files
-		setsDir := filepath.Join(cloneDir, "sets", "en")
-		if entries, derr := os.ReadDir(setsDir); derr == nil {
-			written := 0
-			for _, e := range entries {
-				if filepath.Ext(e.Name()) != ".json" {
-					continue
-				}
-				p := filepath.Join(setsDir, e.Name())
-				b, rerr := os.ReadFile(p)
-				if rerr != nil {
-					continue
-				}
-				var s apiSet
-				if uerr := json.Unmarshal(b, &s); uerr != nil {
-					continue
-				}
-				setObj := game.CollectionTypeSet{
-					Name:         s.Name,
-					Code:         s.ID,
-					Series:       s.Series,
-					ReleaseDate:  s.ReleaseDate,
-					PrintedTotal: s.PrintedTotal,
-					Total:        s.Total,
-				}
-				key := filepath.Join("pokemon", "pokemontcg-data", "sets", s.ID+".json")
-				data, merr := json.Marshal(setObj)
-				if merr != nil {
-					continue
-				}
-				if err := d.blob.Write(ctx, key, data); err == nil {
-					written++
-				}
-			}
-			d.log.Infof(ctx, "Processed %d set metadata entries (dir mode).", written)
+		return sets
+	}
+
+	setsDir := filepath.Join(cloneDir, "sets", "en")
+	entries, err := os.ReadDir(setsDir)
+	if err != nil {
+		return nil
+	}
+	var sets []apiSet
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(setsDir, e.Name()))
+		if err != nil {
+			continue
 		}
+		var set apiSet
+		if err := json.Unmarshal(b, &set); err != nil {
+			continue
+		}
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+func setType(set apiSet, commit string) *game.CollectionTypeSet {
+	return &game.CollectionTypeSet{
+		Name:         set.Name,
+		Code:         set.ID,
+		Series:       set.Series,
+		ReleaseDate:  set.ReleaseDate,
+		PrintedTotal: set.PrintedTotal,
+		Total:        set.Total,
+		SourceCommit: commit,
 	}
+}
 
-	return nil
+// setReleaseDate parses the repo's "2006/01/02" release dates
+func setReleaseDate(s string) (time.Time, error) {
+	if t, err := time.Parse("2006/01/02", s); err == nil {
+		return t, nil
+	}
+	return games.ParseDateWithValidation(s)
+}
+
+// setCollections groups cards by set into PokemonSet collections, one
+// entry per card name. Sets without a parseable release date in the
+// metadata are skipped, since a collection needs one.
+func setCollections(cards []apiCard, setsByID map[string]apiSet, commit, url string) []*game.Collection {
+	var order []string
+	bySet := make(map[string][]game.CardDesc)
+	seen := make(map[string]map[string]int) // set ID -> card name -> index
+	for _, c := range cards {
+		id, name := c.Set.ID, strings.TrimSpace(c.Name)
+		if id == "" || name == "" {
+			continue
+		}
+		if seen[id] == nil {
+			seen[id] = make(map[string]int)
+			order = append(order, id)
+		}
+		if i, ok := seen[id][name]; ok {
+			bySet[id][i].Count++
+			continue
+		}
+		seen[id][name] = len(bySet[id])
+		bySet[id] = append(bySet[id], game.CardDesc{Name: name, Count: 1})
+	}
+
+	var cols []*game.Collection
+	for _, id := range order {
+		meta, ok := setsByID[id]
+		if !ok {
+			continue
+		}
+		released, err := setReleaseDate(meta.ReleaseDate)
+		if err != nil {
+			continue
+		}
+		ty := setType(meta, commit)
+		cols = append(cols, &game.Collection{
+			ID:          id,
+			URL:         url,
+			Type:        game.CollectionTypeWrapper{Type: ty.Type(), Inner: ty},
+			ReleaseDate: released,
+			Partitions:  []game.Partition{{Name: "Cards", Cards: bySet[id]}},
+			Source:      "pokemontcg-data",
+		})
+	}
+	return cols
+}
+
+func (d *Dataset) writeSetCollection(ctx context.Context, col *game.Collection) error {
+	if err := col.Canonicalize(); err != nil {
+		return fmt.Errorf("collection is invalid: %w", err)
+	}
+	data, err := json.Marshal(col)
+	if err != nil {
+		return err
+	}
+	return d.blob.Write(ctx, setCollectionKey(col.ID), data)
+}
+
+// setCollectionKey is where a set's PokemonSet collection is stored,
+// alongside the raw set metadata under sets/
+func setCollectionKey(setID string) string {
+	return filepath.Join("pokemon", "pokemontcg-data", "collections", setID+".json")
+}
+
+// fileURL links a card file at the imported commit
+func fileURL(commit, name string) string {
+	return strings.TrimSuffix(repoURL, ".git") + "/blob/" + commit + "/cards/en/" + name
+}
+
+func (d *Dataset) state() *games.BlobKV {
+	return games.NewBlobKV(d.blob, filepath.Join("pokemon", "pokemontcg-data"))
+}
+
+func (d *Dataset) loadState(ctx context.Context) (repoState, error) {
+	var state repoState
+	_, err := d.state().Get(ctx, stateKey, &state)
+	return state, err
+}
+
+func (d *Dataset) saveState(ctx context.Context, state repoState) error {
+	return d.state().Set(ctx, stateKey, state)
+}
+
+// changedCardFiles returns the names of card files under cards/en added or
+// modified between two commits
+func changedCardFiles(ctx context.Context, dir, from, to string) (map[string]bool, error) {
+	changed := make(map[string]bool)
+	if from == to {
+		return changed, nil
+	}
+	out, err := git(ctx, dir, "diff", "--name-only", "--diff-filter=d", from, to, "--", "cards/en")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if line != "" {
+			changed[filepath.Base(line)] = true
+		}
+	}
+	return changed, nil
+}
+
+// git runs a git command in dir and returns its trimmed stdout
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
 }
 
 func convertToCard(apiCard apiCard) game.Card {
@@ -0,0 +1,310 @@
+package pokemontcgdata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"collections/blob"
+	"collections/games"
+	"collections/games/pokemon/game"
+	"collections/logger"
+)
+
+// testRepo is a local stand-in for the pokemon-tcg-data repo
+type testRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	r := &testRepo{t: t, dir: t.TempDir()}
+	r.git("init", "-q")
+	return r
+}
+
+func (r *testRepo) git(args ...string) string {
+	r.t.Helper()
+	args = append([]string{"-C", r.dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return string(out)
+}
+
+// writeSet writes cards/en/{setID}.json with one card per name
+func (r *testRepo) writeSet(setID string, names ...string) {
+	r.t.Helper()
+	var cards []apiCard
+	for i, name := range names {
+		c := apiCard{ID: fmt.Sprintf("%s-%d", setID, i+1), Name: name}
+		c.Set.ID = setID
+		cards = append(cards, c)
+	}
+	data, err := json.Marshal(cards)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	path := filepath.Join(r.dir, "cards", "en", setID+".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// writeSets writes sets/en.json with one entry per set ID
+func (r *testRepo) writeSets(ids ...string) {
+	r.t.Helper()
+	var sets []apiSet
+	for _, id := range ids {
+		sets = append(sets, apiSet{ID: id, Name: id, ReleaseDate: "1999/01/09"})
+	}
+	data, err := json.Marshal(sets)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(r.dir, "sets"), 0o755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(r.dir, "sets", "en.json"), data, 0o644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// commit commits everything and returns the new commit SHA
+func (r *testRepo) commit(msg string) string {
+	r.t.Helper()
+	r.git("add", "-A")
+	r.git("commit", "-q", "-m", msg)
+	return strings.TrimSpace(r.git("rev-parse", "HEAD"))
+}
+
+// useRepo points the dataset at repo with a fresh clone directory
+func useRepo(t *testing.T, repo *testRepo) {
+	repoURL = repo.dir
+	t.Cleanup(func() { repoURL = "https://github.com/PokemonTCG/pokemon-tcg-data.git" })
+	t.Setenv("POKEMON_TCG_DATA_DIR", filepath.Join(t.TempDir(), "clone"))
+}
+
+func cardKey(id string) string {
+	return fmt.Sprintf("pokemon/pokemontcg-data/cards/%s.json", id)
+}
+
+func TestExtractOnlyChangedSetFiles(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	repo := newTestRepo(t)
+	repo.writeSet("base1", "Alakazam", "Blastoise")
+	repo.writeSet("jungle", "Clefable")
+	repo.commit("initial")
+	useRepo(t, repo)
+
+	bucket := blob.NewMemBucket(ctx, log)
+	d := NewDataset(log, bucket)
+	if err := d.Extract(ctx, nil); err != nil {
+		t.Fatalf("first Extract() error = %v", err)
+	}
+	for _, id := range []string{"base1-1", "base1-2", "jungle-1"} {
+		if ok, _ := bucket.Exists(ctx, cardKey(id)); !ok {
+			t.Fatalf("card %s not written on first run", id)
+		}
+	}
+
+	// Drop every card so the second run shows exactly what it reprocessed
+	if _, err := bucket.DeletePrefix(ctx, "pokemon/pokemontcg-data/cards/"); err != nil {
+		t.Fatal(err)
+	}
+	repo.writeSet("jungle", "Clefable", "Snorlax")
+	repo.commit("add Snorlax")
+
+	if err := d.Extract(ctx, nil); err != nil {
+		t.Fatalf("second Extract() error = %v", err)
+	}
+	for id, want := range map[string]bool{"base1-1": false, "base1-2": false, "jungle-1": true, "jungle-2": true} {
+		if ok, _ := bucket.Exists(ctx, cardKey(id)); ok != want {
+			t.Errorf("card %s written = %v, want %v", id, ok, want)
+		}
+	}
+
+	state, err := d.loadState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head := strings.TrimSpace(repo.git("rev-parse", "HEAD")); state.Commit != head {
+		t.Errorf("state commit = %s, want %s", state.Commit, head)
+	}
+}
+
+func TestExtractKeepsStateOnFailure(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	repo := newTestRepo(t)
+	repo.writeSet("base1", "Alakazam")
+	repo.writeSet("jungle", "Clefable")
+	first := repo.commit("initial")
+	useRepo(t, repo)
+
+	bucket := blob.NewMemBucket(ctx, log)
+	d := NewDataset(log, bucket)
+	if err := d.Extract(ctx, nil); err != nil {
+		t.Fatalf("first Extract() error = %v", err)
+	}
+
+	// A broken set file must not be skipped for good by the next diff
+	repo.writeSet("base1", "Alakazam", "Blastoise")
+	if err := os.WriteFile(filepath.Join(repo.dir, "cards", "en", "jungle.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	repo.commit("break jungle")
+	if err := d.Extract(ctx, nil); err != nil {
+		t.Fatalf("second Extract() error = %v", err)
+	}
+	if ok, _ := bucket.Exists(ctx, cardKey("base1-2")); !ok {
+		t.Error("card base1-2 not written alongside the broken file")
+	}
+	state, err := d.loadState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Commit != first {
+		t.Errorf("state commit = %s after a failed file, want %s", state.Commit, first)
+	}
+
+	repo.writeSet("jungle", "Clefable", "Snorlax")
+	head := repo.commit("fix jungle")
+	if err := d.Extract(ctx, nil); err != nil {
+		t.Fatalf("third Extract() error = %v", err)
+	}
+	if ok, _ := bucket.Exists(ctx, cardKey("jungle-2")); !ok {
+		t.Error("card jungle-2 not written after the fix")
+	}
+	if state, err = d.loadState(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if state.Commit != head {
+		t.Errorf("state commit = %s, want %s", state.Commit, head)
+	}
+}
+
+func TestExtractPinnedRef(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	repo := newTestRepo(t)
+	repo.writeSet("base1", "Alakazam")
+	repo.writeSets("base1")
+	pinned := repo.commit("base set")
+	repo.git("tag", "v1")
+	repo.writeSet("jungle", "Clefable")
+	repo.writeSets("base1", "jungle")
+	repo.commit("jungle")
+	useRepo(t, repo)
+
+	bucket := blob.NewMemBucket(ctx, log)
+	d := NewDataset(log, bucket)
+	if err := d.Extract(ctx, nil, &games.OptExtractRepoRef{Ref: "v1"}); err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+
+	if ok, _ := bucket.Exists(ctx, cardKey("jungle-1")); ok {
+		t.Error("card from after the pinned ref was imported")
+	}
+	data, err := bucket.Read(ctx, cardKey("base1-1"))
+	if err != nil {
+		t.Fatalf("pinned card not imported: %v", err)
+	}
+	var card game.Card
+	if err := json.Unmarshal(data, &card); err != nil {
+		t.Fatal(err)
+	}
+	if card.SourceCommit != pinned {
+		t.Errorf("card source_commit = %q, want %q", card.SourceCommit, pinned)
+	}
+
+	data, err = bucket.Read(ctx, "pokemon/pokemontcg-data/sets/base1.json")
+	if err != nil {
+		t.Fatalf("set metadata not imported: %v", err)
+	}
+	var set game.CollectionTypeSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		t.Fatal(err)
+	}
+	if set.SourceCommit != pinned {
+		t.Errorf("set source_commit = %q, want %q", set.SourceCommit, pinned)
+	}
+	if ok, _ := bucket.Exists(ctx, "pokemon/pokemontcg-data/sets/jungle.json"); ok {
+		t.Error("set from after the pinned ref was imported")
+	}
+	if ok, _ := bucket.Exists(ctx, setCollectionKey("base1")); !ok {
+		t.Error("set collection for base1 not written")
+	}
+}
+
+func TestSetCollections(t *testing.T) {
+	card := func(id, setID, name string) apiCard {
+		c := apiCard{ID: id, Name: name}
+		c.Set.ID = setID
+		return c
+	}
+	cards := []apiCard{
+		card("base1-1", "base1", "Alakazam"),
+		card("jungle-1", "jungle", "Clefable"),
+		card("base1-2", "base1", "Blastoise"),
+		card("base1-3", "base1", "Alakazam"), // second printing
+		card("promo-1", "promo", "Pikachu"),  // no set metadata
+	}
+	setsByID := map[string]apiSet{
+		"base1":  {ID: "base1", Name: "Base", Series: "Base", ReleaseDate: "1999/01/09"},
+		"jungle": {ID: "jungle", Name: "Jungle", Series: "Base", ReleaseDate: "1999/06/16"},
+	}
+
+	cols := setCollections(cards, setsByID, "abc123", "https://example.com/cards/en/mixed.json")
+	if len(cols) != 2 {
+		t.Fatalf("setCollections() = %d collections, want 2", len(cols))
+	}
+	want := map[string]struct {
+		released time.Time
+		cards    []game.CardDesc
+	}{
+		"base1":  {time.Date(1999, 1, 9, 0, 0, 0, 0, time.UTC), []game.CardDesc{{Name: "Alakazam", Count: 2}, {Name: "Blastoise", Count: 1}}},
+		"jungle": {time.Date(1999, 6, 16, 0, 0, 0, 0, time.UTC), []game.CardDesc{{Name: "Clefable", Count: 1}}},
+	}
+	for _, col := range cols {
+		if err := col.Canonicalize(); err != nil {
+			t.Fatalf("Canonicalize(%s) error = %v", col.ID, err)
+		}
+		w, ok := want[col.ID]
+		if !ok {
+			t.Errorf("unexpected set collection %s", col.ID)
+			continue
+		}
+		if !col.ReleaseDate.Equal(w.released) {
+			t.Errorf("%s release date = %v, want %v", col.ID, col.ReleaseDate, w.released)
+		}
+		if got := fmt.Sprint(col.Partitions[0].Cards); got != fmt.Sprint(w.cards) {
+			t.Errorf("%s cards = %s, want %v", col.ID, got, w.cards)
+		}
+		ty, ok := col.Type.Inner.(*game.CollectionTypeSet)
+		if !ok || col.Type.Type != "PokemonSet" || ty.Code != col.ID || ty.SourceCommit != "abc123" {
+			t.Errorf("%s type = %s %+v", col.ID, col.Type.Type, col.Type.Inner)
+		}
+	}
+}
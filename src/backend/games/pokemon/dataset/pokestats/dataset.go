@@ -15,7 +15,6 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
-	"time"
 
 	"github.com/PuerkitoBio/goquery"
 )
@@ -192,7 +191,7 @@ func (d *Dataset) parsePost(
 	}
 	part := pgame.Partition{Name: pgame.PartitionDeck, Cards: cards}
 	deckType := &pgame.CollectionTypeDeck{
-		Name:      doc.Find("h1, h2, .post-title").First().Text(),
+		Name:      games.CleanDeckName(doc.Find("h1, h2, .post-title").First().Text()),
 		Format:    "Unknown",
 		Archetype: doc.Find("h1, h2, .post-title").First().Text(),
 	}
@@ -201,7 +200,8 @@ func (d *Dataset) parsePost(
 		ID:          id,
 		URL:         postURL,
 		Type:        tw,
-		ReleaseDate: time.Now(),
+		ReleaseDate: page.ScrapedAt, // no deck date on the page
+		ScrapedAt:   page.ScrapedAt,
 		Partitions:  []pgame.Partition{part},
 		Source:      "pokestats",
 	}
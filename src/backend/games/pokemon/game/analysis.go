@@ -0,0 +1,215 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"collections/cio"
+	"collections/games"
+)
+
+// Supertypes as normalized by NormalizeSupertype
+const (
+	SupertypePokemon = "Pokemon"
+	SupertypeTrainer = "Trainer"
+	SupertypeEnergy  = "Energy"
+	SupertypeUnknown = "Unknown"
+)
+
+// DeckSize is the number of cards in a legal Pokemon TCG deck
+const DeckSize = 60
+
+// NormalizeSupertype maps a card supertype ("Pokémon", "trainer", ...) to
+// one of the Supertype constants
+func NormalizeSupertype(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "pokémon", "pokemon":
+		return SupertypePokemon
+	case "trainer":
+		return SupertypeTrainer
+	case "energy":
+		return SupertypeEnergy
+	}
+	return SupertypeUnknown
+}
+
+// DeckStats is the Pokemon analog of an MTG mana curve: copies per
+// supertype and, for Pokémon, copies per attack energy cost.
+type DeckStats struct {
+	Cards      int            `json:"cards"`
+	Supertypes map[string]int `json:"supertypes"`
+	// EnergyCurve maps a Pokémon's most expensive attack
+	// (convertedEnergyCost; 0 for no attacks) to copies in the deck
+	EnergyCurve map[int]int `json:"energy_curve"`
+	// Unknown lists card names with no card data; they count as
+	// SupertypeUnknown
+	Unknown []string `json:"unknown,omitempty"`
+}
+
+// AnalyzeDeck computes deck stats using cards, keyed by card name, for
+// supertypes and attack costs. Cards missing from cards whose name ends in
+// "Energy" count as Energy, since decks list basic energy by name.
+func AnalyzeDeck(deck *Collection, cards map[string]Card) DeckStats {
+	stats := DeckStats{
+		Supertypes:  make(map[string]int),
+		EnergyCurve: make(map[int]int),
+	}
+	for _, p := range deck.Partitions {
+		for _, c := range p.Cards {
+			stats.Cards += c.Count
+			card, ok := cards[c.Name]
+			if !ok {
+				if strings.HasSuffix(c.Name, " Energy") {
+					stats.Supertypes[SupertypeEnergy] += c.Count
+					continue
+				}
+				stats.Supertypes[SupertypeUnknown] += c.Count
+				stats.Unknown = append(stats.Unknown, c.Name)
+				continue
+			}
+			supertype := NormalizeSupertype(card.SuperType)
+			stats.Supertypes[supertype] += c.Count
+			if supertype == SupertypePokemon {
+				stats.EnergyCurve[maxAttackCost(card)] += c.Count
+			}
+		}
+	}
+	sort.Strings(stats.Unknown)
+	return stats
+}
+
+func maxAttackCost(card Card) int {
+	cost := 0
+	for _, a := range card.Attacks {
+		if a.ConvertedEnergyCost > cost {
+			cost = a.ConvertedEnergyCost
+		}
+	}
+	return cost
+}
+
+// Ratio returns the share of the deck's cards with the given supertype
+func (s DeckStats) Ratio(supertype string) float64 {
+	if s.Cards == 0 {
+		return 0
+	}
+	return float64(s.Supertypes[supertype]) / float64(s.Cards)
+}
+
+// Warnings flags decks that are probably incomplete parses: no Energy or
+// no Pokémon, or not DeckSize cards
+func (s DeckStats) Warnings() []string {
+	var warnings []string
+	if s.Supertypes[SupertypeEnergy] == 0 {
+		warnings = append(warnings, "no Energy cards")
+	}
+	if s.Supertypes[SupertypePokemon] == 0 && s.Supertypes[SupertypeUnknown] == 0 {
+		warnings = append(warnings, "no Pokémon")
+	}
+	if s.Cards != DeckSize {
+		warnings = append(warnings, fmt.Sprintf("%d cards, want %d", s.Cards, DeckSize))
+	}
+	return warnings
+}
+
+// maxUnknownListed bounds the card names quoted in the report's warning
+const maxUnknownListed = 5
+
+// DeckAnalyzer is the games.Analyzer for Pokemon decks: supertype ratios
+// and the combined attack energy curve, using Cards for card data.
+type DeckAnalyzer struct {
+	Cards map[string]Card // keyed by card name
+}
+
+var (
+	_ games.Analyzer       = (*DeckAnalyzer)(nil)
+	_ games.CardDataLoader = (*DeckAnalyzer)(nil)
+)
+
+func (a *DeckAnalyzer) Game() string     { return "pokemon" }
+func (a *DeckAnalyzer) DeckType() string { return "PokemonDeck" }
+
+// CardDataDir is where the pokemontcg-data dataset stores cards
+func (a *DeckAnalyzer) CardDataDir() string {
+	return filepath.Join("pokemon", "pokemontcg-data", "cards")
+}
+
+// LoadCardData indexes the card JSON files under dir by name; the first
+// printing of a name, in path order, wins
+func (a *DeckAnalyzer) LoadCardData(dir string) (int, error) {
+	files, err := cio.FindCollectionFiles(dir, cio.FindOpts{SkipErrors: true})
+	if err != nil {
+		return 0, err
+	}
+	sort.Strings(files)
+	cards := make(map[string]Card, len(files))
+	for _, file := range files {
+		data, err := cio.ReadCollectionFile(file)
+		if err != nil {
+			continue
+		}
+		var card Card
+		if err := json.Unmarshal(data, &card); err != nil || card.Name == "" {
+			continue
+		}
+		if _, ok := cards[card.Name]; !ok {
+			cards[card.Name] = card
+		}
+	}
+	a.Cards = cards
+	return len(cards), nil
+}
+
+// Analyze reports mean supertype ratios (metrics "<supertype>_ratio"), the
+// combined "energy_curve" histogram, and warnings for incomplete decks
+func (a *DeckAnalyzer) Analyze(decks []*Collection) games.AnalysisReport {
+	report := games.AnalysisReport{
+		Game:       a.Game(),
+		Decks:      len(decks),
+		Metrics:    make(map[string]float64),
+		Histograms: map[string]map[int]int{"energy_curve": {}},
+	}
+	supertypes := []string{SupertypePokemon, SupertypeTrainer, SupertypeEnergy, SupertypeUnknown}
+	unknown := make(map[string]int)
+	for _, deck := range decks {
+		stats := AnalyzeDeck(deck, a.Cards)
+		for _, supertype := range supertypes {
+			report.Metrics[strings.ToLower(supertype)+"_ratio"] += stats.Ratio(supertype)
+		}
+		for cost, n := range stats.EnergyCurve {
+			report.Histograms["energy_curve"][cost] += n
+		}
+		for _, name := range stats.Unknown {
+			unknown[name]++
+		}
+		if w := stats.Warnings(); len(w) > 0 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %s", deck.ID, strings.Join(w, ", ")))
+		}
+	}
+	if len(decks) > 0 {
+		for k := range report.Metrics {
+			report.Metrics[k] /= float64(len(decks))
+		}
+	}
+	if len(unknown) > 0 {
+		names := make([]string, 0, len(unknown))
+		for name := range unknown {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if unknown[names[i]] != unknown[names[j]] {
+				return unknown[names[i]] > unknown[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		listed := names
+		if len(listed) > maxUnknownListed {
+			listed = listed[:maxUnknownListed]
+		}
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d card names have no card data (most common: %s)", len(names), strings.Join(listed, ", ")))
+	}
+	return report
+}
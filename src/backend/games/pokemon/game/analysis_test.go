@@ -0,0 +1,125 @@
+package game
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"collections/games"
+)
+
+func TestAnalyzeDeck(t *testing.T) {
+	attack := func(cost int) []Attack {
+		return []Attack{{Name: "Attack", ConvertedEnergyCost: cost}}
+	}
+	cards := map[string]Card{
+		"Charmander":           {Name: "Charmander", SuperType: "Pokémon", Attacks: append(attack(1), attack(2)...)},
+		"Charizard ex":         {Name: "Charizard ex", SuperType: "Pokémon", Attacks: attack(3)},
+		"Pidgey":               {Name: "Pidgey", SuperType: "Pokemon"},
+		"Rare Candy":           {Name: "Rare Candy", SuperType: "Trainer"},
+		"Professor's Research": {Name: "Professor's Research", SuperType: "Trainer"},
+	}
+	deck := &Collection{Partitions: []Partition{{
+		Name: "Main Deck",
+		Cards: []CardDesc{
+			{Name: "Charmander", Count: 4},
+			{Name: "Charizard ex", Count: 3},
+			{Name: "Pidgey", Count: 2},
+			{Name: "Rare Candy", Count: 4},
+			{Name: "Professor's Research", Count: 4},
+			{Name: "Fire Energy", Count: 8}, // basic energy, no card data
+			{Name: "Mystery Card", Count: 1},
+		},
+	}}}
+
+	stats := AnalyzeDeck(deck, cards)
+	if stats.Cards != 26 {
+		t.Errorf("Cards = %d, want 26", stats.Cards)
+	}
+	for supertype, want := range map[string]float64{
+		SupertypePokemon: 9.0 / 26,
+		SupertypeTrainer: 8.0 / 26,
+		SupertypeEnergy:  8.0 / 26,
+		SupertypeUnknown: 1.0 / 26,
+	} {
+		if got := stats.Ratio(supertype); math.Abs(got-want) > 1e-9 {
+			t.Errorf("Ratio(%s) = %v, want %v", supertype, got, want)
+		}
+	}
+	for cost, want := range map[int]int{0: 2, 2: 4, 3: 3} {
+		if got := stats.EnergyCurve[cost]; got != want {
+			t.Errorf("EnergyCurve[%d] = %d, want %d", cost, got, want)
+		}
+	}
+	if len(stats.Unknown) != 1 || stats.Unknown[0] != "Mystery Card" {
+		t.Errorf("Unknown = %v, want [Mystery Card]", stats.Unknown)
+	}
+	if w := stats.Warnings(); len(w) != 1 || w[0] != "26 cards, want 60" {
+		t.Errorf("Warnings() = %v, want only the deck size warning", w)
+	}
+}
+
+func TestDeckStatsWarnsWithoutEnergy(t *testing.T) {
+	deck := &Collection{Partitions: []Partition{{
+		Name:  "Main Deck",
+		Cards: []CardDesc{{Name: "Pikachu", Count: 60}},
+	}}}
+	stats := AnalyzeDeck(deck, map[string]Card{"Pikachu": {SuperType: "Pokémon"}})
+	if w := stats.Warnings(); len(w) != 1 || w[0] != "no Energy cards" {
+		t.Errorf("Warnings() = %v, want [no Energy cards]", w)
+	}
+}
+
+func TestDeckAnalyzer(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"sv1-1.json": `{"name": "Pikachu", "supertype": "Pokémon", "attacks": [{"name": "Zap", "convertedEnergyCost": 2}]}`,
+		"sv1-2.json": `{"name": "Nest Ball", "supertype": "Trainer"}`,
+		"sv2-1.json": `{"name": "Pikachu", "supertype": "Trainer"}`, // later printing loses
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a, ok := games.LookupAnalyzer("pokemon")
+	if !ok {
+		t.Fatal("no analyzer registered for pokemon")
+	}
+	analyzer := &DeckAnalyzer{}
+	if a.DeckType() != analyzer.DeckType() {
+		t.Errorf("registered DeckType() = %q, want %q", a.DeckType(), analyzer.DeckType())
+	}
+	if n, err := analyzer.LoadCardData(dir); err != nil || n != 2 {
+		t.Fatalf("LoadCardData() = %d, %v, want 2 cards", n, err)
+	}
+
+	full := &Collection{ID: "full", Partitions: []Partition{{Name: "Main Deck", Cards: []CardDesc{
+		{Name: "Pikachu", Count: 20},
+		{Name: "Nest Ball", Count: 20},
+		{Name: "Lightning Energy", Count: 20},
+	}}}}
+	partial := &Collection{ID: "partial", Partitions: []Partition{{Name: "Main Deck", Cards: []CardDesc{
+		{Name: "Pikachu", Count: 4},
+		{Name: "Mystery Card", Count: 1},
+	}}}}
+	report := analyzer.Analyze([]*Collection{full, partial})
+
+	if report.Game != "pokemon" || report.Decks != 2 {
+		t.Errorf("report = %s/%d decks, want pokemon/2", report.Game, report.Decks)
+	}
+	if got, want := report.Metrics["pokemon_ratio"], (20.0/60+4.0/5)/2; math.Abs(got-want) > 1e-9 {
+		t.Errorf("pokemon_ratio = %v, want %v", got, want)
+	}
+	if got := report.Histograms["energy_curve"][2]; got != 24 {
+		t.Errorf("energy_curve[2] = %d, want 24", got)
+	}
+	want := []string{
+		"partial: no Energy cards, 5 cards, want 60",
+		"1 card names have no card data (most common: Mystery Card)",
+	}
+	if len(report.Warnings) != len(want) || report.Warnings[0] != want[0] || report.Warnings[1] != want[1] {
+		t.Errorf("Warnings = %q, want %q", report.Warnings, want)
+	}
+}
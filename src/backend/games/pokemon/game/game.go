@@ -15,6 +15,7 @@ func init() {
 	games.RegisterCollectionType("PokemonBinder", func() games.CollectionType {
 		return new(CollectionTypeBinder)
 	})
+	games.RegisterAnalyzer(&DeckAnalyzer{})
 }
 
 // Type aliases for shared types (universal across all card games)
@@ -53,6 +54,9 @@ type Card struct {
 	SetName     string     `json:"set_name,omitempty"`    // Set name
 	Regulation  string     `json:"regulation,omitempty"`  // Regulation mark (D, E, F, etc.)
 	Legalities  map[string]string `json:"legalities,omitempty"` // Standard, Expanded legality
+
+	// Provenance
+	SourceCommit string `json:"source_commit,omitempty"` // Commit of the source data repo this card was imported from
 }
 
 type CardPrices struct {
@@ -102,7 +106,7 @@ type CollectionTypeDeck struct {
 	Player    string `json:"player,omitempty"`
 	// Tournament metadata (from Limitless TCG API)
 	Event     string `json:"event,omitempty"`     // Tournament name
-	Placement int    `json:"placement,omitempty"` // Finishing position (1 = 1st place)
+	Placement *int   `json:"placement,omitempty"` // Finishing position (1 = 1st place), nil when unknown
 	EventDate string `json:"eventDate,omitempty"` // Tournament date
 
 	// Enhanced tournament metadata
@@ -139,6 +143,7 @@ type CollectionTypeSet struct {
 	ReleaseDate  string `json:"releaseDate,omitempty"`
 	PrintedTotal int    `json:"printedTotal,omitempty"`
 	Total        int    `json:"total,omitempty"`
+	SourceCommit string `json:"source_commit,omitempty"` // Commit of the source data repo this set was imported from
 }
 
 type CollectionTypeBinder struct {
@@ -154,6 +159,18 @@ func (ct *CollectionTypeDeck) IsCollectionType()   {}
 func (ct *CollectionTypeSet) IsCollectionType()    {}
 func (ct *CollectionTypeBinder) IsCollectionType() {}
 
+func (ct *CollectionTypeDeck) DeckMetadata() games.DeckMetadata {
+	return games.DeckMetadata{
+		Name:      ct.Name,
+		Archetype: ct.Archetype,
+		Format:    ct.Format,
+		Player:    ct.Player,
+		Event:     ct.Event,
+		EventDate: ct.EventDate,
+		Placement: ct.Placement,
+	}
+}
+
 // Standard partition names for Pokemon
 const (
 	PartitionDeck   = "Deck"
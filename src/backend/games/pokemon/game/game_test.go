@@ -3,6 +3,8 @@ package game
 import (
 	"encoding/json"
 	"testing"
+
+	"collections/games"
 )
 
 func TestCollectionTypeMarshal(t *testing.T) {
@@ -19,6 +21,15 @@ func TestCollectionTypeMarshal(t *testing.T) {
 			},
 			want: `{"name":"Charizard Deck","format":"Standard"}`,
 		},
+		{
+			name: "PokemonDeckFirstPlace",
+			ct: &CollectionTypeDeck{
+				Name:      "Charizard Deck",
+				Format:    "Standard",
+				Placement: games.KnownPlacement(1),
+			},
+			want: `{"name":"Charizard Deck","format":"Standard","placement":1}`,
+		},
 		{
 			name: "PokemonSet",
 			ct: &CollectionTypeSet{
@@ -84,3 +95,25 @@ func TestCardMarshal(t *testing.T) {
 		t.Errorf("Attacks count = %v, want 1", len(decoded.Attacks))
 	}
 }
+
+func TestDeckPlacementUnknown(t *testing.T) {
+	var deck CollectionTypeDeck
+	if err := json.Unmarshal([]byte(`{"name":"Charizard Deck","format":"Standard"}`), &deck); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if deck.Placement != nil {
+		t.Errorf("Placement = %d, want nil", *deck.Placement)
+	}
+
+	got, err := json.Marshal(&deck)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := `{"name":"Charizard Deck","format":"Standard"}`; string(got) != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+
+	if p := games.KnownPlacement(0); p != nil {
+		t.Errorf("KnownPlacement(0) = %d, want nil", *p)
+	}
+}
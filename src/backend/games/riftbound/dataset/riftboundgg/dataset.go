@@ -305,6 +305,9 @@ func (d *Dataset) parseDeck(
 		}
 		if exists {
 			d.log.Field("deck_id", deckID).Debugf(ctx, "Deck already exists")
+			if stats := games.ExtractStatsFromContext(ctx); stats != nil {
+				stats.RecordSkipped()
+			}
 			return nil
 		}
 	}
@@ -330,7 +333,7 @@ func (d *Dataset) parseDeck(
 
 	// Extract deck metadata
 	deckName := doc.Find("h1, .deck-title, .deck-name, [data-deck-name]").First().Text()
-	deckName = strings.TrimSpace(deckName)
+	deckName = games.CleanDeckName(deckName)
 	if deckName == "" {
 		deckName = "Untitled Deck"
 	}
@@ -444,7 +447,7 @@ func (d *Dataset) parseDeck(
 		Archetype: archetype,
 		Player:    playerName,
 		Event:     tournamentName,
-		Placement: placement,
+		Placement: games.KnownPlacement(placement),
 		EventDate: eventDateStr,
 	}
 
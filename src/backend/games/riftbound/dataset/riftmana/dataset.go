@@ -384,11 +384,11 @@ func (d *Dataset) storeDecklist(
 
 	// Build collection metadata
 	deckType := &game.CollectionTypeDeck{
-		Name:      deckName,
+		Name:      games.CleanDeckName(deckName),
 		Format:    format,
 		Champion:  champion,
 		Event:     event,
-		Placement: placement,
+		Placement: games.KnownPlacement(placement),
 		EventDate: eventDate.Format("2006-01-02"),
 	}
 
@@ -74,7 +74,7 @@ type CollectionTypeDeck struct {
 	Champion  string `json:"champion,omitempty"` // Champion name
 	// Tournament metadata
 	Event     string `json:"event,omitempty"`
-	Placement int    `json:"placement,omitempty"`
+	Placement *int   `json:"placement,omitempty"` // nil when unknown
 	EventDate string `json:"eventDate,omitempty"`
 }
 
@@ -93,6 +93,18 @@ func (ct *CollectionTypeSet) Type() string    { return "RiftboundSet" }
 func (ct *CollectionTypeDeck) IsCollectionType()   {}
 func (ct *CollectionTypeSet) IsCollectionType()    {}
 
+func (ct *CollectionTypeDeck) DeckMetadata() games.DeckMetadata {
+	return games.DeckMetadata{
+		Name:      ct.Name,
+		Archetype: ct.Archetype,
+		Format:    ct.Format,
+		Player:    ct.Player,
+		Event:     ct.Event,
+		EventDate: ct.EventDate,
+		Placement: ct.Placement,
+	}
+}
+
 // Standard partition names for Riftbound
 const (
 	PartitionDeck = "Deck"
@@ -0,0 +1,147 @@
+package games
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"hash/fnv"
+	"sort"
+	"sync"
+)
+
+// ReservoirSampler keeps a uniform random sample of up to Size items per
+// stratum from a stream too large to hold in memory, such as every
+// collection under a blob prefix. Memory is O(Size) per stratum, however
+// long the stream.
+//
+// Each item gets a random priority from a hash of the seed and its id, and
+// a stratum keeps the Size items with the lowest priorities. The sample so
+// depends only on the seed and the ids, not on the order items arrive in,
+// which keeps it reproducible when items are added from parallel workers
+// (as IterItemsBlobPrefix calls fn). Add is safe for concurrent use.
+type ReservoirSampler[T any] struct {
+	size int
+	seed uint64
+
+	mu     sync.Mutex
+	strata map[string]*reservoir[T]
+}
+
+// NewReservoirSampler returns a sampler keeping up to size items per
+// stratum
+func NewReservoirSampler[T any](size int, seed int64) *ReservoirSampler[T] {
+	return &ReservoirSampler[T]{
+		size:   size,
+		seed:   uint64(seed),
+		strata: make(map[string]*reservoir[T]),
+	}
+}
+
+// Add offers item, identified by id, to the sample of stratum. Ids should
+// be unique within a stratum; items with the same id are all kept or all
+// dropped.
+func (s *ReservoirSampler[T]) Add(stratum, id string, item T) {
+	p := s.priority(id)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.strata[stratum]
+	if !ok {
+		r = &reservoir[T]{}
+		s.strata[stratum] = r
+	}
+	r.seen++
+	if s.size <= 0 {
+		return
+	}
+	if len(r.items) < s.size {
+		heap.Push(r, sampled[T]{priority: p, item: item})
+	} else if p < r.items[0].priority {
+		r.items[0] = sampled[T]{priority: p, item: item}
+		heap.Fix(r, 0)
+	}
+}
+
+// Seen returns how many items were offered to stratum
+func (s *ReservoirSampler[T]) Seen(stratum string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.strata[stratum]; ok {
+		return r.seen
+	}
+	return 0
+}
+
+// Strata returns the strata items were offered to, sorted
+func (s *ReservoirSampler[T]) Strata() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	strata := make([]string, 0, len(s.strata))
+	for stratum := range s.strata {
+		strata = append(strata, stratum)
+	}
+	sort.Strings(strata)
+	return strata
+}
+
+// Sample returns a uniform random sample of up to n of the items offered to
+// stratum, at most Size. A smaller n is still uniform over the whole
+// stream, so strata can be sampled to sizes decided after the pass.
+func (s *ReservoirSampler[T]) Sample(stratum string, n int) []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.strata[stratum]
+	if !ok {
+		return nil
+	}
+	kept := append([]sampled[T](nil), r.items...)
+	sort.Slice(kept, func(i, j int) bool { return kept[i].priority < kept[j].priority })
+	if n < len(kept) {
+		kept = kept[:n]
+	}
+	items := make([]T, len(kept))
+	for i, k := range kept {
+		items[i] = k.item
+	}
+	return items
+}
+
+// priority hashes id with the seed to a uniformly distributed value
+func (s *ReservoirSampler[T]) priority(id string) uint64 {
+	h := fnv.New64a()
+	var seed [8]byte
+	binary.LittleEndian.PutUint64(seed[:], s.seed)
+	h.Write(seed[:])
+	h.Write([]byte(id))
+	// FNV alone is poorly mixed for ids differing in their last bytes
+	return mix64(h.Sum64())
+}
+
+// mix64 is the splitmix64 finalizer
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+type sampled[T any] struct {
+	priority uint64
+	item     T
+}
+
+// reservoir is a max-heap on priority, so the item to evict is at the root
+type reservoir[T any] struct {
+	seen  int
+	items []sampled[T]
+}
+
+func (r *reservoir[T]) Len() int           { return len(r.items) }
+func (r *reservoir[T]) Less(i, j int) bool { return r.items[i].priority > r.items[j].priority }
+func (r *reservoir[T]) Swap(i, j int)      { r.items[i], r.items[j] = r.items[j], r.items[i] }
+func (r *reservoir[T]) Push(x any)         { r.items = append(r.items, x.(sampled[T])) }
+func (r *reservoir[T]) Pop() any {
+	last := r.items[len(r.items)-1]
+	r.items = r.items[:len(r.items)-1]
+	return last
+}
@@ -0,0 +1,86 @@
+package games
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestReservoirSamplerUniform(t *testing.T) {
+	const (
+		stream = 100
+		size   = 10
+		runs   = 4000
+	)
+	// Every item of the stream should be picked in about size/stream of
+	// the runs, whatever its position
+	picked := make([]int, stream)
+	for seed := 0; seed < runs; seed++ {
+		s := NewReservoirSampler[int](size, int64(seed))
+		for i := 0; i < stream; i++ {
+			s.Add("modern", fmt.Sprint(i), i)
+			s.Add("legacy", fmt.Sprint(i), -1) // Must not crowd out modern
+		}
+		sample := s.Sample("modern", size)
+		if len(sample) != size {
+			t.Fatalf("seed %d: sampled %d items, want %d", seed, len(sample), size)
+		}
+		for _, i := range sample {
+			picked[i]++
+		}
+	}
+
+	want := float64(runs * size / stream)
+	var chi2 float64
+	for i, n := range picked {
+		// Binomial sd is about 19 here; 5 sd allows for 100 items
+		if math.Abs(float64(n)-want) > 5*math.Sqrt(want) {
+			t.Errorf("item %d picked %d times, want about %.0f", i, n, want)
+		}
+		chi2 += (float64(n) - want) * (float64(n) - want) / want
+	}
+	// 99 degrees of freedom: p < 0.001 above about 149
+	if chi2 > 149 {
+		t.Errorf("chi-squared = %.1f over %d items, sampling is not uniform", chi2, stream)
+	}
+}
+
+func TestReservoirSamplerOrderIndependent(t *testing.T) {
+	sample := func(order []int) []int {
+		s := NewReservoirSampler[int](5, 42)
+		for _, i := range order {
+			s.Add("s", fmt.Sprint(i), i)
+		}
+		got := s.Sample("s", 3)
+		sort.Ints(got)
+		return got
+	}
+	forward, backward := make([]int, 50), make([]int, 50)
+	for i := range forward {
+		forward[i], backward[i] = i, 49-i
+	}
+	if a, b := sample(forward), sample(backward); fmt.Sprint(a) != fmt.Sprint(b) {
+		t.Errorf("sample depends on stream order: %v vs %v", a, b)
+	}
+}
+
+func TestReservoirSamplerCounts(t *testing.T) {
+	s := NewReservoirSampler[string](2, 1)
+	for _, id := range []string{"a", "b", "c"} {
+		s.Add("x", id, id)
+	}
+	s.Add("y", "d", "d")
+	if got := s.Strata(); fmt.Sprint(got) != "[x y]" {
+		t.Errorf("Strata() = %v, want [x y]", got)
+	}
+	if s.Seen("x") != 3 || s.Seen("y") != 1 || s.Seen("z") != 0 {
+		t.Errorf("Seen() = %d, %d, %d, want 3, 1, 0", s.Seen("x"), s.Seen("y"), s.Seen("z"))
+	}
+	if got := s.Sample("x", 5); len(got) != 2 {
+		t.Errorf("Sample(x, 5) = %v, want the 2 kept", got)
+	}
+	if got := s.Sample("z", 5); got != nil {
+		t.Errorf("Sample(z) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,52 @@
+package games
+
+import (
+	"context"
+
+	"collections/scraper"
+)
+
+// ScrapeResult summarizes the outcome of a single Extract run
+type ScrapeResult struct {
+	New     int `json:"new"`     // Items parsed and written
+	Skipped int `json:"skipped"` // Items already present and not re-parsed
+	Errors  int `json:"errors"`  // Items that failed to extract
+}
+
+// Result returns the current counts as a ScrapeResult
+func (s *ExtractStats) Result() ScrapeResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ScrapeResult{
+		New:     s.Successful,
+		Skipped: s.Skipped,
+		Errors:  s.Failed,
+	}
+}
+
+// ExtractWithResult runs d.Extract and returns how many items were scraped,
+// skipped, or errored during that run.
+// Counts are read from the ExtractStats carried in ctx; one is attached if
+// ctx has none. Extract itself is unchanged, so existing callers keep working.
+func ExtractWithResult(
+	ctx context.Context,
+	d Dataset,
+	sc *scraper.Scraper,
+	options ...UpdateOption,
+) (ScrapeResult, error) {
+	stats := ExtractStatsFromContext(ctx)
+	if stats == nil {
+		stats = NewExtractStats(nil)
+		ctx = WithExtractStats(ctx, stats)
+	}
+
+	before := stats.Result()
+	err := d.Extract(ctx, sc, options...)
+	after := stats.Result()
+
+	return ScrapeResult{
+		New:     after.New - before.New,
+		Skipped: after.Skipped - before.Skipped,
+		Errors:  after.Errors - before.Errors,
+	}, err
+}
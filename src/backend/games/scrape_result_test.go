@@ -0,0 +1,75 @@
+package games
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"collections/scraper"
+)
+
+// fakeDataset records a fixed number of outcomes through the context stats,
+// the same way real datasets do
+type fakeDataset struct {
+	new, skipped, failed int
+}
+
+func (d *fakeDataset) Description() Description {
+	return Description{Game: "test", Name: "fake"}
+}
+
+func (d *fakeDataset) Extract(ctx context.Context, _ *scraper.Scraper, _ ...UpdateOption) error {
+	stats := ExtractStatsFromContext(ctx)
+	if stats == nil {
+		return errors.New("no stats in context")
+	}
+	for i := 0; i < d.new; i++ {
+		stats.RecordSuccess()
+	}
+	for i := 0; i < d.skipped; i++ {
+		stats.RecordSkipped()
+	}
+	for i := 0; i < d.failed; i++ {
+		stats.RecordError(ctx, "http://example.com", "fake", errors.New("boom"))
+	}
+	return nil
+}
+
+func (d *fakeDataset) IterItems(context.Context, func(Item) error, ...IterItemsOption) error {
+	return nil
+}
+
+func TestExtractWithResult(t *testing.T) {
+	ctx := context.Background()
+	d := &fakeDataset{new: 3, skipped: 2, failed: 1}
+
+	res, err := ExtractWithResult(ctx, d, nil)
+	if err != nil {
+		t.Fatalf("ExtractWithResult() error = %v", err)
+	}
+	want := ScrapeResult{New: 3, Skipped: 2, Errors: 1}
+	if res != want {
+		t.Errorf("ExtractWithResult() = %+v, want %+v", res, want)
+	}
+}
+
+func TestExtractWithResultExistingStats(t *testing.T) {
+	ctx := context.Background()
+	stats := NewExtractStats(nil)
+	stats.RecordSuccess()
+	stats.RecordSkipped()
+	ctx = WithExtractStats(ctx, stats)
+
+	res, err := ExtractWithResult(ctx, &fakeDataset{new: 2}, nil)
+	if err != nil {
+		t.Fatalf("ExtractWithResult() error = %v", err)
+	}
+	// Only counts from this run are reported
+	if want := (ScrapeResult{New: 2}); res != want {
+		t.Errorf("ExtractWithResult() = %+v, want %+v", res, want)
+	}
+	// The shared stats still accumulate across runs
+	if stats.Successful != 3 || stats.Skipped != 1 {
+		t.Errorf("stats = %d successful, %d skipped; want 3, 1", stats.Successful, stats.Skipped)
+	}
+}
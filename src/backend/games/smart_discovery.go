@@ -2,9 +2,7 @@ package games
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"path/filepath"
 	"sync"
 	"time"
 
@@ -23,29 +21,27 @@ type DiscoveryState struct {
 
 // SmartDiscoveryTracker enables smarter discovery by tracking where we left off
 type SmartDiscoveryTracker struct {
-	blob   *blob.Bucket
-	log    *logger.Logger
-	prefix string
-	state  map[string]*DiscoveryState // dataset name -> state
-	mu     sync.RWMutex                // Protects state map for concurrent access
+	kv    *BlobKV
+	log   *logger.Logger
+	state map[string]*DiscoveryState // dataset name -> state
+	mu    sync.RWMutex                // Protects state map for concurrent access
 }
 
 // NewSmartDiscoveryTracker creates a new smart discovery tracker
 func NewSmartDiscoveryTracker(log *logger.Logger, blob *blob.Bucket, prefix string) *SmartDiscoveryTracker {
 	return &SmartDiscoveryTracker{
-		blob:   blob,
-		log:    log,
-		prefix: prefix,
-		state:  make(map[string]*DiscoveryState),
+		kv:    NewBlobKV(blob, prefix),
+		log:   log,
+		state: make(map[string]*DiscoveryState),
 	}
 }
 
 // Load loads discovery state from blob storage
 func (sdt *SmartDiscoveryTracker) Load(ctx context.Context, datasetName string) (*DiscoveryState, error) {
-	key := sdt.stateKey(datasetName)
-	exists, err := sdt.blob.Exists(ctx, key)
+	var state DiscoveryState
+	exists, err := sdt.kv.Get(ctx, sdt.stateKey(datasetName), &state)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check discovery state: %w", err)
+		return nil, fmt.Errorf("failed to load discovery state: %w", err)
 	}
 	if !exists {
 		sdt.log.Debugf(ctx, "No existing discovery state for %s, starting fresh", datasetName)
@@ -55,16 +51,6 @@ func (sdt *SmartDiscoveryTracker) Load(ctx context.Context, datasetName string)
 		}, nil
 	}
 
-	data, err := sdt.blob.Read(ctx, key)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read discovery state: %w", err)
-	}
-
-	var state DiscoveryState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("failed to parse discovery state: %w", err)
-	}
-
 	sdt.mu.Lock()
 	sdt.state[datasetName] = &state
 	sdt.mu.Unlock()
@@ -74,14 +60,8 @@ func (sdt *SmartDiscoveryTracker) Load(ctx context.Context, datasetName string)
 
 // Save saves discovery state to blob storage
 func (sdt *SmartDiscoveryTracker) Save(ctx context.Context, datasetName string, state *DiscoveryState) error {
-	data, err := json.Marshal(state)
-	if err != nil {
-		return fmt.Errorf("failed to marshal discovery state: %w", err)
-	}
-
-	key := sdt.stateKey(datasetName)
-	if err := sdt.blob.Write(ctx, key, data); err != nil {
-		return fmt.Errorf("failed to write discovery state: %w", err)
+	if err := sdt.kv.Set(ctx, sdt.stateKey(datasetName), state); err != nil {
+		return fmt.Errorf("failed to save discovery state: %w", err)
 	}
 
 	sdt.mu.Lock()
@@ -155,6 +135,6 @@ func (sdt *SmartDiscoveryTracker) ShouldStopDiscovery(ctx context.Context, datas
 }
 
 func (sdt *SmartDiscoveryTracker) stateKey(datasetName string) string {
-	return filepath.Join(sdt.prefix, fmt.Sprintf(".discovery_%s.json", datasetName))
+	return ".discovery_" + datasetName
 }
 
@@ -0,0 +1,175 @@
+package games
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Source names the scraper or dataset a collection came from. Datasets
+// write their own name; exports fall back to InferSource for collections
+// saved without one.
+type Source string
+
+const (
+	SourceMTGTop8              Source = "mtgtop8"
+	SourceGoldfish             Source = "goldfish"
+	SourceDeckbox              Source = "deckbox"
+	SourceScryfall             Source = "scryfall"
+	SourceMoxfield             Source = "moxfield"
+	SourceArchidekt            Source = "archidekt"
+	Source17Lands              Source = "17lands"
+	SourceLimitless            Source = "limitless"
+	SourceLimitlessWeb         Source = "limitless-web"
+	SourcePokemonTCG           Source = "pokemontcg"
+	SourcePokemonTCGData       Source = "pokemontcg-data"
+	SourcePokemonTCGIO         Source = "pokemontcg.io"
+	SourcePokemonTCGPriceAPI   Source = "pokemon-tcg-price-api"
+	SourcePokemonCardIO        Source = "pokemoncard-io"
+	SourcePokestats            Source = "pokestats"
+	SourceYGOPRODeck           Source = "ygoprodeck"
+	SourceYGOPRODeckTournament Source = "ygoprodeck-tournament"
+	SourceYugiohMeta           Source = "yugiohmeta"
+	SourceRiftcodex            Source = "riftcodex"
+	SourceRiftdecks            Source = "riftdecks"
+	SourceRiftboundGG          Source = "riftboundgg"
+	SourceRiftmana             Source = "riftmana"
+	SourceDigimonMeta          Source = "digimonmeta"
+	SourceOnePieceTopDecks     Source = "onepiecetopdecks"
+
+	// SourceUnknown is what InferSource returns when nothing identifies
+	// the source. It is not a known source.
+	SourceUnknown Source = unknownSource
+)
+
+// knownSources lists every Source constant but SourceUnknown
+var knownSources = map[Source]bool{
+	SourceMTGTop8:              true,
+	SourceGoldfish:             true,
+	SourceDeckbox:              true,
+	SourceScryfall:             true,
+	SourceMoxfield:             true,
+	SourceArchidekt:            true,
+	Source17Lands:              true,
+	SourceLimitless:            true,
+	SourceLimitlessWeb:         true,
+	SourcePokemonTCG:           true,
+	SourcePokemonTCGData:       true,
+	SourcePokemonTCGIO:         true,
+	SourcePokemonTCGPriceAPI:   true,
+	SourcePokemonCardIO:        true,
+	SourcePokestats:            true,
+	SourceYGOPRODeck:           true,
+	SourceYGOPRODeckTournament: true,
+	SourceYugiohMeta:           true,
+	SourceRiftcodex:            true,
+	SourceRiftdecks:            true,
+	SourceRiftboundGG:          true,
+	SourceRiftmana:             true,
+	SourceDigimonMeta:          true,
+	SourceOnePieceTopDecks:     true,
+}
+
+// sourceAliases maps other spellings of a source, such as site names, to
+// the Source datasets write
+var sourceAliases = map[string]Source{
+	"mtggoldfish":     SourceGoldfish,
+	"mtggoldfish.com": SourceGoldfish,
+	"mtgtop8.com":     SourceMTGTop8,
+	"deckbox.org":     SourceDeckbox,
+	"scryfall.com":    SourceScryfall,
+	"limitlesstcg":    SourceLimitlessWeb,
+	"ygoprodeck.com":  SourceYGOPRODeck,
+	"riftbound.gg":    SourceRiftboundGG,
+}
+
+// IsKnown reports whether s is one of the Source constants, other than
+// SourceUnknown
+func (s Source) IsKnown() bool {
+	return knownSources[s]
+}
+
+// ParseSource returns the Source named by s, accepting the aliases in
+// sourceAliases and ignoring case and surrounding whitespace. Reports false
+// for anything else.
+func ParseSource(s string) (Source, bool) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if alias, ok := sourceAliases[name]; ok {
+		return alias, true
+	}
+	source := Source(name)
+	return source, source.IsKnown()
+}
+
+// validateSource returns the canonical form of a collection's source, which
+// may be empty, or an error when it isn't a known source
+func validateSource(s Source) (Source, error) {
+	if s == "" {
+		return "", nil
+	}
+	source, ok := ParseSource(string(s))
+	if !ok {
+		return s, fmt.Errorf("unknown source %q", s)
+	}
+	return source, nil
+}
+
+// urlSources and pathSources are the substrings InferSource looks for, in
+// order
+var (
+	urlSources = []struct {
+		hint   string
+		source Source
+	}{
+		{"mtgtop8", SourceMTGTop8},
+		{"goldfish", SourceGoldfish},
+		{"deckbox", SourceDeckbox},
+		{"limitless", SourceLimitlessWeb},
+		{"ygoprodeck", SourceYGOPRODeckTournament},
+		{"scryfall", SourceScryfall},
+		{"moxfield", SourceMoxfield},
+		{"archidekt", SourceArchidekt},
+		{"riftdecks", SourceRiftdecks},
+		{"riftbound.gg", SourceRiftboundGG},
+		{"riftmana", SourceRiftmana},
+	}
+	pathSources = []struct {
+		hint   string
+		source Source
+	}{
+		{"mtgtop8", SourceMTGTop8},
+		{"goldfish", SourceGoldfish},
+		{"deckbox", SourceDeckbox},
+		{"limitless", SourceLimitlessWeb},
+		{"ygoprodeck", SourceYGOPRODeckTournament},
+		{"scryfall", SourceScryfall},
+		{"pokemon", SourceLimitlessWeb},        // Default for Pokemon
+		{"yugioh", SourceYGOPRODeckTournament}, // Default for Yu-Gi-Oh
+		{"ygo", SourceYGOPRODeckTournament},
+	}
+)
+
+// InferSource guesses the source of a collection saved without one, from
+// its URL and then its file path (the dataset directory, or the game's
+// usual deck source), falling back to the parent directory name when that
+// is a known source and finally SourceUnknown
+func InferSource(url, filePath string) Source {
+	urlLower := strings.ToLower(url)
+	for _, s := range urlSources {
+		if strings.Contains(urlLower, s.hint) {
+			return s.source
+		}
+	}
+
+	pathLower := strings.ToLower(filePath)
+	for _, s := range pathSources {
+		if strings.Contains(pathLower, s.hint) {
+			return s.source
+		}
+	}
+
+	if source, ok := ParseSource(filepath.Base(filepath.Dir(filePath))); ok {
+		return source
+	}
+	return SourceUnknown
+}
@@ -0,0 +1,61 @@
+package games
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInferSource(t *testing.T) {
+	tests := []struct {
+		url, path string
+		want      Source
+	}{
+		{"https://www.mtgtop8.com/event?e=1&d=2", "data/deck.json", "mtgtop8"},
+		{"https://limitlesstcg.com/decks/list/123", "", "limitless-web"},
+		{"", "data-full/games/magic/goldfish/collections/1.json.zst", "goldfish"},
+		{"", "data-full/games/pokemon/other/1.json", "limitless-web"},
+		{"https://example.com/1", "data-full/games/digimon/digimonmeta/1.json", "digimonmeta"},
+		{"", "deck.json", "unknown"},
+	}
+	for _, tt := range tests {
+		if got := InferSource(tt.url, tt.path); got != tt.want {
+			t.Errorf("InferSource(%q, %q) = %q, want %q", tt.url, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Source
+		ok   bool
+	}{
+		{"mtgtop8", SourceMTGTop8, true},
+		{" MTGGoldfish ", SourceGoldfish, true},
+		{"limitlesstcg", SourceLimitlessWeb, true},
+		{"unknown", SourceUnknown, false},
+		{"example.com", "example.com", false},
+	}
+	for _, tt := range tests {
+		if got, ok := ParseSource(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("ParseSource(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCanonicalizeSource(t *testing.T) {
+	c := Collection{
+		ID:          "1",
+		URL:         "https://www.mtggoldfish.com/deck/1",
+		Type:        CollectionTypeWrapper{Type: "TestType", Inner: &testCollectionType{}},
+		ReleaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Partitions:  []Partition{{Name: "Main", Cards: []CardDesc{{Name: "Card A", Count: 1}}}},
+		Source:      "MTGGoldfish",
+	}
+	if err := c.Canonicalize(); err != nil {
+		t.Fatalf("Canonicalize() error = %v", err)
+	}
+	if c.Source != SourceGoldfish {
+		t.Errorf("Source = %q, want %q", c.Source, SourceGoldfish)
+	}
+}
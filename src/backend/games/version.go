@@ -0,0 +1,55 @@
+package games
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+// BuildVersion overrides the version read from the build info, for builds
+// made without VCS info (e.g. from a source tarball):
+//
+//	go build -ldflags "-X collections/games.BuildVersion=v1.2.3" ./cmd/dataset
+var BuildVersion string
+
+// ScraperVersion returns BuildVersion when set, else the VCS revision the
+// running binary was built from, suffixed "-dirty" when the tree had local
+// changes, or "" when the build carries no VCS info
+func ScraperVersion() string {
+	if BuildVersion != "" {
+		return BuildVersion
+	}
+	return scraperVersion()
+}
+
+// ParserVersion is the version Canonicalize stamps on collections: the
+// ScraperVersion, or "devel" for builds without one, such as go run and
+// tests
+func ParserVersion() string {
+	if v := ScraperVersion(); v != "" {
+		return v
+	}
+	return "devel"
+}
+
+var scraperVersion = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	var revision, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		}
+	}
+	if revision == "" {
+		return ""
+	}
+	if modified == "true" {
+		revision += "-dirty"
+	}
+	return revision
+})
@@ -0,0 +1,41 @@
+package games
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParserVersion(t *testing.T) {
+	if ParserVersion() == "" {
+		t.Fatal("ParserVersion() is empty")
+	}
+
+	defer func(v string) { BuildVersion = v }(BuildVersion)
+	BuildVersion = "v1.2.3"
+	if got := ParserVersion(); got != "v1.2.3" {
+		t.Errorf("ParserVersion() with BuildVersion set = %q, want v1.2.3", got)
+	}
+
+	c := Collection{
+		ID:          "test-123",
+		URL:         "https://example.com/test",
+		Type:        CollectionTypeWrapper{Type: "TestType", Inner: &testCollectionType{}},
+		ReleaseDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Partitions:  []Partition{{Name: "Main", Cards: []CardDesc{{Name: "Lightning Bolt", Count: 4}}}},
+	}
+	if err := c.Canonicalize(); err != nil {
+		t.Fatalf("Canonicalize() error = %v", err)
+	}
+	if c.ParserVersion != "v1.2.3" {
+		t.Errorf("ParserVersion = %q, want v1.2.3", c.ParserVersion)
+	}
+
+	// Recanonicalizing a stored collection keeps the version that parsed it
+	BuildVersion = "v2.0.0"
+	if err := c.Canonicalize(); err != nil {
+		t.Fatalf("Canonicalize() error = %v", err)
+	}
+	if c.ParserVersion != "v1.2.3" {
+		t.Errorf("ParserVersion after recanonicalizing = %q, want v1.2.3", c.ParserVersion)
+	}
+}
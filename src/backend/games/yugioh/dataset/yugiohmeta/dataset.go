@@ -16,7 +16,6 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/PuerkitoBio/goquery"
 )
@@ -192,7 +191,7 @@ func (d *Dataset) parseDeck(
 	}
 
 	// Extract deck metadata
-	deckName := strings.TrimSpace(doc.Find("h1.deck-title, h2.deck-name").Text())
+	deckName := games.CleanDeckName(doc.Find("h1.deck-title, h2.deck-name").Text())
 	if deckName == "" {
 		deckName = "Unnamed Deck"
 	}
@@ -212,7 +211,7 @@ func (d *Dataset) parseDeck(
 	// Extract player and event info
 	player := ""
 	event := ""
-	placement := ""
+	placement := 0
 	eventDate := ""
 
 	doc.Find(".player-info, .tournament-info").Each(func(i int, s *goquery.Selection) {
@@ -222,7 +221,7 @@ func (d *Dataset) parseDeck(
 		} else if strings.Contains(text, "Event:") {
 			event = strings.TrimSpace(strings.Split(text, "Event:")[1])
 		} else if strings.Contains(text, "Place:") || strings.Contains(text, "Placement:") {
-			placement = strings.TrimSpace(strings.Split(text, ":")[1])
+			placement, _ = games.ParsePlacement(strings.Split(text, ":")[1])
 		}
 	})
 
@@ -266,7 +265,7 @@ func (d *Dataset) parseDeck(
 		Archetype:      archetype,
 		Player:         player,
 		Event:          event,
-		Placement:      placement,
+		Placement:      games.KnownPlacement(placement),
 		EventDate:      eventDate,
 		TournamentType: tournamentType,
 		Location:       location,
@@ -279,7 +278,8 @@ func (d *Dataset) parseDeck(
 		},
 		ID:          deckID,
 		URL:         deckURL,
-		ReleaseDate: time.Now(),
+		ReleaseDate: page.ScrapedAt, // the event date is on the deck type
+		ScrapedAt:   page.ScrapedAt,
 		Partitions:  partitions,
 		Source:      "yugiohmeta",
 	}
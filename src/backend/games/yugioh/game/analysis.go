@@ -0,0 +1,49 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"collections/games"
+)
+
+// DeckAnalyzer is the games.Analyzer for Yu-Gi-Oh! decks: main, extra and
+// side deck sizes and the extra-to-main ratio.
+type DeckAnalyzer struct{}
+
+var _ games.Analyzer = (*DeckAnalyzer)(nil)
+
+func (a *DeckAnalyzer) Game() string     { return "yugioh" }
+func (a *DeckAnalyzer) DeckType() string { return "YGODeck" }
+
+// Analyze reports mean partition sizes ("main_size", "extra_size",
+// "side_size"), the mean "extra_ratio" (extra / (main + extra)), a
+// "main_size" histogram, and warnings for decks outside the size limits
+func (a *DeckAnalyzer) Analyze(decks []*Collection) games.AnalysisReport {
+	report := games.AnalysisReport{
+		Game:       a.Game(),
+		Decks:      len(decks),
+		Metrics:    make(map[string]float64),
+		Histograms: map[string]map[int]int{"main_size": {}},
+	}
+	for _, deck := range decks {
+		main, extra, side := ZoneSizes(deck)
+		report.Metrics["main_size"] += float64(main)
+		report.Metrics["extra_size"] += float64(extra)
+		report.Metrics["side_size"] += float64(side)
+		if main+extra > 0 {
+			report.Metrics["extra_ratio"] += float64(extra) / float64(main+extra)
+		}
+		report.Histograms["main_size"][main]++
+
+		if warnings := ZoneViolations(deck); len(warnings) > 0 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %s", deck.ID, strings.Join(warnings, ", ")))
+		}
+	}
+	if len(decks) > 0 {
+		for k := range report.Metrics {
+			report.Metrics[k] /= float64(len(decks))
+		}
+	}
+	return report
+}
@@ -0,0 +1,45 @@
+package game
+
+import (
+	"math"
+	"testing"
+
+	"collections/games"
+)
+
+func TestDeckAnalyzer(t *testing.T) {
+	if _, ok := games.LookupAnalyzer("yugioh"); !ok {
+		t.Fatal("no analyzer registered for yugioh")
+	}
+	legal := &Collection{ID: "legal", Partitions: []Partition{
+		{Name: PartitionMain, Cards: []CardDesc{{Name: "Ash Blossom & Joyous Spring", Count: 3}, {Name: "Snake-Eye Ash", Count: 37}}},
+		{Name: PartitionExtra, Cards: []CardDesc{{Name: "I:P Masquerena", Count: 10}}},
+		{Name: PartitionSide, Cards: []CardDesc{{Name: "Droll & Lock Bird", Count: 15}}},
+	}}
+	partial := &Collection{ID: "partial", Partitions: []Partition{
+		{Name: PartitionMain, Cards: []CardDesc{{Name: "Snake-Eye Ash", Count: 20}}},
+		{Name: PartitionExtra, Cards: []CardDesc{{Name: "I:P Masquerena", Count: 20}}},
+	}}
+
+	report := (&DeckAnalyzer{}).Analyze([]*Collection{legal, partial})
+	if report.Game != "yugioh" || report.Decks != 2 {
+		t.Errorf("report = %s/%d decks, want yugioh/2", report.Game, report.Decks)
+	}
+	for metric, want := range map[string]float64{
+		"main_size":   30,
+		"extra_size":  15,
+		"side_size":   7.5,
+		"extra_ratio": (10.0/50 + 20.0/40) / 2,
+	} {
+		if got := report.Metrics[metric]; math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", metric, got, want)
+		}
+	}
+	if h := report.Histograms["main_size"]; h[40] != 1 || h[20] != 1 {
+		t.Errorf("main_size histogram = %v, want 40 and 20 once each", h)
+	}
+	want := "partial: 20 main deck cards, want 40-60, 20 extra deck cards, want at most 15"
+	if len(report.Warnings) != 1 || report.Warnings[0] != want {
+		t.Errorf("Warnings = %q, want [%q]", report.Warnings, want)
+	}
+}
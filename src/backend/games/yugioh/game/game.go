@@ -2,6 +2,7 @@ package game
 
 import (
 	"collections/games"
+	"encoding/json"
 )
 
 // Register Yu-Gi-Oh! collection types with the global registry
@@ -12,6 +13,7 @@ func init() {
 	games.RegisterCollectionType("YGOCollection", func() games.CollectionType {
 		return new(CollectionTypeCollection)
 	})
+	games.RegisterAnalyzer(&DeckAnalyzer{})
 }
 
 // Type aliases for shared types (universal across all card games)
@@ -94,7 +96,7 @@ type CollectionTypeDeck struct {
 	Player    string `json:"player,omitempty"`
 	// Tournament metadata (from YGOPRODeck tournament section)
 	Event     string `json:"event,omitempty"`     // Tournament name
-	Placement string `json:"placement,omitempty"` // "Top 16", "Winner", etc.
+	Placement *int   `json:"placement,omitempty"` // Finishing position (1 = 1st place), nil when unknown
 	EventDate string `json:"eventDate,omitempty"` // Tournament date
 
 	// Enhanced tournament metadata
@@ -115,6 +117,24 @@ type CollectionTypeDeck struct {
 	RoundResults []RoundResult `json:"roundResults,omitempty"`
 }
 
+// UnmarshalJSON decodes Placement with games.UnmarshalPlacement
+func (ct *CollectionTypeDeck) UnmarshalJSON(b []byte) error {
+	type plain CollectionTypeDeck
+	aux := struct {
+		*plain
+		Placement json.RawMessage `json:"placement,omitempty"`
+	}{plain: (*plain)(ct)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	placement, err := games.UnmarshalPlacement(aux.Placement)
+	if err != nil {
+		return err
+	}
+	ct.Placement = placement
+	return nil
+}
+
 // RoundResult represents a single round/match result
 type RoundResult struct {
 	RoundNumber  int    `json:"roundNumber"`
@@ -136,6 +156,18 @@ func (ct *CollectionTypeCollection) Type() string { return "YGOCollection" }
 func (ct *CollectionTypeDeck) IsCollectionType()       {}
 func (ct *CollectionTypeCollection) IsCollectionType() {}
 
+func (ct *CollectionTypeDeck) DeckMetadata() games.DeckMetadata {
+	return games.DeckMetadata{
+		Name:      ct.Name,
+		Archetype: ct.Archetype,
+		Format:    ct.Format,
+		Player:    ct.Player,
+		Event:     ct.Event,
+		EventDate: ct.EventDate,
+		Placement: ct.Placement,
+	}
+}
+
 // Standard partition names for Yu-Gi-Oh!
 const (
 	PartitionMain  = "Main Deck"
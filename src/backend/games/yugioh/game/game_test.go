@@ -42,6 +42,27 @@ func TestCollectionTypeMarshal(t *testing.T) {
 	}
 }
 
+func TestCollectionTypeDeckUnmarshalPlacement(t *testing.T) {
+	for data, want := range map[string]int{
+		`{"name":"Snake-Eye","format":"TCG","placement":4}`:          4,
+		`{"name":"Snake-Eye","format":"TCG","placement":"Top 16"}`:   16,
+		`{"name":"Snake-Eye","format":"TCG","placement":"Winner"}`:   1,
+		`{"name":"Snake-Eye","format":"TCG","placement":"Finalist"}`: 2,
+		`{"name":"Snake-Eye","format":"TCG"}`:                        0,
+	} {
+		var ct CollectionTypeDeck
+		if err := json.Unmarshal([]byte(data), &ct); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", data, err)
+		}
+		if ct.Name != "Snake-Eye" || ct.Format != "TCG" {
+			t.Errorf("Unmarshal(%s) = %+v, want the other fields kept", data, ct)
+		}
+		if (want == 0) != (ct.Placement == nil) || (ct.Placement != nil && *ct.Placement != want) {
+			t.Errorf("Unmarshal(%s) Placement = %v, want %d", data, ct.Placement, want)
+		}
+	}
+}
+
 func TestCardMarshal(t *testing.T) {
 	card := Card{
 		Name: "Blue-Eyes White Dragon",
@@ -0,0 +1,48 @@
+package game
+
+import "fmt"
+
+// Zone size limits for Yu-Gi-Oh! decks (Advanced/Traditional format)
+const (
+	MainDeckMin  = 40
+	MainDeckMax  = 60
+	ExtraDeckMax = 15
+	SideDeckMax  = 15
+)
+
+// ZoneSizes returns the card counts of a deck's Main, Extra and Side Deck
+// partitions
+func ZoneSizes(deck *Collection) (main, extra, side int) {
+	for _, p := range deck.Partitions {
+		n := 0
+		for _, c := range p.Cards {
+			n += c.Count
+		}
+		switch p.Name {
+		case PartitionMain:
+			main += n
+		case PartitionExtra:
+			extra += n
+		case PartitionSide:
+			side += n
+		}
+	}
+	return main, extra, side
+}
+
+// ZoneViolations describes each zone outside its size limits; empty for a
+// legal deck. A short Main Deck usually means the parser missed cards.
+func ZoneViolations(deck *Collection) []string {
+	main, extra, side := ZoneSizes(deck)
+	var violations []string
+	if main < MainDeckMin || main > MainDeckMax {
+		violations = append(violations, fmt.Sprintf("%d main deck cards, want %d-%d", main, MainDeckMin, MainDeckMax))
+	}
+	if extra > ExtraDeckMax {
+		violations = append(violations, fmt.Sprintf("%d extra deck cards, want at most %d", extra, ExtraDeckMax))
+	}
+	if side > SideDeckMax {
+		violations = append(violations, fmt.Sprintf("%d side deck cards, want at most %d", side, SideDeckMax))
+	}
+	return violations
+}
@@ -0,0 +1,28 @@
+package game
+
+import (
+	"testing"
+)
+
+func ygoDeck(main, extra, side int) *Collection {
+	return &Collection{Partitions: []Partition{
+		{Name: PartitionMain, Cards: []CardDesc{{Name: "Ash Blossom & Joyous Spring", Count: 3}, {Name: "Snake-Eye Ash", Count: main - 3}}},
+		{Name: PartitionExtra, Cards: []CardDesc{{Name: "I:P Masquerena", Count: extra}}},
+		{Name: PartitionSide, Cards: []CardDesc{{Name: "Droll & Lock Bird", Count: side}}},
+	}}
+}
+
+func TestZoneViolationsLegalDeck(t *testing.T) {
+	for _, deck := range []*Collection{ygoDeck(40, 15, 15), ygoDeck(60, 0, 0)} {
+		if v := ZoneViolations(deck); len(v) != 0 {
+			t.Errorf("ZoneViolations(%v) = %v, want none", deck.Partitions, v)
+		}
+	}
+}
+
+func TestZoneViolationsOversizedExtraDeck(t *testing.T) {
+	v := ZoneViolations(ygoDeck(40, 16, 0))
+	if want := "16 extra deck cards, want at most 15"; len(v) != 1 || v[0] != want {
+		t.Errorf("ZoneViolations() = %q, want [%q]", v, want)
+	}
+}
@@ -0,0 +1,86 @@
+package graphio
+
+import "container/heap"
+
+// EdgeFilter selects the edges NewFilterWriter passes on. MinCount drops
+// edges with CountSet below it; TopN then keeps the N edges with the
+// highest CountSet. Zero disables either.
+type EdgeFilter struct {
+	MinCount int64
+	TopN     int
+}
+
+// NewFilterWriter returns an EdgeWriter passing the edges filter selects
+// on to w. Without TopN edges stream straight through; with it they are
+// held in a heap of at most TopN edges and written on Close in SortEdges
+// order, so memory stays proportional to TopN. Close also closes w.
+func NewFilterWriter(w EdgeWriter, filter EdgeFilter) EdgeWriter {
+	return &filterWriter{w: w, filter: filter}
+}
+
+type filterWriter struct {
+	w      EdgeWriter
+	filter EdgeFilter
+	top    edgeHeap
+}
+
+func (f *filterWriter) Write(e Edge) error {
+	if e.CountSet < f.filter.MinCount {
+		return nil
+	}
+	if f.filter.TopN <= 0 {
+		return f.w.Write(e)
+	}
+	if len(f.top) < f.filter.TopN {
+		heap.Push(&f.top, e)
+	} else if rankLess(f.top[0], e) {
+		f.top[0] = e
+		heap.Fix(&f.top, 0)
+	}
+	return nil
+}
+
+func (f *filterWriter) Close() error {
+	if f.filter.TopN > 0 {
+		edges := []Edge(f.top)
+		f.top = nil
+		SortEdges(edges)
+		for _, e := range edges {
+			if err := f.w.Write(e); err != nil {
+				return err
+			}
+		}
+	}
+	return f.w.Close()
+}
+
+// rankLess reports whether a ranks below b for TopN: by CountSet, then
+// CountMultiset, then card names (earlier names rank higher), so the
+// selection does not depend on input order
+func rankLess(a, b Edge) bool {
+	if a.CountSet != b.CountSet {
+		return a.CountSet < b.CountSet
+	}
+	if a.CountMultiset != b.CountMultiset {
+		return a.CountMultiset < b.CountMultiset
+	}
+	if a.Card1 != b.Card1 {
+		return a.Card1 > b.Card1
+	}
+	return a.Card2 > b.Card2
+}
+
+// edgeHeap is a min-heap by rankLess, so the lowest ranked kept edge is
+// at the root
+type edgeHeap []Edge
+
+func (h edgeHeap) Len() int           { return len(h) }
+func (h edgeHeap) Less(i, j int) bool { return rankLess(h[i], h[j]) }
+func (h edgeHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *edgeHeap) Push(x any)        { *h = append(*h, x.(Edge)) }
+func (h *edgeHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
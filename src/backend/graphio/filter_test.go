@@ -0,0 +1,112 @@
+package graphio
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// memWriter collects written edges
+type memWriter struct {
+	edges  []Edge
+	closed bool
+}
+
+func (m *memWriter) Write(e Edge) error { m.edges = append(m.edges, e); return nil }
+func (m *memWriter) Close() error       { m.closed = true; return nil }
+
+func TestFilterWriterSubset(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var all []Edge
+	for i := 0; i < 500; i++ {
+		all = append(all, Edge{
+			Card1:         fmt.Sprintf("Card %03d", i),
+			Card2:         fmt.Sprintf("Card %03d", i+1),
+			CountSet:      int64(rng.Intn(50)),
+			CountMultiset: int64(rng.Intn(200)),
+		})
+	}
+	SortEdges(all)
+	index := make(map[[2]string]Edge, len(all))
+	for _, e := range all {
+		index[[2]string{e.Card1, e.Card2}] = e
+	}
+
+	filter := func(f EdgeFilter, edges []Edge) []Edge {
+		t.Helper()
+		m := &memWriter{}
+		w := NewFilterWriter(m, f)
+		for _, e := range edges {
+			if err := w.Write(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if !m.closed {
+			t.Error("Close() did not close the wrapped writer")
+		}
+		return m.edges
+	}
+
+	tests := []EdgeFilter{
+		{MinCount: 10},
+		{TopN: 25},
+		{MinCount: 45, TopN: 1000},
+		{MinCount: 20, TopN: 25},
+	}
+	for _, f := range tests {
+		got := filter(f, all)
+
+		want := 0
+		for _, e := range all {
+			if e.CountSet >= f.MinCount {
+				want++
+			}
+		}
+		if f.TopN > 0 {
+			want = min(want, f.TopN)
+		}
+		if len(got) != want {
+			t.Errorf("%+v: kept %d edges, want %d", f, len(got), want)
+		}
+
+		// A subset of the input, in the same order
+		for i, e := range got {
+			if index[[2]string{e.Card1, e.Card2}] != e {
+				t.Errorf("%+v: edge %v not in the unfiltered output", f, e)
+			}
+			if e.CountSet < f.MinCount {
+				t.Errorf("%+v: kept %v below the minimum count", f, e)
+			}
+			if i > 0 && !(got[i-1].Card1 < e.Card1 || got[i-1].Card1 == e.Card1 && got[i-1].Card2 < e.Card2) {
+				t.Errorf("%+v: output not in SortEdges order at %d", f, i)
+			}
+		}
+
+		if f.TopN > 0 && len(got) == f.TopN {
+			// Nothing dropped outranks anything kept
+			kept := make(map[[2]string]bool, len(got))
+			lowest := got[0]
+			for _, e := range got {
+				kept[[2]string{e.Card1, e.Card2}] = true
+				if rankLess(e, lowest) {
+					lowest = e
+				}
+			}
+			for _, e := range all {
+				if !kept[[2]string{e.Card1, e.Card2}] && e.CountSet >= f.MinCount && rankLess(lowest, e) {
+					t.Errorf("%+v: dropped %v outranking kept %v", f, e, lowest)
+				}
+			}
+		}
+	}
+
+	// Top-N does not depend on input order
+	shuffled := append([]Edge(nil), all...)
+	rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	if a, b := filter(EdgeFilter{TopN: 25}, all), filter(EdgeFilter{TopN: 25}, shuffled); fmt.Sprint(a) != fmt.Sprint(b) {
+		t.Error("top-N selection depends on input order")
+	}
+}
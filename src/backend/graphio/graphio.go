@@ -0,0 +1,344 @@
+// Package graphio writes card co-occurrence edges in the formats consumed
+// downstream: CSV (the historical default), JSONL, Parquet and GEXF.
+package graphio
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Edge is an undirected co-occurrence between two cards
+type Edge struct {
+	Card1         string `json:"name_1" parquet:"name_1"`
+	Card2         string `json:"name_2" parquet:"name_2"`
+	CountSet      int64  `json:"count_set" parquet:"count_set"`           // Collections containing both cards
+	CountMultiset int64  `json:"count_multiset" parquet:"count_multiset"` // Copy-weighted co-occurrences
+	// Weight is a derived edge weight (e.g. age-decayed co-occurrence). It
+	// is only written by writers created with OptWriterWeight.
+	Weight float64 `json:"weight,omitempty" parquet:"weight,optional"`
+}
+
+// Format is an output encoding for edges
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatJSONL   Format = "jsonl"
+	FormatParquet Format = "parquet"
+	FormatGEXF    Format = "gexf"
+)
+
+// Formats lists the supported formats, for flag help text
+var Formats = []Format{FormatCSV, FormatJSONL, FormatParquet, FormatGEXF}
+
+// ParseFormat validates a --output-format value
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range Formats {
+		if f == known {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q (supported: csv, jsonl, parquet, gexf)", s)
+}
+
+// FormatFromPath infers the format from an output file's extension,
+// defaulting to CSV
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	case ".parquet":
+		return FormatParquet
+	case ".gexf":
+		return FormatGEXF
+	default:
+		return FormatCSV
+	}
+}
+
+// ResolveFormat returns the format named by flag, or the one implied by
+// path when flag is empty
+func ResolveFormat(flag, path string) (Format, error) {
+	if flag == "" {
+		return FormatFromPath(path), nil
+	}
+	return ParseFormat(flag)
+}
+
+// WriterOption configures NewEdgeWriter and Create
+type WriterOption interface {
+	writerOption()
+}
+
+// OptWriterWeight adds Edge.Weight to the output: a WEIGHT column in CSV, a
+// weight attribute in GEXF. JSONL and Parquet always carry the field and
+// leave it empty when unset.
+type OptWriterWeight struct{}
+
+func (o *OptWriterWeight) writerOption() {}
+
+// OptWriterRowGroupSize caps Parquet row groups at Rows edges, so readers
+// and the writer's buffers stay bounded on large graphs. Other formats
+// ignore it.
+type OptWriterRowGroupSize struct {
+	Rows int64
+}
+
+func (o *OptWriterRowGroupSize) writerOption() {}
+
+// EdgeWriter writes edges in a single format. Close flushes buffered
+// output but does not close the underlying writer.
+type EdgeWriter interface {
+	Write(e Edge) error
+	Close() error
+}
+
+// NewEdgeWriter returns an EdgeWriter encoding to w
+func NewEdgeWriter(w io.Writer, format Format, options ...WriterOption) (EdgeWriter, error) {
+	weight := false
+	var parquetOpts []parquet.WriterOption
+	for _, opt := range options {
+		switch opt := opt.(type) {
+		case *OptWriterWeight:
+			weight = true
+		case *OptWriterRowGroupSize:
+			parquetOpts = append(parquetOpts, parquet.MaxRowsPerRowGroup(opt.Rows))
+		default:
+			panic(fmt.Sprintf("invalid writer option: %T", opt))
+		}
+	}
+
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		header := []string{"NAME_1", "NAME_2", "COUNT_SET", "COUNT_MULTISET"}
+		if weight {
+			header = append(header, "WEIGHT")
+		}
+		if err := cw.Write(header); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: cw, weight: weight}, nil
+	case FormatJSONL:
+		bw := bufio.NewWriter(w)
+		return &jsonlWriter{bw: bw, enc: json.NewEncoder(bw)}, nil
+	case FormatParquet:
+		return &parquetWriter{w: parquet.NewGenericWriter[Edge](w, parquetOpts...)}, nil
+	case FormatGEXF:
+		return &gexfWriter{w: w, nodes: make(map[string]bool), weight: weight}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// fileEdgeWriter closes the output file after the encoder
+type fileEdgeWriter struct {
+	EdgeWriter
+	f *os.File
+}
+
+func (w *fileEdgeWriter) Close() error {
+	if err := w.EdgeWriter.Close(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// Create creates path and returns an EdgeWriter for it. Closing the
+// writer closes the file.
+func Create(path string, format Format, options ...WriterOption) (EdgeWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewEdgeWriter(f, format, options...)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &fileEdgeWriter{EdgeWriter: w, f: f}, nil
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	weight bool
+}
+
+func (w *csvWriter) Write(e Edge) error {
+	row := []string{
+		e.Card1,
+		e.Card2,
+		strconv.FormatInt(e.CountSet, 10),
+		strconv.FormatInt(e.CountMultiset, 10),
+	}
+	if w.weight {
+		row = append(row, strconv.FormatFloat(e.Weight, 'g', -1, 64))
+	}
+	return w.w.Write(row)
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type jsonlWriter struct {
+	bw  *bufio.Writer
+	enc *json.Encoder
+}
+
+func (w *jsonlWriter) Write(e Edge) error {
+	return w.enc.Encode(e)
+}
+
+func (w *jsonlWriter) Close() error {
+	return w.bw.Flush()
+}
+
+type parquetWriter struct {
+	w *parquet.GenericWriter[Edge]
+}
+
+func (w *parquetWriter) Write(e Edge) error {
+	_, err := w.w.Write([]Edge{e})
+	return err
+}
+
+func (w *parquetWriter) Close() error {
+	return w.w.Close()
+}
+
+// gexfWriter buffers edges because GEXF lists every node before any edge
+type gexfWriter struct {
+	w      io.Writer
+	weight bool
+	nodes  map[string]bool
+	order  []string
+	edges  []Edge
+}
+
+func (w *gexfWriter) Write(e Edge) error {
+	for _, name := range []string{e.Card1, e.Card2} {
+		if !w.nodes[name] {
+			w.nodes[name] = true
+			w.order = append(w.order, name)
+		}
+	}
+	w.edges = append(w.edges, e)
+	return nil
+}
+
+// GEXF document structure; node ids are the card names
+type gexfDoc struct {
+	XMLName xml.Name  `xml:"gexf"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string         `xml:"defaultedgetype,attr"`
+	Attributes      gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode     `xml:"nodes>node"`
+	Edges           []gexfEdge     `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	ID    string `xml:"id,attr"`
+	Label string `xml:"label,attr"`
+}
+
+type gexfEdge struct {
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	Weight    int64          `xml:"weight,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+func (w *gexfWriter) Close() error {
+	doc := gexfDoc{
+		XMLNS:   "http://gexf.net/1.3",
+		Version: "1.3",
+		Graph: gexfGraph{
+			DefaultEdgeType: "undirected",
+			Attributes: gexfAttributes{
+				Class: "edge",
+				Attributes: []gexfAttribute{
+					{ID: "count_multiset", Title: "count_multiset", Type: "long"},
+				},
+			},
+		},
+	}
+	if w.weight {
+		doc.Graph.Attributes.Attributes = append(doc.Graph.Attributes.Attributes,
+			gexfAttribute{ID: "weight", Title: "weight", Type: "double"})
+	}
+	for _, name := range w.order {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gexfNode{ID: name, Label: name})
+	}
+	for i, e := range w.edges {
+		ge := gexfEdge{
+			ID:     strconv.Itoa(i),
+			Source: e.Card1,
+			Target: e.Card2,
+			Weight: e.CountSet,
+			AttValues: []gexfAttValue{
+				{For: "count_multiset", Value: strconv.FormatInt(e.CountMultiset, 10)},
+			},
+		}
+		if w.weight {
+			ge.AttValues = append(ge.AttValues, gexfAttValue{For: "weight", Value: strconv.FormatFloat(e.Weight, 'g', -1, 64)})
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, ge)
+	}
+
+	if _, err := io.WriteString(w.w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w.w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// SortEdges orders edges by card names for deterministic output
+func SortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Card1 != edges[j].Card1 {
+			return edges[i].Card1 < edges[j].Card1
+		}
+		return edges[i].Card2 < edges[j].Card2
+	})
+}
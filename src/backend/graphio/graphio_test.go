@@ -0,0 +1,187 @@
+package graphio
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+var testEdges = []Edge{
+	{Card1: "Brainstorm", Card2: "Ponder", CountSet: 12, CountMultiset: 160},
+	{Card1: "Fire // Ice", Card2: "Lightning Bolt", CountSet: 3, CountMultiset: 9},
+	{Card1: "Lightning Bolt", Card2: "Mountain", CountSet: 40, CountMultiset: 3200},
+}
+
+func readCSV(t *testing.T, path string) []Edge {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[NAME_1 NAME_2 COUNT_SET COUNT_MULTISET]"; fmt.Sprint(rows[0]) != want {
+		t.Errorf("header = %v, want %v", rows[0], want)
+	}
+	var edges []Edge
+	for _, row := range rows[1:] {
+		set, _ := strconv.ParseInt(row[2], 10, 64)
+		multiset, _ := strconv.ParseInt(row[3], 10, 64)
+		edges = append(edges, Edge{Card1: row[0], Card2: row[1], CountSet: set, CountMultiset: multiset})
+	}
+	return edges
+}
+
+func readJSONL(t *testing.T, path string) []Edge {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var edges []Edge
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e Edge
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		edges = append(edges, e)
+	}
+	return edges
+}
+
+func readParquet(t *testing.T, path string) []Edge {
+	edges, err := parquet.ReadFile[Edge](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return edges
+}
+
+func readGEXF(t *testing.T, path string) []Edge {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc gexfDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	nodes := make(map[string]bool)
+	for _, n := range doc.Graph.Nodes {
+		nodes[n.ID] = true
+	}
+	var edges []Edge
+	for _, e := range doc.Graph.Edges {
+		if !nodes[e.Source] || !nodes[e.Target] {
+			t.Errorf("edge %s references undeclared node", e.ID)
+		}
+		multiset, _ := strconv.ParseInt(e.AttValues[0].Value, 10, 64)
+		edges = append(edges, Edge{Card1: e.Source, Card2: e.Target, CountSet: e.Weight, CountMultiset: multiset})
+	}
+	return edges
+}
+
+func TestEdgeWriterFormats(t *testing.T) {
+	readers := map[Format]func(*testing.T, string) []Edge{
+		FormatCSV:     readCSV,
+		FormatJSONL:   readJSONL,
+		FormatParquet: readParquet,
+		FormatGEXF:    readGEXF,
+	}
+	dir := t.TempDir()
+	for _, format := range Formats {
+		t.Run(string(format), func(t *testing.T) {
+			path := filepath.Join(dir, "graph."+string(format))
+			w, err := Create(path, format)
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			for _, e := range testEdges {
+				if err := w.Write(e); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			got := readers[format](t, path)
+			if fmt.Sprint(got) != fmt.Sprint(testEdges) {
+				t.Errorf("read back %v, want %v", got, testEdges)
+			}
+		})
+	}
+}
+
+func TestParquetRowGroupSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graph.parquet")
+	w, err := Create(path, FormatParquet, &OptWriterRowGroupSize{Rows: 2})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	for _, e := range testEdges {
+		if err := w.Write(e); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf, err := parquet.OpenFile(f, info.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(pf.RowGroups()); got != 2 {
+		t.Errorf("row groups = %d, want 2", got)
+	}
+	if got := readParquet(t, path); fmt.Sprint(got) != fmt.Sprint(testEdges) {
+		t.Errorf("read back %v, want %v", got, testEdges)
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	tests := []struct {
+		flag, path string
+		want       Format
+		wantErr    bool
+	}{
+		{path: "out.csv", want: FormatCSV},
+		{path: "out.jsonl", want: FormatJSONL},
+		{path: "out.PARQUET", want: FormatParquet},
+		{path: "out.gexf", want: FormatGEXF},
+		{path: "out", want: FormatCSV},
+		{flag: "parquet", path: "out.csv", want: FormatParquet},
+		{flag: "xlsx", path: "out.csv", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ResolveFormat(tt.flag, tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ResolveFormat(%q, %q) error = %v, wantErr %v", tt.flag, tt.path, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ResolveFormat(%q, %q) = %q, want %q", tt.flag, tt.path, got, tt.want)
+		}
+	}
+}
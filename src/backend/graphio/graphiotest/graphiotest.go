@@ -0,0 +1,76 @@
+// Package graphiotest builds the collections and collection trees the
+// graph export tests count pairs over.
+package graphiotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"collections/games/magic/game"
+
+	"github.com/DataDog/zstd"
+)
+
+// Collection is a collection of type typ whose one "Main" partition holds
+// count copies of each of names
+func Collection(typ game.CollectionType, count int, names ...string) *game.Collection {
+	cards := make([]game.CardDesc, len(names))
+	for i, name := range names {
+		cards[i] = game.CardDesc{Name: name, Count: count}
+	}
+	return &game.Collection{
+		Type:       game.CollectionTypeWrapper{Type: typ.Type(), Inner: typ},
+		Partitions: []game.Partition{{Name: "Main", Cards: cards}},
+	}
+}
+
+// Deck is a Collection of a deck in format
+func Deck(format string, count int, names ...string) *game.Collection {
+	return Collection(&game.CollectionTypeDeck{Format: format}, count, names...)
+}
+
+// WriteCollectionTree writes n compressed collections of 30 cards drawn
+// from a shared pool into a temp dir and returns their paths, which are
+// also the order the dir is walked in. Every tenth collection is a cube;
+// the rest are Modern decks dated through 2023 and 2024.
+func WriteCollectionTree(tb testing.TB, n int) []string {
+	tb.Helper()
+	dir := tb.TempDir()
+	rng := rand.New(rand.NewSource(1))
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	files := make([]string, n)
+	for i := range files {
+		var typ game.CollectionType = &game.CollectionTypeDeck{
+			Format:    "Modern",
+			EventDate: start.AddDate(0, 0, rng.Intn(500)).Format("2006-01-02"),
+		}
+		if i%10 == 0 {
+			typ = &game.CollectionTypeCube{Name: "Vintage"}
+		}
+		col := Collection(typ, 1)
+		col.ID = fmt.Sprint(i)
+		cards := make([]game.CardDesc, 30)
+		for j := range cards {
+			cards[j] = game.CardDesc{Name: fmt.Sprintf("Card %03d", rng.Intn(200)), Count: 1 + rng.Intn(4)}
+		}
+		col.Partitions[0].Cards = cards
+
+		data, err := json.Marshal(col)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		if data, err = zstd.Compress(nil, data); err != nil {
+			tb.Fatal(err)
+		}
+		files[i] = filepath.Join(dir, fmt.Sprintf("%05d.json.zst", i))
+		if err := os.WriteFile(files[i], data, 0o644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return files
+}
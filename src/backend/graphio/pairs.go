@@ -0,0 +1,78 @@
+package graphio
+
+import "collections/games/magic/game"
+
+// Pair is an unordered pair of card names, ordered so Card1 <= Card2
+type Pair struct {
+	Card1 string
+	Card2 string
+}
+
+// MakePair returns the Pair of a and b in either order
+func MakePair(a, b string) Pair {
+	if a > b {
+		a, b = b, a
+	}
+	return Pair{Card1: a, Card2: b}
+}
+
+// PairCounts accumulates a pair's co-occurrences before it becomes an
+// Edge
+type PairCounts struct {
+	Set      int
+	Multiset int
+	Weight   float64 // multiset contributions scaled by the collection weight
+}
+
+// Add adds o's counts to c
+func (c *PairCounts) Add(o PairCounts) {
+	c.Set += o.Set
+	c.Multiset += o.Multiset
+	c.Weight += o.Weight
+}
+
+// MergePairs adds src's counts into dst, taking over src's entries for
+// pairs dst doesn't have
+func MergePairs(dst, src map[Pair]*PairCounts) {
+	for p, c := range src {
+		if d := dst[p]; d != nil {
+			d.Add(*c)
+		} else {
+			dst[p] = c
+		}
+	}
+}
+
+// AddCollectionPairs adds the co-occurrences within each of col's
+// partitions to pairs, scaling their weight by weight, and returns the
+// cards and edges seen. Pairs count copies multiplied together and a card
+// with n copies pairs with itself n-1 times, unless binary, when each
+// pair counts once and there are no self-pairs.
+func AddCollectionPairs(pairs map[Pair]*PairCounts, col *game.Collection, binary bool, weight float64) (int, int) {
+	cards, edges := 0, 0
+	add := func(p Pair, set, multiset int) {
+		c := pairs[p]
+		if c == nil {
+			c = &PairCounts{}
+			pairs[p] = c
+		}
+		c.Add(PairCounts{Set: set, Multiset: multiset, Weight: weight * float64(multiset)})
+		edges++
+	}
+	for _, partition := range col.Partitions {
+		cards += len(partition.Cards)
+		for i, c := range partition.Cards {
+			if c.Count > 1 && !binary {
+				add(MakePair(c.Name, c.Name), 0, c.Count-1)
+			}
+			for _, d := range partition.Cards[i+1:] {
+				multiset := 1
+				if !binary {
+					multiset = c.Count * d.Count
+				}
+				add(MakePair(c.Name, d.Name), 1, multiset)
+			}
+		}
+	}
+	return cards, edges
+}
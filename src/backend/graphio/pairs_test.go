@@ -0,0 +1,85 @@
+package graphio
+
+import (
+	"math"
+	"testing"
+
+	"collections/games/magic/game"
+	"collections/graphio/graphiotest"
+)
+
+func TestAddCollectionPairs(t *testing.T) {
+	singleton := graphiotest.Deck("", 1, "Sol Ring", "Counterspell")
+	playset := graphiotest.Deck("", 4, "Sol Ring", "Counterspell")
+	sideboarded := graphiotest.Deck("", 1, "Sol Ring", "Counterspell")
+	sideboarded.Partitions = append(sideboarded.Partitions, game.Partition{
+		Name:  "Sideboard",
+		Cards: []game.CardDesc{{Name: "Pyroblast", Count: 2}},
+	})
+	p := MakePair("Sol Ring", "Counterspell")
+	self := MakePair("Counterspell", "Counterspell")
+	cross := MakePair("Sol Ring", "Pyroblast")
+
+	type add struct {
+		col    *game.Collection
+		weight float64
+	}
+	tests := []struct {
+		name      string
+		binary    bool
+		adds      []add
+		want      map[Pair]PairCounts // pairs not listed must be absent
+		wantEdges int
+	}{
+		{
+			name:      "multiset",
+			adds:      []add{{singleton, 1}, {playset, 1}},
+			want:      map[Pair]PairCounts{p: {2, 17, 17}, self: {0, 3, 3}, MakePair("Sol Ring", "Sol Ring"): {0, 3, 3}},
+			wantEdges: 4,
+		},
+		{
+			name:      "binary",
+			binary:    true,
+			adds:      []add{{singleton, 1}, {playset, 1}},
+			want:      map[Pair]PairCounts{p: {2, 2, 2}},
+			wantEdges: 2,
+		},
+		{
+			name:      "weighted",
+			adds:      []add{{singleton, 1}, {playset, 0.25}},
+			want:      map[Pair]PairCounts{p: {2, 17, 5}, self: {0, 3, 0.75}, MakePair("Sol Ring", "Sol Ring"): {0, 3, 0.75}},
+			wantEdges: 4,
+		},
+		{
+			name:      "partitions pair separately",
+			adds:      []add{{sideboarded, 1}},
+			want:      map[Pair]PairCounts{p: {1, 1, 1}, MakePair("Pyroblast", "Pyroblast"): {0, 1, 1}},
+			wantEdges: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs := make(map[Pair]*PairCounts)
+			edges := 0
+			for _, a := range tt.adds {
+				_, n := AddCollectionPairs(pairs, a.col, tt.binary, a.weight)
+				edges += n
+			}
+			if edges != tt.wantEdges {
+				t.Errorf("edges = %d, want %d", edges, tt.wantEdges)
+			}
+			if len(pairs) != len(tt.want) {
+				t.Errorf("got %d pairs, want %d: %v", len(pairs), len(tt.want), pairs)
+			}
+			for pair, want := range tt.want {
+				got := pairs[pair]
+				if got == nil || got.Set != want.Set || got.Multiset != want.Multiset || math.Abs(got.Weight-want.Weight) > 1e-9 {
+					t.Errorf("%v = %+v, want %+v", pair, got, want)
+				}
+			}
+			if pairs[cross] != nil {
+				t.Errorf("%v paired across partitions", cross)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package graphio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ReadEdges reads every edge from r. CSV is read by header name, so files
+// with or without a WEIGHT column (or cardco's WEIGHT_SUM) work, and
+// fractional counts from weighted exports are rounded. GEXF cannot be
+// read back.
+func ReadEdges(r io.Reader, format Format) ([]Edge, error) {
+	switch format {
+	case FormatCSV:
+		return decodeCSV(r)
+	case FormatJSONL:
+		return decodeJSONL(r)
+	case FormatParquet:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return parquet.Read[Edge](bytes.NewReader(data), int64(len(data)))
+	default:
+		return nil, fmt.Errorf("cannot read edges from format %q", format)
+	}
+}
+
+// ReadFile reads every edge from path
+func ReadFile(path string, format Format) ([]Edge, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if format == FormatParquet {
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		return parquet.Read[Edge](f, info.Size())
+	}
+	return ReadEdges(bufio.NewReader(f), format)
+}
+
+func decodeCSV(r io.Reader) ([]Edge, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[strings.ToUpper(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"NAME_1", "NAME_2", "COUNT_SET"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("header has no %s column", name)
+		}
+	}
+	weightName := "WEIGHT"
+	weightCol, hasWeight := col[weightName]
+	if !hasWeight {
+		weightName = "WEIGHT_SUM"
+		weightCol, hasWeight = col[weightName]
+	}
+	multisetCol, hasMultiset := col["COUNT_MULTISET"]
+
+	var edges []Edge
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return edges, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		e := Edge{Card1: row[col["NAME_1"]], Card2: row[col["NAME_2"]]}
+		if e.CountSet, err = parseCount(row[col["COUNT_SET"]]); err != nil {
+			return nil, fmt.Errorf("line %d: COUNT_SET: %w", line, err)
+		}
+		if hasMultiset {
+			if e.CountMultiset, err = parseCount(row[multisetCol]); err != nil {
+				return nil, fmt.Errorf("line %d: COUNT_MULTISET: %w", line, err)
+			}
+		}
+		if hasWeight {
+			if e.Weight, err = strconv.ParseFloat(row[weightCol], 64); err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", line, weightName, err)
+			}
+		}
+		edges = append(edges, e)
+	}
+}
+
+// parseCount parses a whole or, from a weighted export, fractional count
+func parseCount(s string) (int64, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Round(f)), nil
+}
+
+func decodeJSONL(r io.Reader) ([]Edge, error) {
+	var edges []Edge
+	dec := json.NewDecoder(r)
+	for {
+		var e Edge
+		err := dec.Decode(&e)
+		if errors.Is(err, io.EOF) {
+			return edges, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("edge %d: %w", len(edges)+1, err)
+		}
+		edges = append(edges, e)
+	}
+}
@@ -0,0 +1,57 @@
+package graphio
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadFileRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatCSV, FormatJSONL, FormatParquet} {
+		t.Run(string(format), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "edges."+string(format))
+			w, err := Create(path, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range testEdges {
+				if err := w.Write(e); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadFile(path, format)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(testEdges) {
+				t.Errorf("ReadFile() = %v, want %v", got, testEdges)
+			}
+		})
+	}
+}
+
+func TestReadEdgesCSVColumns(t *testing.T) {
+	// A weighted cardco export: fractional counts and WEIGHT_SUM
+	const in = "NAME_1,NAME_2,COUNT_SET,COUNT_MULTISET,WEIGHT_SUM\nLightning Bolt,Mountain,1.5,120,1.5\n"
+	got, err := ReadEdges(strings.NewReader(in), FormatCSV)
+	if err != nil {
+		t.Fatalf("ReadEdges() error = %v", err)
+	}
+	want := []Edge{{Card1: "Lightning Bolt", Card2: "Mountain", CountSet: 2, CountMultiset: 120, Weight: 1.5}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("ReadEdges() = %v, want %v", got, want)
+	}
+
+	for _, in := range []string{"NAME_1,COUNT_SET\nA,1\n", "NAME_1,NAME_2,COUNT_SET\nA,B,x\n"} {
+		if _, err := ReadEdges(strings.NewReader(in), FormatCSV); err == nil {
+			t.Errorf("ReadEdges(%q) error = nil, want error", in)
+		}
+	}
+	if _, err := ReadEdges(strings.NewReader(""), FormatGEXF); err == nil {
+		t.Error("ReadEdges(gexf) error = nil, want error")
+	}
+}
@@ -0,0 +1,82 @@
+// Package progress reports the progress of long-running tools. On a
+// terminal each update redraws one status line in place with a carriage
+// return; when output is redirected to a file, as under cron or CI, updates
+// are throttled to one newline-terminated line per interval so logs stay
+// readable.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultInterval is how often a Reporter writing to a non-terminal logs
+// an update
+const DefaultInterval = 5 * time.Second
+
+// Reporter prints progress updates. It is safe for concurrent use.
+type Reporter struct {
+	mu       sync.Mutex
+	out      io.Writer
+	tty      bool
+	interval time.Duration
+	now      func() time.Time
+	lastLog  time.Time
+	last     string // Line currently drawn on a terminal
+}
+
+// New returns a Reporter writing to f, redrawing in place when f is a
+// terminal
+func New(f *os.File) *Reporter {
+	return NewWriter(f, IsTerminal(f), DefaultInterval)
+}
+
+// NewWriter returns a Reporter writing to w. With tty false it logs at
+// most one update per interval.
+func NewWriter(w io.Writer, tty bool, interval time.Duration) *Reporter {
+	return &Reporter{out: w, tty: tty, interval: interval, now: time.Now}
+}
+
+// IsTerminal reports whether f is a terminal (a character device)
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Update reports the current progress
+func (r *Reporter) Update(format string, args ...any) {
+	line := fmt.Sprintf(format, args...)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.tty {
+		now := r.now()
+		if !r.lastLog.IsZero() && now.Sub(r.lastLog) < r.interval {
+			return
+		}
+		r.lastLog = now
+		fmt.Fprintln(r.out, line)
+		return
+	}
+	// Pad over the rest of a longer previous line
+	if n := len(r.last) - len(line); n > 0 {
+		line += strings.Repeat(" ", n)
+	}
+	r.last = line
+	fmt.Fprint(r.out, "\r"+line)
+}
+
+// Done clears the line drawn on a terminal, so output that follows starts
+// on a clean line
+func (r *Reporter) Done() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tty && r.last != "" {
+		fmt.Fprint(r.out, "\r"+strings.Repeat(" ", len(r.last))+"\r")
+		r.last = ""
+	}
+}
@@ -0,0 +1,42 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReporterNonTTYLogsPeriodicLines(t *testing.T) {
+	var out bytes.Buffer
+	r := NewWriter(&out, false, 5*time.Second)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	r.Update("done %d", 1)
+	now = now.Add(2 * time.Second)
+	r.Update("done %d", 2) // Within the interval: dropped
+	now = now.Add(3 * time.Second)
+	r.Update("done %d", 3)
+	now = now.Add(time.Minute)
+	r.Update("done %d", 4)
+	r.Done()
+
+	if got, want := out.String(), "done 1\ndone 3\ndone 4\n"; got != want {
+		t.Errorf("non-TTY output = %q, want %q", got, want)
+	}
+	if strings.Contains(out.String(), "\r") {
+		t.Error("non-TTY output contains a carriage return")
+	}
+}
+
+func TestReporterTTYRedrawsInPlace(t *testing.T) {
+	var out bytes.Buffer
+	r := NewWriter(&out, true, time.Hour)
+	r.Update("Progress: 10/20")
+	r.Update("Progress: 2/20")
+	r.Done()
+	if got, want := out.String(), "\rProgress: 10/20\rProgress: 2/20 \r               \r"; got != want {
+		t.Errorf("TTY output = %q, want %q", got, want)
+	}
+}
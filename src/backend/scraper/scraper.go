@@ -2,6 +2,9 @@ package scraper
 
 import (
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"crypto/sha256"
 	"encoding/base64"
@@ -14,6 +17,7 @@ import (
 	"math/rand"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -31,6 +35,8 @@ import (
 var veryStart = time.Now()
 var requests atomic.Uint64
 
+// SCRAPER_RATE_LIMIT sets the process-wide default rate limit, such as
+// "100/m" or "none". ScraperConfig.RateLimit overrides it per scraper.
 var envRateLimit = "SCRAPER_RATE_LIMIT"
 var rateLimitOverride ratelimit.Limiter
 
@@ -73,10 +79,41 @@ type Scraper struct {
 	blob       *blob.Bucket
 }
 
+// ScraperConfig configures a Scraper
+type ScraperConfig struct {
+	// RateLimit limits every request the scraper makes, overriding
+	// SCRAPER_RATE_LIMIT and any OptDoLimiter. Nil uses SCRAPER_RATE_LIMIT
+	// when it is set and the per-request limiters otherwise.
+	RateLimit ratelimit.Limiter
+	// HostRateLimits limits requests to each hostname (without a port, as
+	// in "api.scryfall.com"), taking precedence over RateLimit,
+	// SCRAPER_RATE_LIMIT and the datasets' own OptDoLimiter, so one
+	// scraper shared by several datasets throttles each site on its own.
+	// Requests to other hosts fall back to those.
+	HostRateLimits map[string]ratelimit.Limiter
+}
+
 func NewScraper(
 	log *logger.Logger,
 	blob *blob.Bucket,
 ) *Scraper {
+	return NewScraperWithConfig(log, blob, ScraperConfig{})
+}
+
+// NewScraperWithConfig creates a scraper with a custom configuration
+func NewScraperWithConfig(
+	log *logger.Logger,
+	blob *blob.Bucket,
+	cfg ScraperConfig,
+) *Scraper {
+	limiter := cfg.RateLimit
+	if limiter == nil {
+		limiter = rateLimitOverride
+	}
+	hostLimiters := make(map[string]ratelimit.Limiter, len(cfg.HostRateLimits))
+	for host, l := range cfg.HostRateLimits {
+		hostLimiters[strings.ToLower(host)] = l
+	}
 	httpClient := retryablehttp.NewClient()
 
 	// Configure HTTP client with timeout and connection pooling
@@ -93,8 +130,10 @@ func NewScraper(
 
 	httpClient.Logger = newLeveledLogger(log)
 	httpClient.RequestLogHook = func(_ retryablehttp.Logger, req *http.Request, i int) {
-		if rateLimitOverride != nil {
-			rateLimitOverride.Take()
+		if l, ok := hostLimiters[strings.ToLower(req.URL.Hostname())]; ok {
+			l.Take()
+		} else if limiter != nil {
+			limiter.Take()
 		} else {
 			val, ok := req.Context().Value(ctxKeyLimiter{}).(ctxValLimiter)
 			if ok {
@@ -133,6 +172,17 @@ func (e *ErrFetchThrottled) Error() string {
 	return "fetch throtted"
 }
 
+// ErrResponseTooLarge is returned when a response body exceeds the size
+// limit. The body is not read past the limit.
+type ErrResponseTooLarge struct {
+	URL   string
+	Limit int64
+}
+
+func (e *ErrResponseTooLarge) Error() string {
+	return fmt.Sprintf("response too large: %s exceeds %d bytes", e.URL, e.Limit)
+}
+
 func (s *Scraper) Do(
 	ctx context.Context,
 	req *http.Request,
@@ -143,10 +193,16 @@ func (s *Scraper) Do(
 	replace := false
 	var reSilentThrottle *regexp.Regexp
 	var limiter Limiter
+	storage := BodyStorageInline
+	var maxBytes int64
 	for _, opt := range options {
 		switch opt := opt.(type) {
 		case *OptDoReplace:
 			replace = true
+		case *OptDoBodyStorage:
+			storage = opt.Storage
+		case *OptDoMaxBytes:
+			maxBytes = opt.N
 		case *OptDoSilentThrottle:
 			reSilentThrottle = opt.PageBytesRegexp
 		case *OptDoLimiter:
@@ -162,16 +218,11 @@ func (s *Scraper) Do(
 	}
 
 	if !replace {
-		b, err := s.blob.Read(ctx, bkey)
-		errNoExist := &blob.ErrNotFound{}
-		if !errors.As(err, &errNoExist) {
-			if err != nil {
-				return nil, fmt.Errorf("failed to read from blob: %w", err)
-			}
-			page := new(Page)
-			if err := json.Unmarshal(b, page); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal page: %w", err)
-			}
+		page, err := s.readPage(ctx, bkey)
+		if err != nil {
+			return nil, err
+		}
+		if page != nil {
 			if err := errPageStatusNotOK(page); err != nil {
 				return nil, err
 			}
@@ -208,14 +259,18 @@ func (s *Scraper) Do(
 	}
 	// Use larger limit for bulk data operations (like Scryfall bulk download)
 	// Regular pages should be much smaller, but bulk JSON can be 100MB+
-	// Configurable via SCRAPER_MAX_RESPONSE_SIZE_MB env var (default 200MB)
-	maxResponseSizeMB := 200
-	if envSize := os.Getenv("SCRAPER_MAX_RESPONSE_SIZE_MB"); envSize != "" {
-		if parsed, err := strconv.Atoi(envSize); err == nil && parsed > 0 {
-			maxResponseSizeMB = parsed
+	// Configurable via SCRAPER_MAX_RESPONSE_SIZE_MB env var (default 200MB),
+	// or per request with OptDoMaxBytes
+	maxResponseSize := maxBytes
+	if maxResponseSize <= 0 {
+		maxResponseSizeMB := 200
+		if envSize := os.Getenv("SCRAPER_MAX_RESPONSE_SIZE_MB"); envSize != "" {
+			if parsed, err := strconv.Atoi(envSize); err == nil && parsed > 0 {
+				maxResponseSizeMB = parsed
+			}
 		}
+		maxResponseSize = int64(maxResponseSizeMB) * 1024 * 1024
 	}
-	maxResponseSize := int64(maxResponseSizeMB) * 1024 * 1024
 	for i := 0; i < attemptsMax; i++ {
 		// Check context cancellation before each retry attempt
 		select {
@@ -231,7 +286,7 @@ func (s *Scraper) Do(
 		body, err = io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
 		resp.Body.Close()
 		if int64(len(body)) > maxResponseSize {
-			return nil, fmt.Errorf("response too large: %d bytes (max %d MB)", len(body), maxResponseSizeMB)
+			return nil, &ErrResponseTooLarge{URL: req.URL.String(), Limit: maxResponseSize}
 		}
 		lastAttempt := i >= attemptsMax-1
 		if err != nil {
@@ -242,6 +297,26 @@ func (s *Scraper) Do(
 			wait(i)
 			continue
 		}
+		body, err = decodeBody(resp, body, maxResponseSize)
+		if err != nil {
+			var errTooLarge *ErrResponseTooLarge
+			if errors.As(err, &errTooLarge) {
+				errTooLarge.URL = req.URL.String()
+				return nil, errTooLarge
+			}
+			// Refetching returns the same encoding, so don't retry
+			var errEncoding *ErrUnsupportedEncoding
+			if errors.As(err, &errEncoding) {
+				errEncoding.URL = req.URL.String()
+				return nil, errEncoding
+			}
+			if lastAttempt {
+				return nil, fmt.Errorf("failed to decode http resp body: %w", err)
+			}
+			s.log.Fieldf("attempt", "%d", i).Warnf(ctx, "failed to decode http resp body, retrying: %v", err)
+			wait(i)
+			continue
+		}
 		if reSilentThrottle != nil && reSilentThrottle.Match(body) {
 			n := requests.Load()
 			rate := float64(n) / (float64(time.Since(veryStart).Minutes()))
@@ -281,12 +356,8 @@ func (s *Scraper) Do(
 			Body:       body,
 		},
 	}
-	b, err := json.Marshal(page)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal page: %w", err)
-	}
-	if err := s.blob.Write(ctx, bkey, b); err != nil {
-		return nil, fmt.Errorf("failed to write page: %w", err)
+	if err := s.writePage(ctx, bkey, page, storage); err != nil {
+		return nil, err
 	}
 	if err := errPageStatusNotOK(page); err != nil {
 		return nil, err
@@ -301,6 +372,148 @@ func (s *Scraper) Do(
 	return page, nil
 }
 
+// ErrUnsupportedEncoding is returned when a response has a Content-Encoding
+// the scraper cannot decode. It is not retried.
+type ErrUnsupportedEncoding struct {
+	URL      string
+	Encoding string
+}
+
+func (e *ErrUnsupportedEncoding) Error() string {
+	return fmt.Sprintf("unsupported content encoding %q: %s", e.Encoding, e.URL)
+}
+
+// decodeBody undoes a gzip or deflate Content-Encoding the transport left in
+// place, which happens when the request sets Accept-Encoding itself. HTML
+// responses that still start with the gzip magic number are decoded too, so
+// the stored body is always parseable text. The encoding headers are removed
+// from resp once the body is decoded.
+func decodeBody(resp *http.Response, body []byte, limit int64) ([]byte, error) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if resp.Uncompressed {
+		encoding = ""
+	}
+	if encoding == "" && isHTML(resp.Header) && isGzip(body) {
+		encoding = "gzip"
+	}
+
+	var r io.Reader
+	switch encoding {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	case "deflate":
+		// Servers disagree on whether deflate means zlib-wrapped or raw
+		zr, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			r = flate.NewReader(bytes.NewReader(body))
+		} else {
+			defer zr.Close()
+			r = zr
+		}
+	default:
+		return nil, &ErrUnsupportedEncoding{Encoding: encoding}
+	}
+
+	decoded, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(decoded)) > limit {
+		return nil, &ErrResponseTooLarge{Limit: limit}
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	return decoded, nil
+}
+
+func isHTML(header http.Header) bool {
+	return strings.Contains(strings.ToLower(header.Get("Content-Type")), "text/html")
+}
+
+func isGzip(body []byte) bool {
+	return len(body) >= 2 && body[0] == 0x1f && body[1] == 0x8b
+}
+
+// readPage returns the cached page for bkey, or nil if there is no usable
+// cached copy. A page whose body was discarded, or whose raw body has since
+// been deleted, counts as a miss so the caller refetches it.
+// ReadPage returns the page cached under bkey, a key in the scraper's
+// bucket such as one listed from it, with a raw body loaded. It returns nil
+// when the page or its body is not cached.
+func (s *Scraper) ReadPage(ctx context.Context, bkey string) (*Page, error) {
+	return s.readPage(ctx, bkey)
+}
+
+func (s *Scraper) readPage(ctx context.Context, bkey string) (*Page, error) {
+	b, err := s.blob.Read(ctx, bkey)
+	errNoExist := &blob.ErrNotFound{}
+	if errors.As(err, &errNoExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from blob: %w", err)
+	}
+	page := new(Page)
+	if err := json.Unmarshal(b, page); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal page: %w", err)
+	}
+	if page.Response.BodyDiscarded {
+		return nil, nil
+	}
+	if ref := page.Response.BodyRef; ref != "" {
+		body, err := s.blob.Read(ctx, ref)
+		if errors.As(err, &errNoExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read raw body: %w", err)
+		}
+		page.Response.Body = body
+	}
+	return page, nil
+}
+
+// writePage stores page under bkey according to storage. The page passed in
+// keeps its body either way.
+func (s *Scraper) writePage(ctx context.Context, bkey string, page *Page, storage BodyStorage) error {
+	stored := *page
+	switch storage {
+	case BodyStorageInline:
+	case BodyStorageRaw:
+		ref := rawBodyKey(bkey)
+		if err := s.blob.Write(ctx, ref, page.Response.Body); err != nil {
+			return fmt.Errorf("failed to write raw body: %w", err)
+		}
+		stored.Response.Body = nil
+		stored.Response.BodyRef = ref
+	case BodyStorageDiscard:
+		stored.Response.Body = nil
+		stored.Response.BodyDiscarded = true
+	default:
+		return fmt.Errorf("invalid body storage: %d", storage)
+	}
+	b, err := json.Marshal(&stored)
+	if err != nil {
+		return fmt.Errorf("failed to marshal page: %w", err)
+	}
+	if err := s.blob.Write(ctx, bkey, b); err != nil {
+		return fmt.Errorf("failed to write page: %w", err)
+	}
+	return nil
+}
+
+// rawBodyKey is where BodyStorageRaw keeps the response body for bkey
+func rawBodyKey(bkey string) string {
+	return path.Join(rawPrefix, strings.TrimSuffix(bkey, ".json"))
+}
+
 func (s *Scraper) blobKey(req *http.Request) (string, []byte, error) {
 	buf := new(bytes.Buffer)
 
@@ -363,6 +576,33 @@ type OptDoSilentThrottle struct {
 	PageBytesRegexp *regexp.Regexp
 }
 
+// BodyStorage controls where Do persists response bodies
+type BodyStorage int
+
+const (
+	// BodyStorageInline keeps the body inside the cached page (default)
+	BodyStorageInline BodyStorage = iota
+	// BodyStorageRaw keeps only metadata and a BodyRef in the cached page,
+	// with the body stored separately under the raw/ prefix. Deleting raw/
+	// drops the bodies; affected pages are refetched on the next Do.
+	BodyStorageRaw
+	// BodyStorageDiscard keeps only metadata. Cached pages without a body
+	// are refetched on the next Do.
+	BodyStorageDiscard
+)
+
+const rawPrefix = "raw"
+
+type OptDoBodyStorage struct {
+	Storage BodyStorage
+}
+
+// OptDoMaxBytes caps the response body at N bytes, overriding the
+// SCRAPER_MAX_RESPONSE_SIZE_MB default. Use a large N for bulk downloads.
+type OptDoMaxBytes struct {
+	N int64
+}
+
 type ctxKeyLimiter struct{}
 type ctxValLimiter struct {
 	Limiter Limiter
@@ -379,6 +619,8 @@ type Limiter interface {
 func (o *OptDoReplace) doOption()        {}
 func (o *OptDoSilentThrottle) doOption() {}
 func (o *OptDoLimiter) doOption()        {}
+func (o *OptDoBodyStorage) doOption()    {}
+func (o *OptDoMaxBytes) doOption()       {}
 
 var _ retryablehttp.LeveledLogger = (*leveledLogger)(nil)
 
@@ -438,4 +680,8 @@ type PageResponse struct {
 	StatusCode int         `json:"status_code"`
 	Header     http.Header `json:"header,omitempty"`
 	Body       []byte      `json:"body,omitempty"`
+	// BodyRef is the blob key of the body when stored with BodyStorageRaw
+	BodyRef string `json:"body_ref,omitempty"`
+	// BodyDiscarded is set when stored with BodyStorageDiscard
+	BodyDiscarded bool `json:"body_discarded,omitempty"`
 }
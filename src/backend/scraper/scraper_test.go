@@ -1,10 +1,21 @@
 package scraper
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"go.uber.org/ratelimit"
 
 	"collections/blob"
 	"collections/logger"
@@ -39,7 +50,7 @@ func TestResponseSizeLimit(t *testing.T) {
 		t.Fatalf("failed to create request: %v", err)
 	}
 
-	_, err = sc.Do(ctx, req)
+	_, err = sc.Do(ctx, req, &OptDoMaxBytes{N: 10 * 1024 * 1024})
 	if err == nil {
 		t.Error("Expected error for response exceeding size limit, got nil")
 	}
@@ -48,6 +59,58 @@ func TestResponseSizeLimit(t *testing.T) {
 	}
 }
 
+func TestOptDoMaxBytes(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(make([]byte, 2048))
+	}))
+	defer server.Close()
+
+	sc := NewScraper(log, bucket)
+
+	req, err := http.NewRequest("GET", server.URL+"/big", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	_, err = sc.Do(ctx, req, &OptDoMaxBytes{N: 1024})
+	errTooLarge := &ErrResponseTooLarge{}
+	if !errors.As(err, &errTooLarge) {
+		t.Fatalf("Do() error = %v, want ErrResponseTooLarge", err)
+	}
+	if errTooLarge.Limit != 1024 {
+		t.Errorf("Limit = %d, want 1024", errTooLarge.Limit)
+	}
+
+	// Nothing is cached for a rejected response
+	bkey, _, err := sc.blobKey(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := bucket.Exists(ctx, bkey); err != nil || ok {
+		t.Errorf("Exists() = %v, %v, want false", ok, err)
+	}
+
+	// The same response fits a larger limit
+	req2, err := http.NewRequest("GET", server.URL+"/big", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	page, err := sc.Do(ctx, req2, &OptDoMaxBytes{N: 4096})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if len(page.Response.Body) != 2048 {
+		t.Errorf("body length = %d, want 2048", len(page.Response.Body))
+	}
+}
+
 func TestResponseSizeWithinLimit(t *testing.T) {
 	ctx := context.Background()
 	log := logger.NewLogger(ctx)
@@ -141,3 +204,359 @@ func TestCaching(t *testing.T) {
 		t.Error("Cached response should match original")
 	}
 }
+
+func TestBodyStorage(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	body := []byte("<html>deck</html>")
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	readStored := func(t *testing.T, bucket *blob.Bucket, sc *Scraper, req *http.Request) *Page {
+		t.Helper()
+		bkey, _, err := sc.blobKey(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := bucket.Read(ctx, bkey)
+		if err != nil {
+			t.Fatalf("failed to read stored page: %v", err)
+		}
+		page := new(Page)
+		if err := json.Unmarshal(b, page); err != nil {
+			t.Fatal(err)
+		}
+		return page
+	}
+
+	t.Run("inline", func(t *testing.T) {
+		bucket := blob.NewMemBucket(ctx, log)
+		defer bucket.Close(ctx)
+		sc := NewScraper(log, bucket)
+
+		req, _ := http.NewRequest("GET", server.URL+"/inline", nil)
+		if _, err := sc.Do(ctx, req); err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		stored := readStored(t, bucket, sc, req)
+		if string(stored.Response.Body) != string(body) {
+			t.Errorf("stored body = %q, want %q", stored.Response.Body, body)
+		}
+		if stored.Response.BodyRef != "" {
+			t.Errorf("BodyRef = %q, want empty", stored.Response.BodyRef)
+		}
+	})
+
+	t.Run("raw", func(t *testing.T) {
+		bucket := blob.NewMemBucket(ctx, log)
+		defer bucket.Close(ctx)
+		sc := NewScraper(log, bucket)
+		opt := &OptDoBodyStorage{Storage: BodyStorageRaw}
+
+		req, _ := http.NewRequest("GET", server.URL+"/raw", nil)
+		page, err := sc.Do(ctx, req, opt)
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		if string(page.Response.Body) != string(body) {
+			t.Errorf("returned body = %q, want %q", page.Response.Body, body)
+		}
+
+		stored := readStored(t, bucket, sc, req)
+		if len(stored.Response.Body) != 0 {
+			t.Errorf("stored page has body %q, want none", stored.Response.Body)
+		}
+		if !strings.HasPrefix(stored.Response.BodyRef, "raw/") {
+			t.Fatalf("BodyRef = %q, want raw/ prefix", stored.Response.BodyRef)
+		}
+		raw, err := bucket.Read(ctx, stored.Response.BodyRef)
+		if err != nil {
+			t.Fatalf("failed to read raw body: %v", err)
+		}
+		if string(raw) != string(body) {
+			t.Errorf("raw body = %q, want %q", raw, body)
+		}
+
+		// Cache hit resolves the reference
+		before := requestCount
+		req2, _ := http.NewRequest("GET", server.URL+"/raw", nil)
+		cached, err := sc.Do(ctx, req2, opt)
+		if err != nil {
+			t.Fatalf("cached Do() error = %v", err)
+		}
+		if requestCount != before {
+			t.Errorf("cached Do() hit server")
+		}
+		if string(cached.Response.Body) != string(body) {
+			t.Errorf("cached body = %q, want %q", cached.Response.Body, body)
+		}
+
+		// Discarding raw bodies forces a refetch
+		if _, err := bucket.DeletePrefix(ctx, "raw/"); err != nil {
+			t.Fatal(err)
+		}
+		req3, _ := http.NewRequest("GET", server.URL+"/raw", nil)
+		if _, err := sc.Do(ctx, req3, opt); err != nil {
+			t.Fatalf("Do() after discard error = %v", err)
+		}
+		if requestCount != before+1 {
+			t.Errorf("requestCount = %d, want %d", requestCount, before+1)
+		}
+	})
+
+	t.Run("discard", func(t *testing.T) {
+		bucket := blob.NewMemBucket(ctx, log)
+		defer bucket.Close(ctx)
+		sc := NewScraper(log, bucket)
+
+		req, _ := http.NewRequest("GET", server.URL+"/discard", nil)
+		page, err := sc.Do(ctx, req, &OptDoBodyStorage{Storage: BodyStorageDiscard})
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		if string(page.Response.Body) != string(body) {
+			t.Errorf("returned body = %q, want %q", page.Response.Body, body)
+		}
+		stored := readStored(t, bucket, sc, req)
+		if len(stored.Response.Body) != 0 || !stored.Response.BodyDiscarded {
+			t.Errorf("stored response = %+v, want discarded body", stored.Response)
+		}
+		if stored.Response.StatusCode != http.StatusOK {
+			t.Errorf("stored StatusCode = %d, want 200", stored.Response.StatusCode)
+		}
+	})
+}
+
+func TestContentEncodingDecoded(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+
+	html := "<html><body><h1>Deck</h1></body></html>"
+	compress := func(encoding string) []byte {
+		buf := new(bytes.Buffer)
+		var w io.WriteCloser
+		switch encoding {
+		case "gzip":
+			w = gzip.NewWriter(buf)
+		case "deflate":
+			w = zlib.NewWriter(buf)
+		}
+		w.Write([]byte(html))
+		w.Close()
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name     string
+		header   string // Content-Encoding sent by the server
+		encoding string // How the body is actually encoded
+	}{
+		{name: "gzip", header: "gzip", encoding: "gzip"},
+		{name: "deflate", header: "deflate", encoding: "deflate"},
+		{name: "unlabeled gzip", header: "", encoding: "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				if tt.header != "" {
+					w.Header().Set("Content-Encoding", tt.header)
+				}
+				w.WriteHeader(http.StatusOK)
+				w.Write(compress(tt.encoding))
+			}))
+			defer server.Close()
+
+			bucket := blob.NewMemBucket(ctx, log)
+			defer bucket.Close(ctx)
+			sc := NewScraper(log, bucket)
+
+			// Setting Accept-Encoding stops the transport from decoding
+			req, err := http.NewRequest("GET", server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Accept-Encoding", "gzip, deflate")
+			page, err := sc.Do(ctx, req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			if string(page.Response.Body) != html {
+				t.Errorf("body = %q, want %q", page.Response.Body, html)
+			}
+			if enc := page.Response.Header.Get("Content-Encoding"); enc != "" {
+				t.Errorf("Content-Encoding = %q, want removed", enc)
+			}
+
+			bkey, _, err := sc.blobKey(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := bucket.Read(ctx, bkey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stored := new(Page)
+			if err := json.Unmarshal(b, stored); err != nil {
+				t.Fatal(err)
+			}
+			if string(stored.Response.Body) != html {
+				t.Errorf("stored body = %q, want %q", stored.Response.Body, html)
+			}
+		})
+	}
+}
+
+func TestUnsupportedEncodingNotRetried(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+
+	var requestCount atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount.Add(1)
+		w.Header().Set("Content-Encoding", "br")
+		w.Write([]byte("not really brotli"))
+	}))
+	defer server.Close()
+
+	sc := NewScraper(log, bucket)
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept-Encoding", "br")
+	_, err = sc.Do(ctx, req)
+	errEncoding := &ErrUnsupportedEncoding{}
+	if !errors.As(err, &errEncoding) || errEncoding.Encoding != "br" {
+		t.Fatalf("Do() error = %v, want ErrUnsupportedEncoding for br", err)
+	}
+	if n := requestCount.Load(); n != 1 {
+		t.Errorf("server got %d requests, want 1", n)
+	}
+}
+
+// countingLimiter counts Take calls without ever blocking
+type countingLimiter struct {
+	n atomic.Int64
+}
+
+func (l *countingLimiter) Take() time.Time {
+	l.n.Add(1)
+	return time.Now()
+}
+
+func TestScraperConfigRateLimit(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	limitA, limitB := &countingLimiter{}, &countingLimiter{}
+	scA := NewScraperWithConfig(log, bucket.WithPrefix("a/"), ScraperConfig{RateLimit: limitA})
+	scB := NewScraperWithConfig(log, bucket.WithPrefix("b/"), ScraperConfig{RateLimit: limitB})
+
+	fetch := func(sc *Scraper, path string, options ...DoOption) {
+		t.Helper()
+		req, err := http.NewRequest("GET", server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sc.Do(ctx, req, options...); err != nil {
+			t.Fatalf("Do(%s): %v", path, err)
+		}
+	}
+	fetch(scA, "/1")
+	fetch(scA, "/2")
+	// The configured limit wins over a per-request one
+	perRequest := &countingLimiter{}
+	fetch(scA, "/3", &OptDoLimiter{Limiter: perRequest})
+	fetch(scB, "/1")
+
+	if got := limitA.n.Load(); got != 3 {
+		t.Errorf("scraper A took %d times, want 3", got)
+	}
+	if got := limitB.n.Load(); got != 1 {
+		t.Errorf("scraper B took %d times, want 1", got)
+	}
+	if got := perRequest.n.Load(); got != 0 {
+		t.Errorf("per-request limiter took %d times, want 0", got)
+	}
+}
+
+// roundTripFunc serves requests to any host without a network
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestScraperConfigHostRateLimits(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	bucket := blob.NewMemBucket(ctx, log)
+	defer bucket.Close(ctx)
+
+	scryfall, mtgtop8, global := &countingLimiter{}, &countingLimiter{}, &countingLimiter{}
+	sc := NewScraperWithConfig(log, bucket, ScraperConfig{
+		RateLimit: global,
+		HostRateLimits: map[string]ratelimit.Limiter{
+			"api.scryfall.com": scryfall,
+			"MTGTop8.com":      mtgtop8,
+		},
+	})
+	sc.httpClient.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader("ok")),
+			Request:    req,
+		}, nil
+	})
+
+	for _, u := range []string{
+		"https://api.scryfall.com/cards/1",
+		"https://api.scryfall.com/cards/2",
+		"https://api.scryfall.com:443/cards/3",
+		"https://mtgtop8.com/event?e=1",
+		"https://www.mtggoldfish.com/deck/1",
+	} {
+		req, err := http.NewRequest("GET", u, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sc.Do(ctx, req); err != nil {
+			t.Fatalf("Do(%s): %v", u, err)
+		}
+	}
+
+	for name, tt := range map[string]struct {
+		l    *countingLimiter
+		want int64
+	}{
+		"api.scryfall.com": {scryfall, 3},
+		"mtgtop8.com":      {mtgtop8, 1},
+		"global":           {global, 1},
+	} {
+		if got := tt.l.n.Load(); got != tt.want {
+			t.Errorf("%s limiter took %d times, want %d", name, got, tt.want)
+		}
+	}
+}
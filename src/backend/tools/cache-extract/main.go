@@ -1,16 +1,26 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"collections/blob"
+	"collections/progress"
+	"collections/tools/cachescan"
+
 	"github.com/DataDog/zstd"
 	"github.com/dgraph-io/badger/v3"
 )
@@ -22,11 +32,32 @@ var (
 	sourceFilter = flag.String("source", "", "Only extract specific source (e.g., 'goldfish')")
 	onlyGames    = flag.Bool("only-games", false, "Only extract game data, skip scraper HTTP cache")
 	onlyScraper  = flag.Bool("only-scraper", false, "Only extract scraper HTTP cache, skip game data")
+	cacheOpts    = cachescan.RegisterFlags(flag.CommandLine)
+	yes          = flag.Bool("yes", false, "Extract without asking for confirmation")
+	nonInteract  = flag.Bool("non-interactive", false, "Same as --yes, for cron and CI")
+	outputDir    = flag.String("output-dir", "../../data-full", "Directory to extract entries into")
+	keyRewrite   = flag.String("key-rewrite", "", "Rewrite a key prefix before writing, as from=to (e.g. games/magic/=magic/)")
+	verify       = flag.Bool("verify", false, "Read back each written file and check it decompresses to the cached value")
 )
 
+// compress is the zstd compressor used for extracted files, replaceable in
+// tests
+var compress = func(data []byte) ([]byte, error) {
+	return zstd.Compress(nil, data)
+}
+
+// errVerify marks a written file that did not read back as the cached value
+var errVerify = errors.New("verification failed")
+
 func main() {
 	flag.Parse()
 
+	out, err := newLayout(*outputDir, *keyRewrite)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
 	fmt.Println("🔄 CACHE EXTRACTION TOOL")
 	fmt.Println(strings.Repeat("=", 80))
 	fmt.Println()
@@ -36,11 +67,7 @@ func main() {
 		fmt.Println()
 	}
 
-	opts := badger.DefaultOptions("../../cache")
-	opts.ReadOnly = true
-	opts.Logger = nil
-
-	db, err := badger.Open(opts)
+	db, err := cachescan.Open(*cacheOpts)
 	if err != nil {
 		fmt.Printf("❌ Failed to open cache: %v\n", err)
 		os.Exit(1)
@@ -54,66 +81,66 @@ func main() {
 	var keysToExtract []string
 	categories := make(map[string]int)
 
-	err = db.View(func(txn *badger.Txn) error {
-		it := txn.NewIterator(badger.DefaultIteratorOptions)
-		defer it.Close()
-
-		checked := 0
-		for it.Rewind(); it.Valid(); it.Next() {
-			key := string(it.Item().Key())
-			checked++
+	// Filter based on flags by scanning only the prefixes to extract
+	var prefixes []string
+	if !*onlyScraper {
+		prefixes = append(prefixes, "games/")
+	}
+	if !*onlyGames {
+		prefixes = append(prefixes, "scraper/")
+	}
+	if len(prefixes) == 0 {
+		fmt.Println("❌ --only-games and --only-scraper exclude everything")
+		os.Exit(1)
+	}
 
-			// Filter based on flags
-			includeEntry := false
-			if strings.HasPrefix(key, "games/") && !*onlyScraper {
-				includeEntry = true
-			}
-			if strings.HasPrefix(key, "scraper/") && !*onlyGames {
-				includeEntry = true
+	scanProgress := progress.New(os.Stdout)
+	checked := 0
+	err = cachescan.Scan(db, *cacheOpts, prefixes, func(item *badger.Item) error {
+		key := string(item.Key())
+		checked++
+
+		// Filter by source if specified
+		if *sourceFilter != "" && strings.HasPrefix(key, "games/") {
+			parts := strings.Split(key, "/")
+			if len(parts) >= 3 && parts[2] != *sourceFilter {
+				return nil
 			}
+		}
 
-			if !includeEntry {
-				continue
+		// Check if exists on disk
+		diskPath, err := out.path(key)
+		if err != nil {
+			return nil // Extraction reports it
+		}
+		if _, err := os.Stat(diskPath); err == nil {
+			if *onConflict == "skip" {
+				return nil // Already exists, skip
 			}
+		}
 
-			// Filter by source if specified
-			if *sourceFilter != "" && strings.HasPrefix(key, "games/") {
-				parts := strings.Split(key, "/")
-				if len(parts) >= 3 && parts[2] != *sourceFilter {
-					continue
-				}
-			}
+		keysToExtract = append(keysToExtract, key)
 
-			// Check if exists on disk
-			diskPath := "../../data-full/" + key
-			if _, err := os.Stat(diskPath); err == nil {
-				if *onConflict == "skip" {
-					continue // Already exists, skip
-				}
+		// Categorize for stats
+		if strings.HasPrefix(key, "games/") {
+			parts := strings.Split(key, "/")
+			if len(parts) >= 3 {
+				categories[strings.Join(parts[:3], "/")]++
 			}
-
-			keysToExtract = append(keysToExtract, key)
-
-			// Categorize for stats
-			if strings.HasPrefix(key, "games/") {
-				parts := strings.Split(key, "/")
-				if len(parts) >= 3 {
-					categories[strings.Join(parts[:3], "/")]++
-				}
-			} else if strings.HasPrefix(key, "scraper/") {
-				parts := strings.Split(key, "/")
-				if len(parts) >= 2 {
-					categories["scraper/"+parts[1]]++
-				}
+		} else if strings.HasPrefix(key, "scraper/") {
+			parts := strings.Split(key, "/")
+			if len(parts) >= 2 {
+				categories["scraper/"+parts[1]]++
 			}
+		}
 
-			if checked%50000 == 0 {
-				fmt.Printf("\rScanning... %d entries", checked)
-			}
+		if checked%50000 == 0 {
+			scanProgress.Update("Scanning... %d entries", checked)
 		}
-		fmt.Printf("\rScanned %d entries\n\n", checked)
 		return nil
 	})
+	scanProgress.Done()
+	fmt.Printf("Scanned %d entries\n\n", checked)
 
 	if err != nil {
 		fmt.Printf("❌ Error scanning cache: %v\n", err)
@@ -154,7 +181,11 @@ func main() {
 				fmt.Printf("   ... and %d more\n", len(keysToExtract)-20)
 				break
 			}
-			fmt.Printf("   %s\n", key)
+			if p, err := out.path(key); err == nil && *keyRewrite != "" {
+				fmt.Printf("   %s -> %s\n", key, p)
+			} else {
+				fmt.Printf("   %s\n", key)
+			}
 		}
 		fmt.Println()
 		fmt.Println("Run without --dry-run to actually extract")
@@ -164,10 +195,7 @@ func main() {
 	// Confirm extraction
 	fmt.Printf("⚠️  About to extract %d entries (%.2f GB estimated)\n",
 		len(keysToExtract), float64(estimatedTotal)/(1024*1024*1024))
-	fmt.Print("Continue? [y/N]: ")
-	var response string
-	fmt.Scanln(&response)
-	if strings.ToLower(response) != "y" {
+	if !confirm(os.Stdin, os.Stdout, *yes || *nonInteract) {
 		fmt.Println("Aborted")
 		return
 	}
@@ -180,7 +208,7 @@ func main() {
 	var (
 		extracted atomic.Int64
 		skipped   atomic.Int64
-		errors    atomic.Int64
+		failed    atomic.Int64
 	)
 
 	start := time.Now()
@@ -194,21 +222,21 @@ func main() {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			extractWorker(db, work, &extracted, &skipped, &errors)
+			extractWorker(db, out, *verify, work, &extracted, &skipped, &failed)
 		}()
 	}
 
 	// Progress reporter
 	done := make(chan bool)
+	// The ticker already paces updates, so log every one
+	extractProgress := progress.NewWriter(os.Stdout, progress.IsTerminal(os.Stdout), 0)
 	go func() {
 		ticker := time.NewTicker(5 * time.Second)
 		defer ticker.Stop()
-		lastPrinted := ""
 		for {
 			select {
 			case <-done:
-				// Clear the line
-				fmt.Print("\r" + strings.Repeat(" ", len(lastPrinted)) + "\r")
+				extractProgress.Done()
 				return
 			case <-ticker.C:
 				e := extracted.Load()
@@ -216,10 +244,8 @@ func main() {
 				pct := 100.0 * float64(e) / float64(total)
 				rate := float64(e) / time.Since(start).Seconds()
 				remaining := time.Duration(float64(total-e)/rate) * time.Second
-				line := fmt.Sprintf("📈 Progress: %d/%d (%.1f%%) - %.1f/s - ETA: %v         ",
+				extractProgress.Update("📈 Progress: %d/%d (%.1f%%) - %.1f/s - ETA: %v",
 					e, total, pct, rate, remaining.Round(time.Second))
-				fmt.Print("\r" + line)
-				lastPrinted = line
 			}
 		}
 	}()
@@ -244,30 +270,80 @@ func main() {
 	fmt.Println()
 	fmt.Printf("✅ Extracted: %d\n", extracted.Load())
 	fmt.Printf("⏭️  Skipped:   %d\n", skipped.Load())
-	fmt.Printf("❌ Errors:    %d\n", errors.Load())
+	fmt.Printf("❌ Errors:    %d\n", failed.Load())
 	fmt.Printf("⏱️  Duration:  %v\n", elapsed.Round(time.Second))
 	if elapsed.Seconds() > 0 {
 		fmt.Printf("📈 Rate:      %.1f entries/sec\n", float64(extracted.Load())/elapsed.Seconds())
 	}
 	fmt.Println()
 
-	if errors.Load() > 0 {
+	if failed.Load() > 0 {
 		fmt.Println("⚠️  Some entries failed to extract")
 	} else {
 		fmt.Println("🎉 All entries extracted successfully!")
 		fmt.Println()
 		fmt.Println("Next steps:")
 		fmt.Println("  1. Verify game data: go run cmd/analyze-decks/main.go data-full/games/magic")
-		fmt.Println("  2. Count HTTP cache: find data-full/scraper -name '*.zst' | wc -l")
+		fmt.Printf("  2. Count extracted files: find %s -name '*.zst' | wc -l\n", *outputDir)
 		fmt.Println("  3. Re-parse with updated parsers if needed")
 	}
 }
 
-func extractWorker(db *badger.DB, work chan string, extracted, skipped, errors *atomic.Int64) {
+// confirm asks on out whether to continue and reads the answer from in;
+// skip answers yes without reading in
+func confirm(in io.Reader, out io.Writer, skip bool) bool {
+	fmt.Fprint(out, "Continue? [y/N]: ")
+	if skip {
+		fmt.Fprintln(out, "y (--yes)")
+		return true
+	}
+	response, _ := bufio.NewReader(in).ReadString('\n')
+	return strings.ToLower(strings.TrimSpace(response)) == "y"
+}
+
+// layout maps cache keys to files under an output directory, optionally
+// replacing a key prefix
+type layout struct {
+	dir      string
+	from, to string
+}
+
+// newLayout parses a from=to key rewrite; an empty rewrite keeps keys as
+// they are
+func newLayout(dir, rewrite string) (layout, error) {
+	l := layout{dir: dir}
+	if rewrite == "" {
+		return l, nil
+	}
+	from, to, ok := strings.Cut(rewrite, "=")
+	if !ok || from == "" {
+		return layout{}, fmt.Errorf("invalid --key-rewrite %q, want from=to", rewrite)
+	}
+	l.from, l.to = from, to
+	return l, nil
+}
+
+// path is the file key is extracted to. Keys without the rewrite prefix
+// keep their name; a rewritten key may not leave the output directory.
+func (l layout) path(key string) (string, error) {
+	if l.from != "" && strings.HasPrefix(key, l.from) {
+		key = l.to + strings.TrimPrefix(key, l.from)
+	}
+	rel := path.Clean(key)
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") || path.IsAbs(rel) {
+		return "", fmt.Errorf("key %q rewrites outside the output directory", key)
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(rel)), nil
+}
+
+func extractWorker(db *badger.DB, out layout, verify bool, work chan string, extracted, skipped, failed *atomic.Int64) {
 	db.View(func(txn *badger.Txn) error {
 		for key := range work {
-			if err := extractEntry(txn, key); err != nil {
-				errors.Add(1)
+			if err := extractEntry(txn, out, key, verify); err != nil {
+				if errors.Is(err, errVerify) {
+					fmt.Fprintf(os.Stderr, "\n❌ %s: %v\n", key, err)
+				}
+				failed.Add(1)
 			} else {
 				extracted.Add(1)
 			}
@@ -276,7 +352,15 @@ func extractWorker(db *badger.DB, work chan string, extracted, skipped, errors *
 	})
 }
 
-func extractEntry(txn *badger.Txn, key string) error {
+// extractEntry writes the cached value of key to its file. With verify it
+// reads the file back and removes it unless it decompresses to the value,
+// so a later run retries the entry.
+func extractEntry(txn *badger.Txn, out layout, key string, verify bool) error {
+	diskPath, err := out.path(key)
+	if err != nil {
+		return err
+	}
+
 	item, err := txn.Get([]byte(key))
 	if err != nil {
 		return fmt.Errorf("failed to get from cache: %w", err)
@@ -300,14 +384,13 @@ func extractEntry(txn *badger.Txn, key string) error {
 
 	// Write to disk with proper compression
 	// BadgerDB stores uncompressed, but blob storage expects zstd compression
-	diskPath := "../../data-full/" + key
 	dir := filepath.Dir(diskPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
 	// Compress data using zstd
-	compressed, err := zstd.Compress(nil, data)
+	compressed, err := compress(data)
 	if err != nil {
 		return fmt.Errorf("failed to compress data: %w", err)
 	}
@@ -316,15 +399,58 @@ func extractEntry(txn *badger.Txn, key string) error {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
-	// Create .attrs file for consistency
+	if verify {
+		if err := verifyFile(diskPath, data); err != nil {
+			os.Remove(diskPath)
+			return fmt.Errorf("%w: %v", errVerify, err)
+		}
+	}
+
+	// Write the .attrs sidecar file buckets read, so the extracted blob
+	// has the same content hash a bucket write would have stored
 	attrsPath := diskPath + ".attrs"
-	attrsData := []byte("{}")
+	attrsData, err := json.Marshal(newFileAttrs(data, compressed))
+	if err != nil {
+		return fmt.Errorf("failed to marshal attrs: %w", err)
+	}
 	os.WriteFile(attrsPath, attrsData, 0644)
 	// Ignore errors on attrs - not critical
 
 	return nil
 }
 
+// fileAttrs is the subset of gocloud's fileblob .attrs sidecar that
+// blob.Bucket.Stat reads
+type fileAttrs struct {
+	Metadata map[string]string `json:"user.metadata"`
+	MD5      []byte            `json:"md5"`
+}
+
+// newFileAttrs describes data stored compressed as compressed
+func newFileAttrs(data, compressed []byte) fileAttrs {
+	sum := md5.Sum(compressed)
+	return fileAttrs{
+		Metadata: map[string]string{blob.MetadataSHA256: blob.ContentHash(data)},
+		MD5:      sum[:],
+	}
+}
+
+// verifyFile checks that the file at p decompresses to want
+func verifyFile(p string, want []byte) error {
+	written, err := os.ReadFile(p)
+	if err != nil {
+		return fmt.Errorf("failed to read back file: %w", err)
+	}
+	got, err := zstd.Decompress(nil, written)
+	if err != nil {
+		return fmt.Errorf("failed to decompress written file: %w", err)
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("written file decompresses to %d bytes that differ from the %d cached", len(got), len(want))
+	}
+	return nil
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
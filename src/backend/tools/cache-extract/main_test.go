@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"collections/blob"
+
+	"github.com/DataDog/zstd"
+	"github.com/dgraph-io/badger/v3"
+)
+
+// failReader fails the test if confirm reads from it
+type failReader struct{ t *testing.T }
+
+func (r failReader) Read([]byte) (int, error) {
+	r.t.Fatal("confirm read stdin despite --yes")
+	return 0, nil
+}
+
+func TestConfirmYesSkipsStdin(t *testing.T) {
+	var out bytes.Buffer
+	if !confirm(failReader{t}, &out, true) {
+		t.Error("confirm(--yes) = false, want true")
+	}
+	if !strings.Contains(out.String(), "Continue?") {
+		t.Errorf("confirm(--yes) printed %q, want the prompt echoed", out.String())
+	}
+}
+
+func TestConfirmReadsAnswer(t *testing.T) {
+	for in, want := range map[string]bool{"y\n": true, "Y\n": true, "n\n": false, "\n": false, "": false, "yes\n": false} {
+		if got := confirm(strings.NewReader(in), &bytes.Buffer{}, false); got != want {
+			t.Errorf("confirm(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestLayoutPath(t *testing.T) {
+	l, err := newLayout("out", "games/magic/=magic/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"games/magic/goldfish/1.json":    filepath.Join("out", "magic", "goldfish", "1.json"),
+		"games/pokemon/limitless/2.json": filepath.Join("out", "games", "pokemon", "limitless", "2.json"),
+		"scraper/abc":                    filepath.Join("out", "scraper", "abc"),
+	}
+	for key, want := range tests {
+		if got, err := l.path(key); err != nil || got != want {
+			t.Errorf("path(%q) = %q, %v, want %q", key, got, err, want)
+		}
+	}
+
+	escape, err := newLayout("out", "games/=../")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := escape.path("games/x"); err == nil {
+		t.Errorf("path() of a key rewritten outside the output dir = %q, want error", got)
+	}
+	for _, bad := range []string{"games", "=magic/"} {
+		if _, err := newLayout("out", bad); err == nil {
+			t.Errorf("newLayout(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+// openTestDB opens a temporary cache holding key with value
+func openTestDB(t *testing.T, key string, value []byte) *badger.DB {
+	t.Helper()
+	opts := badger.DefaultOptions(t.TempDir())
+	opts.Logger = nil
+	db, err := badger.Open(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Update(func(txn *badger.Txn) error { return txn.Set([]byte(key), value) }); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestExtractEntryRewritesIntoOutputDir(t *testing.T) {
+	key, value := "games/magic/goldfish/deck:1.json", []byte(`{"id":"1"}`)
+	db := openTestDB(t, key, value)
+
+	dir := t.TempDir()
+	out, err := newLayout(dir, "games/=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.View(func(txn *badger.Txn) error { return extractEntry(txn, out, key, true) })
+	if err != nil {
+		t.Fatalf("extractEntry() error = %v", err)
+	}
+
+	want := filepath.Join(dir, "magic", "goldfish", "deck:1.json")
+	compressed, err := os.ReadFile(want)
+	if err != nil {
+		t.Fatalf("rewritten file not written: %v", err)
+	}
+	got, err := zstd.Decompress(nil, compressed)
+	if err != nil || !bytes.Equal(got, value) {
+		t.Errorf("extracted %q, %v, want %q", got, err, value)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "games")); !os.IsNotExist(err) {
+		t.Errorf("original key path exists under output dir (err = %v)", err)
+	}
+}
+
+func TestExtractEntryVerifySurfacesCorruptWrite(t *testing.T) {
+	key, value := "games/magic/goldfish/deck:1.json", []byte(`{"id":"1"}`)
+	db := openTestDB(t, key, value)
+
+	faults := map[string]func([]byte) ([]byte, error){
+		"not zstd": func(data []byte) ([]byte, error) {
+			return append([]byte("garbage"), data...), nil
+		},
+		"wrong content": func(data []byte) ([]byte, error) {
+			return zstd.Compress(nil, data[:len(data)-1])
+		},
+	}
+	for name, fault := range faults {
+		t.Run(name, func(t *testing.T) {
+			defer func(orig func([]byte) ([]byte, error)) { compress = orig }(compress)
+			compress = fault
+
+			out, err := newLayout(t.TempDir(), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			diskPath, _ := out.path(key)
+
+			// Without --verify the corrupt file is written and reported as extracted
+			err = db.View(func(txn *badger.Txn) error { return extractEntry(txn, out, key, false) })
+			if err != nil {
+				t.Fatalf("extractEntry() without verify error = %v", err)
+			}
+
+			err = db.View(func(txn *badger.Txn) error { return extractEntry(txn, out, key, true) })
+			if !errors.Is(err, errVerify) {
+				t.Fatalf("extractEntry() with verify error = %v, want %v", err, errVerify)
+			}
+			if _, err := os.Stat(diskPath); !os.IsNotExist(err) {
+				t.Errorf("corrupt file left on disk (err = %v)", err)
+			}
+		})
+	}
+}
+
+func TestExtractEntryAttrsVerify(t *testing.T) {
+	ctx := context.Background()
+	key, value := "games/magic/goldfish/deck:1.json.zst", []byte(`{"id":"1"}`)
+	db := openTestDB(t, key, value)
+
+	dir := t.TempDir()
+	out, err := newLayout(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.View(func(txn *badger.Txn) error { return extractEntry(txn, out, key, false) })
+	if err != nil {
+		t.Fatalf("extractEntry() error = %v", err)
+	}
+
+	// The extracted file reads back through a bucket with its content hash
+	b, err := blob.NewBucket(ctx, nil, "file://"+dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close(ctx)
+	bkey := strings.TrimSuffix(key, ".zst")
+	attrs, err := b.Stat(ctx, bkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attrs.SHA256 != blob.ContentHash(value) || len(attrs.MD5) == 0 {
+		t.Errorf("attrs = %+v, want the content hash and an MD5", attrs)
+	}
+	if err := b.Verify(ctx, bkey); err != nil {
+		t.Errorf("Verify() = %v", err)
+	}
+}
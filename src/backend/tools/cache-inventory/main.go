@@ -1,19 +1,26 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"strings"
 
+	"collections/progress"
+	"collections/tools/cachescan"
+
 	"github.com/dgraph-io/badger/v3"
 )
 
+var (
+	cacheOpts = cachescan.RegisterFlags(flag.CommandLine)
+	allKeys   = flag.Bool("all", false, "Scan every key, counting those outside games/ and scraper/ as other")
+)
+
 func main() {
-	opts := badger.DefaultOptions("../../cache")
-	opts.ReadOnly = true
-	opts.Logger = nil
+	flag.Parse()
 
-	db, err := badger.Open(opts)
+	db, err := cachescan.Open(*cacheOpts)
 	if err != nil {
 		fmt.Printf("❌ Failed to open cache: %v\n", err)
 		os.Exit(1)
@@ -28,41 +35,45 @@ func main() {
 	stats := make(map[string]int)
 	onlyInCache := make(map[string][]string)
 
-	err = db.View(func(txn *badger.Txn) error {
-		it := txn.NewIterator(badger.DefaultIteratorOptions)
-		defer it.Close()
-
-		checked := 0
-		for it.Rewind(); it.Valid(); it.Next() {
-			key := string(it.Item().Key())
-			checked++
-
-			// Categorize
-			if strings.HasPrefix(key, "games/") {
-				parts := strings.Split(key, "/")
-				if len(parts) >= 3 {
-					category := strings.Join(parts[:3], "/")
-					stats[category]++
-
-					// Check if exists on disk
-					diskPath := "../../data-full/" + key
-					if _, err := os.Stat(diskPath); os.IsNotExist(err) {
-						onlyInCache[category] = append(onlyInCache[category], key)
-					}
+	// Only games/ and scraper/ are reported, so skip the rest of the cache
+	// unless --all asks for the other count
+	prefixes := []string{"games/", "scraper/"}
+	if *allKeys {
+		prefixes = nil
+	}
+
+	scanProgress := progress.New(os.Stdout)
+	checked := 0
+	err = cachescan.Scan(db, *cacheOpts, prefixes, func(item *badger.Item) error {
+		key := string(item.Key())
+		checked++
+
+		// Categorize
+		if strings.HasPrefix(key, "games/") {
+			parts := strings.Split(key, "/")
+			if len(parts) >= 3 {
+				category := strings.Join(parts[:3], "/")
+				stats[category]++
+
+				// Check if exists on disk
+				diskPath := "../../data-full/" + key
+				if _, err := os.Stat(diskPath); os.IsNotExist(err) {
+					onlyInCache[category] = append(onlyInCache[category], key)
 				}
-			} else if strings.HasPrefix(key, "scraper/") {
-				stats["scraper/*"]++
-			} else {
-				stats["other"]++
 			}
+		} else if strings.HasPrefix(key, "scraper/") {
+			stats["scraper/*"]++
+		} else {
+			stats["other"]++
+		}
 
-			if checked%50000 == 0 {
-				fmt.Printf("\rProcessing... %d entries", checked)
-			}
+		if checked%50000 == 0 {
+			scanProgress.Update("Processing... %d entries", checked)
 		}
-		fmt.Printf("\rProcessed %d entries\n\n", checked)
 		return nil
 	})
+	scanProgress.Done()
+	fmt.Printf("Processed %d entries\n\n", checked)
 
 	if err != nil {
 		fmt.Printf("❌ Error: %v\n", err)
@@ -0,0 +1,112 @@
+// Package cachescan opens the badger scraper cache read-only and walks its
+// keys, for the cache-inventory and cache-extract tools
+//
+// Badger memory-maps its tables, so a read-only scan pages keys in from
+// disk rather than loading them. Two things keep a scan of a large cache
+// cheap: restricting it to key prefixes, which lets badger skip tables
+// outside them instead of visiting every key, and not prefetching values
+// the caller will not read.
+package cachescan
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+// Options configures opening and scanning the cache
+type Options struct {
+	Dir string
+	// PrefetchSize is how many values the iterator reads ahead; 0 iterates
+	// keys only
+	PrefetchSize int
+	// ValueThreshold overrides badger's value threshold when positive; it
+	// should match the value the cache was written with
+	ValueThreshold int64
+	// Prefix narrows every scan to keys under it, such as
+	// games/magic/mtgtop8/ to extract one source
+	Prefix string
+}
+
+// RegisterFlags adds --cache, --prefix, --prefetch-size and
+// --value-threshold to fs
+func RegisterFlags(fs *flag.FlagSet) *Options {
+	opts := &Options{}
+	fs.StringVar(&opts.Dir, "cache", "../../cache", "Badger cache directory")
+	fs.StringVar(&opts.Prefix, "prefix", "", "Only scan keys under this prefix (e.g. games/magic/mtgtop8/)")
+	fs.IntVar(&opts.PrefetchSize, "prefetch-size", 0, "Values to prefetch while scanning (0 = keys only)")
+	fs.Int64Var(&opts.ValueThreshold, "value-threshold", 0, "Badger value threshold in bytes (0 = badger default)")
+	return opts
+}
+
+// Open opens the cache read-only
+func Open(opts Options) (*badger.DB, error) {
+	bopts := badger.DefaultOptions(opts.Dir)
+	bopts.ReadOnly = true
+	bopts.Logger = nil
+	if opts.ValueThreshold > 0 {
+		bopts.ValueThreshold = opts.ValueThreshold
+	}
+	return badger.Open(bopts)
+}
+
+// Scan calls fn for each key under any of prefixes and under
+// opts.Prefix, prefix by prefix in key order. No prefixes scans every key.
+// An error from fn stops the scan and is returned.
+func Scan(db *badger.DB, opts Options, prefixes []string, fn func(item *badger.Item) error) error {
+	if len(prefixes) == 0 {
+		prefixes = []string{""}
+	}
+	prefixes = narrow(prefixes, opts.Prefix)
+	return db.View(func(txn *badger.Txn) error {
+		for _, prefix := range prefixes {
+			if err := scanPrefix(txn, opts, []byte(prefix), fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// narrow restricts prefixes to keys under prefix: each becomes prefix if
+// prefix lies within it, stays if it lies within prefix, and is dropped
+// otherwise
+func narrow(prefixes []string, prefix string) []string {
+	if prefix == "" {
+		return prefixes
+	}
+	var out []string
+	seen := make(map[string]bool)
+	for _, p := range prefixes {
+		switch {
+		case strings.HasPrefix(prefix, p):
+			p = prefix
+		case !strings.HasPrefix(p, prefix):
+			continue
+		}
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func scanPrefix(txn *badger.Txn, opts Options, prefix []byte, fn func(item *badger.Item) error) error {
+	iopts := badger.DefaultIteratorOptions
+	iopts.PrefetchValues = opts.PrefetchSize > 0
+	if iopts.PrefetchValues {
+		iopts.PrefetchSize = opts.PrefetchSize
+	}
+	iopts.Prefix = prefix
+
+	it := txn.NewIterator(iopts)
+	defer it.Close()
+	for it.Seek(prefix); it.Valid(); it.Next() {
+		if err := fn(it.Item()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
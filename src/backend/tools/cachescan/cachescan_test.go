@@ -0,0 +1,133 @@
+package cachescan
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+func writeCache(t *testing.T, keys ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	opts := badger.DefaultOptions(dir)
+	opts.Logger = nil
+	db, err := badger.Open(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Update(func(txn *badger.Txn) error {
+		for _, k := range keys {
+			if err := txn.Set([]byte(k), []byte(`{}`)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func scanKeys(t *testing.T, db *badger.DB, opts Options, prefixes ...string) []string {
+	t.Helper()
+	var got []string
+	err := Scan(db, opts, prefixes, func(item *badger.Item) error {
+		got = append(got, string(item.Key()))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Scan(%v) error = %v", prefixes, err)
+	}
+	return got
+}
+
+func TestScanVisitsOnlyPrefixedKeys(t *testing.T) {
+	dir := writeCache(t,
+		"aaa/first",
+		"games/magic/goldfish/1.json",
+		"games/pokemon/limitless/2.json",
+		"gamesx/not-a-game",
+		"other/key",
+		"scraper/abc",
+		"zzz/last",
+	)
+	opts := Options{Dir: dir}
+	db, err := Open(opts)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	got := scanKeys(t, db, opts, "games/", "scraper/")
+	want := []string{"games/magic/goldfish/1.json", "games/pokemon/limitless/2.json", "scraper/abc"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan(games/, scraper/) = %v, want %v", got, want)
+	}
+
+	opts.PrefetchSize = 2
+	if got := scanKeys(t, db, opts, "games/magic/"); !reflect.DeepEqual(got, want[:1]) {
+		t.Errorf("Scan(games/magic/) with prefetch = %v, want %v", got, want[:1])
+	}
+	if got := scanKeys(t, db, opts, "missing/"); len(got) != 0 {
+		t.Errorf("Scan(missing/) = %v, want none", got)
+	}
+	if got := scanKeys(t, db, opts); len(got) != 7 {
+		t.Errorf("Scan() with no prefixes visited %d keys, want 7", len(got))
+	}
+}
+
+func TestScanOptionsPrefix(t *testing.T) {
+	dir := writeCache(t,
+		"games/magic/goldfish/1.json",
+		"games/magic/mtgtop8/1.json",
+		"games/magic/mtgtop8/2.json",
+		"games/magic/mtgtop8x/3.json",
+		"games/pokemon/limitless/1.json",
+		"scraper/abc",
+	)
+	opts := Options{Dir: dir, Prefix: "games/magic/mtgtop8/"}
+	db, err := Open(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	want := []string{"games/magic/mtgtop8/1.json", "games/magic/mtgtop8/2.json"}
+	for _, prefixes := range [][]string{nil, {"games/", "scraper/"}, {"games/magic/"}} {
+		if got := scanKeys(t, db, opts, prefixes...); !reflect.DeepEqual(got, want) {
+			t.Errorf("Scan(%v) with --prefix = %v, want %v", prefixes, got, want)
+		}
+	}
+	// A narrower scan prefix stays as is; a disjoint one visits nothing
+	if got := scanKeys(t, db, opts, "games/magic/mtgtop8/2"); !reflect.DeepEqual(got, want[1:]) {
+		t.Errorf("Scan(games/magic/mtgtop8/2) with --prefix = %v, want %v", got, want[1:])
+	}
+	if got := scanKeys(t, db, opts, "scraper/"); len(got) != 0 {
+		t.Errorf("Scan(scraper/) with --prefix = %v, want none", got)
+	}
+}
+
+func TestScanStopsOnError(t *testing.T) {
+	dir := writeCache(t, "games/a", "games/b", "games/c")
+	db, err := Open(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	errStop := errors.New("stop")
+	visited := 0
+	err = Scan(db, Options{}, []string{"games/"}, func(*badger.Item) error {
+		visited++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || visited != 1 {
+		t.Errorf("Scan() = %v after %d keys, want %v after 1", err, visited, errStop)
+	}
+}
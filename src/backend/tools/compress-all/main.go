@@ -3,12 +3,14 @@ package main
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/DataDog/zstd"
+
+	"collections/cio"
+	"collections/progress"
 )
 
 func main() {
@@ -26,18 +28,13 @@ func main() {
 	start := time.Now()
 
 	// Collect all files first
-	var files []string
-	filepath.Walk("../../data-full/games/magic", func(path string, info os.FileInfo, err error) error {
-		if err == nil && !info.IsDir() && filepath.Ext(path) == ".zst" {
-			files = append(files, path)
-		}
-		return nil
-	})
+	files, _ := cio.FindCollectionFiles("../../data-full/games/magic", cio.FindOpts{Extensions: []string{".zst"}, SkipErrors: true})
 
 	fmt.Printf("Found %d .zst files to check\n", len(files))
 	fmt.Println()
 
 	// Process in parallel
+	report := progress.New(os.Stdout)
 	work := make(chan string, 100)
 	wg := &sync.WaitGroup{}
 
@@ -86,7 +83,7 @@ func main() {
 				compressed.Add(1)
 
 				if compressed.Load()%1000 == 0 {
-					fmt.Printf("\rCompressed: %d  Skipped: %d  Errors: %d  Progress: %.1f%%",
+					report.Update("Compressed: %d  Skipped: %d  Errors: %d  Progress: %.1f%%",
 						compressed.Load(), skipped.Load(), errors.Load(),
 						100.0*float64(checked.Load())/float64(len(files)))
 				}
@@ -103,7 +100,7 @@ func main() {
 
 	elapsed := time.Since(start)
 
-	fmt.Printf("\r%s\n", "                                                                      ")
+	report.Done()
 	fmt.Println()
 	fmt.Println("==================================")
 	fmt.Println("COMPRESSION COMPLETE")
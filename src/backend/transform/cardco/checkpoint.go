@@ -0,0 +1,114 @@
+package cardco
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/vmihailenco/msgpack"
+)
+
+type checkpointEntry struct {
+	Key tkey
+	Val tval
+}
+
+// forEach calls fn for every accumulated pair
+func (t *Transform) forEach(fn func(k tkey, v tval) error) error {
+	return t.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			var k tkey
+			if err := msgpack.Unmarshal(item.Key(), &k); err != nil {
+				return fmt.Errorf("failed to decode key: %w", err)
+			}
+			var v tval
+			err := item.Value(func(vb []byte) error {
+				return msgpack.Unmarshal(vb, &v)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to decode value: %w", err)
+			}
+			if err := fn(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Checkpoint writes the current pair counts to path so a later Transform
+// can Resume from them. The file is written to a temporary name and
+// renamed, so an interrupted checkpoint leaves the previous one intact.
+func (t *Transform) Checkpoint(ctx context.Context, path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint: %w", err)
+	}
+	defer os.Remove(tmp)
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := msgpack.NewEncoder(w)
+	n := 0
+	err = t.forEach(func(k tkey, v tval) error {
+		n++
+		return enc.Encode(checkpointEntry{Key: k, Val: v})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync checkpoint: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to rename checkpoint: %w", err)
+	}
+	t.log.Infof(ctx, "checkpointed %d pairs to %s", n, path)
+	return nil
+}
+
+// Resume adds the pair counts saved by Checkpoint to the transform.
+// Counts are merged, so resuming into a transform that already holds
+// pairs sums them.
+func (t *Transform) Resume(ctx context.Context, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	defer f.Close()
+
+	dec := msgpack.NewDecoder(bufio.NewReader(f))
+	n := 0
+	for {
+		var e checkpointEntry
+		if err := dec.Decode(&e); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("failed to read checkpoint entry %d: %w", n, err)
+		}
+		if err := t.add(e.Key, e.Val); err != nil {
+			return err
+		}
+		n++
+	}
+	t.log.Infof(ctx, "resumed %d pairs from %s", n, path)
+	return nil
+}
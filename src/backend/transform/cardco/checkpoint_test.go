@@ -0,0 +1,102 @@
+package cardco
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"collections/games/magic/dataset"
+	"collections/games/magic/game"
+	"collections/logger"
+)
+
+func testItems() []dataset.Item {
+	deck := func(cards ...game.CardDesc) dataset.Item {
+		return &dataset.CollectionItem{Collection: &game.Collection{
+			Partitions: []game.Partition{{Name: "Main", Cards: cards}},
+		}}
+	}
+	return []dataset.Item{
+		deck(game.CardDesc{Name: "Lightning Bolt", Count: 4}, game.CardDesc{Name: "Mountain", Count: 20}),
+		deck(game.CardDesc{Name: "Lightning Bolt", Count: 2}, game.CardDesc{Name: "Chain Lightning", Count: 4}),
+		deck(game.CardDesc{Name: "Mountain", Count: 18}, game.CardDesc{Name: "Chain Lightning", Count: 1}, game.CardDesc{Name: "Lightning Bolt", Count: 4}),
+		deck(game.CardDesc{Name: "Island", Count: 20}, game.CardDesc{Name: "Brainstorm", Count: 4}),
+	}
+}
+
+func newTestTransform(t *testing.T) *Transform {
+	t.Helper()
+	ctx := context.Background()
+	log := logger.NewLogger(ctx)
+	log.SetLevel("panic")
+	tr, err := NewTransform(ctx, log)
+	if err != nil {
+		t.Fatalf("NewTransform() error = %v", err)
+	}
+	t.Cleanup(func() { tr.Close() })
+	return tr
+}
+
+func snapshot(t *testing.T, tr *Transform) map[tkey]tval {
+	t.Helper()
+	pairs := make(map[tkey]tval)
+	if err := tr.forEach(func(k tkey, v tval) error {
+		pairs[k] = v
+		return nil
+	}); err != nil {
+		t.Fatalf("forEach() error = %v", err)
+	}
+	return pairs
+}
+
+func TestCheckpointResume(t *testing.T) {
+	ctx := context.Background()
+	items := testItems()
+
+	single := newTestTransform(t)
+	for _, item := range items {
+		if err := single.worker(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := snapshot(t, single)
+
+	path := filepath.Join(t.TempDir(), "pairs.checkpoint")
+
+	first := newTestTransform(t)
+	for _, item := range items[:2] {
+		if err := first.worker(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := first.Checkpoint(ctx, path); err != nil {
+		t.Fatalf("Checkpoint() error = %v", err)
+	}
+	first.Close()
+
+	second := newTestTransform(t)
+	if err := second.Resume(ctx, path); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	for _, item := range items[2:] {
+		if err := second.worker(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := snapshot(t, second)
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("two-session pairs = %v, want %v", got, want)
+	}
+	if v := got[newKey("Lightning Bolt", "Mountain")]; v.Set != 2 || v.Multiset != 4*20+4*18 {
+		t.Errorf("Lightning Bolt/Mountain = %+v, want Set 2, Multiset %d", v, 4*20+4*18)
+	}
+}
+
+func TestResumeMissingCheckpoint(t *testing.T) {
+	tr := newTestTransform(t)
+	if err := tr.Resume(context.Background(), filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Resume() error = nil for missing checkpoint")
+	}
+}
@@ -0,0 +1,77 @@
+package cardco
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// OptExportWeightSum adds a WEIGHT_SUM column to ExportCSV: the summed
+// weight of the collections each pair occurs in
+type OptExportWeightSum struct{}
+
+func (o *OptExportWeightSum) exportOption() {}
+
+// ExportOption configures ExportCSV
+type ExportOption interface {
+	exportOption()
+}
+
+// ExportCSV writes the accumulated pairs to path as NAME_1, NAME_2,
+// COUNT_SET, COUNT_MULTISET and, with OptExportWeightSum, WEIGHT_SUM.
+// Counts are weighted, so they are fractional when a source weight is.
+func (t *Transform) ExportCSV(ctx context.Context, path string, options ...ExportOption) error {
+	weightSum := false
+	for _, opt := range options {
+		switch opt.(type) {
+		case *OptExportWeightSum:
+			weightSum = true
+		default:
+			panic(fmt.Sprintf("invalid export option type %T", opt))
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	header := []string{"NAME_1", "NAME_2", "COUNT_SET", "COUNT_MULTISET"}
+	if weightSum {
+		header = append(header, "WEIGHT_SUM")
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	n := 0
+	err = t.forEach(func(k tkey, v tval) error {
+		row := []string{k.Name1, k.Name2, formatCount(v.Set), formatCount(v.Multiset)}
+		if weightSum {
+			row = append(row, formatCount(v.Weight))
+		}
+		n++
+		return w.Write(row)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write pairs: %w", err)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write pairs: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", path, err)
+	}
+	t.log.Infof(ctx, "exported %d pairs to %s", n, path)
+	return nil
+}
+
+// formatCount writes whole counts without a decimal point, as the
+// unweighted CSV always has
+func formatCount(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -2,23 +2,29 @@ package cardco
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"sync"
 
 	"github.com/dgraph-io/badger/v3"
+	"github.com/samber/mo"
 	"github.com/vmihailenco/msgpack"
 
+	"collections/games"
 	"collections/games/magic/dataset"
+	"collections/games/magic/game"
 	"collections/logger"
 	"collections/transform"
 )
 
 type Transform struct {
-	log *logger.Logger
-	dir string
-	db  *badger.DB
-	mu  *sync.Mutex
+	log     *logger.Logger
+	dir     string
+	db      *badger.DB
+	mu      *sync.Mutex
+	weights map[string]float64 // Source or collection type -> weight
+	formats games.FormatFilter // Nil counts every collection
 }
 
 func NewTransform(
@@ -40,14 +46,16 @@ func NewTransform(
 		return nil, err
 	}
 	return &Transform{
-		log: log,
-		dir: dir,
-		db:  db,
-		mu:  new(sync.Mutex),
+		log:     log,
+		dir:     dir,
+		db:      db,
+		mu:      new(sync.Mutex),
+		weights: make(map[string]float64),
 	}, nil
 }
 
-func (t *Transform) close() error {
+// Close closes the pair database and removes its directory
+func (t *Transform) Close() error {
 	if err := t.db.Close(); err != nil {
 		return err
 	}
@@ -72,12 +80,17 @@ func newKey(name1, name2 string) tkey {
 	}
 }
 
+// tval counts are scaled by the weight of each collection (see
+// collectionWeight), so with the default weights they are whole numbers.
 type tval struct {
 	// Unique set cooccurrences.
-	Set int
+	Set float64
 	// Multiset cooccurrences. Within each collection, multiple repeats of
 	// a card are counted separately. This includes self-edges.
-	Multiset int
+	Multiset float64
+	// Weight is the summed weight of the collections the pair occurs in,
+	// self-edges included.
+	Weight float64
 }
 
 func (t *Transform) add(k tkey, v tval) error {
@@ -109,6 +122,7 @@ func (t *Transform) add(k tkey, v tval) error {
 			}
 			w.Set += v.Set
 			w.Multiset += v.Multiset
+			w.Weight += v.Weight
 			wb, err = msgpack.Marshal(w)
 			if err != nil {
 				return err
@@ -127,16 +141,6 @@ func (t *Transform) add(k tkey, v tval) error {
 		return fmt.Errorf("failed to update: %w", err)
 	}
 	return nil
-	// valuePut := new(bytes.Buffer)
-	// c, ok := t.counts[pair]
-	// if !ok {
-	// 	t.counts[pair] = &count{
-	// 		set:      d.set,
-	// 		multiset: d.multiset,
-	// 	}
-	// 	return
-	// }
-	// c.add(d)
 }
 
 func (t *Transform) Transform(
@@ -144,168 +148,62 @@ func (t *Transform) Transform(
 	datasets []dataset.Dataset,
 	options ...transform.TransformOption,
 ) (*transform.TransformOutput, error) {
-	// defer func() {
-	// 	if err := t.close(); err != nil {
-	// 		t.log.Errorf(ctx, "failed to close: %v", err)
-	// 	}
-	// }()
-
-	// d := datasets[0]
-
-	// limit := mo.None[int]()
-	// parallel := 1024
-	// for _, opt := range options {
-	// 	switch opt := opt.(type) {
-	// 	case *transform.OptTransformLimit:
-	// 		if opt.Limit > 0 {
-	// 			limit = mo.Some(opt.Limit)
-	// 		}
-	// 	case *transform.OptTransformParallel:
-	// 		parallel = opt.Parallel
-	// 	default:
-	// 		panic(fmt.Sprintf("invalid option type %T", opt))
-	// 	}
-	// }
-
-	// f, err := os.Create("pairs.csv")
-	// if err != nil {
-	// 	return nil, err
-	// }
-	// defer f.Close()
-
-	// wg := new(sync.WaitGroup)
-	// items := make(chan dataset.Item)
-	// errs := make(chan error, 1)
-	// for i := 0; i < parallel; i++ {
-	// 	wg.Add(1)
-	// 	go func() {
-	// 		defer wg.Done()
-	// 		for it := range items {
-	// 			if err := t.worker(it); err != nil {
-	// 				errs <- err
-	// 				return
-	// 			}
-	// 		}
-	// 	}()
-	// }
-
-	// total := 0
-	// it := d.IterItems(ctx)
-	// err = nil
-	// checkpoint := 100
-	// ITEMS:
-	// for it.Next(ctx) {
-	// 	select {
-	// 	case err = <-errs:
-	// 		break ITEMS
-	// 	default:
-	// 	}
-	// 	item, err := it.Item(ctx)
-	// 	if err != nil {
-	// 		t.log.Errorf(ctx, "failed to get item: %v", err)
-	// 		continue
-	// 	}
-	// 	switch item := item.(type) {
-	// 	case *dataset.CollectionItem:
-	// 		_ = item
-	// 		// fmt.Println(total, item.Collection.URL)
-	// 	}
-	// 	items <- item
-	// 	total++
-	// 	if total > 0 && total%checkpoint == 0 {
-	// 		t.log.Debugf(ctx, "iterated through %d items", total)
-	// 	}
-	// 	if n, ok := limit.Get(); ok && total >= n {
-	// 		break
-	// 	}
-	// }
-	// close(items)
-	// if err != nil {
-	// 	return nil, err
-	// }
-	// if err := it.Err(); err != nil {
-	// 	return nil, fmt.Errorf("failed to iterate items: %w", err)
-	// }
-	// wg.Wait()
-	// w := csv.NewWriter(f)
-	// defer w.Flush()
-	// w.Write([]string{"NAME_1", "NAME_2", "COUNT_SET", "COUNT_MULTISET"})
-	// stream := t.db.NewStream()
-	// stream.Send = func(buf *z.Buffer) error {
-	// 	list, err := badger.BufferToKVList(buf)
-	// 	if err != nil {
-	// 		return nil
-	// 	}
-	// 	for _, kv := range list.GetKv() {
-	// 		var k tkey
-	// 		if err := msgpack.Unmarshal(kv.Key, &k); err != nil {
-	// 			return err
-	// 		}
-	// 		var v tval
-	// 		if err := msgpack.Unmarshal(kv.Value, &v); err != nil {
-	// 			return err
-	// 		}
-	// 		err = w.Write([]string{
-	// 			k.Name1,
-	// 			k.Name2,
-	// 			fmt.Sprintf("%d", v.Set),
-	// 			fmt.Sprintf("%d", v.Multiset),
-	// 		})
-	// 		if err != nil {
-	// 			return err
-	// 		}
-	// 	}
-	// 	return nil
-	// }
-	// if err := stream.Orchestrate(ctx); err != nil {
-	// 	return nil, err
-	// }
-	// // err = t.db.View(func(txn *badger.Txn) error {
-	// // 	it := txn.NewIterator(badger.DefaultIteratorOptions)
-	// // 	defer it.Close()
-	// // 	for it.Rewind(); it.Valid(); it.Next() {
-	// // 		item := it.Item()
-	// // 		kb := item.Key()
-	// // 		var k tkey
-	// // 		if err := msgpack.Unmarshal(kb, &k); err != nil {
-	// // 			return err
-	// // 		}
-	// // 		vb, err := item.ValueCopy(nil)
-	// // 		if err != nil {
-	// // 			return err
-	// // 		}
-	// // 		var v tval
-	// // 		if err := msgpack.Unmarshal(vb, &v); err != nil {
-	// // 			return err
-	// // 		}
-	// // 		w.Write([]string{
-	// // 			k.Name1,
-	// // 			k.Name2,
-	// // 			fmt.Sprintf("%d", v.Set),
-	// // 			fmt.Sprintf("%d", v.Multiset),
-	// // 		})
+	limit := mo.None[int]()
+	parallel := 1024
+	for _, opt := range options {
+		switch opt := opt.(type) {
+		case *transform.OptTransformLimit:
+			if opt.Limit > 0 {
+				limit = mo.Some(opt.Limit)
+			}
+		case *transform.OptTransformParallel:
+			parallel = opt.Parallel
+		case *transform.OptTransformSourceWeight:
+			t.weights[opt.Source] = opt.Weight
+		case *transform.OptTransformFormat:
+			if err := t.formats.Set(opt.Format); err != nil {
+				return nil, err
+			}
+		default:
+			panic(fmt.Sprintf("invalid option type %T", opt))
+		}
+	}
 
-	// // 	}
-	// // 	return nil
-	// // })
-	// // for pair, count := range t.counts {
-	// // 	w.Write([]string{
-	// // 		pair.name1,
-	// // 		pair.name2,
-	// // 		fmt.Sprintf("%d", count.set),
-	// // 		fmt.Sprintf("%d", count.multiset),
-	// // 	})
-	// // }
-	// if err != nil {
-	// 	return nil, err
-	// }
-	// w.Flush()
-	return nil, nil
+	mu := new(sync.Mutex)
+	total := 0
+	fn := func(item dataset.Item) error {
+		mu.Lock()
+		if n, ok := limit.Get(); ok && total >= n {
+			mu.Unlock()
+			return dataset.ErrIterItemsStop
+		}
+		total++
+		if total%10000 == 0 {
+			t.log.Debugf(ctx, "transformed %d items", total)
+		}
+		mu.Unlock()
+		return t.worker(item)
+	}
+	for _, d := range datasets {
+		err := d.IterItems(ctx, fn, &dataset.OptIterItemsParallel{Parallel: parallel})
+		if err != nil && !errors.Is(err, dataset.ErrIterItemsStop) {
+			return nil, fmt.Errorf("failed to iterate %s items: %w", d.Description().Name, err)
+		}
+	}
+	t.log.Infof(ctx, "transformed %d items", total)
+	return &transform.TransformOutput{}, nil
 }
 
 func (t *Transform) worker(item dataset.Item) error {
 	switch item := item.(type) {
 	case *dataset.CollectionItem:
+		if !t.formats.Match(deckFormat(item.Collection)) {
+			return nil
+		}
+		w := t.collectionWeight(item.Collection)
+		if w == 0 {
+			return nil
+		}
 		for _, partition := range item.Collection.Partitions {
 			n := len(partition.Cards)
 			for i := 0; i < n; i++ {
@@ -314,7 +212,8 @@ func (t *Transform) worker(item dataset.Item) error {
 					k := newKey(c.Name, c.Name)
 					err := t.add(k, tval{
 						Set:      0,
-						Multiset: c.Count - 1,
+						Multiset: w * float64(c.Count-1),
+						Weight:   w,
 					})
 					if err != nil {
 						return err
@@ -324,8 +223,9 @@ func (t *Transform) worker(item dataset.Item) error {
 					d := partition.Cards[j]
 					k := newKey(c.Name, d.Name)
 					err := t.add(k, tval{
-						Set:      1,
-						Multiset: c.Count * d.Count,
+						Set:      w,
+						Multiset: w * float64(c.Count*d.Count),
+						Weight:   w,
 					})
 					if err != nil {
 						return err
@@ -340,6 +240,24 @@ func (t *Transform) worker(item dataset.Item) error {
 	return nil
 }
 
+// FormatOptions returns a Transform option for each format in formats
+func FormatOptions(formats games.FormatFilter) []transform.TransformOption {
+	var options []transform.TransformOption
+	for format := range formats {
+		options = append(options, &transform.OptTransformFormat{Format: format})
+	}
+	return options
+}
+
+// deckFormat is c's deck format, or "" for sets, cubes and decks without
+// one
+func deckFormat(c *game.Collection) string {
+	if deck, ok := c.Type.Inner.(*game.CollectionTypeDeck); ok {
+		return deck.Format
+	}
+	return ""
+}
+
 var _ badger.Logger = (*badgerLogger)(nil)
 
 type badgerLogger struct {
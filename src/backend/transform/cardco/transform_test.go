@@ -0,0 +1,42 @@
+package cardco
+
+import (
+	"context"
+	"testing"
+
+	"collections/games"
+	"collections/games/magic/dataset"
+	"collections/games/magic/game"
+)
+
+func TestTransformFormat(t *testing.T) {
+	deck := func(format string, cards ...string) dataset.Item {
+		var descs []game.CardDesc
+		for _, name := range cards {
+			descs = append(descs, game.CardDesc{Name: name, Count: 1})
+		}
+		return &dataset.CollectionItem{Collection: &game.Collection{
+			Type:       game.CollectionTypeWrapper{Type: "Deck", Inner: &game.CollectionTypeDeck{Format: format}},
+			Partitions: []game.Partition{{Name: "Main", Cards: descs}},
+		}}
+	}
+	d := &itemsDataset{items: []dataset.Item{
+		deck("Modern", "Lightning Bolt", "Mountain"),
+		deck("MOD", "Lightning Bolt", "Mountain"),
+		deck("Commander", "Sol Ring", "Command Tower"),
+		deck("", "Lightning Bolt", "Mountain"),
+	}}
+
+	tr := newTestTransform(t)
+	options := FormatOptions(games.NewFormatFilter([]string{"modern"}))
+	if _, err := tr.Transform(context.Background(), []dataset.Dataset{d}, options...); err != nil {
+		t.Fatalf("Transform() error = %v", err)
+	}
+	pairs := snapshot(t, tr)
+	if v := pairs[newKey("Lightning Bolt", "Mountain")]; v.Set != 2 {
+		t.Errorf("Lightning Bolt/Mountain = %+v, want Set 2 from the Modern decks", v)
+	}
+	if v, ok := pairs[newKey("Sol Ring", "Command Tower")]; ok {
+		t.Errorf("Sol Ring/Command Tower = %+v, want no pair from the Commander deck", v)
+	}
+}
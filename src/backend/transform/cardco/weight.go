@@ -0,0 +1,95 @@
+package cardco
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"collections/games"
+	"collections/games/magic/game"
+	"collections/transform"
+)
+
+// DefaultTypeWeights are the weights of collection types that don't have
+// one set. Sets and cubes group cards printed or curated together, not
+// played together, so they add noise to co-occurrence unless weighted in
+// explicitly.
+var DefaultTypeWeights = map[string]float64{
+	"Set":  0,
+	"Cube": 0,
+}
+
+// collectionWeight is the factor a collection's pair counts are scaled
+// by: the weight of its source, else of its collection type, else the
+// DefaultTypeWeights entry for the type, else 1
+func (t *Transform) collectionWeight(c *game.Collection) float64 {
+	source := string(games.InferSource(c.URL, ""))
+	if w, ok := t.weights[source]; ok {
+		return w
+	}
+	if w, ok := t.weights[c.Type.Type]; ok {
+		return w
+	}
+	if w, ok := DefaultTypeWeights[c.Type.Type]; ok {
+		return w
+	}
+	return 1
+}
+
+// SourceWeights collects repeated --source-weight source=factor flags.
+// It implements flag.Value.
+type SourceWeights map[string]float64
+
+func (w SourceWeights) String() string {
+	pairs := make([]string, 0, len(w))
+	for source, weight := range w {
+		pairs = append(pairs, source+"="+strconv.FormatFloat(weight, 'g', -1, 64))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// collectionTypes are the collection type names a weight can be keyed by
+// in place of a source
+var collectionTypes = []game.CollectionType{
+	&game.CollectionTypeDeck{},
+	&game.CollectionTypeSet{},
+	&game.CollectionTypeCube{},
+}
+
+// Set parses one source=factor pair. The source is resolved with
+// games.ParseSource, so aliases and any casing match the sources
+// collections are attributed to; a collection type name such as Set or
+// Cube is accepted in any casing too. Anything else is an error.
+func (w SourceWeights) Set(s string) error {
+	key, factor, ok := strings.Cut(s, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return fmt.Errorf("source weight %q is not source=factor", s)
+	}
+	weight, err := strconv.ParseFloat(strings.TrimSpace(factor), 64)
+	if err != nil || weight < 0 {
+		return fmt.Errorf("source weight %q: factor must be a non-negative number", s)
+	}
+	if source, ok := games.ParseSource(key); ok {
+		w[string(source)] = weight
+		return nil
+	}
+	for _, typ := range collectionTypes {
+		if strings.EqualFold(key, typ.Type()) {
+			w[typ.Type()] = weight
+			return nil
+		}
+	}
+	return fmt.Errorf("source weight %q: unknown source or collection type %q", s, key)
+}
+
+// Options returns the weights as Transform options
+func (w SourceWeights) Options() []transform.TransformOption {
+	var options []transform.TransformOption
+	for source, weight := range w {
+		options = append(options, &transform.OptTransformSourceWeight{Source: source, Weight: weight})
+	}
+	return options
+}
@@ -0,0 +1,123 @@
+package cardco
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"collections/games/magic/dataset"
+	"collections/games/magic/game"
+	"collections/scraper"
+)
+
+// itemsDataset serves fixed items
+type itemsDataset struct {
+	items []dataset.Item
+}
+
+func (d *itemsDataset) Description() dataset.Description {
+	return dataset.Description{Name: "items"}
+}
+
+func (d *itemsDataset) Extract(context.Context, *scraper.Scraper, ...dataset.UpdateOption) error {
+	return nil
+}
+
+func (d *itemsDataset) IterItems(_ context.Context, fn func(dataset.Item) error, _ ...dataset.IterItemsOption) error {
+	for _, item := range d.items {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestTransformSourceWeights(t *testing.T) {
+	collection := func(url string, typ game.CollectionType, cards ...game.CardDesc) dataset.Item {
+		return &dataset.CollectionItem{Collection: &game.Collection{
+			URL:        url,
+			Type:       game.CollectionTypeWrapper{Type: typ.Type(), Inner: typ},
+			Partitions: []game.Partition{{Name: "Main", Cards: cards}},
+		}}
+	}
+	bolt := game.CardDesc{Name: "Lightning Bolt", Count: 4}
+	mountain := game.CardDesc{Name: "Mountain", Count: 20}
+	d := &itemsDataset{items: []dataset.Item{
+		collection("https://www.mtgtop8.com/event?e=1&d=1", &game.CollectionTypeDeck{}, bolt, mountain),
+		collection("https://www.mtggoldfish.com/deck/1", &game.CollectionTypeDeck{}, bolt, mountain),
+		collection("https://scryfall.com/sets/lea", &game.CollectionTypeSet{},
+			game.CardDesc{Name: "Lightning Bolt", Count: 1}, game.CardDesc{Name: "Mountain", Count: 1}),
+	}}
+
+	tests := []struct {
+		weights []string
+		want    string // Lightning Bolt/Mountain row
+	}{
+		{nil, "2,160,2"},
+		{[]string{"goldfish=0.5"}, "1.5,120,1.5"},
+		{[]string{"Set=1"}, "3,161,3"},
+		{[]string{"Set=1", "scryfall=0"}, "2,160,2"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.weights), func(t *testing.T) {
+			weights := make(SourceWeights)
+			for _, w := range tt.weights {
+				if err := weights.Set(w); err != nil {
+					t.Fatalf("Set(%q) error = %v", w, err)
+				}
+			}
+			tr := newTestTransform(t)
+			if _, err := tr.Transform(context.Background(), []dataset.Dataset{d}, weights.Options()...); err != nil {
+				t.Fatalf("Transform() error = %v", err)
+			}
+			path := filepath.Join(t.TempDir(), "pairs.csv")
+			if err := tr.ExportCSV(context.Background(), path, &OptExportWeightSum{}); err != nil {
+				t.Fatalf("ExportCSV() error = %v", err)
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			rows, err := csv.NewReader(f).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := strings.Join(rows[0], ","), "NAME_1,NAME_2,COUNT_SET,COUNT_MULTISET,WEIGHT_SUM"; got != want {
+				t.Errorf("header = %s, want %s", got, want)
+			}
+			for _, row := range rows[1:] {
+				if row[0] == "Lightning Bolt" && row[1] == "Mountain" {
+					if got := strings.Join(row[2:], ","); got != tt.want {
+						t.Errorf("Lightning Bolt/Mountain = %s, want %s", got, tt.want)
+					}
+					return
+				}
+			}
+			t.Errorf("no Lightning Bolt/Mountain row in %v", rows)
+		})
+	}
+}
+
+func TestSourceWeightsSet(t *testing.T) {
+	weights := make(SourceWeights)
+	for _, s := range []string{"goldfish", "=1", "goldfish=x", "goldfish=-1", "nosuchsite=1"} {
+		if err := weights.Set(s); err == nil {
+			t.Errorf("Set(%q) error = nil, want error", s)
+		}
+	}
+	for _, s := range []string{" mtgtop8 = 2 ", "MTGGoldfish=0.5", "cube=1"} {
+		if err := weights.Set(s); err != nil {
+			t.Errorf("Set(%q) error = %v", s, err)
+		}
+	}
+	want := SourceWeights{"mtgtop8": 2, "goldfish": 0.5, "Cube": 1}
+	if weights.String() != want.String() {
+		t.Errorf("weights = %v, want %v", weights, want)
+	}
+}
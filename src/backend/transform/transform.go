@@ -28,5 +28,23 @@ type OptTransformParallel struct {
 	Parallel int
 }
 
-func (o OptTransformLimit) transformOption()    {}
-func (o OptTransformParallel) transformOption() {}
+// OptTransformSourceWeight multiplies the counts contributed by collections
+// from Source by Weight. Source may also be a collection type ("Set",
+// "Cube"), which applies to collections of that type from any source
+// without a weight of their own.
+type OptTransformSourceWeight struct {
+	Source string
+	Weight float64
+}
+
+// OptTransformFormat only counts decks of Format (see games.FormatFilter);
+// repeat it for several formats. Sets, cubes and decks without a format
+// are skipped once any format is given.
+type OptTransformFormat struct {
+	Format string
+}
+
+func (o OptTransformLimit) transformOption()        {}
+func (o OptTransformParallel) transformOption()     {}
+func (o OptTransformSourceWeight) transformOption() {}
+func (o OptTransformFormat) transformOption()       {}